@@ -18,12 +18,16 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"cloud.google.com/go/pubsub"
 
 	"github.com/google/knative-gcp/pkg/broker/config/volume"
 	"github.com/google/knative-gcp/pkg/broker/handler"
+	"github.com/google/knative-gcp/pkg/broker/handler/breaker"
+	"github.com/google/knative-gcp/pkg/broker/handler/dedup"
+	"github.com/google/knative-gcp/pkg/broker/queue"
 	metadataClient "github.com/google/knative-gcp/pkg/gclient/metadata"
 	"github.com/google/knative-gcp/pkg/metrics"
 	"github.com/google/knative-gcp/pkg/utils"
@@ -58,6 +62,25 @@ type envConfig struct {
 
 	// Max to 10m.
 	TimeoutPerEvent time.Duration `envconfig:"TIMEOUT_PER_EVENT"`
+
+	// DedupCacheSize is the maximum number of events recorded to deduplicate the events
+	// delivered to the triggers with a deduplication window. Deduplication is disabled if 0.
+	DedupCacheSize int `envconfig:"DEDUP_CACHE_SIZE" default:"100000"`
+
+	// UnconvertibleSinkURI is the URI that the messages which can't be converted to events
+	// are sent to, wrapped in an envelope event. They are dropped if it is empty.
+	UnconvertibleSinkURI string `envconfig:"UNCONVERTIBLE_SINK_URI"`
+
+	// The circuit breaker of a trigger opens after CircuitBreakerFailureThreshold consecutive
+	// failed deliveries, or deliveries slower than CircuitBreakerSlowThreshold. The events of the
+	// trigger then go straight to its retry queue until a probe delivery succeeds, at most every
+	// CircuitBreakerOpenDuration. The default failure threshold of 0 disables circuit breaking.
+	CircuitBreakerFailureThreshold int           `envconfig:"CIRCUIT_BREAKER_FAILURE_THRESHOLD" default:"0"`
+	CircuitBreakerSlowThreshold    time.Duration `envconfig:"CIRCUIT_BREAKER_SLOW_THRESHOLD" default:"30s"`
+	CircuitBreakerOpenDuration     time.Duration `envconfig:"CIRCUIT_BREAKER_OPEN_DURATION" default:"30s"`
+
+	// The decouple queue configuration of the BrokerCell.
+	queue.EnvConfig
 }
 
 func main() {
@@ -78,17 +101,10 @@ func main() {
 
 	logger.Info("Starting the broker fanout")
 
-	projectID, err := utils.ProjectID(env.ProjectID, metadataClient.NewDefaultMetadataClient())
-	if err != nil {
-		logger.Fatalf("failed to get default ProjectID: %v", err)
-	}
-
 	syncSignal := poolSyncSignal(ctx, targetsUpdateCh)
-	syncPool, err := InitializeSyncPool(
+	syncPool, err := initializeSyncPool(
 		ctx,
-		clients.ProjectID(projectID),
-		metrics.PodName(env.PodName),
-		metrics.ContainerName(component),
+		env,
 		[]volume.Option{
 			volume.WithPath(env.TargetsConfigPath),
 			volume.WithNotifyChan(targetsUpdateCh),
@@ -109,6 +125,28 @@ func main() {
 	logger.Info("Done waiting, exit.")
 }
 
+// initializeSyncPool initializes the fanout sync pool on the queues of the BrokerCell.
+func initializeSyncPool(ctx context.Context, env envConfig, targetsVolumeOpts []volume.Option, opts ...handler.Option) (*handler.FanoutPool, error) {
+	podName := metrics.PodName(env.PodName)
+	containerName := metrics.ContainerName(component)
+	switch env.Kind {
+	case queue.PubSub:
+		projectID, err := utils.ProjectID(env.ProjectID, metadataClient.NewDefaultMetadataClient())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get default ProjectID: %w", err)
+		}
+		return InitializeSyncPool(ctx, clients.ProjectID(projectID), podName, containerName, targetsVolumeOpts, opts...)
+	case queue.Redis:
+		client, err := env.NewRedisClient()
+		if err != nil {
+			return nil, err
+		}
+		return InitializeRedisSyncPool(ctx, client, podName, containerName, targetsVolumeOpts, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported decouple queue %q", env.Kind)
+	}
+}
+
 func poolSyncSignal(ctx context.Context, targetsUpdateCh chan struct{}) chan struct{} {
 	// Give it some buffer so that multiple signal could queue up
 	// but not blocking the signaler?
@@ -143,9 +181,22 @@ func buildHandlerOptions(env envConfig) []handler.Option {
 	if env.TimeoutPerEvent > 0 {
 		opts = append(opts, handler.WithTimeoutPerEvent(env.TimeoutPerEvent))
 	}
+	opts = append(opts, handler.WithCircuitBreaker(breaker.Settings{
+		FailureThreshold: env.CircuitBreakerFailureThreshold,
+		SlowThreshold:    env.CircuitBreakerSlowThreshold,
+		OpenDuration:     env.CircuitBreakerOpenDuration,
+	}))
 	if env.MaxOutstandingBytes > 0 {
 		rs.MaxOutstandingBytes = env.MaxOutstandingBytes
 	}
+	if env.DedupCacheSize > 0 {
+		// NewLRU only fails with a non-positive size.
+		store, _ := dedup.NewLRU(env.DedupCacheSize)
+		opts = append(opts, handler.WithDedupStore(store))
+	}
+	if env.UnconvertibleSinkURI != "" {
+		opts = append(opts, handler.WithUnconvertibleSinkURI(env.UnconvertibleSinkURI))
+	}
 	opts = append(opts, handler.WithPubsubReceiveSettings(rs))
 	// The default CeClient is good?
 	return opts
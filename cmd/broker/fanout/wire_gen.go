@@ -10,6 +10,7 @@ import (
 	"github.com/google/knative-gcp/pkg/broker/config/volume"
 	"github.com/google/knative-gcp/pkg/broker/handler"
 	"github.com/google/knative-gcp/pkg/metrics"
+	"github.com/google/knative-gcp/pkg/redis"
 	"github.com/google/knative-gcp/pkg/utils/clients"
 )
 
@@ -24,6 +25,7 @@ func InitializeSyncPool(ctx context.Context, projectID clients.ProjectID, podNam
 	if err != nil {
 		return nil, err
 	}
+	inboundFactory := handler.NewPubsubInboundFactory(client)
 	httpClient := _wireClientValue
 	v := _wireValue
 	retryClient, err := handler.NewRetryClient(ctx, client, v...)
@@ -34,7 +36,7 @@ func InitializeSyncPool(ctx context.Context, projectID clients.ProjectID, podNam
 	if err != nil {
 		return nil, err
 	}
-	fanoutPool, err := handler.NewFanoutPool(readonlyTargets, client, httpClient, retryClient, deliveryReporter, opts...)
+	fanoutPool, err := handler.NewFanoutPool(readonlyTargets, inboundFactory, httpClient, retryClient, deliveryReporter, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -45,3 +47,31 @@ var (
 	_wireClientValue = handler.DefaultHTTPClient
 	_wireValue       = handler.DefaultCEClientOpts
 )
+
+func InitializeRedisSyncPool(ctx context.Context, redisClient *redis.Client, podName metrics.PodName, containerName metrics.ContainerName, targetsVolumeOpts []volume.Option, opts ...handler.Option) (*handler.FanoutPool, error) {
+	readonlyTargets, err := volume.NewTargetsFromFile(targetsVolumeOpts...)
+	if err != nil {
+		return nil, err
+	}
+	inboundFactory := handler.NewRedisInboundFactory(redisClient, podName)
+	httpClient := _wireHttpClientValue
+	v := _wireOptionValue
+	retryClient, err := handler.NewRedisRetryClient(redisClient, v...)
+	if err != nil {
+		return nil, err
+	}
+	deliveryReporter, err := metrics.NewDeliveryReporter(podName, containerName)
+	if err != nil {
+		return nil, err
+	}
+	fanoutPool, err := handler.NewFanoutPool(readonlyTargets, inboundFactory, httpClient, retryClient, deliveryReporter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return fanoutPool, nil
+}
+
+var (
+	_wireHttpClientValue = handler.DefaultHTTPClient
+	_wireOptionValue     = handler.DefaultCEClientOpts
+)
@@ -17,6 +17,12 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/google/knative-gcp/pkg/broker/config/volume"
+	"github.com/google/knative-gcp/pkg/broker/ingress"
+	"github.com/google/knative-gcp/pkg/broker/queue"
 	metadataClient "github.com/google/knative-gcp/pkg/gclient/metadata"
 	"github.com/google/knative-gcp/pkg/metrics"
 	"github.com/google/knative-gcp/pkg/utils"
@@ -35,6 +41,9 @@ type envConfig struct {
 
 	// Default 300Mi.
 	PublishBufferedByteLimit int `envconfig:"PUBLISH_BUFFERED_BYTES_LIMIT" default:"314572800"`
+
+	// The decouple queue configuration of the BrokerCell.
+	queue.EnvConfig
 }
 
 const (
@@ -47,6 +56,7 @@ const (
 // 2. It reads "PROJECT_ID" env var for pubsub project. If the env var is empty, it retrieves project ID from
 //    GCE metadata.
 // 3. It expects broker configmap mounted at "/var/run/cloud-run-events/broker/targets"
+// 4. It sends events to Redis streams rather than pubsub topics if "DECOUPLE_QUEUE" is "redis"
 func main() {
 	appcredentials.MustExistOrUnsetEnv()
 
@@ -55,20 +65,7 @@ func main() {
 	defer res.Cleanup()
 	logger := res.Logger
 
-	projectID, err := utils.ProjectID(env.ProjectID, metadataClient.NewDefaultMetadataClient())
-	if err != nil {
-		logger.Desugar().Fatal("Failed to create project id", zap.Error(err))
-	}
-	logger.Desugar().Info("Starting ingress handler", zap.Any("envConfig", env), zap.Any("Project ID", projectID))
-
-	ingress, err := InitializeHandler(
-		ctx,
-		clients.Port(env.Port),
-		clients.ProjectID(projectID),
-		metrics.PodName(env.PodName),
-		metrics.ContainerName(component),
-		publishSetting(logger.Desugar(), env),
-	)
+	ingress, err := initializeHandler(ctx, logger.Desugar(), env)
 	if err != nil {
 		logger.Desugar().Fatal("Unable to create ingress handler: ", zap.Error(err))
 	}
@@ -79,6 +76,48 @@ func main() {
 	}
 }
 
+// initializeHandler initializes the ingress handler on the decouple queues of the BrokerCell.
+func initializeHandler(ctx context.Context, logger *zap.Logger, env envConfig) (*ingress.Handler, error) {
+	targetsUpdateCh := make(chan struct{})
+	targetsVolumeOpts := []volume.Option{volume.WithNotifyChan(targetsUpdateCh)}
+	switch env.Kind {
+	case queue.PubSub:
+		projectID, err := utils.ProjectID(env.ProjectID, metadataClient.NewDefaultMetadataClient())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create project id: %w", err)
+		}
+		logger.Info("Starting ingress handler", zap.Any("envConfig", env), zap.Any("Project ID", projectID))
+		return InitializeHandler(
+			ctx,
+			clients.Port(env.Port),
+			clients.ProjectID(projectID),
+			metrics.PodName(env.PodName),
+			metrics.ContainerName(component),
+			publishSetting(logger, env),
+			targetsVolumeOpts,
+			targetsUpdateCh,
+		)
+	case queue.Redis:
+		client, err := env.NewRedisClient()
+		if err != nil {
+			return nil, err
+		}
+		// The env config holds the Redis password, leave it out of the logs.
+		logger.Info("Starting ingress handler", zap.String("Redis address", env.RedisAddress))
+		return InitializeRedisHandler(
+			ctx,
+			clients.Port(env.Port),
+			client,
+			metrics.PodName(env.PodName),
+			metrics.ContainerName(component),
+			targetsVolumeOpts,
+			targetsUpdateCh,
+		)
+	default:
+		return nil, fmt.Errorf("unsupported decouple queue %q", env.Kind)
+	}
+}
+
 func publishSetting(logger *zap.Logger, env envConfig) pubsub.PublishSettings {
 	s := pubsub.DefaultPublishSettings
 	if env.PublishBufferedByteLimit > 0 {
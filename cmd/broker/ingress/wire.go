@@ -25,6 +25,7 @@ import (
 	"github.com/google/knative-gcp/pkg/broker/config/volume"
 	"github.com/google/knative-gcp/pkg/broker/ingress"
 	"github.com/google/knative-gcp/pkg/metrics"
+	"github.com/google/knative-gcp/pkg/redis"
 	"github.com/google/knative-gcp/pkg/utils/clients"
 	"github.com/google/wire"
 )
@@ -36,10 +37,26 @@ func InitializeHandler(
 	podName metrics.PodName,
 	containerName metrics.ContainerName,
 	publishSettings pubsub.PublishSettings,
+	targetsVolumeOpts []volume.Option,
+	targetsUpdates ingress.TargetsUpdates,
 ) (*ingress.Handler, error) {
 	panic(wire.Build(
 		ingress.HandlerSet,
-		wire.Value([]volume.Option(nil)),
+		volume.NewTargetsFromFile,
+	))
+}
+
+func InitializeRedisHandler(
+	ctx context.Context,
+	port clients.Port,
+	redisClient *redis.Client,
+	podName metrics.PodName,
+	containerName metrics.ContainerName,
+	targetsVolumeOpts []volume.Option,
+	targetsUpdates ingress.TargetsUpdates,
+) (*ingress.Handler, error) {
+	panic(wire.Build(
+		ingress.RedisHandlerSet,
 		volume.NewTargetsFromFile,
 	))
 }
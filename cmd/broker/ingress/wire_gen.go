@@ -10,16 +10,17 @@ import (
 	"context"
 	"github.com/google/knative-gcp/pkg/broker/config/volume"
 	"github.com/google/knative-gcp/pkg/broker/ingress"
+	"github.com/google/knative-gcp/pkg/broker/queue"
 	"github.com/google/knative-gcp/pkg/metrics"
+	"github.com/google/knative-gcp/pkg/redis"
 	"github.com/google/knative-gcp/pkg/utils/clients"
 )
 
 // Injectors from wire.go:
 
-func InitializeHandler(ctx context.Context, port clients.Port, projectID clients.ProjectID, podName metrics.PodName, containerName metrics.ContainerName, publishSettings pubsub.PublishSettings) (*ingress.Handler, error) {
+func InitializeHandler(ctx context.Context, port clients.Port, projectID clients.ProjectID, podName metrics.PodName, containerName metrics.ContainerName, publishSettings pubsub.PublishSettings, targetsVolumeOpts []volume.Option, targetsUpdates ingress.TargetsUpdates) (*ingress.Handler, error) {
 	httpMessageReceiver := clients.NewHTTPMessageReceiver(port)
-	v := _wireValue
-	readonlyTargets, err := volume.NewTargetsFromFile(v...)
+	readonlyTargets, err := volume.NewTargetsFromFile(targetsVolumeOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -28,14 +29,28 @@ func InitializeHandler(ctx context.Context, port clients.Port, projectID clients
 		return nil, err
 	}
 	multiTopicDecoupleSink := ingress.NewMultiTopicDecoupleSink(ctx, readonlyTargets, client, publishSettings)
+	rateLimiter := ingress.NewRateLimiter(ctx, readonlyTargets, targetsUpdates)
 	ingressReporter, err := metrics.NewIngressReporter(podName, containerName)
 	if err != nil {
 		return nil, err
 	}
-	handler := ingress.NewHandler(ctx, httpMessageReceiver, multiTopicDecoupleSink, ingressReporter)
+	handler := ingress.NewHandler(ctx, httpMessageReceiver, multiTopicDecoupleSink, readonlyTargets, rateLimiter, ingressReporter)
 	return handler, nil
 }
 
-var (
-	_wireValue = []volume.Option(nil)
-)
+func InitializeRedisHandler(ctx context.Context, port clients.Port, redisClient *redis.Client, podName metrics.PodName, containerName metrics.ContainerName, targetsVolumeOpts []volume.Option, targetsUpdates ingress.TargetsUpdates) (*ingress.Handler, error) {
+	httpMessageReceiver := clients.NewHTTPMessageReceiver(port)
+	readonlyTargets, err := volume.NewTargetsFromFile(targetsVolumeOpts...)
+	if err != nil {
+		return nil, err
+	}
+	redisSender := queue.NewRedisSender(redisClient)
+	redisDecoupleSink := ingress.NewRedisDecoupleSink(readonlyTargets, redisSender)
+	rateLimiter := ingress.NewRateLimiter(ctx, readonlyTargets, targetsUpdates)
+	ingressReporter, err := metrics.NewIngressReporter(podName, containerName)
+	if err != nil {
+		return nil, err
+	}
+	handler := ingress.NewHandler(ctx, httpMessageReceiver, redisDecoupleSink, readonlyTargets, rateLimiter, ingressReporter)
+	return handler, nil
+}
@@ -19,6 +19,7 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"time"
 
 	"cloud.google.com/go/pubsub"
@@ -26,6 +27,8 @@ import (
 
 	"github.com/google/knative-gcp/pkg/broker/config/volume"
 	"github.com/google/knative-gcp/pkg/broker/handler"
+	"github.com/google/knative-gcp/pkg/broker/handler/dedup"
+	"github.com/google/knative-gcp/pkg/broker/queue"
 	metadataClient "github.com/google/knative-gcp/pkg/gclient/metadata"
 	"github.com/google/knative-gcp/pkg/metrics"
 	"github.com/google/knative-gcp/pkg/utils"
@@ -59,6 +62,17 @@ type envConfig struct {
 
 	// Max to 10m.
 	TimeoutPerEvent time.Duration `envconfig:"TIMEOUT_PER_EVENT"`
+
+	// DedupCacheSize is the maximum number of events recorded to deduplicate the events
+	// delivered to the triggers with a deduplication window. Deduplication is disabled if 0.
+	DedupCacheSize int `envconfig:"DEDUP_CACHE_SIZE" default:"100000"`
+
+	// UnconvertibleSinkURI is the URI that the messages which can't be converted to events
+	// are sent to, wrapped in an envelope event. They are dropped if it is empty.
+	UnconvertibleSinkURI string `envconfig:"UNCONVERTIBLE_SINK_URI"`
+
+	// The decouple queue configuration of the BrokerCell.
+	queue.EnvConfig
 }
 
 func main() {
@@ -79,17 +93,10 @@ func main() {
 	targetsUpdateCh := make(chan struct{})
 	logger.Info("Starting the broker retry")
 
-	projectID, err := utils.ProjectID(env.ProjectID, metadataClient.NewDefaultMetadataClient())
-	if err != nil {
-		logger.Fatalf("failed to get default ProjectID: %v", err)
-	}
-
 	syncSignal := poolSyncSignal(ctx, targetsUpdateCh)
-	syncPool, err := InitializeSyncPool(
+	syncPool, err := initializeSyncPool(
 		ctx,
-		clients.ProjectID(projectID),
-		metrics.PodName(env.PodName),
-		metrics.ContainerName(component),
+		env,
 		[]volume.Option{
 			volume.WithPath(env.TargetsConfigPath),
 			volume.WithNotifyChan(targetsUpdateCh),
@@ -108,6 +115,28 @@ func main() {
 	logger.Info("Exiting...")
 }
 
+// initializeSyncPool initializes the retry sync pool on the queues of the BrokerCell.
+func initializeSyncPool(ctx context.Context, env envConfig, targetsVolumeOpts []volume.Option, opts ...handler.Option) (*handler.RetryPool, error) {
+	podName := metrics.PodName(env.PodName)
+	containerName := metrics.ContainerName(component)
+	switch env.Kind {
+	case queue.PubSub:
+		projectID, err := utils.ProjectID(env.ProjectID, metadataClient.NewDefaultMetadataClient())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get default ProjectID: %w", err)
+		}
+		return InitializeSyncPool(ctx, clients.ProjectID(projectID), podName, containerName, targetsVolumeOpts, opts...)
+	case queue.Redis:
+		client, err := env.NewRedisClient()
+		if err != nil {
+			return nil, err
+		}
+		return InitializeRedisSyncPool(ctx, client, podName, containerName, targetsVolumeOpts, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported decouple queue %q", env.Kind)
+	}
+}
+
 func poolSyncSignal(ctx context.Context, targetsUpdateCh chan struct{}) chan struct{} {
 	// Give it some buffer so that multiple signal could queue up
 	// but not blocking the signaler?
@@ -145,6 +174,14 @@ func buildHandlerOptions(env envConfig) []handler.Option {
 	if env.TimeoutPerEvent > 0 {
 		opts = append(opts, handler.WithTimeoutPerEvent(env.TimeoutPerEvent))
 	}
+	if env.DedupCacheSize > 0 {
+		// NewLRU only fails with a non-positive size.
+		store, _ := dedup.NewLRU(env.DedupCacheSize)
+		opts = append(opts, handler.WithDedupStore(store))
+	}
+	if env.UnconvertibleSinkURI != "" {
+		opts = append(opts, handler.WithUnconvertibleSinkURI(env.UnconvertibleSinkURI))
+	}
 	opts = append(opts, handler.WithPubsubReceiveSettings(rs))
 	// The default CeClient is good?
 	return opts
@@ -24,6 +24,7 @@ import (
 	"github.com/google/knative-gcp/pkg/broker/config/volume"
 	"github.com/google/knative-gcp/pkg/broker/handler"
 	"github.com/google/knative-gcp/pkg/metrics"
+	"github.com/google/knative-gcp/pkg/redis"
 	"github.com/google/knative-gcp/pkg/utils/clients"
 	"github.com/google/wire"
 )
@@ -41,3 +42,17 @@ func InitializeSyncPool(
 	// added here.
 	panic(wire.Build(handler.ProviderSet, volume.NewTargetsFromFile, metrics.NewDeliveryReporter))
 }
+
+// InitializeRedisSyncPool initializes the retry sync pool on Redis streams. Uses the given
+// redisClient to pull and retry events and uses targetsVolumeOpts to initialize the targets
+// volume watcher.
+func InitializeRedisSyncPool(
+	ctx context.Context,
+	redisClient *redis.Client,
+	podName metrics.PodName,
+	containerName metrics.ContainerName,
+	targetsVolumeOpts []volume.Option,
+	opts ...handler.Option,
+) (*handler.RetryPool, error) {
+	panic(wire.Build(handler.RedisProviderSet, volume.NewTargetsFromFile, metrics.NewDeliveryReporter))
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/google/knative-gcp/pkg/broker/config/volume"
 	"github.com/google/knative-gcp/pkg/broker/handler"
 	"github.com/google/knative-gcp/pkg/metrics"
+	"github.com/google/knative-gcp/pkg/redis"
 	"github.com/google/knative-gcp/pkg/utils/clients"
 )
 
@@ -24,12 +25,13 @@ func InitializeSyncPool(ctx context.Context, projectID clients.ProjectID, podNam
 	if err != nil {
 		return nil, err
 	}
+	inboundFactory := handler.NewPubsubInboundFactory(client)
 	httpClient := _wireClientValue
 	deliveryReporter, err := metrics.NewDeliveryReporter(podName, containerName)
 	if err != nil {
 		return nil, err
 	}
-	retryPool, err := handler.NewRetryPool(readonlyTargets, client, httpClient, deliveryReporter, opts...)
+	retryPool, err := handler.NewRetryPool(readonlyTargets, inboundFactory, httpClient, deliveryReporter, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -39,3 +41,25 @@ func InitializeSyncPool(ctx context.Context, projectID clients.ProjectID, podNam
 var (
 	_wireClientValue = handler.DefaultHTTPClient
 )
+
+func InitializeRedisSyncPool(ctx context.Context, redisClient *redis.Client, podName metrics.PodName, containerName metrics.ContainerName, targetsVolumeOpts []volume.Option, opts ...handler.Option) (*handler.RetryPool, error) {
+	readonlyTargets, err := volume.NewTargetsFromFile(targetsVolumeOpts...)
+	if err != nil {
+		return nil, err
+	}
+	inboundFactory := handler.NewRedisInboundFactory(redisClient, podName)
+	httpClient := _wireHttpClientValue
+	deliveryReporter, err := metrics.NewDeliveryReporter(podName, containerName)
+	if err != nil {
+		return nil, err
+	}
+	retryPool, err := handler.NewRetryPool(readonlyTargets, inboundFactory, httpClient, deliveryReporter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return retryPool, nil
+}
+
+var (
+	_wireHttpClientValue = handler.DefaultHTTPClient
+)
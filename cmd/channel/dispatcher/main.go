@@ -0,0 +1,212 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"go.uber.org/zap"
+
+	"github.com/google/knative-gcp/pkg/broker/config/volume"
+	"github.com/google/knative-gcp/pkg/broker/handler"
+	"github.com/google/knative-gcp/pkg/broker/handler/breaker"
+	metadataClient "github.com/google/knative-gcp/pkg/gclient/metadata"
+	"github.com/google/knative-gcp/pkg/metrics"
+	"github.com/google/knative-gcp/pkg/utils"
+	"github.com/google/knative-gcp/pkg/utils/appcredentials"
+	"github.com/google/knative-gcp/pkg/utils/mainhelper"
+)
+
+const (
+	component = "channel-dispatcher"
+	// The delivery metrics of the channel subscribers are reported as those of triggers.
+	metricNamespace  = "trigger"
+	poolResyncPeriod = 15 * time.Second
+
+	// The retry pool health is checked on the port next to the fanout pool one.
+	retryHealthCheckPort = handler.DefaultHealthCheckPort + 1
+)
+
+type envConfig struct {
+	PodName                string `envconfig:"POD_NAME" required:"true"`
+	ProjectID              string `envconfig:"PROJECT_ID"`
+	TargetsConfigPath      string `envconfig:"TARGETS_CONFIG_PATH" default:"/var/run/cloud-run-events/channel/targets"`
+	HandlerConcurrency     int    `envconfig:"HANDLER_CONCURRENCY"`
+	MaxConcurrencyPerEvent int    `envconfig:"MAX_CONCURRENCY_PER_EVENT"`
+
+	// MaxStaleDuration is the max duration of the handler pools without being synced.
+	MaxStaleDuration time.Duration `envconfig:"MAX_STALE_DURATION" default:"1m"`
+
+	// MaxOutstandingBytes is the maximum size of unprocessed messages pulled from the channels.
+	MaxOutstandingBytes int `envconfig:"MAX_OUTSTANDING_BYTES" default:"800000000"`
+
+	// Outstanding messages and bytes pulled from the retry queue of each subscriber.
+	OutstandingMessagesPerSub int `envconfig:"OUTSTANDING_MESSAGES_PER_SUB" default:"100"`
+	OutstandingBytesPerSub    int `envconfig:"OUTSTANDING_BYTES_PER_SUB" default:"3000000"`
+
+	// Max to 10m.
+	TimeoutPerEvent time.Duration `envconfig:"TIMEOUT_PER_EVENT"`
+
+	// The circuit breakers of the subscribers, see the broker fanout.
+	CircuitBreakerFailureThreshold int           `envconfig:"CIRCUIT_BREAKER_FAILURE_THRESHOLD" default:"0"`
+	CircuitBreakerSlowThreshold    time.Duration `envconfig:"CIRCUIT_BREAKER_SLOW_THRESHOLD" default:"30s"`
+	CircuitBreakerOpenDuration     time.Duration `envconfig:"CIRCUIT_BREAKER_OPEN_DURATION" default:"30s"`
+}
+
+// main runs the shared channel dispatcher: a fanout pool delivering the events of the channels
+// to their subscribers, and a retry pool delivering them again from the retry queues of the
+// subscribers. Both share the targets config of the channels using the shared dispatcher.
+func main() {
+	appcredentials.MustExistOrUnsetEnv()
+
+	var env envConfig
+	ctx, res := mainhelper.Init(component, mainhelper.WithMetricNamespace(metricNamespace), mainhelper.WithEnv(&env))
+	defer res.Cleanup()
+	logger := res.Logger
+
+	if env.MaxStaleDuration > 0 && env.MaxStaleDuration < poolResyncPeriod {
+		logger.Fatalf("MAX_STALE_DURATION must be greater than pool resync period %v", poolResyncPeriod)
+	}
+
+	targetsUpdateCh := make(chan struct{})
+
+	logger.Info("Starting the channel dispatcher")
+
+	fanoutSignal, retrySignal := poolSyncSignals(ctx, targetsUpdateCh)
+	fanoutPool, retryPool, err := initializeSyncPools(ctx, env, []volume.Option{
+		volume.WithPath(env.TargetsConfigPath),
+		volume.WithNotifyChan(targetsUpdateCh),
+	})
+	if err != nil {
+		logger.Fatal("Failed to create dispatcher sync pools", zap.Error(err))
+	}
+	if _, err := handler.StartSyncPool(ctx, fanoutPool, fanoutSignal, env.MaxStaleDuration, handler.DefaultHealthCheckPort); err != nil {
+		logger.Fatalw("Failed to start fanout sync pool", zap.Error(err))
+	}
+	if _, err := handler.StartSyncPool(ctx, retryPool, retrySignal, env.MaxStaleDuration, retryHealthCheckPort); err != nil {
+		logger.Fatalw("Failed to start retry sync pool", zap.Error(err))
+	}
+
+	// Context will be done if a TERM signal is issued.
+	<-ctx.Done()
+	// Wait a grace period for the handlers to shutdown.
+	time.Sleep(30 * time.Second)
+	logger.Info("Done waiting, exit.")
+}
+
+// initializeSyncPools initializes the fanout and retry sync pools. They share the targets config
+// and the Pub/Sub client, but not their handler options.
+func initializeSyncPools(ctx context.Context, env envConfig, targetsVolumeOpts []volume.Option) (*handler.FanoutPool, *handler.RetryPool, error) {
+	projectID, err := utils.ProjectID(env.ProjectID, metadataClient.NewDefaultMetadataClient())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get default ProjectID: %w", err)
+	}
+	targets, err := volume.NewTargetsFromFile(targetsVolumeOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := pubsub.NewClient(ctx, projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	inbounds := handler.NewPubsubInboundFactory(client)
+	retryClient, err := handler.NewRetryClient(ctx, client, handler.DefaultCEClientOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	statsReporter, err := metrics.NewDeliveryReporter(metrics.PodName(env.PodName), metrics.ContainerName(component))
+	if err != nil {
+		return nil, nil, err
+	}
+	fanoutPool, err := handler.NewFanoutPool(targets, inbounds, handler.DefaultHTTPClient, retryClient, statsReporter, buildFanoutOptions(env)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	retryPool, err := handler.NewRetryPool(targets, inbounds, handler.DefaultHTTPClient, statsReporter, buildRetryOptions(env)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fanoutPool, retryPool, nil
+}
+
+// poolSyncSignals returns the sync signals of the fanout and retry pools, sent upon targets
+// config updates and every poolResyncPeriod.
+func poolSyncSignals(ctx context.Context, targetsUpdateCh chan struct{}) (chan struct{}, chan struct{}) {
+	fanoutCh := make(chan struct{}, 10)
+	retryCh := make(chan struct{}, 10)
+	ticker := time.NewTicker(poolResyncPeriod)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-targetsUpdateCh:
+			case <-ticker.C:
+			}
+			fanoutCh <- struct{}{}
+			retryCh <- struct{}{}
+		}
+	}()
+	return fanoutCh, retryCh
+}
+
+func buildFanoutOptions(env envConfig) []handler.Option {
+	rs := pubsub.DefaultReceiveSettings
+	var opts []handler.Option
+	if env.HandlerConcurrency > 0 {
+		opts = append(opts, handler.WithHandlerConcurrency(env.HandlerConcurrency))
+		rs.NumGoroutines = env.HandlerConcurrency
+	}
+	if env.MaxConcurrencyPerEvent > 0 {
+		opts = append(opts, handler.WithMaxConcurrentPerEvent(env.MaxConcurrencyPerEvent))
+	}
+	if env.TimeoutPerEvent > 0 {
+		opts = append(opts, handler.WithTimeoutPerEvent(env.TimeoutPerEvent))
+	}
+	opts = append(opts, handler.WithCircuitBreaker(breaker.Settings{
+		FailureThreshold: env.CircuitBreakerFailureThreshold,
+		SlowThreshold:    env.CircuitBreakerSlowThreshold,
+		OpenDuration:     env.CircuitBreakerOpenDuration,
+	}))
+	if env.MaxOutstandingBytes > 0 {
+		rs.MaxOutstandingBytes = env.MaxOutstandingBytes
+	}
+	opts = append(opts, handler.WithPubsubReceiveSettings(rs))
+	return opts
+}
+
+func buildRetryOptions(env envConfig) []handler.Option {
+	rs := pubsub.DefaultReceiveSettings
+	// As for the broker retry, pull the retry queues synchronously to bound the connections to
+	// each subscriber.
+	rs.Synchronous = true
+	rs.MaxOutstandingMessages = env.OutstandingMessagesPerSub
+	rs.MaxOutstandingBytes = env.OutstandingBytesPerSub
+	var opts []handler.Option
+	if env.HandlerConcurrency > 0 {
+		opts = append(opts, handler.WithHandlerConcurrency(env.HandlerConcurrency))
+		rs.NumGoroutines = env.HandlerConcurrency
+	}
+	if env.TimeoutPerEvent > 0 {
+		opts = append(opts, handler.WithTimeoutPerEvent(env.TimeoutPerEvent))
+	}
+	opts = append(opts, handler.WithPubsubReceiveSettings(rs))
+	return opts
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/google/knative-gcp/pkg/reconciler/events/storage"
 	kedapullsubscription "github.com/google/knative-gcp/pkg/reconciler/intevents/pullsubscription/keda"
 	staticpullsubscription "github.com/google/knative-gcp/pkg/reconciler/intevents/pullsubscription/static"
+	"github.com/google/knative-gcp/pkg/reconciler/intevents/redisstreamsource"
 	"github.com/google/knative-gcp/pkg/reconciler/intevents/topic"
 	"github.com/google/knative-gcp/pkg/reconciler/messaging/channel"
 	"github.com/google/knative-gcp/pkg/reconciler/trigger"
@@ -67,6 +68,7 @@ func Controllers(
 	brokerController broker.Constructor,
 	deploymentController deployment.Constructor,
 	brokercellController brokercell.Constructor,
+	redisstreamsourceController redisstreamsource.Constructor,
 ) []injection.ControllerConstructor {
 	return []injection.ControllerConstructor{
 		injection.ControllerConstructor(auditlogsController),
@@ -82,6 +84,7 @@ func Controllers(
 		injection.ControllerConstructor(brokerController),
 		injection.ControllerConstructor(deploymentController),
 		injection.ControllerConstructor(brokercellController),
+		injection.ControllerConstructor(redisstreamsourceController),
 	}
 }
 
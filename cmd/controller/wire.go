@@ -31,6 +31,7 @@ import (
 	"github.com/google/knative-gcp/pkg/reconciler/identity/iam"
 	"github.com/google/knative-gcp/pkg/reconciler/intevents/pullsubscription/keda"
 	"github.com/google/knative-gcp/pkg/reconciler/intevents/pullsubscription/static"
+	"github.com/google/knative-gcp/pkg/reconciler/intevents/redisstreamsource"
 	"github.com/google/knative-gcp/pkg/reconciler/intevents/topic"
 	"github.com/google/knative-gcp/pkg/reconciler/messaging/channel"
 	"github.com/google/knative-gcp/pkg/reconciler/trigger"
@@ -58,5 +59,6 @@ func InitializeControllers(ctx context.Context) ([]injection.ControllerConstruct
 		broker.NewConstructor,
 		deployment.NewConstructor,
 		brokercell.NewConstructor,
+		redisstreamsource.NewConstructor,
 	))
 }
@@ -62,6 +62,21 @@ type envConfig struct {
 	// Otherwise, only Sink is used (for either the sub.reply or sub.reply)
 	Transformer string `envconfig:"TRANSFORMER_URI"`
 
+	// Environment variable containing the URI the messages that could not be
+	// converted to events are sent to, wrapped in envelope events. They are
+	// dropped if it is empty.
+	UnconvertibleSink string `envconfig:"UNCONVERTIBLE_SINK_URI"`
+
+	// Environment variable specifying whether the data of the events is
+	// validated against the schema of their type. It is either empty, "flag"
+	// or "reject".
+	DataValidation string `envconfig:"DATA_VALIDATION"`
+
+	// Environment variable containing the JSON encoded glob patterns of the
+	// names of the objects whose Cloud Storage events are sent. The events of
+	// all the objects are sent if it is empty.
+	ObjectNameFilter string `envconfig:"OBJECT_NAME_FILTER"`
+
 	// Environment variable specifying the type of adapter to use.
 	// Used for CE conversion.
 	AdapterType string `envconfig:"ADAPTER_TYPE"`
@@ -163,14 +178,27 @@ func main() {
 		logger.Error("Failed to convert base64 extensions to map: %v", zap.Error(err))
 	}
 
+	dataValidation, err := converters.ParseDataValidation(env.DataValidation)
+	if err != nil {
+		logger.Fatal("Failed to parse data validation", zap.Error(err))
+	}
+
+	objectNameFilter, err := converters.ParseObjectNameFilter(env.ObjectNameFilter)
+	if err != nil {
+		logger.Fatal("Failed to parse object name filter", zap.Error(err))
+	}
+
 	logger.Info("Initializing adapter", zap.String("projectID", projectID), zap.String("topicID", env.Topic), zap.String("subscriptionID", env.Subscription))
 
 	args := &AdapterArgs{
-		TopicID:        env.Topic,
-		ConverterType:  converters.ConverterType(env.AdapterType),
-		SinkURI:        env.Sink,
-		TransformerURI: env.Transformer,
-		Extensions:     extensions,
+		TopicID:              env.Topic,
+		ConverterType:        converters.ConverterType(env.AdapterType),
+		SinkURI:              env.Sink,
+		TransformerURI:       env.Transformer,
+		Extensions:           extensions,
+		UnconvertibleSinkURI: env.UnconvertibleSink,
+		DataValidation:       dataValidation,
+		ObjectNameFilter:     objectNameFilter,
 	}
 
 	adapter, err := InitializeAdapter(ctx,
@@ -0,0 +1,176 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/tls"
+	"flag"
+	"fmt"
+
+	"github.com/kelseyhightower/envconfig"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/signals"
+
+	"github.com/google/knative-gcp/pkg/redis"
+	"github.com/google/knative-gcp/pkg/redisstream/adapter"
+	"github.com/google/knative-gcp/pkg/utils"
+	"github.com/google/knative-gcp/pkg/utils/clients"
+)
+
+const (
+	component = "redisstream_receive_adapter"
+
+	// TODO make this configurable
+	maxConnectionsPerHost = 1000
+)
+
+type envConfig struct {
+	// Environment variable containing the sink URI.
+	Sink string `envconfig:"SINK_URI" required:"true"`
+
+	// Environment variable containing the address of the Redis instance.
+	Address string `envconfig:"REDIS_ADDRESS" required:"true"`
+
+	// Environment variable containing the name of the stream.
+	Stream string `envconfig:"REDIS_STREAM" required:"true"`
+
+	// Environment variable containing the name of the consumer group.
+	Group string `envconfig:"REDIS_GROUP" required:"true"`
+
+	// Environment variable containing the number of times an entry is
+	// delivered before giving up on it.
+	MaxDeliveries int64 `envconfig:"REDIS_MAX_DELIVERIES"`
+
+	// Environment variable containing the name of the stream the entries
+	// given up on are added to.
+	DeadLetterStream string `envconfig:"REDIS_DEAD_LETTER_STREAM"`
+
+	// Environment variable containing the password of the Redis instance.
+	Password string `envconfig:"REDIS_PASSWORD"`
+
+	// Environment variable indicating whether to connect using TLS.
+	UseTLS bool `envconfig:"REDIS_TLS_ENABLED"`
+
+	// Environment variable indicating whether to skip the TLS verification.
+	SkipVerify bool `envconfig:"REDIS_TLS_SKIP_VERIFY"`
+
+	// Environment variable containing the PEM encoded client certificate.
+	Cert string `envconfig:"REDIS_TLS_CERT"`
+
+	// Environment variable containing the PEM encoded client key.
+	Key string `envconfig:"REDIS_TLS_KEY"`
+
+	// Environment variable containing the PEM encoded server CA certificate.
+	CACert string `envconfig:"REDIS_TLS_CA_CERTIFICATE"`
+
+	// Environment variable containing the name of the pod, used as the
+	// consumer name. Pods of the StatefulSet have stable names, so that a
+	// restarted pod redelivers its pending entries.
+	PodName string `envconfig:"POD_NAME" required:"true"`
+
+	// ExtensionsBase64 is a based64 encoded json string of a map of
+	// CloudEvents extensions (key-value pairs) override onto the outbound
+	// event.
+	ExtensionsBase64 string `envconfig:"K_CE_EXTENSIONS"`
+
+	// MetricsConfigJson is a json string of metrics.ExporterOptions.
+	MetricsConfigJson string `envconfig:"K_METRICS_CONFIG"`
+
+	// LoggingConfigJson is a json string of logging.Config.
+	LoggingConfigJson string `envconfig:"K_LOGGING_CONFIG"`
+}
+
+func main() {
+	flag.Parse()
+
+	var env envConfig
+	if err := envconfig.Process("", &env); err != nil {
+		panic(fmt.Sprintf("Failed to process env var: %s", err))
+	}
+
+	// Convert json logging.Config to logging.Config.
+	loggingConfig, err := logging.JsonToLoggingConfig(env.LoggingConfigJson)
+	if err != nil {
+		fmt.Printf("Failed to process logging config: %s", err.Error())
+		// Use default logging config.
+		if loggingConfig, err = logging.NewConfigFromMap(map[string]string{}); err != nil {
+			// If this fails, there is no recovering.
+			panic(err)
+		}
+	}
+
+	sl, _ := logging.NewLoggerFromConfig(loggingConfig, component)
+	logger := sl.Desugar()
+	defer flush(logger)
+	ctx := logging.WithLogger(signals.NewContext(), logger.Sugar())
+
+	// Convert json metrics.ExporterOptions to metrics.ExporterOptions.
+	metricsConfig, err := metrics.JsonToMetricsOptions(env.MetricsConfigJson)
+	if err != nil {
+		logger.Error("Failed to process metrics options", zap.Error(err))
+	}
+	if metricsConfig != nil {
+		if err := metrics.UpdateExporter(ctx, *metricsConfig, logger.Sugar()); err != nil {
+			logger.Fatal("Failed to create the metrics exporter", zap.Error(err))
+		}
+	}
+
+	var extensions map[string]string
+	if env.ExtensionsBase64 != "" {
+		// Convert base64 encoded json map to extensions map.
+		if extensions, err = utils.Base64ToMap(env.ExtensionsBase64); err != nil {
+			logger.Error("Failed to convert base64 extensions to map", zap.Error(err))
+		}
+	}
+
+	var tlsConfig *tls.Config
+	if env.UseTLS {
+		if tlsConfig, err = redis.TLSConfig(env.SkipVerify, env.Cert, env.Key, env.CACert); err != nil {
+			logger.Fatal("Failed to create the TLS configuration", zap.Error(err))
+		}
+	}
+	client := redis.NewClient(redis.Options{
+		Address:   env.Address,
+		Password:  env.Password,
+		TLSConfig: tlsConfig,
+	})
+	defer client.Close()
+
+	a := adapter.NewAdapter(ctx, client, clients.NewHTTPClient(ctx, maxConnectionsPerHost), &adapter.AdapterArgs{
+		Stream:           env.Stream,
+		Group:            env.Group,
+		Consumer:         env.PodName,
+		Source:           fmt.Sprintf("redis://%s/%s", env.Address, env.Stream),
+		SinkURI:          env.Sink,
+		Extensions:       extensions,
+		MaxDeliveries:    env.MaxDeliveries,
+		DeadLetterStream: env.DeadLetterStream,
+	})
+
+	logger.Info("Starting Receive Adapter.", zap.String("address", env.Address), zap.String("stream", env.Stream), zap.String("group", env.Group))
+	if err := a.Start(ctx); err != nil {
+		logger.Error("Adapter has stopped with error", zap.Error(err))
+	}
+	logger.Info("Exiting...")
+}
+
+func flush(logger *zap.Logger) {
+	_ = logger.Sync()
+	metrics.FlushExporter()
+}
@@ -55,7 +55,8 @@ import (
 
 var types = map[schema.GroupVersionKind]resourcesemantics.GenericCRD{
 	// For group eventing.knative.dev.
-	brokerv1beta1.SchemeGroupVersion.WithKind("Broker"): &brokerv1beta1.Broker{},
+	brokerv1beta1.SchemeGroupVersion.WithKind("Broker"):  &brokerv1beta1.Broker{},
+	brokerv1beta1.SchemeGroupVersion.WithKind("Trigger"): &brokerv1beta1.Trigger{},
 
 	// For group messaging.cloud.google.com.
 	messagingv1alpha1.SchemeGroupVersion.WithKind("Channel"): &messagingv1alpha1.Channel{},
@@ -79,13 +80,14 @@ var types = map[schema.GroupVersionKind]resourcesemantics.GenericCRD{
 	eventsv1.SchemeGroupVersion.WithKind("CloudBuildSource"):           &eventsv1.CloudBuildSource{},
 
 	// For group internal.events.cloud.google.com.
-	inteventsv1alpha1.SchemeGroupVersion.WithKind("PullSubscription"): &inteventsv1alpha1.PullSubscription{},
-	inteventsv1alpha1.SchemeGroupVersion.WithKind("Topic"):            &inteventsv1alpha1.Topic{},
-	inteventsv1beta1.SchemeGroupVersion.WithKind("PullSubscription"):  &inteventsv1beta1.PullSubscription{},
-	inteventsv1beta1.SchemeGroupVersion.WithKind("Topic"):             &inteventsv1beta1.Topic{},
-	inteventsv1.SchemeGroupVersion.WithKind("PullSubscription"):       &inteventsv1.PullSubscription{},
-	inteventsv1.SchemeGroupVersion.WithKind("Topic"):                  &inteventsv1.Topic{},
-	inteventsv1alpha1.SchemeGroupVersion.WithKind("BrokerCell"):       &inteventsv1alpha1.BrokerCell{},
+	inteventsv1alpha1.SchemeGroupVersion.WithKind("PullSubscription"):  &inteventsv1alpha1.PullSubscription{},
+	inteventsv1alpha1.SchemeGroupVersion.WithKind("Topic"):             &inteventsv1alpha1.Topic{},
+	inteventsv1beta1.SchemeGroupVersion.WithKind("PullSubscription"):   &inteventsv1beta1.PullSubscription{},
+	inteventsv1beta1.SchemeGroupVersion.WithKind("Topic"):              &inteventsv1beta1.Topic{},
+	inteventsv1.SchemeGroupVersion.WithKind("PullSubscription"):        &inteventsv1.PullSubscription{},
+	inteventsv1.SchemeGroupVersion.WithKind("Topic"):                   &inteventsv1.Topic{},
+	inteventsv1alpha1.SchemeGroupVersion.WithKind("BrokerCell"):        &inteventsv1alpha1.BrokerCell{},
+	inteventsv1alpha1.SchemeGroupVersion.WithKind("RedisStreamSource"): &inteventsv1alpha1.RedisStreamSource{},
 }
 
 type defaultingAdmissionController func(context.Context, configmap.Watcher) *controller.Impl
@@ -26,6 +26,24 @@ import (
 	"github.com/google/knative-gcp/pkg/apis/configs/broker"
 )
 
+// setDeliverySpecDefaults fills in the unset fields of the delivery spec from
+// the defaults for the namespace of the parent in the context.
+func setDeliverySpecDefaults(ctx context.Context, spec *eventingduckv1beta1.DeliverySpec, deliverySpecDefaults *broker.Defaults) {
+	ns := apis.ParentMeta(ctx).Namespace
+	if spec.BackoffPolicy == nil || spec.BackoffDelay == nil {
+		// Set both defaults if one of the backoff delay or backoff policy are not specified.
+		spec.BackoffPolicy = deliverySpecDefaults.BackoffPolicy(ns)
+		spec.BackoffDelay = deliverySpecDefaults.BackoffDelay(ns)
+	}
+	if spec.DeadLetterSink == nil {
+		spec.DeadLetterSink = deliverySpecDefaults.DeadLetterSink(ns)
+	}
+	if spec.Retry == nil && spec.DeadLetterSink != nil {
+		// Only set the retry count if a dead letter sink is specified.
+		spec.Retry = deliverySpecDefaults.Retry(ns)
+	}
+}
+
 // SetDefaults sets the default field values for a Broker.
 func (b *Broker) SetDefaults(ctx context.Context) {
 	// Apply the default Broker delivery settings from the context.
@@ -39,18 +57,6 @@ func (b *Broker) SetDefaults(ctx context.Context) {
 	if b.Spec.Delivery == nil {
 		b.Spec.Delivery = &eventingduckv1beta1.DeliverySpec{}
 	}
-	ns := apis.ParentMeta(withNS).Namespace
-	if b.Spec.Delivery.BackoffPolicy == nil || b.Spec.Delivery.BackoffDelay == nil {
-		// Set both defaults if one of the backoff delay or backoff policy are not specified.
-		b.Spec.Delivery.BackoffPolicy = deliverySpecDefaults.BackoffPolicy(ns)
-		b.Spec.Delivery.BackoffDelay = deliverySpecDefaults.BackoffDelay(ns)
-	}
-	if b.Spec.Delivery.DeadLetterSink == nil {
-		b.Spec.Delivery.DeadLetterSink = deliverySpecDefaults.DeadLetterSink(ns)
-	}
-	if b.Spec.Delivery.Retry == nil && b.Spec.Delivery.DeadLetterSink != nil {
-		// Only set the retry count if a dead letter sink is specified.
-		b.Spec.Delivery.Retry = deliverySpecDefaults.Retry(ns)
-	}
+	setDeliverySpecDefaults(withNS, b.Spec.Delivery, deliverySpecDefaults)
 	// Besides this, the eventing webhook will add the usual defaults.
 }
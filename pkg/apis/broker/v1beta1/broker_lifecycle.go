@@ -17,6 +17,8 @@ limitations under the License.
 package v1beta1
 
 import (
+	"time"
+
 	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
 	"knative.dev/pkg/apis"
 )
@@ -105,3 +107,37 @@ func (bs *BrokerStatus) MarkSubscriptionUnknown(reason, format string, args ...i
 func (bs *BrokerStatus) MarkSubscriptionReady() {
 	brokerCondSet.Manage(bs).MarkTrue(BrokerConditionSubscription)
 }
+
+// MarkReplayed records that the subscription has been seeked to the given replay time.
+func (bs *BrokerStatus) MarkReplayed(t time.Time) {
+	markReplayed(&bs.Status, t)
+}
+
+// ReplayedTime returns the replay time the subscription has last been seeked to, if any.
+func (bs *BrokerStatus) ReplayedTime() (time.Time, bool) {
+	return replayTime(bs.Annotations)
+}
+
+// MarkPriorityClasses records the names of the priority classes whose topics and subscriptions
+// exist and are fanned out.
+func (bs *BrokerStatus) MarkPriorityClasses(names []string) {
+	markPriorityClasses(&bs.Status, names)
+}
+
+// PriorityClasses returns the names of the priority classes whose topics and subscriptions
+// exist and are fanned out.
+func (bs *BrokerStatus) PriorityClasses() []string {
+	return markedPriorityClasses(&bs.Status)
+}
+
+// MarkDrainingPriorityClasses records the times the priority classes whose topics and
+// subscriptions still exist were removed from the broker at, by name.
+func (bs *BrokerStatus) MarkDrainingPriorityClasses(removed map[string]time.Time) {
+	markDrainingPriorityClasses(&bs.Status, removed)
+}
+
+// DrainingPriorityClasses returns the times the priority classes whose topics and subscriptions
+// still exist were removed from the broker at, by name.
+func (bs *BrokerStatus) DrainingPriorityClasses() map[string]time.Time {
+	return drainingPriorityClasses(&bs.Status)
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package v1beta1
 
 import (
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -30,6 +32,11 @@ const (
 	// BrokerClass is the annotation value to use when creating a
 	// Google Cloud Broker object.
 	BrokerClass = "googlecloud"
+
+	// OrderingAnnotationKey is the annotation of a Broker or a Trigger that, when "true",
+	// enables the ordered delivery of the events sharing the same "partitionkey" extension
+	// (see the CloudEvents partitioning extension). It can only be set at creation.
+	OrderingAnnotationKey = "events.cloud.google.com/ordering"
 )
 
 // +genclient
@@ -117,6 +124,26 @@ func (b *Broker) GetUntypedSpec() interface{} {
 	return b.Spec
 }
 
+// IsOrdered returns true if the Broker orders the events sharing a partition key.
+func (b *Broker) IsOrdered() bool {
+	return isOrdered(b.GetAnnotations())
+}
+
+func isOrdered(annotations map[string]string) bool {
+	return annotations[OrderingAnnotationKey] == "true"
+}
+
+// ReplayRetention returns how long the decouple subscription of the Broker retains the
+// acknowledged events, or 0 if they are not retained.
+func (b *Broker) ReplayRetention() time.Duration {
+	return replayRetention(b.GetAnnotations())
+}
+
+// ReplayTime returns the time the decouple subscription of the Broker is seeked to, if any.
+func (b *Broker) ReplayTime() (time.Time, bool) {
+	return replayTime(b.GetAnnotations())
+}
+
 // GetConditionSet retrieves the condition set for this resource. Implements the KRShaped interface.
 func (*Broker) GetConditionSet() apis.ConditionSet {
 	return brokerCondSet
@@ -18,21 +18,50 @@ package v1beta1
 
 import (
 	"context"
+	"fmt"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	eventingduckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 )
 
+const pubsubScheme = "pubsub"
+
 // Validate verifies that the Broker is valid.
 func (b *Broker) Validate(ctx context.Context) *apis.FieldError {
-	// We validate the GCP Broker's delivery spec. The eventing webhook will run
-	// the other usual validations.
-	if b.Spec.Delivery == nil {
-		return nil
+	// We validate the GCP Broker's delivery spec, ordering, priority classes and replay. The eventing
+	// webhook will run the other usual validations.
+	var errs *apis.FieldError
+	if b.Spec.Delivery != nil {
+		withNS := apis.AllowDifferentNamespace(apis.WithinParent(ctx, b.ObjectMeta))
+		errs = errs.Also(ValidateDeliverySpec(withNS, b.Spec.Delivery).ViaField("spec", "delivery"))
+	}
+	var original metav1.Object
+	if apis.IsInUpdate(ctx) {
+		original = apis.GetBaseline(ctx).(*Broker)
+	}
+	errs = errs.Also(validateOrderingAnnotation(b.GetAnnotations(), original).ViaField("metadata", "annotations"))
+	errs = errs.Also(validatePriorityClassesAnnotation(b.GetAnnotations()).ViaField("metadata", "annotations"))
+	return errs.Also(validateReplayAnnotations(b.GetAnnotations()).ViaField("metadata", "annotations"))
+}
+
+// validateOrderingAnnotation validates the ordering annotation, which can't change on update
+// since the ordering of a Pub/Sub subscription is immutable. original is the object before the
+// update, or nil on create.
+func validateOrderingAnnotation(annotations map[string]string, original metav1.Object) *apis.FieldError {
+	value, ok := annotations[OrderingAnnotationKey]
+	if ok && value != "true" && value != "false" {
+		return apis.ErrInvalidValue(value, OrderingAnnotationKey)
 	}
-	withNS := apis.AllowDifferentNamespace(apis.WithinParent(ctx, b.ObjectMeta))
-	return ValidateDeliverySpec(withNS, b.Spec.Delivery).ViaField("spec", "delivery")
+	if original != nil && isOrdered(annotations) != isOrdered(original.GetAnnotations()) {
+		return &apis.FieldError{
+			Message: "Immutable fields changed (-old +new)",
+			Paths:   []string{OrderingAnnotationKey},
+			Details: fmt.Sprintf("-%q +%q", original.GetAnnotations()[OrderingAnnotationKey], value),
+		}
+	}
+	return nil
 }
 
 func ValidateDeliverySpec(ctx context.Context, spec *eventingduckv1beta1.DeliverySpec) *apis.FieldError {
@@ -46,17 +75,28 @@ func ValidateDeliverySpec(ctx context.Context, spec *eventingduckv1beta1.Deliver
 	return errs.Also(ValidateDeadLetterSink(ctx, spec.DeadLetterSink).ViaField("deadLetterSink"))
 }
 
+// ValidateDeadLetterSink validates a dead letter sink. The sink is either a
+// Pub/Sub topic given as a pubsub://<topic> URI, or any Addressable or URL
+// that the broker delivers dead lettered events to.
 func ValidateDeadLetterSink(ctx context.Context, sink *duckv1.Destination) *apis.FieldError {
 	if sink == nil {
 		return nil
 	}
-	if sink.URI == nil {
-		return apis.ErrMissingField("uri")
+	if IsPubsubDeadLetterSink(sink) {
+		return validatePubsubDeadLetterSink(sink.URI)
 	}
-	if scheme := sink.URI.Scheme; scheme != "pubsub" {
-		return apis.ErrInvalidValue("Dead letter sink URI scheme should be pubsub", "uri")
-	}
-	topicID := sink.URI.Host
+	return sink.Validate(ctx)
+}
+
+// IsPubsubDeadLetterSink returns true if the dead letter sink is a Pub/Sub
+// topic, which is handled by a Pub/Sub dead letter policy rather than by the
+// broker data plane.
+func IsPubsubDeadLetterSink(sink *duckv1.Destination) bool {
+	return sink != nil && sink.URI != nil && sink.URI.Scheme == pubsubScheme
+}
+
+func validatePubsubDeadLetterSink(uri *apis.URL) *apis.FieldError {
+	topicID := uri.Host
 	if topicID == "" {
 		return apis.ErrInvalidValue("Dead letter topic must not be empty", "uri")
 	}
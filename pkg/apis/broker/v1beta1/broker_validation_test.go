@@ -61,7 +61,7 @@ func TestBroker_Validate(t *testing.T) {
 		},
 		want: apis.ErrMissingField("spec.delivery.backoffDelay"),
 	}, {
-		name: "invalid dead letter sink missing uri",
+		name: "invalid dead letter sink missing ref and uri",
 		broker: Broker{
 			Spec: v1beta1.BrokerSpec{
 				Delivery: &eventingduckv1beta1.DeliverySpec{
@@ -71,9 +71,42 @@ func TestBroker_Validate(t *testing.T) {
 				},
 			},
 		},
-		want: apis.ErrMissingField("spec.delivery.deadLetterSink.uri"),
+		want: apis.ErrGeneric("expected at least one, got none", "spec.delivery.deadLetterSink.ref", "spec.delivery.deadLetterSink.uri"),
 	}, {
-		name: "invalid dead letter sink uri scheme",
+		name: "invalid dead letter sink relative uri",
+		broker: Broker{
+			Spec: v1beta1.BrokerSpec{
+				Delivery: &eventingduckv1beta1.DeliverySpec{
+					BackoffDelay:  &bod,
+					BackoffPolicy: &bop,
+					DeadLetterSink: &duckv1.Destination{
+						URI: &apis.URL{
+							Path: "/dead-letter",
+						},
+					},
+				},
+			},
+		},
+		want: apis.ErrInvalidValue("Relative URI is not allowed when Ref and [apiVersion, kind, name] is absent", "spec.delivery.deadLetterSink.uri"),
+	}, {
+		name: "invalid dead letter sink ref missing name",
+		broker: Broker{
+			Spec: v1beta1.BrokerSpec{
+				Delivery: &eventingduckv1beta1.DeliverySpec{
+					BackoffDelay:  &bod,
+					BackoffPolicy: &bop,
+					DeadLetterSink: &duckv1.Destination{
+						Ref: &duckv1.KReference{
+							APIVersion: "serving.knative.dev/v1",
+							Kind:       "Service",
+						},
+					},
+				},
+			},
+		},
+		want: apis.ErrMissingField("spec.delivery.deadLetterSink.ref.name"),
+	}, {
+		name: "valid dead letter sink uri",
 		broker: Broker{
 			Spec: v1beta1.BrokerSpec{
 				Delivery: &eventingduckv1beta1.DeliverySpec{
@@ -82,13 +115,30 @@ func TestBroker_Validate(t *testing.T) {
 					DeadLetterSink: &duckv1.Destination{
 						URI: &apis.URL{
 							Scheme: "http",
-							Host:   "test-topic-id",
+							Host:   "dead-letter.example.com",
+						},
+					},
+				},
+			},
+		},
+	}, {
+		name: "valid dead letter sink ref",
+		broker: Broker{
+			Spec: v1beta1.BrokerSpec{
+				Delivery: &eventingduckv1beta1.DeliverySpec{
+					BackoffDelay:  &bod,
+					BackoffPolicy: &bop,
+					DeadLetterSink: &duckv1.Destination{
+						Ref: &duckv1.KReference{
+							APIVersion: "serving.knative.dev/v1",
+							Kind:       "Service",
+							Name:       "dead-letter",
+							Namespace:  "other-namespace",
 						},
 					},
 				},
 			},
 		},
-		want: apis.ErrInvalidValue("Dead letter sink URI scheme should be pubsub", "spec.delivery.deadLetterSink.uri"),
 	}, {
 		name: "invalid empty dead letter topic id",
 		broker: Broker{
@@ -150,3 +200,60 @@ func TestBroker_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestBroker_ValidateOrdering(t *testing.T) {
+	broker := func(ordering string) *Broker {
+		b := &Broker{}
+		if ordering != "" {
+			b.Annotations = map[string]string{OrderingAnnotationKey: ordering}
+		}
+		return b
+	}
+	tests := []struct {
+		name     string
+		broker   *Broker
+		original *Broker
+		wantErr  bool
+	}{{
+		name:   "ordered",
+		broker: broker("true"),
+	}, {
+		name:   "explicitly unordered",
+		broker: broker("false"),
+	}, {
+		name:    "invalid value",
+		broker:  broker("yes"),
+		wantErr: true,
+	}, {
+		name:     "unchanged on update",
+		broker:   broker("true"),
+		original: broker("true"),
+	}, {
+		name:     "unordered by default on update",
+		broker:   broker("false"),
+		original: broker(""),
+	}, {
+		name:     "enabled on update",
+		broker:   broker("true"),
+		original: broker(""),
+		wantErr:  true,
+	}, {
+		name:     "disabled on update",
+		broker:   broker(""),
+		original: broker("true"),
+		wantErr:  true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.original != nil {
+				ctx = apis.WithinUpdate(ctx, test.original)
+			}
+			got := test.broker.Validate(ctx)
+			if (got != nil) != test.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", got, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+const (
+	// PriorityClassesAnnotationKey is the annotation of a Broker holding its JSON encoded
+	// PriorityClasses. The events of each priority class are decoupled through their own Pub/Sub
+	// topic and subscription, and fanned out with their own concurrency, so that the events of a
+	// class are not delayed by the events of another. The events of no class are decoupled
+	// through the topic of the Broker. The names of the classes whose topics exist are set to the
+	// same annotation in the status, comma separated.
+	PriorityClassesAnnotationKey = "events.cloud.google.com/priority-classes"
+
+	// DrainingPriorityClassesAnnotationKey is the status annotation of a Broker holding the JSON
+	// encoded times the priority classes whose topics still exist were removed from the Broker
+	// at, by name. It is a status annotation rather than a status field because the eventing
+	// webhook rejects the status fields it doesn't know.
+	DrainingPriorityClassesAnnotationKey = "internal.events.cloud.google.com/draining-priority-classes"
+
+	// maxPriorityClassNameLength keeps the names of the Pub/Sub resources of the classes short.
+	maxPriorityClassNameLength = 20
+)
+
+// PriorityClasses routes the events of a Broker to priority classes by the value of one of their
+// attributes.
+type PriorityClasses struct {
+	// Attribute is the name of the CloudEvents context attribute or extension whose value selects
+	// the priority class of an event, e.g. "type".
+	Attribute string `json:"attribute"`
+
+	// Classes are the priority classes of the Broker.
+	Classes []PriorityClass `json:"classes"`
+}
+
+// PriorityClass is a class of events of a Broker decoupled and fanned out separately.
+type PriorityClass struct {
+	// Name is the name of the class, a DNS label of at most 20 characters.
+	Name string `json:"name"`
+
+	// Values are the values of the attribute of the events of the class.
+	Values []string `json:"values"`
+
+	// MaxConcurrency is the maximum number of events of the class each fanout pod handles at
+	// once. It defaults to the concurrency of the fanout.
+	// +optional
+	MaxConcurrency int32 `json:"maxConcurrency,omitempty"`
+}
+
+// PriorityClasses returns the priority classes of the Broker, or nil if it has none.
+func (b *Broker) PriorityClasses() *PriorityClasses {
+	pc, err := priorityClasses(b.GetAnnotations())
+	if err != nil {
+		return nil
+	}
+	return pc
+}
+
+func priorityClasses(annotations map[string]string) (*PriorityClasses, error) {
+	value, ok := annotations[PriorityClassesAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+	pc := &PriorityClasses{}
+	if err := json.Unmarshal([]byte(value), pc); err != nil {
+		return nil, err
+	}
+	return pc, nil
+}
+
+// validatePriorityClassesAnnotation validates the priority classes annotation.
+func validatePriorityClassesAnnotation(annotations map[string]string) *apis.FieldError {
+	pc, err := priorityClasses(annotations)
+	if err != nil {
+		return apis.ErrInvalidValue(annotations[PriorityClassesAnnotationKey], PriorityClassesAnnotationKey)
+	}
+	if pc == nil {
+		return nil
+	}
+	invalid := func(format string, args ...interface{}) *apis.FieldError {
+		return &apis.FieldError{
+			Message: "invalid priority classes",
+			Paths:   []string{PriorityClassesAnnotationKey},
+			Details: fmt.Sprintf(format, args...),
+		}
+	}
+	if pc.Attribute == "" {
+		return invalid("missing attribute")
+	}
+	if len(pc.Classes) == 0 {
+		return invalid("missing classes")
+	}
+	names := make(map[string]bool, len(pc.Classes))
+	values := make(map[string]string)
+	for _, c := range pc.Classes {
+		if errs := validation.IsDNS1123Label(c.Name); len(errs) != 0 || len(c.Name) > maxPriorityClassNameLength {
+			return invalid("class name %q must be a DNS label of at most %d characters", c.Name, maxPriorityClassNameLength)
+		}
+		if names[c.Name] {
+			return invalid("duplicate class %q", c.Name)
+		}
+		names[c.Name] = true
+		if len(c.Values) == 0 {
+			return invalid("class %q has no values", c.Name)
+		}
+		for _, v := range c.Values {
+			if other, ok := values[v]; ok {
+				return invalid("value %q is in classes %q and %q", v, other, c.Name)
+			}
+			values[v] = c.Name
+		}
+		if c.MaxConcurrency < 0 {
+			return invalid("class %q has a negative maxConcurrency", c.Name)
+		}
+	}
+	return nil
+}
+
+func markPriorityClasses(s *duckv1.Status, names []string) {
+	if len(names) == 0 {
+		delete(s.Annotations, PriorityClassesAnnotationKey)
+		return
+	}
+	if s.Annotations == nil {
+		s.Annotations = make(map[string]string, 1)
+	}
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+	s.Annotations[PriorityClassesAnnotationKey] = strings.Join(sorted, ",")
+}
+
+func markedPriorityClasses(s *duckv1.Status) []string {
+	value := s.Annotations[PriorityClassesAnnotationKey]
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+func markDrainingPriorityClasses(s *duckv1.Status, removed map[string]time.Time) {
+	if len(removed) == 0 {
+		delete(s.Annotations, DrainingPriorityClassesAnnotationKey)
+		return
+	}
+	if s.Annotations == nil {
+		s.Annotations = make(map[string]string, 1)
+	}
+	// Marshalling a map of times can't fail.
+	value, _ := json.Marshal(removed)
+	s.Annotations[DrainingPriorityClassesAnnotationKey] = string(value)
+}
+
+func drainingPriorityClasses(s *duckv1.Status) map[string]time.Time {
+	value, ok := s.Annotations[DrainingPriorityClassesAnnotationKey]
+	if !ok {
+		return nil
+	}
+	var removed map[string]time.Time
+	if err := json.Unmarshal([]byte(value), &removed); err != nil {
+		return nil
+	}
+	return removed
+}
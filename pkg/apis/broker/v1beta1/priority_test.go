@@ -0,0 +1,141 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBrokerPriorityClasses(t *testing.T) {
+	b := &Broker{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		PriorityClassesAnnotationKey: `{"attribute": "type", "classes": [{"name": "bulk", "values": ["com.example.audit"], "maxConcurrency": 10}]}`,
+	}}}
+	want := &PriorityClasses{
+		Attribute: "type",
+		Classes: []PriorityClass{{
+			Name:           "bulk",
+			Values:         []string{"com.example.audit"},
+			MaxConcurrency: 10,
+		}},
+	}
+	if diff := cmp.Diff(want, b.PriorityClasses()); diff != "" {
+		t.Errorf("PriorityClasses (-want, +got) = %v", diff)
+	}
+
+	b.Annotations[PriorityClassesAnnotationKey] = "bulk"
+	if got := b.PriorityClasses(); got != nil {
+		t.Errorf("PriorityClasses got=%v, want=nil", got)
+	}
+	if got := (&Broker{}).PriorityClasses(); got != nil {
+		t.Errorf("PriorityClasses got=%v, want=nil", got)
+	}
+}
+
+func TestValidatePriorityClassesAnnotation(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{{
+		name:  "valid",
+		value: `{"attribute": "priority", "classes": [{"name": "high", "values": ["high", "critical"]}, {"name": "low", "values": ["low"], "maxConcurrency": 5}]}`,
+	}, {
+		name:    "invalid json",
+		value:   "high,low",
+		wantErr: true,
+	}, {
+		name:    "missing attribute",
+		value:   `{"classes": [{"name": "high", "values": ["high"]}]}`,
+		wantErr: true,
+	}, {
+		name:    "missing classes",
+		value:   `{"attribute": "priority"}`,
+		wantErr: true,
+	}, {
+		name:    "invalid name",
+		value:   `{"attribute": "priority", "classes": [{"name": "High", "values": ["high"]}]}`,
+		wantErr: true,
+	}, {
+		name:    "name too long",
+		value:   `{"attribute": "priority", "classes": [{"name": "a-very-long-class-name", "values": ["high"]}]}`,
+		wantErr: true,
+	}, {
+		name:    "duplicate name",
+		value:   `{"attribute": "priority", "classes": [{"name": "high", "values": ["high"]}, {"name": "high", "values": ["critical"]}]}`,
+		wantErr: true,
+	}, {
+		name:    "missing values",
+		value:   `{"attribute": "priority", "classes": [{"name": "high"}]}`,
+		wantErr: true,
+	}, {
+		name:    "value in two classes",
+		value:   `{"attribute": "priority", "classes": [{"name": "high", "values": ["high"]}, {"name": "low", "values": ["high"]}]}`,
+		wantErr: true,
+	}, {
+		name:    "negative concurrency",
+		value:   `{"attribute": "priority", "classes": [{"name": "high", "values": ["high"], "maxConcurrency": -1}]}`,
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := &Broker{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				PriorityClassesAnnotationKey: test.value,
+			}}}
+			err := b.Validate(context.Background())
+			if test.wantErr != (err != nil) {
+				t.Errorf("Broker.Validate() = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestMarkPriorityClasses(t *testing.T) {
+	bs := &BrokerStatus{}
+	if got := bs.PriorityClasses(); got != nil {
+		t.Errorf("PriorityClasses got=%v, want=nil", got)
+	}
+	bs.MarkPriorityClasses([]string{"low", "high"})
+	if diff := cmp.Diff([]string{"high", "low"}, bs.PriorityClasses()); diff != "" {
+		t.Errorf("PriorityClasses (-want, +got) = %v", diff)
+	}
+	bs.MarkPriorityClasses(nil)
+	if got := bs.PriorityClasses(); got != nil {
+		t.Errorf("PriorityClasses got=%v, want=nil", got)
+	}
+}
+
+func TestMarkDrainingPriorityClasses(t *testing.T) {
+	bs := &BrokerStatus{}
+	if got := bs.DrainingPriorityClasses(); got != nil {
+		t.Errorf("DrainingPriorityClasses got=%v, want=nil", got)
+	}
+	removed := map[string]time.Time{"low": time.Date(2020, 8, 1, 10, 0, 0, 0, time.UTC)}
+	bs.MarkDrainingPriorityClasses(removed)
+	if diff := cmp.Diff(removed, bs.DrainingPriorityClasses()); diff != "" {
+		t.Errorf("DrainingPriorityClasses (-want, +got) = %v", diff)
+	}
+	bs.MarkDrainingPriorityClasses(nil)
+	if got := bs.DrainingPriorityClasses(); got != nil {
+		t.Errorf("DrainingPriorityClasses got=%v, want=nil", got)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"time"
+
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+const (
+	// ReplayRetentionAnnotationKey is the annotation of a Broker or a Trigger holding how long
+	// its Pub/Sub subscription retains the acknowledged events, e.g. "24h", so that they can be
+	// replayed. Acknowledged events are not retained when it is unset.
+	ReplayRetentionAnnotationKey = "events.cloud.google.com/replay-retention"
+
+	// ReplayTimeAnnotationKey is the annotation of a Broker or a Trigger holding an RFC 3339
+	// time. When it is set or changed, the Pub/Sub subscription is seeked to that time: the
+	// retained events published after it are delivered again. The same annotation is set in the
+	// status once the seek is complete.
+	ReplayTimeAnnotationKey = "events.cloud.google.com/replay-time"
+
+	// The bounds of the message retention duration of a Pub/Sub subscription.
+	minReplayRetention = 10 * time.Minute
+	maxReplayRetention = 7 * 24 * time.Hour
+
+	// maxReplayClockSkew is how far in the future a replay time can be, to allow for the clock
+	// skew between the client and the webhook. Seeking to a later time would acknowledge the
+	// events published until then.
+	maxReplayClockSkew = time.Minute
+)
+
+func replayRetention(annotations map[string]string) time.Duration {
+	d, err := time.ParseDuration(annotations[ReplayRetentionAnnotationKey])
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+func replayTime(annotations map[string]string) (time.Time, bool) {
+	value, ok := annotations[ReplayTimeAnnotationKey]
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// validateReplayAnnotations validates the replay retention and time annotations.
+func validateReplayAnnotations(annotations map[string]string) *apis.FieldError {
+	var errs *apis.FieldError
+	if value, ok := annotations[ReplayRetentionAnnotationKey]; ok {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(value, ReplayRetentionAnnotationKey))
+		} else if d < minReplayRetention || d > maxReplayRetention {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(value, minReplayRetention, maxReplayRetention, ReplayRetentionAnnotationKey))
+		}
+	}
+	if value, ok := annotations[ReplayTimeAnnotationKey]; ok {
+		if t, err := time.Parse(time.RFC3339, value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(value, ReplayTimeAnnotationKey))
+		} else if t.After(time.Now().Add(maxReplayClockSkew)) {
+			errs = errs.Also(&apis.FieldError{
+				Message: "replay time is in the future",
+				Paths:   []string{ReplayTimeAnnotationKey},
+				Details: "the events published until then would be skipped",
+			})
+		}
+	}
+	return errs
+}
+
+func markReplayed(s *duckv1.Status, t time.Time) {
+	if s.Annotations == nil {
+		s.Annotations = make(map[string]string, 1)
+	}
+	s.Annotations[ReplayTimeAnnotationKey] = t.Format(time.RFC3339Nano)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+func TestValidateReplayAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        *apis.FieldError
+	}{{
+		name: "no replay",
+	}, {
+		name: "valid",
+		annotations: map[string]string{
+			ReplayRetentionAnnotationKey: "24h",
+			ReplayTimeAnnotationKey:      "2020-09-01T10:00:00Z",
+		},
+	}, {
+		name:        "invalid retention",
+		annotations: map[string]string{ReplayRetentionAnnotationKey: "1 day"},
+		want:        apis.ErrInvalidValue("1 day", ReplayRetentionAnnotationKey),
+	}, {
+		name:        "retention too short",
+		annotations: map[string]string{ReplayRetentionAnnotationKey: "1m"},
+		want:        apis.ErrOutOfBoundsValue("1m", minReplayRetention, maxReplayRetention, ReplayRetentionAnnotationKey),
+	}, {
+		name:        "retention too long",
+		annotations: map[string]string{ReplayRetentionAnnotationKey: "169h"},
+		want:        apis.ErrOutOfBoundsValue("169h", minReplayRetention, maxReplayRetention, ReplayRetentionAnnotationKey),
+	}, {
+		name:        "within the clock skew",
+		annotations: map[string]string{ReplayTimeAnnotationKey: time.Now().Add(30 * time.Second).Format(time.RFC3339)},
+	}, {
+		name:        "future time",
+		annotations: map[string]string{ReplayTimeAnnotationKey: "2999-09-01T10:00:00Z"},
+		want: &apis.FieldError{
+			Message: "replay time is in the future",
+			Paths:   []string{ReplayTimeAnnotationKey},
+			Details: "the events published until then would be skipped",
+		},
+	}, {
+		name:        "invalid time",
+		annotations: map[string]string{ReplayTimeAnnotationKey: "2020-09-01 10:00"},
+		want:        apis.ErrInvalidValue("2020-09-01 10:00", ReplayTimeAnnotationKey),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := validateReplayAnnotations(test.annotations)
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("validateReplayAnnotations (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestValidateReplayAnnotationsVia(t *testing.T) {
+	annotations := map[string]string{ReplayTimeAnnotationKey: "yesterday"}
+	want := apis.ErrInvalidValue("yesterday", "metadata.annotations."+ReplayTimeAnnotationKey).Error()
+	b := &Broker{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+	if got := b.Validate(context.Background()); got.Error() != want {
+		t.Errorf("Broker.Validate() = %v, want %v", got, want)
+	}
+	tr := &Trigger{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+	if got := tr.Validate(context.Background()); got.Error() != want {
+		t.Errorf("Trigger.Validate() = %v, want %v", got, want)
+	}
+}
+
+func TestReplayTime(t *testing.T) {
+	b := &Broker{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		ReplayRetentionAnnotationKey: "2h",
+		ReplayTimeAnnotationKey:      "2020-09-01T12:00:00.5+02:00",
+	}}}
+	if got, want := b.ReplayRetention(), 2*time.Hour; got != want {
+		t.Errorf("ReplayRetention() = %v, want %v", got, want)
+	}
+	if _, ok := b.Status.ReplayedTime(); ok {
+		t.Error("ReplayedTime() ok before the seek")
+	}
+	replayTime, ok := b.ReplayTime()
+	if !ok {
+		t.Fatal("ReplayTime() not ok")
+	}
+	if want := time.Date(2020, 9, 1, 10, 0, 0, 5e8, time.UTC); !replayTime.Equal(want) {
+		t.Errorf("ReplayTime() = %v, want %v", replayTime, want)
+	}
+	b.Status.MarkReplayed(replayTime)
+	if got, ok := b.Status.ReplayedTime(); !ok || !got.Equal(replayTime) {
+		t.Errorf("ReplayedTime() = %v, %v, want %v", got, ok, replayTime)
+	}
+
+	tr := &Trigger{}
+	if got := tr.ReplayRetention(); got != 0 {
+		t.Errorf("ReplayRetention() = %v, want 0", got)
+	}
+	if _, ok := tr.ReplayTime(); ok {
+		t.Error("ReplayTime() ok without annotation")
+	}
+}
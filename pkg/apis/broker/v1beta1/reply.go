@@ -0,0 +1,136 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"net/url"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// ReplyPolicyAnnotationKey is the annotation of a Trigger holding what is done with the
+	// replies of its subscriber: they are sent to the reply destination with "forward", the
+	// default, and dropped with "drop".
+	ReplyPolicyAnnotationKey = "events.cloud.google.com/reply-policy"
+
+	// ReplyDestinationAnnotationKey is the annotation of a Trigger holding where the replies of
+	// its subscriber are sent instead of its Broker: either an absolute URI, or the name of
+	// another Broker in the namespace of the Trigger.
+	ReplyDestinationAnnotationKey = "events.cloud.google.com/reply-destination"
+
+	// ReplyAllowedTypesAnnotationKey is the annotation of a Trigger holding the comma separated
+	// types of the replies of its subscriber that are sent to the reply destination. The replies
+	// of other types are dropped. The replies are not filtered by type when it is unset.
+	ReplyAllowedTypesAnnotationKey = "events.cloud.google.com/reply-allowed-types"
+
+	// ReplyAllowedSourcesAnnotationKey is the annotation of a Trigger holding the comma separated
+	// sources of the replies of its subscriber that are sent to the reply destination. The
+	// replies from other sources are dropped. The replies are not filtered by source when it is
+	// unset.
+	ReplyAllowedSourcesAnnotationKey = "events.cloud.google.com/reply-allowed-sources"
+
+	// ReplyPolicyForward sends the replies to the reply destination.
+	ReplyPolicyForward = "forward"
+	// ReplyPolicyDrop drops the replies.
+	ReplyPolicyDrop = "drop"
+)
+
+// DropsReplies returns true if the replies of the Trigger subscriber are dropped.
+func (t *Trigger) DropsReplies() bool {
+	return t.GetAnnotations()[ReplyPolicyAnnotationKey] == ReplyPolicyDrop
+}
+
+// ReplyDestination returns the absolute URI, or else the name of the Broker in the namespace of
+// the Trigger, that the replies of the Trigger subscriber are sent to. Both are empty if the
+// replies are sent to the Broker of the Trigger.
+func (t *Trigger) ReplyDestination() (*apis.URL, string) {
+	value := t.GetAnnotations()[ReplyDestinationAnnotationKey]
+	if value == "" {
+		return nil, ""
+	}
+	if isURI(value) {
+		u, err := apis.ParseURL(value)
+		if err != nil {
+			return nil, ""
+		}
+		return u, ""
+	}
+	return nil, value
+}
+
+// ReplyAllowedTypes returns the types of the replies of the Trigger subscriber that are sent to
+// the reply destination, or nil if they are not filtered by type.
+func (t *Trigger) ReplyAllowedTypes() []string {
+	return splitAllowlist(t.GetAnnotations()[ReplyAllowedTypesAnnotationKey])
+}
+
+// ReplyAllowedSources returns the sources of the replies of the Trigger subscriber that are sent
+// to the reply destination, or nil if they are not filtered by source.
+func (t *Trigger) ReplyAllowedSources() []string {
+	return splitAllowlist(t.GetAnnotations()[ReplyAllowedSourcesAnnotationKey])
+}
+
+// isURI tells apart a destination URI from a Broker name, which has no scheme.
+func isURI(destination string) bool {
+	return strings.Contains(destination, "://")
+}
+
+func splitAllowlist(value string) []string {
+	var allowlist []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			allowlist = append(allowlist, v)
+		}
+	}
+	return allowlist
+}
+
+// validateReplyAnnotations validates the reply policy, destination and allowlist annotations.
+func validateReplyAnnotations(annotations map[string]string) *apis.FieldError {
+	var errs *apis.FieldError
+	policy, ok := annotations[ReplyPolicyAnnotationKey]
+	if ok && policy != ReplyPolicyForward && policy != ReplyPolicyDrop {
+		errs = errs.Also(apis.ErrInvalidValue(policy, ReplyPolicyAnnotationKey))
+	}
+	if destination, ok := annotations[ReplyDestinationAnnotationKey]; ok {
+		if isURI(destination) {
+			if u, err := url.Parse(destination); err != nil || !u.IsAbs() || u.Host == "" {
+				errs = errs.Also(apis.ErrInvalidValue(destination, ReplyDestinationAnnotationKey))
+			}
+		} else if msgs := validation.IsDNS1123Subdomain(destination); len(msgs) != 0 {
+			errs = errs.Also(apis.ErrInvalidValue(destination, ReplyDestinationAnnotationKey))
+		}
+	}
+	var set []string
+	for _, key := range []string{ReplyDestinationAnnotationKey, ReplyAllowedTypesAnnotationKey, ReplyAllowedSourcesAnnotationKey} {
+		value, ok := annotations[key]
+		if !ok {
+			continue
+		}
+		set = append(set, key)
+		if key != ReplyDestinationAnnotationKey && len(splitAllowlist(value)) == 0 {
+			errs = errs.Also(apis.ErrInvalidValue(value, key))
+		}
+	}
+	if policy == ReplyPolicyDrop && len(set) != 0 {
+		errs = errs.Also(apis.ErrGeneric("must not be set when the replies are dropped", set...))
+	}
+	return errs
+}
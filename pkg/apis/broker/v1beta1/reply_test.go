@@ -0,0 +1,120 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+func TestValidateReplyAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        *apis.FieldError
+	}{{
+		name: "no reply policy",
+	}, {
+		name: "valid forward to broker",
+		annotations: map[string]string{
+			ReplyPolicyAnnotationKey:         ReplyPolicyForward,
+			ReplyDestinationAnnotationKey:    "replies",
+			ReplyAllowedTypesAnnotationKey:   "com.example.a, com.example.b",
+			ReplyAllowedSourcesAnnotationKey: "/example",
+		},
+	}, {
+		name:        "valid forward to URI",
+		annotations: map[string]string{ReplyDestinationAnnotationKey: "http://replies.example.svc.cluster.local"},
+	}, {
+		name:        "valid drop",
+		annotations: map[string]string{ReplyPolicyAnnotationKey: ReplyPolicyDrop},
+	}, {
+		name:        "invalid policy",
+		annotations: map[string]string{ReplyPolicyAnnotationKey: "validate"},
+		want:        apis.ErrInvalidValue("validate", ReplyPolicyAnnotationKey),
+	}, {
+		name:        "invalid broker name",
+		annotations: map[string]string{ReplyDestinationAnnotationKey: "Replies"},
+		want:        apis.ErrInvalidValue("Replies", ReplyDestinationAnnotationKey),
+	}, {
+		name:        "relative URI",
+		annotations: map[string]string{ReplyDestinationAnnotationKey: "http:///replies"},
+		want:        apis.ErrInvalidValue("http:///replies", ReplyDestinationAnnotationKey),
+	}, {
+		name:        "empty allowlist",
+		annotations: map[string]string{ReplyAllowedTypesAnnotationKey: " , "},
+		want:        apis.ErrInvalidValue(" , ", ReplyAllowedTypesAnnotationKey),
+	}, {
+		name: "drop with destination and allowlist",
+		annotations: map[string]string{
+			ReplyPolicyAnnotationKey:         ReplyPolicyDrop,
+			ReplyDestinationAnnotationKey:    "replies",
+			ReplyAllowedSourcesAnnotationKey: "/example",
+		},
+		want: apis.ErrGeneric("must not be set when the replies are dropped",
+			ReplyDestinationAnnotationKey, ReplyAllowedSourcesAnnotationKey),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := validateReplyAnnotations(test.annotations)
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("validateReplyAnnotations (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestTriggerReplyPolicy(t *testing.T) {
+	tr := &Trigger{}
+	if tr.DropsReplies() {
+		t.Error("DropsReplies() of Trigger without annotations got=true, want=false")
+	}
+	if u, b := tr.ReplyDestination(); u != nil || b != "" {
+		t.Errorf("ReplyDestination() of Trigger without annotations got=(%v, %q), want=(nil, \"\")", u, b)
+	}
+	if got := tr.ReplyAllowedTypes(); got != nil {
+		t.Errorf("ReplyAllowedTypes() of Trigger without annotations got=%v, want=nil", got)
+	}
+
+	tr.ObjectMeta = metav1.ObjectMeta{Annotations: map[string]string{
+		ReplyPolicyAnnotationKey:         ReplyPolicyDrop,
+		ReplyDestinationAnnotationKey:    "replies",
+		ReplyAllowedTypesAnnotationKey:   "com.example.a, com.example.b,",
+		ReplyAllowedSourcesAnnotationKey: "/example",
+	}}
+	if !tr.DropsReplies() {
+		t.Error("DropsReplies() got=false, want=true")
+	}
+	if u, b := tr.ReplyDestination(); u != nil || b != "replies" {
+		t.Errorf("ReplyDestination() got=(%v, %q), want=(nil, \"replies\")", u, b)
+	}
+	if diff := cmp.Diff([]string{"com.example.a", "com.example.b"}, tr.ReplyAllowedTypes()); diff != "" {
+		t.Errorf("ReplyAllowedTypes() (-want, +got) = %v", diff)
+	}
+	if diff := cmp.Diff([]string{"/example"}, tr.ReplyAllowedSources()); diff != "" {
+		t.Errorf("ReplyAllowedSources() (-want, +got) = %v", diff)
+	}
+
+	tr.Annotations[ReplyDestinationAnnotationKey] = "http://replies.example.svc.cluster.local"
+	if u, b := tr.ReplyDestination(); u.String() != "http://replies.example.svc.cluster.local" || b != "" {
+		t.Errorf("ReplyDestination() got=(%v, %q), want=(http://replies.example.svc.cluster.local, \"\")", u, b)
+	}
+}
@@ -18,10 +18,36 @@ package v1beta1
 
 import (
 	"context"
+	"encoding/json"
+
+	"go.uber.org/zap"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/logging"
+
+	"github.com/google/knative-gcp/pkg/apis/configs/broker"
 )
 
 // SetDefaults sets the default field values for a Trigger.
 func (t *Trigger) SetDefaults(ctx context.Context) {
-	// The Google Cloud Broker doesn't have any custom defaults. The
-	// eventing webhook will add the usual defaults.
+	// A Trigger without a delivery spec uses the one of its Broker, so only
+	// default the delivery spec if the Trigger overrides it. The eventing
+	// webhook will add the usual defaults.
+	spec, err := deliverySpec(t.GetAnnotations())
+	if spec == nil || err != nil {
+		// An invalid delivery annotation is rejected by the validation.
+		return
+	}
+	withNS := apis.WithinParent(ctx, t.ObjectMeta)
+	deliverySpecDefaults := broker.FromContextOrDefaults(withNS).BrokerDeliverySpecDefaults
+	if deliverySpecDefaults == nil {
+		logging.FromContext(ctx).Error("Failed to get the BrokerDeliverySpecDefaults")
+		return
+	}
+	setDeliverySpecDefaults(withNS, spec, deliverySpecDefaults)
+	value, err := json.Marshal(spec)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to encode the defaulted delivery spec", zap.Error(err))
+		return
+	}
+	t.Annotations[DeliveryAnnotationKey] = string(value)
 }
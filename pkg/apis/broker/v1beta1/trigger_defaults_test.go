@@ -18,10 +18,101 @@ package v1beta1
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	eventingduckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"github.com/google/knative-gcp/pkg/apis/configs/broker"
 )
 
 func TestTrigger_SetDefaults(t *testing.T) {
-	trig := Trigger{}
-	trig.SetDefaults(context.TODO())
+	testCases := map[string]struct {
+		initial  Trigger
+		expected Trigger
+	}{
+		"no delivery spec": {},
+		"invalid delivery spec left to the validation": {
+			initial: Trigger{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{DeliveryAnnotationKey: "not json"},
+				},
+			},
+			expected: Trigger{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{DeliveryAnnotationKey: "not json"},
+				},
+			},
+		},
+		"default everything from cluster": {
+			initial: Trigger{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{DeliveryAnnotationKey: "{}"},
+				},
+			},
+			expected: Trigger{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: deliveryAnnotations(t, &eventingduckv1beta1.DeliverySpec{
+						BackoffDelay:  &clusterDefaultedBackoffDelay,
+						BackoffPolicy: &clusterDefaultedBackoffPolicy,
+						DeadLetterSink: &duckv1.Destination{
+							URI: &apis.URL{
+								Scheme: "pubsub",
+								Host:   "cluster-default-dead-letter-topic-id",
+							},
+						},
+						Retry: &clusterDefaultedRetry,
+					}),
+				},
+			},
+		},
+		"default backoff from namespace, keep custom retry and dead letter sink": {
+			initial: Trigger{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "mynamespace",
+					Annotations: deliveryAnnotations(t, &eventingduckv1beta1.DeliverySpec{
+						DeadLetterSink: &duckv1.Destination{
+							URI: apis.HTTP("dead-letter.example.com"),
+						},
+						Retry: &customRetry,
+					}),
+				},
+			},
+			expected: Trigger{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "mynamespace",
+					Annotations: deliveryAnnotations(t, &eventingduckv1beta1.DeliverySpec{
+						BackoffDelay:  &nsDefaultedBackoffDelay,
+						BackoffPolicy: &nsDefaultedBackoffPolicy,
+						DeadLetterSink: &duckv1.Destination{
+							URI: apis.HTTP("dead-letter.example.com"),
+						},
+						Retry: &customRetry,
+					}),
+				},
+			},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			tc.initial.SetDefaults(broker.ToContext(context.Background(), defaultConfig))
+			if diff := cmp.Diff(tc.expected, tc.initial); diff != "" {
+				t.Fatalf("Unexpected defaults (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+// deliveryAnnotations returns the annotations holding the given Trigger delivery spec.
+func deliveryAnnotations(t *testing.T, spec *eventingduckv1beta1.DeliverySpec) map[string]string {
+	t.Helper()
+	value, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatalf("Failed to encode the delivery spec: %v", err)
+	}
+	return map[string]string{DeliveryAnnotationKey: string(value)}
 }
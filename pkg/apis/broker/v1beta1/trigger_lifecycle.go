@@ -17,6 +17,8 @@ limitations under the License.
 package v1beta1
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
 	"knative.dev/pkg/apis"
@@ -29,11 +31,16 @@ var triggerCondSet = apis.NewLivingConditionSet(
 	eventingv1beta1.TriggerConditionSubscriberResolved,
 	TriggerConditionTopic,
 	TriggerConditionSubscription,
+	TriggerConditionDeadLetterSinkResolved,
 )
 
 const (
 	TriggerConditionTopic        apis.ConditionType = "TopicReady"
 	TriggerConditionSubscription apis.ConditionType = "SubscriptionReady"
+
+	// TriggerConditionDeadLetterSinkResolved reports whether the dead letter
+	// sink of the Trigger, if any, has been resolved.
+	TriggerConditionDeadLetterSinkResolved apis.ConditionType = "DeadLetterSinkResolved"
 )
 
 // GetCondition returns the condition currently associated with the given type, or nil.
@@ -159,3 +166,40 @@ func (ts *TriggerStatus) PropagateDependencyStatus(ks *duckv1.KResource) {
 		ts.MarkDependencyUnknown("DependencyUnknown", "The status of Dependency is invalid: %v", kc.Status)
 	}
 }
+
+// MarkDeadLetterSinkResolvedSucceeded marks the dead letter sink as resolved
+// and records its URI. An empty URI means events are not dead lettered by the
+// broker, either because there is no dead letter sink or because it is a
+// Pub/Sub topic.
+func (ts *TriggerStatus) MarkDeadLetterSinkResolvedSucceeded(uri *apis.URL) {
+	if uri == nil {
+		delete(ts.Annotations, DeadLetterSinkURIAnnotation)
+	} else {
+		if ts.Annotations == nil {
+			ts.Annotations = make(map[string]string, 1)
+		}
+		ts.Annotations[DeadLetterSinkURIAnnotation] = uri.String()
+	}
+	triggerCondSet.Manage(ts).MarkTrue(TriggerConditionDeadLetterSinkResolved)
+}
+
+func (ts *TriggerStatus) MarkDeadLetterSinkResolvedFailed(reason, messageFormat string, messageA ...interface{}) {
+	delete(ts.Annotations, DeadLetterSinkURIAnnotation)
+	triggerCondSet.Manage(ts).MarkFalse(TriggerConditionDeadLetterSinkResolved, reason, messageFormat, messageA...)
+}
+
+// DeadLetterSinkURI returns the resolved URI of the addressable dead letter
+// sink, or an empty string if the broker does not dead letter events itself.
+func (ts *TriggerStatus) DeadLetterSinkURI() string {
+	return ts.Annotations[DeadLetterSinkURIAnnotation]
+}
+
+// MarkReplayed records that the subscription has been seeked to the given replay time.
+func (ts *TriggerStatus) MarkReplayed(t time.Time) {
+	markReplayed(&ts.Status, t)
+}
+
+// ReplayedTime returns the replay time the subscription has last been seeked to, if any.
+func (ts *TriggerStatus) ReplayedTime() (time.Time, bool) {
+	return replayTime(ts.Annotations)
+}
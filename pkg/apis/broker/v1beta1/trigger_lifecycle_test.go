@@ -147,6 +147,9 @@ func TestTriggerInitializeConditions(t *testing.T) {
 					Conditions: []apis.Condition{{
 						Type:   eventingv1beta1.TriggerConditionBroker,
 						Status: corev1.ConditionUnknown,
+					}, {
+						Type:   TriggerConditionDeadLetterSinkResolved,
+						Status: corev1.ConditionUnknown,
 					}, {
 						Type:   eventingv1beta1.TriggerConditionDependency,
 						Status: corev1.ConditionUnknown,
@@ -184,6 +187,9 @@ func TestTriggerInitializeConditions(t *testing.T) {
 					Conditions: []apis.Condition{{
 						Type:   eventingv1beta1.TriggerConditionBroker,
 						Status: corev1.ConditionFalse,
+					}, {
+						Type:   TriggerConditionDeadLetterSinkResolved,
+						Status: corev1.ConditionUnknown,
 					}, {
 						Type:   eventingv1beta1.TriggerConditionDependency,
 						Status: corev1.ConditionUnknown,
@@ -221,6 +227,9 @@ func TestTriggerInitializeConditions(t *testing.T) {
 					Conditions: []apis.Condition{{
 						Type:   eventingv1beta1.TriggerConditionBroker,
 						Status: corev1.ConditionTrue,
+					}, {
+						Type:   TriggerConditionDeadLetterSinkResolved,
+						Status: corev1.ConditionUnknown,
 					}, {
 						Type:   eventingv1beta1.TriggerConditionDependency,
 						Status: corev1.ConditionUnknown,
@@ -405,6 +414,7 @@ func TestTriggerConditionStatus(t *testing.T) {
 			} else {
 				ts.MarkSubscriberResolvedUnknown("Status of Subscriber URI is unknown", "induced failure")
 			}
+			ts.MarkDeadLetterSinkResolvedSucceeded(nil)
 			if test.dependencyStatus == nil {
 				ts.MarkDependencySucceeded()
 			} else {
@@ -428,3 +438,30 @@ func TestTriggerConditionStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestTriggerDeadLetterSinkResolved(t *testing.T) {
+	ts := &TriggerStatus{}
+	ts.InitializeConditions()
+
+	uri := apis.HTTP("dead-letter.example.com")
+	ts.MarkDeadLetterSinkResolvedSucceeded(uri)
+	if got := ts.DeadLetterSinkURI(); got != uri.String() {
+		t.Errorf("unexpected dead letter sink URI: want %q, got %q", uri.String(), got)
+	}
+	if got := ts.GetCondition(TriggerConditionDeadLetterSinkResolved).Status; got != corev1.ConditionTrue {
+		t.Errorf("unexpected condition status: want %v, got %v", corev1.ConditionTrue, got)
+	}
+
+	ts.MarkDeadLetterSinkResolvedFailed("Unable to get the dead letter sink's URI", "induced failure")
+	if got := ts.DeadLetterSinkURI(); got != "" {
+		t.Errorf("unexpected dead letter sink URI: want empty, got %q", got)
+	}
+	if got := ts.GetCondition(TriggerConditionDeadLetterSinkResolved).Status; got != corev1.ConditionFalse {
+		t.Errorf("unexpected condition status: want %v, got %v", corev1.ConditionFalse, got)
+	}
+
+	ts.MarkDeadLetterSinkResolvedSucceeded(nil)
+	if got := ts.DeadLetterSinkURI(); got != "" {
+		t.Errorf("unexpected dead letter sink URI: want empty, got %q", got)
+	}
+}
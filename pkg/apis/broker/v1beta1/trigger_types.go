@@ -17,9 +17,13 @@ limitations under the License.
 package v1beta1
 
 import (
+	"encoding/json"
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	eventingduckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
 	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
@@ -33,6 +37,33 @@ const (
 	// InjectionAnnotation is the annotation key used to enable knative eventing injection for a namespace and automatically create a default broker.
 	// This will be used when the client creates a trigger paired with default broker and the default broker doesn't exist in the namespace
 	InjectionAnnotation = "knative-eventing-injection"
+
+	// DeadLetterSinkURIAnnotation is the Trigger status annotation holding the
+	// resolved URI of an addressable dead letter sink. It is a status
+	// annotation rather than a status field because the eventing webhook
+	// rejects the status fields it doesn't know.
+	DeadLetterSinkURIAnnotation = "internal.events.cloud.google.com/dead-letter-sink-uri"
+
+	// DedupWindowAnnotationKey is the annotation of a Trigger holding the window, e.g. "10m",
+	// within which the events with the same source and id are delivered to its subscriber only
+	// once. Events are not deduplicated when it is unset.
+	DedupWindowAnnotationKey = "events.cloud.google.com/dedup-window"
+
+	// DeliveryAnnotationKey is the annotation of a Trigger holding its JSON encoded delivery
+	// spec, which overrides the delivery spec of its Broker. It is an annotation rather than a
+	// spec field because the eventing webhook, which also handles Triggers, rejects the unknown
+	// spec fields.
+	DeliveryAnnotationKey = "events.cloud.google.com/delivery"
+
+	// FiltersAnnotationKey is the annotation of a Trigger holding its JSON encoded list of
+	// SubscriptionsAPIFilter, which must all match for an event to be delivered to the subscriber.
+	// When set, it takes precedence over the spec filter. Like the delivery spec, it is an
+	// annotation because the eventing webhook rejects the unknown spec fields.
+	FiltersAnnotationKey = "events.cloud.google.com/filters"
+
+	// The bounds of the deduplication window.
+	minDedupWindow = time.Second
+	maxDedupWindow = 24 * time.Hour
 )
 
 // +genclient
@@ -72,6 +103,38 @@ var (
 	_ duckv1.KRShaped = (*Trigger)(nil)
 )
 
+// SubscriptionsAPIFilter is a filter expression over the CloudEvents
+// attributes of an event. Exactly one of its fields must be set.
+type SubscriptionsAPIFilter struct {
+	// All evaluates to true if all of the nested filters evaluate to true.
+	// +optional
+	All []SubscriptionsAPIFilter `json:"all,omitempty"`
+
+	// Any evaluates to true if at least one of the nested filters evaluates
+	// to true.
+	// +optional
+	Any []SubscriptionsAPIFilter `json:"any,omitempty"`
+
+	// Not evaluates to true if the nested filter evaluates to false.
+	// +optional
+	Not *SubscriptionsAPIFilter `json:"not,omitempty"`
+
+	// Exact evaluates to true if the value of each attribute matches the
+	// given value exactly.
+	// +optional
+	Exact map[string]string `json:"exact,omitempty"`
+
+	// Prefix evaluates to true if the value of each attribute starts with
+	// the given value.
+	// +optional
+	Prefix map[string]string `json:"prefix,omitempty"`
+
+	// Suffix evaluates to true if the value of each attribute ends with the
+	// given value.
+	// +optional
+	Suffix map[string]string `json:"suffix,omitempty"`
+}
+
 // TriggerStatus represents the current state of a Trigger.
 type TriggerStatus struct {
 	eventingv1beta1.TriggerStatus `json:",inline"`
@@ -114,6 +177,68 @@ func (t *Trigger) GetUntypedSpec() interface{} {
 	return t.Spec
 }
 
+// DeliverySpec returns the delivery spec that applies to the Trigger: its own
+// if set, otherwise the one of the given Broker.
+func (t *Trigger) DeliverySpec(b *Broker) *eventingduckv1beta1.DeliverySpec {
+	if spec, err := deliverySpec(t.GetAnnotations()); err == nil && spec != nil {
+		return spec
+	}
+	return b.Spec.Delivery
+}
+
+// deliverySpec returns the delivery spec in the delivery annotation, or nil if it is unset.
+func deliverySpec(annotations map[string]string) (*eventingduckv1beta1.DeliverySpec, error) {
+	value, ok := annotations[DeliveryAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+	spec := &eventingduckv1beta1.DeliverySpec{}
+	if err := json.Unmarshal([]byte(value), spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// Filters returns the filters in the filters annotation of the Trigger, or nil if it is unset.
+func (t *Trigger) Filters() ([]SubscriptionsAPIFilter, error) {
+	value, ok := t.GetAnnotations()[FiltersAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+	var filters []SubscriptionsAPIFilter
+	if err := json.Unmarshal([]byte(value), &filters); err != nil {
+		return nil, err
+	}
+	return filters, nil
+}
+
+// IsOrdered returns true if the events sharing a partition key are delivered to the Trigger
+// subscriber in order. They are only in order if the Broker orders them too.
+func (t *Trigger) IsOrdered() bool {
+	return isOrdered(t.GetAnnotations())
+}
+
+// DedupWindow returns the window within which the events with the same source and id are
+// delivered only once to the Trigger subscriber, or 0 if they are not deduplicated.
+func (t *Trigger) DedupWindow() time.Duration {
+	d, err := time.ParseDuration(t.GetAnnotations()[DedupWindowAnnotationKey])
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// ReplayRetention returns how long the retry subscription of the Trigger retains the
+// acknowledged events, or 0 if they are not retained.
+func (t *Trigger) ReplayRetention() time.Duration {
+	return replayRetention(t.GetAnnotations())
+}
+
+// ReplayTime returns the time the retry subscription of the Trigger is seeked to, if any.
+func (t *Trigger) ReplayTime() (time.Time, bool) {
+	return replayTime(t.GetAnnotations())
+}
+
 // GetConditionSet retrieves the condition set for this resource. Implements the KRShaped interface.
 func (*Trigger) GetConditionSet() apis.ConditionSet {
 	return triggerCondSet
@@ -21,6 +21,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	eventingduckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
 	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
 	"knative.dev/pkg/apis"
 )
@@ -48,6 +49,53 @@ func TestTrigger_GetUntypedSpec(t *testing.T) {
 	}
 }
 
+func TestTrigger_DeliverySpec(t *testing.T) {
+	brokerRetry, triggerRetry := int32(3), int32(10)
+	b := &Broker{
+		Spec: eventingv1beta1.BrokerSpec{
+			Delivery: &eventingduckv1beta1.DeliverySpec{Retry: &brokerRetry},
+		},
+	}
+
+	tr := &Trigger{}
+	if got := tr.DeliverySpec(b); got != b.Spec.Delivery {
+		t.Errorf("DeliverySpec=%v, want the broker's %v", got, b.Spec.Delivery)
+	}
+
+	want := &eventingduckv1beta1.DeliverySpec{Retry: &triggerRetry}
+	tr.Annotations = deliveryAnnotations(t, want)
+	if diff := cmp.Diff(want, tr.DeliverySpec(b)); diff != "" {
+		t.Errorf("DeliverySpec (-want,+got): %v", diff)
+	}
+
+	tr.Annotations[DeliveryAnnotationKey] = "not json"
+	if got := tr.DeliverySpec(b); got != b.Spec.Delivery {
+		t.Errorf("DeliverySpec=%v, want the broker's %v", got, b.Spec.Delivery)
+	}
+}
+
+func TestTrigger_Filters(t *testing.T) {
+	tr := &Trigger{}
+	if got, err := tr.Filters(); got != nil || err != nil {
+		t.Errorf("Filters=%v, %v, want nil, nil", got, err)
+	}
+
+	want := []SubscriptionsAPIFilter{{Prefix: map[string]string{"type": "foo."}}}
+	tr.Annotations = filtersAnnotations(t, want)
+	got, err := tr.Filters()
+	if err != nil {
+		t.Errorf("Filters error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Filters (-want,+got): %v", diff)
+	}
+
+	tr.Annotations[FiltersAnnotationKey] = "not json"
+	if _, err := tr.Filters(); err == nil {
+		t.Error("Filters of an invalid annotation succeeded, want an error")
+	}
+}
+
 func TestTrigger_GetConditionSet(t *testing.T) {
 	tr := &Trigger{}
 
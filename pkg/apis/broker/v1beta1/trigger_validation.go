@@ -18,13 +18,134 @@ package v1beta1
 
 import (
 	"context"
+	"regexp"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 )
 
+// Only allow lowercase alphanumeric attribute names, as required by the
+// CloudEvents spec.
+var validAttributeName = regexp.MustCompile(`^[a-z0-9]+$`)
+
 // Validate the Trigger.
 func (t *Trigger) Validate(ctx context.Context) *apis.FieldError {
-	// The Google Cloud Broker doesn't have any custom validations. The
-	// eventing webhook will run the usual validations.
+	// We validate the Trigger's annotations. The eventing webhook will run
+	// the other usual validations.
+	var errs *apis.FieldError
+	var original metav1.Object
+	if apis.IsInUpdate(ctx) {
+		original = apis.GetBaseline(ctx).(*Trigger)
+	}
+	withNS := apis.AllowDifferentNamespace(apis.WithinParent(ctx, t.ObjectMeta))
+	errs = errs.Also(validateDeliveryAnnotation(withNS, t.GetAnnotations()).ViaField("metadata", "annotations"))
+	errs = errs.Also(t.validateFiltersAnnotation().ViaField("metadata", "annotations"))
+	errs = errs.Also(validateOrderingAnnotation(t.GetAnnotations(), original).ViaField("metadata", "annotations"))
+	errs = errs.Also(validateReplayAnnotations(t.GetAnnotations()).ViaField("metadata", "annotations"))
+	errs = errs.Also(validateReplyAnnotations(t.GetAnnotations()).ViaField("metadata", "annotations"))
+	return errs.Also(validateDedupWindowAnnotation(t.GetAnnotations()).ViaField("metadata", "annotations"))
+}
+
+func validateDeliveryAnnotation(ctx context.Context, annotations map[string]string) *apis.FieldError {
+	spec, err := deliverySpec(annotations)
+	if err != nil {
+		return apis.ErrInvalidValue(annotations[DeliveryAnnotationKey], DeliveryAnnotationKey)
+	}
+	if spec == nil {
+		return nil
+	}
+	return ValidateDeliverySpec(ctx, spec).ViaField(DeliveryAnnotationKey)
+}
+
+func (t *Trigger) validateFiltersAnnotation() *apis.FieldError {
+	filters, err := t.Filters()
+	if err != nil {
+		return apis.ErrInvalidValue(t.GetAnnotations()[FiltersAnnotationKey], FiltersAnnotationKey)
+	}
+	var errs *apis.FieldError
+	for i, f := range filters {
+		errs = errs.Also(ValidateSubscriptionsAPIFilter(&f).ViaIndex(i).ViaField(FiltersAnnotationKey))
+	}
+	return errs
+}
+
+func validateDedupWindowAnnotation(annotations map[string]string) *apis.FieldError {
+	value, ok := annotations[DedupWindowAnnotationKey]
+	if !ok {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return apis.ErrInvalidValue(value, DedupWindowAnnotationKey)
+	}
+	if d < minDedupWindow || d > maxDedupWindow {
+		return apis.ErrOutOfBoundsValue(value, minDedupWindow, maxDedupWindow, DedupWindowAnnotationKey)
+	}
 	return nil
 }
+
+// ValidateSubscriptionsAPIFilter validates that exactly one filter dialect is
+// set and that its attributes are valid, recursing into nested filters.
+func ValidateSubscriptionsAPIFilter(f *SubscriptionsAPIFilter) *apis.FieldError {
+	var errs *apis.FieldError
+	var set []string
+	if f.All != nil {
+		set = append(set, "all")
+		if len(f.All) == 0 {
+			errs = errs.Also(apis.ErrGeneric("at least one filter must be specified", "all"))
+		}
+		for i, nested := range f.All {
+			errs = errs.Also(ValidateSubscriptionsAPIFilter(&nested).ViaFieldIndex("all", i))
+		}
+	}
+	if f.Any != nil {
+		set = append(set, "any")
+		if len(f.Any) == 0 {
+			errs = errs.Also(apis.ErrGeneric("at least one filter must be specified", "any"))
+		}
+		for i, nested := range f.Any {
+			errs = errs.Also(ValidateSubscriptionsAPIFilter(&nested).ViaFieldIndex("any", i))
+		}
+	}
+	if f.Not != nil {
+		set = append(set, "not")
+		errs = errs.Also(ValidateSubscriptionsAPIFilter(f.Not).ViaField("not"))
+	}
+	if f.Exact != nil {
+		set = append(set, "exact")
+		errs = errs.Also(validateAttributes(f.Exact, true).ViaField("exact"))
+	}
+	if f.Prefix != nil {
+		set = append(set, "prefix")
+		errs = errs.Also(validateAttributes(f.Prefix, false).ViaField("prefix"))
+	}
+	if f.Suffix != nil {
+		set = append(set, "suffix")
+		errs = errs.Also(validateAttributes(f.Suffix, false).ViaField("suffix"))
+	}
+	switch len(set) {
+	case 0:
+		errs = errs.Also(apis.ErrMissingOneOf("all", "any", "not", "exact", "prefix", "suffix"))
+	case 1:
+	default:
+		errs = errs.Also(apis.ErrMultipleOneOf(set...))
+	}
+	return errs
+}
+
+func validateAttributes(attrs map[string]string, allowEmptyValue bool) *apis.FieldError {
+	var errs *apis.FieldError
+	if len(attrs) == 0 {
+		return apis.ErrGeneric("at least one attribute must be specified")
+	}
+	for attr, value := range attrs {
+		if !validAttributeName.MatchString(attr) {
+			errs = errs.Also(apis.ErrInvalidKeyName(attr, apis.CurrentField,
+				"attribute name must contain only lowercase alphanumeric characters"))
+		} else if value == "" && !allowEmptyValue {
+			errs = errs.Also(apis.ErrInvalidValue(value, apis.CurrentField).ViaKey(attr))
+		}
+	}
+	return errs
+}
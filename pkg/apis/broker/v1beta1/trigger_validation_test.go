@@ -18,12 +18,230 @@ package v1beta1
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	eventingduckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
 )
 
 func TestTrigger_Validate(t *testing.T) {
-	trig := Trigger{}
-	if err := trig.Validate(context.TODO()); err != nil {
-		t.Errorf("expected nil, got %v", err)
+	bop := eventingduckv1beta1.BackoffPolicyExponential
+	bod := "PT1S"
+	tests := []struct {
+		name    string
+		trigger Trigger
+		want    *apis.FieldError
+	}{{
+		name:    "no delivery spec",
+		trigger: Trigger{},
+	}, {
+		name: "valid delivery spec",
+		trigger: Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: deliveryAnnotations(t, &eventingduckv1beta1.DeliverySpec{
+					BackoffDelay:  &bod,
+					BackoffPolicy: &bop,
+					DeadLetterSink: &duckv1.Destination{
+						URI: apis.HTTP("dead-letter.example.com"),
+					},
+				}),
+			},
+		},
+	}, {
+		name: "malformed delivery spec",
+		trigger: Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{DeliveryAnnotationKey: "not json"},
+			},
+		},
+		want: apis.ErrInvalidValue("not json", "metadata.annotations."+DeliveryAnnotationKey),
+	}, {
+		name: "missing backoff policy",
+		trigger: Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: deliveryAnnotations(t, &eventingduckv1beta1.DeliverySpec{
+					BackoffDelay: &bod,
+				}),
+			},
+		},
+		want: apis.ErrMissingField("metadata.annotations." + DeliveryAnnotationKey + ".backoffPolicy"),
+	}, {
+		name: "invalid dead letter topic",
+		trigger: Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: deliveryAnnotations(t, &eventingduckv1beta1.DeliverySpec{
+					BackoffDelay:  &bod,
+					BackoffPolicy: &bop,
+					DeadLetterSink: &duckv1.Destination{
+						URI: &apis.URL{
+							Scheme: "pubsub",
+						},
+					},
+				}),
+			},
+		},
+		want: apis.ErrInvalidValue("Dead letter topic must not be empty", "metadata.annotations."+DeliveryAnnotationKey+".deadLetterSink.uri"),
+	}, {
+		name: "valid filters",
+		trigger: Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: filtersAnnotations(t, []SubscriptionsAPIFilter{{
+					Prefix: map[string]string{"type": "google.cloud.storage.object.v1."},
+				}, {
+					Any: []SubscriptionsAPIFilter{{
+						Exact: map[string]string{"source": "foo"},
+					}, {
+						Not: &SubscriptionsAPIFilter{
+							Suffix: map[string]string{"subject": ".tmp"},
+						},
+					}},
+				}}),
+			},
+		},
+	}, {
+		name: "invalid filters json",
+		trigger: Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{FiltersAnnotationKey: `{"prefix":{}}`},
+			},
+		},
+		want: apis.ErrInvalidValue(`{"prefix":{}}`, "metadata.annotations."+FiltersAnnotationKey),
+	}, {
+		name: "empty filter",
+		trigger: Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: filtersAnnotations(t, []SubscriptionsAPIFilter{{}}),
+			},
+		},
+		want: apis.ErrMissingOneOf(filtersField+"[0].all", filtersField+"[0].any", filtersField+"[0].not",
+			filtersField+"[0].exact", filtersField+"[0].prefix", filtersField+"[0].suffix"),
+	}, {
+		name: "empty all",
+		trigger: Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{FiltersAnnotationKey: `[{"all":[]}]`},
+			},
+		},
+		want: apis.ErrGeneric("at least one filter must be specified", filtersField+"[0].all"),
+	}, {
+		name: "empty nested any",
+		trigger: Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{FiltersAnnotationKey: `[{"not":{"any":[]}}]`},
+			},
+		},
+		want: apis.ErrGeneric("at least one filter must be specified", filtersField+"[0].not.any"),
+	}, {
+		name: "multiple dialects in one filter",
+		trigger: Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: filtersAnnotations(t, []SubscriptionsAPIFilter{{
+					Exact:  map[string]string{"type": "foo"},
+					Prefix: map[string]string{"type": "f"},
+				}}),
+			},
+		},
+		want: apis.ErrMultipleOneOf(filtersField+"[0].exact", filtersField+"[0].prefix"),
+	}, {
+		name: "invalid attribute name in nested filter",
+		trigger: Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: filtersAnnotations(t, []SubscriptionsAPIFilter{{
+					All: []SubscriptionsAPIFilter{{
+						Exact: map[string]string{"Type": "foo"},
+					}},
+				}}),
+			},
+		},
+		want: apis.ErrInvalidKeyName("Type", filtersField+"[0].all[0].exact",
+			"attribute name must contain only lowercase alphanumeric characters"),
+	}, {
+		name: "empty prefix",
+		trigger: Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: filtersAnnotations(t, []SubscriptionsAPIFilter{{
+					Prefix: map[string]string{"type": ""},
+				}}),
+			},
+		},
+		want: apis.ErrInvalidValue("", filtersField+"[0].prefix[type]"),
+	}, {
+		name: "ordered",
+		trigger: Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{OrderingAnnotationKey: "true"},
+			},
+		},
+	}, {
+		name: "invalid ordering",
+		trigger: Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{OrderingAnnotationKey: "1"},
+			},
+		},
+		want: apis.ErrInvalidValue("1", "metadata.annotations."+OrderingAnnotationKey),
+	}, {
+		name: "dedup window",
+		trigger: Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{DedupWindowAnnotationKey: "10m"},
+			},
+		},
+	}, {
+		name: "invalid dedup window",
+		trigger: Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{DedupWindowAnnotationKey: "10"},
+			},
+		},
+		want: apis.ErrInvalidValue("10", "metadata.annotations."+DedupWindowAnnotationKey),
+	}, {
+		name: "dedup window too long",
+		trigger: Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{DedupWindowAnnotationKey: "48h"},
+			},
+		},
+		want: apis.ErrOutOfBoundsValue("48h", minDedupWindow, maxDedupWindow, "metadata.annotations."+DedupWindowAnnotationKey),
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.trigger.Validate(context.Background())
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("Trigger.Validate (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestTrigger_ValidateOrderingUpdate(t *testing.T) {
+	original := &Trigger{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{OrderingAnnotationKey: "true"},
+		},
+	}
+	ctx := apis.WithinUpdate(context.Background(), original)
+	if err := original.DeepCopy().Validate(ctx); err != nil {
+		t.Errorf("Validate() of unchanged ordering = %v", err)
+	}
+	if err := (&Trigger{}).Validate(ctx); err == nil {
+		t.Error("Validate() of disabled ordering succeeded, want an error")
+	}
+}
+
+// filtersField is the path of the errors in the filters annotation.
+const filtersField = "metadata.annotations." + FiltersAnnotationKey
+
+// filtersAnnotations returns the annotations holding the given Trigger filters.
+func filtersAnnotations(t *testing.T, filters []SubscriptionsAPIFilter) map[string]string {
+	t.Helper()
+	value, err := json.Marshal(filters)
+	if err != nil {
+		t.Fatalf("Failed to encode the filters: %v", err)
 	}
+	return map[string]string{FiltersAnnotationKey: string(value)}
 }
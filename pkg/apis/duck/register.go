@@ -29,6 +29,19 @@ const (
 	// ClusterNameAnnotation is the annotation for the cluster Name.
 	ClusterNameAnnotation = "cluster-name"
 
+	// UnconvertibleSinkAnnotation is the annotation for the URI the messages that could not be
+	// converted to events are sent to, wrapped in envelope events.
+	UnconvertibleSinkAnnotation = "events.cloud.google.com/unconvertible-sink"
+
+	// DataValidationAnnotation is the annotation for the validation of the data of the events
+	// against the schema of their type, either "flag" or "reject".
+	DataValidationAnnotation = "events.cloud.google.com/data-validation"
+
+	// ObjectNameFilterAnnotation is the annotation of a PullSubscription holding the JSON encoded
+	// glob patterns of the names of the objects whose Cloud Storage events are sent. It is set by
+	// the CloudStorageSource reconciler.
+	ObjectNameFilterAnnotation = "internal.events.cloud.google.com/object-name-filter"
+
 	// AutoscalingMinScaleAnnotation is the annotation to specify the minimum number of pods to scale to.
 	AutoscalingMinScaleAnnotation = Autoscaling + "/minScale"
 	// AutoscalingMaxScaleAnnotation is the annotation to specify the maximum number of pods to scale to.
@@ -17,6 +17,10 @@ limitations under the License.
 package v1
 
 import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
 	"knative.dev/pkg/apis"
 )
 
@@ -75,3 +79,32 @@ func (s *PubSubStatus) MarkPullSubscriptionReady(cs *apis.ConditionSet) {
 func (s *PubSubStatus) MarkPullSubscriptionNotConfigured(cs *apis.ConditionSet) {
 	cs.Manage(s).MarkUnknown(PullSubscriptionReady, "PullSubscriptionNotConfigured", "PullSubscription has not yet been reconciled")
 }
+
+// MarkDriftDetected sets the condition that the GCP resources of the source
+// had been changed out of band and were repaired, and what changed. Its last
+// transition time is the time of the last repair. It doesn't change the Ready
+// condition.
+func (s *PubSubStatus) MarkDriftDetected(cs *apis.ConditionSet, reason, messageFormat string, messageA ...interface{}) {
+	// Clear the condition first, so that the last transition time is updated
+	// when the same drift is repaired again.
+	_ = cs.Manage(s).ClearCondition(DriftDetected)
+	cs.Manage(s).SetCondition(apis.Condition{
+		Type:     DriftDetected,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   reason,
+		Message:  fmt.Sprintf(messageFormat, messageA...),
+	})
+}
+
+// ClearDriftDetected removes the DriftDetected condition, as the GCP resources
+// of the source matched their desired state, once the last repair is older
+// than retention.
+func (s *PubSubStatus) ClearDriftDetected(cs *apis.ConditionSet, retention time.Duration) {
+	c := cs.Manage(s).GetCondition(DriftDetected)
+	if c == nil || time.Since(c.LastTransitionTime.Inner.Time) < retention {
+		return
+	}
+	// DriftDetected is not a terminal condition, clearing it can't fail.
+	_ = cs.Manage(s).ClearCondition(DriftDetected)
+}
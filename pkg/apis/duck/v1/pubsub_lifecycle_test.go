@@ -18,6 +18,10 @@ package v1
 
 import (
 	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"knative.dev/pkg/apis"
 )
@@ -116,3 +120,43 @@ func TestPubSubStatusIsReady(t *testing.T) {
 		})
 	}
 }
+
+func TestPubSubStatusDriftDetected(t *testing.T) {
+	s := &PubSubStatus{}
+	s.MarkPullSubscriptionReady(&cs)
+	s.MarkTopicReady(&cs)
+
+	s.MarkDriftDetected(&cs, "NotificationDeleted", "notification %q was deleted", "1")
+	c := cs.Manage(s).GetCondition(DriftDetected)
+	if c == nil || c.Status != corev1.ConditionTrue || c.Reason != "NotificationDeleted" || c.Message != `notification "1" was deleted` {
+		t.Errorf("unexpected DriftDetected condition: %+v", c)
+	}
+	if !s.IsReady() {
+		t.Error("DriftDetected changed the readiness")
+	}
+
+	// The condition is kept until the last repair is older than the retention.
+	s.ClearDriftDetected(&cs, time.Hour)
+	if c := cs.Manage(s).GetCondition(DriftDetected); c == nil {
+		t.Error("DriftDetected condition cleared before the retention")
+	}
+
+	// Repairing the same drift again updates the last transition time.
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == DriftDetected {
+			s.Conditions[i].LastTransitionTime = apis.VolatileTime{Inner: metav1.NewTime(time.Now().Add(-2 * time.Hour))}
+		}
+	}
+	s.MarkDriftDetected(&cs, "NotificationDeleted", "notification %q was deleted", "1")
+	if c := cs.Manage(s).GetCondition(DriftDetected); c == nil || time.Since(c.LastTransitionTime.Inner.Time) > time.Minute {
+		t.Errorf("DriftDetected last transition time not updated: %+v", c)
+	}
+
+	s.ClearDriftDetected(&cs, 0)
+	if c := cs.Manage(s).GetCondition(DriftDetected); c != nil {
+		t.Errorf("DriftDetected condition not cleared: %+v", c)
+	}
+	if !s.IsReady() {
+		t.Error("clearing DriftDetected changed the readiness")
+	}
+}
@@ -98,6 +98,11 @@ const (
 
 	// PullSubscriptionReay has status True when the PullSubscription is ready.
 	PullSubscriptionReady apis.ConditionType = "PullSubscriptionReady"
+
+	// DriftDetected has status True when the last reconciliation found that
+	// the GCP resources of the source had been changed out of band, and
+	// repaired them. It is absent otherwise, and not counted for Ready.
+	DriftDetected apis.ConditionType = "DriftDetected"
 )
 
 var (
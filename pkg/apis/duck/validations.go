@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"net/url"
 	"regexp"
 	"strconv"
 
@@ -68,6 +69,26 @@ func ValidateAutoscalingAnnotations(ctx context.Context, annotations map[string]
 	return errs
 }
 
+// ValidateUnconvertibleSinkAnnotation validates the unconvertible sink annotation, which must be
+// an absolute URI if set.
+func ValidateUnconvertibleSinkAnnotation(annotations map[string]string, errs *apis.FieldError) *apis.FieldError {
+	if sink, ok := annotations[UnconvertibleSinkAnnotation]; ok {
+		if u, err := url.Parse(sink); err != nil || !u.IsAbs() || u.Host == "" {
+			errs = errs.Also(apis.ErrInvalidValue(sink, fmt.Sprintf("metadata.annotations[%s]", UnconvertibleSinkAnnotation)))
+		}
+	}
+	return errs
+}
+
+// ValidateDataValidationAnnotation validates the data validation annotation, which must be either
+// "flag" or "reject" if set.
+func ValidateDataValidationAnnotation(annotations map[string]string, errs *apis.FieldError) *apis.FieldError {
+	if v, ok := annotations[DataValidationAnnotation]; ok && v != "flag" && v != "reject" {
+		errs = errs.Also(apis.ErrInvalidValue(v, fmt.Sprintf("metadata.annotations[%s]", DataValidationAnnotation)))
+	}
+	return errs
+}
+
 func validateAnnotation(annotations map[string]string, annotation string, minimumValue int, errs *apis.FieldError) (int, *apis.FieldError) {
 	var value int
 	if val, ok := annotations[annotation]; !ok {
@@ -120,6 +120,77 @@ func TestValidateAutoscalingAnnotations(t *testing.T) {
 	}
 }
 
+func TestValidateUnconvertibleSinkAnnotation(t *testing.T) {
+	testCases := map[string]struct {
+		annotations map[string]string
+		wantErr     bool
+	}{
+		"no annotation": {
+			annotations: map[string]string{},
+		},
+		"valid sink": {
+			annotations: map[string]string{
+				UnconvertibleSinkAnnotation: "http://unconvertible.default.svc.cluster.local",
+			},
+		},
+		"relative sink": {
+			annotations: map[string]string{
+				UnconvertibleSinkAnnotation: "/unconvertible",
+			},
+			wantErr: true,
+		},
+		"sink without host": {
+			annotations: map[string]string{
+				UnconvertibleSinkAnnotation: "http://",
+			},
+			wantErr: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			err := ValidateUnconvertibleSinkAnnotation(tc.annotations, nil)
+			if tc.wantErr != (err != nil) {
+				t.Errorf("ValidateUnconvertibleSinkAnnotation() = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDataValidationAnnotation(t *testing.T) {
+	testCases := map[string]struct {
+		annotations map[string]string
+		wantErr     bool
+	}{
+		"no annotation": {
+			annotations: map[string]string{},
+		},
+		"flag": {
+			annotations: map[string]string{
+				DataValidationAnnotation: "flag",
+			},
+		},
+		"reject": {
+			annotations: map[string]string{
+				DataValidationAnnotation: "reject",
+			},
+		},
+		"unknown validation": {
+			annotations: map[string]string{
+				DataValidationAnnotation: "ignore",
+			},
+			wantErr: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			err := ValidateDataValidationAnnotation(tc.annotations, nil)
+			if tc.wantErr != (err != nil) {
+				t.Errorf("ValidateDataValidationAnnotation() = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
 func TestCheckImmutableClusterNameAnnotation(t *testing.T) {
 	testCases := map[string]struct {
 		original *v1.ObjectMeta
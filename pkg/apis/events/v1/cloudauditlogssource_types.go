@@ -77,12 +77,51 @@ type CloudAuditLogsSourceSpec struct {
 	// The GCP service providing audit logs. Required.
 	ServiceName string `json:"serviceName"`
 	// The name of the service method or operation. For API calls,
-	// this should be the name of the API method. Required.
-	MethodName string `json:"methodName"`
+	// this should be the name of the API method. Either MethodName or
+	// MethodNames is required.
+	MethodName string `json:"methodName,omitempty"`
+	// MethodNames are the names of several service methods or operations,
+	// any of which is matched. Mutually exclusive with MethodName.
+	// +optional
+	MethodNames []string `json:"methodNames,omitempty"`
 	// The resource or collection that is the target of the
 	// operation. The name is a scheme-less URI, not including the
 	// API service name.
 	ResourceName string `json:"resourceName,omitempty"`
+
+	// Severity is the minimum severity of the log entries, for example
+	// NOTICE or ERROR.
+	// +optional
+	Severity string `json:"severity,omitempty"`
+	// PrincipalEmails are the emails of the principals that made the
+	// operations, any of which is matched.
+	// +optional
+	PrincipalEmails []string `json:"principalEmails,omitempty"`
+	// ResourceType is the type of the monitored resource of the log
+	// entries, for example gce_instance.
+	// +optional
+	ResourceType string `json:"resourceType,omitempty"`
+	// LogName is the ID of the log of the log entries, for example
+	// cloudaudit.googleapis.com/activity.
+	// +optional
+	LogName string `json:"logName,omitempty"`
+	// Filter is an additional filter in the Cloud Logging query language
+	// that the log entries must match. It is combined with the filter built
+	// from the other fields with AND.
+	// +optional
+	Filter string `json:"filter,omitempty"`
+
+	// Parent is the resource in which the Stackdriver sink is created, in
+	// the form organizations/ORGANIZATION_ID, folders/FOLDER_ID or
+	// billingAccounts/BILLING_ACCOUNT_ID. Defaults to the project of the
+	// source.
+	// +optional
+	Parent string `json:"parent,omitempty"`
+	// IncludeChildren is whether the sink of an organization or folder
+	// Parent also exports the log entries of the folders and projects it
+	// contains.
+	// +optional
+	IncludeChildren bool `json:"includeChildren,omitempty"`
 }
 
 type CloudAuditLogsSourceStatus struct {
@@ -90,6 +129,10 @@ type CloudAuditLogsSourceStatus struct {
 
 	// ID of the Stackdriver sink used to publish audit log messages.
 	StackdriverSink string `json:"stackdriverSink,omitempty"`
+
+	// Filter is the effective filter of the Stackdriver sink.
+	// +optional
+	Filter string `json:"filter,omitempty"`
 }
 
 func (*CloudAuditLogsSource) GetGroupVersionKind() schema.GroupVersionKind {
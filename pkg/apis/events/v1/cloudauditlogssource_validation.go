@@ -18,6 +18,9 @@ package v1
 
 import (
 	"context"
+	"errors"
+	"regexp"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -35,7 +38,8 @@ func (current *CloudAuditLogsSource) Validate(ctx context.Context) *apis.FieldEr
 		original := apis.GetBaseline(ctx).(*CloudAuditLogsSource)
 		err = err.Also(current.CheckImmutableFields(ctx, original))
 	}
-	return err
+	err = duck.ValidateUnconvertibleSinkAnnotation(current.Annotations, err)
+	return duck.ValidateDataValidationAnnotation(current.Annotations, err)
 }
 
 func (current *CloudAuditLogsSourceSpec) Validate(ctx context.Context) *apis.FieldError {
@@ -52,10 +56,17 @@ func (current *CloudAuditLogsSourceSpec) Validate(ctx context.Context) *apis.Fie
 	if current.ServiceName == "" {
 		errs = errs.Also(apis.ErrMissingField("serviceName"))
 	}
-	// MethodName [required]
-	if current.MethodName == "" {
+	// MethodName or MethodNames [required]
+	switch {
+	case current.MethodName == "" && len(current.MethodNames) == 0:
 		errs = errs.Also(apis.ErrMissingField("methodName"))
+	case current.MethodName != "" && len(current.MethodNames) != 0:
+		errs = errs.Also(apis.ErrMultipleOneOf("methodName", "methodNames"))
 	}
+	errs = errs.Also(validateFilterValues(current.MethodNames).ViaField("methodNames"))
+
+	errs = errs.Also(current.validateFilter())
+	errs = errs.Also(current.validateParent())
 
 	if err := duck.ValidateCredential(current.Secret, current.ServiceAccountName); err != nil {
 		errs = errs.Also(err)
@@ -64,17 +75,139 @@ func (current *CloudAuditLogsSourceSpec) Validate(ctx context.Context) *apis.Fie
 	return errs
 }
 
+var (
+	// logSeverities are the severities of Cloud Logging log entries.
+	logSeverities = map[string]bool{
+		"DEFAULT":   true,
+		"DEBUG":     true,
+		"INFO":      true,
+		"NOTICE":    true,
+		"WARNING":   true,
+		"ERROR":     true,
+		"CRITICAL":  true,
+		"ALERT":     true,
+		"EMERGENCY": true,
+	}
+	// See https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry for the format of
+	// log IDs and https://cloud.google.com/logging/docs/api/v2/resource-list for resource types.
+	logNameRegexp      = regexp.MustCompile(`^[A-Za-z0-9/_.-]{1,512}$`)
+	resourceTypeRegexp = regexp.MustCompile(`^[a-z0-9_.]+$`)
+
+	// parentRegexp matches the parents of Stackdriver sinks other than projects.
+	parentRegexp = regexp.MustCompile(`^(organizations|folders|billingAccounts)/[A-Za-z0-9-]+$`)
+)
+
+// validateParent validates the parent of the Stackdriver sink.
+func (current *CloudAuditLogsSourceSpec) validateParent() *apis.FieldError {
+	if current.Parent == "" {
+		if current.IncludeChildren {
+			return apis.ErrGeneric("includeChildren requires an organization or folder parent", "includeChildren")
+		}
+		return nil
+	}
+	if !parentRegexp.MatchString(current.Parent) {
+		return apis.ErrInvalidValue(current.Parent, "parent")
+	}
+	if current.IncludeChildren && strings.HasPrefix(current.Parent, "billingAccounts/") {
+		return apis.ErrGeneric("includeChildren requires an organization or folder parent", "includeChildren")
+	}
+	return nil
+}
+
+// validateFilter validates the fields of the spec which are only used to build the filter of the
+// Stackdriver sink.
+func (current *CloudAuditLogsSourceSpec) validateFilter() *apis.FieldError {
+	var errs *apis.FieldError
+	if current.Severity != "" && !logSeverities[current.Severity] {
+		errs = errs.Also(apis.ErrInvalidValue(current.Severity, "severity"))
+	}
+	errs = errs.Also(validateFilterValues(current.PrincipalEmails).ViaField("principalEmails"))
+	for i, email := range current.PrincipalEmails {
+		if strings.Count(email, "@") != 1 || strings.HasPrefix(email, "@") || strings.HasSuffix(email, "@") {
+			errs = errs.Also(apis.ErrInvalidArrayValue(email, "principalEmails", i))
+		}
+	}
+	if current.ResourceType != "" && !resourceTypeRegexp.MatchString(current.ResourceType) {
+		errs = errs.Also(apis.ErrInvalidValue(current.ResourceType, "resourceType"))
+	}
+	if current.LogName != "" && !logNameRegexp.MatchString(current.LogName) {
+		errs = errs.Also(apis.ErrInvalidValue(current.LogName, "logName"))
+	}
+	if current.Filter != "" {
+		if err := validateLoggingFilter(current.Filter); err != nil {
+			errs = errs.Also(&apis.FieldError{
+				Message: "invalid value: " + current.Filter,
+				Paths:   []string{"filter"},
+				Details: err.Error(),
+			})
+		}
+	}
+	return errs
+}
+
+// validateFilterValues validates values that are matched with OR in the filter of the
+// Stackdriver sink, which must be non-empty and unique.
+func validateFilterValues(values []string) *apis.FieldError {
+	var errs *apis.FieldError
+	seen := make(map[string]bool, len(values))
+	for i, v := range values {
+		if v == "" {
+			errs = errs.Also(apis.ErrInvalidArrayValue(v, apis.CurrentField, i))
+		} else if seen[v] {
+			errs = errs.Also(apis.ErrGeneric("duplicate value", apis.CurrentField).ViaIndex(i))
+		}
+		seen[v] = true
+	}
+	return errs
+}
+
+// validateLoggingFilter checks that a filter in the Cloud Logging query language has terminated
+// strings and balanced parentheses, so that it can be safely combined with other filters. The
+// rest of its syntax is checked by Cloud Logging when the sink is created.
+func validateLoggingFilter(filter string) error {
+	depth := 0
+	inString := false
+	for i := 0; i < len(filter); i++ {
+		switch c := filter[i]; {
+		case inString && c == '\\':
+			// Skip the escaped character.
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth == 0 {
+				return errors.New("unbalanced closing parenthesis")
+			}
+			depth--
+		}
+	}
+	if inString {
+		return errors.New("unterminated string")
+	}
+	if depth != 0 {
+		return errors.New("unbalanced opening parenthesis")
+	}
+	if strings.TrimSpace(filter) == "" {
+		return errors.New("empty filter")
+	}
+	return nil
+}
+
 func (current *CloudAuditLogsSource) CheckImmutableFields(ctx context.Context, original *CloudAuditLogsSource) *apis.FieldError {
 	if original == nil {
 		return nil
 	}
 
 	var errs *apis.FieldError
-	// Modification of Topic, Secret, ServiceAccountName, Project, ServiceName, MethodName and ResourceName are not allowed.
+	// Modification of Topic, Secret, ServiceAccountName, Project, ServiceName, MethodName, ResourceName, Parent and
+	// IncludeChildren are not allowed.
 	// Everything else is mutable.
 	if diff := cmp.Diff(original.Spec, current.Spec,
 		cmpopts.IgnoreFields(CloudAuditLogsSourceSpec{},
-			"Sink", "CloudEventOverrides")); diff != "" {
+			"Sink", "CloudEventOverrides", "MethodNames", "Severity", "PrincipalEmails", "ResourceType", "LogName", "Filter")); diff != "" {
 		errs = errs.Also(
 			&apis.FieldError{
 				Message: "Immutable fields changed (-old +new)",
@@ -106,6 +106,134 @@ func TestCloudAuditLogsSourceValidationFields(t *testing.T) {
 			}(),
 			error: true,
 		},
+		"method names": {
+			spec: func() CloudAuditLogsSourceSpec {
+				obj := auditLogsSourceSpec.DeepCopy()
+				obj.MethodName = ""
+				obj.MethodNames = []string{"bar", "qux"}
+				return *obj
+			}(),
+			error: false,
+		},
+		"method name and method names": {
+			spec: func() CloudAuditLogsSourceSpec {
+				obj := auditLogsSourceSpec.DeepCopy()
+				obj.MethodNames = []string{"qux"}
+				return *obj
+			}(),
+			error: true,
+		},
+		"duplicate method names": {
+			spec: func() CloudAuditLogsSourceSpec {
+				obj := auditLogsSourceSpec.DeepCopy()
+				obj.MethodName = ""
+				obj.MethodNames = []string{"bar", "bar"}
+				return *obj
+			}(),
+			error: true,
+		},
+		"filter fields": {
+			spec: func() CloudAuditLogsSourceSpec {
+				obj := auditLogsSourceSpec.DeepCopy()
+				obj.Severity = "NOTICE"
+				obj.PrincipalEmails = []string{"alice@example.com", "sa@project.iam.gserviceaccount.com"}
+				obj.ResourceType = "gce_instance"
+				obj.LogName = "cloudaudit.googleapis.com/activity"
+				obj.Filter = `protoPayload.status.code!=0 AND (labels.a="b)" OR NOT labels.c:"\"")`
+				return *obj
+			}(),
+			error: false,
+		},
+		"bad severity": {
+			spec: func() CloudAuditLogsSourceSpec {
+				obj := auditLogsSourceSpec.DeepCopy()
+				obj.Severity = "notice"
+				return *obj
+			}(),
+			error: true,
+		},
+		"bad principal email": {
+			spec: func() CloudAuditLogsSourceSpec {
+				obj := auditLogsSourceSpec.DeepCopy()
+				obj.PrincipalEmails = []string{"alice"}
+				return *obj
+			}(),
+			error: true,
+		},
+		"bad resource type": {
+			spec: func() CloudAuditLogsSourceSpec {
+				obj := auditLogsSourceSpec.DeepCopy()
+				obj.ResourceType = `gce_instance" OR "a`
+				return *obj
+			}(),
+			error: true,
+		},
+		"bad log name": {
+			spec: func() CloudAuditLogsSourceSpec {
+				obj := auditLogsSourceSpec.DeepCopy()
+				obj.LogName = `activity"`
+				return *obj
+			}(),
+			error: true,
+		},
+		"bad filter": {
+			spec: func() CloudAuditLogsSourceSpec {
+				obj := auditLogsSourceSpec.DeepCopy()
+				obj.Filter = `severity>=ERROR) OR (true`
+				return *obj
+			}(),
+			error: true,
+		},
+		"organization parent": {
+			spec: func() CloudAuditLogsSourceSpec {
+				obj := auditLogsSourceSpec.DeepCopy()
+				obj.Parent = "organizations/123456789"
+				obj.IncludeChildren = true
+				return *obj
+			}(),
+			error: false,
+		},
+		"folder parent": {
+			spec: func() CloudAuditLogsSourceSpec {
+				obj := auditLogsSourceSpec.DeepCopy()
+				obj.Parent = "folders/123456789"
+				return *obj
+			}(),
+			error: false,
+		},
+		"billing account parent": {
+			spec: func() CloudAuditLogsSourceSpec {
+				obj := auditLogsSourceSpec.DeepCopy()
+				obj.Parent = "billingAccounts/0123AB-4567CD-89EF01"
+				return *obj
+			}(),
+			error: false,
+		},
+		"bad parent": {
+			spec: func() CloudAuditLogsSourceSpec {
+				obj := auditLogsSourceSpec.DeepCopy()
+				obj.Parent = "projects/my-project"
+				return *obj
+			}(),
+			error: true,
+		},
+		"include children without parent": {
+			spec: func() CloudAuditLogsSourceSpec {
+				obj := auditLogsSourceSpec.DeepCopy()
+				obj.IncludeChildren = true
+				return *obj
+			}(),
+			error: true,
+		},
+		"include children of billing account": {
+			spec: func() CloudAuditLogsSourceSpec {
+				obj := auditLogsSourceSpec.DeepCopy()
+				obj.Parent = "billingAccounts/0123AB-4567CD-89EF01"
+				obj.IncludeChildren = true
+				return *obj
+			}(),
+			error: true,
+		},
 		"bad sink, name": {
 			spec: func() CloudAuditLogsSourceSpec {
 				obj := auditLogsSourceSpec.DeepCopy()
@@ -167,6 +295,31 @@ func TestCloudAuditLogsSourceValidationFields(t *testing.T) {
 	}
 }
 
+func TestValidateLoggingFilter(t *testing.T) {
+	testCases := map[string]struct {
+		filter string
+		error  bool
+	}{
+		"simple":                   {filter: `severity>=ERROR`},
+		"nested":                   {filter: `(a="1" OR (b="2")) AND c:"3"`},
+		"parentheses in string":    {filter: `a=")(" AND b="\")"`},
+		"blank":                    {filter: "  ", error: true},
+		"unterminated string":      {filter: `a="1`, error: true},
+		"unbalanced opening":       {filter: `(a="1"`, error: true},
+		"unbalanced closing":       {filter: `a="1")`, error: true},
+		"closing before opening":   {filter: `a="1") OR (b="2"`, error: true},
+		"escaped string delimiter": {filter: `a="\"`, error: true},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			err := validateLoggingFilter(tc.filter)
+			if tc.error != (err != nil) {
+				t.Fatalf("Unexpected validation failure. Got %v", err)
+			}
+		})
+	}
+}
+
 func TestCloudAuditLogsSourceCheckImmutableFields(t *testing.T) {
 	testCases := map[string]struct {
 		orig              interface{}
@@ -231,6 +384,28 @@ func TestCloudAuditLogsSourceCheckImmutableFields(t *testing.T) {
 			},
 			allowed: false,
 		},
+		"filter fields changed": {
+			orig: &auditLogsSourceSpec,
+			updated: func() CloudAuditLogsSourceSpec {
+				obj := auditLogsSourceSpec.DeepCopy()
+				obj.Severity = "ERROR"
+				obj.PrincipalEmails = []string{"alice@example.com"}
+				obj.ResourceType = "gce_instance"
+				obj.LogName = "cloudaudit.googleapis.com/activity"
+				obj.Filter = "protoPayload.status.code!=0"
+				return *obj
+			}(),
+			allowed: true,
+		},
+		"Parent changed": {
+			orig: &auditLogsSourceSpec,
+			updated: func() CloudAuditLogsSourceSpec {
+				obj := auditLogsSourceSpec.DeepCopy()
+				obj.Parent = "folders/123456789"
+				return *obj
+			}(),
+			allowed: false,
+		},
 		"ResourceName changed": {
 			orig: &auditLogsSourceSpec,
 			updated: CloudAuditLogsSourceSpec{
@@ -33,6 +33,8 @@ func (current *CloudBuildSource) Validate(ctx context.Context) *apis.FieldError
 		errs = errs.Also(current.CheckImmutableFields(ctx, original))
 	}
 
+	errs = duck.ValidateUnconvertibleSinkAnnotation(current.Annotations, errs)
+	errs = duck.ValidateDataValidationAnnotation(current.Annotations, errs)
 	return duck.ValidateAutoscalingAnnotations(ctx, current.Annotations, errs)
 }
 
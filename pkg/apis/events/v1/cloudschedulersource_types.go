@@ -73,8 +73,68 @@ type CloudSchedulerSourceSpec struct {
 	// every minute.
 	Schedule string `json:"schedule"`
 
-	// What data to send
-	Data string `json:"data"`
+	// TimeZone is the time zone in which Schedule is interpreted, as a name
+	// of the tz database, for example "America/New_York". Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// What data to send, as text. Exactly one of Data, JSONData and
+	// BinaryData must be set.
+	// +optional
+	Data string `json:"data,omitempty"`
+
+	// JSONData is the data to send, as a JSON value.
+	// +optional
+	JSONData *runtime.RawExtension `json:"jsonData,omitempty"`
+
+	// BinaryData is the data to send, base64 encoded.
+	// +optional
+	BinaryData []byte `json:"binaryData,omitempty"`
+
+	// Attributes are additional attributes of the Pub/Sub messages
+	// published by the job. They are added as extensions to the
+	// CloudEvents, so their names must be valid CloudEvents attribute
+	// names.
+	// +optional
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// RetryConfig configures how the job is retried when it fails.
+	// +optional
+	RetryConfig *CloudSchedulerSourceRetryConfig `json:"retryConfig,omitempty"`
+
+	// Paused pauses the job when true. A paused job is kept, but doesn't
+	// run until it is resumed.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// CloudSchedulerSourceRetryConfig is the retry configuration of the job of a
+// CloudSchedulerSource. Unset fields use the Cloud Scheduler defaults.
+type CloudSchedulerSourceRetryConfig struct {
+	// RetryCount is the number of times a failed run is retried, between 0
+	// and 5.
+	// +optional
+	RetryCount *int32 `json:"retryCount,omitempty"`
+
+	// MaxRetryDuration is the time limit for retrying a failed run, for
+	// example '1h'. Zero means unlimited.
+	// +optional
+	MaxRetryDuration *string `json:"maxRetryDuration,omitempty"`
+
+	// MinBackoffDuration is the minimum time to wait before retrying a
+	// failed run, for example '5s'. Must be positive.
+	// +optional
+	MinBackoffDuration *string `json:"minBackoffDuration,omitempty"`
+
+	// MaxBackoffDuration is the maximum time to wait before retrying a
+	// failed run, for example '1h'. Must be positive.
+	// +optional
+	MaxBackoffDuration *string `json:"maxBackoffDuration,omitempty"`
+
+	// MaxDoublings is the number of times the wait between retries doubles
+	// before increasing linearly.
+	// +optional
+	MaxDoublings *int32 `json:"maxDoublings,omitempty"`
 }
 
 const (
@@ -18,11 +18,16 @@ package v1
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/knative-gcp/pkg/apis/duck"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 )
@@ -34,6 +39,8 @@ func (current *CloudSchedulerSource) Validate(ctx context.Context) *apis.FieldEr
 		original := apis.GetBaseline(ctx).(*CloudSchedulerSource)
 		errs = errs.Also(current.CheckImmutableFields(ctx, original))
 	}
+	errs = duck.ValidateUnconvertibleSinkAnnotation(current.Annotations, errs)
+	errs = duck.ValidateDataValidationAnnotation(current.Annotations, errs)
 	return duck.ValidateAutoscalingAnnotations(ctx, current.Annotations, errs)
 }
 
@@ -58,8 +65,25 @@ func (current *CloudSchedulerSourceSpec) Validate(ctx context.Context) *apis.Fie
 	}
 
 	// Data [required]
-	if current.Data == "" {
-		errs = errs.Also(apis.ErrMissingField("data"))
+	if err := current.validateData(); err != nil {
+		errs = errs.Also(err)
+	}
+
+	if current.TimeZone != "" {
+		// time.LoadLocation also accepts "Local", which has no meaning for Cloud Scheduler.
+		if _, err := time.LoadLocation(current.TimeZone); err != nil || current.TimeZone == "Local" {
+			errs = errs.Also(apis.ErrInvalidValue(current.TimeZone, "timeZone"))
+		}
+	}
+
+	for key := range current.Attributes {
+		if err := validateAttributeName(key); err != nil {
+			errs = errs.Also(err.ViaField("attributes"))
+		}
+	}
+
+	if current.RetryConfig != nil {
+		errs = errs.Also(current.RetryConfig.Validate(ctx).ViaField("retryConfig"))
 	}
 
 	if err := duck.ValidateCredential(current.Secret, current.ServiceAccountName); err != nil {
@@ -69,16 +93,107 @@ func (current *CloudSchedulerSourceSpec) Validate(ctx context.Context) *apis.Fie
 	return errs
 }
 
+// validateData checks that exactly one of data, jsonData and binaryData is set.
+func (current *CloudSchedulerSourceSpec) validateData() *apis.FieldError {
+	var set []string
+	if current.Data != "" {
+		set = append(set, "data")
+	}
+	if current.JSONData != nil {
+		set = append(set, "jsonData")
+	}
+	if len(current.BinaryData) > 0 {
+		set = append(set, "binaryData")
+	}
+	switch len(set) {
+	case 0:
+		return apis.ErrMissingField("data")
+	case 1:
+	default:
+		return apis.ErrMultipleOneOf(set...)
+	}
+	if current.JSONData != nil && !json.Valid(current.JSONData.Raw) {
+		return apis.ErrInvalidValue(string(current.JSONData.Raw), "jsonData")
+	}
+	return nil
+}
+
+// maxRetryCount is the largest number of retries Cloud Scheduler allows.
+const maxRetryCount = 5
+
+var (
+	// extensionNameRegexp matches the names of CloudEvents extension attributes.
+	extensionNameRegexp = regexp.MustCompile(`^[a-z0-9]{1,20}$`)
+
+	// reservedAttributeNames are the CloudEvents context attributes, that can't be set as
+	// extensions.
+	reservedAttributeNames = sets.NewString("data", "datacontenttype", "dataschema", "id", "source",
+		"specversion", "subject", "time", "type")
+)
+
+// IsExtensionName returns whether name can be the name of a CloudEvents extension attribute.
+func IsExtensionName(name string) bool {
+	return extensionNameRegexp.MatchString(name) && !reservedAttributeNames.Has(name)
+}
+
+func validateAttributeName(key string) *apis.FieldError {
+	if !extensionNameRegexp.MatchString(key) {
+		return apis.ErrInvalidKeyName(key, apis.CurrentField,
+			fmt.Sprintf("should match %s", extensionNameRegexp.String()))
+	}
+	if reservedAttributeNames.Has(key) {
+		return apis.ErrInvalidKeyName(key, apis.CurrentField, "is a reserved CloudEvents attribute")
+	}
+	return nil
+}
+
+func (current *CloudSchedulerSourceRetryConfig) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+
+	if current.RetryCount != nil && (*current.RetryCount < 0 || *current.RetryCount > maxRetryCount) {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(*current.RetryCount, 0, maxRetryCount, "retryCount"))
+	}
+	if current.MaxDoublings != nil && *current.MaxDoublings < 0 {
+		errs = errs.Also(apis.ErrInvalidValue(*current.MaxDoublings, "maxDoublings"))
+	}
+
+	errs = errs.Also(validateRetryDuration(current.MaxRetryDuration, "maxRetryDuration", false))
+	// Cloud Scheduler uses its default backoff durations instead of zero ones.
+	errs = errs.Also(validateRetryDuration(current.MinBackoffDuration, "minBackoffDuration", true))
+	errs = errs.Also(validateRetryDuration(current.MaxBackoffDuration, "maxBackoffDuration", true))
+	if current.MinBackoffDuration != nil && current.MaxBackoffDuration != nil {
+		min, minErr := time.ParseDuration(*current.MinBackoffDuration)
+		max, maxErr := time.ParseDuration(*current.MaxBackoffDuration)
+		if minErr == nil && maxErr == nil && min > max {
+			errs = errs.Also(&apis.FieldError{
+				Message: "minBackoffDuration must not be greater than maxBackoffDuration",
+				Paths:   []string{"minBackoffDuration", "maxBackoffDuration"},
+			})
+		}
+	}
+	return errs
+}
+
+func validateRetryDuration(duration *string, field string, positive bool) *apis.FieldError {
+	if duration == nil {
+		return nil
+	}
+	if d, err := time.ParseDuration(*duration); err != nil || d < 0 || (positive && d == 0) {
+		return apis.ErrInvalidValue(*duration, field)
+	}
+	return nil
+}
+
 func (current *CloudSchedulerSource) CheckImmutableFields(ctx context.Context, original *CloudSchedulerSource) *apis.FieldError {
 	if original == nil {
 		return nil
 	}
 
 	var errs *apis.FieldError
-	// Modification of Location, Schedule, Data, Secret, ServiceAccountName, Project are not allowed.
-	// Everything else is mutable.
+	// Modification of Location, Schedule, TimeZone, Data, JSONData, BinaryData, Attributes, RetryConfig,
+	// Secret, ServiceAccountName, Project are not allowed. Everything else is mutable.
 	if diff := cmp.Diff(original.Spec, current.Spec,
-		cmpopts.IgnoreFields(CloudSchedulerSourceSpec{}, "Sink", "CloudEventOverrides")); diff != "" {
+		cmpopts.IgnoreFields(CloudSchedulerSourceSpec{}, "Sink", "CloudEventOverrides", "Paused")); diff != "" {
 		errs = errs.Also(&apis.FieldError{
 			Message: "Immutable fields changed (-old +new)",
 			Paths:   []string{"spec"},
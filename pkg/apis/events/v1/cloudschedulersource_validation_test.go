@@ -23,6 +23,8 @@ import (
 	"github.com/google/knative-gcp/pkg/apis/duck"
 	metadatatesting "github.com/google/knative-gcp/pkg/gclient/metadata/testing"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/ptr"
 
 	"github.com/google/go-cmp/cmp"
 	gcpduckv1 "github.com/google/knative-gcp/pkg/apis/duck/v1"
@@ -372,6 +374,131 @@ func TestCloudSchedulerSourceSpecValidationFields(t *testing.T) {
 
 }
 
+func TestCloudSchedulerSourceSpecValidationJobFields(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(*CloudSchedulerSourceSpec)
+		want   *apis.FieldError
+	}{{
+		name: "time zone",
+		modify: func(s *CloudSchedulerSourceSpec) {
+			s.TimeZone = "America/New_York"
+		},
+	}, {
+		name: "unknown time zone",
+		modify: func(s *CloudSchedulerSourceSpec) {
+			s.TimeZone = "Mars/Olympus_Mons"
+		},
+		want: apis.ErrInvalidValue("Mars/Olympus_Mons", "timeZone"),
+	}, {
+		name: "local time zone",
+		modify: func(s *CloudSchedulerSourceSpec) {
+			s.TimeZone = "Local"
+		},
+		want: apis.ErrInvalidValue("Local", "timeZone"),
+	}, {
+		name: "json data",
+		modify: func(s *CloudSchedulerSourceSpec) {
+			s.Data = ""
+			s.JSONData = &runtime.RawExtension{Raw: []byte(`{"report":"daily"}`)}
+		},
+	}, {
+		name: "invalid json data",
+		modify: func(s *CloudSchedulerSourceSpec) {
+			s.Data = ""
+			s.JSONData = &runtime.RawExtension{Raw: []byte(`{"report":`)}
+		},
+		want: apis.ErrInvalidValue(`{"report":`, "jsonData"),
+	}, {
+		name: "binary data",
+		modify: func(s *CloudSchedulerSourceSpec) {
+			s.Data = ""
+			s.BinaryData = []byte{0xca, 0xfe}
+		},
+	}, {
+		name: "data and binary data",
+		modify: func(s *CloudSchedulerSourceSpec) {
+			s.BinaryData = []byte{0xca, 0xfe}
+		},
+		want: apis.ErrMultipleOneOf("data", "binaryData"),
+	}, {
+		name: "attributes",
+		modify: func(s *CloudSchedulerSourceSpec) {
+			s.Attributes = map[string]string{"team": "billing", "report2": "daily"}
+		},
+	}, {
+		name: "invalid attribute name",
+		modify: func(s *CloudSchedulerSourceSpec) {
+			s.Attributes = map[string]string{"Team-Name": "billing"}
+		},
+		want: apis.ErrInvalidKeyName("Team-Name", "attributes", "should match ^[a-z0-9]{1,20}$"),
+	}, {
+		name: "reserved attribute name",
+		modify: func(s *CloudSchedulerSourceSpec) {
+			s.Attributes = map[string]string{"source": "billing"}
+		},
+		want: apis.ErrInvalidKeyName("source", "attributes", "is a reserved CloudEvents attribute"),
+	}, {
+		name: "retry config",
+		modify: func(s *CloudSchedulerSourceSpec) {
+			s.RetryConfig = &CloudSchedulerSourceRetryConfig{
+				RetryCount:         ptr.Int32(3),
+				MaxRetryDuration:   ptr.String("1h"),
+				MinBackoffDuration: ptr.String("10s"),
+				MaxBackoffDuration: ptr.String("5m"),
+				MaxDoublings:       ptr.Int32(2),
+			}
+		},
+	}, {
+		name: "retry count out of bounds",
+		modify: func(s *CloudSchedulerSourceSpec) {
+			s.RetryConfig = &CloudSchedulerSourceRetryConfig{RetryCount: ptr.Int32(6)}
+		},
+		want: apis.ErrOutOfBoundsValue(int32(6), 0, 5, "retryConfig.retryCount"),
+	}, {
+		name: "invalid retry durations",
+		modify: func(s *CloudSchedulerSourceSpec) {
+			s.RetryConfig = &CloudSchedulerSourceRetryConfig{
+				MaxRetryDuration: ptr.String("-1h"),
+				MaxDoublings:     ptr.Int32(-1),
+			}
+		},
+		want: apis.ErrInvalidValue(int32(-1), "retryConfig.maxDoublings").Also(
+			apis.ErrInvalidValue("-1h", "retryConfig.maxRetryDuration")),
+	}, {
+		name: "zero backoff",
+		modify: func(s *CloudSchedulerSourceSpec) {
+			s.RetryConfig = &CloudSchedulerSourceRetryConfig{
+				MaxRetryDuration:   ptr.String("0s"),
+				MinBackoffDuration: ptr.String("0s"),
+			}
+		},
+		want: apis.ErrInvalidValue("0s", "retryConfig.minBackoffDuration"),
+	}, {
+		name: "min backoff greater than max backoff",
+		modify: func(s *CloudSchedulerSourceSpec) {
+			s.RetryConfig = &CloudSchedulerSourceRetryConfig{
+				MinBackoffDuration: ptr.String("1h"),
+				MaxBackoffDuration: ptr.String("5m"),
+			}
+		},
+		want: &apis.FieldError{
+			Message: "minBackoffDuration must not be greater than maxBackoffDuration",
+			Paths:   []string{"retryConfig.minBackoffDuration", "retryConfig.maxBackoffDuration"},
+		},
+	}}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			spec := minimalCloudSchedulerSourceSpec.DeepCopy()
+			test.modify(spec)
+			got := spec.Validate(context.TODO())
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("%s: Validate CloudSchedulerSourceSpec (-want, +got) = %v", test.name, diff)
+			}
+		})
+	}
+}
+
 func TestCloudSchedulerSourceSpecCheckImmutableFields(t *testing.T) {
 	testCases := map[string]struct {
 		orig              interface{}
@@ -635,6 +762,42 @@ func TestCloudSchedulerSourceSpecCheckImmutableFields(t *testing.T) {
 			},
 			allowed: true,
 		},
+		"TimeZone changed": {
+			orig: &schedulerWithSecret,
+			updated: func() CloudSchedulerSourceSpec {
+				s := *schedulerWithSecret.DeepCopy()
+				s.TimeZone = "Europe/Paris"
+				return s
+			}(),
+			allowed: false,
+		},
+		"Attributes changed": {
+			orig: &schedulerWithSecret,
+			updated: func() CloudSchedulerSourceSpec {
+				s := *schedulerWithSecret.DeepCopy()
+				s.Attributes = map[string]string{"team": "billing"}
+				return s
+			}(),
+			allowed: false,
+		},
+		"RetryConfig changed": {
+			orig: &schedulerWithSecret,
+			updated: func() CloudSchedulerSourceSpec {
+				s := *schedulerWithSecret.DeepCopy()
+				s.RetryConfig = &CloudSchedulerSourceRetryConfig{RetryCount: ptr.Int32(3)}
+				return s
+			}(),
+			allowed: false,
+		},
+		"Paused changed": {
+			orig: &schedulerWithSecret,
+			updated: func() CloudSchedulerSourceSpec {
+				s := *schedulerWithSecret.DeepCopy()
+				s.Paused = true
+				return s
+			}(),
+			allowed: true,
+		},
 		"no change": {
 			orig:    &schedulerWithSecret,
 			updated: schedulerWithSecret,
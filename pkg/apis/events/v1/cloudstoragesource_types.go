@@ -61,8 +61,18 @@ type CloudStorageSourceSpec struct {
 	// Sink, CloudEventOverrides, Secret and Project
 	gcpduckv1.PubSubSpec `json:",inline"`
 
-	// Bucket to subscribe to.
-	Bucket string `json:"bucket"`
+	// Bucket to subscribe to. Exactly one of Bucket, Buckets and BucketSelector must be set.
+	// +optional
+	Bucket string `json:"bucket,omitempty"`
+
+	// Buckets to subscribe to.
+	// +optional
+	Buckets []string `json:"buckets,omitempty"`
+
+	// BucketSelector selects the buckets of the project to subscribe to by their labels. The
+	// buckets are listed again each time the source is reconciled.
+	// +optional
+	BucketSelector *metav1.LabelSelector `json:"bucketSelector,omitempty"`
 
 	// EventTypes to subscribe to. If unspecified, then subscribe to all events.
 	// +optional
@@ -71,6 +81,33 @@ type CloudStorageSourceSpec struct {
 	// ObjectNamePrefix limits the notifications to objects with this prefix
 	// +optional
 	ObjectNamePrefix string `json:"objectNamePrefix,omitempty"`
+
+	// IncludeObjectNames are glob patterns of the names of the objects whose events are sent. If
+	// unspecified, then the events of all the objects are sent. In the patterns, `*` matches any
+	// sequence of characters other than `/`, `**` matches any sequence of characters and `?`
+	// matches any character other than `/`.
+	// +optional
+	IncludeObjectNames []string `json:"includeObjectNames,omitempty"`
+
+	// ExcludeObjectNames are glob patterns of the names of the objects whose events are not sent,
+	// even if they match IncludeObjectNames.
+	// +optional
+	ExcludeObjectNames []string `json:"excludeObjectNames,omitempty"`
+}
+
+// BucketNames returns the buckets listed in the spec, which are the buckets subscribed to unless
+// BucketSelector is set.
+func (s *CloudStorageSourceSpec) BucketNames() []string {
+	if s.Bucket != "" {
+		return []string{s.Bucket}
+	}
+	return s.Buckets
+}
+
+// IsMultiBucket returns true if the source may subscribe to more than one bucket, in which case
+// the IDs of its notifications are in Notifications rather than NotificationID.
+func (s *CloudStorageSourceSpec) IsMultiBucket() bool {
+	return s.Bucket == ""
 }
 
 const (
@@ -96,6 +133,20 @@ type CloudStorageSourceStatus struct {
 	// NotificationID is the ID that GCS identifies this notification as.
 	// +optional
 	NotificationID string `json:"notificationId,omitempty"`
+
+	// Notifications are the notifications of the buckets, when the source subscribes to Buckets
+	// or to the buckets of BucketSelector.
+	// +optional
+	Notifications []BucketNotification `json:"notifications,omitempty"`
+}
+
+// BucketNotification is the notification of a bucket a CloudStorageSource subscribes to.
+type BucketNotification struct {
+	// Bucket is the name of the bucket.
+	Bucket string `json:"bucket"`
+
+	// NotificationID is the ID that GCS identifies the notification of the bucket as.
+	NotificationID string `json:"notificationId"`
 }
 
 func (storage *CloudStorageSource) GetGroupVersionKind() schema.GroupVersionKind {
@@ -20,12 +20,14 @@ import (
 	"context"
 
 	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/knative-gcp/pkg/apis/duck"
+	"github.com/google/knative-gcp/pkg/utils/glob"
 )
 
 func (current *CloudStorageSource) Validate(ctx context.Context) *apis.FieldError {
@@ -35,6 +37,8 @@ func (current *CloudStorageSource) Validate(ctx context.Context) *apis.FieldErro
 		original := apis.GetBaseline(ctx).(*CloudStorageSource)
 		errs = errs.Also(current.CheckImmutableFields(ctx, original))
 	}
+	errs = duck.ValidateUnconvertibleSinkAnnotation(current.Annotations, errs)
+	errs = duck.ValidateDataValidationAnnotation(current.Annotations, errs)
 	return duck.ValidateAutoscalingAnnotations(ctx, current.Annotations, errs)
 }
 
@@ -48,10 +52,11 @@ func (current *CloudStorageSourceSpec) Validate(ctx context.Context) *apis.Field
 		errs = errs.Also(err.ViaField("sink"))
 	}
 
-	// Bucket [required]
-	if current.Bucket == "" {
-		errs = errs.Also(apis.ErrMissingField("bucket"))
-	}
+	// Exactly one of Bucket, Buckets and BucketSelector [required]
+	errs = errs.Also(current.validateBuckets())
+
+	errs = errs.Also(validateObjectNames(current.IncludeObjectNames).ViaField("includeObjectNames"))
+	errs = errs.Also(validateObjectNames(current.ExcludeObjectNames).ViaField("excludeObjectNames"))
 
 	if err := duck.ValidateCredential(current.Secret, current.ServiceAccountName); err != nil {
 		errs = errs.Also(err)
@@ -60,6 +65,52 @@ func (current *CloudStorageSourceSpec) Validate(ctx context.Context) *apis.Field
 	return errs
 }
 
+func (current *CloudStorageSourceSpec) validateBuckets() *apis.FieldError {
+	var set []string
+	if current.Bucket != "" {
+		set = append(set, "bucket")
+	}
+	if len(current.Buckets) != 0 {
+		set = append(set, "buckets")
+	}
+	if current.BucketSelector != nil {
+		set = append(set, "bucketSelector")
+	}
+	switch {
+	case len(set) == 0:
+		return apis.ErrMissingField("bucket")
+	case len(set) > 1:
+		return apis.ErrMultipleOneOf(set...)
+	}
+
+	var errs *apis.FieldError
+	seen := make(map[string]bool, len(current.Buckets))
+	for i, b := range current.Buckets {
+		if b == "" {
+			errs = errs.Also(apis.ErrInvalidArrayValue(b, "buckets", i))
+		} else if seen[b] {
+			errs = errs.Also(apis.ErrGeneric("duplicate bucket", apis.CurrentField).ViaFieldIndex("buckets", i))
+		}
+		seen[b] = true
+	}
+	if current.BucketSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(current.BucketSelector); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(err.Error(), "bucketSelector"))
+		}
+	}
+	return errs
+}
+
+func validateObjectNames(patterns []string) *apis.FieldError {
+	var errs *apis.FieldError
+	for i, p := range patterns {
+		if _, err := glob.Compile(p); err != nil {
+			errs = errs.Also(apis.ErrInvalidArrayValue(p, apis.CurrentField, i))
+		}
+	}
+	return errs
+}
+
 func (current *CloudStorageSource) CheckImmutableFields(ctx context.Context, original *CloudStorageSource) *apis.FieldError {
 	if original == nil {
 		return nil
@@ -67,10 +118,10 @@ func (current *CloudStorageSource) CheckImmutableFields(ctx context.Context, ori
 
 	var errs *apis.FieldError
 	// Modification of EventType, Secret, ServiceAccountName, Project, Bucket, PayloadFormat, EventType, ObjectNamePrefix are not allowed.
-	// Everything else is mutable.
+	// Everything else is mutable, including Buckets, BucketSelector and the object name patterns.
 	if diff := cmp.Diff(original.Spec, current.Spec,
 		cmpopts.IgnoreFields(CloudStorageSourceSpec{},
-			"Sink", "CloudEventOverrides")); diff != "" {
+			"Sink", "CloudEventOverrides", "Buckets", "BucketSelector", "IncludeObjectNames", "ExcludeObjectNames")); diff != "" {
 		errs = errs.Also(&apis.FieldError{
 			Message: "Immutable fields changed (-old +new)",
 			Paths:   []string{"spec"},
@@ -141,6 +141,24 @@ func TestValidationFields(t *testing.T) {
 			fe := apis.ErrMissingField("spec.sink")
 			return fe
 		}(),
+	}, {
+		name: "invalid data validation annotation",
+		s: &CloudStorageSource{
+			ObjectMeta: v1.ObjectMeta{
+				Annotations: map[string]string{duck.DataValidationAnnotation: "ignore"},
+			},
+			Spec: minimalCloudStorageSourceSpec,
+		},
+		want: apis.ErrInvalidValue("ignore", "metadata.annotations["+duck.DataValidationAnnotation+"]"),
+	}, {
+		name: "relative unconvertible sink annotation",
+		s: &CloudStorageSource{
+			ObjectMeta: v1.ObjectMeta{
+				Annotations: map[string]string{duck.UnconvertibleSinkAnnotation: "/unconvertible"},
+			},
+			Spec: minimalCloudStorageSourceSpec,
+		},
+		want: apis.ErrInvalidValue("/unconvertible", "metadata.annotations["+duck.UnconvertibleSinkAnnotation+"]"),
 	}}
 	for _, test := range testCases {
 		t.Run(test.name, func(t *testing.T) {
@@ -208,6 +226,59 @@ func TestSpecValidationFields(t *testing.T) {
 			fe := apis.ErrMissingField("bucket")
 			return fe
 		}(),
+	}, {
+		name: "bucket and buckets",
+		spec: &CloudStorageSourceSpec{
+			Bucket:     "my-test-bucket",
+			Buckets:    []string{"my-other-bucket"},
+			PubSubSpec: storageSourceSpec.PubSubSpec,
+		},
+		want: apis.ErrMultipleOneOf("bucket", "buckets"),
+	}, {
+		name: "buckets and bucket selector",
+		spec: &CloudStorageSourceSpec{
+			Buckets:        []string{"my-test-bucket"},
+			BucketSelector: &v1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			PubSubSpec:     storageSourceSpec.PubSubSpec,
+		},
+		want: apis.ErrMultipleOneOf("buckets", "bucketSelector"),
+	}, {
+		name: "empty and duplicate buckets",
+		spec: &CloudStorageSourceSpec{
+			Buckets:    []string{"my-test-bucket", "", "my-test-bucket"},
+			PubSubSpec: storageSourceSpec.PubSubSpec,
+		},
+		want: apis.ErrInvalidArrayValue("", "buckets", 1).Also(
+			apis.ErrGeneric("duplicate bucket", "buckets[2]")),
+	}, {
+		name: "invalid bucket selector",
+		spec: &CloudStorageSourceSpec{
+			BucketSelector: &v1.LabelSelector{MatchExpressions: []v1.LabelSelectorRequirement{{
+				Key:      "env",
+				Operator: "Like",
+			}}},
+			PubSubSpec: storageSourceSpec.PubSubSpec,
+		},
+		want: apis.ErrInvalidValue(`"Like" is not a valid pod selector operator`, "bucketSelector"),
+	}, {
+		name: "invalid object name patterns",
+		spec: &CloudStorageSourceSpec{
+			Buckets:            []string{"my-test-bucket"},
+			IncludeObjectNames: []string{"**.csv", ""},
+			ExcludeObjectNames: []string{""},
+			PubSubSpec:         storageSourceSpec.PubSubSpec,
+		},
+		want: apis.ErrInvalidArrayValue("", "includeObjectNames", 1).Also(
+			apis.ErrInvalidArrayValue("", "excludeObjectNames", 0)),
+	}, {
+		name: "bucket selector and object name patterns",
+		spec: &CloudStorageSourceSpec{
+			BucketSelector:     &v1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			IncludeObjectNames: []string{"**.csv"},
+			ExcludeObjectNames: []string{"tmp/**"},
+			PubSubSpec:         storageSourceSpec.PubSubSpec,
+		},
+		want: nil,
 	}, {
 		name: "invalid secret, missing name",
 		spec: &CloudStorageSourceSpec{
@@ -625,6 +696,30 @@ func TestCheckImmutableFields(t *testing.T) {
 			},
 			allowed: true,
 		},
+		"Buckets and object name patterns changed": {
+			orig: &CloudStorageSourceSpec{
+				Buckets:    []string{"my-test-bucket"},
+				PubSubSpec: storageSourceSpec.PubSubSpec,
+			},
+			updated: CloudStorageSourceSpec{
+				Buckets:            []string{"my-test-bucket", "my-other-bucket"},
+				IncludeObjectNames: []string{"**.csv"},
+				ExcludeObjectNames: []string{"tmp/**"},
+				PubSubSpec:         storageSourceSpec.PubSubSpec,
+			},
+			allowed: true,
+		},
+		"Buckets changed to BucketSelector": {
+			orig: &CloudStorageSourceSpec{
+				Buckets:    []string{"my-test-bucket"},
+				PubSubSpec: storageSourceSpec.PubSubSpec,
+			},
+			updated: CloudStorageSourceSpec{
+				BucketSelector: &v1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+				PubSubSpec:     storageSourceSpec.PubSubSpec,
+			},
+			allowed: true,
+		},
 		"no change": {
 			orig:    &storageSourceSpec,
 			updated: storageSourceSpec,
@@ -21,9 +21,26 @@ limitations under the License.
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketNotification) DeepCopyInto(out *BucketNotification) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketNotification.
+func (in *BucketNotification) DeepCopy() *BucketNotification {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketNotification)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudAuditLogsSource) DeepCopyInto(out *CloudAuditLogsSource) {
 	*out = *in
@@ -89,6 +106,16 @@ func (in *CloudAuditLogsSourceList) DeepCopyObject() runtime.Object {
 func (in *CloudAuditLogsSourceSpec) DeepCopyInto(out *CloudAuditLogsSourceSpec) {
 	*out = *in
 	in.PubSubSpec.DeepCopyInto(&out.PubSubSpec)
+	if in.MethodNames != nil {
+		in, out := &in.MethodNames, &out.MethodNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PrincipalEmails != nil {
+		in, out := &in.PrincipalEmails, &out.PrincipalEmails
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -380,10 +407,73 @@ func (in *CloudSchedulerSourceList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudSchedulerSourceRetryConfig) DeepCopyInto(out *CloudSchedulerSourceRetryConfig) {
+	*out = *in
+	if in.RetryCount != nil {
+		in, out := &in.RetryCount, &out.RetryCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxRetryDuration != nil {
+		in, out := &in.MaxRetryDuration, &out.MaxRetryDuration
+		*out = new(string)
+		**out = **in
+	}
+	if in.MinBackoffDuration != nil {
+		in, out := &in.MinBackoffDuration, &out.MinBackoffDuration
+		*out = new(string)
+		**out = **in
+	}
+	if in.MaxBackoffDuration != nil {
+		in, out := &in.MaxBackoffDuration, &out.MaxBackoffDuration
+		*out = new(string)
+		**out = **in
+	}
+	if in.MaxDoublings != nil {
+		in, out := &in.MaxDoublings, &out.MaxDoublings
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudSchedulerSourceRetryConfig.
+func (in *CloudSchedulerSourceRetryConfig) DeepCopy() *CloudSchedulerSourceRetryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudSchedulerSourceRetryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudSchedulerSourceSpec) DeepCopyInto(out *CloudSchedulerSourceSpec) {
 	*out = *in
 	in.PubSubSpec.DeepCopyInto(&out.PubSubSpec)
+	if in.JSONData != nil {
+		in, out := &in.JSONData, &out.JSONData
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BinaryData != nil {
+		in, out := &in.BinaryData, &out.BinaryData
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.Attributes != nil {
+		in, out := &in.Attributes, &out.Attributes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RetryConfig != nil {
+		in, out := &in.RetryConfig, &out.RetryConfig
+		*out = new(CloudSchedulerSourceRetryConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -479,11 +569,31 @@ func (in *CloudStorageSourceList) DeepCopyObject() runtime.Object {
 func (in *CloudStorageSourceSpec) DeepCopyInto(out *CloudStorageSourceSpec) {
 	*out = *in
 	in.PubSubSpec.DeepCopyInto(&out.PubSubSpec)
+	if in.Buckets != nil {
+		in, out := &in.Buckets, &out.Buckets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BucketSelector != nil {
+		in, out := &in.BucketSelector, &out.BucketSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.EventTypes != nil {
 		in, out := &in.EventTypes, &out.EventTypes
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.IncludeObjectNames != nil {
+		in, out := &in.IncludeObjectNames, &out.IncludeObjectNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeObjectNames != nil {
+		in, out := &in.ExcludeObjectNames, &out.ExcludeObjectNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -501,6 +611,11 @@ func (in *CloudStorageSourceSpec) DeepCopy() *CloudStorageSourceSpec {
 func (in *CloudStorageSourceStatus) DeepCopyInto(out *CloudStorageSourceStatus) {
 	*out = *in
 	in.PubSubStatus.DeepCopyInto(&out.PubSubStatus)
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]BucketNotification, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -182,3 +182,33 @@ func TestCloudStorageSourceConversionBetweenV1(t *testing.T) {
 		}
 	}
 }
+
+func TestCloudStorageSourceConversionFromV1MultiBucket(t *testing.T) {
+	in := &v1.CloudStorageSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ps-name",
+			Namespace: "ps-ns",
+		},
+		Spec: v1.CloudStorageSourceSpec{
+			Buckets:            []string{"bucket1", "bucket2"},
+			IncludeObjectNames: []string{"*.csv"},
+			ExcludeObjectNames: []string{"tmp/*"},
+		},
+		Status: v1.CloudStorageSourceStatus{
+			Notifications: []v1.BucketNotification{{Bucket: "bucket1", NotificationID: "1"}},
+		},
+	}
+
+	// The fields that v1alpha1 doesn't have are kept through v1beta1.
+	mid := &CloudStorageSource{}
+	if err := mid.ConvertFrom(context.Background(), in); err != nil {
+		t.Fatalf("ConvertFrom() = %v", err)
+	}
+	got := &v1.CloudStorageSource{}
+	if err := mid.ConvertTo(context.Background(), got); err != nil {
+		t.Fatalf("ConvertTo() = %v", err)
+	}
+	if diff := cmp.Diff(in, got, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("roundtrip (-want, +got) = %v", diff)
+	}
+}
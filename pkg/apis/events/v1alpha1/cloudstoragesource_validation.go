@@ -35,6 +35,8 @@ func (current *CloudStorageSource) Validate(ctx context.Context) *apis.FieldErro
 		original := apis.GetBaseline(ctx).(*CloudStorageSource)
 		errs = errs.Also(current.CheckImmutableFields(ctx, original))
 	}
+	errs = duck.ValidateUnconvertibleSinkAnnotation(current.Annotations, errs)
+	errs = duck.ValidateDataValidationAnnotation(current.Annotations, errs)
 	return duck.ValidateAutoscalingAnnotations(ctx, current.Annotations, errs)
 }
 
@@ -18,12 +18,39 @@ package v1beta1
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
 	"github.com/google/knative-gcp/pkg/apis/convert"
 	v1 "github.com/google/knative-gcp/pkg/apis/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 )
 
+const (
+	// CloudStorageSourceV1SpecAnnotationKey is the annotation holding the JSON encoded fields of
+	// the spec of a v1 CloudStorageSource that v1beta1 doesn't have, so that they aren't lost when
+	// the source is converted to v1beta1 and back.
+	CloudStorageSourceV1SpecAnnotationKey = "internal.events.cloud.google.com/v1-spec"
+	// CloudStorageSourceV1StatusAnnotationKey is the status annotation holding the JSON encoded
+	// fields of the status of a v1 CloudStorageSource that v1beta1 doesn't have.
+	CloudStorageSourceV1StatusAnnotationKey = "internal.events.cloud.google.com/v1-status"
+)
+
+// cloudStorageSourceV1Spec holds the fields of v1.CloudStorageSourceSpec that v1beta1 doesn't have.
+type cloudStorageSourceV1Spec struct {
+	Buckets            []string              `json:"buckets,omitempty"`
+	BucketSelector     *metav1.LabelSelector `json:"bucketSelector,omitempty"`
+	IncludeObjectNames []string              `json:"includeObjectNames,omitempty"`
+	ExcludeObjectNames []string              `json:"excludeObjectNames,omitempty"`
+}
+
+// cloudStorageSourceV1Status holds the fields of v1.CloudStorageSourceStatus that v1beta1 doesn't
+// have.
+type cloudStorageSourceV1Status struct {
+	Notifications []v1.BucketNotification `json:"notifications,omitempty"`
+}
+
 // ConvertTo implements apis.Convertible.
 // Converts from v1beta1.CloudStorageSource to a higher version.
 // Currently, we only support v1 as a higher version.
@@ -38,6 +65,21 @@ func (source *CloudStorageSource) ConvertTo(ctx context.Context, to apis.Convert
 		sink.Spec.ObjectNamePrefix = source.Spec.ObjectNamePrefix
 		sink.Status.PubSubStatus = convert.ToV1PubSubStatus(source.Status.PubSubStatus)
 		sink.Status.NotificationID = source.Status.NotificationID
+
+		var spec cloudStorageSourceV1Spec
+		var err error
+		if sink.Annotations, err = popJSONAnnotation(sink.Annotations, CloudStorageSourceV1SpecAnnotationKey, &spec); err != nil {
+			return err
+		}
+		sink.Spec.Buckets = spec.Buckets
+		sink.Spec.BucketSelector = spec.BucketSelector
+		sink.Spec.IncludeObjectNames = spec.IncludeObjectNames
+		sink.Spec.ExcludeObjectNames = spec.ExcludeObjectNames
+		var status cloudStorageSourceV1Status
+		if sink.Status.Annotations, err = popJSONAnnotation(sink.Status.Annotations, CloudStorageSourceV1StatusAnnotationKey, &status); err != nil {
+			return err
+		}
+		sink.Status.Notifications = status.Notifications
 		return nil
 	default:
 		return apis.ConvertToViaProxy(ctx, source, &v1.CloudStorageSource{}, sink)
@@ -59,8 +101,76 @@ func (sink *CloudStorageSource) ConvertFrom(ctx context.Context, from apis.Conve
 		sink.Spec.ObjectNamePrefix = source.Spec.ObjectNamePrefix
 		sink.Status.PubSubStatus = convert.FromV1PubSubStatus(source.Status.PubSubStatus)
 		sink.Status.NotificationID = source.Status.NotificationID
+
+		// v1beta1 only has a single Bucket, keep the other fields in annotations, see
+		// CloudStorageSourceV1SpecAnnotationKey.
+		var spec, status interface{}
+		if len(source.Spec.Buckets) > 0 || source.Spec.BucketSelector != nil ||
+			len(source.Spec.IncludeObjectNames) > 0 || len(source.Spec.ExcludeObjectNames) > 0 {
+			spec = cloudStorageSourceV1Spec{
+				Buckets:            source.Spec.Buckets,
+				BucketSelector:     source.Spec.BucketSelector,
+				IncludeObjectNames: source.Spec.IncludeObjectNames,
+				ExcludeObjectNames: source.Spec.ExcludeObjectNames,
+			}
+		}
+		if len(source.Status.Notifications) > 0 {
+			status = cloudStorageSourceV1Status{Notifications: source.Status.Notifications}
+		}
+		var err error
+		if sink.Annotations, err = setJSONAnnotation(sink.Annotations, CloudStorageSourceV1SpecAnnotationKey, spec); err != nil {
+			return err
+		}
+		if sink.Status.Annotations, err = setJSONAnnotation(sink.Status.Annotations, CloudStorageSourceV1StatusAnnotationKey, status); err != nil {
+			return err
+		}
 		return nil
 	default:
 		return apis.ConvertFromViaProxy(ctx, source, &v1.CloudStorageSource{}, sink)
 	}
 }
+
+// setJSONAnnotation returns annotations with the JSON encoding of v at key, or without key if v is
+// nil. annotations is copied rather than modified, since it is shared with the converted object.
+func setJSONAnnotation(annotations map[string]string, key string, v interface{}) (map[string]string, error) {
+	if _, ok := annotations[key]; !ok && v == nil {
+		return annotations, nil
+	}
+	copied := make(map[string]string, len(annotations)+1)
+	for k, v := range annotations {
+		copied[k] = v
+	}
+	if v == nil {
+		delete(copied, key)
+		return copied, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode annotation %s: %w", key, err)
+	}
+	copied[key] = string(b)
+	return copied, nil
+}
+
+// popJSONAnnotation decodes the JSON annotation at key, if any, into v, and returns annotations
+// without key, or nil if it has no other annotation. annotations is copied rather than modified,
+// since it is shared with the converted object.
+func popJSONAnnotation(annotations map[string]string, key string, v interface{}) (map[string]string, error) {
+	value, ok := annotations[key]
+	if !ok {
+		return annotations, nil
+	}
+	if err := json.Unmarshal([]byte(value), v); err != nil {
+		return nil, fmt.Errorf("failed to decode annotation %s: %w", key, err)
+	}
+	if len(annotations) == 1 {
+		return nil, nil
+	}
+	copied := make(map[string]string, len(annotations)-1)
+	for k, v := range annotations {
+		if k != key {
+			copied[k] = v
+		}
+	}
+	return copied, nil
+}
@@ -109,3 +109,48 @@ func TestCloudStorageSourceConversion(t *testing.T) {
 		}
 	}
 }
+
+func TestCloudStorageSourceConversionFromV1MultiBucket(t *testing.T) {
+	in := &v1.CloudStorageSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "ps-name",
+			Namespace:   "ps-ns",
+			Annotations: map[string]string{"foo": "bar"},
+		},
+		Spec: v1.CloudStorageSourceSpec{
+			Buckets: []string{"bucket1", "bucket2"},
+			BucketSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"env": "prod"},
+			},
+			IncludeObjectNames: []string{"*.csv"},
+			ExcludeObjectNames: []string{"tmp/*"},
+		},
+		Status: v1.CloudStorageSourceStatus{
+			Notifications: []v1.BucketNotification{{Bucket: "bucket1", NotificationID: "1"}},
+		},
+	}
+	want := in.DeepCopy()
+
+	mid := &CloudStorageSource{}
+	if err := mid.ConvertFrom(context.Background(), in); err != nil {
+		t.Fatalf("ConvertFrom() = %v", err)
+	}
+	if _, ok := mid.Annotations[CloudStorageSourceV1SpecAnnotationKey]; !ok {
+		t.Errorf("ConvertFrom() didn't set the %s annotation", CloudStorageSourceV1SpecAnnotationKey)
+	}
+	if _, ok := mid.Status.Annotations[CloudStorageSourceV1StatusAnnotationKey]; !ok {
+		t.Errorf("ConvertFrom() didn't set the %s status annotation", CloudStorageSourceV1StatusAnnotationKey)
+	}
+	// The converted source is left untouched.
+	if diff := cmp.Diff(want, in); diff != "" {
+		t.Errorf("ConvertFrom() modified its source (-want, +got) = %v", diff)
+	}
+
+	got := &v1.CloudStorageSource{}
+	if err := mid.ConvertTo(context.Background(), got); err != nil {
+		t.Fatalf("ConvertTo() = %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("roundtrip (-want, +got) = %v", diff)
+	}
+}
@@ -43,6 +43,12 @@ const (
 	MinAckDeadline = 0 * time.Second
 	// MinAckDeadline is the maximum ack deadline (10 minutes) to validate the pullSubscription.
 	MaxAckDeadline = 10 * time.Minute
+	// MaxBackoff is the maximum retry backoff (10 minutes) to validate the pullSubscription.
+	MaxBackoff = 10 * time.Minute
+	// MinDeliveryAttempts is the minimum number of delivery attempts before dead lettering to validate the pullSubscription.
+	MinDeliveryAttempts = 5
+	// MaxDeliveryAttempts is the maximum number of delivery attempts before dead lettering to validate the pullSubscription.
+	MaxDeliveryAttempts = 100
 )
 
 var (
@@ -94,6 +94,44 @@ type PullSubscriptionSpec struct {
 	// PullSubscription uses.
 	// +optional
 	AdapterType string `json:"adapterType,omitempty"`
+
+	// RetryPolicy is the policy with which Pub/Sub retries the delivery of
+	// the events nacked by the receive adapter. Pub/Sub retries immediately if
+	// unset.
+	// +optional
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+
+	// DeadLetterPolicy is the policy with which Pub/Sub forwards the events
+	// that could not be delivered to a dead letter topic.
+	// +optional
+	DeadLetterPolicy *DeadLetterPolicy `json:"deadLetterPolicy,omitempty"`
+}
+
+// RetryPolicy defines the backoff between the deliveries of an event.
+type RetryPolicy struct {
+	// MinimumBackoff is the minimum delay between consecutive deliveries of
+	// an event. Defaults to 10 seconds ('10s'). Cannot be longer than 600
+	// seconds.
+	// +optional
+	MinimumBackoff *string `json:"minimumBackoff,omitempty"`
+
+	// MaximumBackoff is the maximum delay between consecutive deliveries of
+	// an event. Defaults to 600 seconds ('600s'). Cannot be longer than 600
+	// seconds.
+	// +optional
+	MaximumBackoff *string `json:"maximumBackoff,omitempty"`
+}
+
+// DeadLetterPolicy defines when the events are forwarded to a dead letter
+// topic.
+type DeadLetterPolicy struct {
+	// DeadLetterTopic is the ID of the Pub/Sub topic the events are forwarded
+	// to, in the project of the PullSubscription.
+	DeadLetterTopic string `json:"deadLetterTopic"`
+
+	// MaxDeliveryAttempts is the number of delivery attempts of an event
+	// before it is forwarded to the dead letter topic, between 5 and 100.
+	MaxDeliveryAttempts int32 `json:"maxDeliveryAttempts"`
 }
 
 // GetAckDeadline parses AckDeadline and returns the default if an error occurs.
@@ -38,6 +38,8 @@ func (current *PullSubscription) Validate(ctx context.Context) *apis.FieldError
 		original := apis.GetBaseline(ctx).(*PullSubscription)
 		errs = errs.Also(current.CheckImmutableFields(ctx, original))
 	}
+	errs = duck.ValidateUnconvertibleSinkAnnotation(current.Annotations, errs)
+	errs = duck.ValidateDataValidationAnnotation(current.Annotations, errs)
 	return duck.ValidateAutoscalingAnnotations(ctx, current.Annotations, errs)
 }
 
@@ -80,6 +82,14 @@ func (current *PullSubscriptionSpec) Validate(ctx context.Context) *apis.FieldEr
 		}
 	}
 
+	if current.RetryPolicy != nil {
+		errs = errs.Also(current.RetryPolicy.Validate(ctx).ViaField("retryPolicy"))
+	}
+
+	if current.DeadLetterPolicy != nil {
+		errs = errs.Also(current.DeadLetterPolicy.Validate(ctx).ViaField("deadLetterPolicy"))
+	}
+
 	if current.Secret != nil {
 		if !equality.Semantic.DeepEqual(current.Secret, &corev1.SecretKeySelector{}) {
 			err := validateSecret(current.Secret)
@@ -92,6 +102,50 @@ func (current *PullSubscriptionSpec) Validate(ctx context.Context) *apis.FieldEr
 	return errs
 }
 
+func (rp *RetryPolicy) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	var minBackoff, maxBackoff time.Duration
+	if rp.MinimumBackoff != nil {
+		b, err := validateBackoff(*rp.MinimumBackoff, "minimumBackoff")
+		errs = errs.Also(err)
+		minBackoff = b
+	}
+	if rp.MaximumBackoff != nil {
+		b, err := validateBackoff(*rp.MaximumBackoff, "maximumBackoff")
+		errs = errs.Also(err)
+		maxBackoff = b
+	}
+	if errs == nil && rp.MinimumBackoff != nil && rp.MaximumBackoff != nil && minBackoff > maxBackoff {
+		errs = &apis.FieldError{
+			Message: "minimumBackoff cannot be longer than maximumBackoff",
+			Paths:   []string{"minimumBackoff"},
+		}
+	}
+	return errs
+}
+
+func validateBackoff(backoff, field string) (time.Duration, *apis.FieldError) {
+	b, err := time.ParseDuration(backoff)
+	if err != nil {
+		return 0, apis.ErrInvalidValue(backoff, field)
+	}
+	if b < 0 || b > intevents.MaxBackoff {
+		return 0, apis.ErrOutOfBoundsValue(backoff, "0s", intevents.MaxBackoff.String(), field)
+	}
+	return b, nil
+}
+
+func (dlp *DeadLetterPolicy) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	if dlp.DeadLetterTopic == "" {
+		errs = errs.Also(apis.ErrMissingField("deadLetterTopic"))
+	}
+	if dlp.MaxDeliveryAttempts < intevents.MinDeliveryAttempts || dlp.MaxDeliveryAttempts > intevents.MaxDeliveryAttempts {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(dlp.MaxDeliveryAttempts, intevents.MinDeliveryAttempts, intevents.MaxDeliveryAttempts, "maxDeliveryAttempts"))
+	}
+	return errs
+}
+
 // TODO move this to a common place.
 func validateSecret(secret *corev1.SecretKeySelector) *apis.FieldError {
 	var errs *apis.FieldError
@@ -114,7 +168,7 @@ func (current *PullSubscription) CheckImmutableFields(ctx context.Context, origi
 	// Everything else is mutable.
 	if diff := cmp.Diff(original.Spec, current.Spec,
 		cmpopts.IgnoreFields(PullSubscriptionSpec{},
-			"Sink", "Transformer", "CloudEventOverrides")); diff != "" {
+			"Sink", "Transformer", "CloudEventOverrides", "RetryPolicy", "DeadLetterPolicy")); diff != "" {
 		errs = errs.Also(&apis.FieldError{
 			Message: "Immutable fields changed (-old +new)",
 			Paths:   []string{"spec"},
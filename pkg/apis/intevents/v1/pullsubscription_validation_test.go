@@ -136,6 +136,73 @@ func TestPullSubscriptionCheckValidationFields(t *testing.T) {
 			}(),
 			error: true,
 		},
+		"ok RetryPolicy and DeadLetterPolicy": {
+			spec: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.RetryPolicy = &RetryPolicy{
+					MinimumBackoff: ptr.String("1s"),
+					MaximumBackoff: ptr.String("10m"),
+				}
+				obj.DeadLetterPolicy = &DeadLetterPolicy{
+					DeadLetterTopic:     "dead-letter",
+					MaxDeliveryAttempts: 5,
+				}
+				return *obj
+			}(),
+			error: false,
+		},
+		"bad RetryPolicy, MinimumBackoff": {
+			spec: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.RetryPolicy = &RetryPolicy{
+					MinimumBackoff: ptr.String("wrong"),
+				}
+				return *obj
+			}(),
+			error: true,
+		},
+		"bad RetryPolicy, MaximumBackoff range": {
+			spec: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.RetryPolicy = &RetryPolicy{
+					MaximumBackoff: ptr.String("11m"),
+				}
+				return *obj
+			}(),
+			error: true,
+		},
+		"bad RetryPolicy, MinimumBackoff longer than MaximumBackoff": {
+			spec: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.RetryPolicy = &RetryPolicy{
+					MinimumBackoff: ptr.String("2m"),
+					MaximumBackoff: ptr.String("1m"),
+				}
+				return *obj
+			}(),
+			error: true,
+		},
+		"bad DeadLetterPolicy, missing DeadLetterTopic": {
+			spec: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.DeadLetterPolicy = &DeadLetterPolicy{
+					MaxDeliveryAttempts: 5,
+				}
+				return *obj
+			}(),
+			error: true,
+		},
+		"bad DeadLetterPolicy, MaxDeliveryAttempts range": {
+			spec: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.DeadLetterPolicy = &DeadLetterPolicy{
+					DeadLetterTopic:     "dead-letter",
+					MaxDeliveryAttempts: 4,
+				}
+				return *obj
+			}(),
+			error: true,
+		},
 		"bad sink, name": {
 			spec: func() PullSubscriptionSpec {
 				obj := pullSubscriptionSpec.DeepCopy()
@@ -733,6 +800,21 @@ func TestPullSubscriptionCheckImmutableFields(t *testing.T) {
 			},
 			allowed: false,
 		},
+		"RetryPolicy and DeadLetterPolicy changed": {
+			orig: &pullSubscriptionSpec,
+			updated: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.RetryPolicy = &RetryPolicy{
+					MinimumBackoff: ptr.String("1s"),
+				}
+				obj.DeadLetterPolicy = &DeadLetterPolicy{
+					DeadLetterTopic:     "dead-letter",
+					MaxDeliveryAttempts: 5,
+				}
+				return *obj
+			}(),
+			allowed: true,
+		},
 		"ClusterName annotation added": {
 			origAnnotation: nil,
 			updatedAnnotation: map[string]string{
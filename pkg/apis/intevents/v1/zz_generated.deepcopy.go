@@ -27,6 +27,22 @@ import (
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeadLetterPolicy) DeepCopyInto(out *DeadLetterPolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeadLetterPolicy.
+func (in *DeadLetterPolicy) DeepCopy() *DeadLetterPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DeadLetterPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PullSubscription) DeepCopyInto(out *PullSubscription) {
 	*out = *in
@@ -107,6 +123,16 @@ func (in *PullSubscriptionSpec) DeepCopyInto(out *PullSubscriptionSpec) {
 		*out = new(duckv1.Destination)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(RetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeadLetterPolicy != nil {
+		in, out := &in.DeadLetterPolicy, &out.DeadLetterPolicy
+		*out = new(DeadLetterPolicy)
+		**out = **in
+	}
 	return
 }
 
@@ -142,6 +168,32 @@ func (in *PullSubscriptionStatus) DeepCopy() *PullSubscriptionStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryPolicy) DeepCopyInto(out *RetryPolicy) {
+	*out = *in
+	if in.MinimumBackoff != nil {
+		in, out := &in.MinimumBackoff, &out.MinimumBackoff
+		*out = new(string)
+		**out = **in
+	}
+	if in.MaximumBackoff != nil {
+		in, out := &in.MaximumBackoff, &out.MaximumBackoff
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryPolicy.
+func (in *RetryPolicy) DeepCopy() *RetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Topic) DeepCopyInto(out *Topic) {
 	*out = *in
@@ -0,0 +1,66 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"net"
+
+	"knative.dev/pkg/apis"
+)
+
+// DefaultRedisPort is the port used when the address of a RedisStreamSource
+// doesn't specify one.
+const (
+	DefaultRedisPort = "6379"
+
+	// DefaultRedisMaxDeliveries is the default number of times an entry is
+	// delivered before giving up on it.
+	DefaultRedisMaxDeliveries = 10
+)
+
+func (s *RedisStreamSource) SetDefaults(ctx context.Context) {
+	ctx = apis.WithinParent(ctx, s.ObjectMeta)
+	s.Spec.SetDefaults(ctx)
+}
+
+func (ss *RedisStreamSourceSpec) SetDefaults(ctx context.Context) {
+	if ss.MaxDeliveries == 0 {
+		ss.MaxDeliveries = DefaultRedisMaxDeliveries
+	}
+	ss.RedisConnection.SetDefaults(ctx)
+}
+
+func (rc *RedisConnection) SetDefaults(ctx context.Context) {
+	if rc.Address != "" {
+		if _, _, err := net.SplitHostPort(rc.Address); err != nil {
+			// The address has no port, use the default one.
+			rc.Address = net.JoinHostPort(rc.Address, DefaultRedisPort)
+		}
+	}
+
+	if rc.Options != nil {
+		rc.Options.SetDefaults(ctx)
+	}
+}
+
+func (o *RedisConnectionOptions) SetDefaults(ctx context.Context) {
+	// Certificates are only used with TLS, so providing one enables TLS.
+	if o.Cert.SecretKeyRef != nil || o.Key.SecretKeyRef != nil || o.CACert.SecretKeyRef != nil {
+		o.UseTLS = true
+	}
+}
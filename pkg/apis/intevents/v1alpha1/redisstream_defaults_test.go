@@ -0,0 +1,107 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestRedisStreamSourceDefaults(t *testing.T) {
+	caCert := RedisSecretValueFromSource{SecretKeyRef: &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "tls"},
+		Key:                  "ca.crt",
+	}}
+	testCases := map[string]struct {
+		start *RedisConnection
+		want  *RedisConnection
+	}{
+		"missing port": {
+			start: &RedisConnection{Address: "redis.default.svc"},
+			want:  &RedisConnection{Address: "redis.default.svc:6379"},
+		},
+		"missing port, ipv6": {
+			start: &RedisConnection{Address: "::1"},
+			want:  &RedisConnection{Address: "[::1]:6379"},
+		},
+		"port set": {
+			start: &RedisConnection{Address: "redis:1234"},
+			want:  &RedisConnection{Address: "redis:1234"},
+		},
+		"certificate enables TLS": {
+			start: &RedisConnection{
+				Address: "redis:6379",
+				Options: &RedisConnectionOptions{CACert: caCert},
+			},
+			want: &RedisConnection{
+				Address: "redis:6379",
+				Options: &RedisConnectionOptions{UseTLS: true, CACert: caCert},
+			},
+		},
+		"no certificate": {
+			start: &RedisConnection{
+				Address: "redis:6379",
+				Options: &RedisConnectionOptions{},
+			},
+			want: &RedisConnection{
+				Address: "redis:6379",
+				Options: &RedisConnectionOptions{},
+			},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			s := &RedisStreamSource{
+				Spec: RedisStreamSourceSpec{RedisConnection: *tc.start},
+			}
+			s.SetDefaults(context.Background())
+			if diff := cmp.Diff(*tc.want, s.Spec.RedisConnection); diff != "" {
+				t.Errorf("Unexpected defaults (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestRedisStreamSourceMaxDeliveriesDefaults(t *testing.T) {
+	testCases := map[string]struct {
+		start int32
+		want  int32
+	}{
+		"unset": {
+			start: 0,
+			want:  DefaultRedisMaxDeliveries,
+		},
+		"set": {
+			start: 3,
+			want:  3,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			s := &RedisStreamSource{
+				Spec: RedisStreamSourceSpec{MaxDeliveries: tc.start},
+			}
+			s.SetDefaults(context.Background())
+			if s.Spec.MaxDeliveries != tc.want {
+				t.Errorf("MaxDeliveries = %d, want %d", s.Spec.MaxDeliveries, tc.want)
+			}
+		})
+	}
+}
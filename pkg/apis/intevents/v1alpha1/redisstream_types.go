@@ -43,10 +43,10 @@ type RedisStreamSource struct {
 var (
 	_ runtime.Object     = (*RedisStreamSource)(nil)
 	_ kmeta.OwnerRefable = (*RedisStreamSource)(nil)
-	//_ apis.Validatable   = (*RedisStreamSource)(nil)
-	//_ apis.Defaultable   = (*RedisStreamSource)(nil)
-	_ apis.HasSpec    = (*RedisStreamSource)(nil)
-	_ duckv1.KRShaped = (*RedisStreamSource)(nil)
+	_ apis.Validatable   = (*RedisStreamSource)(nil)
+	_ apis.Defaultable   = (*RedisStreamSource)(nil)
+	_ apis.HasSpec       = (*RedisStreamSource)(nil)
+	_ duckv1.KRShaped    = (*RedisStreamSource)(nil)
 )
 
 // RedisStreamSourceSpec defines the desired state of the RedisStreamSource.
@@ -70,6 +70,16 @@ type RedisStreamSourceSpec struct {
 	// deleted when this source is deleted.
 	// +optional
 	Group string `json:"group,omitempty"`
+
+	// MaxDeliveries is the number of times an entry is delivered to the sink
+	// before giving up on it. Defaults to 10.
+	// +optional
+	MaxDeliveries int32 `json:"maxDeliveries,omitempty"`
+
+	// DeadLetterStream is the name of the stream the entries given up on are
+	// added to. When left empty, these entries are dropped.
+	// +optional
+	DeadLetterStream string `json:"deadLetterStream,omitempty"`
 }
 
 // RedisConnection defines the address and options to connect to a Redis instance
@@ -127,7 +137,7 @@ type RedisStreamSourceStatus struct {
 	duckv1.SourceStatus `json:",inline"`
 
 	// Group is the actual name of the consumer group associated to this source
-	Group string `json:",group"`
+	Group string `json:"group,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
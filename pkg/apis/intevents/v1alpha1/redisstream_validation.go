@@ -0,0 +1,162 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func (current *RedisStreamSource) Validate(ctx context.Context) *apis.FieldError {
+	errs := current.Spec.Validate(ctx).ViaField("spec")
+
+	if apis.IsInUpdate(ctx) {
+		original := apis.GetBaseline(ctx).(*RedisStreamSource)
+		errs = errs.Also(current.CheckImmutableFields(ctx, original))
+	}
+	return errs
+}
+
+func (current *RedisStreamSourceSpec) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	// Stream [required]
+	if current.Stream == "" {
+		errs = errs.Also(apis.ErrMissingField("stream"))
+	}
+	if current.MaxDeliveries < 0 {
+		errs = errs.Also(apis.ErrInvalidValue(current.MaxDeliveries, "maxDeliveries"))
+	}
+	// The entries given up on can't be added back to the stream.
+	if current.DeadLetterStream != "" && current.DeadLetterStream == current.Stream {
+		errs = errs.Also(apis.ErrInvalidValue(current.DeadLetterStream, "deadLetterStream"))
+	}
+
+	// Sink [required]
+	if equality.Semantic.DeepEqual(current.Sink, duckv1.Destination{}) {
+		errs = errs.Also(apis.ErrMissingField("sink"))
+	} else if err := current.Sink.Validate(ctx); err != nil {
+		errs = errs.Also(err.ViaField("sink"))
+	}
+	return errs.Also(current.RedisConnection.Validate(ctx))
+}
+
+func (current *RedisConnection) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	// Address [required]
+	if current.Address == "" {
+		errs = errs.Also(apis.ErrMissingField("address"))
+	} else if host, port, err := net.SplitHostPort(current.Address); err != nil || host == "" || !validPort(port) {
+		errs = errs.Also(apis.ErrInvalidValue(current.Address, "address"))
+	}
+
+	if current.Options != nil {
+		errs = errs.Also(current.Options.Validate(ctx).ViaField("dialOptions"))
+	}
+	return errs
+}
+
+func validPort(port string) bool {
+	p, err := strconv.Atoi(port)
+	return err == nil && p > 0 && p <= 65535
+}
+
+func (current *RedisConnectionOptions) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+
+	// The password must reference a secret by name.
+	if !equality.Semantic.DeepEqual(current.Password, corev1.ObjectReference{}) {
+		if current.Password.Name == "" {
+			errs = errs.Also(apis.ErrMissingField("password.name"))
+		}
+		if current.Password.Kind != "" && current.Password.Kind != "Secret" {
+			errs = errs.Also(apis.ErrInvalidValue(current.Password.Kind, "password.kind"))
+		}
+	}
+
+	errs = errs.Also(current.Cert.Validate(ctx).ViaField("cert"))
+	errs = errs.Also(current.Key.Validate(ctx).ViaField("key"))
+	errs = errs.Also(current.CACert.Validate(ctx).ViaField("caCert"))
+
+	// The client certificate and key go together.
+	if current.Cert.SecretKeyRef != nil && current.Key.SecretKeyRef == nil {
+		errs = errs.Also(apis.ErrMissingField("key"))
+	}
+	if current.Key.SecretKeyRef != nil && current.Cert.SecretKeyRef == nil {
+		errs = errs.Also(apis.ErrMissingField("cert"))
+	}
+
+	if !current.UseTLS {
+		if current.SkipVerify {
+			errs = errs.Also(requiresTLS("skipVerify"))
+		}
+		if current.Cert.SecretKeyRef != nil {
+			errs = errs.Also(requiresTLS("cert"))
+		}
+		if current.Key.SecretKeyRef != nil {
+			errs = errs.Also(requiresTLS("key"))
+		}
+		if current.CACert.SecretKeyRef != nil {
+			errs = errs.Also(requiresTLS("caCert"))
+		}
+	}
+	return errs
+}
+
+func requiresTLS(field string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: field + " requires useTLS",
+		Paths:   []string{field},
+	}
+}
+
+func (current *RedisSecretValueFromSource) Validate(ctx context.Context) *apis.FieldError {
+	if current.SecretKeyRef == nil {
+		return nil
+	}
+	var errs *apis.FieldError
+	if current.SecretKeyRef.Name == "" {
+		errs = errs.Also(apis.ErrMissingField("secretKeyRef.name"))
+	}
+	if current.SecretKeyRef.Key == "" {
+		errs = errs.Also(apis.ErrMissingField("secretKeyRef.key"))
+	}
+	return errs
+}
+
+func (current *RedisStreamSource) CheckImmutableFields(ctx context.Context, original *RedisStreamSource) *apis.FieldError {
+	if original == nil {
+		return nil
+	}
+
+	// Modification of Stream is not allowed, as the consumer group created
+	// for the source belongs to the stream. Everything else is mutable.
+	if diff := cmp.Diff(original.Spec.Stream, current.Spec.Stream); diff != "" {
+		return &apis.FieldError{
+			Message: "Immutable fields changed (-old +new)",
+			Paths:   []string{"spec", "stream"},
+			Details: diff,
+		}
+	}
+	return nil
+}
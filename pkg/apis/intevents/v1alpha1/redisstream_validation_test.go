@@ -0,0 +1,214 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+var (
+	redisStreamSourceSpec = RedisStreamSourceSpec{
+		SourceSpec: duckv1.SourceSpec{
+			Sink: duckv1.Destination{
+				Ref: &duckv1.KReference{
+					APIVersion: "foo",
+					Kind:       "bar",
+					Namespace:  "baz",
+					Name:       "qux",
+				},
+			},
+		},
+		RedisConnection: RedisConnection{
+			Address: "redis.default.svc:6379",
+		},
+		Stream: "mystream",
+	}
+
+	redisSecret = RedisSecretValueFromSource{SecretKeyRef: &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "tls"},
+		Key:                  "tls.crt",
+	}}
+)
+
+func TestRedisStreamSourceCheckValidationFields(t *testing.T) {
+	testCases := map[string]struct {
+		spec  func(*RedisStreamSourceSpec)
+		error bool
+	}{
+		"ok": {
+			spec:  func(*RedisStreamSourceSpec) {},
+			error: false,
+		},
+		"no stream": {
+			spec:  func(s *RedisStreamSourceSpec) { s.Stream = "" },
+			error: true,
+		},
+		"no sink": {
+			spec:  func(s *RedisStreamSourceSpec) { s.Sink = duckv1.Destination{} },
+			error: true,
+		},
+		"bad sink, name": {
+			spec:  func(s *RedisStreamSourceSpec) { s.Sink.Ref.Name = "" },
+			error: true,
+		},
+		"dead letter stream": {
+			spec: func(s *RedisStreamSourceSpec) {
+				s.MaxDeliveries = 3
+				s.DeadLetterStream = "dead-letters"
+			},
+			error: false,
+		},
+		"bad max deliveries": {
+			spec:  func(s *RedisStreamSourceSpec) { s.MaxDeliveries = -1 },
+			error: true,
+		},
+		"bad dead letter stream, same as stream": {
+			spec:  func(s *RedisStreamSourceSpec) { s.DeadLetterStream = s.Stream },
+			error: true,
+		},
+		"no address": {
+			spec:  func(s *RedisStreamSourceSpec) { s.Address = "" },
+			error: true,
+		},
+		"bad address, no port": {
+			spec:  func(s *RedisStreamSourceSpec) { s.Address = "redis" },
+			error: true,
+		},
+		"bad address, no host": {
+			spec:  func(s *RedisStreamSourceSpec) { s.Address = ":6379" },
+			error: true,
+		},
+		"bad address, port": {
+			spec:  func(s *RedisStreamSourceSpec) { s.Address = "redis:70000" },
+			error: true,
+		},
+		"password": {
+			spec: func(s *RedisStreamSourceSpec) {
+				s.Options = &RedisConnectionOptions{Password: corev1.ObjectReference{Name: "redis"}}
+			},
+			error: false,
+		},
+		"bad password, no name": {
+			spec: func(s *RedisStreamSourceSpec) {
+				s.Options = &RedisConnectionOptions{Password: corev1.ObjectReference{FieldPath: "password"}}
+			},
+			error: true,
+		},
+		"bad password, kind": {
+			spec: func(s *RedisStreamSourceSpec) {
+				s.Options = &RedisConnectionOptions{Password: corev1.ObjectReference{Kind: "ConfigMap", Name: "redis"}}
+			},
+			error: true,
+		},
+		"tls with cert and key": {
+			spec: func(s *RedisStreamSourceSpec) {
+				s.Options = &RedisConnectionOptions{UseTLS: true, Cert: redisSecret, Key: redisSecret, CACert: redisSecret}
+			},
+			error: false,
+		},
+		"bad tls, cert without key": {
+			spec: func(s *RedisStreamSourceSpec) {
+				s.Options = &RedisConnectionOptions{UseTLS: true, Cert: redisSecret}
+			},
+			error: true,
+		},
+		"bad tls, key without cert": {
+			spec: func(s *RedisStreamSourceSpec) {
+				s.Options = &RedisConnectionOptions{UseTLS: true, Key: redisSecret}
+			},
+			error: true,
+		},
+		"bad tls, secret without key": {
+			spec: func(s *RedisStreamSourceSpec) {
+				s.Options = &RedisConnectionOptions{UseTLS: true, CACert: RedisSecretValueFromSource{
+					SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "tls"}},
+				}}
+			},
+			error: true,
+		},
+		"bad tls, skip verify without tls": {
+			spec: func(s *RedisStreamSourceSpec) {
+				s.Options = &RedisConnectionOptions{SkipVerify: true}
+			},
+			error: true,
+		},
+		"bad tls, certificate without tls": {
+			spec: func(s *RedisStreamSourceSpec) {
+				s.Options = &RedisConnectionOptions{CACert: redisSecret}
+			},
+			error: true,
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			spec := redisStreamSourceSpec.DeepCopy()
+			tc.spec(spec)
+			err := spec.Validate(context.Background())
+			if tc.error != (err != nil) {
+				t.Fatalf("Unexpected validation failure. Got %v", err)
+			}
+		})
+	}
+}
+
+func TestRedisStreamSourceCheckImmutableFields(t *testing.T) {
+	testCases := map[string]struct {
+		updated func(*RedisStreamSourceSpec)
+		allowed bool
+	}{
+		"nil orig": {
+			allowed: true,
+		},
+		"Stream changed": {
+			updated: func(s *RedisStreamSourceSpec) { s.Stream = "other" },
+			allowed: false,
+		},
+		"Group changed": {
+			updated: func(s *RedisStreamSourceSpec) { s.Group = "other" },
+			allowed: true,
+		},
+		"Address changed": {
+			updated: func(s *RedisStreamSourceSpec) { s.Address = "other:6379" },
+			allowed: true,
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var orig *RedisStreamSource
+			updated := &RedisStreamSource{Spec: *redisStreamSourceSpec.DeepCopy()}
+			if tc.updated != nil {
+				orig = &RedisStreamSource{Spec: *redisStreamSourceSpec.DeepCopy()}
+				tc.updated(&updated.Spec)
+			}
+			ctx := context.Background()
+			if orig != nil {
+				ctx = apis.WithinUpdate(ctx, orig)
+			}
+			err := updated.Validate(ctx)
+			if tc.allowed != (err == nil) {
+				t.Fatalf("Unexpected immutable field check. Expected %v. Actual %v", tc.allowed, err)
+			}
+		})
+	}
+}
@@ -40,6 +40,18 @@ func (source *PullSubscription) ConvertTo(ctx context.Context, to apis.Convertib
 		sink.Spec.RetentionDuration = source.Spec.RetentionDuration
 		sink.Spec.Transformer = source.Spec.Transformer
 		sink.Spec.AdapterType = source.Spec.AdapterType
+		if source.Spec.RetryPolicy != nil {
+			sink.Spec.RetryPolicy = &v1.RetryPolicy{
+				MinimumBackoff: source.Spec.RetryPolicy.MinimumBackoff,
+				MaximumBackoff: source.Spec.RetryPolicy.MaximumBackoff,
+			}
+		}
+		if source.Spec.DeadLetterPolicy != nil {
+			sink.Spec.DeadLetterPolicy = &v1.DeadLetterPolicy{
+				DeadLetterTopic:     source.Spec.DeadLetterPolicy.DeadLetterTopic,
+				MaxDeliveryAttempts: source.Spec.DeadLetterPolicy.MaxDeliveryAttempts,
+			}
+		}
 		sink.Status.PubSubStatus = convert.ToV1PubSubStatus(source.Status.PubSubStatus)
 		sink.Status.TransformerURI = source.Status.TransformerURI
 		sink.Status.SubscriptionID = source.Status.SubscriptionID
@@ -65,6 +77,18 @@ func (sink *PullSubscription) ConvertFrom(ctx context.Context, from apis.Convert
 		// Since we remove Mode from PullSubscriptionSpec in v1, we treat it as an empty string.
 		sink.Spec.Mode = ""
 		sink.Spec.AdapterType = source.Spec.AdapterType
+		if source.Spec.RetryPolicy != nil {
+			sink.Spec.RetryPolicy = &RetryPolicy{
+				MinimumBackoff: source.Spec.RetryPolicy.MinimumBackoff,
+				MaximumBackoff: source.Spec.RetryPolicy.MaximumBackoff,
+			}
+		}
+		if source.Spec.DeadLetterPolicy != nil {
+			sink.Spec.DeadLetterPolicy = &DeadLetterPolicy{
+				DeadLetterTopic:     source.Spec.DeadLetterPolicy.DeadLetterTopic,
+				MaxDeliveryAttempts: source.Spec.DeadLetterPolicy.MaxDeliveryAttempts,
+			}
+		}
 		sink.Status.PubSubStatus = convert.FromV1PubSubStatus(source.Status.PubSubStatus)
 		sink.Status.TransformerURI = source.Status.TransformerURI
 		sink.Status.SubscriptionID = source.Status.SubscriptionID
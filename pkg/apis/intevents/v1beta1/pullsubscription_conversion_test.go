@@ -46,6 +46,14 @@ var (
 			Transformer:         &gcptesting.CompleteDestination,
 			Mode:                ModeCloudEventsBinary,
 			AdapterType:         "adapterType",
+			RetryPolicy: &RetryPolicy{
+				MinimumBackoff: &gcptesting.RetentionDuration,
+				MaximumBackoff: &gcptesting.RetentionDuration,
+			},
+			DeadLetterPolicy: &DeadLetterPolicy{
+				DeadLetterTopic:     "deadLetterTopic",
+				MaxDeliveryAttempts: 5,
+			},
 		},
 		Status: PullSubscriptionStatus{
 			PubSubStatus:   gcptesting.CompleteV1beta1PubSubStatus,
@@ -81,6 +81,16 @@ type ChannelSpec struct {
 	*eventingduck.SubscribableSpec `json:",inline"`
 }
 
+const (
+	// DispatcherAnnotationKey is the annotation key for the dispatcher of a Channel.
+	DispatcherAnnotationKey = "messaging.cloud.google.com/dispatcher"
+
+	// DispatcherShared is the value of the dispatcher annotation for Channels whose events are
+	// delivered by the shared channel dispatcher, rather than by one PullSubscription per
+	// subscriber.
+	DispatcherShared = "shared"
+)
+
 var channelCondSet = apis.NewLivingConditionSet(
 	ChannelConditionAddressable,
 	ChannelConditionTopicReady,
@@ -123,6 +133,12 @@ type ChannelStatus struct {
 	TopicID string `json:"topicId,omitempty"`
 }
 
+// IsSharedDispatcher returns true if the events of the Channel are delivered by the shared
+// channel dispatcher.
+func (c *Channel) IsSharedDispatcher() bool {
+	return c.Annotations[DispatcherAnnotationKey] == DispatcherShared
+}
+
 // Methods for identifiable interface.
 // IdentitySpec returns the IdentitySpec portion of the Spec.
 func (c *Channel) IdentitySpec() *gcpduckv1.IdentitySpec {
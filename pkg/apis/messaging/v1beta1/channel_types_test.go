@@ -22,6 +22,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"knative.dev/pkg/apis"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/google/go-cmp/cmp"
@@ -43,6 +44,22 @@ func TestChannelGetGroupVersionKind(t *testing.T) {
 	}
 }
 
+func TestChannelIsSharedDispatcher(t *testing.T) {
+	for _, tc := range []struct {
+		annotations map[string]string
+		want        bool
+	}{
+		{annotations: nil, want: false},
+		{annotations: map[string]string{DispatcherAnnotationKey: DispatcherShared}, want: true},
+		{annotations: map[string]string{DispatcherAnnotationKey: "other"}, want: false},
+	} {
+		c := &Channel{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+		if got := c.IsSharedDispatcher(); got != tc.want {
+			t.Errorf("IsSharedDispatcher() with annotations %v = %v, want %v", tc.annotations, got, tc.want)
+		}
+	}
+}
+
 func TestChannelIdentitySpec(t *testing.T) {
 	s := &Channel{
 		Spec: ChannelSpec{
@@ -30,6 +30,16 @@ import (
 func (c *Channel) Validate(ctx context.Context) *apis.FieldError {
 	err := c.Spec.Validate(ctx).ViaField("spec")
 
+	if d, ok := c.Annotations[DispatcherAnnotationKey]; ok && d != DispatcherShared {
+		err = err.Also(apis.ErrInvalidValue(d, DispatcherAnnotationKey).ViaField("metadata", "annotations"))
+	}
+	// The shared dispatcher only pulls the events of the channels in its own project.
+	if c.IsSharedDispatcher() && c.Spec.Project != "" {
+		fe := apis.ErrDisallowedFields("project").ViaField("spec")
+		fe.Details = "channels using the shared dispatcher are in the project of the dispatcher"
+		err = err.Also(fe)
+	}
+
 	if apis.IsInUpdate(ctx) {
 		original := apis.GetBaseline(ctx).(*Channel)
 		err = err.Also(c.CheckImmutableFields(ctx, original))
@@ -74,6 +84,15 @@ func (current *Channel) CheckImmutableFields(ctx context.Context, original *Chan
 		})
 	}
 
+	// Modification of the dispatcher annotation is not allowed.
+	if original.Annotations[DispatcherAnnotationKey] != current.Annotations[DispatcherAnnotationKey] {
+		errs = errs.Also(&apis.FieldError{
+			Message: "Immutable fields changed (-old +new)",
+			Paths:   []string{"metadata", "annotations", DispatcherAnnotationKey},
+			Details: fmt.Sprintf("-%q +%q", original.Annotations[DispatcherAnnotationKey], current.Annotations[DispatcherAnnotationKey]),
+		})
+	}
+
 	// Modification of AutoscalingClassAnnotations is not allowed.
 	errs = duck.CheckImmutableAutoscalingClassAnnotations(&current.ObjectMeta, &original.ObjectMeta, errs)
 
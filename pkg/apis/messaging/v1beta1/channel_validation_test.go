@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/google/go-cmp/cmp"
 	gcpauthtesthelper "github.com/google/knative-gcp/pkg/apis/configs/gcpauth/testhelper"
@@ -172,6 +173,40 @@ func TestChannelValidation(t *testing.T) {
 			}
 			return fe
 		}(),
+	}, {
+		name: "shared dispatcher",
+		cr: &Channel{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{DispatcherAnnotationKey: DispatcherShared},
+			},
+			Spec: channelSpec,
+		},
+		want: nil,
+	}, {
+		name: "shared dispatcher in another project",
+		cr: &Channel{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{DispatcherAnnotationKey: DispatcherShared},
+			},
+			Spec: ChannelSpec{
+				Project:          "other-project",
+				SubscribableSpec: channelSpec.SubscribableSpec,
+			},
+		},
+		want: func() *apis.FieldError {
+			fe := apis.ErrDisallowedFields("spec.project")
+			fe.Details = "channels using the shared dispatcher are in the project of the dispatcher"
+			return fe
+		}(),
+	}, {
+		name: "invalid dispatcher",
+		cr: &Channel{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{DispatcherAnnotationKey: "dedicated"},
+			},
+			Spec: channelSpec,
+		},
+		want: apis.ErrInvalidValue("dedicated", "metadata.annotations."+DispatcherAnnotationKey),
 	}}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -244,3 +279,42 @@ func TestCheckImmutableFields(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckImmutableDispatcher(t *testing.T) {
+	testCases := map[string]struct {
+		orig    map[string]string
+		updated map[string]string
+		allowed bool
+	}{
+		"unchanged": {
+			orig:    map[string]string{DispatcherAnnotationKey: DispatcherShared},
+			updated: map[string]string{DispatcherAnnotationKey: DispatcherShared},
+			allowed: true,
+		},
+		"added": {
+			updated: map[string]string{DispatcherAnnotationKey: DispatcherShared},
+			allowed: false,
+		},
+		"removed": {
+			orig:    map[string]string{DispatcherAnnotationKey: DispatcherShared},
+			allowed: false,
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			orig := &Channel{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tc.orig},
+				Spec:       channelSpec,
+			}
+			updated := &Channel{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tc.updated},
+				Spec:       channelSpec,
+			}
+			err := updated.CheckImmutableFields(context.TODO(), orig)
+			if tc.allowed != (err == nil) {
+				t.Fatalf("Unexpected immutable field check. Expected %v. Actual %v", tc.allowed, err)
+			}
+		})
+	}
+}
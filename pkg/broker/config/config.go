@@ -19,6 +19,7 @@ package config
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 // ReadonlyTargets provides "read" functions for brokers and targets.
@@ -63,6 +64,15 @@ type BrokerMutation interface {
 	SetDecoupleQueue(q *Queue) BrokerMutation
 	// SetState sets the broker state.
 	SetState(s State) BrokerMutation
+	// SetRateLimit sets the broker ingress rate limit.
+	SetRateLimit(l *RateLimit) BrokerMutation
+	// SetNamespaceRateLimit sets the ingress rate limit of the broker namespace.
+	SetNamespaceRateLimit(l *RateLimit) BrokerMutation
+	// SetOrdered sets whether the broker orders events sharing a partition key.
+	SetOrdered(ordered bool) BrokerMutation
+	// SetPriorityClasses sets the priority classes of the broker and the
+	// attribute of the events whose value selects their class.
+	SetPriorityClasses(attribute string, classes ...*PriorityClass) BrokerMutation
 	// UpsertTargets upserts Targets to the broker.
 	// The targets' namespace and broker will be forced to be
 	// the same as the broker's namespace and name.
@@ -102,7 +112,63 @@ func (t *Target) Key() string {
 	return TriggerKey(t.Namespace, t.Broker, t.Name)
 }
 
+// DedupWindow returns the window within which the events with the same source
+// and id are delivered to the target only once, or 0 if they are not
+// deduplicated.
+func (t *Target) DedupWindow() time.Duration {
+	return time.Duration(t.DedupWindowSeconds) * time.Second
+}
+
+// Allows returns true if a reply with the given type and source is sent to
+// the reply address of the target. A nil policy allows all the replies.
+func (p *ReplyPolicy) Allows(eventType, source string) bool {
+	if p == nil {
+		return true
+	}
+	if p.Drop {
+		return false
+	}
+	return allowedBy(p.AllowedTypes, eventType) && allowedBy(p.AllowedSources, source)
+}
+
+// allowedBy returns true if value is in the allowlist, or if it is empty.
+func allowedBy(allowlist []string, value string) bool {
+	if len(allowlist) == 0 {
+		return true
+	}
+	for _, v := range allowlist {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 // Key returns the broker key.
 func (b *Broker) Key() string {
 	return BrokerKey(b.Namespace, b.Name)
 }
+
+// PriorityClass returns the priority class of the broker with the given name,
+// or nil if there is none.
+func (b *Broker) PriorityClass(name string) *PriorityClass {
+	for _, c := range b.PriorityClasses {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// PriorityClassOf returns the priority class of the events with the given
+// value of the priority attribute, or nil if they are in no class.
+func (b *Broker) PriorityClassOf(value string) *PriorityClass {
+	for _, c := range b.PriorityClasses {
+		for _, v := range c.Values {
+			if v == value {
+				return c
+			}
+		}
+	}
+	return nil
+}
@@ -35,3 +35,57 @@ func TestTriggerKeyAndSplitTriggerKey(t *testing.T) {
 		t.Errorf("unexpected readiness: want %v, got %v", want, got)
 	}
 }
+
+func TestBrokerPriorityClass(t *testing.T) {
+	high := &PriorityClass{Name: "high", Values: []string{"critical", "high"}}
+	low := &PriorityClass{Name: "low", Values: []string{"low"}}
+	b := &Broker{PriorityAttribute: "priority", PriorityClasses: []*PriorityClass{high, low}}
+
+	if got := b.PriorityClass("low"); got != low {
+		t.Errorf("PriorityClass(low) got=%v, want=%v", got, low)
+	}
+	if got := b.PriorityClass("medium"); got != nil {
+		t.Errorf("PriorityClass(medium) got=%v, want=nil", got)
+	}
+	if got := b.PriorityClassOf("critical"); got != high {
+		t.Errorf("PriorityClassOf(critical) got=%v, want=%v", got, high)
+	}
+	if got := b.PriorityClassOf("medium"); got != nil {
+		t.Errorf("PriorityClassOf(medium) got=%v, want=nil", got)
+	}
+}
+
+func TestReplyPolicyAllows(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy *ReplyPolicy
+		want   bool
+	}{{
+		name: "no policy",
+		want: true,
+	}, {
+		name:   "drop",
+		policy: &ReplyPolicy{Drop: true},
+	}, {
+		name:   "no allowlist",
+		policy: &ReplyPolicy{},
+		want:   true,
+	}, {
+		name:   "allowed type and source",
+		policy: &ReplyPolicy{AllowedTypes: []string{"other", "type"}, AllowedSources: []string{"source"}},
+		want:   true,
+	}, {
+		name:   "type not allowed",
+		policy: &ReplyPolicy{AllowedTypes: []string{"other"}},
+	}, {
+		name:   "source not allowed",
+		policy: &ReplyPolicy{AllowedTypes: []string{"type"}, AllowedSources: []string{"other"}},
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.policy.Allows("type", "source"); got != tc.want {
+				t.Errorf("Allows() got=%v, want=%v", got, tc.want)
+			}
+		})
+	}
+}
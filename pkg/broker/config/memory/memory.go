@@ -52,6 +52,31 @@ func (m *brokerMutation) SetState(s config.State) config.BrokerMutation {
 	return m
 }
 
+func (m *brokerMutation) SetRateLimit(l *config.RateLimit) config.BrokerMutation {
+	m.delete = false
+	m.b.RateLimit = l
+	return m
+}
+
+func (m *brokerMutation) SetNamespaceRateLimit(l *config.RateLimit) config.BrokerMutation {
+	m.delete = false
+	m.b.NamespaceRateLimit = l
+	return m
+}
+
+func (m *brokerMutation) SetOrdered(ordered bool) config.BrokerMutation {
+	m.delete = false
+	m.b.Ordered = ordered
+	return m
+}
+
+func (m *brokerMutation) SetPriorityClasses(attribute string, classes ...*config.PriorityClass) config.BrokerMutation {
+	m.delete = false
+	m.b.PriorityAttribute = attribute
+	m.b.PriorityClasses = classes
+	return m
+}
+
 func (m *brokerMutation) UpsertTargets(targets ...*config.Target) config.BrokerMutation {
 	m.delete = false
 	if m.b.Targets == nil {
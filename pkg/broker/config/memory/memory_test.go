@@ -93,6 +93,39 @@ func TestMutateBroker(t *testing.T) {
 		assertBroker(t, wantBroker, "ns", "broker", targets)
 	})
 
+	t.Run("update broker ordering", func(t *testing.T) {
+		wantBroker.Ordered = true
+		targets.MutateBroker("ns", "broker", func(m config.BrokerMutation) {
+			m.SetOrdered(true)
+		})
+		assertBroker(t, wantBroker, "ns", "broker", targets)
+	})
+
+	class := &config.PriorityClass{
+		Name:           "high",
+		Values:         []string{"critical"},
+		DecoupleQueue:  &config.Queue{Topic: "topic-high", Subscription: "sub-high"},
+		MaxConcurrency: 10,
+	}
+	t.Run("update broker priority classes", func(t *testing.T) {
+		wantBroker.PriorityAttribute = "priority"
+		wantBroker.PriorityClasses = []*config.PriorityClass{class}
+		targets.MutateBroker("ns", "broker", func(m config.BrokerMutation) {
+			m.SetPriorityClasses("priority", class)
+		})
+		assertBroker(t, wantBroker, "ns", "broker", targets)
+	})
+
+	t.Run("update broker rate limits", func(t *testing.T) {
+		wantBroker.RateLimit = &config.RateLimit{EventsPerSecond: 10, Burst: 20}
+		wantBroker.NamespaceRateLimit = &config.RateLimit{EventsPerSecond: 100, Burst: 200}
+		targets.MutateBroker("ns", "broker", func(m config.BrokerMutation) {
+			m.SetRateLimit(&config.RateLimit{EventsPerSecond: 10, Burst: 20})
+			m.SetNamespaceRateLimit(&config.RateLimit{EventsPerSecond: 100, Burst: 200})
+		})
+		assertBroker(t, wantBroker, "ns", "broker", targets)
+	})
+
 	t1 := &config.Target{
 		Id:      "uid-1",
 		Address: "consumer1.example.com",
@@ -174,6 +207,10 @@ func TestMutateBroker(t *testing.T) {
 				Topic:        "topic",
 				Subscription: "sub",
 			})
+			m.SetRateLimit(&config.RateLimit{EventsPerSecond: 10, Burst: 20})
+			m.SetNamespaceRateLimit(&config.RateLimit{EventsPerSecond: 100, Burst: 200})
+			m.SetOrdered(true)
+			m.SetPriorityClasses("priority", class)
 			m.UpsertTargets(t1, t2)
 		})
 		assertBroker(t, wantBroker, "ns", "broker", targets)
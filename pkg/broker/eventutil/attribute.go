@@ -0,0 +1,57 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventutil
+
+import (
+	"github.com/cloudevents/sdk-go/v2/event"
+	cetypes "github.com/cloudevents/sdk-go/v2/types"
+)
+
+// AttributeValue returns the canonical string value of the context attribute
+// or extension of the event with the given name, or "" if it is not set.
+func AttributeValue(e *event.Event, name string) string {
+	switch name {
+	case "specversion":
+		return e.SpecVersion()
+	case "type":
+		return e.Type()
+	case "source":
+		return e.Source()
+	case "id":
+		return e.ID()
+	case "subject":
+		return e.Subject()
+	case "dataschema":
+		return e.DataSchema()
+	case "datacontenttype":
+		return e.DataContentType()
+	case "time":
+		if e.Time().IsZero() {
+			return ""
+		}
+		return cetypes.FormatTime(e.Time())
+	}
+	v, ok := e.Extensions()[name]
+	if !ok {
+		return ""
+	}
+	s, err := cetypes.Format(v)
+	if err != nil {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventutil
+
+import (
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+func TestAttributeValue(t *testing.T) {
+	e := cloudevents.NewEvent()
+	e.SetType("com.example.alert")
+	e.SetSource("/example")
+	e.SetExtension("priority", "high")
+	e.SetExtension("level", 3)
+
+	tests := map[string]string{
+		"type":     "com.example.alert",
+		"source":   "/example",
+		"subject":  "",
+		"time":     "",
+		"priority": "high",
+		"level":    "3",
+		"missing":  "",
+	}
+	for name, want := range tests {
+		if got := AttributeValue(&e, name); got != want {
+			t.Errorf("AttributeValue(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventutil
+
+import (
+	"github.com/cloudevents/sdk-go/v2/binding"
+)
+
+const (
+	// ErrorCodeAttribute is the extension carrying the last HTTP status code
+	// returned by the target before the event was dead lettered. It is absent
+	// if the target never responded, e.g. on timeouts.
+	ErrorCodeAttribute = "knativeerrorcode"
+	// ErrorMessageAttribute is the extension carrying the last delivery error.
+	ErrorMessageAttribute = "knativeerrormessage"
+	// AttemptsAttribute is the extension carrying the number of delivery
+	// attempts made before the event was dead lettered.
+	AttemptsAttribute = "knativeattempts"
+	// TriggerAttribute is the extension carrying the namespace/name of the
+	// trigger the event failed to be delivered for.
+	TriggerAttribute = "knativetrigger"
+	// UndeliveredAttribute is the extension set on the events the fanout sends
+	// to the retry queue without attempting to deliver them, so that only the
+	// attempts that reached the target are counted. It is short like the hops
+	// extension, and removed before the event leaves the broker.
+	UndeliveredAttribute = "kgcpundelivered"
+
+	// maxErrorMessageLength bounds the error message so that it fits
+	// comfortably in an HTTP header.
+	maxErrorMessageLength = 1024
+)
+
+// DeadLetterTransformer enriches a dead lettered event with the context of
+// its last failed delivery.
+type DeadLetterTransformer struct {
+	// Trigger is the namespace/name of the trigger.
+	Trigger string
+	// Attempts is the number of delivery attempts.
+	Attempts int
+	// ResponseCode is the last HTTP status code returned by the target, or 0
+	// if there was none.
+	ResponseCode int
+	// Err is the last delivery error.
+	Err error
+}
+
+var _ binding.Transformer = DeadLetterTransformer{}
+
+func (d DeadLetterTransformer) Transform(_ binding.MessageMetadataReader, out binding.MessageMetadataWriter) error {
+	if err := out.SetExtension(TriggerAttribute, d.Trigger); err != nil {
+		return err
+	}
+	if err := out.SetExtension(AttemptsAttribute, int32(d.Attempts)); err != nil {
+		return err
+	}
+	if d.ResponseCode != 0 {
+		if err := out.SetExtension(ErrorCodeAttribute, int32(d.ResponseCode)); err != nil {
+			return err
+		}
+	}
+	if d.Err != nil {
+		msg := d.Err.Error()
+		if len(msg) > maxErrorMessageLength {
+			msg = msg[:maxErrorMessageLength]
+		}
+		if err := out.SetExtension(ErrorMessageAttribute, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
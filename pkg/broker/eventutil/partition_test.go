@@ -0,0 +1,38 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventutil
+
+import (
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+func TestPartitionKey(t *testing.T) {
+	e := cloudevents.NewEvent()
+	if got := PartitionKey(&e); got != "" {
+		t.Errorf("PartitionKey() of an event without key = %q, want empty", got)
+	}
+	e.SetExtension(PartitionKeyAttribute, "sku-1")
+	if got := PartitionKey(&e); got != "sku-1" {
+		t.Errorf("PartitionKey() = %q, want %q", got, "sku-1")
+	}
+	e.SetExtension(PartitionKeyAttribute, 42)
+	if got := PartitionKey(&e); got != "42" {
+		t.Errorf("PartitionKey() of an integer key = %q, want %q", got, "42")
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"sync"
+	"time"
+)
+
+// attemptTracker counts how many times each Pub/Sub message has been received.
+// Pub/Sub only reports delivery attempts on subscriptions with a dead letter
+// policy, so handlers delivering to addressable dead letter sinks keep their
+// own count. The count is local to the process and may undercount if a message
+// is redelivered to another replica, which is in line with the retry count
+// being a minimum.
+type attemptTracker struct {
+	// ttl is how long an entry is kept after the message was last seen. It
+	// must be longer than the maximum redelivery backoff.
+	ttl time.Duration
+
+	mu        sync.Mutex
+	attempts  map[string]*attempt
+	lastSweep time.Time
+}
+
+type attempt struct {
+	count    int
+	lastSeen time.Time
+}
+
+func newAttemptTracker(ttl time.Duration) *attemptTracker {
+	return &attemptTracker{
+		ttl:       ttl,
+		attempts:  make(map[string]*attempt),
+		lastSweep: time.Now(),
+	}
+}
+
+// increment records a delivery of the message and returns its attempt number,
+// starting at 1.
+func (t *attemptTracker) increment(id string) int {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sweep(now)
+	a, ok := t.attempts[id]
+	if !ok {
+		a = &attempt{}
+		t.attempts[id] = a
+	}
+	a.count++
+	a.lastSeen = now
+	return a.count
+}
+
+// forget drops the message once it has been acked.
+func (t *attemptTracker) forget(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.attempts, id)
+}
+
+// sweep drops entries of messages that have not been redelivered within the
+// ttl, e.g. because they were acked by another replica. Must be called with
+// the lock held.
+func (t *attemptTracker) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < t.ttl {
+		return
+	}
+	for id, a := range t.attempts {
+		if now.Sub(a.lastSeen) >= t.ttl {
+			delete(t.attempts, id)
+		}
+	}
+	t.lastSweep = now
+}
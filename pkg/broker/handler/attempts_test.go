@@ -0,0 +1,56 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAttemptTracker(t *testing.T) {
+	tr := newAttemptTracker(time.Hour)
+	for want := 1; want <= 3; want++ {
+		if got := tr.increment("msg"); got != want {
+			t.Errorf("increment got=%d, want=%d", got, want)
+		}
+	}
+	if got := tr.increment("other"); got != 1 {
+		t.Errorf("increment of another message got=%d, want=1", got)
+	}
+
+	tr.forget("msg")
+	if got := tr.increment("msg"); got != 1 {
+		t.Errorf("increment after forget got=%d, want=1", got)
+	}
+}
+
+func TestAttemptTrackerExpiry(t *testing.T) {
+	tr := newAttemptTracker(time.Minute)
+	tr.increment("stale")
+	tr.increment("fresh")
+
+	now := time.Now()
+	tr.attempts["stale"].lastSeen = now.Add(-2 * time.Minute)
+	tr.lastSweep = now.Add(-2 * time.Minute)
+
+	if got := tr.increment("fresh"); got != 2 {
+		t.Errorf("increment got=%d, want=2", got)
+	}
+	if _, ok := tr.attempts["stale"]; ok {
+		t.Error("stale attempt was not swept")
+	}
+}
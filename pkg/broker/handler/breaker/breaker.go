@@ -0,0 +1,188 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package breaker provides the circuit breakers that isolate the delivery of
+// events to a target from the other targets of the same broker.
+package breaker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// State is the state of a circuit breaker.
+type State int
+
+const (
+	// Closed lets all the deliveries through.
+	Closed State = iota
+	// HalfOpen lets a single probe delivery through at a time.
+	HalfOpen
+	// Open short-circuits all the deliveries.
+	Open
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case HalfOpen:
+		return "half-open"
+	case Open:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// Settings configures the circuit breakers.
+type Settings struct {
+	// FailureThreshold is the number of consecutive failed deliveries that
+	// opens a circuit breaker. Circuit breaking is disabled if it is 0.
+	FailureThreshold int
+	// SlowThreshold is the delivery latency above which a successful
+	// delivery counts as failed. Latency is not considered if it is 0.
+	SlowThreshold time.Duration
+	// OpenDuration is how long a circuit breaker stays open before letting a
+	// probe delivery through.
+	OpenDuration time.Duration
+}
+
+// Enabled returns true if the settings enable circuit breaking.
+func (s Settings) Enabled() bool {
+	return s.FailureThreshold > 0
+}
+
+// StateChangeFunc is called with the context of the delivery that changed the
+// state of a circuit breaker.
+type StateChangeFunc func(ctx context.Context, s State)
+
+// Breaker is the circuit breaker of a target. It opens after
+// FailureThreshold consecutive failed deliveries. Once open, it lets a probe
+// delivery through after OpenDuration, and closes if the probe succeeds.
+type Breaker struct {
+	settings Settings
+	onChange StateChangeFunc
+	now      func() time.Time
+
+	mu    sync.Mutex
+	state State
+	// generation is incremented on every state change, so that the results of
+	// the deliveries allowed in a previous state are ignored.
+	generation uint64
+	failures   int
+	openedAt   time.Time
+	probing    bool
+}
+
+// Call is a delivery allowed by a circuit breaker.
+type Call struct {
+	// generation is the generation of the breaker when the delivery was
+	// allowed.
+	generation uint64
+}
+
+// New creates a closed circuit breaker. onChange may be nil.
+func New(settings Settings, onChange StateChangeFunc) *Breaker {
+	return &Breaker{
+		settings: settings,
+		onChange: onChange,
+		now:      time.Now,
+	}
+}
+
+// State returns the current state of the circuit breaker.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow returns true if a delivery can be attempted, in which case Done must
+// be called with the returned call and its result.
+func (b *Breaker) Allow(ctx context.Context) (Call, bool) {
+	b.mu.Lock()
+	changed := false
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.settings.OpenDuration {
+		b.setState(HalfOpen)
+		changed = true
+	}
+	allowed := true
+	switch b.state {
+	case Open:
+		allowed = false
+	case HalfOpen:
+		allowed = !b.probing
+		b.probing = true
+	}
+	state, call := b.state, Call{generation: b.generation}
+	b.mu.Unlock()
+
+	if changed {
+		b.notify(ctx, state)
+	}
+	return call, allowed
+}
+
+// Done records the result of a delivery allowed by Allow. The result is
+// ignored if the state of the circuit breaker has changed since the delivery
+// was allowed: a delivery started before the breaker opened doesn't close it,
+// and only the probe delivery ends the half-open state.
+func (b *Breaker) Done(ctx context.Context, call Call, err error, latency time.Duration) {
+	failed := err != nil || (b.settings.SlowThreshold > 0 && latency > b.settings.SlowThreshold)
+
+	b.mu.Lock()
+	if call.generation != b.generation {
+		b.mu.Unlock()
+		return
+	}
+	prev := b.state
+	if b.state == HalfOpen {
+		b.probing = false
+	}
+	if failed {
+		b.failures++
+		if b.state == HalfOpen || b.failures >= b.settings.FailureThreshold {
+			b.setState(Open)
+			b.openedAt = b.now()
+		}
+	} else {
+		b.failures = 0
+		b.setState(Closed)
+	}
+	state := b.state
+	b.mu.Unlock()
+
+	if state != prev {
+		b.notify(ctx, state)
+	}
+}
+
+// setState changes the state of the circuit breaker, starting a new
+// generation if it differs. b.mu must be held.
+func (b *Breaker) setState(s State) {
+	if s != b.state {
+		b.state = s
+		b.generation++
+	}
+}
+
+func (b *Breaker) notify(ctx context.Context, s State) {
+	if b.onChange != nil {
+		b.onChange(ctx, s)
+	}
+}
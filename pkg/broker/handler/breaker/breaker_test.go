@@ -0,0 +1,193 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var errDelivery = errors.New("delivery failed")
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestBreaker(settings Settings) (*Breaker, *fakeClock, *[]State) {
+	var changes []State
+	b := New(settings, func(_ context.Context, s State) {
+		changes = append(changes, s)
+	})
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b.now = clock.Now
+	return b, clock, &changes
+}
+
+// deliver records a delivery with the given result if the breaker allows it,
+// and returns whether it was allowed.
+func deliver(ctx context.Context, b *Breaker, err error, latency time.Duration) bool {
+	call, ok := b.Allow(ctx)
+	if ok {
+		b.Done(ctx, call, err, latency)
+	}
+	return ok
+}
+
+func TestBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	ctx := context.Background()
+	b, _, changes := newTestBreaker(Settings{FailureThreshold: 3, OpenDuration: time.Minute})
+
+	for i, err := range []error{errDelivery, errDelivery, nil, errDelivery, errDelivery} {
+		call, ok := b.Allow(ctx)
+		if !ok {
+			t.Fatalf("delivery %d not allowed", i)
+		}
+		b.Done(ctx, call, err, time.Millisecond)
+	}
+	if got := b.State(); got != Closed {
+		t.Fatalf("State() = %v after non-consecutive failures, want %v", got, Closed)
+	}
+
+	deliver(ctx, b, errDelivery, time.Millisecond)
+	if got := b.State(); got != Open {
+		t.Fatalf("State() = %v, want %v", got, Open)
+	}
+	if _, ok := b.Allow(ctx); ok {
+		t.Error("delivery allowed while open")
+	}
+	if diff := cmp.Diff([]State{Open}, *changes); diff != "" {
+		t.Errorf("unexpected state changes (-want, +got) = %v", diff)
+	}
+}
+
+func TestBreakerSlowDeliveries(t *testing.T) {
+	ctx := context.Background()
+	b, _, _ := newTestBreaker(Settings{FailureThreshold: 2, SlowThreshold: time.Second, OpenDuration: time.Minute})
+
+	deliver(ctx, b, nil, time.Second)
+	deliver(ctx, b, nil, 2*time.Second)
+	if got := b.State(); got != Closed {
+		t.Fatalf("State() = %v, want %v", got, Closed)
+	}
+	deliver(ctx, b, nil, 2*time.Second)
+	if got := b.State(); got != Open {
+		t.Fatalf("State() = %v, want %v", got, Open)
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	ctx := context.Background()
+	b, clock, changes := newTestBreaker(Settings{FailureThreshold: 1, OpenDuration: time.Minute})
+
+	deliver(ctx, b, errDelivery, time.Millisecond)
+	clock.now = clock.now.Add(59 * time.Second)
+	if _, ok := b.Allow(ctx); ok {
+		t.Fatal("delivery allowed before the open duration")
+	}
+
+	// A single probe is let through once the open duration has elapsed.
+	clock.now = clock.now.Add(time.Second)
+	probe, ok := b.Allow(ctx)
+	if !ok {
+		t.Fatal("probe delivery not allowed")
+	}
+	if _, ok := b.Allow(ctx); ok {
+		t.Fatal("second delivery allowed while probing")
+	}
+	// A failed probe opens the breaker again.
+	b.Done(ctx, probe, errDelivery, time.Millisecond)
+	if _, ok := b.Allow(ctx); ok {
+		t.Fatal("delivery allowed after a failed probe")
+	}
+
+	// A successful probe closes the breaker.
+	clock.now = clock.now.Add(time.Minute)
+	if !deliver(ctx, b, nil, time.Millisecond) {
+		t.Fatal("probe delivery not allowed")
+	}
+	if !deliver(ctx, b, nil, time.Millisecond) || !deliver(ctx, b, nil, time.Millisecond) {
+		t.Error("delivery not allowed after a successful probe")
+	}
+
+	want := []State{Open, HalfOpen, Open, HalfOpen, Closed}
+	if diff := cmp.Diff(want, *changes); diff != "" {
+		t.Errorf("unexpected state changes (-want, +got) = %v", diff)
+	}
+}
+
+func TestBreakerIgnoresStaleResults(t *testing.T) {
+	ctx := context.Background()
+	b, clock, changes := newTestBreaker(Settings{FailureThreshold: 1, OpenDuration: time.Minute})
+
+	// Deliveries started before the breaker opened.
+	late, _ := b.Allow(ctx)
+	lateProbing, _ := b.Allow(ctx)
+	deliver(ctx, b, errDelivery, time.Millisecond)
+
+	// A late success doesn't close the breaker.
+	b.Done(ctx, late, nil, time.Millisecond)
+	if got := b.State(); got != Open {
+		t.Fatalf("State() = %v after a late success, want %v", got, Open)
+	}
+
+	// A late result doesn't end the probe.
+	clock.now = clock.now.Add(time.Minute)
+	probe, ok := b.Allow(ctx)
+	if !ok {
+		t.Fatal("probe delivery not allowed")
+	}
+	b.Done(ctx, lateProbing, nil, time.Millisecond)
+	if got := b.State(); got != HalfOpen {
+		t.Fatalf("State() = %v after a late success while probing, want %v", got, HalfOpen)
+	}
+	if _, ok := b.Allow(ctx); ok {
+		t.Fatal("second delivery allowed while probing")
+	}
+
+	b.Done(ctx, probe, nil, time.Millisecond)
+	want := []State{Open, HalfOpen, Closed}
+	if diff := cmp.Diff(want, *changes); diff != "" {
+		t.Errorf("unexpected state changes (-want, +got) = %v", diff)
+	}
+}
+
+func TestSet(t *testing.T) {
+	s := NewSet(Settings{FailureThreshold: 1, OpenDuration: time.Minute}, nil)
+	b := s.Get("ns/broker/t1")
+	if got := s.Get("ns/broker/t1"); got != b {
+		t.Error("Get() returned another breaker for the same target")
+	}
+	if got := s.Get("ns/broker/t2"); got == b {
+		t.Error("Get() returned the same breaker for another target")
+	}
+
+	deliver(context.Background(), b, errDelivery, time.Millisecond)
+	s.Prune(func(key string) bool {
+		return key != "ns/broker/t1"
+	})
+	if got := s.Get("ns/broker/t1").State(); got != Closed {
+		t.Errorf("State() = %v after pruning, want %v", got, Closed)
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package breaker
+
+import "sync"
+
+// Set holds the circuit breakers of the targets, by target key.
+type Set struct {
+	settings Settings
+	onChange StateChangeFunc
+	breakers sync.Map
+}
+
+// NewSet creates a Set of circuit breakers with the given settings. onChange
+// is called when the state of any of them changes, and may be nil.
+func NewSet(settings Settings, onChange StateChangeFunc) *Set {
+	return &Set{
+		settings: settings,
+		onChange: onChange,
+	}
+}
+
+// Get returns the circuit breaker of the target, creating it if needed.
+func (s *Set) Get(key string) *Breaker {
+	if b, ok := s.breakers.Load(key); ok {
+		return b.(*Breaker)
+	}
+	b, _ := s.breakers.LoadOrStore(key, New(s.settings, s.onChange))
+	return b.(*Breaker)
+}
+
+// Prune removes the circuit breakers of the targets for which keep returns
+// false.
+func (s *Set) Prune(keep func(key string) bool) {
+	s.breakers.Range(func(key, _ interface{}) bool {
+		if !keep(key.(string)) {
+			s.breakers.Delete(key)
+		}
+		return true
+	})
+}
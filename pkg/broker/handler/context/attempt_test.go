@@ -0,0 +1,39 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package context
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDeliveryAttempt(t *testing.T) {
+	_, err := GetDeliveryAttempt(context.Background())
+	if err != ErrDeliveryAttemptNotPresent {
+		t.Errorf("error from GetDeliveryAttempt got=%v, want=%v", err, ErrDeliveryAttemptNotPresent)
+	}
+
+	wantAttempt := 3
+	ctx := WithDeliveryAttempt(context.Background(), wantAttempt)
+	gotAttempt, err := GetDeliveryAttempt(ctx)
+	if err != nil {
+		t.Errorf("unexpected error from GetDeliveryAttempt: %v", err)
+	}
+	if gotAttempt != wantAttempt {
+		t.Errorf("GetDeliveryAttempt got=%v, want=%v", gotAttempt, wantAttempt)
+	}
+}
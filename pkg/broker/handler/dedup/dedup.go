@@ -0,0 +1,49 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dedup provides the stores that the broker uses to deliver the
+// events with the same source and id only once to a target, within a window.
+package dedup
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// Store records the events delivered to targets. Implementations must be
+// safe for concurrent use.
+type Store interface {
+	// Reserve reserves the delivery of the event identified by key, for the
+	// given time to live, unless it is already reserved or recorded and has
+	// not expired. It returns false if the event isn't reserved. Checking and
+	// reserving is atomic, so only one of concurrent duplicates is reserved.
+	Reserve(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Record records the delivery of the event identified by key, for the
+	// given time to live.
+	Record(ctx context.Context, key string, ttl time.Duration) error
+	// Release releases the reservation of the event identified by key, when
+	// its delivery failed.
+	Release(ctx context.Context, key string) error
+}
+
+// Key returns the key of the event with the given source and id delivered to
+// the target with the given key.
+func Key(targetKey, source, id string) string {
+	// The length of the source keeps the key unambiguous whatever the
+	// characters of the source and id.
+	return targetKey + "/" + strconv.Itoa(len(source)) + ":" + source + id
+}
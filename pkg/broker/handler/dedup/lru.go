@@ -0,0 +1,74 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dedup
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// LRU is an in-memory Store holding a bounded number of events. The least
+// recently recorded events are evicted first when it is full, so duplicates
+// may go undetected under a high event rate.
+type LRU struct {
+	// mu makes checking and reserving an event atomic.
+	mu    sync.Mutex
+	cache *lru.Cache
+	now   func() time.Time
+}
+
+var _ Store = (*LRU)(nil)
+
+// NewLRU creates an LRU store holding up to size events.
+func NewLRU(size int) (*LRU, error) {
+	cache, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	return &LRU{cache: cache, now: time.Now}, nil
+}
+
+// Reserve implements Store.Reserve.
+func (s *LRU) Reserve(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if expiry, ok := s.cache.Peek(key); ok && now.Before(expiry.(time.Time)) {
+		return false, nil
+	}
+	s.cache.Add(key, now.Add(ttl))
+	return true, nil
+}
+
+// Record implements Store.Record.
+func (s *LRU) Record(_ context.Context, key string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache.Add(key, s.now().Add(ttl))
+	return nil
+}
+
+// Release implements Store.Release.
+func (s *LRU) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache.Remove(key)
+	return nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dedup
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewLRU(2)
+	if err != nil {
+		t.Fatalf("NewLRU() got error: %v", err)
+	}
+	now := time.Unix(0, 0)
+	s.now = func() time.Time { return now }
+
+	reserve := func(key string, ttl time.Duration) bool {
+		t.Helper()
+		ok, err := s.Reserve(ctx, key, ttl)
+		if err != nil {
+			t.Fatalf("Reserve(%q) got error: %v", key, err)
+		}
+		return ok
+	}
+	record := func(key string, ttl time.Duration) {
+		t.Helper()
+		if err := s.Record(ctx, key, ttl); err != nil {
+			t.Fatalf("Record(%q) got error: %v", key, err)
+		}
+	}
+	release := func(key string) {
+		t.Helper()
+		if err := s.Release(ctx, key); err != nil {
+			t.Fatalf("Release(%q) got error: %v", key, err)
+		}
+	}
+
+	if !reserve("a", time.Minute) {
+		t.Error("Reserve(a) false before any reservation")
+	}
+	if reserve("a", time.Minute) {
+		t.Error("Reserve(a) true while reserved")
+	}
+	record("a", time.Minute)
+	if reserve("a", time.Minute) {
+		t.Error("Reserve(a) true after Record")
+	}
+
+	// A released reservation can be reserved again.
+	if !reserve("b", time.Hour) {
+		t.Error("Reserve(b) false before any reservation")
+	}
+	release("b")
+	if !reserve("b", time.Hour) {
+		t.Error("Reserve(b) false after Release")
+	}
+	record("b", time.Hour)
+
+	// The entries expire after their ttl.
+	now = now.Add(time.Minute)
+	if !reserve("a", time.Minute) {
+		t.Error("Reserve(a) false after its ttl")
+	}
+	if reserve("b", time.Hour) {
+		t.Error("Reserve(b) true within its ttl")
+	}
+
+	// The least recently recorded entry is evicted when full.
+	record("c", time.Hour)
+	record("d", time.Hour)
+	if !reserve("b", time.Hour) {
+		t.Error("Reserve(b) false after eviction")
+	}
+}
+
+func TestLRUConcurrentReserve(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewLRU(10)
+	if err != nil {
+		t.Fatalf("NewLRU() got error: %v", err)
+	}
+
+	var reserved int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := s.Reserve(ctx, "a", time.Minute)
+			if err != nil {
+				t.Errorf("Reserve(a) got error: %v", err)
+			}
+			if ok {
+				atomic.AddInt32(&reserved, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if reserved != 1 {
+		t.Errorf("Got %d concurrent reservations of a, want 1", reserved)
+	}
+}
+
+func TestKey(t *testing.T) {
+	if Key("ns/broker/trigger", "a", "bc") == Key("ns/broker/trigger", "ab", "c") {
+		t.Error("Key() is ambiguous")
+	}
+	if Key("ns/broker/t1", "source", "id") == Key("ns/broker/t2", "source", "id") {
+		t.Error("Key() is the same for different targets")
+	}
+}
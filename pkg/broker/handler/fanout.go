@@ -23,17 +23,18 @@ import (
 	"sync"
 	"time"
 
-	"cloud.google.com/go/pubsub"
 	ceclient "github.com/cloudevents/sdk-go/v2/client"
 	"github.com/google/knative-gcp/pkg/logging"
 	"go.uber.org/zap"
 
 	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/broker/handler/breaker"
 	handlerctx "github.com/google/knative-gcp/pkg/broker/handler/context"
 	"github.com/google/knative-gcp/pkg/broker/handler/processors"
 	"github.com/google/knative-gcp/pkg/broker/handler/processors/deliver"
 	"github.com/google/knative-gcp/pkg/broker/handler/processors/fanout"
 	"github.com/google/knative-gcp/pkg/broker/handler/processors/filter"
+	"github.com/google/knative-gcp/pkg/kncloudevents"
 	"github.com/google/knative-gcp/pkg/metrics"
 )
 
@@ -46,8 +47,8 @@ type FanoutPool struct {
 	targets config.ReadonlyTargets
 	pool    sync.Map
 
-	// Pubsub client used to pull events from decoupling topics.
-	pubsubClient *pubsub.Client
+	// Creates the inbounds to pull events from decoupling queues.
+	inbounds InboundFactory
 	// For sending retry events. We only need a shared client.
 	// And we can set retry topic dynamically.
 	deliverRetryClient ceclient.Client
@@ -55,35 +56,56 @@ type FanoutPool struct {
 	// And we can set target address dynamically.
 	deliverClient *http.Client
 	statsReporter *metrics.DeliveryReporter
+	// The circuit breakers of the targets, shared by the handlers so that
+	// they outlive handler renewals. Nil if circuit breaking is disabled.
+	breakers *breaker.Set
+	// The compiled filters of the targets, shared by the handlers so that
+	// they are compiled once per config sync.
+	filters *filter.Cache
 }
 
 type fanoutHandlerCache struct {
 	Handler
 	b *config.Broker
+	// class is the name of the priority class the handler pulls the events of,
+	// or "" for the events of the broker that are in no class.
+	class string
+	q     *config.Queue
+	// concurrency is the max concurrency of the priority class.
+	concurrency int32
 }
 
 // If somehow the existing handler's setting has deviated from the current broker config,
 // we need to renew the handler.
-func (hc *fanoutHandlerCache) shouldRenew(b *config.Broker) bool {
+func (hc *fanoutHandlerCache) shouldRenew(q *config.Queue, concurrency int32) bool {
 	if !hc.IsAlive() {
 		return true
 	}
 	// If this really happens, it means a data corruption.
 	// The handler creation will fail (which is expected).
-	if b == nil || b.DecoupleQueue == nil {
+	if q == nil {
 		return true
 	}
-	if b.DecoupleQueue.Topic != hc.b.DecoupleQueue.Topic ||
-		b.DecoupleQueue.Subscription != hc.b.DecoupleQueue.Subscription {
+	if q.Topic != hc.q.Topic ||
+		q.Subscription != hc.q.Subscription {
 		return true
 	}
-	return false
+	return concurrency != hc.concurrency
+}
+
+// fanoutHandlerKey returns the key of the handler of the priority class of the
+// broker in the pool, or of the handler of the broker if class is "".
+func fanoutHandlerKey(b *config.Broker, class string) string {
+	if class == "" {
+		return b.Key()
+	}
+	return b.Key() + "/" + class
 }
 
 // NewFanoutPool creates a new fanout handler pool.
 func NewFanoutPool(
 	targets config.ReadonlyTargets,
-	pubsubClient *pubsub.Client,
+	inbounds InboundFactory,
 	deliverClient *http.Client,
 	retryClient RetryClient,
 	statsReporter *metrics.DeliveryReporter,
@@ -109,14 +131,26 @@ func NewFanoutPool(
 	p := &FanoutPool{
 		targets:            targets,
 		options:            options,
-		pubsubClient:       pubsubClient,
+		inbounds:           inbounds,
 		deliverClient:      deliverClient,
 		deliverRetryClient: retryClient,
 		statsReporter:      statsReporter,
+		filters:            filter.NewCache(),
+	}
+	if options.CircuitBreaker.Enabled() {
+		p.breakers = breaker.NewSet(options.CircuitBreaker, p.circuitBreakerStateChanged)
 	}
 	return p, nil
 }
 
+// circuitBreakerStateChanged is called with the context of the delivery to
+// the target whose circuit breaker changed state.
+func (p *FanoutPool) circuitBreakerStateChanged(ctx context.Context, s breaker.State) {
+	tk, _ := handlerctx.GetTargetKey(ctx)
+	logging.FromContext(ctx).Info("circuit breaker state changed", zap.String("target", tk), zap.Stringer("state", s))
+	p.statsReporter.ReportCircuitBreakerState(ctx, s)
+}
+
 // SyncOnce syncs once the handler pool based on the targets config.
 func (p *FanoutPool) SyncOnce(ctx context.Context) error {
 	ctx, err := p.statsReporter.AddTags(ctx)
@@ -125,66 +159,110 @@ func (p *FanoutPool) SyncOnce(ctx context.Context) error {
 	}
 
 	p.pool.Range(func(key, value interface{}) bool {
-		if _, ok := p.targets.GetBrokerByKey(key.(string)); !ok {
-			value.(*fanoutHandlerCache).Stop()
+		hc := value.(*fanoutHandlerCache)
+		b, ok := p.targets.GetBrokerByKey(hc.b.Key())
+		if !ok || (hc.class != "" && b.PriorityClass(hc.class) == nil) {
+			hc.Stop()
 			p.pool.Delete(key)
 		}
 		return true
 	})
 
+	p.filters.Prune(p.targets)
+	if p.breakers != nil {
+		p.breakers.Prune(func(key string) bool {
+			_, ok := p.targets.GetTargetByKey(key)
+			return ok
+		})
+	}
+
 	p.targets.RangeBrokers(func(b *config.Broker) bool {
-		if value, ok := p.pool.Load(b.Key()); ok {
-			// Skip if we don't need to renew the handler.
-			if !value.(*fanoutHandlerCache).shouldRenew(b) {
-				return true
-			}
-			// Stop and clean up the old handler before we start a new one.
-			value.(*fanoutHandlerCache).Stop()
-			p.pool.Delete(b.Key())
+		p.syncHandler(ctx, b, "", b.DecoupleQueue, 0)
+		for _, c := range b.PriorityClasses {
+			p.syncHandler(ctx, b, c.Name, c.DecoupleQueue, c.MaxConcurrency)
 		}
+		return true
+	})
 
-		// Don't start the handler if broker is not ready.
-		// The decouple topic/sub might not be ready at this point.
-		if b.State != config.State_READY {
-			return true
-		}
+	return nil
+}
 
-		sub := p.pubsubClient.Subscription(b.DecoupleQueue.Subscription)
-		sub.ReceiveSettings = p.options.PubsubReceiveSettings
-
-		h := NewHandler(
-			sub,
-			processors.ChainProcessors(
-				&fanout.Processor{MaxConcurrency: p.options.MaxConcurrencyPerEvent, Targets: p.targets},
-				&filter.Processor{Targets: p.targets},
-				&deliver.Processor{
-					DeliverClient:      p.deliverClient,
-					Targets:            p.targets,
-					RetryOnFailure:     true,
-					DeliverRetryClient: p.deliverRetryClient,
-					DeliverTimeout:     p.options.DeliveryTimeout,
-					StatsReporter:      p.statsReporter,
-				},
-			),
-			p.options.TimeoutPerEvent,
-		)
-		hc := &fanoutHandlerCache{
-			Handler: *h,
-			b:       b,
+// syncHandler starts or renews the handler pulling the events of the broker
+// from the decouple queue of its priority class, with the max concurrency of
+// the class. The handler pulling the events that are in no class has the
+// class "" and no max concurrency.
+func (p *FanoutPool) syncHandler(ctx context.Context, b *config.Broker, class string, q *config.Queue, concurrency int32) {
+	key := fanoutHandlerKey(b, class)
+	if value, ok := p.pool.Load(key); ok {
+		// Skip if we don't need to renew the handler.
+		if !value.(*fanoutHandlerCache).shouldRenew(q, concurrency) {
+			return
 		}
+		// Stop and clean up the old handler before we start a new one.
+		value.(*fanoutHandlerCache).Stop()
+		p.pool.Delete(key)
+	}
 
-		// Start the handler with broker key in context.
-		hc.Start(handlerctx.WithBrokerKey(ctx, b.Key()), func(err error) {
-			if err != nil {
-				logging.FromContext(ctx).Error("handler for broker has stopped with error", zap.String("broker", b.Key()), zap.Error(err))
-			} else {
-				logging.FromContext(ctx).Info("handler for broker has stopped", zap.String("broker", b.Key()))
-			}
-		})
+	// Don't start the handler if broker is not ready.
+	// The decouple topic/sub might not be ready at this point.
+	if b.State != config.State_READY || q == nil || (class != "" && q.State != config.State_READY) {
+		return
+	}
 
-		p.pool.Store(b.Key(), hc)
-		return true
+	h := NewHandler(
+		p.inbounds.NewInbound(q, p.classOptions(concurrency)),
+		processors.ChainProcessors(
+			&fanout.Processor{MaxConcurrency: p.options.MaxConcurrencyPerEvent, Targets: p.targets},
+			&filter.Processor{Targets: p.targets, Cache: p.filters},
+			&deliver.Processor{
+				DeliverClient:      p.deliverClient,
+				Targets:            p.targets,
+				RetryOnFailure:     true,
+				DeliverRetryClient: p.deliverRetryClient,
+				DeliverTimeout:     p.options.DeliveryTimeout,
+				StatsReporter:      p.statsReporter,
+				Breakers:           p.breakers,
+				Dedup:              p.options.DedupStore,
+			},
+		),
+		p.options.TimeoutPerEvent,
+	)
+	if p.options.UnconvertibleSinkURI != "" {
+		h.unconvertible = kncloudevents.NewUnconvertibleSender(http.DefaultClient, p.options.UnconvertibleSinkURI,
+			fmt.Sprintf("/apis/v1/namespaces/%s/brokers/%s", b.Namespace, b.Name))
+	}
+	hc := &fanoutHandlerCache{
+		Handler:     *h,
+		b:           b,
+		class:       class,
+		q:           q,
+		concurrency: concurrency,
+	}
+
+	// Start the handler with broker key in context.
+	hc.Start(handlerctx.WithBrokerKey(ctx, b.Key()), func(err error) {
+		if err != nil {
+			logging.FromContext(ctx).Error("handler for broker has stopped with error", zap.String("broker", b.Key()), zap.String("class", class), zap.Error(err))
+		} else {
+			logging.FromContext(ctx).Info("handler for broker has stopped", zap.String("broker", b.Key()), zap.String("class", class))
+		}
 	})
 
-	return nil
+	p.pool.Store(key, hc)
+}
+
+// classOptions returns the options of the handler of a priority class, whose
+// max concurrency bounds the number of events pulled and processed at once.
+// The options of the pool are returned as is if concurrency is 0.
+func (p *FanoutPool) classOptions(concurrency int32) *Options {
+	if concurrency <= 0 {
+		return p.options
+	}
+	options := *p.options
+	options.HandlerConcurrency = int(concurrency)
+	options.PubsubReceiveSettings.MaxOutstandingMessages = int(concurrency)
+	if options.PubsubReceiveSettings.NumGoroutines > int(concurrency) {
+		options.PubsubReceiveSettings.NumGoroutines = int(concurrency)
+	}
+	return &options
 }
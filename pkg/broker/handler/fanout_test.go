@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"cloud.google.com/go/pubsub"
 	"github.com/cloudevents/sdk-go/v2/event"
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/sync/errgroup"
@@ -105,6 +106,58 @@ func TestFanoutWatchAndSync(t *testing.T) {
 		assertFanoutHandlers(t, syncPool, helper.Targets)
 	})
 
+	t.Run("adding priority classes creates handlers per class", func(t *testing.T) {
+		for _, class := range []string{"bulk", "urgent"} {
+			topic, err := helper.PubsubClient.CreateTopic(ctx, "decouple-topic-"+class)
+			if err != nil {
+				t.Fatalf("failed to create priority class decouple topic: %v", err)
+			}
+			if _, err := helper.PubsubClient.CreateSubscription(ctx, "decouple-sub-"+class, pubsub.SubscriptionConfig{Topic: topic}); err != nil {
+				t.Fatalf("failed to create priority class decouple subscription: %v", err)
+			}
+		}
+		helper.Targets.MutateBroker(bs[2].Namespace, bs[2].Name, func(bm config.BrokerMutation) {
+			bm.SetPriorityClasses("tier",
+				&config.PriorityClass{
+					Name:           "bulk",
+					Values:         []string{"batch"},
+					DecoupleQueue:  &config.Queue{Topic: "decouple-topic-bulk", Subscription: "decouple-sub-bulk", State: config.State_READY},
+					MaxConcurrency: 2,
+				},
+				&config.PriorityClass{
+					Name:          "urgent",
+					Values:        []string{"page"},
+					DecoupleQueue: &config.Queue{Topic: "decouple-topic-urgent", Subscription: "decouple-sub-urgent", State: config.State_READY},
+				},
+			)
+		})
+		signal <- struct{}{}
+		// Wait a short period for the handlers to be updated.
+		<-time.After(time.Second)
+		assertFanoutHandlers(t, syncPool, helper.Targets)
+		value, ok := syncPool.pool.Load(bs[2].Key() + "/bulk")
+		if !ok {
+			t.Fatal("no handler for the bulk priority class")
+		}
+		if got := value.(*fanoutHandlerCache).concurrency; got != 2 {
+			t.Errorf("bulk priority class handler concurrency got=%d, want=2", got)
+		}
+	})
+
+	t.Run("removing a priority class deletes its handler", func(t *testing.T) {
+		helper.Targets.MutateBroker(bs[2].Namespace, bs[2].Name, func(bm config.BrokerMutation) {
+			bm.SetPriorityClasses("tier", &config.PriorityClass{
+				Name:          "urgent",
+				Values:        []string{"page"},
+				DecoupleQueue: &config.Queue{Topic: "decouple-topic-urgent", Subscription: "decouple-sub-urgent", State: config.State_READY},
+			})
+		})
+		signal <- struct{}{}
+		// Wait a short period for the handlers to be updated.
+		<-time.After(time.Second)
+		assertFanoutHandlers(t, syncPool, helper.Targets)
+	})
+
 	t.Run("deleting all brokers deletes all handlers", func(t *testing.T) {
 		// clean up all brokers
 		for _, b := range bs {
@@ -407,6 +460,9 @@ func assertFanoutHandlers(t *testing.T, p *FanoutPool, targets config.Targets) {
 	targets.RangeBrokers(func(b *config.Broker) bool {
 		if b.State == config.State_READY {
 			wantHandlers[b.Key()] = true
+			for _, c := range b.PriorityClasses {
+				wantHandlers[b.Key()+"/"+c.Name] = true
+			}
 		}
 		return true
 	})
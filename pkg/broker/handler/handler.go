@@ -22,21 +22,22 @@ import (
 	"sync/atomic"
 	"time"
 
-	"cloud.google.com/go/pubsub"
-	cepubsub "github.com/cloudevents/sdk-go/protocol/pubsub/v2"
 	"github.com/cloudevents/sdk-go/v2/binding"
+	handlerctx "github.com/google/knative-gcp/pkg/broker/handler/context"
 	"github.com/google/knative-gcp/pkg/broker/handler/processors"
+	"github.com/google/knative-gcp/pkg/broker/queue"
+	"github.com/google/knative-gcp/pkg/kncloudevents"
 	"github.com/google/knative-gcp/pkg/logging"
 	"github.com/google/knative-gcp/pkg/metrics"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
 	"go.uber.org/zap"
 )
 
-// Handler pulls Pubsub messages as events and processes them
+// Handler pulls messages from a queue as events and processes them
 // with chain of processors.
 type Handler struct {
-	// Subscription is the pubsub subscription that messages will be
-	// received from.
-	Subscription *pubsub.Subscription
+	// Inbound is the queue that messages will be received from.
+	Inbound queue.Inbound
 
 	// Processor is the processor to process events.
 	Processor processors.Interface
@@ -44,6 +45,14 @@ type Handler struct {
 	// Timeout is the timeout for processing each individual event.
 	Timeout time.Duration
 
+	// attempts counts deliveries of messages that don't carry a delivery
+	// attempt. If nil, attempts are only taken from the queue.
+	attempts *attemptTracker
+
+	// unconvertible sends the messages that can't be converted to events to
+	// the unconvertible sink. If nil, they are dropped.
+	unconvertible *kncloudevents.UnconvertibleSender
+
 	// cancel is function to stop pulling messages.
 	cancel context.CancelFunc
 
@@ -53,19 +62,19 @@ type Handler struct {
 
 // NewHandler creates a new Handler.
 func NewHandler(
-	sub *pubsub.Subscription,
+	inbound queue.Inbound,
 	processor processors.Interface,
 	timeout time.Duration,
 ) *Handler {
 	return &Handler{
-		Subscription: sub,
-		Processor:    processor,
-		Timeout:      timeout,
+		Inbound:   inbound,
+		Processor: processor,
+		Timeout:   timeout,
 	}
 }
 
 // Start starts the handler.
-// done func will be called if the pubsub inbound is closed.
+// done func will be called if the inbound is closed.
 func (h *Handler) Start(ctx context.Context, done func(error)) {
 	ctx, h.cancel = context.WithCancel(ctx)
 	h.alive.Store(true)
@@ -73,7 +82,7 @@ func (h *Handler) Start(ctx context.Context, done func(error)) {
 	go func() {
 		// For any reason if inbound is closed, mark alive as false.
 		defer h.alive.Store(false)
-		done(h.Subscription.Receive(ctx, h.receive))
+		done(h.Inbound.Receive(ctx, h.receive))
 	}()
 }
 
@@ -88,14 +97,12 @@ func (h *Handler) IsAlive() bool {
 }
 
 // receive converts message to events and invoke processor chain.
-func (h *Handler) receive(ctx context.Context, msg *pubsub.Message) {
+func (h *Handler) receive(ctx context.Context, msg queue.Message) {
 	ctx = metrics.StartEventProcessing(ctx)
-	event, err := binding.ToEvent(ctx, cepubsub.NewMessage(msg))
+	event, err := binding.ToEvent(ctx, msg.Binding())
 	if isNonRetryable(err) {
 		logEventConversionError(ctx, msg, err, "failed to convert received message to an event, check the msg format")
-		// Ack the message so it won't be retried.
-		// TODO Should this go to the DLQ once DLQ is implemented?
-		msg.Ack()
+		h.handleUnconvertible(ctx, msg, err)
 		return
 	}
 	if err != nil {
@@ -104,6 +111,10 @@ func (h *Handler) receive(ctx context.Context, msg *pubsub.Message) {
 		return
 	}
 
+	if attempt, ok := h.deliveryAttempt(msg); ok {
+		ctx = handlerctx.WithDeliveryAttempt(ctx, attempt)
+	}
+
 	if h.Timeout != 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
@@ -115,6 +126,45 @@ func (h *Handler) receive(ctx context.Context, msg *pubsub.Message) {
 		return
 	}
 
+	h.ack(msg)
+}
+
+// deliveryAttempt returns the number of times the message has been delivered,
+// including the current delivery.
+func (h *Handler) deliveryAttempt(msg queue.Message) (int, bool) {
+	if attempt := msg.DeliveryAttempt(); attempt != nil {
+		return *attempt, true
+	}
+	if h.attempts != nil {
+		return h.attempts.increment(msg.ID()), true
+	}
+	return 0, false
+}
+
+// handleUnconvertible sends a message that can't be converted to an event to
+// the unconvertible sink, if any, and acks it so that it won't be retried. The
+// message is nacked if it can't be sent.
+func (h *Handler) handleUnconvertible(ctx context.Context, msg queue.Message, err error) {
+	if h.unconvertible != nil {
+		if err := h.unconvertible.Send(ctx, schemasv1.UnconvertibleMessage{
+			ID:          msg.ID(),
+			Data:        msg.Data(),
+			Attributes:  msg.Attributes(),
+			PublishTime: msg.PublishTime(),
+			Error:       err.Error(),
+		}); err != nil {
+			logging.FromContext(ctx).Error("failed to send unconvertible message", zap.String("messageID", msg.ID()), zap.Error(err))
+			msg.Nack()
+			return
+		}
+	}
+	h.ack(msg)
+}
+
+func (h *Handler) ack(msg queue.Message) {
+	if h.attempts != nil {
+		h.attempts.forget(msg.ID())
+	}
 	msg.Ack()
 }
 
@@ -125,14 +175,13 @@ func isNonRetryable(err error) bool {
 }
 
 // Log the full message in debug level and a truncated version as an error in case the message is too big (can be as big as 10MB),
-func logEventConversionError(ctx context.Context, pm *pubsub.Message, err error, msg string) {
+func logEventConversionError(ctx context.Context, m queue.Message, err error, msg string) {
 	maxLen := 2000
-	truncated := pm
-	if len(pm.Data) > maxLen {
-		copy := *pm
-		copy.Data = copy.Data[:maxLen]
-		truncated = &copy
+	data := m.Data()
+	truncated := data
+	if len(data) > maxLen {
+		truncated = data[:maxLen]
 	}
-	logging.FromContext(ctx).Debug(msg, zap.Any("message", pm), zap.Error(err))
-	logging.FromContext(ctx).Error(msg, zap.Any("message-truncated", truncated), zap.Error(err))
+	logging.FromContext(ctx).Debug(msg, zap.String("messageID", m.ID()), zap.ByteString("message", data), zap.Error(err))
+	logging.FromContext(ctx).Error(msg, zap.String("messageID", m.ID()), zap.ByteString("message-truncated", truncated), zap.Error(err))
 }
@@ -19,6 +19,8 @@ package handler
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"runtime"
 	"testing"
 	"time"
@@ -28,12 +30,16 @@ import (
 	cepubsub "github.com/cloudevents/sdk-go/protocol/pubsub/v2"
 	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/event"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/sync/semaphore"
 	"google.golang.org/api/option"
 	"google.golang.org/grpc"
 
 	"github.com/google/knative-gcp/pkg/broker/handler/processors"
+	"github.com/google/knative-gcp/pkg/broker/queue"
+	"github.com/google/knative-gcp/pkg/kncloudevents"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
 	kgcptesting "github.com/google/knative-gcp/pkg/testing"
 )
 
@@ -88,7 +94,7 @@ func TestHandler(t *testing.T) {
 
 	eventCh := make(chan *event.Event)
 	processor := &processors.FakeProcessor{PrevEventsCh: eventCh}
-	h := NewHandler(sub, processor, time.Second)
+	h := NewHandler(queue.NewPubsubInbound(sub), processor, time.Second)
 	h.Start(ctx, func(err error) {})
 	defer h.Stop()
 	if !h.IsAlive() {
@@ -160,6 +166,88 @@ func TestHandler(t *testing.T) {
 	})
 }
 
+func TestHandlerUnconvertible(t *testing.T) {
+	ctx := context.Background()
+	c, close := testPubsubClient(ctx, t, testProjectID)
+	defer close()
+
+	topic, err := c.CreateTopic(ctx, testTopic)
+	if err != nil {
+		t.Fatalf("failed to create topic: %v", err)
+	}
+	sub, err := c.CreateSubscription(ctx, testSub, pubsub.SubscriptionConfig{
+		Topic: topic,
+	})
+	if err != nil {
+		t.Fatalf("failed to create subscription: %v", err)
+	}
+
+	sinkClient, err := cehttp.New()
+	if err != nil {
+		t.Fatalf("failed to create unconvertible sink cloudevents client: %v", err)
+	}
+	sinkSvr := httptest.NewServer(sinkClient)
+	defer sinkSvr.Close()
+
+	eventCh := make(chan *event.Event)
+	processor := &processors.FakeProcessor{PrevEventsCh: eventCh}
+	h := NewHandler(queue.NewPubsubInbound(sub), processor, time.Second)
+	h.unconvertible = kncloudevents.NewUnconvertibleSender(http.DefaultClient, sinkSvr.URL, "/apis/v1/namespaces/ns/brokers/broker")
+	h.Start(ctx, func(err error) {})
+	defer h.Stop()
+
+	msgID, err := topic.Publish(ctx, &pubsub.Message{
+		Data:       []byte("not an event"),
+		Attributes: map[string]string{"key": "value"},
+	}).Get(ctx)
+	if err != nil {
+		t.Fatalf("failed to publish message: %v", err)
+	}
+
+	rctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	msg, err := sinkClient.Receive(rctx)
+	if err != nil {
+		t.Fatalf("unexpected error from unconvertible sink when receiving event: %v", err)
+	}
+	defer msg.Finish(nil)
+	gotEvent, err := binding.ToEvent(rctx, msg)
+	if err != nil {
+		t.Fatalf("unconvertible sink received message that cannot be converted to an event: %v", err)
+	}
+	if got, want := gotEvent.Type(), schemasv1.UnconvertibleMessageEventType; got != want {
+		t.Errorf("event type = %q, want %q", got, want)
+	}
+	if got, want := gotEvent.Source(), "/apis/v1/namespaces/ns/brokers/broker"; got != want {
+		t.Errorf("event source = %q, want %q", got, want)
+	}
+	var data schemasv1.UnconvertibleMessage
+	if err := gotEvent.DataAs(&data); err != nil {
+		t.Fatalf("failed to decode event data: %v", err)
+	}
+	if data.PublishTime == nil {
+		t.Error("event data has no publish time")
+	}
+	if data.Error == "" {
+		t.Error("event data has no conversion error")
+	}
+	data.PublishTime = nil
+	data.Error = ""
+	want := schemasv1.UnconvertibleMessage{
+		ID:         msgID,
+		Data:       []byte("not an event"),
+		Attributes: map[string]string{"key": "value"},
+	}
+	if diff := cmp.Diff(want, data); diff != "" {
+		t.Errorf("unconvertible message (-want,+got): %v", diff)
+	}
+
+	// The message is acked and doesn't reach the processor.
+	if gotEvent := nextEventWithTimeout(eventCh); gotEvent != nil {
+		t.Errorf("processor should receive 0 events but got: %+v", gotEvent)
+	}
+}
+
 type BenchProcessor struct {
 	processors.BaseProcessor
 
@@ -199,7 +287,7 @@ func runBench(b *testing.B, eventSize int) {
 	processor := &BenchProcessor{
 		processed: semaphore.NewWeighted(maxMsgs),
 	}
-	h := NewHandler(sub, processor, time.Second)
+	h := NewHandler(queue.NewPubsubInbound(sub), processor, time.Second)
 	h.Start(ctx, func(err error) {})
 	defer h.Stop()
 	if !h.IsAlive() {
@@ -0,0 +1,66 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"cloud.google.com/go/pubsub"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/broker/queue"
+	"github.com/google/knative-gcp/pkg/metrics"
+	"github.com/google/knative-gcp/pkg/redis"
+)
+
+// InboundFactory creates the inbound of the handler of a decouple or retry
+// queue.
+type InboundFactory interface {
+	NewInbound(q *config.Queue, options *Options) queue.Inbound
+}
+
+// NewPubsubInboundFactory creates an InboundFactory receiving from the
+// Pub/Sub subscriptions of the queues.
+func NewPubsubInboundFactory(client *pubsub.Client) InboundFactory {
+	return &pubsubInboundFactory{client: client}
+}
+
+type pubsubInboundFactory struct {
+	client *pubsub.Client
+}
+
+func (f *pubsubInboundFactory) NewInbound(q *config.Queue, options *Options) queue.Inbound {
+	sub := f.client.Subscription(q.Subscription)
+	sub.ReceiveSettings = options.PubsubReceiveSettings
+	return queue.NewPubsubInbound(sub)
+}
+
+// NewRedisInboundFactory creates an InboundFactory receiving from the Redis
+// streams named after the topics of the queues, through the consumer groups
+// named after their subscriptions. The pod name is the consumer name.
+func NewRedisInboundFactory(client *redis.Client, podName metrics.PodName) InboundFactory {
+	return &redisInboundFactory{client: client, consumer: string(podName)}
+}
+
+type redisInboundFactory struct {
+	client   *redis.Client
+	consumer string
+}
+
+func (f *redisInboundFactory) NewInbound(q *config.Queue, options *Options) queue.Inbound {
+	inbound := queue.NewRedisInbound(f.client, q.Topic, q.Subscription, f.consumer)
+	inbound.BatchSize = options.HandlerConcurrency
+	return inbound
+}
@@ -21,6 +21,9 @@ import (
 	"time"
 
 	"cloud.google.com/go/pubsub"
+
+	"github.com/google/knative-gcp/pkg/broker/handler/breaker"
+	"github.com/google/knative-gcp/pkg/broker/handler/dedup"
 )
 
 var (
@@ -50,6 +53,15 @@ type Options struct {
 	DeliveryTimeout time.Duration
 	// PubsubReceiveSettings is the pubsub receive settings.
 	PubsubReceiveSettings pubsub.ReceiveSettings
+	// CircuitBreaker configures the circuit breakers of the fanout targets.
+	// Circuit breaking is disabled by default.
+	CircuitBreaker breaker.Settings
+	// DedupStore records the events delivered to the targets with a
+	// deduplication window. Events are not deduplicated if it is nil.
+	DedupStore dedup.Store
+	// UnconvertibleSinkURI is the URI that the messages which can't be
+	// converted to events are sent to. They are dropped if it is empty.
+	UnconvertibleSinkURI string
 }
 
 // NewOptions creates a Options.
@@ -107,3 +119,24 @@ func WithDeliveryTimeout(t time.Duration) Option {
 		o.DeliveryTimeout = t
 	}
 }
+
+// WithCircuitBreaker sets the CircuitBreaker settings.
+func WithCircuitBreaker(s breaker.Settings) Option {
+	return func(o *Options) {
+		o.CircuitBreaker = s
+	}
+}
+
+// WithDedupStore sets the DedupStore.
+func WithDedupStore(s dedup.Store) Option {
+	return func(o *Options) {
+		o.DedupStore = s
+	}
+}
+
+// WithUnconvertibleSinkURI sets the UnconvertibleSinkURI.
+func WithUnconvertibleSinkURI(uri string) Option {
+	return func(o *Options) {
+		o.UnconvertibleSinkURI = uri
+	}
+}
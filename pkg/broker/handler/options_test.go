@@ -22,6 +22,8 @@ import (
 
 	"cloud.google.com/go/pubsub"
 	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/knative-gcp/pkg/broker/handler/breaker"
 )
 
 func TestWithHandlerConcurrency(t *testing.T) {
@@ -96,3 +98,26 @@ func TestWithDeliveryTimeout(t *testing.T) {
 		t.Errorf("options timeout per event got=%v, want=%v", opt.DeliveryTimeout, want)
 	}
 }
+
+func TestWithCircuitBreaker(t *testing.T) {
+	opt, err := NewOptions()
+	if err != nil {
+		t.Errorf("NewOptions got unexpected error: %v", err)
+	}
+	if opt.CircuitBreaker.Enabled() {
+		t.Errorf("options circuit breaker enabled by default: %+v", opt.CircuitBreaker)
+	}
+
+	want := breaker.Settings{
+		FailureThreshold: 5,
+		SlowThreshold:    30 * time.Second,
+		OpenDuration:     time.Minute,
+	}
+	opt, err = NewOptions(WithCircuitBreaker(want))
+	if err != nil {
+		t.Errorf("NewOptions got unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, opt.CircuitBreaker); diff != "" {
+		t.Errorf("options CircuitBreaker (-want,+got): %v", diff)
+	}
+}
@@ -36,13 +36,25 @@ import (
 
 	"github.com/google/knative-gcp/pkg/broker/config"
 	"github.com/google/knative-gcp/pkg/broker/eventutil"
+	"github.com/google/knative-gcp/pkg/broker/handler/breaker"
 	handlerctx "github.com/google/knative-gcp/pkg/broker/handler/context"
+	"github.com/google/knative-gcp/pkg/broker/handler/dedup"
 	"github.com/google/knative-gcp/pkg/broker/handler/processors"
+	"github.com/google/knative-gcp/pkg/broker/queue"
 	"github.com/google/knative-gcp/pkg/metrics"
 )
 
 const defaultEventHopsLimit int32 = 255
 
+// deliveryError is returned when the target responds with a non-2xx status code.
+type deliveryError struct {
+	statusCode int
+}
+
+func (e *deliveryError) Error() string {
+	return fmt.Sprintf("event delivery failed: HTTP status code %d", e.statusCode)
+}
+
 // Processor delivers events based on the broker/target in the context.
 type Processor struct {
 	processors.BaseProcessor
@@ -67,6 +79,15 @@ type Processor struct {
 
 	// StatsReporter is used to report delivery metrics.
 	StatsReporter *metrics.DeliveryReporter
+
+	// Breakers holds the circuit breakers of the targets. If set along with
+	// RetryOnFailure, the events of a target whose circuit breaker is open are
+	// sent to the retry topic without attempting delivery.
+	Breakers *breaker.Set
+
+	// Dedup reserves and records the events delivered to the targets with a
+	// deduplication window, so that their duplicates are dropped.
+	Dedup dedup.Store
 }
 
 var _ processors.Interface = (*Processor)(nil)
@@ -102,6 +123,52 @@ func (p *Processor) Process(ctx context.Context, e *event.Event) error {
 		return nil
 	}
 
+	if p.RetryOnFailure && target.Ordered {
+		// Delivering the events of an ordered target from the fanout would reorder them when a
+		// delivery fails and the event goes to the retry queue. Instead, all its events go
+		// through the retry queue, whose ordered subscription serialises the delivery of the
+		// events sharing a partition key. Delivering directly when no event of the key is
+		// pending isn't possible: the retry queue is consumed by the retry pods, and the events
+		// of a key can move between fanout pods, so the fanout can't know what is pending. This
+		// adds the latency of a publish and a pull, see docs/how-to/broker-ordering.md.
+		return p.sendToRetryTopic(ctx, target, e, false)
+	}
+
+	var dedupKey string
+	if p.Dedup != nil && target.DedupWindowSeconds > 0 {
+		// Reserving the event rather than looking it up drops the duplicates handled
+		// concurrently, and not only the ones handled after the event was delivered.
+		key := dedup.Key(tk, e.Source(), e.ID())
+		if reserved, err := p.Dedup.Reserve(ctx, key, target.DedupWindow()); err != nil {
+			// Deliver the event rather than risking to drop it.
+			logging.FromContext(ctx).Warn("failed to reserve event delivery", zap.String("target", tk), zap.Error(err))
+		} else if !reserved {
+			logging.FromContext(ctx).Debug("event already delivered to target",
+				zap.String("target", tk), zap.String("event.id", e.ID()), zap.String("event.source", e.Source()))
+			trace.FromContext(ctx).Annotate(
+				ceclient.EventTraceAttributes(e),
+				"event dropped: duplicate event",
+			)
+			return nil
+		} else {
+			dedupKey = key
+		}
+	}
+
+	var br *breaker.Breaker
+	var call breaker.Call
+	if p.RetryOnFailure && p.Breakers != nil {
+		br = p.Breakers.Get(tk)
+		var allowed bool
+		if call, allowed = br.Allow(ctx); !allowed {
+			// A failing or slow target would otherwise hold the goroutines and the
+			// time budget of the event shared with the other targets of the broker.
+			trace.FromContext(ctx).Annotate(nil, "circuit breaker open: enqueueing for retry")
+			p.releaseDedup(ctx, tk, dedupKey)
+			return p.sendToRetryTopic(ctx, target, e, false)
+		}
+	}
+
 	// Hops is a broker local counter so remove any hops value before forwarding.
 	// Do not modify the original event as we need to send the original
 	// event to retry queue on failure.
@@ -127,8 +194,30 @@ func (p *Processor) Process(ctx context.Context, e *event.Event) error {
 		defer cancel()
 	}
 
-	if err := p.deliver(dctx, target, broker, eventutil.NewImmutableEventMessage(e), hops); err != nil {
+	startTime := time.Now()
+	resp, err := p.sendToTarget(dctx, target, eventutil.NewImmutableEventMessage(e))
+	if br != nil {
+		// Only the subscriber call counts, a slow or failing reply path isn't the target's.
+		br.Done(ctx, call, err, time.Since(startTime))
+	}
+	if err == nil {
+		if dedupKey != "" {
+			// Record the event as soon as the target accepted it, so that a duplicate
+			// handled while the reply is sent is dropped.
+			if err := p.Dedup.Record(ctx, dedupKey, target.DedupWindow()); err != nil {
+				logging.FromContext(ctx).Warn("failed to record delivered event", zap.String("target", tk), zap.Error(err))
+			}
+		}
+		err = p.reply(dctx, target, broker, resp, hops)
+	} else {
+		// Release the reservation so that the failed delivery is retried.
+		p.releaseDedup(ctx, tk, dedupKey)
+	}
+	if err != nil {
 		if !p.RetryOnFailure {
+			if attempts, ok := p.retriesExhausted(ctx, target, e); ok {
+				return p.sendToDeadLetterSink(ctx, target, e, attempts, err)
+			}
 			return err
 		}
 
@@ -138,32 +227,39 @@ func (p *Processor) Process(ctx context.Context, e *event.Event) error {
 			"enqueueing for retry",
 		)
 
-		return p.sendToRetryTopic(ctx, target, e)
+		return p.sendToRetryTopic(ctx, target, e, true)
 	}
 	// For post-delivery processing.
 	return p.Next().Process(ctx, e)
 }
 
-// deliver delivers msg to target and sends the target's reply to the broker ingress.
-func (p *Processor) deliver(ctx context.Context, target *config.Target, broker *config.Broker, msg binding.Message, hops int32) error {
+// releaseDedup releases the deduplication reservation of the event with key dedupKey for the
+// target with key tk, if any, when the event wasn't delivered.
+func (p *Processor) releaseDedup(ctx context.Context, tk, dedupKey string) {
+	if dedupKey == "" {
+		return
+	}
+	if err := p.Dedup.Release(ctx, dedupKey); err != nil {
+		logging.FromContext(ctx).Warn("failed to release event delivery", zap.String("target", tk), zap.Error(err))
+	}
+}
+
+// sendToTarget delivers msg to target, and returns the response of the target when it
+// accepted the event.
+func (p *Processor) sendToTarget(ctx context.Context, target *config.Target, msg binding.Message) (*http.Response, error) {
 	startTime := time.Now()
 	// Remove hops from forwarded event.
-	resp, err := p.sendMsg(ctx, target.Address, msg, transformer.DeleteExtension(eventutil.HopsAttribute))
+	resp, err := p.sendMsg(ctx, target.Address, msg,
+		transformer.DeleteExtension(eventutil.HopsAttribute), transformer.DeleteExtension(eventutil.UndeliveredAttribute))
 	if err != nil {
 		var result *url.Error
 		if errors.As(err, &result) && result.Timeout() {
 			// If the delivery is cancelled because of timeout, report event dispatch time without resp status code.
 			p.StatsReporter.ReportEventDispatchTime(ctx, time.Since(startTime))
 		}
-		return err
+		return nil, err
 	}
 
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			logging.FromContext(ctx).Warn("failed to close response body", zap.Error(err))
-		}
-	}()
-
 	// Insert status code tag into context.
 	cctx, err := metrics.AddRespStatusCodeTags(ctx, resp.StatusCode)
 	if err != nil {
@@ -173,8 +269,22 @@ func (p *Processor) deliver(ctx context.Context, target *config.Target, broker *
 	p.StatsReporter.ReportEventDispatchTime(cctx, time.Since(startTime))
 
 	if resp.StatusCode/100 != 2 {
-		return fmt.Errorf("event delivery failed: HTTP status code %d", resp.StatusCode)
+		if err := resp.Body.Close(); err != nil {
+			logging.FromContext(ctx).Warn("failed to close response body", zap.Error(err))
+		}
+		return nil, &deliveryError{statusCode: resp.StatusCode}
 	}
+	return resp, nil
+}
+
+// reply sends the reply in resp, the response of target, to the reply address of the target,
+// or else to the broker ingress, unless the reply policy of the target drops it.
+func (p *Processor) reply(ctx context.Context, target *config.Target, broker *config.Broker, resp *http.Response, hops int32) error {
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logging.FromContext(ctx).Warn("failed to close response body", zap.Error(err))
+		}
+	}()
 
 	respMsg := cehttp.NewMessageFromHttpResponse(resp)
 	if respMsg.ReadEncoding() == binding.EncodingUnknown {
@@ -226,8 +336,45 @@ func (p *Processor) deliver(ctx context.Context, target *config.Target, broker *
 		return nil
 	}
 
+	replyAddress := target.ReplyAddress
+	if replyAddress == "" {
+		replyAddress = broker.Address
+	}
+	if replyAddress == "" || target.ReplyPolicy.GetDrop() {
+		// Neither the target nor the broker takes replies, e.g. a channel subscriber without reply,
+		// or the reply policy of the target drops them.
+		logging.FromContext(ctx).Debug("no reply address or replies dropped by policy: dropping reply", zap.String("target", target.Name))
+		if err := respMsg.Finish(nil); err != nil {
+			logging.FromContext(ctx).Warn("failed to close reply response body", zap.Error(err))
+		}
+		return nil
+	}
+
+	var reply binding.Message = respMsg
+	if rp := target.ReplyPolicy; len(rp.GetAllowedTypes()) > 0 || len(rp.GetAllowedSources()) > 0 {
+		// The reply is converted to an event to check it against the allowlists of the policy.
+		e, err := binding.ToEvent(ctx, respMsg)
+		if err != nil {
+			logging.FromContext(ctx).Error("failed to convert response message to event",
+				zap.Error(err),
+				zap.Any("response", respMsg),
+			)
+			return nil
+		}
+		if !rp.Allows(e.Type(), e.Source()) {
+			logging.FromContext(ctx).Warn("reply is not allowed by the reply policy: dropping reply",
+				zap.String("target", target.Name),
+				zap.String("reply.type", e.Type()),
+				zap.String("reply.source", e.Source()),
+			)
+			trace.FromContext(ctx).Annotate(ceclient.EventTraceAttributes(e), "Event reply dropped due to reply policy")
+			return nil
+		}
+		reply = eventutil.NewImmutableEventMessage(e)
+	}
+
 	// Attach the previous hops for the reply.
-	replyResp, err := p.sendMsg(ctx, broker.Address, respMsg, eventutil.SetRemainingHopsTransformer(hops))
+	replyResp, err := p.sendMsg(ctx, replyAddress, reply, eventutil.SetRemainingHopsTransformer(hops))
 	if err != nil {
 		return err
 	}
@@ -248,10 +395,76 @@ func (p *Processor) sendMsg(ctx context.Context, address string, msg binding.Mes
 	return p.DeliverClient.Do(req)
 }
 
-func (p *Processor) sendToRetryTopic(ctx context.Context, target *config.Target, event *event.Event) error {
+// sendToRetryTopic sends the event to the retry topic of the target. The events whose delivery
+// wasn't attempted are marked so that the retry doesn't count an attempt for them.
+func (p *Processor) sendToRetryTopic(ctx context.Context, target *config.Target, event *event.Event, attempted bool) error {
+	if !attempted {
+		undelivered := event.Clone()
+		undelivered.SetExtension(eventutil.UndeliveredAttribute, true)
+		event = &undelivered
+	}
 	pctx := cecontext.WithTopic(ctx, target.RetryQueue.Topic)
+	if target.Ordered {
+		pctx = queue.WithOrderingKey(pctx, eventutil.PartitionKey(event))
+	}
 	if err := p.DeliverRetryClient.Send(pctx, *event); err != nil {
 		return fmt.Errorf("failed to send event to retry topic: %w", err)
 	}
 	return nil
 }
+
+// retriesExhausted returns the number of delivery attempts if the target has an
+// addressable dead letter sink and the event has used up its retries. The
+// initial delivery by the fanout is counted unless the fanout sent the event to
+// the retry topic without attempting it.
+func (p *Processor) retriesExhausted(ctx context.Context, target *config.Target, e *event.Event) (int, bool) {
+	if target.DeliverySpec == nil || target.DeliverySpec.DeadLetter == "" {
+		return 0, false
+	}
+	attempts, err := handlerctx.GetDeliveryAttempt(ctx)
+	if err != nil {
+		return 0, false
+	}
+	if _, ok := e.Extensions()[eventutil.UndeliveredAttribute]; !ok {
+		attempts++
+	}
+	return attempts, attempts > int(target.DeliverySpec.Retry)
+}
+
+// sendToDeadLetterSink sends the event to the target's dead letter sink along
+// with the context of the failed delivery and the number of delivery attempts.
+func (p *Processor) sendToDeadLetterSink(ctx context.Context, target *config.Target, e *event.Event, attempts int, deliveryErr error) error {
+	dl := eventutil.DeadLetterTransformer{
+		Trigger:  target.Namespace + "/" + target.Name,
+		Attempts: attempts,
+		Err:      deliveryErr,
+	}
+	var de *deliveryError
+	if errors.As(deliveryErr, &de) {
+		dl.ResponseCode = de.statusCode
+	}
+	resp, err := p.sendMsg(ctx, target.DeliverySpec.DeadLetter, eventutil.NewImmutableEventMessage(e),
+		transformer.DeleteExtension(eventutil.HopsAttribute), transformer.DeleteExtension(eventutil.UndeliveredAttribute), dl)
+	if err != nil {
+		return fmt.Errorf("failed to send event to dead letter sink: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logging.FromContext(ctx).Warn("failed to close dead letter response body", zap.Error(err))
+		}
+	}()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to send event to dead letter sink: HTTP status code %d", resp.StatusCode)
+	}
+
+	logging.FromContext(ctx).Warn("target delivery failed, event sent to dead letter sink",
+		zap.String("target", target.Key()), zap.Int("attempts", dl.Attempts), zap.Error(deliveryErr))
+	trace.FromContext(ctx).Annotate(
+		[]trace.Attribute{
+			trace.StringAttribute("error_message", deliveryErr.Error()),
+			trace.Int64Attribute("attempts", int64(dl.Attempts)),
+		},
+		"event sent to dead letter sink",
+	)
+	return nil
+}
@@ -25,6 +25,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"runtime"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -48,7 +49,10 @@ import (
 	"github.com/google/knative-gcp/pkg/broker/config"
 	"github.com/google/knative-gcp/pkg/broker/config/memory"
 	"github.com/google/knative-gcp/pkg/broker/eventutil"
+	"github.com/google/knative-gcp/pkg/broker/handler/breaker"
 	handlerctx "github.com/google/knative-gcp/pkg/broker/handler/context"
+	"github.com/google/knative-gcp/pkg/broker/handler/dedup"
+	"github.com/google/knative-gcp/pkg/broker/queue"
 	"github.com/google/knative-gcp/pkg/metrics"
 	reportertest "github.com/google/knative-gcp/pkg/metrics/testing"
 
@@ -86,6 +90,12 @@ func TestDeliverSuccess(t *testing.T) {
 		wantOrigin *event.Event
 		reply      *event.Event
 		wantReply  *event.Event
+		// Whether the reply address is set on the target rather than the broker.
+		targetReply bool
+		// Whether neither the target nor the broker has a reply address.
+		noReply bool
+		// The reply policy of the target.
+		replyPolicy *config.ReplyPolicy
 	}{{
 		name:       "success",
 		origin:     sampleEvent,
@@ -105,6 +115,46 @@ func TestDeliverSuccess(t *testing.T) {
 		}(),
 		wantOrigin: sampleEvent,
 		reply:      &sampleReply,
+	}, {
+		name:       "success with reply to target reply address",
+		origin:     sampleEvent,
+		wantOrigin: sampleEvent,
+		reply:      &sampleReply,
+		wantReply: func() *event.Event {
+			copy := sampleReply.Clone()
+			eventutil.UpdateRemainingHops(context.Background(), &copy, defaultEventHopsLimit)
+			return &copy
+		}(),
+		targetReply: true,
+	}, {
+		name:       "success without reply address",
+		origin:     sampleEvent,
+		wantOrigin: sampleEvent,
+		reply:      &sampleReply,
+		noReply:    true,
+	}, {
+		name:        "success with reply dropped by reply policy",
+		origin:      sampleEvent,
+		wantOrigin:  sampleEvent,
+		reply:       &sampleReply,
+		replyPolicy: &config.ReplyPolicy{Drop: true},
+	}, {
+		name:       "success with reply allowed by reply policy",
+		origin:     sampleEvent,
+		wantOrigin: sampleEvent,
+		reply:      &sampleReply,
+		wantReply: func() *event.Event {
+			copy := sampleReply.Clone()
+			eventutil.UpdateRemainingHops(context.Background(), &copy, defaultEventHopsLimit)
+			return &copy
+		}(),
+		replyPolicy: &config.ReplyPolicy{AllowedTypes: []string{"type"}, AllowedSources: []string{"other", "source"}},
+	}, {
+		name:        "success with reply not allowed by reply policy",
+		origin:      sampleEvent,
+		wantOrigin:  sampleEvent,
+		reply:       &sampleReply,
+		replyPolicy: &config.ReplyPolicy{AllowedTypes: []string{"other"}},
 	}}
 
 	for _, tc := range cases {
@@ -126,10 +176,18 @@ func TestDeliverSuccess(t *testing.T) {
 			defer ingressSvr.Close()
 
 			broker := &config.Broker{Namespace: "ns", Name: "broker"}
-			target := &config.Target{Namespace: "ns", Name: "target", Broker: "broker", Address: targetSvr.URL}
+			target := &config.Target{Namespace: "ns", Name: "target", Broker: "broker", Address: targetSvr.URL, ReplyPolicy: tc.replyPolicy}
+			brokerAddress := ingressSvr.URL
+			if tc.targetReply {
+				target.ReplyAddress = ingressSvr.URL
+				brokerAddress = ""
+			}
+			if tc.noReply {
+				brokerAddress = ""
+			}
 			testTargets := memory.NewEmptyTargets()
 			testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
-				bm.SetAddress(ingressSvr.URL)
+				bm.SetAddress(brokerAddress)
 				bm.UpsertTargets(target)
 			})
 			ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
@@ -332,6 +390,487 @@ func TestDeliverFailure(t *testing.T) {
 	}
 }
 
+func TestDeliverOrderedTarget(t *testing.T) {
+	reportertest.ResetDeliveryMetrics()
+	ctx := logtest.TestContextWithLogger(t)
+
+	delivered := make(chan struct{}, 1)
+	targetSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		delivered <- struct{}{}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer targetSvr.Close()
+
+	srv, c, close := testPubsubClient(ctx, t, "test-project")
+	defer close()
+	if _, err := c.CreateTopic(ctx, "test-retry-topic"); err != nil {
+		t.Fatalf("failed to create test pubsub topic: %v", err)
+	}
+	deliverRetryClient, err := ceclient.New(queue.NewPubsubSender(c))
+	if err != nil {
+		t.Fatalf("failed to create cloudevents client: %v", err)
+	}
+
+	broker := &config.Broker{Namespace: "ns", Name: "broker"}
+	target := &config.Target{
+		Namespace: "ns",
+		Name:      "target",
+		Broker:    "broker",
+		Address:   targetSvr.URL,
+		RetryQueue: &config.Queue{
+			Topic: "test-retry-topic",
+		},
+		Ordered: true,
+	}
+	testTargets := memory.NewEmptyTargets()
+	testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+		bm.UpsertTargets(target)
+	})
+	ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
+	ctx = handlerctx.WithTargetKey(ctx, target.Key())
+
+	r, err := metrics.NewDeliveryReporter("pod", "container")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Processor{
+		DeliverClient:      http.DefaultClient,
+		Targets:            testTargets,
+		RetryOnFailure:     true,
+		DeliverRetryClient: deliverRetryClient,
+		StatsReporter:      r,
+	}
+
+	origin := newSampleEvent()
+	origin.SetExtension(eventutil.PartitionKeyAttribute, "sku-1")
+	if err := p.Process(ctx, origin); err != nil {
+		t.Fatalf("Process() got error: %v", err)
+	}
+
+	select {
+	case <-delivered:
+		t.Error("The fanout delivered the event of an ordered target, want it enqueued for retry")
+	default:
+	}
+	msgs := srv.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("Got %d messages in the retry topic, want 1", len(msgs))
+	}
+	if got := msgs[0].OrderingKey; got != "sku-1" {
+		t.Errorf("Retry message ordering key got %q, want %q", got, "sku-1")
+	}
+	if got := msgs[0].Attributes["ce-"+eventutil.UndeliveredAttribute]; got != "true" {
+		t.Errorf("Retry message %s attribute got %q, want %q", eventutil.UndeliveredAttribute, got, "true")
+	}
+}
+
+func TestDeliverCircuitBreaker(t *testing.T) {
+	reportertest.ResetDeliveryMetrics()
+	ctx := logtest.TestContextWithLogger(t)
+
+	var deliveries int32
+	targetSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&deliveries, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer targetSvr.Close()
+
+	srv, c, close := testPubsubClient(ctx, t, "test-project")
+	defer close()
+	if _, err := c.CreateTopic(ctx, "test-retry-topic"); err != nil {
+		t.Fatalf("failed to create test pubsub topic: %v", err)
+	}
+	deliverRetryClient, err := ceclient.New(queue.NewPubsubSender(c))
+	if err != nil {
+		t.Fatalf("failed to create cloudevents client: %v", err)
+	}
+
+	broker := &config.Broker{Namespace: "ns", Name: "broker"}
+	target := &config.Target{
+		Namespace: "ns",
+		Name:      "target",
+		Broker:    "broker",
+		Address:   targetSvr.URL,
+		RetryQueue: &config.Queue{
+			Topic: "test-retry-topic",
+		},
+	}
+	testTargets := memory.NewEmptyTargets()
+	testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+		bm.UpsertTargets(target)
+	})
+	ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
+	ctx = handlerctx.WithTargetKey(ctx, target.Key())
+
+	r, err := metrics.NewDeliveryReporter("pod", "container")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var states []breaker.State
+	p := &Processor{
+		DeliverClient:      http.DefaultClient,
+		Targets:            testTargets,
+		RetryOnFailure:     true,
+		DeliverRetryClient: deliverRetryClient,
+		StatsReporter:      r,
+		Breakers: breaker.NewSet(breaker.Settings{FailureThreshold: 2, OpenDuration: time.Hour}, func(_ context.Context, s breaker.State) {
+			states = append(states, s)
+		}),
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := p.Process(ctx, newSampleEvent()); err != nil {
+			t.Fatalf("Process() got error: %v", err)
+		}
+	}
+
+	if got := atomic.LoadInt32(&deliveries); got != 2 {
+		t.Errorf("Got %d delivery attempts, want 2 before the circuit breaker opens", got)
+	}
+	msgs := srv.Messages()
+	if got := len(msgs); got != 3 {
+		t.Fatalf("Got %d messages in the retry topic, want 3", got)
+	}
+	// Only the event enqueued while the circuit breaker is open wasn't delivered.
+	for i, msg := range msgs {
+		_, got := msg.Attributes["ce-"+eventutil.UndeliveredAttribute]
+		if want := i == 2; got != want {
+			t.Errorf("Retry message %d has %s attribute got %v, want %v", i, eventutil.UndeliveredAttribute, got, want)
+		}
+	}
+	if diff := cmp.Diff([]breaker.State{breaker.Open}, states); diff != "" {
+		t.Errorf("Unexpected circuit breaker states (-want, +got) = %v", diff)
+	}
+}
+
+func TestDeliverCircuitBreakerSlowReply(t *testing.T) {
+	reportertest.ResetDeliveryMetrics()
+	ctx := logtest.TestContextWithLogger(t)
+
+	sampleEvent := newSampleEvent()
+	sampleReply := sampleEvent.Clone()
+	sampleReply.SetID("reply")
+
+	targetSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		cehttp.WriteResponseWriter(req.Context(), binding.ToMessage(&sampleReply), http.StatusOK, w)
+	}))
+	defer targetSvr.Close()
+	// The reply path is slower than the slow threshold of the circuit breaker.
+	ingressSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ingressSvr.Close()
+
+	broker := &config.Broker{Namespace: "ns", Name: "broker", Address: ingressSvr.URL}
+	target := &config.Target{Namespace: "ns", Name: "target", Broker: "broker", Address: targetSvr.URL}
+	testTargets := memory.NewEmptyTargets()
+	testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+		bm.SetAddress(broker.Address)
+		bm.UpsertTargets(target)
+	})
+	ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
+	ctx = handlerctx.WithTargetKey(ctx, target.Key())
+
+	r, err := metrics.NewDeliveryReporter("pod", "container")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var states []breaker.State
+	p := &Processor{
+		DeliverClient:  http.DefaultClient,
+		Targets:        testTargets,
+		RetryOnFailure: true,
+		StatsReporter:  r,
+		Breakers: breaker.NewSet(breaker.Settings{FailureThreshold: 1, SlowThreshold: 50 * time.Millisecond, OpenDuration: time.Hour}, func(_ context.Context, s breaker.State) {
+			states = append(states, s)
+		}),
+	}
+
+	if err := p.Process(ctx, sampleEvent); err != nil {
+		t.Fatalf("Process() got error: %v", err)
+	}
+	if len(states) != 0 {
+		t.Errorf("Got circuit breaker states %v, want none since only the subscriber call counts", states)
+	}
+}
+
+func TestDeliverDedup(t *testing.T) {
+	reportertest.ResetDeliveryMetrics()
+	ctx := logtest.TestContextWithLogger(t)
+
+	var deliveries int32
+	targetSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// Fail the first delivery.
+		if atomic.AddInt32(&deliveries, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer targetSvr.Close()
+
+	broker := &config.Broker{Namespace: "ns", Name: "broker"}
+	target := &config.Target{
+		Namespace:          "ns",
+		Name:               "target",
+		Broker:             "broker",
+		Address:            targetSvr.URL,
+		DedupWindowSeconds: 60,
+	}
+	testTargets := memory.NewEmptyTargets()
+	testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+		bm.UpsertTargets(target)
+	})
+	ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
+	ctx = handlerctx.WithTargetKey(ctx, target.Key())
+
+	r, err := metrics.NewDeliveryReporter("pod", "container")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := dedup.NewLRU(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Processor{
+		DeliverClient: http.DefaultClient,
+		Targets:       testTargets,
+		StatsReporter: r,
+		Dedup:         store,
+	}
+
+	origin := newSampleEvent()
+	if err := p.Process(ctx, origin); err == nil {
+		t.Fatal("Process() got no error for the failed delivery")
+	}
+	// The failed delivery is not recorded, so the retried event is delivered.
+	if err := p.Process(ctx, origin); err != nil {
+		t.Fatalf("Process() got error: %v", err)
+	}
+	// Duplicates of the delivered event are dropped.
+	duplicate := origin.Clone()
+	if err := p.Process(ctx, &duplicate); err != nil {
+		t.Fatalf("Process() got error: %v", err)
+	}
+	if got := atomic.LoadInt32(&deliveries); got != 2 {
+		t.Errorf("Got %d delivery attempts, want 2", got)
+	}
+	other := origin.Clone()
+	other.SetID("other")
+	if err := p.Process(ctx, &other); err != nil {
+		t.Fatalf("Process() got error: %v", err)
+	}
+	if got := atomic.LoadInt32(&deliveries); got != 3 {
+		t.Errorf("Got %d delivery attempts, want 3 for another event", got)
+	}
+}
+
+func TestDeliverDedupConcurrent(t *testing.T) {
+	reportertest.ResetDeliveryMetrics()
+	ctx := logtest.TestContextWithLogger(t)
+
+	origin := newSampleEvent()
+	reply := origin.Clone()
+	reply.SetID("reply")
+
+	var deliveries int32
+	delivering := make(chan struct{})
+	delivered := make(chan struct{})
+	targetSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&deliveries, 1)
+		close(delivering)
+		<-delivered
+		cehttp.WriteResponseWriter(req.Context(), binding.ToMessage(&reply), http.StatusOK, w)
+	}))
+	defer targetSvr.Close()
+
+	var p *Processor
+	var duplicateErr error
+	ingressSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// A duplicate handled while the reply is sent.
+		duplicate := origin.Clone()
+		duplicateErr = p.Process(ctx, &duplicate)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ingressSvr.Close()
+
+	broker := &config.Broker{Namespace: "ns", Name: "broker", Address: ingressSvr.URL}
+	target := &config.Target{
+		Namespace:          "ns",
+		Name:               "target",
+		Broker:             "broker",
+		Address:            targetSvr.URL,
+		DedupWindowSeconds: 60,
+	}
+	testTargets := memory.NewEmptyTargets()
+	testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+		bm.SetAddress(broker.Address)
+		bm.UpsertTargets(target)
+	})
+	ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
+	ctx = handlerctx.WithTargetKey(ctx, target.Key())
+
+	r, err := metrics.NewDeliveryReporter("pod", "container")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := dedup.NewLRU(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p = &Processor{
+		DeliverClient: http.DefaultClient,
+		Targets:       testTargets,
+		StatsReporter: r,
+		Dedup:         store,
+	}
+
+	errs := make(chan error)
+	go func() {
+		errs <- p.Process(ctx, origin)
+	}()
+	<-delivering
+	// A duplicate handled while the event is delivered.
+	duplicate := origin.Clone()
+	if err := p.Process(ctx, &duplicate); err != nil {
+		t.Errorf("Process() got error for the duplicate: %v", err)
+	}
+	close(delivered)
+	if err := <-errs; err != nil {
+		t.Fatalf("Process() got error: %v", err)
+	}
+	if duplicateErr != nil {
+		t.Errorf("Process() got error for the duplicate handled during the reply: %v", duplicateErr)
+	}
+	if got := atomic.LoadInt32(&deliveries); got != 1 {
+		t.Errorf("Got %d delivery attempts, want 1", got)
+	}
+}
+
+func TestDeliverDeadLetter(t *testing.T) {
+	cases := []struct {
+		name          string
+		attempt       int
+		undelivered   bool
+		deadLetterErr bool
+		wantErr       bool
+		wantDelivered bool
+		wantAttempts  int
+	}{{
+		name:    "retries not exhausted",
+		attempt: 2,
+		wantErr: true,
+	}, {
+		name:          "retries exhausted",
+		attempt:       3,
+		wantDelivered: true,
+		wantAttempts:  4,
+	}, {
+		name:        "retries of undelivered event not exhausted",
+		attempt:     3,
+		undelivered: true,
+		wantErr:     true,
+	}, {
+		name:          "retries of undelivered event exhausted",
+		attempt:       4,
+		undelivered:   true,
+		wantDelivered: true,
+		wantAttempts:  4,
+	}, {
+		name:          "dead letter sink failure",
+		attempt:       3,
+		deadLetterErr: true,
+		wantErr:       true,
+		wantDelivered: true,
+		wantAttempts:  4,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			reportertest.ResetDeliveryMetrics()
+			ctx := logtest.TestContextWithLogger(t)
+			targetSvr := httptest.NewServer(&targetWithFailureHandler{t: t, respCode: http.StatusServiceUnavailable})
+			defer targetSvr.Close()
+
+			deadLetterCh := make(chan *event.Event, 1)
+			deadLetterSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				e, err := binding.ToEvent(req.Context(), cehttp.NewMessageFromHttpRequest(req))
+				if err != nil {
+					t.Errorf("dead letter sink received invalid event: %v", err)
+				}
+				deadLetterCh <- e
+				if tc.deadLetterErr {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer deadLetterSvr.Close()
+
+			broker := &config.Broker{Namespace: "ns", Name: "broker"}
+			target := &config.Target{
+				Namespace: "ns",
+				Name:      "target",
+				Broker:    "broker",
+				Address:   targetSvr.URL,
+				DeliverySpec: &config.DeliverySpec{
+					DeadLetter: deadLetterSvr.URL,
+					Retry:      3,
+				},
+			}
+			testTargets := memory.NewEmptyTargets()
+			testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+				bm.UpsertTargets(target)
+			})
+			ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
+			ctx = handlerctx.WithTargetKey(ctx, target.Key())
+			ctx = handlerctx.WithDeliveryAttempt(ctx, tc.attempt)
+
+			r, err := metrics.NewDeliveryReporter("pod", "container")
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := &Processor{
+				DeliverClient: http.DefaultClient,
+				Targets:       testTargets,
+				StatsReporter: r,
+			}
+
+			origin := newSampleEvent()
+			eventutil.UpdateRemainingHops(ctx, origin, 10)
+			if tc.undelivered {
+				origin.SetExtension(eventutil.UndeliveredAttribute, true)
+			}
+			err = p.Process(ctx, origin)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("processing got error=%v, want=%v", err, tc.wantErr)
+			}
+
+			select {
+			case got := <-deadLetterCh:
+				if !tc.wantDelivered {
+					t.Fatalf("unexpected event sent to dead letter sink: %v", got)
+				}
+				want := origin.Clone()
+				eventutil.DeleteRemainingHops(ctx, &want)
+				want.SetExtension(eventutil.UndeliveredAttribute, nil)
+				want.SetExtension(eventutil.TriggerAttribute, "ns/target")
+				want.SetExtension(eventutil.AttemptsAttribute, tc.wantAttempts)
+				want.SetExtension(eventutil.ErrorCodeAttribute, http.StatusServiceUnavailable)
+				want.SetExtension(eventutil.ErrorMessageAttribute, "event delivery failed: HTTP status code 503")
+				if diff := cmp.Diff(want.String(), got.String()); diff != "" {
+					t.Errorf("dead lettered event (-want,+got): %v", diff)
+				}
+			default:
+				if tc.wantDelivered {
+					t.Error("event was not sent to the dead letter sink")
+				}
+			}
+		})
+	}
+}
+
 type NoReplyHandler struct{}
 
 func (NoReplyHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
@@ -0,0 +1,67 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"sync"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+// Cache holds the compiled filters of the targets, by target key. It is
+// shared by the filter processors of a handler pool, which prunes it on each
+// config sync.
+type Cache struct {
+	compiled sync.Map
+}
+
+// compiledFilters is the compiled filters for a target from a given config.
+type compiledFilters struct {
+	target  *config.Target
+	matcher matcher
+	err     error
+}
+
+// NewCache creates an empty Cache of compiled filters.
+func NewCache() *Cache {
+	return &Cache{}
+}
+
+// get returns the compiled filters of the target, compiling them only once
+// per target per config sync.
+func (c *Cache) get(target *config.Target) *compiledFilters {
+	if v, ok := c.compiled.Load(target.Key()); ok {
+		if cf := v.(*compiledFilters); cf.target == target {
+			return cf
+		}
+	}
+	m, err := compileFilters(target.Filters)
+	cf := &compiledFilters{target: target, matcher: m, err: err}
+	c.compiled.Store(target.Key(), cf)
+	return cf
+}
+
+// Prune removes the compiled filters of the targets that are no longer in
+// the config or were replaced by a config update.
+func (c *Cache) Prune(targets config.ReadonlyTargets) {
+	c.compiled.Range(func(key, value interface{}) bool {
+		if t, ok := targets.GetTargetByKey(key.(string)); !ok || t != value.(*compiledFilters).target {
+			c.compiled.Delete(key)
+		}
+		return true
+	})
+}
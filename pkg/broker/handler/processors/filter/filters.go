@@ -0,0 +1,150 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+// matcher is a compiled filter expression that matches the attributes of an
+// event.
+type matcher interface {
+	match(attrs map[string]string) bool
+}
+
+type allMatcher []matcher
+
+func (m allMatcher) match(attrs map[string]string) bool {
+	for _, n := range m {
+		if !n.match(attrs) {
+			return false
+		}
+	}
+	return true
+}
+
+type anyMatcher []matcher
+
+func (m anyMatcher) match(attrs map[string]string) bool {
+	for _, n := range m {
+		if n.match(attrs) {
+			return true
+		}
+	}
+	return false
+}
+
+type notMatcher struct {
+	matcher
+}
+
+func (m notMatcher) match(attrs map[string]string) bool {
+	return !m.matcher.match(attrs)
+}
+
+// attributeMatcher matches if each attribute is present in the event and its
+// value satisfies the comparison against the expected value.
+type attributeMatcher struct {
+	expected map[string]string
+	compare  func(value, expected string) bool
+}
+
+func (m attributeMatcher) match(attrs map[string]string) bool {
+	for k, expected := range m.expected {
+		value, ok := attrs[k]
+		if !ok || !m.compare(value, expected) {
+			return false
+		}
+	}
+	return true
+}
+
+func exact(value, expected string) bool {
+	return value == expected
+}
+
+// compileFilters compiles the filters of a target into a single matcher which
+// matches if all of the filters match.
+func compileFilters(filters []*config.Filter) (matcher, error) {
+	m := make(allMatcher, 0, len(filters))
+	for i, f := range filters {
+		c, err := compileFilter(f)
+		if err != nil {
+			return nil, fmt.Errorf("filters[%d]: %w", i, err)
+		}
+		m = append(m, c)
+	}
+	return m, nil
+}
+
+func compileFilter(f *config.Filter) (matcher, error) {
+	var compiled []matcher
+	if len(f.All) > 0 {
+		m, err := compileNested(f.All)
+		if err != nil {
+			return nil, fmt.Errorf("all%w", err)
+		}
+		compiled = append(compiled, allMatcher(m))
+	}
+	if len(f.Any) > 0 {
+		m, err := compileNested(f.Any)
+		if err != nil {
+			return nil, fmt.Errorf("any%w", err)
+		}
+		compiled = append(compiled, anyMatcher(m))
+	}
+	if f.Not != nil {
+		m, err := compileFilter(f.Not)
+		if err != nil {
+			return nil, fmt.Errorf("not: %w", err)
+		}
+		compiled = append(compiled, notMatcher{m})
+	}
+	if len(f.Exact) > 0 {
+		compiled = append(compiled, attributeMatcher{expected: f.Exact, compare: exact})
+	}
+	if len(f.Prefix) > 0 {
+		compiled = append(compiled, attributeMatcher{expected: f.Prefix, compare: strings.HasPrefix})
+	}
+	if len(f.Suffix) > 0 {
+		compiled = append(compiled, attributeMatcher{expected: f.Suffix, compare: strings.HasSuffix})
+	}
+	switch len(compiled) {
+	case 0:
+		return nil, errors.New("empty filter")
+	case 1:
+		return compiled[0], nil
+	default:
+		return nil, errors.New("filter must have exactly one of all, any, not, exact, prefix or suffix")
+	}
+}
+
+func compileNested(filters []*config.Filter) ([]matcher, error) {
+	m := make([]matcher, 0, len(filters))
+	for i, f := range filters {
+		c, err := compileFilter(f)
+		if err != nil {
+			return nil, fmt.Errorf("[%d]: %w", i, err)
+		}
+		m = append(m, c)
+	}
+	return m, nil
+}
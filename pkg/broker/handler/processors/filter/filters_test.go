@@ -0,0 +1,137 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"testing"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+func TestCompileFilters(t *testing.T) {
+	attrs := map[string]string{
+		"type":    "google.cloud.storage.object.v1.finalized",
+		"source":  "//storage.googleapis.com/projects/_/buckets/my-bucket",
+		"subject": "objects/photo.jpg",
+	}
+	cases := []struct {
+		name    string
+		filters []*config.Filter
+		want    bool
+		wantErr bool
+	}{{
+		name: "no filters",
+		want: true,
+	}, {
+		name: "exact match",
+		filters: []*config.Filter{{
+			Exact: map[string]string{"type": "google.cloud.storage.object.v1.finalized"},
+		}},
+		want: true,
+	}, {
+		name: "exact no match",
+		filters: []*config.Filter{{
+			Exact: map[string]string{"type": "google.cloud.storage.object.v1"},
+		}},
+		want: false,
+	}, {
+		name: "prefix match",
+		filters: []*config.Filter{{
+			Prefix: map[string]string{"type": "google.cloud.storage.object.v1."},
+		}},
+		want: true,
+	}, {
+		name: "suffix no match",
+		filters: []*config.Filter{{
+			Suffix: map[string]string{"subject": ".png"},
+		}},
+		want: false,
+	}, {
+		name: "missing attribute",
+		filters: []*config.Filter{{
+			Prefix: map[string]string{"myext": "foo"},
+		}},
+		want: false,
+	}, {
+		name: "all filters must match",
+		filters: []*config.Filter{{
+			Prefix: map[string]string{"type": "google.cloud.storage."},
+		}, {
+			Suffix: map[string]string{"subject": ".png"},
+		}},
+		want: false,
+	}, {
+		name: "any",
+		filters: []*config.Filter{{
+			Any: []*config.Filter{{
+				Suffix: map[string]string{"subject": ".png"},
+			}, {
+				Suffix: map[string]string{"subject": ".jpg"},
+			}},
+		}},
+		want: true,
+	}, {
+		name: "all",
+		filters: []*config.Filter{{
+			All: []*config.Filter{{
+				Prefix: map[string]string{"type": "google.cloud.storage."},
+			}, {
+				Suffix: map[string]string{"subject": ".png"},
+			}},
+		}},
+		want: false,
+	}, {
+		name: "not",
+		filters: []*config.Filter{{
+			Not: &config.Filter{
+				Suffix: map[string]string{"subject": ".tmp"},
+			},
+		}},
+		want: true,
+	}, {
+		name:    "empty filter",
+		filters: []*config.Filter{{}},
+		wantErr: true,
+	}, {
+		name: "empty nested filter",
+		filters: []*config.Filter{{
+			Any: []*config.Filter{{}},
+		}},
+		wantErr: true,
+	}, {
+		name: "multiple dialects",
+		filters: []*config.Filter{{
+			Exact:  map[string]string{"type": "foo"},
+			Prefix: map[string]string{"type": "f"},
+		}},
+		wantErr: true,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := compileFilters(tc.filters)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("compile error got=%v, want error=%v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := m.match(attrs); got != tc.want {
+				t.Errorf("match got=%v, want=%v", got, tc.want)
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/cloudevents/sdk-go/v2/event"
 	"github.com/cloudevents/sdk-go/v2/extensions"
+	cetypes "github.com/cloudevents/sdk-go/v2/types"
 	"github.com/google/knative-gcp/pkg/logging"
 	"go.opencensus.io/trace"
 	"go.uber.org/zap"
@@ -39,6 +40,12 @@ type Processor struct {
 
 	// Targets is the targets from config.
 	Targets config.ReadonlyTargets
+
+	// Cache is the compiled filters of the targets, shared with the other
+	// processors of the pool. The processor keeps its own if it is nil.
+	Cache *Cache
+
+	local Cache
 }
 
 var _ processors.Interface = (*Processor)(nil)
@@ -64,6 +71,14 @@ func (p *Processor) Process(ctx context.Context, event *event.Event) error {
 	ctx, span := startSpan(ctx, trigger, event)
 	defer span.End()
 
+	if len(target.Filters) > 0 {
+		if p.passFilters(ctx, target, event) {
+			return p.Next().Process(ctx, event)
+		}
+		logging.FromContext(ctx).Debug("event does not pass filters for target", zap.Any("target", target))
+		return nil
+	}
+
 	if target.FilterAttributes == nil {
 		return p.Next().Process(ctx, event)
 	}
@@ -133,3 +148,58 @@ func (p *Processor) passFilter(ctx context.Context, attrs map[string]string, eve
 	}
 	return true
 }
+
+// passFilters evaluates the compiled filter expressions of the target.
+func (p *Processor) passFilters(ctx context.Context, target *config.Target, event *event.Event) bool {
+	c := p.compile(target)
+	if c.err != nil {
+		// The filters are validated by the webhook, so this should not happen.
+		// Fail closed rather than delivering events the trigger didn't ask for.
+		logging.FromContext(ctx).Error("failed to compile filters for target", zap.String("target", target.Key()), zap.Error(c.err))
+		trace.FromContext(ctx).Annotatef(nil, "invalid trigger filters: %v", c.err)
+		return false
+	}
+	if !c.matcher.match(eventAttributes(event)) {
+		trace.FromContext(ctx).Annotate(nil, "event does not match filters")
+		return false
+	}
+	return true
+}
+
+// compile returns the compiled filters of the target.
+func (p *Processor) compile(target *config.Target) *compiledFilters {
+	if p.Cache != nil {
+		return p.Cache.get(target)
+	}
+	return p.local.get(target)
+}
+
+// eventAttributes returns the canonical string values of the context
+// attributes and extensions set on the event.
+func eventAttributes(event *event.Event) map[string]string {
+	attrs := map[string]string{
+		"specversion": event.SpecVersion(),
+		"type":        event.Type(),
+		"source":      event.Source(),
+		"id":          event.ID(),
+	}
+	optional := map[string]string{
+		"subject":         event.Subject(),
+		"dataschema":      event.DataSchema(),
+		"datacontenttype": event.DataContentType(),
+	}
+	if !event.Time().IsZero() {
+		optional["time"] = cetypes.FormatTime(event.Time())
+	}
+	for k, v := range optional {
+		if v != "" {
+			attrs[k] = v
+		}
+	}
+	for k, v := range event.Extensions() {
+		if s, err := cetypes.Format(v); err == nil {
+			attrs[k] = s
+		}
+	}
+	return attrs
+}
@@ -311,6 +311,148 @@ func TestFilterProcessor(t *testing.T) {
 	}
 }
 
+func TestFilterProcessorFilters(t *testing.T) {
+	e := event.New()
+	e.SetID("id")
+	e.SetSource("source")
+	e.SetType("google.cloud.storage.object.v1.finalized")
+	e.SetExtension("bucket", "my-bucket")
+
+	cases := []struct {
+		name       string
+		attrs      map[string]string
+		filters    []*config.Filter
+		shouldPass bool
+	}{{
+		name: "prefix pass",
+		filters: []*config.Filter{{
+			Prefix: map[string]string{"type": "google.cloud.storage.object.v1."},
+		}},
+		shouldPass: true,
+	}, {
+		name: "extension not pass",
+		filters: []*config.Filter{{
+			Exact: map[string]string{"bucket": "other-bucket"},
+		}},
+		shouldPass: false,
+	}, {
+		name:  "filters take precedence over attributes",
+		attrs: map[string]string{"type": "other"},
+		filters: []*config.Filter{{
+			Exact: map[string]string{"bucket": "my-bucket"},
+		}},
+		shouldPass: true,
+	}, {
+		name:       "invalid filters not pass",
+		filters:    []*config.Filter{{}},
+		shouldPass: false,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, testTargets := newTestTargets(tc.attrs)
+			testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+				bm.UpsertTargets(&config.Target{
+					Name:             "target",
+					FilterAttributes: tc.attrs,
+					Filters:          tc.filters,
+				})
+			})
+			next := &processors.FakeProcessor{}
+			p := &Processor{Targets: testTargets}
+			p.WithNext(next)
+			ch := make(chan *event.Event, 1)
+			next.PrevEventsCh = ch
+
+			if err := p.Process(ctx, &e); err != nil {
+				t.Errorf("unexpected error from processing: %v", err)
+			}
+			close(ch)
+			if gotEvent := <-ch; (gotEvent != nil) != tc.shouldPass {
+				t.Errorf("event passed filters got=%v, want=%v", gotEvent != nil, tc.shouldPass)
+			}
+		})
+	}
+}
+
+func TestFilterProcessorRecompilesOnConfigChange(t *testing.T) {
+	e := event.New()
+	e.SetID("id")
+	e.SetSource("source")
+	e.SetType("foo.bar")
+
+	ctx, testTargets := newTestTargets(nil)
+	setFilters := func(prefix string) {
+		testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+			bm.UpsertTargets(&config.Target{
+				Name:    "target",
+				Filters: []*config.Filter{{Prefix: map[string]string{"type": prefix}}},
+			})
+		})
+	}
+	next := &processors.FakeProcessor{}
+	p := &Processor{Targets: testTargets}
+	p.WithNext(next)
+	ch := make(chan *event.Event, 2)
+	next.PrevEventsCh = ch
+
+	setFilters("foo.")
+	if err := p.Process(ctx, &e); err != nil {
+		t.Errorf("unexpected error from processing: %v", err)
+	}
+	setFilters("baz.")
+	if err := p.Process(ctx, &e); err != nil {
+		t.Errorf("unexpected error from processing: %v", err)
+	}
+	close(ch)
+	if got := len(ch); got != 1 {
+		t.Errorf("events passed filters got=%d, want=1", got)
+	}
+}
+
+func TestFilterCachePrune(t *testing.T) {
+	e := event.New()
+	e.SetID("id")
+	e.SetSource("source")
+	e.SetType("foo.bar")
+
+	ctx, testTargets := newTestTargets(nil)
+	setFilters := func(name string) {
+		testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+			bm.UpsertTargets(&config.Target{
+				Name:    name,
+				Filters: []*config.Filter{{Prefix: map[string]string{"type": "foo."}}},
+			})
+		})
+	}
+	setFilters("target")
+	setFilters("other")
+	cache := NewCache()
+	for _, name := range []string{"target", "other"} {
+		p := &Processor{Targets: testTargets, Cache: cache}
+		p.WithNext(&processors.FakeProcessor{PrevEventsCh: make(chan *event.Event, 1)})
+		if err := p.Process(handlerctx.WithTargetKey(ctx, config.TriggerKey("ns", "broker", name)), &e); err != nil {
+			t.Errorf("unexpected error from processing: %v", err)
+		}
+	}
+
+	// Replace one target and delete the other.
+	setFilters("target")
+	testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+		bm.DeleteTargets(&config.Target{Name: "other"})
+	})
+	cache.Prune(testTargets)
+
+	var got []interface{}
+	cache.compiled.Range(func(key, _ interface{}) bool {
+		got = append(got, key)
+		return true
+	})
+	if len(got) != 0 {
+		t.Errorf("compiled filters left after prune: %v", got)
+	}
+}
+
 func newTestTargets(filter map[string]string) (context.Context, config.Targets) {
 	testTarget := &config.Target{
 		Name:             "target",
@@ -22,8 +22,9 @@ import (
 	"time"
 
 	"cloud.google.com/go/pubsub"
-	cepubsub "github.com/cloudevents/sdk-go/protocol/pubsub/v2"
 	ceclient "github.com/cloudevents/sdk-go/v2/client"
+	"github.com/google/knative-gcp/pkg/broker/queue"
+	"github.com/google/knative-gcp/pkg/redis"
 	"github.com/google/knative-gcp/pkg/utils/clients"
 	"github.com/google/wire"
 	"go.opencensus.io/plugin/ochttp"
@@ -56,20 +57,35 @@ var (
 		NewFanoutPool,
 		NewRetryPool,
 		clients.NewPubsubClient,
+		NewPubsubInboundFactory,
 		NewRetryClient,
 		wire.Value(DefaultHTTPClient),
 		wire.Value(DefaultCEClientOpts),
 	)
+
+	// RedisProviderSet provides the fanout and retry sync pools pulling from and retrying
+	// through Redis streams, using the default client options. In order to inject either pool,
+	// *redis.Client, PodName, []Option, and config.ReadOnlyTargets must be externally provided.
+	RedisProviderSet = wire.NewSet(
+		NewFanoutPool,
+		NewRetryPool,
+		NewRedisInboundFactory,
+		NewRedisRetryClient,
+		wire.Value(DefaultHTTPClient),
+		wire.Value(DefaultCEClientOpts),
+	)
 )
 
 type RetryClient ceclient.Client
 
 // NewRetryClient provides a retry CE client from a PubSub client and list of CE client options.
+// The events are published with the ordering key of the context, see queue.WithOrderingKey.
 func NewRetryClient(ctx context.Context, client *pubsub.Client, opts ...ceclient.Option) (RetryClient, error) {
-	rps, err := cepubsub.New(ctx, cepubsub.WithClient(client))
-	if err != nil {
-		return nil, err
-	}
+	return ceclient.NewObserved(queue.NewPubsubSender(client), opts...)
+}
 
-	return ceclient.NewObserved(rps, opts...)
+// NewRedisRetryClient provides a retry CE client appending to Redis streams from a Redis client
+// and list of CE client options.
+func NewRedisRetryClient(client *redis.Client, opts ...ceclient.Option) (RetryClient, error) {
+	return ceclient.NewObserved(queue.NewRedisSender(client), opts...)
 }
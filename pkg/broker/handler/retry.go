@@ -18,22 +18,27 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/google/knative-gcp/pkg/logging"
 	"go.uber.org/zap"
 
-	"cloud.google.com/go/pubsub"
-
 	"github.com/google/knative-gcp/pkg/broker/config"
 	handlerctx "github.com/google/knative-gcp/pkg/broker/handler/context"
 	"github.com/google/knative-gcp/pkg/broker/handler/processors"
 	"github.com/google/knative-gcp/pkg/broker/handler/processors/deliver"
 	"github.com/google/knative-gcp/pkg/broker/handler/processors/filter"
+	"github.com/google/knative-gcp/pkg/kncloudevents"
 	"github.com/google/knative-gcp/pkg/metrics"
 )
 
+// attemptTrackerTTL is how long retry handlers remember the delivery attempts
+// of a message. It is well above the maximum Pub/Sub retry backoff.
+const attemptTrackerTTL = 30 * time.Minute
+
 // RetryPool is the sync pool for retry handlers.
 // For each trigger in the config, it will attempt to create a handler.
 // It will also stop/delete the handler if the corresponding trigger is deleted
@@ -42,12 +47,15 @@ type RetryPool struct {
 	options *Options
 	targets config.ReadonlyTargets
 	pool    sync.Map
-	// Pubsub client used to pull events from decoupling topics.
-	pubsubClient *pubsub.Client
+	// Creates the inbounds to pull events from retry queues.
+	inbounds InboundFactory
 	// For initial events delivery. We only need a shared client.
 	// And we can set target address dynamically.
 	deliverClient *http.Client
 	statsReporter *metrics.DeliveryReporter
+	// The compiled filters of the targets, shared by the handlers so that
+	// they are compiled once per config sync.
+	filters *filter.Cache
 }
 
 type retryHandlerCache struct {
@@ -76,7 +84,7 @@ func (hc *retryHandlerCache) shouldRenew(t *config.Target) bool {
 // NewRetryPool creates a new retry handler pool.
 func NewRetryPool(
 	targets config.ReadonlyTargets,
-	pubsubClient *pubsub.Client,
+	inbounds InboundFactory,
 	deliverClient *http.Client,
 	statsReporter *metrics.DeliveryReporter,
 	opts ...Option) (*RetryPool, error) {
@@ -88,9 +96,10 @@ func NewRetryPool(
 	p := &RetryPool{
 		targets:       targets,
 		options:       options,
-		pubsubClient:  pubsubClient,
+		inbounds:      inbounds,
 		deliverClient: deliverClient,
 		statsReporter: statsReporter,
+		filters:       filter.NewCache(),
 	}
 	return p, nil
 }
@@ -110,6 +119,7 @@ func (p *RetryPool) SyncOnce(ctx context.Context) error {
 		}
 		return true
 	})
+	p.filters.Prune(p.targets)
 
 	p.targets.RangeAllTargets(func(t *config.Target) bool {
 		if value, ok := p.pool.Load(t.Key()); ok {
@@ -128,21 +138,26 @@ func (p *RetryPool) SyncOnce(ctx context.Context) error {
 			return true
 		}
 
-		sub := p.pubsubClient.Subscription(t.RetryQueue.Subscription)
-		sub.ReceiveSettings = p.options.PubsubReceiveSettings
-
 		h := NewHandler(
-			sub,
+			p.inbounds.NewInbound(t.RetryQueue, p.options),
 			processors.ChainProcessors(
-				&filter.Processor{Targets: p.targets},
+				&filter.Processor{Targets: p.targets, Cache: p.filters},
 				&deliver.Processor{
 					DeliverClient: p.deliverClient,
 					Targets:       p.targets,
 					StatsReporter: p.statsReporter,
+					Dedup:         p.options.DedupStore,
 				},
 			),
 			p.options.TimeoutPerEvent,
 		)
+		// Count delivery attempts so that events can be sent to addressable
+		// dead letter sinks once their retries are exhausted.
+		h.attempts = newAttemptTracker(attemptTrackerTTL)
+		if p.options.UnconvertibleSinkURI != "" {
+			h.unconvertible = kncloudevents.NewUnconvertibleSender(http.DefaultClient, p.options.UnconvertibleSinkURI,
+				fmt.Sprintf("/apis/v1/namespaces/%s/triggers/%s", t.Namespace, t.Name))
+		}
 		hc := &retryHandlerCache{
 			Handler: *h,
 			t:       t,
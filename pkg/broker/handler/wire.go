@@ -37,6 +37,7 @@ func InitializeTestFanoutPool(
 ) (*FanoutPool, error) {
 	panic(wire.Build(
 		NewFanoutPool,
+		NewPubsubInboundFactory,
 		NewRetryClient,
 		metrics.NewDeliveryReporter,
 		wire.Value(DefaultHTTPClient),
@@ -53,6 +54,7 @@ func InitializeTestRetryPool(
 ) (*RetryPool, error) {
 	panic(wire.Build(
 		NewRetryPool,
+		NewPubsubInboundFactory,
 		metrics.NewDeliveryReporter,
 		wire.Value(DefaultHTTPClient),
 	))
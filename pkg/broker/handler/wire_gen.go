@@ -15,6 +15,7 @@ import (
 // Injectors from wire.go:
 
 func InitializeTestFanoutPool(ctx context.Context, podName metrics.PodName, containerName metrics.ContainerName, targets config.ReadonlyTargets, pubsubClient *pubsub.Client, opts ...Option) (*FanoutPool, error) {
+	inboundFactory := NewPubsubInboundFactory(pubsubClient)
 	client := _wireClientValue
 	v := _wireValue
 	retryClient, err := NewRetryClient(ctx, pubsubClient, v...)
@@ -25,7 +26,7 @@ func InitializeTestFanoutPool(ctx context.Context, podName metrics.PodName, cont
 	if err != nil {
 		return nil, err
 	}
-	fanoutPool, err := NewFanoutPool(targets, pubsubClient, client, retryClient, deliveryReporter, opts...)
+	fanoutPool, err := NewFanoutPool(targets, inboundFactory, client, retryClient, deliveryReporter, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -38,12 +39,13 @@ var (
 )
 
 func InitializeTestRetryPool(targets config.ReadonlyTargets, podName metrics.PodName, containerName metrics.ContainerName, pubsubClient *pubsub.Client, opts ...Option) (*RetryPool, error) {
+	inboundFactory := NewPubsubInboundFactory(pubsubClient)
 	client := _wireHttpClientValue
 	deliveryReporter, err := metrics.NewDeliveryReporter(podName, containerName)
 	if err != nil {
 		return nil, err
 	}
-	retryPool, err := NewRetryPool(targets, pubsubClient, client, deliveryReporter, opts...)
+	retryPool, err := NewRetryPool(targets, inboundFactory, client, deliveryReporter, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,257 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	nethttp "net/http"
+	"sync"
+	"time"
+
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"go.opencensus.io/trace"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+	kntracing "knative.dev/eventing/pkg/tracing"
+
+	"github.com/google/knative-gcp/pkg/broker/eventutil"
+	"github.com/google/knative-gcp/pkg/logging"
+	"github.com/google/knative-gcp/pkg/tracing"
+)
+
+const (
+	// maxBatchSize is the maximum number of events in a batch. Larger batches are rejected with
+	// 413 Request Entity Too Large.
+	maxBatchSize = 100
+	// batchWorkers is the maximum number of events of a batch sent to the decouple sink
+	// concurrently.
+	batchWorkers = 10
+)
+
+var errBatchTooLarge = fmt.Errorf("batch has more than %d events", maxBatchSize)
+
+// BatchResult is the result of an event of a batch.
+type BatchResult struct {
+	// ID is the ID of the event, if it could be read.
+	ID string `json:"id,omitempty"`
+	// Status is the status code the event would have been answered with if sent alone.
+	Status int `json:"status"`
+	// Error is the reason why the event wasn't accepted.
+	Error string `json:"error,omitempty"`
+}
+
+// BatchResponse is the body of the response to a batch of events. The results are in the
+// order of the events in the batch.
+type BatchResponse struct {
+	Results []BatchResult `json:"results"`
+}
+
+// isBatch returns true if the request is in the batched content mode of the CloudEvents HTTP
+// binding.
+func isBatch(request *nethttp.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	return err == nil && mediaType == event.ApplicationCloudEventsBatchJSON
+}
+
+// serveBatch sends the events of a batch to the decouple sink. Each event is validated and sent
+// independently by at most batchWorkers goroutines, so that some events may be accepted while
+// others are not. The events of an ordered broker that share a partition key are sent one after
+// another, in the order of the batch, and the ones following an event that isn't accepted are
+// not sent. The status code of the response is the one shared by all the results, or 207
+// Multi-Status if they differ.
+func (h *Handler) serveBatch(ctx context.Context, response nethttp.ResponseWriter, request *nethttp.Request, broker types.NamespacedName) {
+	batch, err := decodeBatch(request.Body)
+	if errors.Is(err, errBatchTooLarge) {
+		logging.FromContext(ctx).Debug("Batch too large")
+		nethttp.Error(response, err.Error(), nethttp.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		logging.FromContext(ctx).Debug("Malformed batch", zap.Error(err))
+		nethttp.Error(response, "Malformed batch: "+err.Error(), nethttp.StatusBadRequest)
+		return
+	}
+
+	span := trace.FromContext(ctx)
+	span.SetName(kntracing.BrokerMessagingDestination(broker))
+	if span.IsRecordingEvents() {
+		span.AddAttributes(
+			kntracing.MessagingSystemAttribute,
+			tracing.PubSubProtocolAttribute,
+			kntracing.BrokerMessagingDestinationAttribute(broker),
+			trace.Int64Attribute("cloudevents.batch_size", int64(len(batch))),
+		)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, decoupleSinkTimeout)
+	defer cancel()
+	results := make([]BatchResult, len(batch))
+	groups := h.batchGroups(broker, batch)
+	pending := make(chan []int, len(groups))
+	for _, g := range groups {
+		pending <- g
+	}
+	close(pending)
+	workers := batchWorkers
+	if len(groups) < workers {
+		workers = len(groups)
+	}
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for g := range pending {
+				for j, i := range g {
+					results[i] = h.sendBatchEvent(ctx, request.Context(), broker, batch[i])
+					if results[i].Status != nethttp.StatusAccepted {
+						// The following events sharing the partition key would be published
+						// before the one that failed.
+						skipBatchEvents(results, batch, g[j+1:], results[i].ID)
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	statusCode := nethttp.StatusAccepted
+	for i, res := range results {
+		if i == 0 {
+			statusCode = res.Status
+		} else if res.Status != statusCode {
+			statusCode = nethttp.StatusMultiStatus
+			break
+		}
+	}
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(statusCode)
+	if err := json.NewEncoder(response).Encode(BatchResponse{Results: results}); err != nil {
+		logging.FromContext(ctx).Warn("Failed to write the batch response", zap.Error(err))
+	}
+}
+
+// batchGroups returns the indexes of the events of the batch in groups, each sent in order by a
+// single worker. If the broker is ordered, the events sharing a partition key are in the same
+// group so that they are published in order. The other events each have their own group.
+func (h *Handler) batchGroups(broker types.NamespacedName, batch []json.RawMessage) [][]int {
+	ordered := false
+	if h.brokerConfig != nil {
+		if b, ok := h.brokerConfig.GetBroker(broker.Namespace, broker.Name); ok {
+			ordered = b.Ordered
+		}
+	}
+	groups := make([][]int, 0, len(batch))
+	keyGroups := make(map[string]int)
+	for i, raw := range batch {
+		var key string
+		if ordered {
+			key = batchPartitionKey(raw)
+		}
+		if key == "" {
+			groups = append(groups, []int{i})
+			continue
+		}
+		if g, ok := keyGroups[key]; ok {
+			groups[g] = append(groups[g], i)
+			continue
+		}
+		keyGroups[key] = len(groups)
+		groups = append(groups, []int{i})
+	}
+	return groups
+}
+
+// skipBatchEvents sets the results of the events of the batch that are not sent because an
+// event sharing their partition key wasn't accepted.
+func skipBatchEvents(results []BatchResult, batch []json.RawMessage, skipped []int, failedID string) {
+	for _, i := range skipped {
+		var id string
+		if event := cev2.NewEvent(); json.Unmarshal(batch[i], &event) == nil {
+			id = event.ID()
+		}
+		results[i] = BatchResult{
+			ID:     id,
+			Status: nethttp.StatusServiceUnavailable,
+			Error:  fmt.Sprintf("not sent because event %q sharing its partition key wasn't accepted", failedID),
+		}
+	}
+}
+
+// batchPartitionKey returns the partition key of an event of a batch, or "" if it has none or
+// isn't an event. Invalid events are rejected when they are sent.
+func batchPartitionKey(raw json.RawMessage) string {
+	event := cev2.NewEvent()
+	if err := json.Unmarshal(raw, &event); err != nil {
+		return ""
+	}
+	return eventutil.PartitionKey(&event)
+}
+
+// decodeBatch reads the JSON array of events of a batch. It stops reading and returns
+// errBatchTooLarge as soon as the batch has more than maxBatchSize events.
+func decodeBatch(r io.Reader) ([]json.RawMessage, error) {
+	dec := json.NewDecoder(r)
+	if t, err := dec.Token(); err != nil {
+		return nil, err
+	} else if t != json.Delim('[') {
+		return nil, fmt.Errorf("batch must be a JSON array, got %v", t)
+	}
+	batch := []json.RawMessage{}
+	for dec.More() {
+		if len(batch) == maxBatchSize {
+			return nil, errBatchTooLarge
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		batch = append(batch, raw)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// sendBatchEvent validates an event of a batch and sends it to the decouple sink. Metrics are
+// reported with reportCtx, which isn't subject to the decouple sink timeout.
+func (h *Handler) sendBatchEvent(ctx, reportCtx context.Context, broker types.NamespacedName, raw json.RawMessage) BatchResult {
+	event := cev2.NewEvent()
+	if err := json.Unmarshal(raw, &event); err != nil {
+		return BatchResult{Status: nethttp.StatusBadRequest, Error: err.Error()}
+	}
+	if err := event.Validate(); err != nil {
+		return BatchResult{ID: event.ID(), Status: nethttp.StatusBadRequest, Error: err.Error()}
+	}
+	now := time.Now()
+	if event.Time().IsZero() {
+		event.SetTime(now)
+	}
+	event.SetExtension(EventArrivalTime, cev2.Timestamp{Time: now})
+
+	statusCode, errMsg, err := h.send(ctx, broker, &event)
+	h.reportMetrics(reportCtx, broker, &event, statusCode, err)
+	return BatchResult{ID: event.ID(), Status: statusCode, Error: errMsg}
+}
@@ -0,0 +1,421 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	nethttp "net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/logging"
+	logtest "knative.dev/pkg/logging/testing"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/broker/config/memory"
+	"github.com/google/knative-gcp/pkg/broker/eventutil"
+	reportertest "github.com/google/knative-gcp/pkg/metrics/testing"
+)
+
+// fakeDecoupleSink records the events it accepts, and returns the error set for the ID of the
+// event if any.
+type fakeDecoupleSink struct {
+	errs map[string]error
+
+	mu     sync.Mutex
+	events map[string]cev2.Event
+}
+
+func (s *fakeDecoupleSink) Send(ctx context.Context, broker types.NamespacedName, event cev2.Event) protocol.Result {
+	if err, ok := s.errs[event.ID()]; ok {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events[event.ID()] = event
+	return nil
+}
+
+func TestHandlerBatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		errs        map[string]error
+		wantCode    int
+		wantResults []BatchResult
+		wantEvents  []string
+	}{
+		{
+			name:        "all accepted",
+			contentType: "application/cloudevents-batch+json",
+			body: `[{"specversion":"1.0","id":"1","source":"test-source","type":"test-type"},` +
+				`{"specversion":"1.0","id":"2","source":"test-source","type":"test-type","data":{"foo":"bar"}}]`,
+			wantCode: nethttp.StatusAccepted,
+			wantResults: []BatchResult{
+				{ID: "1", Status: nethttp.StatusAccepted},
+				{ID: "2", Status: nethttp.StatusAccepted},
+			},
+			wantEvents: []string{"1", "2"},
+		},
+		{
+			name:        "content type with parameters",
+			contentType: "application/cloudevents-batch+json; charset=utf-8",
+			body:        `[{"specversion":"1.0","id":"1","source":"test-source","type":"test-type"}]`,
+			wantCode:    nethttp.StatusAccepted,
+			wantResults: []BatchResult{{ID: "1", Status: nethttp.StatusAccepted}},
+			wantEvents:  []string{"1"},
+		},
+		{
+			name:        "partial success",
+			contentType: "application/cloudevents-batch+json",
+			body: `[{"specversion":"1.0","id":"1","source":"test-source","type":"test-type"},` +
+				`{"specversion":"1.0","id":"2","source":"test-source"},` +
+				`"not an event",` +
+				`{"specversion":"1.0","id":"3","source":"test-source","type":"test-type"}]`,
+			errs:     map[string]error{"3": ErrNotReady},
+			wantCode: nethttp.StatusMultiStatus,
+			wantResults: []BatchResult{
+				{ID: "1", Status: nethttp.StatusAccepted},
+				{ID: "2", Status: nethttp.StatusBadRequest},
+				{Status: nethttp.StatusBadRequest},
+				{ID: "3", Status: nethttp.StatusServiceUnavailable, Error: "Failed to publish to PubSub"},
+			},
+			wantEvents: []string{"1"},
+		},
+		{
+			name:        "all rejected with the same status",
+			contentType: "application/cloudevents-batch+json",
+			body:        `[{"specversion":"1.0","id":"1","source":"test-source","type":"test-type"}]`,
+			errs:        map[string]error{"1": ErrNotFound},
+			wantCode:    nethttp.StatusNotFound,
+			wantResults: []BatchResult{{ID: "1", Status: nethttp.StatusNotFound, Error: "Failed to publish to PubSub"}},
+		},
+		{
+			name:        "empty batch",
+			contentType: "application/cloudevents-batch+json",
+			body:        `[]`,
+			wantCode:    nethttp.StatusAccepted,
+			wantResults: []BatchResult{},
+		},
+		{
+			name:        "batch at the maximum size",
+			contentType: "application/cloudevents-batch+json",
+			body:        batchOf(maxBatchSize),
+			wantCode:    nethttp.StatusAccepted,
+			wantEvents:  batchIDs(maxBatchSize),
+		},
+		{
+			name:        "batch too large",
+			contentType: "application/cloudevents-batch+json",
+			body:        batchOf(maxBatchSize + 1),
+			wantCode:    nethttp.StatusRequestEntityTooLarge,
+		},
+		{
+			name:        "malformed batch",
+			contentType: "application/cloudevents-batch+json",
+			body:        `{"specversion":"1.0","id":"1","source":"test-source","type":"test-type"}`,
+			wantCode:    nethttp.StatusBadRequest,
+		},
+	}
+
+	client := nethttp.Client{}
+	defer client.CloseIdleConnections()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			reportertest.ResetIngressMetrics()
+			ctx := logging.WithLogger(context.Background(), logtest.TestLogger(t))
+			ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+			defer cancel()
+
+			decouple := &fakeDecoupleSink{errs: tc.errs, events: make(map[string]cev2.Event)}
+			url := createAndStartIngress(ctx, t, nil, decouple, nil)
+
+			request, _ := nethttp.NewRequest(nethttp.MethodPost, url+"/ns1/broker1", bytes.NewBufferString(tc.body))
+			request.Header.Set("Content-Type", tc.contentType)
+			res, err := client.Do(request)
+			if err != nil {
+				t.Fatalf("Unexpected error from http client: %v", err)
+			}
+			defer res.Body.Close()
+			if res.StatusCode != tc.wantCode {
+				t.Errorf("StatusCode mismatch. got: %v, want: %v", res.StatusCode, tc.wantCode)
+			}
+			if tc.wantResults != nil {
+				var got BatchResponse
+				if err := json.NewDecoder(res.Body).Decode(&got); err != nil {
+					t.Fatalf("Failed to decode the response: %v", err)
+				}
+				// Validation errors come from the SDK, only check that there is one.
+				for i := range got.Results {
+					if got.Results[i].Status == nethttp.StatusBadRequest {
+						if got.Results[i].Error == "" {
+							t.Errorf("Result %d has no error", i)
+						}
+						got.Results[i].Error = ""
+					}
+				}
+				if diff := cmp.Diff(tc.wantResults, got.Results); diff != "" {
+					t.Errorf("Unexpected results (-want, +got) = %v", diff)
+				}
+			}
+
+			if len(decouple.events) != len(tc.wantEvents) {
+				t.Errorf("Unexpected events sent to the decouple sink: %v", decouple.events)
+			}
+			for _, id := range tc.wantEvents {
+				e, ok := decouple.events[id]
+				if !ok {
+					t.Errorf("Event %q wasn't sent to the decouple sink", id)
+					continue
+				}
+				if e.Time().IsZero() {
+					t.Errorf("Event %q should be decorated with timestamp, got zero.", id)
+				}
+				if _, ok := e.Extensions()[EventArrivalTime]; !ok {
+					t.Errorf("Event %q misses the %s extension", id, EventArrivalTime)
+				}
+			}
+		})
+	}
+}
+
+// batchOf returns a batch of n valid events, with the IDs returned by batchIDs.
+func batchOf(n int) string {
+	events := make([]string, n)
+	for i, id := range batchIDs(n) {
+		events[i] = fmt.Sprintf(`{"specversion":"1.0","id":%q,"source":"test-source","type":"test-type"}`, id)
+	}
+	return "[" + strings.Join(events, ",") + "]"
+}
+
+func batchIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i)
+	}
+	return ids
+}
+
+// concurrencySink blocks every event until release is closed, and records the maximum number of
+// events it was sent concurrently.
+type concurrencySink struct {
+	release chan struct{}
+
+	mu       sync.Mutex
+	inFlight int
+	max      int
+}
+
+func (s *concurrencySink) Send(ctx context.Context, broker types.NamespacedName, event cev2.Event) protocol.Result {
+	s.mu.Lock()
+	s.inFlight++
+	if s.inFlight > s.max {
+		s.max = s.inFlight
+	}
+	s.mu.Unlock()
+	<-s.release
+	s.mu.Lock()
+	s.inFlight--
+	s.mu.Unlock()
+	return nil
+}
+
+func TestHandlerBatchBoundedConcurrency(t *testing.T) {
+	reportertest.ResetIngressMetrics()
+	ctx := logging.WithLogger(context.Background(), logtest.TestLogger(t))
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	decouple := &concurrencySink{release: make(chan struct{})}
+	url := createAndStartIngress(ctx, t, nil, decouple, nil)
+
+	go func() {
+		// Let the workers pile up on the sink before releasing them.
+		time.Sleep(200 * time.Millisecond)
+		close(decouple.release)
+	}()
+	client := nethttp.Client{}
+	defer client.CloseIdleConnections()
+	request, _ := nethttp.NewRequest(nethttp.MethodPost, url+"/ns1/broker1", bytes.NewBufferString(batchOf(maxBatchSize)))
+	request.Header.Set("Content-Type", "application/cloudevents-batch+json")
+	res, err := client.Do(request)
+	if err != nil {
+		t.Fatalf("Unexpected error from http client: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != nethttp.StatusAccepted {
+		t.Errorf("StatusCode mismatch. got: %v, want: %v", res.StatusCode, nethttp.StatusAccepted)
+	}
+	decouple.mu.Lock()
+	defer decouple.mu.Unlock()
+	if decouple.max != batchWorkers {
+		t.Errorf("Got %d events sent concurrently, want %d", decouple.max, batchWorkers)
+	}
+}
+
+// orderRecordingSink records the IDs of the events it is sent, in the order of their partition
+// keys. It holds each event for a while so that the events sent concurrently interleave. The
+// event whose ID is fail isn't accepted.
+type orderRecordingSink struct {
+	mu   sync.Mutex
+	sent map[string][]string
+	fail string
+}
+
+func (s *orderRecordingSink) Send(ctx context.Context, broker types.NamespacedName, event cev2.Event) protocol.Result {
+	time.Sleep(time.Duration(rand.Intn(10)) * time.Millisecond)
+	if event.ID() == s.fail {
+		return errors.New("inject error")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := eventutil.PartitionKey(&event)
+	s.sent[key] = append(s.sent[key], event.ID())
+	return nil
+}
+
+func TestHandlerBatchOrdered(t *testing.T) {
+	reportertest.ResetIngressMetrics()
+	ctx := logging.WithLogger(context.Background(), logtest.TestLogger(t))
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	targets := memory.NewTargets(&config.TargetsConfig{
+		Brokers: map[string]*config.Broker{
+			"ns1/broker1": {
+				Name:      "broker1",
+				Namespace: "ns1",
+				Ordered:   true,
+			},
+		},
+	})
+	decouple := &orderRecordingSink{sent: make(map[string][]string)}
+	url := createAndStartIngress(ctx, t, nil, decouple, targets)
+
+	// Interleave the events of two keys with events without a key.
+	const n = 30
+	events := make([]string, n)
+	want := map[string][]string{}
+	for i := range events {
+		id := strconv.Itoa(i)
+		switch i % 3 {
+		case 0:
+			events[i] = fmt.Sprintf(`{"specversion":"1.0","id":%q,"source":"test-source","type":"test-type"}`, id)
+		default:
+			key := fmt.Sprintf("key%d", i%3)
+			events[i] = fmt.Sprintf(`{"specversion":"1.0","id":%q,"source":"test-source","type":"test-type","partitionkey":%q}`, id, key)
+			want[key] = append(want[key], id)
+		}
+	}
+	client := nethttp.Client{}
+	defer client.CloseIdleConnections()
+	request, _ := nethttp.NewRequest(nethttp.MethodPost, url+"/ns1/broker1", bytes.NewBufferString("["+strings.Join(events, ",")+"]"))
+	request.Header.Set("Content-Type", "application/cloudevents-batch+json")
+	res, err := client.Do(request)
+	if err != nil {
+		t.Fatalf("Unexpected error from http client: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != nethttp.StatusAccepted {
+		t.Errorf("StatusCode mismatch. got: %v, want: %v", res.StatusCode, nethttp.StatusAccepted)
+	}
+
+	decouple.mu.Lock()
+	defer decouple.mu.Unlock()
+	for key, ids := range want {
+		if diff := cmp.Diff(ids, decouple.sent[key]); diff != "" {
+			t.Errorf("Unexpected order of the events of %s (-want, +got) = %v", key, diff)
+		}
+	}
+	if got := len(decouple.sent[""]); got != n/3 {
+		t.Errorf("Got %d events without a partition key, want %d", got, n/3)
+	}
+}
+
+func TestHandlerBatchOrderedFailure(t *testing.T) {
+	reportertest.ResetIngressMetrics()
+	ctx := logging.WithLogger(context.Background(), logtest.TestLogger(t))
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	targets := memory.NewTargets(&config.TargetsConfig{
+		Brokers: map[string]*config.Broker{
+			"ns1/broker1": {
+				Name:      "broker1",
+				Namespace: "ns1",
+				Ordered:   true,
+			},
+		},
+	})
+	decouple := &orderRecordingSink{sent: make(map[string][]string), fail: "1"}
+	url := createAndStartIngress(ctx, t, nil, decouple, targets)
+
+	body := `[
+		{"specversion":"1.0","id":"0","source":"test-source","type":"test-type","partitionkey":"key1"},
+		{"specversion":"1.0","id":"1","source":"test-source","type":"test-type","partitionkey":"key1"},
+		{"specversion":"1.0","id":"2","source":"test-source","type":"test-type","partitionkey":"key1"},
+		{"specversion":"1.0","id":"3","source":"test-source","type":"test-type","partitionkey":"key2"},
+		{"specversion":"1.0","id":"4","source":"test-source","type":"test-type","partitionkey":"key1"}
+	]`
+	client := nethttp.Client{}
+	defer client.CloseIdleConnections()
+	request, _ := nethttp.NewRequest(nethttp.MethodPost, url+"/ns1/broker1", bytes.NewBufferString(body))
+	request.Header.Set("Content-Type", "application/cloudevents-batch+json")
+	res, err := client.Do(request)
+	if err != nil {
+		t.Fatalf("Unexpected error from http client: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != nethttp.StatusMultiStatus {
+		t.Errorf("StatusCode mismatch. got: %v, want: %v", res.StatusCode, nethttp.StatusMultiStatus)
+	}
+	var got BatchResponse
+	if err := json.NewDecoder(res.Body).Decode(&got); err != nil {
+		t.Fatalf("Failed to decode the response: %v", err)
+	}
+	skipped := `not sent because event "1" sharing its partition key wasn't accepted`
+	want := []BatchResult{
+		{ID: "0", Status: nethttp.StatusAccepted},
+		{ID: "1", Status: nethttp.StatusInternalServerError, Error: "Failed to publish to PubSub"},
+		{ID: "2", Status: nethttp.StatusServiceUnavailable, Error: skipped},
+		{ID: "3", Status: nethttp.StatusAccepted},
+		{ID: "4", Status: nethttp.StatusServiceUnavailable, Error: skipped},
+	}
+	if diff := cmp.Diff(want, got.Results); diff != "" {
+		t.Errorf("Unexpected results (-want, +got) = %v", diff)
+	}
+
+	decouple.mu.Lock()
+	defer decouple.mu.Unlock()
+	wantSent := map[string][]string{"key1": {"0"}, "key2": {"3"}}
+	if diff := cmp.Diff(wantSent, decouple.sent); diff != "" {
+		t.Errorf("Unexpected events sent (-want, +got) = %v", diff)
+	}
+}
@@ -28,3 +28,11 @@ var ErrIncomplete = errors.New("incomplete config")
 
 // ErrNotReady is the error when a broker is not ready.
 var ErrNotReady = errors.New("not ready")
+
+// ErrPaused is the error when an event of an ordered broker shares its partition key with an
+// event that failed to be published, and that must be retried first.
+var ErrPaused = errors.New("publishing paused")
+
+// ErrRateLimited is the error when an event exceeds the ingress rate limit of its broker or of
+// the namespace of its broker.
+var ErrRateLimited = errors.New("rate limit exceeded")
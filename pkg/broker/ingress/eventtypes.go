@@ -0,0 +1,94 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// maxObservedEventTypes is the maximum number of event types recorded for a
+// broker. The types received once it is reached are not recorded.
+const maxObservedEventTypes = 1000
+
+// ObservedEventType is a type of the events accepted for a broker.
+type ObservedEventType struct {
+	// Type is the CloudEvent type of the events.
+	Type string `json:"type"`
+	// Count is the number of events of the type accepted since the ingress started.
+	Count uint64 `json:"count"`
+	// LastSeen is when the last event of the type was accepted.
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// ObservedEventTypes is the response to a GET request on /eventtypes/<ns>/<broker>.
+type ObservedEventTypes struct {
+	EventTypes []ObservedEventType `json:"eventTypes"`
+}
+
+// eventTypeRecorder records the types of the events accepted for each broker.
+type eventTypeRecorder struct {
+	mu      sync.Mutex
+	max     int
+	brokers map[types.NamespacedName]map[string]*ObservedEventType
+}
+
+func newEventTypeRecorder(max int) *eventTypeRecorder {
+	return &eventTypeRecorder{
+		max:     max,
+		brokers: make(map[types.NamespacedName]map[string]*ObservedEventType),
+	}
+}
+
+// record records that an event of the given type was accepted for the broker.
+func (r *eventTypeRecorder) record(broker types.NamespacedName, eventType string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	observed, ok := r.brokers[broker]
+	if !ok {
+		observed = make(map[string]*ObservedEventType)
+		r.brokers[broker] = observed
+	}
+	et, ok := observed[eventType]
+	if !ok {
+		if len(observed) >= r.max {
+			return
+		}
+		et = &ObservedEventType{Type: eventType}
+		observed[eventType] = et
+	}
+	et.Count++
+	et.LastSeen = time.Now()
+}
+
+// list returns the event types recorded for the broker, sorted by type.
+func (r *eventTypeRecorder) list(broker types.NamespacedName) []ObservedEventType {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	observed := r.brokers[broker]
+	ets := make([]ObservedEventType, 0, len(observed))
+	for _, et := range observed {
+		ets = append(ets, *et)
+	}
+	sort.Slice(ets, func(i, j int) bool {
+		return ets[i].Type < ets[j].Type
+	})
+	return ets
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/logging"
+	logtest "knative.dev/pkg/logging/testing"
+
+	"github.com/google/knative-gcp/pkg/metrics"
+	reportertest "github.com/google/knative-gcp/pkg/metrics/testing"
+)
+
+func TestEventTypeRecorder(t *testing.T) {
+	broker := types.NamespacedName{Namespace: "ns", Name: "broker"}
+	other := types.NamespacedName{Namespace: "ns", Name: "other"}
+	r := newEventTypeRecorder(2)
+	r.record(broker, "type.b")
+	r.record(broker, "type.a")
+	r.record(broker, "type.b")
+	// The recorder is full.
+	r.record(broker, "type.c")
+	r.record(other, "type.c")
+
+	ignoreLastSeen := cmpopts.IgnoreFields(ObservedEventType{}, "LastSeen")
+	want := []ObservedEventType{{Type: "type.a", Count: 1}, {Type: "type.b", Count: 2}}
+	if diff := cmp.Diff(want, r.list(broker), ignoreLastSeen); diff != "" {
+		t.Errorf("Unexpected event types (-want +got): %s", diff)
+	}
+	want = []ObservedEventType{{Type: "type.c", Count: 1}}
+	if diff := cmp.Diff(want, r.list(other), ignoreLastSeen); diff != "" {
+		t.Errorf("Unexpected event types (-want +got): %s", diff)
+	}
+	if got := r.list(types.NamespacedName{Namespace: "ns", Name: "none"}); len(got) != 0 {
+		t.Errorf("Unexpected event types of a broker without events: %v", got)
+	}
+}
+
+func TestHandlerEventTypes(t *testing.T) {
+	reportertest.ResetIngressMetrics()
+	ctx := logging.WithLogger(context.Background(), logtest.TestLogger(t))
+	decouple := &fakeDecoupleSink{
+		errs:   map[string]error{"rejected": errors.New("induced error")},
+		events: make(map[string]cev2.Event),
+	}
+	reporter, err := metrics.NewIngressReporter(metrics.PodName(pod), metrics.ContainerName(container))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewHandler(ctx, nil, decouple, nil, nil, reporter)
+
+	for _, id := range []string{"accepted", "rejected"} {
+		req := httptest.NewRequest(nethttp.MethodPost, "/ns1/broker1", nil)
+		if err := http.WriteRequest(ctx, binding.ToMessage(createTestEvent(id)), req); err != nil {
+			t.Fatal(err)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(nethttp.MethodGet, "/eventtypes/ns1/broker1", nil))
+	if got := w.Result().StatusCode; got != nethttp.StatusOK {
+		t.Fatalf("Got status code %d, want %d", got, nethttp.StatusOK)
+	}
+	var got ObservedEventTypes
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("Failed to decode the event types: %v", err)
+	}
+	// Only the accepted event is recorded.
+	want := ObservedEventTypes{EventTypes: []ObservedEventType{{Type: eventType, Count: 1}}}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(ObservedEventType{}, "LastSeen")); diff != "" {
+		t.Errorf("Unexpected event types (-want +got): %s", diff)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(nethttp.MethodGet, "/eventtypes/ns1/broker1/else", nil))
+	if got := w.Result().StatusCode; got != nethttp.StatusNotFound {
+		t.Errorf("Got status code %d for a malformed path, want %d", got, nethttp.StatusNotFound)
+	}
+
+	// The event types are only served on their own path.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(nethttp.MethodGet, "/ns1/broker1", nil))
+	if got := w.Result().StatusCode; got != nethttp.StatusMethodNotAllowed {
+		t.Errorf("Got status code %d for a GET on the broker path, want %d", got, nethttp.StatusMethodNotAllowed)
+	}
+}
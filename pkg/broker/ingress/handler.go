@@ -18,8 +18,10 @@ package ingress
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	nethttp "net/http"
+	"strings"
 	"time"
 
 	cev2 "github.com/cloudevents/sdk-go/v2"
@@ -28,6 +30,8 @@ import (
 	ceclient "github.com/cloudevents/sdk-go/v2/client"
 	"github.com/cloudevents/sdk-go/v2/protocol"
 	"github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/broker/queue"
 	"github.com/google/knative-gcp/pkg/logging"
 	"github.com/google/knative-gcp/pkg/metrics"
 	"github.com/google/knative-gcp/pkg/tracing"
@@ -56,6 +60,10 @@ const (
 	// For probes.
 	heathCheckPath = "/healthz"
 
+	// eventTypesPathPrefix prefixes the path of a broker in the GET requests for the types of
+	// its events, e.g. /eventtypes/<ns>/<broker>. The other GET requests are not allowed.
+	eventTypesPathPrefix = "/eventtypes"
+
 	// for permission denied error msg
 	// TODO(cathyzhyi) point to official doc rather than github doc
 	deniedErrMsg string = `Failed to publish to PubSub because permission denied.
@@ -70,6 +78,20 @@ var HandlerSet wire.ProviderSet = wire.NewSet(
 	NewMultiTopicDecoupleSink,
 	wire.Bind(new(DecoupleSink), new(*multiTopicDecoupleSink)),
 	clients.NewPubsubClient,
+	NewRateLimiter,
+	metrics.NewIngressReporter,
+)
+
+// RedisHandlerSet provides a handler with a real HTTPMessageReceiver and a
+// Redis Streams DecoupleSink.
+var RedisHandlerSet wire.ProviderSet = wire.NewSet(
+	NewHandler,
+	clients.NewHTTPMessageReceiver,
+	wire.Bind(new(HttpMessageReceiver), new(*kncloudevents.HttpMessageReceiver)),
+	NewRedisDecoupleSink,
+	wire.Bind(new(DecoupleSink), new(*redisDecoupleSink)),
+	queue.NewRedisSender,
+	NewRateLimiter,
 	metrics.NewIngressReporter,
 )
 
@@ -90,17 +112,26 @@ type Handler struct {
 	httpReceiver HttpMessageReceiver
 	// decouple is the client to send events to a decouple sink.
 	decouple DecoupleSink
+	// brokerConfig holds configurations for all brokers. Nil if no broker is ordered.
+	brokerConfig config.ReadonlyTargets
+	// limiter enforces the rate limits of the brokers. Nil if events aren't rate limited.
+	limiter  *RateLimiter
 	logger   *zap.Logger
 	reporter *metrics.IngressReporter
+	// eventTypes records the types of the events accepted for each broker.
+	eventTypes *eventTypeRecorder
 }
 
 // NewHandler creates a new ingress handler.
-func NewHandler(ctx context.Context, httpReceiver HttpMessageReceiver, decouple DecoupleSink, reporter *metrics.IngressReporter) *Handler {
+func NewHandler(ctx context.Context, httpReceiver HttpMessageReceiver, decouple DecoupleSink, brokerConfig config.ReadonlyTargets, limiter *RateLimiter, reporter *metrics.IngressReporter) *Handler {
 	return &Handler{
 		httpReceiver: httpReceiver,
 		decouple:     decouple,
+		brokerConfig: brokerConfig,
+		limiter:      limiter,
 		reporter:     reporter,
 		logger:       logging.FromContext(ctx),
+		eventTypes:   newEventTypeRecorder(maxObservedEventTypes),
 	}
 }
 
@@ -114,6 +145,7 @@ func (h *Handler) Start(ctx context.Context) error {
 // 2. Parse request URL to get namespace and broker.
 // 3. Convert request to event.
 // 4. Send event to decouple sink.
+// GET requests on /eventtypes/<ns>/<broker> return the types of the events accepted for the broker.
 func (h *Handler) ServeHTTP(response nethttp.ResponseWriter, request *nethttp.Request) {
 	if request.URL.Path == heathCheckPath {
 		response.WriteHeader(nethttp.StatusOK)
@@ -124,6 +156,10 @@ func (h *Handler) ServeHTTP(response nethttp.ResponseWriter, request *nethttp.Re
 	ctx = logging.WithLogger(ctx, h.logger)
 	ctx = tracing.WithLogging(ctx, trace.FromContext(ctx))
 	logging.FromContext(ctx).Debug("Serving http", zap.Any("headers", request.Header))
+	if request.Method == nethttp.MethodGet && strings.HasPrefix(request.URL.Path, eventTypesPathPrefix+"/") {
+		h.serveEventTypes(ctx, response, strings.TrimPrefix(request.URL.Path, eventTypesPathPrefix))
+		return
+	}
 	if request.Method != nethttp.MethodPost {
 		response.WriteHeader(nethttp.StatusMethodNotAllowed)
 		return
@@ -137,6 +173,11 @@ func (h *Handler) ServeHTTP(response nethttp.ResponseWriter, request *nethttp.Re
 		return
 	}
 
+	if isBatch(request) {
+		h.serveBatch(ctx, response, request, broker)
+		return
+	}
+
 	event, err := h.toEvent(ctx, request)
 	if err != nil {
 		nethttp.Error(response, err.Error(), nethttp.StatusBadRequest)
@@ -159,35 +200,65 @@ func (h *Handler) ServeHTTP(response nethttp.ResponseWriter, request *nethttp.Re
 		)
 	}
 
-	// Optimistically set status code to StatusAccepted. It will be updated if there is an error.
-	// According to the data plane spec (https://github.com/knative/eventing/blob/master/docs/spec/data-plane.md), a
-	// non-callable SINK (which broker is) MUST respond with 202 Accepted if the request is accepted.
-	statusCode := nethttp.StatusAccepted
 	ctx, cancel := context.WithTimeout(ctx, decoupleSinkTimeout)
 	defer cancel()
-	defer func() { h.reportMetrics(request.Context(), broker, event, statusCode) }()
-	if res := h.decouple.Send(ctx, broker, *event); !cev2.IsACK(res) {
-		logging.FromContext(ctx).Error("Error publishing to PubSub", zap.Error(res))
-		statusCode = nethttp.StatusInternalServerError
-
-		switch {
-		case errors.Is(res, ErrNotFound):
-			statusCode = nethttp.StatusNotFound
-		case errors.Is(res, ErrNotReady):
-			statusCode = nethttp.StatusServiceUnavailable
-		case errors.Is(res, bundler.ErrOverflow):
-			statusCode = nethttp.StatusTooManyRequests
-		case grpcstatus.Code(res) == grpccode.PermissionDenied:
-			nethttp.Error(response, deniedErrMsg, statusCode)
-			return
-		}
-		nethttp.Error(response, "Failed to publish to PubSub", statusCode)
+	statusCode, errMsg, err := h.send(ctx, broker, event)
+	h.reportMetrics(request.Context(), broker, event, statusCode, err)
+	if errMsg != "" {
+		nethttp.Error(response, errMsg, statusCode)
 		return
 	}
 
 	response.WriteHeader(statusCode)
 }
 
+// send sends the event to the decouple sink, unless it exceeds a rate limit, and returns the
+// status code and, if the event wasn't accepted, the error message of the response and the error.
+func (h *Handler) send(ctx context.Context, broker types.NamespacedName, event *cev2.Event) (int, string, error) {
+	if h.limiter != nil {
+		if err := h.limiter.Allow(broker); err != nil {
+			logging.FromContext(ctx).Debug("Event rate limited", zap.Error(err))
+			return nethttp.StatusTooManyRequests, err.Error(), err
+		}
+	}
+
+	res := h.decouple.Send(ctx, broker, *event)
+	if cev2.IsACK(res) {
+		h.eventTypes.record(broker, event.Type())
+		// According to the data plane spec (https://github.com/knative/eventing/blob/master/docs/spec/data-plane.md), a
+		// non-callable SINK (which broker is) MUST respond with 202 Accepted if the request is accepted.
+		return nethttp.StatusAccepted, "", nil
+	}
+
+	logging.FromContext(ctx).Error("Error publishing to PubSub", zap.Error(res))
+	switch {
+	case errors.Is(res, ErrNotFound):
+		return nethttp.StatusNotFound, "Failed to publish to PubSub", res
+	case errors.Is(res, ErrNotReady), errors.Is(res, ErrPaused):
+		return nethttp.StatusServiceUnavailable, "Failed to publish to PubSub", res
+	case errors.Is(res, bundler.ErrOverflow):
+		return nethttp.StatusTooManyRequests, "Failed to publish to PubSub", res
+	case grpcstatus.Code(res) == grpccode.PermissionDenied:
+		return nethttp.StatusInternalServerError, deniedErrMsg, res
+	}
+	return nethttp.StatusInternalServerError, "Failed to publish to PubSub", res
+}
+
+// serveEventTypes responds with the types of the events accepted for the broker of the given
+// path since the ingress started.
+func (h *Handler) serveEventTypes(ctx context.Context, response nethttp.ResponseWriter, brokerPath string) {
+	broker, err := ConvertPathToNamespacedName(brokerPath)
+	if err != nil {
+		logging.FromContext(ctx).Debug("Malformed event types path", zap.String("path", brokerPath))
+		nethttp.Error(response, err.Error(), nethttp.StatusNotFound)
+		return
+	}
+	response.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(response).Encode(ObservedEventTypes{EventTypes: h.eventTypes.list(broker)}); err != nil {
+		logging.FromContext(ctx).Error("Failed to write event types", zap.Stringer("broker", broker), zap.Error(err))
+	}
+}
+
 // toEvent converts an http request to an event.
 func (h *Handler) toEvent(ctx context.Context, request *nethttp.Request) (*cev2.Event, error) {
 	message := http.NewMessageFromHttpRequest(request)
@@ -209,13 +280,18 @@ func (h *Handler) toEvent(ctx context.Context, request *nethttp.Request) (*cev2.
 	return event, nil
 }
 
-func (h *Handler) reportMetrics(ctx context.Context, broker types.NamespacedName, event *cev2.Event, statusCode int) {
+// reportMetrics reports the response to the event. err is the error sending the event, if any.
+func (h *Handler) reportMetrics(ctx context.Context, broker types.NamespacedName, event *cev2.Event, statusCode int, err error) {
 	args := metrics.IngressReportArgs{
 		Namespace:    broker.Namespace,
 		Broker:       broker.Name,
 		EventType:    event.Type(),
 		ResponseCode: statusCode,
 	}
+	var limitErr *RateLimitError
+	if errors.As(err, &limitErr) {
+		args.ResponseCodeReason = limitErr.Reason()
+	}
 	if err := h.reporter.ReportEventCount(ctx, args); err != nil {
 		logging.FromContext(ctx).Warn("Failed to record metrics.", zap.Any("broker", broker.Name), zap.Error(err))
 	}
@@ -303,7 +303,7 @@ func TestHandler(t *testing.T) {
 				decouple = NewMultiTopicDecoupleSink(ctx, memory.NewTargets(brokerConfig), createPubsubClient(ctx, t, psSrv), pubsub.DefaultPublishSettings)
 			}
 
-			url := createAndStartIngress(ctx, t, psSrv, decouple)
+			url := createAndStartIngress(ctx, t, psSrv, decouple, nil)
 			rec := setupTestReceiver(ctx, t, psSrv)
 
 			res, err := client.Do(createRequest(tc, url))
@@ -372,7 +372,7 @@ func runIngressHandlerBenchmark(b *testing.B, eventSize int) {
 	if err != nil {
 		b.Fatal(err)
 	}
-	h := NewHandler(ctx, nil, decouple, statsReporter)
+	h := NewHandler(ctx, nil, decouple, nil, nil, statsReporter)
 
 	if _, err := psClient.CreateTopic(ctx, topicID); err != nil {
 		b.Fatal(err)
@@ -437,13 +437,13 @@ func setupTestReceiver(ctx context.Context, t testing.TB, psSrv *pstest.Server)
 }
 
 // createAndStartIngress creates an ingress and calls its Start() method in a goroutine.
-func createAndStartIngress(ctx context.Context, t testing.TB, psSrv *pstest.Server, decouple DecoupleSink) string {
+func createAndStartIngress(ctx context.Context, t testing.TB, psSrv *pstest.Server, decouple DecoupleSink, brokerConfig config.ReadonlyTargets) string {
 	receiver := &testHttpMessageReceiver{urlCh: make(chan string)}
 	statsReporter, err := metrics.NewIngressReporter(metrics.PodName(pod), metrics.ContainerName(container))
 	if err != nil {
 		t.Fatal(err)
 	}
-	h := NewHandler(ctx, receiver, decouple, statsReporter)
+	h := NewHandler(ctx, receiver, decouple, brokerConfig, nil, statsReporter)
 
 	errCh := make(chan error, 1)
 	go func() {
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"cloud.google.com/go/pubsub"
 	"go.opencensus.io/trace"
@@ -32,10 +33,21 @@ import (
 	"github.com/cloudevents/sdk-go/v2/extensions"
 	"github.com/cloudevents/sdk-go/v2/protocol"
 	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/broker/eventutil"
 	"github.com/google/knative-gcp/pkg/logging"
 )
 
-const projectEnvKey = "PROJECT_ID"
+const (
+	projectEnvKey = "PROJECT_ID"
+
+	// topicsPruneInterval is how often the topics of the decouple queues removed from the broker
+	// config are stopped.
+	topicsPruneInterval = time.Minute
+
+	// pausedTimeout is how long an ordering key stays paused when the event that failed isn't
+	// retried, e.g. because it was retried through another ingress pod.
+	pausedTimeout = time.Minute
+)
 
 // NewMultiTopicDecoupleSink creates a new multiTopicDecoupleSink.
 func NewMultiTopicDecoupleSink(
@@ -44,13 +56,39 @@ func NewMultiTopicDecoupleSink(
 	client *pubsub.Client,
 	publishSettings pubsub.PublishSettings) *multiTopicDecoupleSink {
 
-	return &multiTopicDecoupleSink{
+	m := &multiTopicDecoupleSink{
 		pubsub:          client,
 		publishSettings: publishSettings,
 		brokerConfig:    brokerConfig,
 		// TODO(#1118): remove Topic when broker config is removed
-		topics: make(map[types.NamespacedName]*pubsub.Topic),
+		topics: make(map[decoupleKey]*pubsub.Topic),
+		paused: make(map[pausedKey]pausedEvent),
 	}
+	go m.pruneTopicsPeriodically(ctx)
+	return m
+}
+
+// decoupleKey identifies the decouple queue of a broker, or of one of its priority classes.
+type decoupleKey struct {
+	broker types.NamespacedName
+	// class is the name of the priority class, or "" for the queue of the broker.
+	class string
+}
+
+// pausedKey is an ordering key of a decouple queue.
+type pausedKey struct {
+	queue       decoupleKey
+	orderingKey string
+}
+
+// pausedEvent is the first event of an ordering key whose publishing failed.
+type pausedEvent struct {
+	// seq is the position of the event in the order it was published in.
+	seq    uint64
+	source string
+	id     string
+	// failed is when publishing the event failed.
+	failed time.Time
 }
 
 // multiTopicDecoupleSink implements DecoupleSink and routes events to pubsub topics corresponding
@@ -59,17 +97,25 @@ type multiTopicDecoupleSink struct {
 	// pubsub talks to pubsub.
 	pubsub          *pubsub.Client
 	publishSettings pubsub.PublishSettings
-	// map from brokers to topics
-	topics    map[types.NamespacedName]*pubsub.Topic
+	// map from brokers and their priority classes to topics
+	topics    map[decoupleKey]*pubsub.Topic
 	topicsMut sync.RWMutex
 	// brokerConfig holds configurations for all brokers. It's a view of a configmap populated by
 	// the broker controller.
 	brokerConfig config.ReadonlyTargets
+	// paused holds the ordering keys whose publishing is paused after an error, and the event
+	// that failed. Publishing resumes when that event is sent again, so that the events sharing
+	// its key are not published before it.
+	paused map[pausedKey]pausedEvent
+	// seq numbers the events published with an ordering key.
+	seq       uint64
+	pausedMut sync.Mutex
 }
 
-// Send sends incoming event to its corresponding pubsub topic based on which broker it belongs to.
+// Send sends incoming event to its corresponding pubsub topic based on which broker it belongs to,
+// and which priority class of the broker it is in.
 func (m *multiTopicDecoupleSink) Send(ctx context.Context, broker types.NamespacedName, event cev2.Event) protocol.Result {
-	topic, err := m.getTopicForBroker(ctx, broker)
+	class, topicID, err := decoupleTopic(ctx, m.brokerConfig, broker, &event)
 	if err != nil {
 		trace.FromContext(ctx).Annotate(
 			[]trace.Attribute{
@@ -79,80 +125,207 @@ func (m *multiTopicDecoupleSink) Send(ctx context.Context, broker types.Namespac
 		)
 		return err
 	}
+	key := decoupleKey{broker: broker, class: class}
+	topic := m.getTopic(key, topicID, isOrdered(m.brokerConfig, broker))
 
 	dt := extensions.FromSpanContext(trace.FromContext(ctx).SpanContext())
 	msg := new(pubsub.Message)
 	if err := cepubsub.WritePubSubMessage(ctx, binding.ToMessage(&event), msg, dt.WriteTransformer()); err != nil {
 		return err
 	}
+	msg.OrderingKey = orderingKey(m.brokerConfig, broker, &event)
+	if msg.OrderingKey != "" {
+		return m.publishOrdered(ctx, topic, pausedKey{queue: key, orderingKey: msg.OrderingKey}, msg, &event)
+	}
 
 	_, err = topic.Publish(ctx, msg).Get(ctx)
 	return err
 }
 
-// getTopicForBroker finds the corresponding decouple topic for the broker from the mounted broker configmap volume.
-func (m *multiTopicDecoupleSink) getTopicForBroker(ctx context.Context, broker types.NamespacedName) (*pubsub.Topic, error) {
-	topicID, err := m.getTopicIDForBroker(ctx, broker)
-	if err != nil {
-		return nil, err
+// publishOrdered publishes a message with an ordering key. Publishing is paused for the key after
+// an error, so that the events aren't published out of order, until the sender retries the first
+// event that failed, or for at most pausedTimeout. The other events of the key are rejected with
+// ErrPaused meanwhile.
+func (m *multiTopicDecoupleSink) publishOrdered(ctx context.Context, topic *pubsub.Topic, key pausedKey, msg *pubsub.Message, event *cev2.Event) error {
+	m.pausedMut.Lock()
+	if p, ok := m.paused[key]; ok {
+		if (p.source != event.Source() || p.id != event.ID()) && time.Since(p.failed) < pausedTimeout {
+			m.pausedMut.Unlock()
+			return fmt.Errorf("ordering key %q until event %q is retried: %w", key.orderingKey, p.id, ErrPaused)
+		}
+		delete(m.paused, key)
+		topic.ResumePublish(key.orderingKey)
+	}
+	// The messages are published under the lock so that they are numbered in the order they are
+	// published in.
+	m.seq++
+	seq := m.seq
+	res := topic.Publish(ctx, msg)
+	m.pausedMut.Unlock()
+
+	if _, err := res.Get(ctx); err != nil {
+		m.pausedMut.Lock()
+		defer m.pausedMut.Unlock()
+		// The events published after the one that failed fail as well.
+		if p, ok := m.paused[key]; !ok || seq < p.seq {
+			m.paused[key] = pausedEvent{seq: seq, source: event.Source(), id: event.ID(), failed: time.Now()}
+		}
+		return err
+	}
+	return nil
+}
+
+// isOrdered returns true if the broker is ordered.
+func isOrdered(brokerConfig config.ReadonlyTargets, broker types.NamespacedName) bool {
+	b, ok := brokerConfig.GetBroker(broker.Namespace, broker.Name)
+	return ok && b.Ordered
+}
+
+// orderingKey returns the ordering key of the event if the broker is ordered, or "" otherwise.
+func orderingKey(brokerConfig config.ReadonlyTargets, broker types.NamespacedName, event *cev2.Event) string {
+	if !isOrdered(brokerConfig, broker) {
+		return ""
 	}
+	return eventutil.PartitionKey(event)
+}
 
-	if topic, ok := m.getExistingTopic(broker); ok {
-		// Check that the broker's topic ID hasn't changed.
-		if topic.ID() == topicID {
-			return topic, nil
+// getTopic returns the topic of the decouple queue, whose topic ID is read from the mounted broker
+// configmap volume. Message ordering is enabled for the topics of ordered brokers.
+func (m *multiTopicDecoupleSink) getTopic(key decoupleKey, topicID string, ordered bool) *pubsub.Topic {
+	if topic, ok := m.getExistingTopic(key); ok {
+		// Check that the topic ID and ordering haven't changed.
+		if topic.ID() == topicID && topic.EnableMessageOrdering == ordered {
+			return topic
 		}
 	}
 
 	// Topic needs to be created or updated.
-	return m.updateTopicForBroker(ctx, broker)
+	return m.updateTopic(key, topicID, ordered)
 }
 
-func (m *multiTopicDecoupleSink) updateTopicForBroker(ctx context.Context, broker types.NamespacedName) (*pubsub.Topic, error) {
+func (m *multiTopicDecoupleSink) updateTopic(key decoupleKey, topicID string, ordered bool) *pubsub.Topic {
 	m.topicsMut.Lock()
 	defer m.topicsMut.Unlock()
-	// Fetch latest decouple topic ID under lock.
-	topicID, err := m.getTopicIDForBroker(ctx, broker)
-	if err != nil {
-		return nil, err
-	}
 
-	if topic, ok := m.topics[broker]; ok {
-		if topic.ID() == topicID {
+	if topic, ok := m.topics[key]; ok {
+		if topic.ID() == topicID && topic.EnableMessageOrdering == ordered {
 			// Topic already updated.
-			return topic, nil
+			return topic
 		}
 		// Stop old topic.
-		m.topics[broker].Stop()
+		topic.Stop()
+		m.resumeAll(key)
 	}
 	topic := m.pubsub.Topic(topicID)
-	m.topics[broker] = topic
-	return topic, nil
+	topic.EnableMessageOrdering = ordered
+	m.topics[key] = topic
+	return topic
+}
+
+// resumeAll forgets the ordering keys paused for the topic of the decouple queue, when the topic
+// is stopped.
+func (m *multiTopicDecoupleSink) resumeAll(queue decoupleKey) {
+	m.pausedMut.Lock()
+	defer m.pausedMut.Unlock()
+	for key := range m.paused {
+		if key.queue == queue {
+			delete(m.paused, key)
+		}
+	}
+}
+
+func (m *multiTopicDecoupleSink) getExistingTopic(key decoupleKey) (*pubsub.Topic, bool) {
+	m.topicsMut.RLock()
+	defer m.topicsMut.RUnlock()
+	topic, ok := m.topics[key]
+	return topic, ok
+}
+
+func (m *multiTopicDecoupleSink) pruneTopicsPeriodically(ctx context.Context) {
+	ticker := time.NewTicker(topicsPruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.pruneTopics()
+		}
+	}
+}
+
+// pruneTopics stops and removes the topics of the decouple queues that are no longer in the broker
+// config, such as the ones of the deleted brokers and of the removed priority classes, or whose
+// topic ID has changed. It also resumes the ordering keys paused for longer than pausedTimeout.
+func (m *multiTopicDecoupleSink) pruneTopics() {
+	m.topicsMut.Lock()
+	defer m.topicsMut.Unlock()
+	for key, topic := range m.topics {
+		if topicID, ok := m.decoupleTopicID(key); !ok || topicID != topic.ID() {
+			topic.Stop()
+			delete(m.topics, key)
+			m.resumeAll(key)
+		}
+	}
+
+	m.pausedMut.Lock()
+	defer m.pausedMut.Unlock()
+	for key, p := range m.paused {
+		if time.Since(p.failed) >= pausedTimeout {
+			if topic, ok := m.topics[key.queue]; ok {
+				topic.ResumePublish(key.orderingKey)
+			}
+			delete(m.paused, key)
+		}
+	}
+}
+
+// decoupleTopicID returns the topic ID of the decouple queue in the broker config, if the broker
+// and its priority class are still in it.
+func (m *multiTopicDecoupleSink) decoupleTopicID(key decoupleKey) (string, bool) {
+	b, ok := m.brokerConfig.GetBroker(key.broker.Namespace, key.broker.Name)
+	if !ok {
+		return "", false
+	}
+	queue := b.DecoupleQueue
+	if key.class != "" {
+		c := b.PriorityClass(key.class)
+		if c == nil {
+			return "", false
+		}
+		queue = c.DecoupleQueue
+	}
+	if queue == nil {
+		return "", false
+	}
+	return queue.Topic, true
 }
 
-func (m *multiTopicDecoupleSink) getTopicIDForBroker(ctx context.Context, broker types.NamespacedName) (string, error) {
-	brokerConfig, ok := m.brokerConfig.GetBroker(broker.Namespace, broker.Name)
+// decoupleTopic finds the decouple topic of the event from the broker config: the topic of the
+// priority class of the event if it is in one, or the topic of the broker otherwise. It also
+// returns the name of the priority class, or "" if the event is in no class.
+func decoupleTopic(ctx context.Context, targets config.ReadonlyTargets, broker types.NamespacedName, event *cev2.Event) (string, string, error) {
+	brokerConfig, ok := targets.GetBroker(broker.Namespace, broker.Name)
 	if !ok {
 		// There is an propagation delay between the controller reconciles the broker config and
 		// the config being pushed to the configmap volume in the ingress pod. So sometimes we return
 		// an error even if the request is valid.
 		logging.FromContext(ctx).Warn("config is not found for")
-		return "", fmt.Errorf("%q: %w", broker, ErrNotFound)
+		return "", "", fmt.Errorf("%q: %w", broker, ErrNotFound)
 	}
-	if brokerConfig.DecoupleQueue == nil || brokerConfig.DecoupleQueue.Topic == "" {
-		logging.FromContext(ctx).Error("DecoupleQueue or topic missing for broker, this should NOT happen.", zap.Any("brokerConfig", brokerConfig))
-		return "", fmt.Errorf("decouple queue of %q: %w", broker, ErrIncomplete)
+	class, queue, name := "", brokerConfig.DecoupleQueue, broker.String()
+	if brokerConfig.PriorityAttribute != "" {
+		if c := brokerConfig.PriorityClassOf(eventutil.AttributeValue(event, brokerConfig.PriorityAttribute)); c != nil {
+			class, queue, name = c.Name, c.DecoupleQueue, fmt.Sprintf("%s (priority class %s)", broker, c.Name)
+		}
+	}
+	if queue == nil || queue.Topic == "" {
+		logging.FromContext(ctx).Error("DecoupleQueue or topic missing for broker, this should NOT happen.", zap.Any("brokerConfig", brokerConfig), zap.String("class", class))
+		return "", "", fmt.Errorf("decouple queue of %q: %w", name, ErrIncomplete)
 	}
-	if brokerConfig.DecoupleQueue.State != config.State_READY {
+	if queue.State != config.State_READY {
 		logging.FromContext(ctx).Debug("decouple queue is not ready")
-		return "", fmt.Errorf("%q: %w", broker, ErrNotReady)
+		return "", "", fmt.Errorf("%q: %w", name, ErrNotReady)
 	}
-	return brokerConfig.DecoupleQueue.Topic, nil
-}
-
-func (m *multiTopicDecoupleSink) getExistingTopic(broker types.NamespacedName) (*pubsub.Topic, bool) {
-	m.topicsMut.RLock()
-	defer m.topicsMut.RUnlock()
-	topic, ok := m.topics[broker]
-	return topic, ok
+	return class, queue.Topic, nil
 }
@@ -18,7 +18,10 @@ package ingress
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"sync"
 	"testing"
 
 	"cloud.google.com/go/pubsub"
@@ -36,6 +39,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/knative-gcp/pkg/broker/config"
 	"github.com/google/knative-gcp/pkg/broker/config/memory"
+	"github.com/google/knative-gcp/pkg/broker/eventutil"
 	logtest "knative.dev/pkg/logging/testing"
 )
 
@@ -220,6 +224,258 @@ func TestMultiTopicDecoupleSink(t *testing.T) {
 	}
 }
 
+func TestMultiTopicDecoupleSinkOrderingKey(t *testing.T) {
+	ctx := logtest.TestContextWithLogger(t)
+	psSrv := pstest.NewServer()
+	defer psSrv.Close()
+	psClient := createPubsubClient(ctx, t, psSrv)
+	for _, topic := range []string{"ordered_topic", "unordered_topic"} {
+		if _, err := psClient.CreateTopic(ctx, topic); err != nil {
+			t.Fatal(err)
+		}
+	}
+	brokerConfig := memory.NewTargets(&config.TargetsConfig{
+		Brokers: map[string]*config.Broker{
+			"test_ns/ordered": {
+				DecoupleQueue: &config.Queue{Topic: "ordered_topic", State: config.State_READY},
+				Ordered:       true,
+			},
+			"test_ns/unordered": {
+				DecoupleQueue: &config.Queue{Topic: "unordered_topic", State: config.State_READY},
+			},
+		},
+	})
+	sink := NewMultiTopicDecoupleSink(ctx, brokerConfig, psClient, pubsub.DefaultPublishSettings)
+
+	for _, name := range []string{"ordered", "unordered"} {
+		event := createTestEvent(name)
+		event.SetExtension(eventutil.PartitionKeyAttribute, "sku-1")
+		if err := sink.Send(ctx, types.NamespacedName{Namespace: "test_ns", Name: name}, *event); err != nil {
+			t.Fatalf("Send() to %s broker got error: %v", name, err)
+		}
+	}
+
+	// The ID of the events is the name of their broker.
+	gotKeys := make(map[string]string)
+	for _, msg := range psSrv.Messages() {
+		gotKeys[msg.Attributes["ce-id"]] = msg.OrderingKey
+	}
+	wantKeys := map[string]string{
+		"ordered":   "sku-1",
+		"unordered": "",
+	}
+	if diff := cmp.Diff(wantKeys, gotKeys); diff != "" {
+		t.Errorf("Unexpected ordering keys by broker (-want +got): %s", diff)
+	}
+	unordered := decoupleKey{broker: types.NamespacedName{Namespace: "test_ns", Name: "unordered"}}
+	if sink.topics[unordered].EnableMessageOrdering {
+		t.Error("Message ordering is enabled for the topic of the unordered broker")
+	}
+	if !sink.topics[decoupleKey{broker: types.NamespacedName{Namespace: "test_ns", Name: "ordered"}}].EnableMessageOrdering {
+		t.Error("Message ordering is not enabled for the topic of the ordered broker")
+	}
+
+	// The topic is replaced when the broker becomes ordered.
+	brokerConfig.MutateBroker("test_ns", "unordered", func(m config.BrokerMutation) {
+		m.SetOrdered(true)
+	})
+	event := createTestEvent("reordered")
+	event.SetExtension(eventutil.PartitionKeyAttribute, "sku-1")
+	if err := sink.Send(ctx, unordered.broker, *event); err != nil {
+		t.Fatalf("Send() to reordered broker got error: %v", err)
+	}
+	if !sink.topics[unordered].EnableMessageOrdering {
+		t.Error("Message ordering is not enabled for the topic of the broker that became ordered")
+	}
+}
+
+func TestMultiTopicDecoupleSinkPaused(t *testing.T) {
+	ctx := logtest.TestContextWithLogger(t)
+	psSrv := pstest.NewServer()
+	defer psSrv.Close()
+	psClient := createPubsubClient(ctx, t, psSrv)
+	brokerConfig := memory.NewTargets(&config.TargetsConfig{
+		Brokers: map[string]*config.Broker{
+			"test_ns/ordered": {
+				DecoupleQueue: &config.Queue{Topic: "ordered_topic", State: config.State_READY},
+				Ordered:       true,
+			},
+		},
+	})
+	sink := NewMultiTopicDecoupleSink(ctx, brokerConfig, psClient, pubsub.DefaultPublishSettings)
+	broker := types.NamespacedName{Namespace: "test_ns", Name: "ordered"}
+	send := func(id, key string) error {
+		event := createTestEvent(id)
+		event.SetExtension(eventutil.PartitionKeyAttribute, key)
+		return sink.Send(ctx, broker, *event)
+	}
+
+	// The topic doesn't exist yet, so that the first event fails.
+	if err := send("first", "sku-1"); err == nil {
+		t.Fatal("Send() to missing topic got no error")
+	}
+	if _, err := psClient.CreateTopic(ctx, "ordered_topic"); err != nil {
+		t.Fatal(err)
+	}
+	if err := send("second", "sku-1"); !errors.Is(err, ErrPaused) {
+		t.Errorf("Send() of an event of the paused key got error %v, want %v", err, ErrPaused)
+	}
+	if err := send("other", "sku-2"); err != nil {
+		t.Errorf("Send() of an event of another key got error: %v", err)
+	}
+	// Retrying the event that failed resumes the key.
+	for _, id := range []string{"first", "second"} {
+		if err := send(id, "sku-1"); err != nil {
+			t.Errorf("Send() of %q got error: %v", id, err)
+		}
+	}
+
+	var got []string
+	for _, msg := range psSrv.Messages() {
+		got = append(got, msg.Attributes["ce-id"])
+	}
+	if diff := cmp.Diff([]string{"other", "first", "second"}, got); diff != "" {
+		t.Errorf("Unexpected published events (-want +got): %s", diff)
+	}
+}
+
+func TestMultiTopicDecoupleSinkPriorityClasses(t *testing.T) {
+	ctx := logtest.TestContextWithLogger(t)
+	psSrv := pstest.NewServer()
+	defer psSrv.Close()
+	psClient := createPubsubClient(ctx, t, psSrv)
+	subscriptions := make(map[string]*pubsub.Subscription)
+	for _, topicID := range []string{"default_topic", "bulk_topic"} {
+		topic, err := psClient.CreateTopic(ctx, topicID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if subscriptions[topicID], err = psClient.CreateSubscription(
+			ctx, topicID+"_sub", pubsub.SubscriptionConfig{Topic: topic}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	brokerConfig := memory.NewTargets(&config.TargetsConfig{
+		Brokers: map[string]*config.Broker{
+			"test_ns/test_broker": {
+				DecoupleQueue:     &config.Queue{Topic: "default_topic", State: config.State_READY},
+				PriorityAttribute: "tier",
+				PriorityClasses: []*config.PriorityClass{
+					{
+						Name:          "bulk",
+						Values:        []string{"batch", "backfill"},
+						DecoupleQueue: &config.Queue{Topic: "bulk_topic", State: config.State_READY},
+					},
+					{
+						Name:          "pending",
+						Values:        []string{"later"},
+						DecoupleQueue: &config.Queue{Topic: "pending_topic", State: config.State_UNKNOWN},
+					},
+				},
+			},
+		},
+	})
+	sink := NewMultiTopicDecoupleSink(ctx, brokerConfig, psClient, pubsub.DefaultPublishSettings)
+	broker := types.NamespacedName{Namespace: "test_ns", Name: "test_broker"}
+
+	// The ID of the events is their tier, events without tier have the ID "none".
+	for _, tier := range []string{"batch", "backfill", "interactive", "none"} {
+		event := createTestEvent(tier)
+		if tier != "none" {
+			event.SetExtension("tier", tier)
+		}
+		if err := sink.Send(ctx, broker, *event); err != nil {
+			t.Fatalf("Send() of %q event got error: %v", tier, err)
+		}
+	}
+	event := createTestEvent("later")
+	event.SetExtension("tier", "later")
+	if err := sink.Send(ctx, broker, *event); !errors.Is(err, ErrNotReady) {
+		t.Errorf("Send() to class that is not ready got error %v, want %v", err, ErrNotReady)
+	}
+
+	gotIDs := make(map[string][]string)
+	for topicID, subscription := range subscriptions {
+		rctx, cancel := context.WithCancel(ctx)
+		var mu sync.Mutex
+		if err := subscription.Receive(rctx, func(ctx context.Context, m *pubsub.Message) {
+			mu.Lock()
+			defer mu.Unlock()
+			gotIDs[topicID] = append(gotIDs[topicID], m.Attributes["ce-id"])
+			if len(gotIDs[topicID]) == 2 {
+				cancel()
+			}
+			m.Ack()
+		}); err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(gotIDs[topicID])
+	}
+	wantIDs := map[string][]string{
+		"bulk_topic":    {"backfill", "batch"},
+		"default_topic": {"interactive", "none"},
+	}
+	if diff := cmp.Diff(wantIDs, gotIDs); diff != "" {
+		t.Errorf("Unexpected events by topic (-want +got): %s", diff)
+	}
+}
+
+func TestMultiTopicDecoupleSinkPruneTopics(t *testing.T) {
+	ctx := logtest.TestContextWithLogger(t)
+	psSrv := pstest.NewServer()
+	defer psSrv.Close()
+	psClient := createPubsubClient(ctx, t, psSrv)
+	for _, topicID := range []string{"topic_1", "bulk_topic_1", "topic_2"} {
+		if _, err := psClient.CreateTopic(ctx, topicID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	brokerConfig := memory.NewTargets(&config.TargetsConfig{
+		Brokers: map[string]*config.Broker{
+			"test_ns/test_broker_1": {
+				DecoupleQueue:     &config.Queue{Topic: "topic_1", State: config.State_READY},
+				PriorityAttribute: "tier",
+				PriorityClasses: []*config.PriorityClass{{
+					Name:          "bulk",
+					Values:        []string{"batch"},
+					DecoupleQueue: &config.Queue{Topic: "bulk_topic_1", State: config.State_READY},
+				}},
+			},
+			"test_ns/test_broker_2": {DecoupleQueue: &config.Queue{Topic: "topic_2", State: config.State_READY}},
+		},
+	})
+	sink := NewMultiTopicDecoupleSink(ctx, brokerConfig, psClient, pubsub.DefaultPublishSettings)
+	broker1 := types.NamespacedName{Namespace: "test_ns", Name: "test_broker_1"}
+	broker2 := types.NamespacedName{Namespace: "test_ns", Name: "test_broker_2"}
+	for _, tier := range []string{"batch", "interactive"} {
+		event := createTestEvent(tier)
+		event.SetExtension("tier", tier)
+		if err := sink.Send(ctx, broker1, *event); err != nil {
+			t.Fatalf("Send() of %q event got error: %v", tier, err)
+		}
+	}
+	if err := sink.Send(ctx, broker2, *createTestEvent("test")); err != nil {
+		t.Fatalf("Send() got error: %v", err)
+	}
+
+	// Remove the priority class of the first broker, and the second broker.
+	brokerConfig.MutateBroker("test_ns", "test_broker_1", func(m config.BrokerMutation) {
+		m.SetPriorityClasses("")
+	})
+	brokerConfig.MutateBroker("test_ns", "test_broker_2", func(m config.BrokerMutation) {
+		m.Delete()
+	})
+	sink.pruneTopics()
+
+	var got []decoupleKey
+	for key := range sink.topics {
+		got = append(got, key)
+	}
+	if diff := cmp.Diff([]decoupleKey{{broker: broker1}}, got, cmp.AllowUnexported(decoupleKey{})); diff != "" {
+		t.Errorf("Unexpected topics after pruning (-want +got): %s", diff)
+	}
+}
+
 type fakePubsubClient struct {
 	t *testing.T
 	// topics is the mapping from topic name to corresponding channel which contains the event.
@@ -0,0 +1,194 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+const (
+	// BrokerRateLimitScope is the scope of the rate limit of a single broker.
+	BrokerRateLimitScope = "broker"
+	// NamespaceRateLimitScope is the scope of the rate limit shared by all brokers of a namespace.
+	NamespaceRateLimitScope = "namespace"
+)
+
+// RateLimitError is the error when an event exceeds a rate limit. It matches ErrRateLimited.
+type RateLimitError struct {
+	// Scope is the scope of the exceeded limit, BrokerRateLimitScope or NamespaceRateLimitScope.
+	Scope string
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s %v", e.Scope, ErrRateLimited)
+}
+
+// Reason returns the reason of the response code reported in the metrics of an event exceeding
+// the limit, e.g. "broker_rate_limit".
+func (e *RateLimitError) Reason() string {
+	return e.Scope + "_rate_limit"
+}
+
+// Is returns true if target is ErrRateLimited.
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// RateLimiter enforces the token bucket rate limits of the brokers and of their namespaces,
+// as set in the broker config. Brokers without limits are unlimited.
+type RateLimiter struct {
+	targets config.ReadonlyTargets
+
+	mu         sync.Mutex
+	brokers    map[string]*bucket
+	namespaces map[string]*bucket
+}
+
+// TargetsUpdates is notified each time the broker config is updated, see volume.WithNotifyChan.
+type TargetsUpdates <-chan struct{}
+
+// bucket is a token bucket along with the config it was created from, so that it can be
+// updated when the config changes.
+type bucket struct {
+	eventsPerSecond float64
+	burst           int32
+	limiter         *rate.Limiter
+}
+
+// NewRateLimiter creates a RateLimiter enforcing the rate limits of the given broker config. The
+// buckets of the deleted brokers are pruned on each update notified by updates until ctx is done,
+// updates may be nil if the config doesn't change.
+func NewRateLimiter(ctx context.Context, targets config.ReadonlyTargets, updates TargetsUpdates) *RateLimiter {
+	l := &RateLimiter{
+		targets:    targets,
+		brokers:    make(map[string]*bucket),
+		namespaces: make(map[string]*bucket),
+	}
+	if updates != nil {
+		go l.pruneOnUpdate(ctx, updates)
+	}
+	return l
+}
+
+// Allow takes a token for an event of the broker from the bucket of the broker and from the
+// bucket of its namespace. It returns a *RateLimitError, without taking any token, if either
+// bucket is empty.
+func (l *RateLimiter) Allow(broker types.NamespacedName) error {
+	b, ok := l.targets.GetBroker(broker.Namespace, broker.Name)
+	if !ok {
+		// The decouple sink rejects the events of a deleted broker anyway.
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// Reserve both tokens at the same instant, so that a reservation can be cancelled in full:
+	// a reservation is only given back if it isn't in the past.
+	now := time.Now()
+	brokerRes, ok := reserve(l.brokers, b.Key(), b.RateLimit, now)
+	if !ok {
+		return &RateLimitError{Scope: BrokerRateLimitScope}
+	}
+	if _, ok := reserve(l.namespaces, b.Namespace, b.NamespaceRateLimit, now); !ok {
+		if brokerRes != nil {
+			// Give the broker token back, the event isn't accepted.
+			brokerRes.CancelAt(now)
+		}
+		return &RateLimitError{Scope: NamespaceRateLimitScope}
+	}
+	return nil
+}
+
+// pruneOnUpdate prunes the buckets on each update of the broker config until ctx is done. It must
+// keep receiving the updates, since the config volume blocks until they are received.
+func (l *RateLimiter) pruneOnUpdate(ctx context.Context, updates TargetsUpdates) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-updates:
+			l.prune()
+		}
+	}
+}
+
+// prune forgets the limits of the brokers that were deleted, and of the namespaces that no
+// longer have brokers.
+func (l *RateLimiter) prune() {
+	brokers := make(map[string]bool)
+	namespaces := make(map[string]bool)
+	l.targets.RangeBrokers(func(b *config.Broker) bool {
+		brokers[b.Key()] = true
+		namespaces[b.Namespace] = true
+		return true
+	})
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key := range l.brokers {
+		if !brokers[key] {
+			delete(l.brokers, key)
+		}
+	}
+	for ns := range l.namespaces {
+		if !namespaces[ns] {
+			delete(l.namespaces, ns)
+		}
+	}
+}
+
+// reserve takes a token at the given time from the bucket of the given key, creating or updating
+// the bucket to match the limit. It returns false if there is no token available at that time,
+// and a nil reservation if there is no limit.
+func reserve(buckets map[string]*bucket, key string, limit *config.RateLimit, now time.Time) (*rate.Reservation, bool) {
+	if limit == nil || limit.EventsPerSecond <= 0 {
+		delete(buckets, key)
+		return nil, true
+	}
+	bk, ok := buckets[key]
+	if !ok || bk.eventsPerSecond != limit.EventsPerSecond || bk.burst != limit.Burst {
+		bk = &bucket{
+			eventsPerSecond: limit.EventsPerSecond,
+			burst:           limit.Burst,
+			limiter:         rate.NewLimiter(rate.Limit(limit.EventsPerSecond), burst(limit)),
+		}
+		buckets[key] = bk
+	}
+	res := bk.limiter.ReserveN(now, 1)
+	if !res.OK() || res.DelayFrom(now) > 0 {
+		res.CancelAt(now)
+		return nil, false
+	}
+	return res, true
+}
+
+// burst returns the size of the bucket of the limit, which defaults to one second of events.
+func burst(limit *config.RateLimit) int {
+	if limit.Burst > 0 {
+		return int(limit.Burst)
+	}
+	return int(math.Max(1, math.Ceil(limit.EventsPerSecond)))
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/google/go-cmp/cmp"
+	"go.opencensus.io/stats/view"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/logging"
+	logtest "knative.dev/pkg/logging/testing"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/broker/config/memory"
+	"github.com/google/knative-gcp/pkg/metrics"
+	reportertest "github.com/google/knative-gcp/pkg/metrics/testing"
+)
+
+func TestRateLimiter(t *testing.T) {
+	targets := memory.NewTargets(&config.TargetsConfig{
+		Brokers: map[string]*config.Broker{
+			"ns1/limited": {
+				Name:      "limited",
+				Namespace: "ns1",
+				RateLimit: &config.RateLimit{EventsPerSecond: 0.001, Burst: 2},
+			},
+			"ns1/unlimited": {
+				Name:      "unlimited",
+				Namespace: "ns1",
+			},
+			"ns2/broker1": {
+				Name:               "broker1",
+				Namespace:          "ns2",
+				RateLimit:          &config.RateLimit{EventsPerSecond: 0.001, Burst: 5},
+				NamespaceRateLimit: &config.RateLimit{EventsPerSecond: 0.001, Burst: 3},
+			},
+			"ns2/broker2": {
+				Name:               "broker2",
+				Namespace:          "ns2",
+				NamespaceRateLimit: &config.RateLimit{EventsPerSecond: 0.001, Burst: 3},
+			},
+		},
+	})
+	l := NewRateLimiter(context.Background(), targets, nil)
+
+	allow := func(ns, name string, want error) {
+		t.Helper()
+		err := l.Allow(types.NamespacedName{Namespace: ns, Name: name})
+		if want == nil {
+			if err != nil {
+				t.Errorf("Allow(%s/%s) got unexpected error: %v", ns, name, err)
+			}
+			return
+		}
+		if !errors.Is(err, ErrRateLimited) || err.Error() != want.Error() {
+			t.Errorf("Allow(%s/%s) got error %v, want %v", ns, name, err, want)
+		}
+	}
+	brokerLimited := &RateLimitError{Scope: BrokerRateLimitScope}
+	namespaceLimited := &RateLimitError{Scope: NamespaceRateLimitScope}
+
+	// The broker limit doesn't apply to the other brokers of the namespace.
+	allow("ns1", "limited", nil)
+	allow("ns1", "limited", nil)
+	allow("ns1", "limited", brokerLimited)
+	for i := 0; i < 10; i++ {
+		allow("ns1", "unlimited", nil)
+	}
+
+	// The namespace limit is shared by the brokers of the namespace, and events rejected by the
+	// namespace limit don't use the broker budget.
+	allow("ns2", "broker1", nil)
+	allow("ns2", "broker2", nil)
+	allow("ns2", "broker1", nil)
+	allow("ns2", "broker1", namespaceLimited)
+	allow("ns2", "broker2", namespaceLimited)
+
+	// Without the namespace limit, broker1 still has the 3 tokens of its burst it didn't use.
+	for _, name := range []string{"broker1", "broker2"} {
+		targets.MutateBroker("ns2", name, func(m config.BrokerMutation) {
+			m.SetNamespaceRateLimit(nil)
+		})
+	}
+	allow("ns2", "broker1", nil)
+	allow("ns2", "broker1", nil)
+	allow("ns2", "broker1", nil)
+	allow("ns2", "broker1", brokerLimited)
+
+	// Brokers that aren't in the config aren't limited.
+	allow("ns3", "broker", nil)
+
+	// The buckets are pruned with the last broker of the namespace.
+	targets.MutateBroker("ns4", "broker", func(m config.BrokerMutation) {
+		m.SetRateLimit(&config.RateLimit{EventsPerSecond: 0.001, Burst: 2})
+		m.SetNamespaceRateLimit(&config.RateLimit{EventsPerSecond: 0.001, Burst: 1})
+	})
+	allow("ns4", "broker", nil)
+	allow("ns4", "broker", namespaceLimited)
+	targets.MutateBroker("ns4", "broker", func(m config.BrokerMutation) {
+		m.Delete()
+	})
+	allow("ns4", "broker", nil)
+	l.prune()
+	if _, ok := l.brokers["ns4/broker"]; ok {
+		t.Error("The bucket of broker ns4/broker wasn't pruned with the broker")
+	}
+	if _, ok := l.namespaces["ns4"]; ok {
+		t.Error("The bucket of namespace ns4 wasn't pruned with its last broker")
+	}
+	if _, ok := l.brokers["ns2/broker1"]; !ok {
+		t.Error("The bucket of broker ns2/broker1 was pruned though it wasn't deleted")
+	}
+
+	// Updating the limit resets the bucket.
+	targets.MutateBroker("ns1", "limited", func(m config.BrokerMutation) {
+		m.SetRateLimit(&config.RateLimit{EventsPerSecond: 0.001, Burst: 1})
+	})
+	allow("ns1", "limited", nil)
+	allow("ns1", "limited", brokerLimited)
+
+	// Removing the limit removes the bucket.
+	targets.MutateBroker("ns1", "limited", func(m config.BrokerMutation) {
+		m.SetRateLimit(nil)
+	})
+	allow("ns1", "limited", nil)
+	allow("ns1", "limited", nil)
+}
+
+func TestRateLimiterPruneOnUpdate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	targets := memory.NewEmptyTargets()
+	targets.MutateBroker("ns", "broker", func(m config.BrokerMutation) {
+		m.SetRateLimit(&config.RateLimit{EventsPerSecond: 0.001, Burst: 1})
+	})
+	updates := make(chan struct{})
+	l := NewRateLimiter(ctx, targets, updates)
+
+	if err := l.Allow(types.NamespacedName{Namespace: "ns", Name: "broker"}); err != nil {
+		t.Fatalf("Allow() got unexpected error: %v", err)
+	}
+	targets.MutateBroker("ns", "broker", func(m config.BrokerMutation) {
+		m.Delete()
+	})
+	// The second update is only received once the first one was handled.
+	updates <- struct{}{}
+	updates <- struct{}{}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.brokers) != 0 {
+		t.Errorf("Got broker buckets %v after the update, want none", l.brokers)
+	}
+}
+
+func TestRateLimitBurstDefault(t *testing.T) {
+	tests := []struct {
+		limit *config.RateLimit
+		want  int
+	}{
+		{limit: &config.RateLimit{EventsPerSecond: 10, Burst: 3}, want: 3},
+		{limit: &config.RateLimit{EventsPerSecond: 10}, want: 10},
+		{limit: &config.RateLimit{EventsPerSecond: 2.5}, want: 3},
+		{limit: &config.RateLimit{EventsPerSecond: 0.1}, want: 1},
+	}
+	for _, tc := range tests {
+		if got := burst(tc.limit); got != tc.want {
+			t.Errorf("burst(%v) = %d, want %d", tc.limit, got, tc.want)
+		}
+	}
+}
+
+func TestHandlerRateLimit(t *testing.T) {
+	reportertest.ResetIngressMetrics()
+	ctx := logging.WithLogger(context.Background(), logtest.TestLogger(t))
+
+	targets := memory.NewTargets(&config.TargetsConfig{
+		Brokers: map[string]*config.Broker{
+			"ns1/broker1": {
+				Name:      "broker1",
+				Namespace: "ns1",
+				RateLimit: &config.RateLimit{EventsPerSecond: 0.001, Burst: 1},
+			},
+		},
+	})
+	decouple := &fakeDecoupleSink{events: make(map[string]cev2.Event)}
+	reporter, err := metrics.NewIngressReporter(metrics.PodName(pod), metrics.ContainerName(container))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewHandler(ctx, nil, decouple, targets, NewRateLimiter(ctx, targets, nil), reporter)
+
+	for i, want := range []int{nethttp.StatusAccepted, nethttp.StatusTooManyRequests, nethttp.StatusTooManyRequests} {
+		req := httptest.NewRequest(nethttp.MethodPost, "/ns1/broker1", nil)
+		if err := http.WriteRequest(ctx, binding.ToMessage(createTestEvent("test-event")), req); err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if got := w.Result().StatusCode; got != want {
+			t.Errorf("Request %d got status code %d, want %d", i, got, want)
+		}
+	}
+
+	// Only the rate limited events are tagged with the reason of their response code.
+	rows, err := view.RetrieveData("event_count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]int64)
+	for _, row := range rows {
+		code, reason := "", ""
+		for _, tag := range row.Tags {
+			switch tag.Key {
+			case metrics.ResponseCodeKey:
+				code = tag.Value
+			case metrics.ResponseCodeReasonKey:
+				reason = tag.Value
+			}
+		}
+		counts[code+"/"+reason] += row.Data.(*view.CountData).Value
+	}
+	wantCounts := map[string]int64{"202/": 1, "429/broker_rate_limit": 2}
+	if diff := cmp.Diff(wantCounts, counts); diff != "" {
+		t.Errorf("Unexpected event counts by response code (-want +got): %s", diff)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"go.opencensus.io/trace"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/broker/queue"
+)
+
+// NewRedisDecoupleSink creates a new redisDecoupleSink.
+func NewRedisDecoupleSink(brokerConfig config.ReadonlyTargets, sender *queue.RedisSender) *redisDecoupleSink {
+	return &redisDecoupleSink{
+		sender:       sender,
+		brokerConfig: brokerConfig,
+	}
+}
+
+// redisDecoupleSink implements DecoupleSink and appends events to the Redis
+// streams named after the decouple topics of the brokers to which the events
+// are sent.
+type redisDecoupleSink struct {
+	sender *queue.RedisSender
+	// brokerConfig holds configurations for all brokers. It's a view of a configmap populated by
+	// the broker controller.
+	brokerConfig config.ReadonlyTargets
+}
+
+// Send sends incoming event to the stream of the broker it belongs to, or of the priority class of
+// the broker it is in.
+func (r *redisDecoupleSink) Send(ctx context.Context, broker types.NamespacedName, event cev2.Event) protocol.Result {
+	_, stream, err := decoupleTopic(ctx, r.brokerConfig, broker, &event)
+	if err != nil {
+		trace.FromContext(ctx).Annotate(
+			[]trace.Attribute{
+				trace.StringAttribute("error_message", err.Error()),
+			},
+			"unable to accept event",
+		)
+		return err
+	}
+
+	if span := trace.FromContext(ctx); span != nil {
+		event = event.Clone()
+		extensions.FromSpanContext(span.SpanContext()).AddTracingAttributes(&event)
+	}
+	if key := orderingKey(r.brokerConfig, broker, &event); key != "" {
+		ctx = queue.WithOrderingKey(ctx, key)
+	}
+	_, err = r.sender.Publish(ctx, stream, &event)
+	return err
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"errors"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/types"
+	logtest "knative.dev/pkg/logging/testing"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/broker/config/memory"
+	"github.com/google/knative-gcp/pkg/broker/eventutil"
+	"github.com/google/knative-gcp/pkg/broker/queue"
+	"github.com/google/knative-gcp/pkg/redis"
+	redistesting "github.com/google/knative-gcp/pkg/redis/testing"
+)
+
+func TestRedisDecoupleSink(t *testing.T) {
+	tests := []struct {
+		name    string
+		broker  *config.Broker
+		stream  string
+		wantKey string
+		wantErr error
+	}{
+		{
+			name:   "happy path",
+			broker: &config.Broker{DecoupleQueue: &config.Queue{Topic: "test_topic", State: config.State_READY}},
+			stream: "test_topic",
+		},
+		{
+			name:    "ordered broker",
+			broker:  &config.Broker{DecoupleQueue: &config.Queue{Topic: "test_topic", State: config.State_READY}, Ordered: true},
+			stream:  "test_topic",
+			wantKey: "test-key",
+		},
+		{
+			name:    "broker config not found",
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "decouple queue missing",
+			broker:  &config.Broker{},
+			wantErr: ErrIncomplete,
+		},
+		{
+			name:    "decouple queue not ready",
+			broker:  &config.Broker{DecoupleQueue: &config.Queue{Topic: "test_topic", State: config.State_UNKNOWN}},
+			wantErr: ErrNotReady,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := logtest.TestContextWithLogger(t)
+			srv, err := redistesting.NewServer("")
+			if err != nil {
+				t.Fatalf("Failed to start Redis server: %v", err)
+			}
+			defer srv.Close()
+			client := redis.NewClient(redis.Options{Address: srv.Addr()})
+			defer client.Close()
+			if err := client.CreateGroup(ctx, "test_topic", "test_group", redis.LastMessage); err != nil {
+				t.Fatalf("Failed to create group: %v", err)
+			}
+
+			brokers := map[string]*config.Broker{}
+			if tt.broker != nil {
+				brokers["test_ns/test_broker"] = tt.broker
+			}
+			sink := NewRedisDecoupleSink(memory.NewTargets(&config.TargetsConfig{Brokers: brokers}), queue.NewRedisSender(client))
+
+			event := cloudevents.NewEvent()
+			event.SetID("test-id")
+			event.SetSource("test-source")
+			event.SetType("test-type")
+			event.SetExtension(eventutil.PartitionKeyAttribute, "test-key")
+			err = sink.Send(ctx, types.NamespacedName{Namespace: "test_ns", Name: "test_broker"}, event)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Send error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Send failed: %v", err)
+			}
+
+			msgs, err := client.ReadGroup(ctx, tt.stream, "test_group", "test_consumer", redis.NewMessages, 10, 0)
+			if err != nil {
+				t.Fatalf("ReadGroup failed: %v", err)
+			}
+			wantFields := 2
+			if tt.wantKey != "" {
+				wantFields = 4
+			}
+			if len(msgs) != 1 || len(msgs[0].Fields) != wantFields {
+				t.Fatalf("Unexpected stream entries %v", msgs)
+			}
+			if tt.wantKey != "" && msgs[0].Fields[3] != tt.wantKey {
+				t.Errorf("Ordering key = %q, want %q", msgs[0].Fields[3], tt.wantKey)
+			}
+			got := cloudevents.NewEvent()
+			if err := format.JSON.Unmarshal([]byte(msgs[0].Fields[1]), &got); err != nil {
+				t.Fatalf("Failed to unmarshal the event: %v", err)
+			}
+			if diff := cmp.Diff(event, got); diff != "" {
+				t.Errorf("Unexpected event (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	"github.com/google/knative-gcp/pkg/redis"
+)
+
+// Environment variables configuring the queues of the broker data plane.
+const (
+	KindEnvKey                  = "DECOUPLE_QUEUE"
+	RedisAddressEnvKey          = "REDIS_ADDRESS"
+	RedisPasswordEnvKey         = "REDIS_PASSWORD"
+	RedisTLSEnabledEnvKey       = "REDIS_TLS_ENABLED"
+	RedisTLSSkipVerifyEnvKey    = "REDIS_TLS_SKIP_VERIFY"
+	RedisTLSCertEnvKey          = "REDIS_TLS_CERT"
+	RedisTLSKeyEnvKey           = "REDIS_TLS_KEY"
+	RedisTLSCACertificateEnvKey = "REDIS_TLS_CA_CERTIFICATE"
+)
+
+// EnvConfig is the queue configuration of the broker data plane, set by the
+// BrokerCell reconciler.
+type EnvConfig struct {
+	Kind Kind `envconfig:"DECOUPLE_QUEUE" default:"pubsub"`
+
+	RedisAddress       string `envconfig:"REDIS_ADDRESS"`
+	RedisPassword      string `envconfig:"REDIS_PASSWORD"`
+	RedisUseTLS        bool   `envconfig:"REDIS_TLS_ENABLED"`
+	RedisSkipVerify    bool   `envconfig:"REDIS_TLS_SKIP_VERIFY"`
+	RedisCert          string `envconfig:"REDIS_TLS_CERT"`
+	RedisKey           string `envconfig:"REDIS_TLS_KEY"`
+	RedisCACertificate string `envconfig:"REDIS_TLS_CA_CERTIFICATE"`
+}
+
+// NewRedisClient creates a client for the Redis instance of the
+// configuration.
+func (e EnvConfig) NewRedisClient() (*redis.Client, error) {
+	if e.RedisAddress == "" {
+		return nil, fmt.Errorf("%s must be set for %q queues", RedisAddressEnvKey, Redis)
+	}
+	var tlsConfig *tls.Config
+	if e.RedisUseTLS {
+		var err error
+		if tlsConfig, err = redis.TLSConfig(e.RedisSkipVerify, e.RedisCert, e.RedisKey, e.RedisCACertificate); err != nil {
+			return nil, err
+		}
+	}
+	return redis.NewClient(redis.Options{
+		Address:   e.RedisAddress,
+		Password:  e.RedisPassword,
+		TLSConfig: tlsConfig,
+	}), nil
+}
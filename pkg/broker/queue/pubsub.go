@@ -0,0 +1,139 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	cepubsub "github.com/cloudevents/sdk-go/protocol/pubsub/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+)
+
+// PubsubSender publishes events to Pub/Sub topics. It implements
+// protocol.Sender, so that CloudEvents clients can send events to the topic of
+// the context, with the ordering key of the context if any.
+type PubsubSender struct {
+	client *pubsub.Client
+
+	mu     sync.Mutex
+	topics map[string]*pubsub.Topic
+}
+
+// NewPubsubSender creates a PubsubSender using the given client.
+func NewPubsubSender(client *pubsub.Client) *PubsubSender {
+	return &PubsubSender{
+		client: client,
+		topics: make(map[string]*pubsub.Topic),
+	}
+}
+
+// Send implements protocol.Sender.Send. The topic is the topic of the
+// context, see cecontext.WithTopic, and the ordering key is the one of the
+// context, see WithOrderingKey.
+func (s *PubsubSender) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) (err error) {
+	defer func() { _ = m.Finish(err) }()
+	topicID := cecontext.TopicFrom(ctx)
+	if topicID == "" {
+		return fmt.Errorf("no topic set in the context")
+	}
+	msg := &pubsub.Message{}
+	if err := cepubsub.WritePubSubMessage(ctx, m, msg, transformers...); err != nil {
+		return err
+	}
+	msg.OrderingKey = OrderingKeyFrom(ctx)
+
+	topic := s.topic(topicID)
+	if _, err := topic.Publish(ctx, msg).Get(ctx); err != nil {
+		if msg.OrderingKey != "" {
+			// Publishing is paused for the key after an error, so that the
+			// messages aren't published out of order. The caller retries.
+			topic.ResumePublish(msg.OrderingKey)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *PubsubSender) topic(id string) *pubsub.Topic {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	topic, ok := s.topics[id]
+	if !ok {
+		topic = s.client.Topic(id)
+		topic.EnableMessageOrdering = true
+		s.topics[id] = topic
+	}
+	return topic
+}
+
+// NewPubsubInbound creates an Inbound receiving messages from the Pub/Sub
+// subscription.
+func NewPubsubInbound(sub *pubsub.Subscription) Inbound {
+	return &pubsubInbound{sub: sub}
+}
+
+type pubsubInbound struct {
+	sub *pubsub.Subscription
+}
+
+// Receive implements Inbound.Receive.
+func (i *pubsubInbound) Receive(ctx context.Context, f func(context.Context, Message)) error {
+	return i.sub.Receive(ctx, func(ctx context.Context, msg *pubsub.Message) {
+		f(ctx, &pubsubMessage{msg: msg})
+	})
+}
+
+type pubsubMessage struct {
+	msg *pubsub.Message
+}
+
+func (m *pubsubMessage) ID() string {
+	return m.msg.ID
+}
+
+func (m *pubsubMessage) Data() []byte {
+	return m.msg.Data
+}
+
+func (m *pubsubMessage) Attributes() map[string]string {
+	return m.msg.Attributes
+}
+
+func (m *pubsubMessage) PublishTime() *time.Time {
+	return &m.msg.PublishTime
+}
+
+func (m *pubsubMessage) Binding() binding.Message {
+	return cepubsub.NewMessage(m.msg)
+}
+
+func (m *pubsubMessage) DeliveryAttempt() *int {
+	return m.msg.DeliveryAttempt
+}
+
+func (m *pubsubMessage) Ack() {
+	m.msg.Ack()
+}
+
+func (m *pubsubMessage) Nack() {
+	m.msg.Nack()
+}
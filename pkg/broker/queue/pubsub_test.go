@@ -0,0 +1,86 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	ceclient "github.com/cloudevents/sdk-go/v2/client"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	logtest "knative.dev/pkg/logging/testing"
+)
+
+func newTestPubsubClient(ctx context.Context, t *testing.T) (*pubsub.Client, *pstest.Server) {
+	t.Helper()
+	srv := pstest.NewServer()
+	conn, err := grpc.Dial(srv.Addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Failed to dial the Pub/Sub server: %v", err)
+	}
+	c, err := pubsub.NewClient(ctx, "test-project", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatalf("Failed to create the Pub/Sub client: %v", err)
+	}
+	t.Cleanup(func() {
+		c.Close()
+		conn.Close()
+		srv.Close()
+	})
+	return c, srv
+}
+
+func TestPubsubSender(t *testing.T) {
+	ctx := logtest.TestContextWithLogger(t)
+	c, srv := newTestPubsubClient(ctx, t)
+	if _, err := c.CreateTopic(ctx, "topic"); err != nil {
+		t.Fatal(err)
+	}
+	client, err := ceclient.New(NewPubsubSender(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tctx := cecontext.WithTopic(ctx, "topic")
+	if res := client.Send(tctx, newTestEvent("unordered")); !cev2.IsACK(res) {
+		t.Fatalf("Send() got error: %v", res)
+	}
+	if res := client.Send(WithOrderingKey(tctx, "key"), newTestEvent("ordered")); !cev2.IsACK(res) {
+		t.Fatalf("Send() with ordering key got error: %v", res)
+	}
+	if res := client.Send(ctx, newTestEvent("no-topic")); cev2.IsACK(res) {
+		t.Error("Send() without topic succeeded, want an error")
+	}
+	if res := client.Send(cecontext.WithTopic(ctx, "missing"), newTestEvent("missing-topic")); cev2.IsACK(res) {
+		t.Error("Send() to a missing topic succeeded, want an error")
+	}
+
+	got := make(map[string]string)
+	for _, msg := range srv.Messages() {
+		got[msg.Attributes["ce-id"]] = msg.OrderingKey
+	}
+	want := map[string]string{"unordered": "", "ordered": "key"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected ordering keys by event ID (-want +got): %s", diff)
+	}
+}
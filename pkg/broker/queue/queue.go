@@ -0,0 +1,96 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package queue abstracts the queues decoupling the broker ingress from the
+// fanout and retry handlers. Pub/Sub topics and subscriptions are the default
+// queues, and Redis streams read through consumer groups can be used instead.
+package queue
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+)
+
+// Kind is the kind of the decouple and retry queues of a BrokerCell.
+type Kind string
+
+const (
+	// PubSub queues are Pub/Sub topics and subscriptions.
+	PubSub Kind = "pubsub"
+	// Redis queues are Redis streams, subscriptions being consumer groups.
+	Redis Kind = "redis"
+)
+
+// Inbound is the receive side of a queue.
+type Inbound interface {
+	// Receive calls f with the messages received from the queue, possibly
+	// concurrently, until ctx is done or an unrecoverable error occurs. The
+	// error is nil if ctx is done.
+	Receive(ctx context.Context, f func(context.Context, Message)) error
+}
+
+// Message is a message received from a queue. Either Ack or Nack must be
+// called once the message is processed.
+type Message interface {
+	// ID is the ID of the message in the queue.
+	ID() string
+
+	// Data is the raw payload of the message, for logging purposes.
+	Data() []byte
+
+	// Attributes are the attributes of the message other than the event, or
+	// nil if it has none.
+	Attributes() map[string]string
+
+	// PublishTime is the time the message was published to the queue, or nil
+	// if the queue doesn't record it.
+	PublishTime() *time.Time
+
+	// Binding returns the message to convert to an event.
+	Binding() binding.Message
+
+	// DeliveryAttempt is the number of times the message has been delivered,
+	// including the current delivery, or nil if the queue doesn't track it.
+	DeliveryAttempt() *int
+
+	// Ack acknowledges the message, so that it is not redelivered.
+	Ack()
+
+	// Nack indicates that the message should be redelivered.
+	Nack()
+}
+
+type orderingKeyKey struct{}
+
+// WithOrderingKey returns a context in which the messages sent by the senders
+// of this package have the given ordering key. The messages of a Pub/Sub topic
+// that share an ordering key are received in order from the subscriptions
+// with message ordering enabled. The entries of a Redis stream that share an
+// ordering key are processed in order by each consumer of a group, see
+// RedisInbound.Receive, but a group spreads the entries across its consumers,
+// so the entries of a key read by different consumers are not ordered.
+func WithOrderingKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, orderingKeyKey{}, key)
+}
+
+// OrderingKeyFrom returns the ordering key of the context, or "" if it has
+// none.
+func OrderingKeyFrom(ctx context.Context) string {
+	key, _ := ctx.Value(orderingKeyKey{}).(string)
+	return key
+}
@@ -0,0 +1,414 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"go.uber.org/zap"
+
+	"github.com/google/knative-gcp/pkg/logging"
+	"github.com/google/knative-gcp/pkg/redis"
+)
+
+const (
+	// eventField is the field of the stream entries holding the event in the
+	// structured JSON format.
+	eventField = "event"
+	// orderingKeyField is the field of the stream entries holding their
+	// ordering key, if any.
+	orderingKeyField = "orderingkey"
+
+	defaultBatchSize    = 10
+	defaultBlockTimeout = 5 * time.Second
+	defaultRetryDelay   = 5 * time.Second
+	defaultMaxLen       = 100000
+	// defaultClaimIdle is longer than the longest timeout to handle an event.
+	defaultClaimIdle = 15 * time.Minute
+
+	// claimScanSize is the maximum number of pending entries of the group
+	// listed at once when looking for entries to claim.
+	claimScanSize = 100
+)
+
+// RedisSender appends events to Redis streams. It implements
+// protocol.Sender, so that CloudEvents clients can send events to the stream
+// named by the topic of the context.
+type RedisSender struct {
+	client *redis.Client
+
+	// MaxLen is about the maximum number of entries kept in a stream. The
+	// oldest entries are trimmed when events are appended, whether or not
+	// they were received. Zero disables the trimming.
+	MaxLen int64
+}
+
+// NewRedisSender creates a RedisSender using the given client.
+func NewRedisSender(client *redis.Client) *RedisSender {
+	return &RedisSender{client: client, MaxLen: defaultMaxLen}
+}
+
+// Publish appends the event to the stream and returns the ID of the entry.
+// The entry has the ordering key of the context, see WithOrderingKey.
+func (s *RedisSender) Publish(ctx context.Context, stream string, event *cev2.Event) (string, error) {
+	data, err := format.JSON.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+	fields := []string{eventField, string(data)}
+	if key := OrderingKeyFrom(ctx); key != "" {
+		fields = append(fields, orderingKeyField, key)
+	}
+	return s.client.Add(ctx, stream, s.MaxLen, fields...)
+}
+
+// Send implements protocol.Sender.Send. The stream is the topic of the
+// context, see cecontext.WithTopic.
+func (s *RedisSender) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) (err error) {
+	defer func() { _ = m.Finish(err) }()
+	stream := cecontext.TopicFrom(ctx)
+	if stream == "" {
+		return fmt.Errorf("no stream set in the context")
+	}
+	event, err := binding.ToEvent(ctx, m, transformers...)
+	if err != nil {
+		return err
+	}
+	_, err = s.Publish(ctx, stream, event)
+	return err
+}
+
+// RedisInbound receives the events of a Redis stream as a consumer of a
+// group. The group is created at the beginning of the stream if it doesn't
+// exist, so that the events appended before the first consumer starts are
+// received.
+type RedisInbound struct {
+	client   *redis.Client
+	stream   string
+	group    string
+	consumer string
+
+	// BatchSize is the maximum number of entries read at once. The entries
+	// of a batch are processed concurrently, except the ones sharing an
+	// ordering key which are processed one after another.
+	BatchSize int
+
+	// BlockTimeout is how long a read waits for new entries.
+	BlockTimeout time.Duration
+
+	// RetryDelay is how long nacked entries wait before being redelivered.
+	RetryDelay time.Duration
+
+	// ClaimIdle is how long the entries delivered to the other consumers of
+	// the group stay pending before this consumer claims them, as when the
+	// other consumer is gone. It must be longer than the time to process an
+	// entry, otherwise entries still being processed are delivered twice.
+	ClaimIdle time.Duration
+}
+
+// NewRedisInbound creates a RedisInbound for the consumer of the group of
+// the stream.
+func NewRedisInbound(client *redis.Client, stream, group, consumer string) *RedisInbound {
+	return &RedisInbound{
+		client:       client,
+		stream:       stream,
+		group:        group,
+		consumer:     consumer,
+		BatchSize:    defaultBatchSize,
+		BlockTimeout: defaultBlockTimeout,
+		RetryDelay:   defaultRetryDelay,
+		ClaimIdle:    defaultClaimIdle,
+	}
+}
+
+// Receive implements Inbound.Receive. The entries left pending by a previous
+// run of the consumer are redelivered first, then new entries are read.
+// Nacked entries stay pending and are redelivered after RetryDelay. The later
+// entries sharing the ordering key of a nacked entry are left pending too,
+// without being processed, so that they are redelivered after it. Every
+// ClaimIdle, the entries pending for other consumers for at least ClaimIdle
+// are claimed and processed.
+func (i *RedisInbound) Receive(ctx context.Context, f func(context.Context, Message)) error {
+	if err := i.client.CreateGroup(ctx, i.stream, i.group, redis.PendingMessages); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+
+	pending, cursor := true, redis.PendingMessages
+	var retryAt time.Time
+	// blocked holds the ordering keys of the entries nacked since the last
+	// pass over the pending entries.
+	blocked := make(map[string]bool)
+	nextClaim := time.Now().Add(i.ClaimIdle)
+	for ctx.Err() == nil {
+		if !pending && !time.Now().Before(nextClaim) {
+			if i.claim(ctx, blocked, f) && retryAt.IsZero() {
+				retryAt = time.Now().Add(i.RetryDelay)
+			}
+			nextClaim = time.Now().Add(i.ClaimIdle)
+		}
+
+		var msgs []redis.Message
+		var err error
+		if pending {
+			msgs, err = i.client.ReadGroup(ctx, i.stream, i.group, i.consumer, cursor, i.BatchSize, 0)
+		} else {
+			msgs, err = i.client.ReadGroup(ctx, i.stream, i.group, i.consumer, redis.NewMessages, i.BatchSize, i.BlockTimeout)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if pending {
+			if len(msgs) == 0 {
+				pending, cursor = false, redis.PendingMessages
+			} else {
+				// Pending entries are read in order, resume after the last one.
+				cursor = msgs[len(msgs)-1].ID
+			}
+		}
+
+		if i.process(ctx, msgs, blocked, f) && retryAt.IsZero() {
+			retryAt = time.Now().Add(i.RetryDelay)
+		}
+		if !pending && !retryAt.IsZero() && !time.Now().Before(retryAt) {
+			// The pending entries are redelivered in order, so the keys
+			// are not blocked anymore.
+			pending, retryAt = true, time.Time{}
+			blocked = make(map[string]bool)
+		}
+	}
+	return nil
+}
+
+// claim claims the entries that have been pending for other consumers for at
+// least ClaimIdle, and processes them. It returns whether any was nacked or
+// left pending.
+func (i *RedisInbound) claim(ctx context.Context, blocked map[string]bool, f func(context.Context, Message)) bool {
+	logger := logging.FromContext(ctx).With(zap.String("stream", i.stream), zap.String("group", i.group))
+	left := false
+	for ctx.Err() == nil {
+		pending, err := i.client.Pending(ctx, i.stream, i.group, "", claimScanSize)
+		if err != nil {
+			logger.Error("Failed to list pending stream entries", zap.Error(err))
+			return left
+		}
+		var ids []string
+		for _, p := range pending {
+			if p.Consumer != i.consumer && p.Idle >= i.ClaimIdle && len(ids) < i.BatchSize {
+				ids = append(ids, p.ID)
+			}
+		}
+		if len(ids) == 0 {
+			return left
+		}
+		msgs, err := i.client.Claim(ctx, i.stream, i.group, i.consumer, i.ClaimIdle, ids...)
+		if err != nil {
+			logger.Error("Failed to claim pending stream entries", zap.Error(err))
+			return left
+		}
+		logger.Info("Claimed stream entries pending for other consumers", zap.Int("count", len(msgs)))
+		// The claimed entries that were deleted from the stream aren't
+		// returned, they are redelivered without event by the next pass over
+		// the pending entries.
+		if i.process(ctx, msgs, blocked, f) || len(msgs) < len(ids) {
+			left = true
+		}
+		if len(ids) < i.BatchSize {
+			return left
+		}
+	}
+	return left
+}
+
+// process calls f with the messages and returns whether any was nacked or
+// left pending. The messages sharing an ordering key are processed one after
+// another, in order, and concurrently with the other messages. The messages
+// of the blocked keys are left pending, and the key of a nacked message is
+// blocked.
+func (i *RedisInbound) process(ctx context.Context, msgs []redis.Message, blocked map[string]bool, f func(context.Context, Message)) bool {
+	var groups [][]*redisMessage
+	keyGroups := make(map[string]int)
+	skipped := false
+	for _, msg := range msgs {
+		m := &redisMessage{ctx: ctx, inbound: i, msg: msg}
+		key := m.field(orderingKeyField)
+		switch {
+		case key == "":
+			groups = append(groups, []*redisMessage{m})
+		case blocked[key]:
+			skipped = true
+		default:
+			if g, ok := keyGroups[key]; ok {
+				groups[g] = append(groups[g], m)
+			} else {
+				keyGroups[key] = len(groups)
+				groups = append(groups, []*redisMessage{m})
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	nacked := make([]bool, len(groups))
+	for n, g := range groups {
+		n, g := n, g
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, m := range g {
+				f(ctx, m)
+				if m.nacked {
+					nacked[n] = true
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for n, g := range groups {
+		if nacked[n] {
+			if key := g[0].field(orderingKeyField); key != "" {
+				blocked[key] = true
+			}
+			skipped = true
+		}
+	}
+	return skipped
+}
+
+type redisMessage struct {
+	ctx     context.Context
+	inbound *RedisInbound
+	msg     redis.Message
+	nacked  bool
+}
+
+func (m *redisMessage) ID() string {
+	return m.msg.ID
+}
+
+func (m *redisMessage) Data() []byte {
+	return []byte(m.event())
+}
+
+// Attributes returns the fields of the entry other than the event.
+func (m *redisMessage) Attributes() map[string]string {
+	var attrs map[string]string
+	for n := 0; n+1 < len(m.msg.Fields); n += 2 {
+		if m.msg.Fields[n] == eventField || m.msg.Fields[n] == orderingKeyField {
+			continue
+		}
+		if attrs == nil {
+			attrs = make(map[string]string)
+		}
+		attrs[m.msg.Fields[n]] = m.msg.Fields[n+1]
+	}
+	return attrs
+}
+
+// PublishTime returns the time of the entry ID, "<milliseconds>-<sequence>".
+func (m *redisMessage) PublishTime() *time.Time {
+	ms, err := strconv.ParseInt(strings.SplitN(m.msg.ID, "-", 2)[0], 10, 64)
+	if err != nil {
+		return nil
+	}
+	t := time.Unix(0, ms*int64(time.Millisecond))
+	return &t
+}
+
+func (m *redisMessage) Binding() binding.Message {
+	return &redisBindingMessage{data: m.event()}
+}
+
+func (m *redisMessage) DeliveryAttempt() *int {
+	return nil
+}
+
+func (m *redisMessage) Ack() {
+	i := m.inbound
+	if _, err := i.client.Ack(m.ctx, i.stream, i.group, m.msg.ID); err != nil {
+		// The entry stays pending and is redelivered later.
+		logging.FromContext(m.ctx).Error("Failed to ack stream entry", zap.String("stream", i.stream), zap.String("id", m.msg.ID), zap.Error(err))
+	}
+}
+
+func (m *redisMessage) Nack() {
+	m.nacked = true
+}
+
+// event returns the value of the event field, or an empty string if the
+// entry doesn't have one.
+func (m *redisMessage) event() string {
+	return m.field(eventField)
+}
+
+// field returns the value of the field, or an empty string if the entry
+// doesn't have it.
+func (m *redisMessage) field(name string) string {
+	for n := 0; n+1 < len(m.msg.Fields); n += 2 {
+		if m.msg.Fields[n] == name {
+			return m.msg.Fields[n+1]
+		}
+	}
+	return ""
+}
+
+// redisBindingMessage is a structured mode binding.Message holding the JSON
+// encoded event of a stream entry.
+type redisBindingMessage struct {
+	data string
+}
+
+func (m *redisBindingMessage) ReadEncoding() binding.Encoding {
+	if m.data == "" {
+		return binding.EncodingUnknown
+	}
+	return binding.EncodingStructured
+}
+
+func (m *redisBindingMessage) ReadStructured(ctx context.Context, w binding.StructuredWriter) error {
+	if m.data == "" {
+		return binding.ErrNotStructured
+	}
+	if err := w.SetStructuredEvent(ctx, format.JSON, bytes.NewReader([]byte(m.data))); err != nil {
+		// The entry won't ever be readable.
+		return fmt.Errorf("%w: %v", binding.ErrCannotConvertToEvent, err)
+	}
+	return nil
+}
+
+func (m *redisBindingMessage) ReadBinary(context.Context, binding.BinaryWriter) error {
+	return binding.ErrNotBinary
+}
+
+func (m *redisBindingMessage) Finish(error) error {
+	return nil
+}
@@ -0,0 +1,320 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	ceclient "github.com/cloudevents/sdk-go/v2/client"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/google/go-cmp/cmp"
+	logtest "knative.dev/pkg/logging/testing"
+
+	"github.com/google/knative-gcp/pkg/redis"
+	redistesting "github.com/google/knative-gcp/pkg/redis/testing"
+)
+
+func newTestClient(t *testing.T) (*redis.Client, *redistesting.Server) {
+	t.Helper()
+	srv, err := redistesting.NewServer("")
+	if err != nil {
+		t.Fatalf("Failed to start Redis server: %v", err)
+	}
+	c := redis.NewClient(redis.Options{Address: srv.Addr()})
+	t.Cleanup(func() {
+		c.Close()
+		srv.Close()
+	})
+	return c, srv
+}
+
+func newTestEvent(id string) cev2.Event {
+	e := cev2.NewEvent()
+	e.SetID(id)
+	e.SetSource("source")
+	e.SetType("type")
+	return e
+}
+
+// receive starts receiving from the inbound and returns the channel of the
+// received messages. The messages are nacked the first time if nack is set.
+func receive(ctx context.Context, t *testing.T, inbound Inbound, nack bool) <-chan Message {
+	ch := make(chan Message, 10)
+	var mu sync.Mutex
+	nacked := make(map[string]bool)
+	go func() {
+		err := inbound.Receive(ctx, func(ctx context.Context, m Message) {
+			mu.Lock()
+			defer mu.Unlock()
+			if nack && !nacked[m.ID()] {
+				nacked[m.ID()] = true
+				m.Nack()
+			} else {
+				m.Ack()
+			}
+			ch <- m
+		})
+		if err != nil {
+			t.Errorf("Receive failed: %v", err)
+		}
+	}()
+	return ch
+}
+
+func nextEvent(t *testing.T, ch <-chan Message) (string, *cev2.Event) {
+	t.Helper()
+	select {
+	case m := <-ch:
+		e, err := binding.ToEvent(context.Background(), m.Binding())
+		if err != nil {
+			t.Fatalf("Failed to convert the message: %v", err)
+		}
+		return m.ID(), e
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a message")
+		return "", nil
+	}
+}
+
+func TestRedisReceive(t *testing.T) {
+	ctx, cancel := context.WithCancel(logtest.TestContextWithLogger(t))
+	defer cancel()
+	client, srv := newTestClient(t)
+	sender := NewRedisSender(client)
+
+	// Events sent before the consumer starts are received.
+	want := newTestEvent("1")
+	if _, err := sender.Publish(ctx, "stream", &want); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	inbound := NewRedisInbound(client, "stream", "group", "consumer")
+	inbound.BlockTimeout = 100 * time.Millisecond
+	ch := receive(ctx, t, inbound, false)
+
+	_, got := nextEvent(t, ch)
+	if diff := cmp.Diff(&want, got); diff != "" {
+		t.Errorf("Unexpected event (-want, +got) = %v", diff)
+	}
+
+	// Events sent by a CloudEvents client are appended to the stream of the topic.
+	c, err := ceclient.New(sender)
+	if err != nil {
+		t.Fatalf("Failed to create the client: %v", err)
+	}
+	want = newTestEvent("2")
+	if err := c.Send(cecontext.WithTopic(ctx, "stream"), want); !cev2.IsACK(err) {
+		t.Fatalf("Send failed: %v", err)
+	}
+	_, got = nextEvent(t, ch)
+	if diff := cmp.Diff(&want, got); diff != "" {
+		t.Errorf("Unexpected event (-want, +got) = %v", diff)
+	}
+
+	// Acked entries are not pending anymore.
+	time.Sleep(100 * time.Millisecond)
+	if pending := srv.Pending("stream", "group"); len(pending) != 0 {
+		t.Errorf("Unexpected pending entries %v", pending)
+	}
+}
+
+func TestRedisSenderTrims(t *testing.T) {
+	client, srv := newTestClient(t)
+	sender := NewRedisSender(client)
+	sender.MaxLen = 1
+
+	for _, id := range []string{"1", "2"} {
+		e := newTestEvent(id)
+		if _, err := sender.Publish(context.Background(), "stream", &e); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+	if got := len(srv.Entries("stream")); got != 1 {
+		t.Errorf("stream has %d entries, want 1", got)
+	}
+}
+
+func TestRedisReceiveNack(t *testing.T) {
+	ctx, cancel := context.WithCancel(logtest.TestContextWithLogger(t))
+	defer cancel()
+	client, srv := newTestClient(t)
+
+	inbound := NewRedisInbound(client, "stream", "group", "consumer")
+	inbound.BlockTimeout = 50 * time.Millisecond
+	inbound.RetryDelay = 100 * time.Millisecond
+	ch := receive(ctx, t, inbound, true)
+
+	want := newTestEvent("1")
+	// Wait for the group to be created at the beginning of the stream.
+	for !srv.HasGroup("stream", "group") {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := NewRedisSender(client).Publish(ctx, "stream", &want); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	id1, got := nextEvent(t, ch)
+	if diff := cmp.Diff(&want, got); diff != "" {
+		t.Errorf("Unexpected event (-want, +got) = %v", diff)
+	}
+	// The nacked entry is redelivered.
+	id2, got := nextEvent(t, ch)
+	if id1 != id2 {
+		t.Errorf("Redelivered ID = %q, want %q", id2, id1)
+	}
+	if diff := cmp.Diff(&want, got); diff != "" {
+		t.Errorf("Unexpected redelivered event (-want, +got) = %v", diff)
+	}
+}
+
+func TestRedisReceiveClaims(t *testing.T) {
+	ctx, cancel := context.WithCancel(logtest.TestContextWithLogger(t))
+	defer cancel()
+	client, srv := newTestClient(t)
+
+	// A consumer that is gone left an entry pending.
+	if err := client.CreateGroup(ctx, "stream", "group", redis.PendingMessages); err != nil {
+		t.Fatalf("CreateGroup failed: %v", err)
+	}
+	want := newTestEvent("1")
+	if _, err := NewRedisSender(client).Publish(ctx, "stream", &want); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if _, err := client.ReadGroup(ctx, "stream", "group", "gone", redis.NewMessages, 10, 0); err != nil {
+		t.Fatalf("ReadGroup failed: %v", err)
+	}
+
+	inbound := NewRedisInbound(client, "stream", "group", "consumer")
+	inbound.BlockTimeout = 50 * time.Millisecond
+	inbound.ClaimIdle = 200 * time.Millisecond
+	ch := receive(ctx, t, inbound, false)
+
+	_, got := nextEvent(t, ch)
+	if diff := cmp.Diff(&want, got); diff != "" {
+		t.Errorf("Unexpected event (-want, +got) = %v", diff)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if pending := srv.Pending("stream", "group"); len(pending) != 0 {
+		t.Errorf("Unexpected pending entries %v", pending)
+	}
+}
+
+func TestRedisReceiveOrdered(t *testing.T) {
+	ctx, cancel := context.WithCancel(logtest.TestContextWithLogger(t))
+	defer cancel()
+	client, _ := newTestClient(t)
+	sender := NewRedisSender(client)
+
+	// Interleave the events of two keys, all read in one batch.
+	for n := 0; n < 10; n++ {
+		e := newTestEvent(strconv.Itoa(n))
+		key := fmt.Sprintf("key%d", n%2)
+		if _, err := sender.Publish(WithOrderingKey(ctx, key), "stream", &e); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+	inbound := NewRedisInbound(client, "stream", "group", "consumer")
+	inbound.BatchSize = 10
+	inbound.BlockTimeout = 50 * time.Millisecond
+	inbound.RetryDelay = 100 * time.Millisecond
+
+	// The event 2 is nacked the first time, the later events of its key wait for it.
+	var mu sync.Mutex
+	processed := make(map[string][]string)
+	nacked := false
+	done := make(chan struct{})
+	go func() {
+		err := inbound.Receive(ctx, func(ctx context.Context, m Message) {
+			e, err := binding.ToEvent(ctx, m.Binding())
+			if err != nil {
+				t.Errorf("Failed to convert the message: %v", err)
+				return
+			}
+			time.Sleep(time.Duration(rand.Intn(10)) * time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+			key := m.(*redisMessage).field(orderingKeyField)
+			processed[key] = append(processed[key], e.ID())
+			if e.ID() == "2" && !nacked {
+				nacked = true
+				m.Nack()
+				return
+			}
+			m.Ack()
+			if len(processed["key0"]) == 6 && len(processed["key1"]) == 5 {
+				close(done)
+			}
+		})
+		if err != nil {
+			t.Errorf("Receive failed: %v", err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the events")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := map[string][]string{
+		"key0": {"0", "2", "2", "4", "6", "8"},
+		"key1": {"1", "3", "5", "7", "9"},
+	}
+	if diff := cmp.Diff(want, processed); diff != "" {
+		t.Errorf("Unexpected processing order (-want, +got) = %v", diff)
+	}
+}
+
+func TestRedisMessageWithoutEvent(t *testing.T) {
+	m := &redisMessage{msg: redis.Message{ID: "1-0", Fields: []string{"foo", "bar"}}}
+	if _, err := binding.ToEvent(context.Background(), m.Binding()); !errors.Is(err, binding.ErrUnknownEncoding) {
+		t.Errorf("ToEvent error = %v, want %v", err, binding.ErrUnknownEncoding)
+	}
+
+	m = &redisMessage{msg: redis.Message{ID: "1-0", Fields: []string{eventField, "not json"}}}
+	if _, err := binding.ToEvent(context.Background(), m.Binding()); !errors.Is(err, binding.ErrCannotConvertToEvent) {
+		t.Errorf("ToEvent error = %v, want %v", err, binding.ErrCannotConvertToEvent)
+	}
+}
+
+func TestRedisMessageMetadata(t *testing.T) {
+	m := &redisMessage{msg: redis.Message{ID: "1600000000123-4", Fields: []string{eventField, "{}", "foo", "bar", orderingKeyField, "key"}}}
+	if diff := cmp.Diff(map[string]string{"foo": "bar"}, m.Attributes()); diff != "" {
+		t.Errorf("Attributes (-want,+got): %v", diff)
+	}
+	want := time.Unix(1600000000, 123*int64(time.Millisecond))
+	if got := m.PublishTime(); got == nil || !got.Equal(want) {
+		t.Errorf("PublishTime = %v, want %v", got, want)
+	}
+
+	m = &redisMessage{msg: redis.Message{ID: "invalid", Fields: []string{eventField, "{}"}}}
+	if got := m.Attributes(); got != nil {
+		t.Errorf("Attributes = %v, want nil", got)
+	}
+	if got := m.PublishTime(); got != nil {
+		t.Errorf("PublishTime = %v, want nil", got)
+	}
+}
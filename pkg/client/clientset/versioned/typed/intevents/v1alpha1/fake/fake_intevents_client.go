@@ -36,6 +36,10 @@ func (c *FakeInternalV1alpha1) PullSubscriptions(namespace string) v1alpha1.Pull
 	return &FakePullSubscriptions{c, namespace}
 }
 
+func (c *FakeInternalV1alpha1) RedisStreamSources(namespace string) v1alpha1.RedisStreamSourceInterface {
+	return &FakeRedisStreamSources{c, namespace}
+}
+
 func (c *FakeInternalV1alpha1) Topics(namespace string) v1alpha1.TopicInterface {
 	return &FakeTopics{c, namespace}
 }
@@ -22,4 +22,6 @@ type BrokerCellExpansion interface{}
 
 type PullSubscriptionExpansion interface{}
 
+type RedisStreamSourceExpansion interface{}
+
 type TopicExpansion interface{}
@@ -28,6 +28,7 @@ type InternalV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	BrokerCellsGetter
 	PullSubscriptionsGetter
+	RedisStreamSourcesGetter
 	TopicsGetter
 }
 
@@ -44,6 +45,10 @@ func (c *InternalV1alpha1Client) PullSubscriptions(namespace string) PullSubscri
 	return newPullSubscriptions(c, namespace)
 }
 
+func (c *InternalV1alpha1Client) RedisStreamSources(namespace string) RedisStreamSourceInterface {
+	return newRedisStreamSources(c, namespace)
+}
+
 func (c *InternalV1alpha1Client) Topics(namespace string) TopicInterface {
 	return newTopics(c, namespace)
 }
@@ -113,6 +113,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Internal().V1alpha1().BrokerCells().Informer()}, nil
 	case inteventsv1alpha1.SchemeGroupVersion.WithResource("pullsubscriptions"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Internal().V1alpha1().PullSubscriptions().Informer()}, nil
+	case inteventsv1alpha1.SchemeGroupVersion.WithResource("redisstreamsources"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Internal().V1alpha1().RedisStreamSources().Informer()}, nil
 	case inteventsv1alpha1.SchemeGroupVersion.WithResource("topics"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Internal().V1alpha1().Topics().Informer()}, nil
 
@@ -28,6 +28,8 @@ type Interface interface {
 	BrokerCells() BrokerCellInformer
 	// PullSubscriptions returns a PullSubscriptionInformer.
 	PullSubscriptions() PullSubscriptionInformer
+	// RedisStreamSources returns a RedisStreamSourceInformer.
+	RedisStreamSources() RedisStreamSourceInformer
 	// Topics returns a TopicInformer.
 	Topics() TopicInformer
 }
@@ -53,6 +55,11 @@ func (v *version) PullSubscriptions() PullSubscriptionInformer {
 	return &pullSubscriptionInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// RedisStreamSources returns a RedisStreamSourceInformer.
+func (v *version) RedisStreamSources() RedisStreamSourceInformer {
+	return &redisStreamSourceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Topics returns a TopicInformer.
 func (v *version) Topics() TopicInformer {
 	return &topicInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
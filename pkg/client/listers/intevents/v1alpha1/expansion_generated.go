@@ -34,6 +34,14 @@ type PullSubscriptionListerExpansion interface{}
 // PullSubscriptionNamespaceLister.
 type PullSubscriptionNamespaceListerExpansion interface{}
 
+// RedisStreamSourceListerExpansion allows custom methods to be added to
+// RedisStreamSourceLister.
+type RedisStreamSourceListerExpansion interface{}
+
+// RedisStreamSourceNamespaceListerExpansion allows custom methods to be added to
+// RedisStreamSourceNamespaceLister.
+type RedisStreamSourceNamespaceListerExpansion interface{}
+
 // TopicListerExpansion allows custom methods to be added to
 // TopicLister.
 type TopicListerExpansion interface{}
@@ -26,6 +26,8 @@ import (
 type TestHandleData struct {
 	PolicyErr    error
 	SetPolicyErr error
+	// Policy, if set, is the initial policy of the handle.
+	Policy *iam.Policy
 }
 
 type testHandle struct {
@@ -49,5 +51,9 @@ func (h *testHandle) SetPolicy(ctx context.Context, policy *iam.Policy) error {
 }
 
 func NewTestHandle(config TestHandleData) giam.Handle {
-	return &testHandle{Config: config}
+	h := &testHandle{Config: config}
+	if config.Policy != nil {
+		h.policy = *config.Policy
+	}
+	return h
 }
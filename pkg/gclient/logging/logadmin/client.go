@@ -25,6 +25,8 @@ import (
 
 // CreateFn is a factory function to create a logadmin client.
 // Matches the signature of https://godoc.org/cloud.google.com/go/logging/logadmin#NewClient.
+// The parent is a project ID, or one of projects/PROJECT_ID, folders/FOLDER_ID,
+// organizations/ORGANIZATION_ID and billingAccounts/BILLING_ACCOUNT_ID.
 type CreateFn func(ctx context.Context, parent string, opts ...option.ClientOption) (Client, error)
 
 func NewClient(ctx context.Context, parent string, opts ...option.ClientOption) (Client, error) {
@@ -35,4 +35,6 @@ type Client interface {
 	DeleteSink(ctx context.Context, sinkID string) error
 	// Sink: https://godoc.org/cloud.google.com/go/logging/logadmin#Client.Sink
 	Sink(ctx context.Context, sinkID string) (*logadmin.Sink, error)
+	// UpdateSinkOpt: https://godoc.org/cloud.google.com/go/logging/logadmin#Client.UpdateSinkOpt
+	UpdateSinkOpt(ctx context.Context, sink *logadmin.Sink, opts logadmin.SinkOptions) (*logadmin.Sink, error)
 }
@@ -67,6 +67,8 @@ func (c *pubsubClient) CreateSubscription(ctx context.Context, id string, cfg Su
 		RetainAckedMessages: cfg.RetainAckedMessages,
 		RetentionDuration:   cfg.RetentionDuration,
 		Labels:              cfg.Labels,
+		RetryPolicy:         cfg.RetryPolicy,
+		DeadLetterPolicy:    cfg.DeadLetterPolicy,
 	}
 	sub, err := c.client.CreateSubscription(ctx, id, pscfg)
 	if err != nil {
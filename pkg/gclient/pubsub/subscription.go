@@ -31,6 +31,8 @@ type SubscriptionConfig struct {
 	RetainAckedMessages bool
 	RetentionDuration   time.Duration
 	Labels              map[string]string
+	RetryPolicy         *pubsub.RetryPolicy
+	DeadLetterPolicy    *pubsub.DeadLetterPolicy
 }
 
 // pubsubSubscription wraps pubsub.Subscription. Is the subscription that will be used everywhere except unit tests.
@@ -58,6 +60,8 @@ func (s *pubsubSubscription) Config(ctx context.Context) (SubscriptionConfig, er
 		RetainAckedMessages: cfg.RetainAckedMessages,
 		RetentionDuration:   cfg.RetentionDuration,
 		Labels:              cfg.Labels,
+		RetryPolicy:         cfg.RetryPolicy,
+		DeadLetterPolicy:    cfg.DeadLetterPolicy,
 	}, nil
 }
 
@@ -68,6 +72,8 @@ func (s *pubsubSubscription) Update(ctx context.Context, cfg SubscriptionConfig)
 		RetainAckedMessages: cfg.RetainAckedMessages,
 		RetentionDuration:   cfg.RetentionDuration,
 		AckDeadline:         cfg.AckDeadline,
+		RetryPolicy:         cfg.RetryPolicy,
+		DeadLetterPolicy:    cfg.DeadLetterPolicy,
 	}
 	updatedConfig, err := s.sub.Update(ctx, config)
 	if err != nil {
@@ -79,6 +85,8 @@ func (s *pubsubSubscription) Update(ctx context.Context, cfg SubscriptionConfig)
 		RetainAckedMessages: updatedConfig.RetainAckedMessages,
 		RetentionDuration:   updatedConfig.RetentionDuration,
 		Labels:              updatedConfig.Labels,
+		RetryPolicy:         updatedConfig.RetryPolicy,
+		DeadLetterPolicy:    updatedConfig.DeadLetterPolicy,
 	}, err
 }
 
@@ -71,3 +71,13 @@ func (c *schedulerClient) DeleteJob(ctx context.Context, req *schedulerpb.Delete
 func (c *schedulerClient) GetJob(ctx context.Context, req *schedulerpb.GetJobRequest, opts ...gax.CallOption) (*schedulerpb.Job, error) {
 	return c.client.GetJob(ctx, req, opts...)
 }
+
+// PauseJob implements scheduler.CloudSchedulerClient.PauseJob
+func (c *schedulerClient) PauseJob(ctx context.Context, req *schedulerpb.PauseJobRequest, opts ...gax.CallOption) (*schedulerpb.Job, error) {
+	return c.client.PauseJob(ctx, req, opts...)
+}
+
+// ResumeJob implements scheduler.CloudSchedulerClient.ResumeJob
+func (c *schedulerClient) ResumeJob(ctx context.Context, req *schedulerpb.ResumeJobRequest, opts ...gax.CallOption) (*schedulerpb.Job, error) {
+	return c.client.ResumeJob(ctx, req, opts...)
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 
 	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
@@ -54,3 +55,19 @@ func (c *storageClient) Close() error {
 func (c *storageClient) Bucket(name string) Bucket {
 	return &storageBucket{handle: c.client.Bucket(name)}
 }
+
+// ListBuckets implements storage.Client.Buckets
+func (c *storageClient) ListBuckets(ctx context.Context, projectID string) ([]*storage.BucketAttrs, error) {
+	var buckets []*storage.BucketAttrs
+	it := c.client.Buckets(ctx, projectID)
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return buckets, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, attrs)
+	}
+}
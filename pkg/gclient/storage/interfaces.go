@@ -29,6 +29,9 @@ type Client interface {
 	Close() error
 	// Bucket see https://godoc.org/cloud.google.com/go/storage#Client.Bucket
 	Bucket(name string) Bucket
+	// ListBuckets lists the attributes of all the buckets of a project, see
+	// https://godoc.org/cloud.google.com/go/storage#Client.Buckets
+	ListBuckets(ctx context.Context, projectID string) ([]*storage.BucketAttrs, error)
 }
 
 // Bucket matches the interface exposed by storage.BucketHandle
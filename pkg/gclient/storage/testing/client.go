@@ -19,6 +19,7 @@ package testing
 import (
 	"context"
 
+	gstorage "cloud.google.com/go/storage"
 	"github.com/google/knative-gcp/pkg/gclient/storage"
 	"google.golang.org/api/option"
 )
@@ -50,6 +51,8 @@ type TestClientData struct {
 	CreateTopicErr        error
 	CloseErr              error
 	BucketData            TestBucketData
+	Buckets               []*gstorage.BucketAttrs
+	ListBucketsErr        error
 }
 
 // testClient is a test Storage client.
@@ -69,3 +72,8 @@ func (c *testClient) Close() error {
 func (c *testClient) Bucket(name string) storage.Bucket {
 	return &testBucket{data: c.data.BucketData}
 }
+
+// ListBuckets implements client.ListBuckets
+func (c *testClient) ListBuckets(ctx context.Context, projectID string) ([]*gstorage.BucketAttrs, error) {
+	return c.data.Buckets, c.data.ListBucketsErr
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kncloudevents
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"time"
+
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
+)
+
+// UnconvertibleSender sends the messages that could not be converted to events to a sink,
+// wrapped in envelope events of type schemasv1.UnconvertibleMessageEventType.
+type UnconvertibleSender struct {
+	client  *nethttp.Client
+	sinkURI string
+	source  string
+}
+
+// NewUnconvertibleSender creates an UnconvertibleSender sending the envelope events with the
+// given source to the sink URI.
+func NewUnconvertibleSender(client *nethttp.Client, sinkURI, source string) *UnconvertibleSender {
+	return &UnconvertibleSender{
+		client:  client,
+		sinkURI: sinkURI,
+		source:  source,
+	}
+}
+
+// Send wraps the message in an envelope event and sends it to the sink. It returns an error
+// unless the sink accepted the event.
+func (s *UnconvertibleSender) Send(ctx context.Context, msg schemasv1.UnconvertibleMessage) error {
+	event, err := NewUnconvertibleEvent(s.source, msg)
+	if err != nil {
+		return err
+	}
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodPost, s.sinkURI, nil)
+	if err != nil {
+		return err
+	}
+	if err := cehttp.WriteRequest(ctx, binding.ToMessage(event), req); err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unconvertible sink responded with status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// NewUnconvertibleEvent creates the envelope event of a message that could not be converted to
+// an event. The ID of the event is the ID of the message, so that redeliveries of the message
+// can be deduplicated.
+func NewUnconvertibleEvent(source string, msg schemasv1.UnconvertibleMessage) (*cev2.Event, error) {
+	event := cev2.NewEvent(cev2.VersionV1)
+	event.SetID(msg.ID)
+	event.SetSource(source)
+	event.SetType(schemasv1.UnconvertibleMessageEventType)
+	event.SetTime(time.Now())
+	if err := event.SetData(cev2.ApplicationJSON, msg); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
@@ -30,6 +30,7 @@ import (
 	"knative.dev/pkg/metrics"
 
 	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/broker/handler/breaker"
 )
 
 type DeliveryMetricsKey int
@@ -43,6 +44,7 @@ type DeliveryReporter struct {
 	containerName         ContainerName
 	dispatchTimeInMsecM   *stats.Float64Measure
 	processingTimeInMsecM *stats.Float64Measure
+	circuitBreakerStateM  *stats.Int64Measure
 }
 
 func (r *DeliveryReporter) register() error {
@@ -93,6 +95,19 @@ func (r *DeliveryReporter) register() error {
 				ContainerNameKey,
 			},
 		},
+		&view.View{
+			Name:        r.circuitBreakerStateM.Name(),
+			Description: r.circuitBreakerStateM.Description(),
+			Measure:     r.circuitBreakerStateM,
+			Aggregation: view.LastValue(),
+			TagKeys: []tag.Key{
+				NamespaceNameKey,
+				BrokerNameKey,
+				TriggerNameKey,
+				PodNameKey,
+				ContainerNameKey,
+			},
+		},
 	)
 }
 
@@ -115,6 +130,13 @@ func NewDeliveryReporter(podName PodName, containerName ContainerName) (*Deliver
 			"The time spent processing an event before it is dispatched to a Trigger subscriber",
 			stats.UnitMilliseconds,
 		),
+		// circuitBreakerStateM records the state of the circuit breaker of a
+		// Trigger: 0 when closed, 1 when half-open and 2 when open.
+		circuitBreakerStateM: stats.Int64(
+			"circuit_breaker_state",
+			"The state of the circuit breaker of a Trigger subscriber: 0 closed, 1 half-open, 2 open",
+			stats.UnitDimensionless,
+		),
 	}
 
 	if err := r.register(); err != nil {
@@ -130,6 +152,12 @@ func (r *DeliveryReporter) ReportEventDispatchTime(ctx context.Context, d time.D
 	metrics.Record(ctx, r.dispatchTimeInMsecM.M(float64(d/time.Millisecond)), stats.WithAttachments(attachments))
 }
 
+// ReportCircuitBreakerState captures the state of the circuit breaker of a
+// Trigger subscriber.
+func (r *DeliveryReporter) ReportCircuitBreakerState(ctx context.Context, s breaker.State) {
+	metrics.Record(ctx, r.circuitBreakerStateM.M(int64(s)))
+}
+
 // StartEventProcessing records the start of event processing for delivery within the given context.
 func StartEventProcessing(ctx context.Context) context.Context {
 	return context.WithValue(ctx, startDeliveryProcessingTime, time.Now())
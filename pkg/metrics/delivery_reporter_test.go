@@ -24,6 +24,7 @@ import (
 	_ "knative.dev/pkg/metrics/testing"
 
 	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/broker/handler/breaker"
 	reportertest "github.com/google/knative-gcp/pkg/metrics/testing"
 	"knative.dev/pkg/metrics/metricskey"
 	"knative.dev/pkg/metrics/metricstest"
@@ -171,3 +172,36 @@ func TestMetricsWithEmptySourceAndTypeFilter(t *testing.T) {
 	})
 	metricstest.CheckCountData(t, "event_count", wantTags, 1)
 }
+
+func TestReportCircuitBreakerState(t *testing.T) {
+	reportertest.ResetDeliveryMetrics()
+
+	wantTags := map[string]string{
+		metricskey.LabelNamespaceName: "testns",
+		metricskey.LabelBrokerName:    "testbroker",
+		metricskey.LabelTriggerName:   "testtrigger",
+		metricskey.PodName:            "testpod",
+		metricskey.ContainerName:      "testcontainer",
+	}
+
+	r, err := NewDeliveryReporter("testpod", "testcontainer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := r.AddTags(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err = AddTargetTags(ctx, &config.Target{
+		Namespace: "testns",
+		Broker:    "testbroker",
+		Name:      "testtrigger",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.ReportCircuitBreakerState(ctx, breaker.Open)
+	metricstest.CheckLastValueData(t, "circuit_breaker_state", wantTags, 2)
+	r.ReportCircuitBreakerState(ctx, breaker.HalfOpen)
+	metricstest.CheckLastValueData(t, "circuit_breaker_state", wantTags, 1)
+}
@@ -37,6 +37,9 @@ type IngressReportArgs struct {
 	Broker       string
 	EventType    string
 	ResponseCode int
+	// ResponseCodeReason is the reason of the response code, if it needs to be told apart from
+	// other responses with the same code, e.g. "broker_rate_limit". Not tagged if empty.
+	ResponseCodeReason string
 }
 
 func (r *IngressReporter) register() error {
@@ -46,6 +49,7 @@ func (r *IngressReporter) register() error {
 		EventTypeKey,
 		ResponseCodeKey,
 		ResponseCodeClassKey,
+		ResponseCodeReasonKey,
 		PodNameKey,
 		ContainerNameKey,
 	}
@@ -87,8 +91,7 @@ type IngressReporter struct {
 }
 
 func (r *IngressReporter) ReportEventCount(ctx context.Context, args IngressReportArgs) error {
-	tag, err := tag.New(
-		ctx,
+	mutators := []tag.Mutator{
 		tag.Insert(PodNameKey, string(r.podName)),
 		tag.Insert(ContainerNameKey, string(r.containerName)),
 		tag.Insert(NamespaceNameKey, args.Namespace),
@@ -96,7 +99,11 @@ func (r *IngressReporter) ReportEventCount(ctx context.Context, args IngressRepo
 		tag.Insert(EventTypeKey, EventTypeMetricValue(args.EventType)),
 		tag.Insert(ResponseCodeKey, strconv.Itoa(args.ResponseCode)),
 		tag.Insert(ResponseCodeClassKey, metrics.ResponseCodeClass(args.ResponseCode)),
-	)
+	}
+	if args.ResponseCodeReason != "" {
+		mutators = append(mutators, tag.Insert(ResponseCodeReasonKey, args.ResponseCodeReason))
+	}
+	tag, err := tag.New(ctx, mutators...)
 	if err != nil {
 		return fmt.Errorf("failed to create metrics tag: %v", err)
 	}
@@ -94,3 +94,35 @@ func TestStatsReporterWithCustomEventType(t *testing.T) {
 	})
 	metricstest.CheckCountData(t, "event_count", wantTags, 2)
 }
+
+func TestStatsReporterWithResponseCodeReason(t *testing.T) {
+	reportertest.ResetIngressMetrics()
+
+	args := IngressReportArgs{
+		Namespace:          "testns",
+		Broker:             "testbroker",
+		EventType:          "testeventtype",
+		ResponseCode:       429,
+		ResponseCodeReason: "broker_rate_limit",
+	}
+	wantTags := map[string]string{
+		metricskey.LabelNamespaceName:     "testns",
+		metricskey.LabelBrokerName:        "testbroker",
+		metricskey.LabelEventType:         "custom",
+		metricskey.LabelResponseCode:      "429",
+		metricskey.LabelResponseCodeClass: "4xx",
+		"response_code_reason":            "broker_rate_limit",
+		metricskey.ContainerName:          "testcontainer",
+		metricskey.PodName:                "testpod",
+	}
+
+	r, err := NewIngressReporter(PodName("testpod"), ContainerName("testcontainer"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reportertest.ExpectMetrics(t, func() error {
+		return r.ReportEventCount(context.Background(), args)
+	})
+	metricstest.CheckCountData(t, "event_count", wantTags, 1)
+}
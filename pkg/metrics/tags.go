@@ -27,6 +27,8 @@ const (
 	defaultEventType  = "custom"
 	labelResourceKind = "resource_kind"
 	labelResourceName = "resource_name"
+
+	labelResponseCodeReason = "response_code_reason"
 )
 
 type PodName string
@@ -49,6 +51,9 @@ var (
 
 	ResponseCodeKey      = tag.MustNewKey(metricskey.LabelResponseCode)
 	ResponseCodeClassKey = tag.MustNewKey(metricskey.LabelResponseCodeClass)
+	// ResponseCodeReasonKey distinguishes responses with the same code but different causes,
+	// e.g. a 429 because of a rate limit from a 429 because the decouple queue is overloaded.
+	ResponseCodeReasonKey = tag.MustNewKey(labelResponseCodeReason)
 
 	PodNameKey       = tag.MustNewKey(metricskey.PodName)
 	ContainerNameKey = tag.MustNewKey(metricskey.ContainerName)
@@ -13,7 +13,7 @@ func ResetIngressMetrics() {
 
 func ResetDeliveryMetrics() {
 	// OpenCensus metrics carry global state that need to be reset between unit tests.
-	metricstest.Unregister("event_count", "event_dispatch_latencies", "event_processing_latencies")
+	metricstest.Unregister("event_count", "event_dispatch_latencies", "event_processing_latencies", "circuit_breaker_state")
 }
 
 func ResetBrokerCellMetrics() {
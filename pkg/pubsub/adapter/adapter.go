@@ -28,11 +28,14 @@ import (
 	"github.com/cloudevents/sdk-go/v2/extensions"
 	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
 	"github.com/google/knative-gcp/pkg/apis/messaging"
+	"github.com/google/knative-gcp/pkg/kncloudevents"
 	"github.com/google/knative-gcp/pkg/logging"
 	. "github.com/google/knative-gcp/pkg/pubsub/adapter/context"
 	"github.com/google/knative-gcp/pkg/pubsub/adapter/converters"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
 	"github.com/google/knative-gcp/pkg/tracing"
 	"github.com/google/knative-gcp/pkg/utils/clients"
+	"github.com/google/knative-gcp/pkg/utils/glob"
 	"go.opencensus.io/trace"
 	"k8s.io/apimachinery/pkg/types"
 	kntracing "knative.dev/eventing/pkg/tracing"
@@ -55,6 +58,19 @@ type AdapterArgs struct {
 
 	// ConverterType use to select which converter to use.
 	ConverterType converters.ConverterType
+
+	// UnconvertibleSinkURI is the URI where to send the messages that could
+	// not be converted to events, wrapped in envelope events. These messages
+	// are dropped if it is empty.
+	UnconvertibleSinkURI string
+
+	// DataValidation is the mode of validation of the data of the converted
+	// events against the schema of their type.
+	DataValidation converters.DataValidation
+
+	// ObjectNameFilter filters the Cloud Storage messages by the names of
+	// their objects. The messages that don't match are acked and dropped.
+	ObjectNameFilter *glob.Filter
 }
 
 // Adapter implements the Pub/Sub adapter to deliver Pub/Sub messages from a
@@ -84,6 +100,10 @@ type Adapter struct {
 	// args holds a set of arguments used to configure the Adapter.
 	args *AdapterArgs
 
+	// unconvertible sends the messages that could not be converted to events
+	// to the unconvertible sink, if any.
+	unconvertible *kncloudevents.UnconvertibleSender
+
 	// cancel is function to stop pulling messages.
 	cancel context.CancelFunc
 
@@ -102,17 +122,22 @@ func NewAdapter(
 	converter converters.Converter,
 	reporter StatsReporter,
 	args *AdapterArgs) *Adapter {
-	return &Adapter{
+	a := &Adapter{
 		subscription:   subscription,
 		projectID:      string(projectID),
 		namespacedName: types.NamespacedName{Namespace: string(namespace), Name: string(name)},
 		resourceGroup:  string(resourceGroup),
 		outbound:       outbound,
-		converter:      converter,
+		converter:      converters.NewObjectNameFilteringConverter(converters.NewValidatingConverter(converter, args.DataValidation), args.ObjectNameFilter),
 		reporter:       reporter,
 		args:           args,
 		logger:         logging.FromContext(ctx),
 	}
+	if args.UnconvertibleSinkURI != "" {
+		source := schemasv1.CloudPubSubEventSource(string(projectID), args.TopicID)
+		a.unconvertible = kncloudevents.NewUnconvertibleSender(outbound, args.UnconvertibleSinkURI, source)
+	}
+	return a
 }
 
 func (a *Adapter) Start(ctx context.Context) error {
@@ -135,12 +160,15 @@ func (a *Adapter) Stop() {
 //  (in the case of Channels) and the logic is more convoluted.
 func (a *Adapter) receive(ctx context.Context, msg *pubsub.Message) {
 	event, err := a.converter.Convert(ctx, msg, a.args.ConverterType)
-	if err != nil {
-		a.logger.Debug("Failed to convert received message to an event, check the msg format: %v", zap.Error(err))
-		// Ack the message so it won't be retried, we consider all errors to be non-retryable.
+	if err == converters.ErrFiltered {
+		a.logger.Debug("Dropping filtered message", zap.String("messageID", msg.ID))
 		msg.Ack()
 		return
 	}
+	if err != nil {
+		a.handleUnconvertible(ctx, msg, err)
+		return
+	}
 
 	ctx, span := a.startSpan(ctx, event)
 	defer span.End()
@@ -236,6 +264,31 @@ func (a *Adapter) receive(ctx context.Context, msg *pubsub.Message) {
 	msg.Ack()
 }
 
+// handleUnconvertible sends a message that could not be converted to an event
+// to the unconvertible sink, if any.
+func (a *Adapter) handleUnconvertible(ctx context.Context, msg *pubsub.Message, err error) {
+	a.logger.Debug("Failed to convert received message to an event, check the msg format", zap.String("messageID", msg.ID), zap.Error(err))
+	if a.unconvertible == nil {
+		// Ack the message so it won't be retried, we consider all errors to be non-retryable.
+		msg.Ack()
+		return
+	}
+	publishTime := msg.PublishTime
+	if err := a.unconvertible.Send(ctx, schemasv1.UnconvertibleMessage{
+		ID:          msg.ID,
+		Data:        msg.Data,
+		Attributes:  msg.Attributes,
+		PublishTime: &publishTime,
+		Error:       err.Error(),
+	}); err != nil {
+		a.logger.Error("Failed to send unconvertible message to sink", zap.String("address", a.args.UnconvertibleSinkURI), zap.String("messageID", msg.ID), zap.Error(err))
+		// Nack the message so that it is not lost.
+		msg.Nack()
+		return
+	}
+	msg.Ack()
+}
+
 func (a *Adapter) sendMsg(ctx context.Context, address string, msg binding.Message) (*nethttp.Response, error) {
 	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodPost, address, nil)
 	if err != nil {
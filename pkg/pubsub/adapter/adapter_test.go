@@ -34,6 +34,7 @@ import (
 	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/knative-gcp/pkg/pubsub/adapter/converters"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
 	"github.com/google/knative-gcp/pkg/utils/clients"
 	"golang.org/x/sync/errgroup"
 	logtest "knative.dev/pkg/logging/testing"
@@ -223,8 +224,8 @@ func TestAdapter(t *testing.T) {
 					return fmt.Errorf("unexpected error from transformer receiving event: %v", err)
 				}
 
-				defer msg.Finish(nil)
 				gotEvent, err := binding.ToEvent(rctx, msg)
+				msg.Finish(nil)
 				if err != nil {
 					return fmt.Errorf("transformer received message cannot be converted to an event: %v", err)
 				}
@@ -250,8 +251,8 @@ func TestAdapter(t *testing.T) {
 					return fmt.Errorf("unexpected error from sink when receiving event: %v", err)
 				}
 
-				defer msg.Finish(nil)
 				gotEvent, err := binding.ToEvent(rctx, msg)
+				msg.Finish(nil)
 				if err != nil {
 					return fmt.Errorf("sink received message that cannot be converted to an event: %v", err)
 				}
@@ -283,6 +284,102 @@ func TestAdapter(t *testing.T) {
 	}
 }
 
+func TestAdapterUnconvertible(t *testing.T) {
+	ctx := logtest.TestContextWithLogger(t)
+
+	unconvertibleClient, err := cehttp.New()
+	if err != nil {
+		t.Fatalf("failed to create unconvertible sink cloudevents client: %v", err)
+	}
+	unconvertibleSvr := httptest.NewServer(unconvertibleClient)
+	defer unconvertibleSvr.Close()
+
+	c, close := testPubsubClient(ctx, t, testProjectID)
+	defer close()
+
+	topic, err := c.CreateTopic(ctx, testTopic)
+	if err != nil {
+		t.Fatalf("failed to create topic: %v", err)
+	}
+	sub, err := c.CreateSubscription(ctx, testSub, pubsub.SubscriptionConfig{
+		Topic: topic,
+	})
+	if err != nil {
+		t.Fatalf("failed to create subscription: %v", err)
+	}
+
+	adapter := NewAdapter(ctx,
+		clients.ProjectID(testProjectID),
+		Namespace(testNamespace),
+		Name(testName),
+		ResourceGroup(testResourceGroup),
+		sub,
+		http.DefaultClient,
+		&mockConverter{},
+		&statsReporterRecorder{},
+		&AdapterArgs{
+			TopicID:              testTopic,
+			SinkURI:              "http://unused",
+			ConverterType:        converters.ConverterType(testConverterType),
+			UnconvertibleSinkURI: unconvertibleSvr.URL,
+		})
+
+	rctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		adapter.Start(rctx)
+		done <- struct{}{}
+	}()
+	// Stop the adapter and wait for it before the test ends.
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	msgID, err := topic.Publish(ctx, &pubsub.Message{
+		Data:       []byte("not an event"),
+		Attributes: map[string]string{"key": "value"},
+	}).Get(ctx)
+	if err != nil {
+		t.Fatalf("failed to publish message: %v", err)
+	}
+
+	msg, err := unconvertibleClient.Receive(rctx)
+	if err != nil {
+		t.Fatalf("unexpected error from unconvertible sink when receiving event: %v", err)
+	}
+	gotEvent, err := binding.ToEvent(rctx, msg)
+	// Answer the adapter before any assertion can stop the test.
+	msg.Finish(nil)
+	if err != nil {
+		t.Fatalf("unconvertible sink received message that cannot be converted to an event: %v", err)
+	}
+	if got, want := gotEvent.Type(), schemasv1.UnconvertibleMessageEventType; got != want {
+		t.Errorf("event type = %q, want %q", got, want)
+	}
+	if got, want := gotEvent.Source(), schemasv1.CloudPubSubEventSource(testProjectID, testTopic); got != want {
+		t.Errorf("event source = %q, want %q", got, want)
+	}
+	var data schemasv1.UnconvertibleMessage
+	if err := gotEvent.DataAs(&data); err != nil {
+		t.Fatalf("failed to decode event data: %v", err)
+	}
+	if data.PublishTime == nil {
+		t.Error("event data has no publish time")
+	}
+	data.PublishTime = nil
+	want := schemasv1.UnconvertibleMessage{
+		ID:         msgID,
+		Data:       []byte("not an event"),
+		Attributes: map[string]string{"key": "value"},
+		Error:      "induced error",
+	}
+	if diff := cmp.Diff(want, data); diff != "" {
+		t.Errorf("event data (-want,+got): %v", diff)
+	}
+}
+
 func newSampleEvent() *event.Event {
 	sampleEvent := event.New()
 	sampleEvent.SetID("id")
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/pubsub"
+	cev2 "github.com/cloudevents/sdk-go/v2"
+
+	"github.com/google/knative-gcp/pkg/utils/glob"
+)
+
+// ErrFiltered is returned by a converter when a message is dropped on purpose. Such messages are
+// acked without being sent anywhere.
+var ErrFiltered = errors.New("message filtered out")
+
+// ObjectNameFilter holds the glob patterns of the names of the objects whose Cloud Storage
+// events are sent, see pkg/utils/glob.
+type ObjectNameFilter struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// String returns the JSON encoding of the filter, or "" if it has no patterns.
+func (f ObjectNameFilter) String() string {
+	if len(f.Include) == 0 && len(f.Exclude) == 0 {
+		return ""
+	}
+	// Encoding string slices doesn't fail.
+	b, _ := json.Marshal(f)
+	return string(b)
+}
+
+// ParseObjectNameFilter parses the JSON encoding of an ObjectNameFilter, returning nil if s is
+// empty.
+func ParseObjectNameFilter(s string) (*glob.Filter, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var f ObjectNameFilter
+	if err := json.Unmarshal([]byte(s), &f); err != nil {
+		return nil, fmt.Errorf("failed to decode object name filter: %w", err)
+	}
+	return glob.NewFilter(f.Include, f.Exclude)
+}
+
+type objectNameFilteringConverter struct {
+	converter Converter
+	filter    *glob.Filter
+}
+
+// NewObjectNameFilteringConverter returns a converter failing with ErrFiltered the conversion of
+// the Cloud Storage messages of the objects whose names don't match the given filter. The other
+// messages are converted by the given converter.
+func NewObjectNameFilteringConverter(converter Converter, filter *glob.Filter) Converter {
+	if filter == nil {
+		return converter
+	}
+	return &objectNameFilteringConverter{
+		converter: converter,
+		filter:    filter,
+	}
+}
+
+func (c *objectNameFilteringConverter) Convert(ctx context.Context, msg *pubsub.Message, converterType ConverterType) (*cev2.Event, error) {
+	if converterType == CloudStorage && msg != nil {
+		// Messages without objectId fail to convert below.
+		if name, ok := msg.Attributes["objectId"]; ok && !c.filter.Match(name) {
+			return nil, ErrFiltered
+		}
+	}
+	return c.converter.Convert(ctx, msg, converterType)
+}
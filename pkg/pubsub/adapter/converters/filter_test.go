@@ -0,0 +1,99 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converters
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+)
+
+func TestObjectNameFilterString(t *testing.T) {
+	if s := (ObjectNameFilter{}).String(); s != "" {
+		t.Errorf("String() of empty filter got=%q, want empty", s)
+	}
+	f := ObjectNameFilter{Include: []string{"**.jpg"}, Exclude: []string{"tmp/**"}}
+	if s, want := f.String(), `{"include":["**.jpg"],"exclude":["tmp/**"]}`; s != want {
+		t.Errorf("String() got=%q, want=%q", s, want)
+	}
+}
+
+func TestParseObjectNameFilter(t *testing.T) {
+	if f, err := ParseObjectNameFilter(""); f != nil || err != nil {
+		t.Errorf("ParseObjectNameFilter(\"\") got=(%v, %v), want=(nil, nil)", f, err)
+	}
+	for _, s := range []string{"**.jpg", `{"include":[""]}`} {
+		if _, err := ParseObjectNameFilter(s); err == nil {
+			t.Errorf("ParseObjectNameFilter(%q) got no error", s)
+		}
+	}
+}
+
+func TestObjectNameFilteringConverter(t *testing.T) {
+	filter, err := ParseObjectNameFilter(ObjectNameFilter{Include: []string{"*.jpg"}, Exclude: []string{"tmp*"}}.String())
+	if err != nil {
+		t.Fatalf("ParseObjectNameFilter got error: %v", err)
+	}
+	converter := NewObjectNameFilteringConverter(NewPubSubConverter(), filter)
+
+	tests := []struct {
+		name          string
+		objectID      string
+		converterType ConverterType
+		wantFiltered  bool
+	}{{
+		name:          "included",
+		objectID:      objectId,
+		converterType: CloudStorage,
+	}, {
+		name:          "not included",
+		objectID:      "myfile.png",
+		converterType: CloudStorage,
+		wantFiltered:  true,
+	}, {
+		name:          "excluded",
+		objectID:      "tmp.jpg",
+		converterType: CloudStorage,
+		wantFiltered:  true,
+	}, {
+		name:          "not storage",
+		objectID:      "myfile.png",
+		converterType: CloudPubSub,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			msg := &pubsub.Message{
+				ID:   "id",
+				Data: []byte("test data"),
+				Attributes: map[string]string{
+					"bucketId":  bucket,
+					"objectId":  test.objectID,
+					"eventType": eventType,
+				},
+			}
+			_, err := converter.Convert(context.Background(), msg, test.converterType)
+			if gotFiltered := err == ErrFiltered; gotFiltered != test.wantFiltered {
+				t.Errorf("Convert got error %v, want filtered=%v", err, test.wantFiltered)
+			}
+			// Other types of messages aren't filtered, regardless of whether they convert.
+			if test.converterType == CloudStorage && err != nil && err != ErrFiltered {
+				t.Errorf("Convert got unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 
+	v1 "github.com/google/knative-gcp/pkg/apis/events/v1"
 	"github.com/google/knative-gcp/pkg/apis/events/v1beta1"
 	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
 
@@ -40,6 +41,13 @@ func convertCloudScheduler(ctx context.Context, msg *pubsub.Message) (*cev2.Even
 	}
 	event.SetSource(schemasv1.CloudSchedulerEventSource(jobName))
 
+	// The other attributes are the custom attributes of the job, which become extensions.
+	for name, value := range msg.Attributes {
+		if name != v1beta1.CloudSchedulerSourceJobName && v1.IsExtensionName(name) {
+			event.SetExtension(name, value)
+		}
+	}
+
 	if err := event.SetData(cev2.ApplicationJSON, &schemasv1.SchedulerJobData{CustomData: msg.Data}); err != nil {
 		return nil, err
 	}
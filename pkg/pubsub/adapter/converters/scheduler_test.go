@@ -49,6 +49,22 @@ func TestConvertCloudSchedulerSource(t *testing.T) {
 				"attribute2":    "value2",
 			},
 		},
+		wantEventFn: func() *cev2.Event {
+			e := schedulerCloudEvent("//cloudscheduler.googleapis.com/projects/knative-gcp-test/locations/us-east4/jobs/cre-scheduler-test")
+			e.SetExtension("attribute1", "value1")
+			e.SetExtension("attribute2", "value2")
+			return e
+		},
+	}, {
+		name: "reserved attributes",
+		message: &pubsub.Message{
+			ID:   "id",
+			Data: []byte("test data"),
+			Attributes: map[string]string{
+				"jobName": "projects/knative-gcp-test/locations/us-east4/jobs/cre-scheduler-test",
+				"source":  "other",
+			},
+		},
 		wantEventFn: func() *cev2.Event {
 			return schedulerCloudEvent("//cloudscheduler.googleapis.com/projects/knative-gcp-test/locations/us-east4/jobs/cre-scheduler-test")
 		},
@@ -0,0 +1,83 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converters
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/pubsub"
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
+)
+
+// DataValidation is the mode of validation of the data of the converted events against the
+// schema of their type.
+type DataValidation string
+
+const (
+	// NoDataValidation doesn't validate the data of the events.
+	NoDataValidation DataValidation = ""
+	// FlagDataValidation sets the schemasv1.DataSchemaErrorExtension extension of the events
+	// whose data doesn't match the schema to the validation error.
+	FlagDataValidation DataValidation = "flag"
+	// RejectDataValidation fails the conversion of the messages whose data doesn't match the
+	// schema.
+	RejectDataValidation DataValidation = "reject"
+)
+
+// ParseDataValidation parses a data validation mode.
+func ParseDataValidation(s string) (DataValidation, error) {
+	switch v := DataValidation(s); v {
+	case NoDataValidation, FlagDataValidation, RejectDataValidation:
+		return v, nil
+	default:
+		return NoDataValidation, fmt.Errorf("unknown data validation %q", s)
+	}
+}
+
+type validatingConverter struct {
+	converter  Converter
+	validation DataValidation
+}
+
+// NewValidatingConverter returns a converter validating the data of the events converted by the
+// given converter, with the schemas of pkg/schemas/v1. The data of the events of types without a
+// schema is not validated.
+func NewValidatingConverter(converter Converter, validation DataValidation) Converter {
+	if validation == NoDataValidation {
+		return converter
+	}
+	return &validatingConverter{
+		converter:  converter,
+		validation: validation,
+	}
+}
+
+func (c *validatingConverter) Convert(ctx context.Context, msg *pubsub.Message, converterType ConverterType) (*cev2.Event, error) {
+	event, err := c.converter.Convert(ctx, msg, converterType)
+	if err != nil {
+		return nil, err
+	}
+	if err := schemasv1.ValidateEventData(event.Type(), event.Data()); err != nil {
+		if c.validation == RejectDataValidation {
+			return nil, fmt.Errorf("data of %s event does not match its schema: %w", event.Type(), err)
+		}
+		event.SetExtension(schemasv1.DataSchemaErrorExtension, err.Error())
+	}
+	return event, nil
+}
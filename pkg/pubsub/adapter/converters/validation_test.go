@@ -0,0 +1,98 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converters
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
+)
+
+func TestParseDataValidation(t *testing.T) {
+	for _, s := range []string{"", "flag", "reject"} {
+		if v, err := ParseDataValidation(s); err != nil || string(v) != s {
+			t.Errorf("ParseDataValidation(%q) got=%q, %v", s, v, err)
+		}
+	}
+	if _, err := ParseDataValidation("ignore"); err == nil {
+		t.Errorf("ParseDataValidation got no error for an unknown validation")
+	}
+}
+
+func TestValidatingConverter(t *testing.T) {
+	attributes := map[string]string{
+		"bucketId":  bucket,
+		"objectId":  objectId,
+		"eventType": eventType,
+	}
+	validData := []byte(`{"kind": "storage#object", "id": "my-bucket/myfile.jpg/1", "name": "myfile.jpg", "bucket": "my-bucket"}`)
+	invalidData := []byte(`{"kind": "storage#object", "id": "my-bucket/myfile.jpg/1", "name": "myfile.jpg"}`)
+
+	tests := []struct {
+		name       string
+		validation DataValidation
+		data       []byte
+		wantErr    bool
+		wantFlag   bool
+	}{{
+		name:       "no validation",
+		validation: NoDataValidation,
+		data:       invalidData,
+	}, {
+		name:       "flag valid data",
+		validation: FlagDataValidation,
+		data:       validData,
+	}, {
+		name:       "flag invalid data",
+		validation: FlagDataValidation,
+		data:       invalidData,
+		wantFlag:   true,
+	}, {
+		name:       "reject valid data",
+		validation: RejectDataValidation,
+		data:       validData,
+	}, {
+		name:       "reject invalid data",
+		validation: RejectDataValidation,
+		data:       []byte("test data"),
+		wantErr:    true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			msg := &pubsub.Message{
+				ID:         "id",
+				Data:       test.data,
+				Attributes: attributes,
+			}
+			converter := NewValidatingConverter(NewPubSubConverter(), test.validation)
+			gotEvent, err := converter.Convert(context.Background(), msg, CloudStorage)
+			if test.wantErr != (err != nil) {
+				t.Fatalf("Convert got error %v want error=%v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			_, gotFlag := gotEvent.Extensions()[schemasv1.DataSchemaErrorExtension]
+			if gotFlag != test.wantFlag {
+				t.Errorf("Extension %s present=%v, want %v", schemasv1.DataSchemaErrorExtension, gotFlag, test.wantFlag)
+			}
+		})
+	}
+}
@@ -21,6 +21,8 @@ package broker
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/google/knative-gcp/pkg/apis/configs/dataresidency"
 
@@ -29,7 +31,9 @@ import (
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/system"
 
 	brokerv1beta1 "github.com/google/knative-gcp/pkg/apis/broker/v1beta1"
 	brokerreconciler "github.com/google/knative-gcp/pkg/client/injection/reconciler/broker/v1beta1/broker"
@@ -38,6 +42,7 @@ import (
 	"github.com/google/knative-gcp/pkg/reconciler"
 	"github.com/google/knative-gcp/pkg/reconciler/broker/resources"
 	reconcilerutilspubsub "github.com/google/knative-gcp/pkg/reconciler/utils/pubsub"
+	reconcilerutilsredis "github.com/google/knative-gcp/pkg/reconciler/utils/redis"
 	"github.com/google/knative-gcp/pkg/utils"
 )
 
@@ -46,6 +51,14 @@ const (
 	brokerReconciled  = "BrokerReconciled"
 	brokerFinalized   = "BrokerFinalized"
 	brokerCellCreated = "BrokerCellCreated"
+
+	// priorityClassDrainPeriod is how long the subscription of a priority class removed from a
+	// broker is still fanned out.
+	priorityClassDrainPeriod = time.Hour
+	// priorityClassAckPeriod is how long the subscription of a drained priority class is kept
+	// once it is no longer fanned out, so that the events being delivered can be acknowledged. It
+	// exceeds the maximum delivery time of an event by the fanout.
+	priorityClassAckPeriod = 15 * time.Minute
 )
 
 type Reconciler struct {
@@ -59,6 +72,10 @@ type Reconciler struct {
 	// pubsubClient is used as the Pubsub client when present.
 	pubsubClient *pubsub.Client
 
+	// newRedisQueueDeleterFn connects to the Redis instance of the BrokerCell
+	// when its queues are Redis streams.
+	newRedisQueueDeleterFn reconcilerutilsredis.QueueDeleterFn
+
 	dataresidencyStore *dataresidency.Store
 }
 
@@ -86,6 +103,9 @@ func (r *Reconciler) FinalizeKind(ctx context.Context, b *brokerv1beta1.Broker)
 	if err := r.deleteDecouplingTopicAndSubscription(ctx, b); err != nil {
 		return fmt.Errorf("failed to delete Pub/Sub topic: %v", err)
 	}
+	if err := r.deleteRedisQueues(ctx, b); err != nil {
+		return fmt.Errorf("failed to delete Redis streams: %v", err)
+	}
 
 	return pkgreconciler.NewEvent(corev1.EventTypeNormal, brokerFinalized, "Broker finalized: \"%s/%s\"", b.Namespace, b.Name)
 }
@@ -146,8 +166,55 @@ func (r *Reconciler) reconcileDecouplingTopicAndSubscription(ctx context.Context
 		//TODO add resource labels, but need to be sanitized: https://cloud.google.com/pubsub/docs/labels#requirements
 	}
 
+	sub, err := r.reconcileTopicAndSubscription(ctx, pubsubReconciler, b, labels,
+		resources.GenerateDecouplingTopicName(b), resources.GenerateDecouplingSubscriptionName(b))
+	if err != nil {
+		return err
+	}
+	subs := []*pubsub.Subscription{sub}
+
+	// Each priority class has its own topic and subscription.
+	var classes []string
+	if pc := b.PriorityClasses(); pc != nil {
+		for _, c := range pc.Classes {
+			sub, err := r.reconcileTopicAndSubscription(ctx, pubsubReconciler, b, labels,
+				resources.GeneratePriorityClassTopicName(b, c.Name), resources.GeneratePriorityClassSubscriptionName(b, c.Name))
+			if err != nil {
+				return fmt.Errorf("failed to reconcile priority class %q: %w", c.Name, err)
+			}
+			subs = append(subs, sub)
+			classes = append(classes, c.Name)
+		}
+	}
+	// The removed priority classes are drained before their topics and subscriptions are deleted.
+	draining, err := r.drainPriorityClasses(ctx, pubsubReconciler, b, classes, time.Now())
+	if err != nil {
+		return err
+	}
+	b.Status.MarkPriorityClasses(append(classes, draining...))
+
+	// Seek the subscriptions when the replay time is set or changed.
+	if replayTime, ok := b.ReplayTime(); ok {
+		if replayed, ok := b.Status.ReplayedTime(); !ok || !replayed.Equal(replayTime) {
+			for _, sub := range subs {
+				if err := pubsubReconciler.SeekSubscription(ctx, sub, replayTime, b); err != nil {
+					return fmt.Errorf("failed to replay the events since %v: %w", replayTime, err)
+				}
+			}
+			b.Status.MarkReplayed(replayTime)
+		}
+	}
+
+	// TODO(grantr): this isn't actually persisted due to webhook issues.
+	//TODO uncomment when eventing webhook allows this
+	//b.Status.SubscriptionID = sub.ID()
+
+	return nil
+}
+
+// reconcileTopicAndSubscription reconciles a decoupling topic of the broker and its subscription.
+func (r *Reconciler) reconcileTopicAndSubscription(ctx context.Context, pubsubReconciler *reconcilerutilspubsub.Reconciler, b *brokerv1beta1.Broker, labels map[string]string, topicID, subID string) (*pubsub.Subscription, error) {
 	// Check if topic exists, and if not, create it.
-	topicID := resources.GenerateDecouplingTopicName(b)
 	topicConfig := &pubsub.TopicConfig{Labels: labels}
 	if r.dataresidencyStore != nil {
 		if dataresidencyConfig := r.dataresidencyStore.Load(); dataresidencyConfig != nil {
@@ -158,30 +225,96 @@ func (r *Reconciler) reconcileDecouplingTopicAndSubscription(ctx context.Context
 	}
 	topic, err := pubsubReconciler.ReconcileTopic(ctx, topicID, topicConfig, b, &b.Status)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// TODO(grantr): this isn't actually persisted due to webhook issues.
 	//TODO uncomment when eventing webhook allows this
 	//b.Status.TopicID = topic.ID()
 
 	// Check if PullSub exists, and if not, create it.
-	subID := resources.GenerateDecouplingSubscriptionName(b)
 	subConfig := pubsub.SubscriptionConfig{
 		Topic:  topic,
 		Labels: labels,
+		// The ordering of an existing subscription can't be changed, which is why the ordering
+		// annotation is immutable.
+		EnableMessageOrdering: b.IsOrdered(),
+		// Acknowledged events are retained so that they can be replayed.
+		RetainAckedMessages: b.ReplayRetention() != 0,
+		RetentionDuration:   b.ReplayRetention(),
 		//TODO(grantr): configure these settings?
 		// AckDeadline
-		// RetentionDuration
 	}
-	if _, err := pubsubReconciler.ReconcileSubscription(ctx, subID, subConfig, b, &b.Status); err != nil {
-		return err
+	return pubsubReconciler.ReconcileSubscription(ctx, subID, subConfig, b, &b.Status)
+}
+
+// removedPriorityClasses returns the priority classes of reconciled that are not in classes.
+func removedPriorityClasses(reconciled, classes []string) []string {
+	current := make(map[string]bool, len(classes))
+	for _, c := range classes {
+		current[c] = true
+	}
+	var removed []string
+	for _, c := range reconciled {
+		if !current[c] {
+			removed = append(removed, c)
+		}
 	}
+	return removed
+}
 
-	// TODO(grantr): this isn't actually persisted due to webhook issues.
-	//TODO uncomment when eventing webhook allows this
-	//b.Status.SubscriptionID = sub.ID()
+// drainPriorityClasses records when the priority classes of the broker were removed, deletes the
+// decoupling topics and subscriptions of the ones removed for longer than
+// priorityClassDrainPeriod and priorityClassAckPeriod, and returns the ones still fanned out.
+// The events of a removed class are no longer published to its topic, but its subscription is
+// still pulled by the fanout for priorityClassDrainPeriod. It is then kept for
+// priorityClassAckPeriod, so that the events the fanout was delivering can still be
+// acknowledged. The broker is reconciled again on resync.
+func (r *Reconciler) drainPriorityClasses(ctx context.Context, pubsubReconciler *reconcilerutilspubsub.Reconciler, b *brokerv1beta1.Broker, classes []string, now time.Time) ([]string, error) {
+	removed := b.Status.DrainingPriorityClasses()
+	if removed == nil {
+		removed = make(map[string]time.Time)
+	}
+	for _, c := range removedPriorityClasses(b.Status.PriorityClasses(), classes) {
+		if _, ok := removed[c]; !ok {
+			removed[c] = now
+		}
+	}
+	// A class added back is no longer draining.
+	for _, c := range classes {
+		delete(removed, c)
+	}
+	var draining, drained []string
+	for c, t := range removed {
+		switch {
+		case now.Before(t.Add(priorityClassDrainPeriod)):
+			logging.FromContext(ctx).Info("Priority class is draining", zap.String("class", c))
+			draining = append(draining, c)
+		case now.Before(t.Add(priorityClassDrainPeriod + priorityClassAckPeriod)):
+			// No longer fanned out, but the events being delivered can still be acknowledged.
+		default:
+			drained = append(drained, c)
+		}
+	}
+	if err := r.deletePriorityClasses(ctx, pubsubReconciler, b, drained); err != nil {
+		return nil, err
+	}
+	for _, c := range drained {
+		delete(removed, c)
+	}
+	b.Status.MarkDrainingPriorityClasses(removed)
+	sort.Strings(draining)
+	return draining, nil
+}
 
-	return nil
+// deletePriorityClasses deletes the decoupling topics and subscriptions of priority classes of the
+// broker.
+func (r *Reconciler) deletePriorityClasses(ctx context.Context, pubsubReconciler *reconcilerutilspubsub.Reconciler, b *brokerv1beta1.Broker, classes []string) error {
+	var err error
+	for _, c := range classes {
+		err = multierr.Append(err, pubsubReconciler.DeleteTopic(ctx, resources.GeneratePriorityClassTopicName(b, c), b, &b.Status))
+		err = multierr.Append(err, pubsubReconciler.DeleteSubscription(ctx, resources.GeneratePriorityClassSubscriptionName(b, c), b, &b.Status))
+	}
+	return err
 }
 
 func (r *Reconciler) deleteDecouplingTopicAndSubscription(ctx context.Context, b *brokerv1beta1.Broker) error {
@@ -217,5 +350,50 @@ func (r *Reconciler) deleteDecouplingTopicAndSubscription(ctx context.Context, b
 	subID := resources.GenerateDecouplingSubscriptionName(b)
 	err = multierr.Append(err, pubsubReconciler.DeleteSubscription(ctx, subID, b, &b.Status))
 
+	// Delete the topics and subscriptions of the priority classes, including the ones removed
+	// from the broker before they could be deleted.
+	err = multierr.Append(err, r.deletePriorityClasses(ctx, pubsubReconciler, b, finalizedPriorityClasses(b)))
+
 	return err
 }
+
+// finalizedPriorityClasses returns the priority classes of the broker, including the ones
+// removed from the broker before their topics and subscriptions could be deleted.
+func finalizedPriorityClasses(b *brokerv1beta1.Broker) []string {
+	var classes []string
+	if pc := b.PriorityClasses(); pc != nil {
+		for _, c := range pc.Classes {
+			classes = append(classes, c.Name)
+		}
+	}
+	classes = append(classes, removedPriorityClasses(b.Status.PriorityClasses(), classes)...)
+	for c := range b.Status.DrainingPriorityClasses() {
+		classes = append(classes, removedPriorityClasses([]string{c}, classes)...)
+	}
+	return classes
+}
+
+// deleteRedisQueues deletes the streams and consumer groups standing for the decoupling topics
+// and subscriptions of the broker, when the queues of the BrokerCell are Redis streams.
+func (r *Reconciler) deleteRedisQueues(ctx context.Context, b *brokerv1beta1.Broker) error {
+	bc, err := r.brokerCellLister.BrokerCells(system.Namespace()).Get(resources.DefaultBrokerCellName)
+	if apierrs.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	d, err := r.newRedisQueueDeleterFn(ctx, bc)
+	if d == nil || err != nil {
+		return err
+	}
+	defer d.Close()
+
+	queues := map[string]string{
+		resources.GenerateDecouplingTopicName(b): resources.GenerateDecouplingSubscriptionName(b),
+	}
+	for _, c := range finalizedPriorityClasses(b) {
+		queues[resources.GeneratePriorityClassTopicName(b, c)] = resources.GeneratePriorityClassSubscriptionName(b, c)
+	}
+	return reconcilerutilsredis.DeleteQueues(ctx, d, queues)
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/pubsub"
 	"github.com/google/knative-gcp/pkg/broker/ingress"
@@ -46,6 +47,9 @@ import (
 	"github.com/google/knative-gcp/pkg/reconciler/broker/resources"
 	brokercellresources "github.com/google/knative-gcp/pkg/reconciler/brokercell/resources"
 	. "github.com/google/knative-gcp/pkg/reconciler/testing"
+	reconcilerutilsredis "github.com/google/knative-gcp/pkg/reconciler/utils/redis"
+	"github.com/google/knative-gcp/pkg/redis"
+	redistesting "github.com/google/knative-gcp/pkg/redis/testing"
 )
 
 const (
@@ -67,9 +71,12 @@ var (
 
 	testKey = fmt.Sprintf("%s/%s", testNS, brokerName)
 
+	replayTime = time.Date(2020, 9, 1, 10, 0, 0, 0, time.UTC)
+
 	brokerFinalizerUpdatedEvent = Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "test-broker" finalizers`)
 	brokerReconciledEvent       = Eventf(corev1.EventTypeNormal, "BrokerReconciled", `Broker reconciled: "testnamespace/test-broker"`)
 	brokerFinalizedEvent        = Eventf(corev1.EventTypeNormal, "BrokerFinalized", `Broker finalized: "testnamespace/test-broker"`)
+	brokerSeekedEvent           = Eventf(corev1.EventTypeNormal, "SubscriptionSeeked", `Seeked PubSub subscription "cre-bkr_testnamespace_test-broker_abc123" to 2020-09-01T10:00:00Z`)
 	ingressServiceName          = brokercellresources.Name(resources.DefaultBrokerCellName, brokercellresources.IngressName)
 
 	brokerAddress = &apis.URL{
@@ -96,6 +103,8 @@ func init() {
 }
 
 func TestAllCases(t *testing.T) {
+	redisSrv := newRedisQueue(t, "cre-bkr_testnamespace_test-broker_abc123", "cre-bkr_testnamespace_test-broker_abc123")
+
 	table := TableTest{{
 		Name: "bad workqueue key",
 		Key:  "too/many/parts",
@@ -149,6 +158,35 @@ func TestAllCases(t *testing.T) {
 			NoTopicsExist(),
 			NoSubscriptionsExist(),
 		},
+	}, {
+		Name: "Broker is being deleted, Redis stream and group exist",
+		Key:  testKey,
+		Objects: []runtime.Object{
+			NewBroker(brokerName, testNS,
+				WithBrokerClass(brokerv1beta1.BrokerClass),
+				WithBrokerUID(testUID),
+				WithInitBrokerConditions,
+				WithBrokerDeletionTimestamp,
+				WithBrokerSetDefaults,
+			),
+			NewBrokerCell(resources.DefaultBrokerCellName, systemNS,
+				WithBrokerCellAnnotations(map[string]string{
+					brokercellresources.DecoupleQueueAnnotationKey:   "redis",
+					brokercellresources.RedisConnectionAnnotationKey: fmt.Sprintf(`{"address":%q}`, redisSrv.Addr()),
+				}),
+			),
+		},
+		WantEvents: []string{
+			brokerFinalizedEvent,
+		},
+		OtherTestData: map[string]interface{}{
+			"pre": []PubsubAction{},
+		},
+		PostConditions: []func(*testing.T, *TableRow){
+			NoTopicsExist(),
+			NoSubscriptionsExist(),
+			redisStreamDeleted(redisSrv, "cre-bkr_testnamespace_test-broker_abc123"),
+		},
 	}, {
 		Name: "Create broker with ready brokercell, broker is created",
 		Key:  testKey,
@@ -187,6 +225,83 @@ func TestAllCases(t *testing.T) {
 			TopicExists("cre-bkr_testnamespace_test-broker_abc123"),
 			SubscriptionExists("cre-bkr_testnamespace_test-broker_abc123"),
 		},
+	}, {
+		Name: "Replay time set, broker subscription is seeked",
+		Key:  testKey,
+		Objects: []runtime.Object{
+			NewBroker(brokerName, testNS,
+				WithBrokerClass(brokerv1beta1.BrokerClass),
+				WithBrokerUID(testUID),
+				WithBrokerAnnotation(brokerv1beta1.ReplayTimeAnnotationKey, replayTime.Format(time.RFC3339)),
+				WithBrokerDeliverySpec(brokerDeliverySpec),
+				WithBrokerSetDefaults),
+			NewBrokerCell(resources.DefaultBrokerCellName, systemNS,
+				WithBrokerCellReady,
+				WithBrokerCellSetDefaults),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: NewBroker(brokerName, testNS,
+				WithBrokerClass(brokerv1beta1.BrokerClass),
+				WithBrokerUID(testUID),
+				WithBrokerAnnotation(brokerv1beta1.ReplayTimeAnnotationKey, replayTime.Format(time.RFC3339)),
+				WithBrokerDeliverySpec(brokerDeliverySpec),
+				WithBrokerReadyURI(brokerAddress),
+				WithBrokerReplayed(replayTime),
+				WithBrokerSetDefaults,
+			),
+		}},
+		WantEvents: []string{
+			brokerFinalizerUpdatedEvent,
+			Eventf(corev1.EventTypeNormal, "TopicCreated", `Created PubSub topic "cre-bkr_testnamespace_test-broker_abc123"`),
+			Eventf(corev1.EventTypeNormal, "SubscriptionCreated", `Created PubSub subscription "cre-bkr_testnamespace_test-broker_abc123"`),
+			brokerSeekedEvent,
+			brokerReconciledEvent,
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, brokerName, brokerFinalizerName),
+		},
+		OtherTestData: map[string]interface{}{
+			"pre": []PubsubAction{},
+		},
+		PostConditions: []func(*testing.T, *TableRow){
+			SubscriptionExists("cre-bkr_testnamespace_test-broker_abc123"),
+		},
+	}, {
+		Name: "Replay time already replayed, broker subscription isn't seeked again",
+		Key:  testKey,
+		Objects: []runtime.Object{
+			NewBroker(brokerName, testNS,
+				WithBrokerClass(brokerv1beta1.BrokerClass),
+				WithBrokerUID(testUID),
+				WithBrokerAnnotation(brokerv1beta1.ReplayTimeAnnotationKey, replayTime.Format(time.RFC3339)),
+				WithBrokerDeliverySpec(brokerDeliverySpec),
+				WithBrokerReplayed(replayTime),
+				WithBrokerFinalizers(brokerFinalizerName),
+				WithBrokerSetDefaults),
+			NewBrokerCell(resources.DefaultBrokerCellName, systemNS,
+				WithBrokerCellReady,
+				WithBrokerCellSetDefaults),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: NewBroker(brokerName, testNS,
+				WithBrokerClass(brokerv1beta1.BrokerClass),
+				WithBrokerUID(testUID),
+				WithBrokerAnnotation(brokerv1beta1.ReplayTimeAnnotationKey, replayTime.Format(time.RFC3339)),
+				WithBrokerDeliverySpec(brokerDeliverySpec),
+				WithBrokerReplayed(replayTime),
+				WithBrokerFinalizers(brokerFinalizerName),
+				WithBrokerReadyURI(brokerAddress),
+				WithBrokerSetDefaults,
+			),
+		}},
+		WantEvents: []string{
+			brokerReconciledEvent,
+		},
+		OtherTestData: map[string]interface{}{
+			"pre": []PubsubAction{
+				TopicAndSub("cre-bkr_testnamespace_test-broker_abc123", "cre-bkr_testnamespace_test-broker_abc123"),
+			},
+		},
 	}, {
 		Name: "Create broker with unready brokercell, broker is created",
 		Key:  testKey,
@@ -379,16 +494,42 @@ func TestAllCases(t *testing.T) {
 		ctx = addressable.WithDuck(ctx)
 		ctx = resource.WithDuck(ctx)
 		r := &Reconciler{
-			Base:               reconciler.NewBase(ctx, controllerAgentName, cmw),
-			brokerCellLister:   listers.GetBrokerCellLister(),
-			projectID:          testProject,
-			pubsubClient:       psclient,
-			dataresidencyStore: drStore,
+			Base:                   reconciler.NewBase(ctx, controllerAgentName, cmw),
+			brokerCellLister:       listers.GetBrokerCellLister(),
+			newRedisQueueDeleterFn: reconcilerutilsredis.NewQueueDeleterFn(listers.GetSecretLister()),
+			projectID:              testProject,
+			pubsubClient:           psclient,
+			dataresidencyStore:     drStore,
 		}
 		return brokerreconciler.NewReconciler(ctx, r.Logger, r.RunClientSet, listers.GetBrokerLister(), r.Recorder, r, brokerv1beta1.BrokerClass)
 	}))
 }
 
+// newRedisQueue starts a Redis server holding the stream and consumer group of a queue.
+func newRedisQueue(t *testing.T, stream, group string) *redistesting.Server {
+	t.Helper()
+	srv, err := redistesting.NewServer("")
+	if err != nil {
+		t.Fatalf("Failed to start Redis server: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	c := redis.NewClient(redis.Options{Address: srv.Addr()})
+	defer c.Close()
+	if err := c.CreateGroup(context.Background(), stream, group, redis.LastMessage); err != nil {
+		t.Fatalf("Failed to create consumer group: %v", err)
+	}
+	srv.Add(stream, "event", "{}")
+	return srv
+}
+
+func redisStreamDeleted(srv *redistesting.Server, stream string) func(*testing.T, *TableRow) {
+	return func(t *testing.T, _ *TableRow) {
+		if entries := srv.Entries(stream); entries != nil {
+			t.Errorf("Stream %q still has entries %v", stream, entries)
+		}
+	}
+}
+
 func patchFinalizers(namespace, name, finalizer string) clientgotesting.PatchActionImpl {
 	action := clientgotesting.PatchActionImpl{}
 	action.Name = name
@@ -29,6 +29,7 @@ import (
 
 	"github.com/google/knative-gcp/pkg/logging"
 	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	secretinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/secret"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/injection"
@@ -41,6 +42,7 @@ import (
 	brokerreconciler "github.com/google/knative-gcp/pkg/client/injection/reconciler/broker/v1beta1/broker"
 	metadataClient "github.com/google/knative-gcp/pkg/gclient/metadata"
 	"github.com/google/knative-gcp/pkg/reconciler"
+	reconcilerutilsredis "github.com/google/knative-gcp/pkg/reconciler/utils/redis"
 	"github.com/google/knative-gcp/pkg/utils"
 )
 
@@ -85,10 +87,11 @@ func newController(ctx context.Context, cmw configmap.Watcher, drs *dataresidenc
 	}
 
 	r := &Reconciler{
-		Base:               reconciler.NewBase(ctx, controllerAgentName, cmw),
-		brokerCellLister:   bcInformer.Lister(),
-		pubsubClient:       client,
-		dataresidencyStore: drs,
+		Base:                   reconciler.NewBase(ctx, controllerAgentName, cmw),
+		brokerCellLister:       bcInformer.Lister(),
+		newRedisQueueDeleterFn: reconcilerutilsredis.NewQueueDeleterFn(secretinformer.Get(ctx).Lister()),
+		pubsubClient:           client,
+		dataresidencyStore:     drs,
 	}
 
 	impl := brokerreconciler.NewImpl(ctx, r, brokerv1beta1.BrokerClass)
@@ -47,6 +47,22 @@ func GenerateDecouplingSubscriptionName(b *brokerv1beta1.Broker) string {
 	return naming.TruncatedPubsubResourceName("cre-bkr", b.Namespace, b.Name, b.UID)
 }
 
+// GeneratePriorityClassTopicName generates a deterministic decoupling topic
+// name for a priority class of a Broker. The class is part of the prefix, so
+// that it is never truncated. If the topic name would be longer than allowed by
+// PubSub, the Broker name is truncated to fit.
+func GeneratePriorityClassTopicName(b *brokerv1beta1.Broker, class string) string {
+	return naming.TruncatedPubsubResourceName("cre-bkr-"+class, b.Namespace, b.Name, b.UID)
+}
+
+// GeneratePriorityClassSubscriptionName generates a deterministic decoupling
+// subscription name for a priority class of a Broker. The class is part of the
+// prefix, so that it is never truncated. If the subscription name would be
+// longer than allowed by PubSub, the Broker name is truncated to fit.
+func GeneratePriorityClassSubscriptionName(b *brokerv1beta1.Broker, class string) string {
+	return naming.TruncatedPubsubResourceName("cre-bkr-"+class, b.Namespace, b.Name, b.UID)
+}
+
 // GenerateRetryTopicName generates a deterministic topic name for a Trigger.
 // If the topic name would be longer than allowed by PubSub, the Trigger name is
 // truncated to fit.
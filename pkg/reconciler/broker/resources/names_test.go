@@ -115,6 +115,46 @@ func TestGenerateDecouplingSubscriptionName(t *testing.T) {
 	}
 }
 
+func TestGeneratePriorityClassTopicAndSubscriptionName(t *testing.T) {
+	testCases := []struct {
+		ns    string
+		n     string
+		class string
+		uid   string
+		want  string
+	}{{
+		ns:    "default",
+		n:     "default",
+		class: "bulk",
+		uid:   testUID,
+		want:  fmt.Sprintf("cre-bkr-bulk_default_default_%s", testUID),
+	}, {
+		ns:    "with-dashes",
+		n:     "more-dashes",
+		class: "urgent",
+		uid:   testUID,
+		want:  fmt.Sprintf("cre-bkr-urgent_with-dashes_more-dashes_%s", testUID),
+	}, {
+		ns:    maxNamespace,
+		n:     maxName,
+		class: "bulk",
+		uid:   testUID,
+		want:  fmt.Sprintf("cre-bkr-bulk_%s_%s_%s", maxNamespace, strings.Repeat("n", truncatedNameMax-len("-bulk")), testUID),
+	}}
+
+	for _, tc := range testCases {
+		b := broker(tc.ns, tc.n, tc.uid)
+		for _, got := range []string{GeneratePriorityClassTopicName(b, tc.class), GeneratePriorityClassSubscriptionName(b, tc.class)} {
+			if len(got) > naming.PubsubMax {
+				t.Errorf("name length %d is greater than %d", len(got), naming.PubsubMax)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected (-want, +got) = %v", diff)
+			}
+		}
+	}
+}
+
 func TestGenerateRetryTopicName(t *testing.T) {
 	testCases := []struct {
 		ns   string
@@ -19,12 +19,16 @@ package brokercell
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/knative-gcp/pkg/logging"
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
+	eventingduckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
 	"knative.dev/eventing/pkg/apis/eventing"
+	pkgreconciler "knative.dev/pkg/reconciler"
 
 	brokerv1beta1 "github.com/google/knative-gcp/pkg/apis/broker/v1beta1"
 	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
@@ -36,10 +40,17 @@ import (
 )
 
 const (
-	configFailed = "BrokerTargetsConfigFailed"
+	configFailed      = "BrokerTargetsConfigFailed"
+	rateLimitsInvalid = "IngressRateLimitsInvalid"
 )
 
 func (r *Reconciler) reconcileConfig(ctx context.Context, bc *intv1alpha1.BrokerCell) error {
+	rateLimits, err := resources.GetIngressRateLimits(bc)
+	if err != nil {
+		logging.FromContext(ctx).Error("Invalid ingress rate limits", zap.Error(err))
+		bc.Status.MarkTargetsConfigFailed(rateLimitsInvalid, "Invalid ingress rate limits: %v", err)
+		return pkgreconciler.NewEvent(corev1.EventTypeWarning, rateLimitsInvalid, "Invalid ingress rate limits: %v", err)
+	}
 	// TODO(#866) Only select brokers that point to this brokercell by label selector once the
 	// webhook assigns the brokercell label, i.e.,
 	// r.brokerLister.List(labels.SelectorFromSet(map[string]string{"brokercell":bc.Name, "brokercellns":bc.Namespace}))
@@ -53,6 +64,11 @@ func (r *Reconciler) reconcileConfig(ctx context.Context, bc *intv1alpha1.Broker
 	// however not efficient if there are too many triggers. If performance becomes an issue, we can consider
 	// maintaining 2 queues for updated brokers and triggers, and only update the config for updated brokers/triggers.
 	brokerTargets := memory.NewEmptyTargets()
+	// The addresses of the brokers, to resolve the brokers the triggers send their replies to.
+	brokerAddresses := make(map[string]string, len(brokers))
+	for _, broker := range brokers {
+		brokerAddresses[config.BrokerKey(broker.Namespace, broker.Name)] = broker.Status.Address.URL.String()
+	}
 	for _, broker := range brokers {
 		// Filter by `eventing.knative.dev/broker: <name>` here
 		// to get only the triggers for this broker. The trigger webhook will
@@ -63,7 +79,7 @@ func (r *Reconciler) reconcileConfig(ctx context.Context, bc *intv1alpha1.Broker
 			bc.Status.MarkTargetsConfigFailed(configFailed, "failed to list triggers for broker %v: %v", broker.Name, err)
 			return err
 		}
-		r.addToConfig(ctx, broker, triggers, brokerTargets)
+		r.addToConfig(ctx, broker, triggers, rateLimits, brokerAddresses, brokerTargets)
 	}
 	if err := r.updateTargetsConfig(ctx, bc, brokerTargets); err != nil {
 		logging.FromContext(ctx).Error("Failed to update broker targets configmap", zap.Error(err))
@@ -75,7 +91,7 @@ func (r *Reconciler) reconcileConfig(ctx context.Context, bc *intv1alpha1.Broker
 }
 
 // addToConfig reconstructs the data entry for the given broker and add it to targets-config.
-func (r *Reconciler) addToConfig(ctx context.Context, b *brokerv1beta1.Broker, triggers []*brokerv1beta1.Trigger, brokerTargets config.Targets) {
+func (r *Reconciler) addToConfig(ctx context.Context, b *brokerv1beta1.Broker, triggers []*brokerv1beta1.Trigger, rateLimits *resources.IngressRateLimits, brokerAddresses map[string]string, brokerTargets config.Targets) {
 	// TODO Maybe get rid of BrokerMutation and add Delete() and Upsert(broker) methods to TargetsConfig. Now we always
 	//  delete or update the entire broker entry and we don't need partial updates per trigger.
 	// The code can be simplified to r.targetsConfig.Upsert(brokerConfigEntry)
@@ -102,6 +118,11 @@ func (r *Reconciler) addToConfig(ctx context.Context, b *brokerv1beta1.Broker, t
 		} else {
 			m.SetState(config.State_UNKNOWN)
 		}
+		m.SetRateLimit(rateLimits.BrokerLimit(b.Namespace, b.Name))
+		m.SetNamespaceRateLimit(rateLimits.NamespaceLimit(b.Namespace))
+		m.SetOrdered(b.IsOrdered())
+		attribute, classes := priorityClasses(b, brokerQueueState)
+		m.SetPriorityClasses(attribute, classes...)
 
 		// Insert each Trigger to the config.
 		for _, t := range triggers {
@@ -116,10 +137,28 @@ func (r *Reconciler) addToConfig(ctx context.Context, b *brokerv1beta1.Broker, t
 						Topic:        brokerresources.GenerateRetryTopicName(t),
 						Subscription: brokerresources.GenerateRetrySubscriptionName(t),
 					},
+					Ordered:            t.IsOrdered(),
+					DedupWindowSeconds: int64(t.DedupWindow() / time.Second),
 				}
+				target.ReplyAddress, target.ReplyPolicy = replyPolicy(ctx, t, brokerAddresses)
 				if t.Spec.Filter != nil && t.Spec.Filter.Attributes != nil {
 					target.FilterAttributes = t.Spec.Filter.Attributes
 				}
+				if filters, err := t.Filters(); err != nil {
+					// The filters are validated by the webhook, so this should not happen. An empty
+					// filter doesn't compile, so that the fanout drops the events rather than
+					// delivering the events the trigger didn't ask for.
+					logging.FromContext(ctx).Error("Invalid trigger filters", zap.String("trigger", t.Name), zap.Error(err))
+					target.Filters = []*config.Filter{{}}
+				} else if len(filters) > 0 {
+					target.Filters = toConfigFilters(filters)
+				}
+				if deadLetter := t.Status.DeadLetterSinkURI(); deadLetter != "" {
+					target.DeliverySpec = &config.DeliverySpec{
+						DeadLetter: deadLetter,
+						Retry:      deliveryRetry(t.DeliverySpec(b)),
+					}
+				}
 				// TODO(#939) May need to use "data plane readiness" for trigger in stead of the
 				//  overall status, see https://github.com/google/knative-gcp/issues/939#issuecomment-644337937
 				if t.Status.IsReady() {
@@ -133,6 +172,113 @@ func (r *Reconciler) addToConfig(ctx context.Context, b *brokerv1beta1.Broker, t
 	})
 }
 
+// replyPolicy returns the address the replies of the trigger are sent to, empty for its broker,
+// and the policy applied to them. The replies are dropped while the broker they are sent to has no
+// address, rather than being sent back to the broker of the trigger.
+func replyPolicy(ctx context.Context, t *brokerv1beta1.Trigger, brokerAddresses map[string]string) (string, *config.ReplyPolicy) {
+	if t.DropsReplies() {
+		return "", &config.ReplyPolicy{Drop: true}
+	}
+	var address string
+	if u, broker := t.ReplyDestination(); u != nil {
+		address = u.String()
+	} else if broker != "" {
+		address = brokerAddresses[config.BrokerKey(t.Namespace, broker)]
+		if address == "" {
+			logging.FromContext(ctx).Warn("Reply destination broker not found or not addressable, dropping replies",
+				zap.String("trigger", t.Name), zap.String("broker", broker))
+			return "", &config.ReplyPolicy{Drop: true}
+		}
+	}
+	types, sources := t.ReplyAllowedTypes(), t.ReplyAllowedSources()
+	if len(types) == 0 && len(sources) == 0 {
+		return address, nil
+	}
+	return address, &config.ReplyPolicy{AllowedTypes: types, AllowedSources: sources}
+}
+
+// priorityClasses returns the attribute and the config of the priority classes of the broker. The
+// queue of a class is ready once its topic and subscription are reconciled, if the queue of the
+// broker is ready. The removed classes still listed in the status are draining: they have no
+// values, so that no event is published to them, but are still fanned out.
+func priorityClasses(b *brokerv1beta1.Broker, brokerQueueState config.State) (string, []*config.PriorityClass) {
+	pc := b.PriorityClasses()
+	if pc == nil {
+		pc = &brokerv1beta1.PriorityClasses{}
+	}
+	reconciled := make(map[string]bool)
+	for _, c := range b.Status.PriorityClasses() {
+		reconciled[c] = true
+	}
+	classes := make([]*config.PriorityClass, 0, len(reconciled))
+	for _, c := range pc.Classes {
+		state := config.State_UNKNOWN
+		if reconciled[c.Name] {
+			state = brokerQueueState
+			delete(reconciled, c.Name)
+		}
+		classes = append(classes, &config.PriorityClass{
+			Name:   c.Name,
+			Values: c.Values,
+			DecoupleQueue: &config.Queue{
+				Topic:        brokerresources.GeneratePriorityClassTopicName(b, c.Name),
+				Subscription: brokerresources.GeneratePriorityClassSubscriptionName(b, c.Name),
+				State:        state,
+			},
+			MaxConcurrency: c.MaxConcurrency,
+		})
+	}
+	for _, c := range b.Status.PriorityClasses() {
+		if reconciled[c] {
+			classes = append(classes, &config.PriorityClass{
+				Name: c,
+				DecoupleQueue: &config.Queue{
+					Topic:        brokerresources.GeneratePriorityClassTopicName(b, c),
+					Subscription: brokerresources.GeneratePriorityClassSubscriptionName(b, c),
+					State:        brokerQueueState,
+				},
+			})
+		}
+	}
+	return pc.Attribute, classes
+}
+
+// toConfigFilters converts the Trigger filters to their targets config
+// representation.
+func toConfigFilters(filters []brokerv1beta1.SubscriptionsAPIFilter) []*config.Filter {
+	if filters == nil {
+		return nil
+	}
+	out := make([]*config.Filter, 0, len(filters))
+	for _, f := range filters {
+		out = append(out, toConfigFilter(&f))
+	}
+	return out
+}
+
+func toConfigFilter(f *brokerv1beta1.SubscriptionsAPIFilter) *config.Filter {
+	out := &config.Filter{
+		All:    toConfigFilters(f.All),
+		Any:    toConfigFilters(f.Any),
+		Exact:  f.Exact,
+		Prefix: f.Prefix,
+		Suffix: f.Suffix,
+	}
+	if f.Not != nil {
+		out.Not = toConfigFilter(f.Not)
+	}
+	return out
+}
+
+// deliveryRetry returns the number of retries before an event is sent to the
+// dead letter sink.
+func deliveryRetry(spec *eventingduckv1beta1.DeliverySpec) int32 {
+	if spec == nil || spec.Retry == nil {
+		return 0
+	}
+	return *spec.Retry
+}
+
 //TODO all this stuff should be in a configmap variant of the config object
 func (r *Reconciler) updateTargetsConfig(ctx context.Context, bc *intv1alpha1.BrokerCell, brokerTargets config.Targets) error {
 	desired, err := resources.MakeTargetsConfig(bc, brokerTargets)
@@ -19,6 +19,7 @@ package resources
 import (
 	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
 	"knative.dev/pkg/kmeta"
 
 	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
@@ -50,6 +51,8 @@ type Args struct {
 	CPULimit           string
 	MemoryRequest      string
 	MemoryLimit        string
+	// DecoupleQueueEnv configures the queues of the data plane, see DecoupleQueueEnv.
+	DecoupleQueueEnv []corev1.EnvVar
 }
 
 // IngressArgs are the arguments to create a Broker's ingress Deployment.
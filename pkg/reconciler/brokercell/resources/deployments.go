@@ -81,6 +81,7 @@ func MakeFanoutDeployment(args FanoutArgs) *appsv1.Deployment {
 		Name:  "MAX_CONCURRENCY_PER_EVENT",
 		Value: "100",
 	})
+	container.Env = append(container.Env, UnconvertibleSinkEnv(args.BrokerCell)...)
 	container.LivenessProbe = &corev1.Probe{
 		Handler: corev1.Handler{
 			HTTPGet: &corev1.HTTPGetAction{
@@ -101,6 +102,7 @@ func MakeFanoutDeployment(args FanoutArgs) *appsv1.Deployment {
 // MakeRetryDeployment creates the retry Deployment object.
 func MakeRetryDeployment(args RetryArgs) *appsv1.Deployment {
 	container := containerTemplate(args.Args)
+	container.Env = append(container.Env, UnconvertibleSinkEnv(args.BrokerCell)...)
 	container.Resources = resourceutil.BuildResourceRequirements(args.CPURequest, args.CPULimit, args.MemoryRequest, args.MemoryLimit)
 	container.Ports = append(container.Ports,
 		corev1.ContainerPort{
@@ -172,7 +174,7 @@ func deploymentTemplate(args Args, containers []corev1.Container) *appsv1.Deploy
 
 // containerTemplate returns a common template for broker data plane containers.
 func containerTemplate(args Args) corev1.Container {
-	return corev1.Container{
+	container := corev1.Container{
 		Image: args.Image,
 		Name:  args.ComponentName,
 		Env: []corev1.EnvVar{
@@ -223,4 +225,6 @@ func containerTemplate(args Args) corev1.Container {
 			},
 		},
 	}
+	container.Env = append(container.Env, args.DecoupleQueueEnv...)
+	return container
 }
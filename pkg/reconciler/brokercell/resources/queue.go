@@ -0,0 +1,124 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+	"github.com/google/knative-gcp/pkg/broker/queue"
+	redisstreamresources "github.com/google/knative-gcp/pkg/reconciler/intevents/redisstreamsource/resources"
+)
+
+const (
+	// DecoupleQueueAnnotationKey is the annotation of a BrokerCell selecting the kind of the
+	// queues decoupling its ingress from its fanout and retry, "pubsub" (the default) or "redis".
+	DecoupleQueueAnnotationKey = "internal.events.cloud.google.com/decouple-queue"
+	// RedisConnectionAnnotationKey is the annotation of a BrokerCell holding the JSON encoded
+	// RedisConnection to the Redis instance of its queues, when they are Redis streams.
+	RedisConnectionAnnotationKey = "internal.events.cloud.google.com/redis-connection"
+)
+
+// DecoupleQueueEnv returns the environment variables configuring the queues of the data plane
+// of the BrokerCell, from its annotations.
+func DecoupleQueueEnv(ctx context.Context, bc *intv1alpha1.BrokerCell) ([]corev1.EnvVar, error) {
+	conn, err := RedisQueueConnection(ctx, bc)
+	if conn == nil || err != nil {
+		return nil, err
+	}
+	return redisEnv(conn), nil
+}
+
+// RedisQueueConnection returns the connection to the Redis instance of the queues of the
+// BrokerCell, or nil if its queues aren't Redis streams.
+func RedisQueueConnection(ctx context.Context, bc *intv1alpha1.BrokerCell) (*intv1alpha1.RedisConnection, error) {
+	kind := queue.Kind(bc.GetAnnotations()[DecoupleQueueAnnotationKey])
+	switch kind {
+	case "", queue.PubSub:
+		return nil, nil
+	case queue.Redis:
+		return redisConnection(ctx, bc)
+	default:
+		return nil, fmt.Errorf("unsupported %s annotation %q", DecoupleQueueAnnotationKey, kind)
+	}
+}
+
+func redisConnection(ctx context.Context, bc *intv1alpha1.BrokerCell) (*intv1alpha1.RedisConnection, error) {
+	value, ok := bc.GetAnnotations()[RedisConnectionAnnotationKey]
+	if !ok {
+		return nil, fmt.Errorf("missing %s annotation", RedisConnectionAnnotationKey)
+	}
+	conn := &intv1alpha1.RedisConnection{}
+	if err := json.Unmarshal([]byte(value), conn); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", RedisConnectionAnnotationKey, err)
+	}
+	conn.SetDefaults(ctx)
+	if err := conn.Validate(ctx); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", RedisConnectionAnnotationKey, err)
+	}
+	return conn, nil
+}
+
+func redisEnv(conn *intv1alpha1.RedisConnection) []corev1.EnvVar {
+	env := []corev1.EnvVar{{
+		Name:  queue.KindEnvKey,
+		Value: string(queue.Redis),
+	}, {
+		Name:  queue.RedisAddressEnvKey,
+		Value: conn.Address,
+	}}
+	opts := conn.Options
+	if opts == nil {
+		return env
+	}
+	if password := redisstreamresources.PasswordSecretKeySelector(conn); password != nil {
+		env = append(env, corev1.EnvVar{
+			Name:      queue.RedisPasswordEnvKey,
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: password},
+		})
+	}
+	if opts.UseTLS {
+		env = append(env, corev1.EnvVar{
+			Name:  queue.RedisTLSEnabledEnvKey,
+			Value: "true",
+		})
+		if opts.SkipVerify {
+			env = append(env, corev1.EnvVar{
+				Name:  queue.RedisTLSSkipVerifyEnvKey,
+				Value: "true",
+			})
+		}
+		env = appendSecretEnv(env, queue.RedisTLSCertEnvKey, opts.Cert)
+		env = appendSecretEnv(env, queue.RedisTLSKeyEnvKey, opts.Key)
+		env = appendSecretEnv(env, queue.RedisTLSCACertificateEnvKey, opts.CACert)
+	}
+	return env
+}
+
+func appendSecretEnv(env []corev1.EnvVar, name string, value intv1alpha1.RedisSecretValueFromSource) []corev1.EnvVar {
+	if value.SecretKeyRef == nil {
+		return env
+	}
+	return append(env, corev1.EnvVar{
+		Name:      name,
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: value.SecretKeyRef},
+	})
+}
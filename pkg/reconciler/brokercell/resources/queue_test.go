@@ -0,0 +1,111 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+)
+
+func TestDecoupleQueueEnv(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        []corev1.EnvVar
+		wantErr     bool
+	}{{
+		name: "default",
+	}, {
+		name:        "pubsub",
+		annotations: map[string]string{DecoupleQueueAnnotationKey: "pubsub"},
+	}, {
+		name: "redis",
+		annotations: map[string]string{
+			DecoupleQueueAnnotationKey:   "redis",
+			RedisConnectionAnnotationKey: `{"address":"redis.example.com"}`,
+		},
+		want: []corev1.EnvVar{
+			{Name: "DECOUPLE_QUEUE", Value: "redis"},
+			{Name: "REDIS_ADDRESS", Value: "redis.example.com:6379"},
+		},
+	}, {
+		name: "redis with password and TLS",
+		annotations: map[string]string{
+			DecoupleQueueAnnotationKey: "redis",
+			RedisConnectionAnnotationKey: `{"address":"redis.example.com:6380","dialOptions":{` +
+				`"password":{"kind":"Secret","name":"redis"},` +
+				`"skipVerify":true,` +
+				`"caCert":{"secretKeyRef":{"name":"redis-tls","key":"ca.crt"}}}}`,
+		},
+		want: []corev1.EnvVar{
+			{Name: "DECOUPLE_QUEUE", Value: "redis"},
+			{Name: "REDIS_ADDRESS", Value: "redis.example.com:6380"},
+			{Name: "REDIS_PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "redis"},
+				Key:                  "password",
+			}}},
+			{Name: "REDIS_TLS_ENABLED", Value: "true"},
+			{Name: "REDIS_TLS_SKIP_VERIFY", Value: "true"},
+			{Name: "REDIS_TLS_CA_CERTIFICATE", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "redis-tls"},
+				Key:                  "ca.crt",
+			}}},
+		},
+	}, {
+		name:        "redis without connection",
+		annotations: map[string]string{DecoupleQueueAnnotationKey: "redis"},
+		wantErr:     true,
+	}, {
+		name: "redis with malformed connection",
+		annotations: map[string]string{
+			DecoupleQueueAnnotationKey:   "redis",
+			RedisConnectionAnnotationKey: `{"address":`,
+		},
+		wantErr: true,
+	}, {
+		name: "redis with invalid connection",
+		annotations: map[string]string{
+			DecoupleQueueAnnotationKey:   "redis",
+			RedisConnectionAnnotationKey: `{"address":"redis.example.com:port"}`,
+		},
+		wantErr: true,
+	}, {
+		name:        "unsupported queue",
+		annotations: map[string]string{DecoupleQueueAnnotationKey: "kafka"},
+		wantErr:     true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bc := &intv1alpha1.BrokerCell{
+				ObjectMeta: metav1.ObjectMeta{Name: "bc", Namespace: "ns", Annotations: tt.annotations},
+			}
+			got, err := DecoupleQueueEnv(context.Background(), bc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecoupleQueueEnv error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Unexpected env (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+
+	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+// IngressRateLimitsAnnotationKey is the annotation of a BrokerCell holding the JSON encoded
+// IngressRateLimits of its ingress. Events are not rate limited if it's absent.
+const IngressRateLimitsAnnotationKey = "internal.events.cloud.google.com/ingress-rate-limits"
+
+// RateLimit is a token bucket limit of the rate of events.
+type RateLimit struct {
+	// EventsPerSecond is the sustained rate of events. Unlimited if zero.
+	EventsPerSecond float64 `json:"eventsPerSecond"`
+	// Burst is the maximum number of events accepted at once. Defaults to one second of events.
+	// +optional
+	Burst int32 `json:"burst,omitempty"`
+}
+
+// IngressRateLimits are the limits of the rate of events the ingress of a BrokerCell accepts
+// for its brokers.
+type IngressRateLimits struct {
+	// Broker is the limit of each broker, unless it is overridden in Brokers.
+	// +optional
+	Broker *RateLimit `json:"broker,omitempty"`
+	// Brokers overrides the limit of individual brokers, keyed by namespace/name.
+	// +optional
+	Brokers map[string]RateLimit `json:"brokers,omitempty"`
+	// Namespace is the limit shared by all brokers of each namespace, unless it is overridden in
+	// Namespaces.
+	// +optional
+	Namespace *RateLimit `json:"namespace,omitempty"`
+	// Namespaces overrides the limit of individual namespaces, keyed by namespace.
+	// +optional
+	Namespaces map[string]RateLimit `json:"namespaces,omitempty"`
+}
+
+// GetIngressRateLimits returns the ingress rate limits of the BrokerCell from its annotations,
+// or nil if it has none.
+func GetIngressRateLimits(bc *intv1alpha1.BrokerCell) (*IngressRateLimits, error) {
+	value, ok := bc.GetAnnotations()[IngressRateLimitsAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+	limits := &IngressRateLimits{}
+	if err := json.Unmarshal([]byte(value), limits); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", IngressRateLimitsAnnotationKey, err)
+	}
+	if err := limits.validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", IngressRateLimitsAnnotationKey, err)
+	}
+	return limits, nil
+}
+
+func (l *IngressRateLimits) validate() error {
+	if err := l.Broker.validate(); err != nil {
+		return fmt.Errorf("broker: %w", err)
+	}
+	for key, limit := range l.Brokers {
+		if err := limit.validate(); err != nil {
+			return fmt.Errorf("brokers[%s]: %w", key, err)
+		}
+	}
+	if err := l.Namespace.validate(); err != nil {
+		return fmt.Errorf("namespace: %w", err)
+	}
+	for key, limit := range l.Namespaces {
+		if err := limit.validate(); err != nil {
+			return fmt.Errorf("namespaces[%s]: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func (l *RateLimit) validate() error {
+	if l == nil {
+		return nil
+	}
+	if l.EventsPerSecond < 0 {
+		return fmt.Errorf("negative eventsPerSecond %v", l.EventsPerSecond)
+	}
+	if l.Burst < 0 {
+		return fmt.Errorf("negative burst %d", l.Burst)
+	}
+	return nil
+}
+
+// BrokerLimit returns the rate limit of the broker in the broker config, or nil if it isn't
+// limited.
+func (l *IngressRateLimits) BrokerLimit(namespace, name string) *config.RateLimit {
+	if l == nil {
+		return nil
+	}
+	if limit, ok := l.Brokers[config.BrokerKey(namespace, name)]; ok {
+		return limit.toConfig()
+	}
+	return l.Broker.toConfig()
+}
+
+// NamespaceLimit returns the rate limit shared by the brokers of the namespace in the broker
+// config, or nil if it isn't limited.
+func (l *IngressRateLimits) NamespaceLimit(namespace string) *config.RateLimit {
+	if l == nil {
+		return nil
+	}
+	if limit, ok := l.Namespaces[namespace]; ok {
+		return limit.toConfig()
+	}
+	return l.Namespace.toConfig()
+}
+
+func (l *RateLimit) toConfig() *config.RateLimit {
+	if l == nil || l.EventsPerSecond == 0 {
+		return nil
+	}
+	return &config.RateLimit{
+		EventsPerSecond: l.EventsPerSecond,
+		Burst:           l.Burst,
+	}
+}
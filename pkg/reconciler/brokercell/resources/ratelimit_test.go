@@ -0,0 +1,88 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+func TestGetIngressRateLimits(t *testing.T) {
+	bc := func(value string) *intv1alpha1.BrokerCell {
+		return &intv1alpha1.BrokerCell{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{IngressRateLimitsAnnotationKey: value},
+			},
+		}
+	}
+
+	t.Run("no annotation", func(t *testing.T) {
+		limits, err := GetIngressRateLimits(&intv1alpha1.BrokerCell{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := limits.BrokerLimit("ns", "broker"); got != nil {
+			t.Errorf("BrokerLimit got %v, want nil", got)
+		}
+		if got := limits.NamespaceLimit("ns"); got != nil {
+			t.Errorf("NamespaceLimit got %v, want nil", got)
+		}
+	})
+
+	t.Run("defaults and overrides", func(t *testing.T) {
+		limits, err := GetIngressRateLimits(bc(`{` +
+			`"broker":{"eventsPerSecond":100,"burst":200},` +
+			`"brokers":{"ns1/fast":{"eventsPerSecond":1000},"ns1/exempt":{"eventsPerSecond":0}},` +
+			`"namespace":{"eventsPerSecond":500},` +
+			`"namespaces":{"ns2":{"eventsPerSecond":50,"burst":10}}}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tests := []struct {
+			name string
+			got  *config.RateLimit
+			want *config.RateLimit
+		}{
+			{"default broker", limits.BrokerLimit("ns1", "broker"), &config.RateLimit{EventsPerSecond: 100, Burst: 200}},
+			{"overridden broker", limits.BrokerLimit("ns1", "fast"), &config.RateLimit{EventsPerSecond: 1000}},
+			{"exempt broker", limits.BrokerLimit("ns1", "exempt"), nil},
+			{"default namespace", limits.NamespaceLimit("ns1"), &config.RateLimit{EventsPerSecond: 500}},
+			{"overridden namespace", limits.NamespaceLimit("ns2"), &config.RateLimit{EventsPerSecond: 50, Burst: 10}},
+		}
+		for _, tc := range tests {
+			if diff := cmp.Diff(tc.want, tc.got, protocmp.Transform()); diff != "" {
+				t.Errorf("%s: unexpected limit (-want +got): %s", tc.name, diff)
+			}
+		}
+	})
+
+	for _, value := range []string{
+		`not json`,
+		`{"broker":{"eventsPerSecond":-1}}`,
+		`{"namespaces":{"ns":{"eventsPerSecond":1,"burst":-1}}}`,
+	} {
+		if _, err := GetIngressRateLimits(bc(value)); err == nil {
+			t.Errorf("GetIngressRateLimits(%s) got no error, want an error", value)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+)
+
+const (
+	// UnconvertibleSinkAnnotationKey is the annotation of a BrokerCell holding the URI that its
+	// fanout and retry send the messages which can't be converted to events to. They are dropped
+	// if it's absent.
+	UnconvertibleSinkAnnotationKey = "internal.events.cloud.google.com/unconvertible-sink"
+
+	unconvertibleSinkEnvKey = "UNCONVERTIBLE_SINK_URI"
+)
+
+// UnconvertibleSinkEnv returns the environment variables configuring the unconvertible sink of
+// the fanout and retry of the BrokerCell, from its annotations.
+func UnconvertibleSinkEnv(bc *intv1alpha1.BrokerCell) []corev1.EnvVar {
+	uri := bc.GetAnnotations()[UnconvertibleSinkAnnotationKey]
+	if uri == "" {
+		return nil
+	}
+	return []corev1.EnvVar{{Name: unconvertibleSinkEnvKey, Value: uri}}
+}
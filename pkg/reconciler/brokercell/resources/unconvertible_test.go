@@ -0,0 +1,57 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+)
+
+func TestUnconvertibleSinkEnv(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        []corev1.EnvVar
+	}{{
+		name: "default",
+	}, {
+		name:        "empty",
+		annotations: map[string]string{UnconvertibleSinkAnnotationKey: ""},
+	}, {
+		name:        "sink",
+		annotations: map[string]string{UnconvertibleSinkAnnotationKey: "http://sink.example.svc.cluster.local"},
+		want: []corev1.EnvVar{
+			{Name: "UNCONVERTIBLE_SINK_URI", Value: "http://sink.example.svc.cluster.local"},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bc := &intv1alpha1.BrokerCell{
+				ObjectMeta: metav1.ObjectMeta{Name: "bc", Namespace: "ns", Annotations: tt.annotations},
+			}
+			got := UnconvertibleSinkEnv(bc)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Unexpected env (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
@@ -18,6 +18,8 @@ package auditlogs
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"cloud.google.com/go/logging/logadmin"
 	"go.uber.org/zap"
@@ -37,6 +39,7 @@ import (
 	"github.com/google/knative-gcp/pkg/reconciler/events/auditlogs/resources"
 	"github.com/google/knative-gcp/pkg/reconciler/identity"
 	"github.com/google/knative-gcp/pkg/reconciler/intevents"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
 )
 
 const (
@@ -86,85 +89,158 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, s *v1.CloudAuditLogsSour
 	}
 	c.Logger.Debugf("Reconciled: PubSub: %+v PullSubscription: %+v", t, ps)
 
-	sink, err := c.reconcileSink(ctx, s)
+	sink, filter, drift, err := c.reconcileSink(ctx, s)
 	if err != nil {
 		return reconciler.NewEvent(corev1.EventTypeWarning, reconciledFailedReason, "Reconcile Sink failed with: %s", err.Error())
 	}
 	s.Status.StackdriverSink = sink
+	s.Status.Filter = filter
 	s.Status.MarkSinkReady()
+	c.PubSubBase.ReportDrift(ctx, s, drift)
 	c.Logger.Debugf("Reconciled Stackdriver sink: %+v", sink)
 
+	// The source of the events depends on the log they are written to.
+	if event := c.PubSubBase.ReconcileEventTypes(ctx, s, "", schemasv1.EventTypes(s.GetGroupVersionKind().Kind)); event != nil {
+		return event
+	}
+
 	return reconciler.NewEvent(corev1.EventTypeNormal, reconciledSuccessReason, `CloudAuditLogsSource reconciled: "%s/%s"`, s.Namespace, s.Name)
 }
 
-func (c *Reconciler) reconcileSink(ctx context.Context, s *v1.CloudAuditLogsSource) (string, error) {
-	sink, err := c.ensureSinkCreated(ctx, s)
+// reconcileSink makes sure that the sink of the source exists, matches its desired state and is
+// allowed to publish to the topic of the source. It returns the ID and the filter of the sink, and
+// the drift of the sink that was repaired, if any.
+func (c *Reconciler) reconcileSink(ctx context.Context, s *v1.CloudAuditLogsSource) (string, string, []string, error) {
+	sink, drift, err := c.ensureSinkCreated(ctx, s)
 	if err != nil {
 		s.Status.MarkSinkNotReady("SinkCreateFailed", "failed to ensure creation of logging sink: %s", err.Error())
-		return "", err
+		return "", "", nil, err
 	}
-	err = c.ensureSinkIsPublisher(ctx, s, sink)
+	granted, err := c.ensureSinkIsPublisher(ctx, s, sink)
 	if err != nil {
 		s.Status.MarkSinkNotReady("SinkNotPublisher", "failed to ensure sink has pubsub.publisher permission on source topic: %s", err.Error())
-		return "", err
+		return "", "", nil, err
 	}
-	return sink.ID, nil
+	// Unless the sink itself drifted, the role was granted before, so it was revoked out of band.
+	if granted && s.Status.StackdriverSink != "" && len(drift) == 0 {
+		drift = append(drift, fmt.Sprintf("sink %q lost the %s role on topic %q", sink.ID, publisherRole, s.Status.TopicID))
+	}
+	return sink.ID, sink.Filter, drift, nil
 }
 
-func (c *Reconciler) ensureSinkCreated(ctx context.Context, s *v1.CloudAuditLogsSource) (*logadmin.Sink, error) {
+func (c *Reconciler) ensureSinkCreated(ctx context.Context, s *v1.CloudAuditLogsSource) (*logadmin.Sink, []string, error) {
 	sinkID := s.Status.StackdriverSink
 	if sinkID == "" {
 		sinkID = resources.GenerateSinkName(s)
 	}
-	logadminClient, err := c.logadminClientProvider(ctx, s.Status.ProjectID)
+	logadminClient, err := c.logadminClientProvider(ctx, resources.SinkParent(s))
 	if err != nil {
 		logging.FromContext(ctx).Desugar().Error("Failed to create LogAdmin client", zap.Error(err))
-		return nil, err
+		return nil, nil, err
+	}
+	desired := &logadmin.Sink{
+		ID:              sinkID,
+		Destination:     resources.GenerateTopicResourceName(s),
+		Filter:          sinkFilter(s),
+		IncludeChildren: s.Spec.IncludeChildren,
 	}
+
 	sink, err := logadminClient.Sink(ctx, sinkID)
 	if status.Code(err) == codes.NotFound {
-		filterBuilder := resources.FilterBuilder{}
-		filterBuilder.WithServiceName(s.Spec.ServiceName).WithMethodName(s.Spec.MethodName)
-		if s.Spec.ResourceName != "" {
-			filterBuilder.WithResourceName(s.Spec.ResourceName)
-		}
-		sink = &logadmin.Sink{
-			ID:          sinkID,
-			Destination: resources.GenerateTopicResourceName(s),
-			Filter:      filterBuilder.GetFilterQuery(),
-		}
-		sink, err = logadminClient.CreateSinkOpt(ctx, sink, logadmin.SinkOptions{UniqueWriterIdentity: true})
+		sink, err = logadminClient.CreateSinkOpt(ctx, desired, logadmin.SinkOptions{UniqueWriterIdentity: true})
 		// Handle AlreadyExists in-case of a race between another create call.
 		if status.Code(err) == codes.AlreadyExists {
 			sink, err = logadminClient.Sink(ctx, sinkID)
 		}
+		if err != nil {
+			return nil, nil, err
+		}
+		// The sink was created before, so it was deleted out of band.
+		if s.Status.StackdriverSink != "" {
+			return sink, []string{fmt.Sprintf("sink %q was deleted", sinkID)}, nil
+		}
+		return sink, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fields, drift []string
+	if sink.Destination != desired.Destination {
+		fields = append(fields, "destination")
+		drift = append(drift, "destination")
+	}
+	if sink.Filter != desired.Filter {
+		fields = append(fields, "filter")
+		// The filter is still the one applied by the last reconciliation, so the spec of the
+		// source changed rather than the sink.
+		if sink.Filter != s.Status.Filter {
+			drift = append(drift, "filter")
+		}
+	}
+	if sink.IncludeChildren != desired.IncludeChildren {
+		fields = append(fields, "includeChildren")
+		drift = append(drift, "includeChildren")
+	}
+	if len(fields) == 0 {
+		return sink, nil, nil
+	}
+	sink, err = logadminClient.UpdateSinkOpt(ctx, desired, logadmin.SinkOptions{
+		UniqueWriterIdentity:  true,
+		UpdateDestination:     true,
+		UpdateFilter:          true,
+		UpdateIncludeChildren: true,
+	})
+	if err != nil {
+		logging.FromContext(ctx).Desugar().Error("Failed to update Stackdriver sink", zap.String("sinkID", sinkID), zap.Strings("fields", fields), zap.Error(err))
+		return nil, nil, err
+	}
+	if len(drift) == 0 {
+		return sink, nil, nil
+	}
+	return sink, []string{fmt.Sprintf("sink %q was modified: %s", sinkID, strings.Join(drift, ", "))}, nil
+}
+
+// sinkFilter returns the filter of the Stackdriver sink of the source.
+func sinkFilter(s *v1.CloudAuditLogsSource) string {
+	filterBuilder := resources.FilterBuilder{}
+	filterBuilder.WithServiceName(s.Spec.ServiceName).WithMethodName(s.Spec.MethodName).WithMethodNames(s.Spec.MethodNames...)
+	if s.Spec.ResourceName != "" {
+		filterBuilder.WithResourceName(s.Spec.ResourceName)
 	}
-	return sink, err
+	filterBuilder.WithSeverity(s.Spec.Severity).
+		WithPrincipalEmails(s.Spec.PrincipalEmails...).
+		WithResourceType(s.Spec.ResourceType).
+		WithLogName(s.Spec.LogName).
+		WithFilter(s.Spec.Filter)
+	return filterBuilder.GetFilterQuery()
 }
 
-// Ensures that the sink has been granted the pubsub.publisher role on the source topic.
-func (c *Reconciler) ensureSinkIsPublisher(ctx context.Context, s *v1.CloudAuditLogsSource, sink *logadmin.Sink) error {
+// Ensures that the sink has been granted the pubsub.publisher role on the source topic. It returns
+// whether the role had to be granted.
+func (c *Reconciler) ensureSinkIsPublisher(ctx context.Context, s *v1.CloudAuditLogsSource, sink *logadmin.Sink) (bool, error) {
 	pubsubClient, err := c.pubsubClientProvider(ctx, s.Status.ProjectID)
 	if err != nil {
 		logging.FromContext(ctx).Desugar().Error("Failed to create PubSub client", zap.Error(err))
-		return err
+		return false, err
 	}
 	topicIam := pubsubClient.Topic(s.Status.TopicID).IAM()
 	topicPolicy, err := topicIam.Policy(ctx)
 	if err != nil {
-		return err
+		return false, err
 	}
-	if !topicPolicy.HasRole(sink.WriterIdentity, publisherRole) {
-		topicPolicy.Add(sink.WriterIdentity, publisherRole)
-		if err = topicIam.SetPolicy(ctx, topicPolicy); err != nil {
-			return err
-		}
-		logging.FromContext(ctx).Desugar().Debug(
-			"Granted the Stackdriver Sink writer identity roles/pubsub.publisher on PubSub Topic.",
-			zap.String("writerIdentity", sink.WriterIdentity),
-			zap.String("topicID", s.Status.TopicID))
+	if topicPolicy.HasRole(sink.WriterIdentity, publisherRole) {
+		return false, nil
 	}
-	return nil
+	topicPolicy.Add(sink.WriterIdentity, publisherRole)
+	if err = topicIam.SetPolicy(ctx, topicPolicy); err != nil {
+		return false, err
+	}
+	logging.FromContext(ctx).Desugar().Debug(
+		"Granted the Stackdriver Sink writer identity roles/pubsub.publisher on PubSub Topic.",
+		zap.String("writerIdentity", sink.WriterIdentity),
+		zap.String("topicID", s.Status.TopicID))
+	return true, nil
 }
 
 // deleteSink looks at status.SinkID and if non-empty will delete the
@@ -173,7 +249,7 @@ func (c *Reconciler) deleteSink(ctx context.Context, s *v1.CloudAuditLogsSource)
 	if s.Status.StackdriverSink == "" {
 		return nil
 	}
-	logadminClient, err := c.logadminClientProvider(ctx, s.Status.ProjectID)
+	logadminClient, err := c.logadminClientProvider(ctx, resources.SinkParent(s))
 	if err != nil {
 		logging.FromContext(ctx).Desugar().Error("Failed to create LogAdmin client", zap.Error(err))
 		s.Status.MarkSinkUnknown(deleteSinkFailed, "Failed to create LogAdmin Client: %s", err.Error())
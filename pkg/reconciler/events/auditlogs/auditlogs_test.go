@@ -24,6 +24,7 @@ import (
 
 	v1 "github.com/google/knative-gcp/pkg/reconciler/testing/v1"
 
+	"cloud.google.com/go/iam"
 	"cloud.google.com/go/logging/logadmin"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -63,9 +64,10 @@ const (
 	testProject  = "test-project-id"
 	testTopicURI = "http://" + sourceName + "-topic." + testNS + ".svc.cluster.local"
 
-	testServiceName = "test-service"
-	testMethodName  = "test-method"
-	testFilter      = `protoPayload.methodName="test-method" AND protoPayload.serviceName="test-service" AND protoPayload."@type"="type.googleapis.com/google.cloud.audit.AuditLog"`
+	testServiceName  = "test-service"
+	testMethodName   = "test-method"
+	testOrganization = "organizations/123456789"
+	testFilter       = `protoPayload.methodName="test-method" AND protoPayload.serviceName="test-service" AND protoPayload."@type"="type.googleapis.com/google.cloud.audit.AuditLog"`
 
 	sinkName = "sink"
 	sinkDNS  = sinkName + ".mynamespace.svc.cluster.local"
@@ -1152,6 +1154,7 @@ func TestAllCases(t *testing.T) {
 				v1.WithCloudAuditLogsSourceSinkURI(calSinkURL),
 				v1.WithCloudAuditLogsSourceSinkReady,
 				v1.WithCloudAuditLogsSourceSinkID(testSinkID),
+				v1.WithCloudAuditLogsSourceFilter(testFilter),
 				v1.WithCloudAuditLogsSourceSetDefaults,
 			),
 		}},
@@ -1224,6 +1227,165 @@ func TestAllCases(t *testing.T) {
 				v1.WithCloudAuditLogsSourceSinkURI(calSinkURL),
 				v1.WithCloudAuditLogsSourceSinkReady,
 				v1.WithCloudAuditLogsSourceSinkID(testSinkID),
+				v1.WithCloudAuditLogsSourceFilter(testFilter),
+				v1.WithCloudAuditLogsSourceSetDefaults,
+			),
+		}},
+	}, {
+		Name: "sink modified out of band",
+		Objects: []runtime.Object{
+			v1.NewCloudAuditLogsSource(sourceName, testNS,
+				v1.WithCloudAuditLogsSourceUID(sourceUID),
+				v1.WithCloudAuditLogsSourceMethodName(testMethodName),
+				v1.WithCloudAuditLogsSourceServiceName(testServiceName),
+				v1.WithCloudAuditLogsSourceSink(sinkGVK, sinkName),
+				v1.WithCloudAuditLogsSourceSinkID(testSinkID),
+				v1.WithCloudAuditLogsSourceSetDefaults,
+			),
+			v1.NewTopic(sourceName, testNS,
+				v1.WithTopicSpec(inteventsv1.TopicSpec{
+					Topic:             testTopicID,
+					PropagationPolicy: "CreateDelete",
+					EnablePublisher:   &falseVal,
+				}),
+				v1.WithTopicReady(testTopicID),
+				v1.WithTopicAddress(testTopicURI),
+				v1.WithTopicProjectID(testProject),
+				v1.WithTopicSetDefaults,
+			),
+			v1.NewPullSubscription(sourceName, testNS,
+				v1.WithPullSubscriptionReady(sinkURI),
+				v1.WithPullSubscriptionSpec(inteventsv1.PullSubscriptionSpec{
+					Topic: testTopicID,
+					PubSubSpec: gcpduckv1.PubSubSpec{
+						Secret: &secret,
+						SourceSpec: duckv1.SourceSpec{
+							Sink: newSinkDestination(),
+						},
+					},
+					AdapterType: string(converters.CloudAuditLogs),
+				})),
+		},
+		Key: testNS + "/" + sourceName,
+		OtherTestData: map[string]interface{}{
+			"existingSinks": []logadmin.Sink{{
+				ID:          testSinkID,
+				Filter:      "severity>=ERROR",
+				Destination: testTopicResource,
+			}},
+			"expectedSinks": map[string]*logadmin.Sink{
+				testSinkID: {
+					ID:          testSinkID,
+					Filter:      testFilter,
+					Destination: testTopicResource,
+				}},
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName, true),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
+			Eventf(corev1.EventTypeWarning, "DriftDetected", `Repaired drift: sink %q was modified: filter`, testSinkID),
+			Eventf(corev1.EventTypeNormal, reconciledSuccessReason, `CloudAuditLogsSource reconciled: "%s/%s"`, testNS, sourceName),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: v1.NewCloudAuditLogsSource(sourceName, testNS,
+				v1.WithCloudAuditLogsSourceUID(sourceUID),
+				v1.WithCloudAuditLogsSourceMethodName(testMethodName),
+				v1.WithCloudAuditLogsSourceServiceName(testServiceName),
+				v1.WithCloudAuditLogsSourceSink(sinkGVK, sinkName),
+				v1.WithCloudAuditLogsSourceProjectID(testProject),
+				v1.WithCloudAuditLogsSourceSubscriptionID(v1.SubscriptionID),
+				v1.WithInitCloudAuditLogsSourceConditions,
+				v1.WithCloudAuditLogsSourceTopicReady(testTopicID),
+				v1.WithCloudAuditLogsSourcePullSubscriptionReady,
+				v1.WithCloudAuditLogsSourceSinkURI(calSinkURL),
+				v1.WithCloudAuditLogsSourceSinkReady,
+				v1.WithCloudAuditLogsSourceSinkID(testSinkID),
+				v1.WithCloudAuditLogsSourceFilter(testFilter),
+				v1.WithCloudAuditLogsSourceDriftDetected(fmt.Sprintf(`Repaired drift: sink %q was modified: filter`, testSinkID)),
+				v1.WithCloudAuditLogsSourceSetDefaults,
+			),
+		}},
+	}, {
+		Name: "sink filter updated after spec change",
+		Objects: []runtime.Object{
+			v1.NewCloudAuditLogsSource(sourceName, testNS,
+				v1.WithCloudAuditLogsSourceUID(sourceUID),
+				v1.WithCloudAuditLogsSourceMethodName(testMethodName),
+				v1.WithCloudAuditLogsSourceServiceName(testServiceName),
+				v1.WithCloudAuditLogsSourceSink(sinkGVK, sinkName),
+				v1.WithCloudAuditLogsSourceSeverity("ERROR"),
+				v1.WithCloudAuditLogsSourceSinkID(testSinkID),
+				v1.WithCloudAuditLogsSourceFilter(testFilter),
+				v1.WithCloudAuditLogsSourceSetDefaults,
+			),
+			v1.NewTopic(sourceName, testNS,
+				v1.WithTopicSpec(inteventsv1.TopicSpec{
+					Topic:             testTopicID,
+					PropagationPolicy: "CreateDelete",
+					EnablePublisher:   &falseVal,
+				}),
+				v1.WithTopicReady(testTopicID),
+				v1.WithTopicAddress(testTopicURI),
+				v1.WithTopicProjectID(testProject),
+				v1.WithTopicSetDefaults,
+			),
+			v1.NewPullSubscription(sourceName, testNS,
+				v1.WithPullSubscriptionReady(sinkURI),
+				v1.WithPullSubscriptionSpec(inteventsv1.PullSubscriptionSpec{
+					Topic: testTopicID,
+					PubSubSpec: gcpduckv1.PubSubSpec{
+						Secret: &secret,
+						SourceSpec: duckv1.SourceSpec{
+							Sink: newSinkDestination(),
+						},
+					},
+					AdapterType: string(converters.CloudAuditLogs),
+				})),
+		},
+		Key: testNS + "/" + sourceName,
+		OtherTestData: map[string]interface{}{
+			"existingSinks": []logadmin.Sink{{
+				ID:          testSinkID,
+				Filter:      testFilter,
+				Destination: testTopicResource,
+			}},
+			"expectedSinks": map[string]*logadmin.Sink{
+				testSinkID: {
+					ID:          testSinkID,
+					Filter:      testFilter + " AND severity>=ERROR",
+					Destination: testTopicResource,
+				}},
+			"pubsub": gpubsub.TestClientData{
+				HandleData: testiam.TestHandleData{
+					Policy: publisherPolicy("writer-identity"),
+				},
+			},
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName, true),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
+			Eventf(corev1.EventTypeNormal, reconciledSuccessReason, `CloudAuditLogsSource reconciled: "%s/%s"`, testNS, sourceName),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: v1.NewCloudAuditLogsSource(sourceName, testNS,
+				v1.WithCloudAuditLogsSourceUID(sourceUID),
+				v1.WithCloudAuditLogsSourceMethodName(testMethodName),
+				v1.WithCloudAuditLogsSourceServiceName(testServiceName),
+				v1.WithCloudAuditLogsSourceSink(sinkGVK, sinkName),
+				v1.WithCloudAuditLogsSourceSeverity("ERROR"),
+				v1.WithCloudAuditLogsSourceProjectID(testProject),
+				v1.WithCloudAuditLogsSourceSubscriptionID(v1.SubscriptionID),
+				v1.WithInitCloudAuditLogsSourceConditions,
+				v1.WithCloudAuditLogsSourceTopicReady(testTopicID),
+				v1.WithCloudAuditLogsSourcePullSubscriptionReady,
+				v1.WithCloudAuditLogsSourceSinkURI(calSinkURL),
+				v1.WithCloudAuditLogsSourceSinkReady,
+				v1.WithCloudAuditLogsSourceSinkID(testSinkID),
+				v1.WithCloudAuditLogsSourceFilter(testFilter+" AND severity>=ERROR"),
 				v1.WithCloudAuditLogsSourceSetDefaults,
 			),
 		}},
@@ -1413,13 +1575,159 @@ func TestAllCases(t *testing.T) {
 				Name: sourceName,
 			},
 		},
+	}, {
+		Name: "organization sink created",
+		Objects: []runtime.Object{
+			v1.NewCloudAuditLogsSource(sourceName, testNS,
+				v1.WithCloudAuditLogsSourceUID(sourceUID),
+				v1.WithCloudAuditLogsSourceMethodName(testMethodName),
+				v1.WithCloudAuditLogsSourceServiceName(testServiceName),
+				v1.WithCloudAuditLogsSourceSink(sinkGVK, sinkName),
+				v1.WithCloudAuditLogsSourceServiceName(testServiceName),
+				v1.WithCloudAuditLogsSourceMethodName(testMethodName),
+				v1.WithCloudAuditLogsSourceParent(testOrganization, true),
+				v1.WithCloudAuditLogsSourceSetDefaults,
+			),
+			v1.NewTopic(sourceName, testNS,
+				v1.WithTopicSpec(inteventsv1.TopicSpec{
+					Topic:             testTopicID,
+					PropagationPolicy: "CreateDelete",
+					EnablePublisher:   &falseVal,
+				}),
+				v1.WithTopicReady(testTopicID),
+				v1.WithTopicAddress(testTopicURI),
+				v1.WithTopicProjectID(testProject),
+				v1.WithTopicSetDefaults,
+			),
+			v1.NewPullSubscription(sourceName, testNS,
+				v1.WithPullSubscriptionReady(sinkURI),
+				v1.WithPullSubscriptionSpec(inteventsv1.PullSubscriptionSpec{
+					Topic: testTopicID,
+					PubSubSpec: gcpduckv1.PubSubSpec{
+						Secret: &secret,
+						SourceSpec: duckv1.SourceSpec{
+							Sink: newSinkDestination(),
+						},
+					},
+					AdapterType: string(converters.CloudAuditLogs),
+				})),
+		},
+		Key: testNS + "/" + sourceName,
+		OtherTestData: map[string]interface{}{
+			"sinkParent": testOrganization,
+			"expectedSinks": map[string]*logadmin.Sink{
+				testSinkID: {
+					ID:              testSinkID,
+					Filter:          testFilter,
+					Destination:     testTopicResource,
+					IncludeChildren: true,
+				}},
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName, true),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
+			Eventf(corev1.EventTypeNormal, reconciledSuccessReason, `CloudAuditLogsSource reconciled: "%s/%s"`, testNS, sourceName),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: v1.NewCloudAuditLogsSource(sourceName, testNS,
+				v1.WithCloudAuditLogsSourceUID(sourceUID),
+				v1.WithCloudAuditLogsSourceMethodName(testMethodName),
+				v1.WithCloudAuditLogsSourceServiceName(testServiceName),
+				v1.WithCloudAuditLogsSourceSink(sinkGVK, sinkName),
+				v1.WithCloudAuditLogsSourceServiceName(testServiceName),
+				v1.WithCloudAuditLogsSourceMethodName(testMethodName),
+				v1.WithCloudAuditLogsSourceParent(testOrganization, true),
+				v1.WithCloudAuditLogsSourceProjectID(testProject),
+				v1.WithCloudAuditLogsSourceSubscriptionID(v1.SubscriptionID),
+				v1.WithInitCloudAuditLogsSourceConditions,
+				v1.WithCloudAuditLogsSourceTopicReady(testTopicID),
+				v1.WithCloudAuditLogsSourcePullSubscriptionReady,
+				v1.WithCloudAuditLogsSourceSinkURI(calSinkURL),
+				v1.WithCloudAuditLogsSourceSinkReady,
+				v1.WithCloudAuditLogsSourceSinkID(testSinkID),
+				v1.WithCloudAuditLogsSourceFilter(testFilter),
+				v1.WithCloudAuditLogsSourceSetDefaults,
+			),
+		}},
+	}, {
+		Name: "organization sink delete succeeds",
+		Objects: []runtime.Object{
+			v1.NewCloudAuditLogsSource(sourceName, testNS,
+				v1.WithCloudAuditLogsSourceUID(sourceUID),
+				v1.WithCloudAuditLogsSourceMethodName(testMethodName),
+				v1.WithCloudAuditLogsSourceServiceName(testServiceName),
+				v1.WithCloudAuditLogsSourceSink(sinkGVK, sinkName),
+				v1.WithCloudAuditLogsSourceParent(testOrganization, false),
+				v1.WithCloudAuditLogsSourceProjectID(testProject),
+				v1.WithInitCloudAuditLogsSourceConditions,
+				v1.WithCloudAuditLogsSourceTopicReady(testTopicID),
+				v1.WithCloudAuditLogsSourcePullSubscriptionReady,
+				v1.WithCloudAuditLogsSourceSinkURI(calSinkURL),
+				v1.WithCloudAuditLogsSourceSinkReady,
+				v1.WithCloudAuditLogsSourceSinkID(testSinkID),
+				v1.WithCloudAuditLogsSourceDeletionTimestamp,
+				v1.WithCloudAuditLogsSourceSetDefaults,
+			),
+			v1.NewTopic(sourceName, testNS,
+				v1.WithTopicReady(testTopicID),
+				v1.WithTopicAddress(testTopicURI),
+				v1.WithTopicProjectID(testProject),
+				v1.WithTopicSetDefaults,
+			),
+			v1.NewPullSubscription(sourceName, testNS,
+				v1.WithPullSubscriptionReady(sinkURI),
+			),
+		},
+		Key: testNS + "/" + sourceName,
+		OtherTestData: map[string]interface{}{
+			"sinkParent": testOrganization,
+			"existingSinks": []logadmin.Sink{{
+				ID:          testSinkID,
+				Filter:      testFilter,
+				Destination: testTopicResource,
+			}},
+			"expectedSinks": map[string]*logadmin.Sink{
+				testSinkID: nil,
+			},
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: v1.NewCloudAuditLogsSource(sourceName, testNS,
+				v1.WithCloudAuditLogsSourceUID(sourceUID),
+				v1.WithCloudAuditLogsSourceMethodName(testMethodName),
+				v1.WithCloudAuditLogsSourceServiceName(testServiceName),
+				v1.WithCloudAuditLogsSourceSink(sinkGVK, sinkName),
+				v1.WithCloudAuditLogsSourceParent(testOrganization, false),
+				v1.WithInitCloudAuditLogsSourceConditions,
+				v1.WithCloudAuditLogsSourceSinkDeleted,
+				v1.WithCloudAuditLogsSourceTopicDeleted,
+				v1.WithCloudAuditLogsSourcePullSubscriptionDeleted,
+				v1.WithCloudAuditLogsSourceDeletionTimestamp,
+				v1.WithCloudAuditLogsSourceSetDefaults,
+			),
+		}},
+		WantDeletes: []clientgotesting.DeleteActionImpl{
+			{ActionImpl: clientgotesting.ActionImpl{
+				Namespace: testNS, Verb: "delete", Resource: schema.GroupVersionResource{Group: "internal.events.cloud.google.com", Version: "v1", Resource: "topics"}},
+				Name: sourceName,
+			},
+			{ActionImpl: clientgotesting.ActionImpl{
+				Namespace: testNS, Verb: "delete", Resource: schema.GroupVersionResource{Group: "internal.events.cloud.google.com", Version: "v1", Resource: "pullsubscriptions"}},
+				Name: sourceName,
+			},
+		},
 	}}
 
 	for _, tt := range table {
 		t.Run(tt.Name, func(t *testing.T) {
 			logadminClientProvider := glogadmintesting.TestClientCreator(tt.OtherTestData["logadmin"])
+			sinkParent := testProject
+			if parent, ok := tt.OtherTestData["sinkParent"]; ok {
+				sinkParent = parent.(string)
+			}
 			if existingSinks := tt.OtherTestData["existingSinks"]; existingSinks != nil {
-				createSinks(t, logadminClientProvider, existingSinks.([]logadmin.Sink))
+				createSinks(t, logadminClientProvider, sinkParent, existingSinks.([]logadmin.Sink))
 			}
 			tt.Test(t, MakeFactory(
 				func(ctx context.Context, listers *Listers, cmw configmap.Watcher, testData map[string]interface{}) controller.Reconciler {
@@ -1430,6 +1738,7 @@ func TestAllCases(t *testing.T) {
 								ReceiveAdapterName:  receiveAdapterName,
 								ReceiveAdapterType:  string(converters.CloudAuditLogs),
 								ConfigWatcher:       cmw,
+								EventTypeLister:     listers.GetEventTypeLister(),
 							}),
 						Identity:               identity.NewIdentity(ctx, NoopIAMPolicyManager, NewGCPAuthTestStore(t, nil)),
 						auditLogsSourceLister:  listers.GetCloudAuditLogsSourceLister(),
@@ -1440,14 +1749,14 @@ func TestAllCases(t *testing.T) {
 					return cloudauditlogssource.NewReconciler(ctx, r.Logger, r.RunClientSet, listers.GetCloudAuditLogsSourceLister(), r.Recorder, r)
 				}))
 			if expectedSinks := tt.OtherTestData["expectedSinks"]; expectedSinks != nil {
-				expectSinks(t, logadminClientProvider, expectedSinks.(map[string]*logadmin.Sink))
+				expectSinks(t, logadminClientProvider, sinkParent, expectedSinks.(map[string]*logadmin.Sink))
 			}
 		})
 	}
 }
 
-func createSinks(t *testing.T, clientProvider glogadmin.CreateFn, sinks []logadmin.Sink) {
-	logadminClient, err := clientProvider(context.Background(), testProject)
+func createSinks(t *testing.T, clientProvider glogadmin.CreateFn, parent string, sinks []logadmin.Sink) {
+	logadminClient, err := clientProvider(context.Background(), parent)
 	if err != nil {
 		t.Fatalf("failed to create logadmin client during setup: %s", err)
 	}
@@ -1456,8 +1765,8 @@ func createSinks(t *testing.T, clientProvider glogadmin.CreateFn, sinks []logadm
 	}
 }
 
-func expectSinks(t *testing.T, clientProvider glogadmin.CreateFn, sinks map[string]*logadmin.Sink) {
-	logadminClient, err := clientProvider(context.Background(), testProject)
+func expectSinks(t *testing.T, clientProvider glogadmin.CreateFn, parent string, sinks map[string]*logadmin.Sink) {
+	logadminClient, err := clientProvider(context.Background(), parent)
 	if err != nil {
 		t.Fatalf("failed to create logadmin client during verification: %s", err)
 	}
@@ -1473,3 +1782,10 @@ func expectSinks(t *testing.T, clientProvider glogadmin.CreateFn, sinks map[stri
 		}
 	}
 }
+
+// publisherPolicy returns an IAM policy granting the publisher role to member.
+func publisherPolicy(member string) *iam.Policy {
+	policy := &iam.Policy{}
+	policy.Add(member, publisherRole)
+	return policy
+}
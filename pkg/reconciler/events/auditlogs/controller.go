@@ -23,6 +23,7 @@ import (
 	"knative.dev/pkg/injection"
 
 	"k8s.io/client-go/tools/cache"
+	eventtypeinformers "knative.dev/eventing/pkg/client/injection/informers/eventing/v1beta1/eventtype"
 	serviceaccountinformers "knative.dev/pkg/client/injection/kube/informers/core/v1/serviceaccount"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
@@ -74,6 +75,7 @@ func newController(
 	topicInformer := topicinformers.Get(ctx)
 	cloudauditlogssourceInformer := cloudauditlogssourceinformers.Get(ctx)
 	serviceAccountInformer := serviceaccountinformers.Get(ctx)
+	eventTypeInformer := eventtypeinformers.Get(ctx)
 
 	r := &Reconciler{
 		PubSubBase: intevents.NewPubSubBase(ctx,
@@ -82,6 +84,7 @@ func newController(
 				ReceiveAdapterName:  receiveAdapterName,
 				ReceiveAdapterType:  string(converters.CloudAuditLogs),
 				ConfigWatcher:       cmw,
+				EventTypeLister:     eventTypeInformer.Lister(),
 			}),
 		Identity:               identity.NewIdentity(ctx, ipm, gcpas),
 		auditLogsSourceLister:  cloudauditlogssourceInformer.Lister(),
@@ -112,5 +115,10 @@ func newController(
 		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
 	})
 
+	eventTypeInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterControllerGK(auditLogsGK),
+		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+	})
+
 	return impl
 }
@@ -30,6 +30,8 @@ import (
 	_ "github.com/google/knative-gcp/pkg/client/injection/informers/intevents/v1/pullsubscription/fake"
 	_ "github.com/google/knative-gcp/pkg/client/injection/informers/intevents/v1/topic/fake"
 	_ "github.com/google/knative-gcp/pkg/reconciler/testing"
+	_ "knative.dev/eventing/pkg/client/injection/client/fake"
+	_ "knative.dev/eventing/pkg/client/injection/informers/eventing/v1beta1/eventtype/fake"
 	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/serviceaccount/fake"
 )
 
@@ -2,25 +2,36 @@ package resources
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 )
 
 const (
-	keyPrefix   = "protoPayload"
-	methodKey   = keyPrefix + ".methodName"
-	serviceKey  = keyPrefix + ".serviceName"
-	resourceKey = keyPrefix + ".resourceName"
-	typeKey     = keyPrefix + ".\x22@type\x22"
-	typeValue   = "type.googleapis.com/google.cloud.audit.AuditLog"
+	keyPrefix       = "protoPayload"
+	methodKey       = keyPrefix + ".methodName"
+	serviceKey      = keyPrefix + ".serviceName"
+	resourceKey     = keyPrefix + ".resourceName"
+	principalKey    = keyPrefix + ".authenticationInfo.principalEmail"
+	typeKey         = keyPrefix + ".\x22@type\x22"
+	typeValue       = "type.googleapis.com/google.cloud.audit.AuditLog"
+	severityKey     = "severity"
+	resourceTypeKey = "resource.type"
+	logNameKey      = "logName"
 )
 
 // Stackdriver query builder for querying audit logs. Currently
-// supports querying by the AuditLog serviceName, methodName, and
-// resourceName.
+// supports querying by the AuditLog serviceName, methodName,
+// resourceName and principalEmail, by the severity, resource type and
+// log name of the log entries, and by an additional raw filter.
 type FilterBuilder struct {
-	serviceName  string
-	methodName   string
-	resourceName string
+	serviceName     string
+	methodNames     []string
+	resourceName    string
+	severity        string
+	principalEmails []string
+	resourceType    string
+	logName         string
+	filter          string
 }
 
 func (fb *FilterBuilder) WithServiceName(serviceName string) *FilterBuilder {
@@ -29,7 +40,15 @@ func (fb *FilterBuilder) WithServiceName(serviceName string) *FilterBuilder {
 }
 
 func (fb *FilterBuilder) WithMethodName(methodName string) *FilterBuilder {
-	fb.methodName = methodName
+	if methodName != "" {
+		fb.methodNames = append(fb.methodNames, methodName)
+	}
+	return fb
+}
+
+// WithMethodNames matches any of the methodNames.
+func (fb *FilterBuilder) WithMethodNames(methodNames ...string) *FilterBuilder {
+	fb.methodNames = append(fb.methodNames, methodNames...)
 	return fb
 }
 
@@ -38,10 +57,41 @@ func (fb *FilterBuilder) WithResourceName(resourceName string) *FilterBuilder {
 	return fb
 }
 
+// WithSeverity matches the log entries with at least the given severity.
+func (fb *FilterBuilder) WithSeverity(severity string) *FilterBuilder {
+	fb.severity = severity
+	return fb
+}
+
+// WithPrincipalEmails matches any of the principalEmails.
+func (fb *FilterBuilder) WithPrincipalEmails(principalEmails ...string) *FilterBuilder {
+	fb.principalEmails = append(fb.principalEmails, principalEmails...)
+	return fb
+}
+
+// WithResourceType matches the log entries of the given monitored resource type.
+func (fb *FilterBuilder) WithResourceType(resourceType string) *FilterBuilder {
+	fb.resourceType = resourceType
+	return fb
+}
+
+// WithLogName matches the log entries of the log with the given ID, in any
+// project, folder or organization.
+func (fb *FilterBuilder) WithLogName(logName string) *FilterBuilder {
+	fb.logName = logName
+	return fb
+}
+
+// WithFilter adds a raw filter in the Cloud Logging query language.
+func (fb *FilterBuilder) WithFilter(filter string) *FilterBuilder {
+	fb.filter = filter
+	return fb
+}
+
 func (fb *FilterBuilder) GetFilterQuery() string {
 	var filters []string
-	if fb.methodName != "" {
-		filters = append(filters, filter{methodKey, fb.methodName}.String())
+	if len(fb.methodNames) != 0 {
+		filters = append(filters, anyOf(methodKey, fb.methodNames))
 	}
 
 	if fb.serviceName != "" {
@@ -53,10 +103,46 @@ func (fb *FilterBuilder) GetFilterQuery() string {
 	}
 
 	filters = append(filters, filter{typeKey, typeValue}.String())
+
+	// The terms below are only added when set, so that the filter of the
+	// sinks created before they existed is unchanged.
+	if fb.severity != "" {
+		filters = append(filters, fmt.Sprintf("%s>=%s", severityKey, fb.severity))
+	}
+
+	if len(fb.principalEmails) != 0 {
+		filters = append(filters, anyOf(principalKey, fb.principalEmails))
+	}
+
+	if fb.resourceType != "" {
+		filters = append(filters, filter{resourceTypeKey, fb.resourceType}.String())
+	}
+
+	if fb.logName != "" {
+		// Log IDs are URL-encoded in the names of the logs.
+		filters = append(filters, fmt.Sprintf("%s:%q", logNameKey, "/logs/"+url.PathEscape(fb.logName)))
+	}
+
+	if fb.filter != "" {
+		filters = append(filters, "("+fb.filter+")")
+	}
+
 	filter := strings.Join(filters, " AND ")
 	return filter
 }
 
+// anyOf returns a filter matching any of the values of the key.
+func anyOf(key string, values []string) string {
+	if len(values) == 1 {
+		return filter{key, values[0]}.String()
+	}
+	filters := make([]string, 0, len(values))
+	for _, v := range values {
+		filters = append(filters, filter{key, v}.String())
+	}
+	return "(" + strings.Join(filters, " OR ") + ")"
+}
+
 type filter struct {
 	key   string
 	value string
@@ -0,0 +1,62 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import "testing"
+
+func TestGetFilterQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		fb   *FilterBuilder
+		want string
+	}{{
+		name: "service and method",
+		fb:   (&FilterBuilder{}).WithServiceName("pubsub.googleapis.com").WithMethodName("google.pubsub.v1.Publisher.CreateTopic"),
+		want: `protoPayload.methodName="google.pubsub.v1.Publisher.CreateTopic" AND protoPayload.serviceName="pubsub.googleapis.com" AND protoPayload."@type"="type.googleapis.com/google.cloud.audit.AuditLog"`,
+	}, {
+		name: "single method name",
+		fb:   (&FilterBuilder{}).WithServiceName("iam.googleapis.com").WithMethodNames("SetIamPolicy"),
+		want: `protoPayload.methodName="SetIamPolicy" AND protoPayload.serviceName="iam.googleapis.com" AND protoPayload."@type"="type.googleapis.com/google.cloud.audit.AuditLog"`,
+	}, {
+		name: "all fields",
+		fb: (&FilterBuilder{}).
+			WithServiceName("iam.googleapis.com").
+			WithMethodNames("SetIamPolicy", "CreateServiceAccountKey").
+			WithResourceName("projects/p").
+			WithSeverity("NOTICE").
+			WithPrincipalEmails("alice@example.com", "bob@example.com").
+			WithResourceType("service_account").
+			WithLogName("cloudaudit.googleapis.com/activity").
+			WithFilter(`protoPayload.status.code!=0 OR severity>=ERROR`),
+		want: `(protoPayload.methodName="SetIamPolicy" OR protoPayload.methodName="CreateServiceAccountKey")` +
+			` AND protoPayload.serviceName="iam.googleapis.com"` +
+			` AND protoPayload.resourceName="projects/p"` +
+			` AND protoPayload."@type"="type.googleapis.com/google.cloud.audit.AuditLog"` +
+			` AND severity>=NOTICE` +
+			` AND (protoPayload.authenticationInfo.principalEmail="alice@example.com" OR protoPayload.authenticationInfo.principalEmail="bob@example.com")` +
+			` AND resource.type="service_account"` +
+			` AND logName:"/logs/cloudaudit.googleapis.com%2Factivity"` +
+			` AND (protoPayload.status.code!=0 OR severity>=ERROR)`,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.fb.GetFilterQuery(); got != test.want {
+				t.Errorf("GetFilterQuery() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -41,3 +41,12 @@ func GenerateTopicResourceName(s *v1.CloudAuditLogsSource) string {
 func GenerateSinkName(s *v1.CloudAuditLogsSource) string {
 	return naming.TruncatedLoggingSinkResourceName("cre-src", s.Namespace, s.Name, s.UID)
 }
+
+// SinkParent returns the parent of the Stackdriver sink of an
+// CloudAuditLogsSource, which is either its Parent or its project.
+func SinkParent(s *v1.CloudAuditLogsSource) string {
+	if s.Spec.Parent != "" {
+		return s.Spec.Parent
+	}
+	return s.Status.ProjectID
+}
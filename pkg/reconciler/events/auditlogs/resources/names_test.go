@@ -70,3 +70,21 @@ func TestGenerateSinkName(t *testing.T) {
 		t.Errorf("unexpected (-want, +got) = %v", diff)
 	}
 }
+
+func TestSinkParent(t *testing.T) {
+	s := &v1.CloudAuditLogsSource{
+		Status: v1.CloudAuditLogsSourceStatus{
+			PubSubStatus: duckv1.PubSubStatus{
+				ProjectID: "project",
+			},
+		},
+	}
+	if diff := cmp.Diff("project", SinkParent(s)); diff != "" {
+		t.Errorf("unexpected (-want, +got) = %v", diff)
+	}
+
+	s.Spec.Parent = "organizations/123"
+	if diff := cmp.Diff("organizations/123", SinkParent(s)); diff != "" {
+		t.Errorf("unexpected (-want, +got) = %v", diff)
+	}
+}
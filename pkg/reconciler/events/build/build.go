@@ -33,6 +33,7 @@ import (
 	listers "github.com/google/knative-gcp/pkg/client/listers/events/v1"
 	"github.com/google/knative-gcp/pkg/reconciler/identity"
 	"github.com/google/knative-gcp/pkg/reconciler/intevents"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
 )
 
 const (
@@ -77,6 +78,11 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, build *v1.CloudBuildSour
 		return event
 	}
 
+	// The source of the events depends on the build.
+	if event := r.PubSubBase.ReconcileEventTypes(ctx, build, "", schemasv1.EventTypes(build.GetGroupVersionKind().Kind)); event != nil {
+		return event
+	}
+
 	return pkgreconciler.NewEvent(corev1.EventTypeNormal, reconciledSuccessReason, `CloudBuildSource reconciled: "%s/%s"`, build.Namespace, build.Name)
 }
 
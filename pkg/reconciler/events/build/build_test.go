@@ -376,6 +376,7 @@ func TestAllCases(t *testing.T) {
 					ReceiveAdapterName:  receiveAdapterName,
 					ReceiveAdapterType:  string(converters.CloudBuild),
 					ConfigWatcher:       cmw,
+					EventTypeLister:     listers.GetEventTypeLister(),
 				}),
 			Identity:             identity.NewIdentity(ctx, NoopIAMPolicyManager, NewGCPAuthTestStore(t, nil)),
 			buildLister:          listers.GetCloudBuildSourceLister(),
@@ -19,6 +19,7 @@ import (
 	"knative.dev/pkg/injection"
 
 	"k8s.io/client-go/tools/cache"
+	eventtypeinformers "knative.dev/eventing/pkg/client/injection/informers/eventing/v1beta1/eventtype"
 	serviceaccountinformers "knative.dev/pkg/client/injection/kube/informers/core/v1/serviceaccount"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
@@ -65,6 +66,7 @@ func newController(
 	pullsubscriptionInformer := pullsubscriptioninformers.Get(ctx)
 	cloudbuildsourceInformer := cloudbuildsourceinformers.Get(ctx)
 	serviceAccountInformer := serviceaccountinformers.Get(ctx)
+	eventTypeInformer := eventtypeinformers.Get(ctx)
 
 	r := &Reconciler{
 		PubSubBase: intevents.NewPubSubBase(ctx,
@@ -73,6 +75,7 @@ func newController(
 				ReceiveAdapterName:  receiveAdapterName,
 				ReceiveAdapterType:  string(converters.CloudBuild),
 				ConfigWatcher:       cmw,
+				EventTypeLister:     eventTypeInformer.Lister(),
 			}),
 		Identity:             identity.NewIdentity(ctx, ipm, gcpas),
 		buildLister:          cloudbuildsourceInformer.Lister(),
@@ -89,5 +92,10 @@ func newController(
 		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
 	})
 
+	eventTypeInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterControllerGK(v1.Kind("CloudBuildSource")),
+		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+	})
+
 	return impl
 }
@@ -22,6 +22,7 @@ import (
 	"knative.dev/pkg/injection"
 
 	"k8s.io/client-go/tools/cache"
+	eventtypeinformers "knative.dev/eventing/pkg/client/injection/informers/eventing/v1beta1/eventtype"
 	serviceaccountinformers "knative.dev/pkg/client/injection/kube/informers/core/v1/serviceaccount"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
@@ -68,6 +69,7 @@ func newController(
 	pullsubscriptionInformer := pullsubscriptioninformers.Get(ctx)
 	cloudpubsubsourceInformer := cloudpubsubsourceinformers.Get(ctx)
 	serviceAccountInformer := serviceaccountinformers.Get(ctx)
+	eventTypeInformer := eventtypeinformers.Get(ctx)
 
 	r := &Reconciler{
 		PubSubBase: intevents.NewPubSubBase(ctx,
@@ -76,6 +78,7 @@ func newController(
 				ReceiveAdapterName:  receiveAdapterName,
 				ReceiveAdapterType:  string(converters.CloudPubSub),
 				ConfigWatcher:       cmw,
+				EventTypeLister:     eventTypeInformer.Lister(),
 			}),
 		Identity:     identity.NewIdentity(ctx, ipm, gcpas),
 		pubsubLister: cloudpubsubsourceInformer.Lister(),
@@ -98,5 +101,10 @@ func newController(
 		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
 	})
 
+	eventTypeInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterControllerGK(pubsubGK),
+		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+	})
+
 	return impl
 }
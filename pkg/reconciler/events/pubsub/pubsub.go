@@ -29,6 +29,7 @@ import (
 	listers "github.com/google/knative-gcp/pkg/client/listers/events/v1"
 	"github.com/google/knative-gcp/pkg/reconciler/identity"
 	"github.com/google/knative-gcp/pkg/reconciler/intevents"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
 )
 
 const (
@@ -64,10 +65,18 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, pubsub *v1.CloudPubSubSo
 		}
 	}
 
-	_, event := r.PubSubBase.ReconcilePullSubscription(ctx, pubsub, pubsub.Spec.Topic, resourceGroup)
+	ps, event := r.PubSubBase.ReconcilePullSubscription(ctx, pubsub, pubsub.Spec.Topic, resourceGroup)
 	if event != nil {
 		return event
 	}
+
+	var source string
+	if ps.Status.ProjectID != "" {
+		source = schemasv1.CloudPubSubEventSource(ps.Status.ProjectID, pubsub.Spec.Topic)
+	}
+	if event := r.PubSubBase.ReconcileEventTypes(ctx, pubsub, source, schemasv1.EventTypes(pubsub.GetGroupVersionKind().Kind)); event != nil {
+		return event
+	}
 	return pkgreconciler.NewEvent(corev1.EventTypeNormal, reconciledSuccessReason, `CloudPubSubSource reconciled: "%s/%s"`, pubsub.Namespace, pubsub.Name)
 }
 
@@ -379,6 +379,7 @@ func TestAllCases(t *testing.T) {
 					ReceiveAdapterName:  receiveAdapterName,
 					ReceiveAdapterType:  string(converters.CloudPubSub),
 					ConfigWatcher:       cmw,
+					EventTypeLister:     listers.GetEventTypeLister(),
 				}),
 			Identity:     identity.NewIdentity(ctx, NoopIAMPolicyManager, NewGCPAuthTestStore(t, nil)),
 			pubsubLister: listers.GetCloudPubSubSourceLister(),
@@ -29,6 +29,7 @@ import (
 	"github.com/google/knative-gcp/pkg/reconciler/identity/iam"
 	"github.com/google/knative-gcp/pkg/reconciler/intevents"
 	"k8s.io/client-go/tools/cache"
+	eventtypeinformers "knative.dev/eventing/pkg/client/injection/informers/eventing/v1beta1/eventtype"
 	serviceaccountinformers "knative.dev/pkg/client/injection/kube/informers/core/v1/serviceaccount"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
@@ -71,6 +72,7 @@ func newController(
 	topicInformer := topicinformers.Get(ctx)
 	cloudschedulersourceInformer := cloudschedulersourceinformers.Get(ctx)
 	serviceAccountInformer := serviceaccountinformers.Get(ctx)
+	eventTypeInformer := eventtypeinformers.Get(ctx)
 
 	c := &Reconciler{
 		PubSubBase: intevents.NewPubSubBase(ctx,
@@ -79,6 +81,7 @@ func newController(
 				ReceiveAdapterName:  receiveAdapterName,
 				ReceiveAdapterType:  string(converters.CloudScheduler),
 				ConfigWatcher:       cmw,
+				EventTypeLister:     eventTypeInformer.Lister(),
 			}),
 		Identity:        identity.NewIdentity(ctx, ipm, gcpas),
 		schedulerLister: cloudschedulersourceInformer.Lister(),
@@ -106,5 +109,10 @@ func newController(
 		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
 	})
 
+	eventTypeInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterControllerGK(schedulerGK),
+		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+	})
+
 	return impl
 }
@@ -0,0 +1,165 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	schedulerpb "google.golang.org/genproto/googleapis/cloud/scheduler/v1"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	v1 "github.com/google/knative-gcp/pkg/apis/events/v1"
+)
+
+// MakeJob makes the desired Cloud Scheduler job of a CloudSchedulerSource, publishing to the
+// given topic.
+func MakeJob(scheduler *v1.CloudSchedulerSource, topic, jobName string) *schedulerpb.Job {
+	attributes := make(map[string]string, len(scheduler.Spec.Attributes)+1)
+	for k, v := range scheduler.Spec.Attributes {
+		attributes[k] = v
+	}
+	// Add jobName as customAttribute.
+	attributes[v1.CloudSchedulerSourceJobName] = jobName
+
+	return &schedulerpb.Job{
+		Name: jobName,
+		Target: &schedulerpb.Job_PubsubTarget{
+			PubsubTarget: &schedulerpb.PubsubTarget{
+				TopicName:  GeneratePubSubTargetTopic(scheduler, topic),
+				Data:       jobData(&scheduler.Spec),
+				Attributes: attributes,
+			},
+		},
+		Schedule:    scheduler.Spec.Schedule,
+		TimeZone:    scheduler.Spec.TimeZone,
+		RetryConfig: makeRetryConfig(scheduler.Spec.RetryConfig),
+	}
+}
+
+// jobData returns the payload of the messages published by the job, from whichever of Data,
+// JSONData and BinaryData is set.
+func jobData(spec *v1.CloudSchedulerSourceSpec) []byte {
+	switch {
+	case spec.JSONData != nil:
+		return spec.JSONData.Raw
+	case len(spec.BinaryData) > 0:
+		return spec.BinaryData
+	default:
+		return []byte(spec.Data)
+	}
+}
+
+func makeRetryConfig(config *v1.CloudSchedulerSourceRetryConfig) *schedulerpb.RetryConfig {
+	if config == nil {
+		return nil
+	}
+	retry := &schedulerpb.RetryConfig{
+		MaxRetryDuration:   durationProto(config.MaxRetryDuration),
+		MinBackoffDuration: durationProto(config.MinBackoffDuration),
+		MaxBackoffDuration: durationProto(config.MaxBackoffDuration),
+	}
+	if config.RetryCount != nil {
+		retry.RetryCount = *config.RetryCount
+	}
+	if config.MaxDoublings != nil {
+		retry.MaxDoublings = *config.MaxDoublings
+	}
+	return retry
+}
+
+// durationProto converts a duration of the spec, which has been validated already.
+func durationProto(duration *string) *durationpb.Duration {
+	if duration == nil {
+		return nil
+	}
+	d, err := time.ParseDuration(*duration)
+	if err != nil {
+		return nil
+	}
+	return ptypes.DurationProto(d)
+}
+
+// JobDrift returns the paths of the fields of the live job that differ from the desired job, as
+// used in the update mask of an UpdateJobRequest. The time zone and retry configuration are only
+// compared when they are set in the desired job, as Cloud Scheduler fills in their defaults.
+func JobDrift(desired, live *schedulerpb.Job) []string {
+	var paths []string
+	if live.GetSchedule() != desired.GetSchedule() {
+		paths = append(paths, "schedule")
+	}
+	if desired.GetTimeZone() != "" && live.GetTimeZone() != desired.GetTimeZone() {
+		paths = append(paths, "time_zone")
+	}
+	if !pubsubTargetEqual(desired.GetPubsubTarget(), live.GetPubsubTarget()) {
+		paths = append(paths, "pubsub_target")
+	}
+	if desired.GetRetryConfig() != nil && !retryConfigEqual(desired.GetRetryConfig(), live.GetRetryConfig()) {
+		paths = append(paths, "retry_config")
+	}
+	return paths
+}
+
+// retryConfigEqual returns whether the live retry configuration matches the desired one. Cloud
+// Scheduler uses its defaults for the zero fields of the desired configuration, other than
+// RetryCount and MaxRetryDuration whose defaults are zero, so those are not compared.
+func retryConfigEqual(desired, live *schedulerpb.RetryConfig) bool {
+	if live == nil {
+		live = &schedulerpb.RetryConfig{}
+	}
+	if desired.RetryCount != live.RetryCount ||
+		durationValue(desired.MaxRetryDuration) != durationValue(live.MaxRetryDuration) {
+		return false
+	}
+	if desired.MaxDoublings != 0 && desired.MaxDoublings != live.MaxDoublings {
+		return false
+	}
+	for _, d := range []struct{ desired, live *durationpb.Duration }{
+		{desired.MinBackoffDuration, live.MinBackoffDuration},
+		{desired.MaxBackoffDuration, live.MaxBackoffDuration},
+	} {
+		if d.desired != nil && durationValue(d.desired) != durationValue(d.live) {
+			return false
+		}
+	}
+	return true
+}
+
+// durationValue returns the value of a duration, zero if unset.
+func durationValue(d *durationpb.Duration) time.Duration {
+	if d == nil {
+		return 0
+	}
+	v, _ := ptypes.Duration(d)
+	return v
+}
+
+func pubsubTargetEqual(a, b *schedulerpb.PubsubTarget) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.TopicName != b.TopicName || !bytes.Equal(a.Data, b.Data) || len(a.Attributes) != len(b.Attributes) {
+		return false
+	}
+	for k, v := range a.Attributes {
+		if got, ok := b.Attributes[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
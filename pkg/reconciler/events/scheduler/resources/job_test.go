@@ -0,0 +1,257 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/go-cmp/cmp"
+	schedulerpb "google.golang.org/genproto/googleapis/cloud/scheduler/v1"
+	"google.golang.org/protobuf/testing/protocmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/ptr"
+
+	duckv1 "github.com/google/knative-gcp/pkg/apis/duck/v1"
+	v1 "github.com/google/knative-gcp/pkg/apis/events/v1"
+)
+
+func TestMakeJob(t *testing.T) {
+	jobName := "projects/project/locations/location/jobs/cre-scheduler-uid"
+	tests := []struct {
+		name   string
+		modify func(*v1.CloudSchedulerSourceSpec)
+		want   *schedulerpb.Job
+	}{{
+		name:   "text data",
+		modify: func(*v1.CloudSchedulerSourceSpec) {},
+		want: &schedulerpb.Job{
+			Name: jobName,
+			Target: &schedulerpb.Job_PubsubTarget{
+				PubsubTarget: &schedulerpb.PubsubTarget{
+					TopicName:  "projects/project/topics/topic",
+					Data:       []byte("data"),
+					Attributes: map[string]string{"jobName": jobName},
+				},
+			},
+			Schedule: "* * * * *",
+		},
+	}, {
+		name: "json data, time zone and attributes",
+		modify: func(s *v1.CloudSchedulerSourceSpec) {
+			s.Data = ""
+			s.JSONData = &runtime.RawExtension{Raw: []byte(`{"report":"daily"}`)}
+			s.TimeZone = "America/New_York"
+			s.Attributes = map[string]string{"team": "billing"}
+		},
+		want: &schedulerpb.Job{
+			Name: jobName,
+			Target: &schedulerpb.Job_PubsubTarget{
+				PubsubTarget: &schedulerpb.PubsubTarget{
+					TopicName:  "projects/project/topics/topic",
+					Data:       []byte(`{"report":"daily"}`),
+					Attributes: map[string]string{"jobName": jobName, "team": "billing"},
+				},
+			},
+			Schedule: "* * * * *",
+			TimeZone: "America/New_York",
+		},
+	}, {
+		name: "binary data and retry config",
+		modify: func(s *v1.CloudSchedulerSourceSpec) {
+			s.Data = ""
+			s.BinaryData = []byte{0xca, 0xfe}
+			s.RetryConfig = &v1.CloudSchedulerSourceRetryConfig{
+				RetryCount:         ptr.Int32(3),
+				MinBackoffDuration: ptr.String("10s"),
+				MaxDoublings:       ptr.Int32(2),
+			}
+		},
+		want: &schedulerpb.Job{
+			Name: jobName,
+			Target: &schedulerpb.Job_PubsubTarget{
+				PubsubTarget: &schedulerpb.PubsubTarget{
+					TopicName:  "projects/project/topics/topic",
+					Data:       []byte{0xca, 0xfe},
+					Attributes: map[string]string{"jobName": jobName},
+				},
+			},
+			Schedule: "* * * * *",
+			RetryConfig: &schedulerpb.RetryConfig{
+				RetryCount:         3,
+				MinBackoffDuration: ptypes.DurationProto(10 * time.Second),
+				MaxDoublings:       2,
+			},
+		},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scheduler := &v1.CloudSchedulerSource{
+				Spec: v1.CloudSchedulerSourceSpec{
+					Location: "location",
+					Schedule: "* * * * *",
+					Data:     "data",
+				},
+				Status: v1.CloudSchedulerSourceStatus{
+					PubSubStatus: duckv1.PubSubStatus{
+						ProjectID: "project",
+					},
+				},
+			}
+			test.modify(&scheduler.Spec)
+			got := MakeJob(scheduler, "topic", jobName)
+			if diff := cmp.Diff(test.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestJobDrift(t *testing.T) {
+	scheduler := &v1.CloudSchedulerSource{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: "uid",
+		},
+		Spec: v1.CloudSchedulerSourceSpec{
+			Location: "location",
+			Schedule: "* * * * *",
+			Data:     "data",
+		},
+		Status: v1.CloudSchedulerSourceStatus{
+			PubSubStatus: duckv1.PubSubStatus{
+				ProjectID: "project",
+			},
+		},
+	}
+	jobName := GenerateJobName(scheduler)
+	desired := MakeJob(scheduler, "topic", jobName)
+
+	tests := []struct {
+		name   string
+		modify func(*schedulerpb.Job)
+		want   []string
+	}{{
+		name:   "no drift",
+		modify: func(*schedulerpb.Job) {},
+	}, {
+		name: "schedule changed",
+		modify: func(j *schedulerpb.Job) {
+			j.Schedule = "0 * * * *"
+		},
+		want: []string{"schedule"},
+	}, {
+		name: "data changed",
+		modify: func(j *schedulerpb.Job) {
+			j.GetPubsubTarget().Data = []byte("other")
+		},
+		want: []string{"pubsub_target"},
+	}, {
+		name: "attributes changed",
+		modify: func(j *schedulerpb.Job) {
+			j.GetPubsubTarget().Attributes["other"] = "value"
+		},
+		want: []string{"pubsub_target"},
+	}, {
+		name: "target replaced",
+		modify: func(j *schedulerpb.Job) {
+			j.Schedule = ""
+			j.Target = &schedulerpb.Job_HttpTarget{HttpTarget: &schedulerpb.HttpTarget{Uri: "https://example.com"}}
+		},
+		want: []string{"schedule", "pubsub_target"},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			live := MakeJob(scheduler, "topic", jobName)
+			test.modify(live)
+			if diff := cmp.Diff(test.want, JobDrift(desired, live)); diff != "" {
+				t.Errorf("unexpected (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestJobDriftTimeZoneAndRetryConfig(t *testing.T) {
+	scheduler := &v1.CloudSchedulerSource{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: "uid",
+		},
+		Spec: v1.CloudSchedulerSourceSpec{
+			Location: "location",
+			Schedule: "* * * * *",
+			TimeZone: "Europe/Paris",
+			Data:     "data",
+			RetryConfig: &v1.CloudSchedulerSourceRetryConfig{
+				RetryCount:         ptr.Int32(3),
+				MaxBackoffDuration: ptr.String("10m"),
+			},
+		},
+		Status: v1.CloudSchedulerSourceStatus{
+			PubSubStatus: duckv1.PubSubStatus{
+				ProjectID: "project",
+			},
+		},
+	}
+	jobName := GenerateJobName(scheduler)
+	desired := MakeJob(scheduler, "topic", jobName)
+
+	tests := []struct {
+		name   string
+		modify func(*schedulerpb.Job)
+		want   []string
+	}{{
+		name: "defaults filled in",
+		modify: func(j *schedulerpb.Job) {
+			j.RetryConfig.MinBackoffDuration = ptypes.DurationProto(5 * time.Second)
+			j.RetryConfig.MaxDoublings = 5
+		},
+	}, {
+		name: "time zone changed",
+		modify: func(j *schedulerpb.Job) {
+			j.TimeZone = "Etc/UTC"
+		},
+		want: []string{"time_zone"},
+	}, {
+		name: "retry count changed",
+		modify: func(j *schedulerpb.Job) {
+			j.RetryConfig.RetryCount = 0
+		},
+		want: []string{"retry_config"},
+	}, {
+		name: "backoff changed",
+		modify: func(j *schedulerpb.Job) {
+			j.RetryConfig.MaxBackoffDuration = ptypes.DurationProto(time.Hour)
+		},
+		want: []string{"retry_config"},
+	}, {
+		name: "retry config removed",
+		modify: func(j *schedulerpb.Job) {
+			j.RetryConfig = nil
+		},
+		want: []string{"retry_config"},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			live := MakeJob(scheduler, "topic", jobName)
+			test.modify(live)
+			if diff := cmp.Diff(test.want, JobDrift(desired, live)); diff != "" {
+				t.Errorf("unexpected (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
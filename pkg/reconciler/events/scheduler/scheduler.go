@@ -18,9 +18,12 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"go.uber.org/zap"
 	schedulerpb "google.golang.org/genproto/googleapis/cloud/scheduler/v1"
+	"google.golang.org/genproto/protobuf/field_mask"
 	"google.golang.org/grpc/codes"
 	gstatus "google.golang.org/grpc/status"
 	corev1 "k8s.io/api/core/v1"
@@ -35,6 +38,7 @@ import (
 	"github.com/google/knative-gcp/pkg/reconciler/events/scheduler/resources"
 	"github.com/google/knative-gcp/pkg/reconciler/identity"
 	"github.com/google/knative-gcp/pkg/reconciler/intevents"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
 	"github.com/google/knative-gcp/pkg/utils"
 )
 
@@ -84,21 +88,29 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, scheduler *v1.CloudSched
 	}
 
 	jobName := resources.GenerateJobName(scheduler)
-	err = r.reconcileJob(ctx, scheduler, topic, jobName)
+	drift, err := r.reconcileJob(ctx, scheduler, topic, jobName)
 	if err != nil {
 		scheduler.Status.MarkJobNotReady(reconciledFailedReason, "Failed to reconcile CloudSchedulerSource job: %s", err.Error())
 		return reconciler.NewEvent(corev1.EventTypeWarning, reconciledFailedReason, "Reconcile Job failed with: %s", err.Error())
 	}
 	scheduler.Status.MarkJobReady(jobName)
+	r.PubSubBase.ReportDrift(ctx, scheduler, drift)
+
+	if event := r.PubSubBase.ReconcileEventTypes(ctx, scheduler, schemasv1.CloudSchedulerEventSource(jobName), schemasv1.EventTypes(scheduler.GetGroupVersionKind().Kind)); event != nil {
+		return event
+	}
 	return reconciler.NewEvent(corev1.EventTypeNormal, reconciledSuccessReason, `CloudSchedulerSource reconciled: "%s/%s"`, scheduler.Namespace, scheduler.Name)
 }
 
-func (r *Reconciler) reconcileJob(ctx context.Context, scheduler *v1.CloudSchedulerSource, topic, jobName string) error {
+// reconcileJob makes sure that the job of the scheduler exists and matches its desired state,
+// creating, updating, pausing or resuming it if needed. It returns the drift of the job that was
+// repaired, if any.
+func (r *Reconciler) reconcileJob(ctx context.Context, scheduler *v1.CloudSchedulerSource, topic, jobName string) ([]string, error) {
 	if scheduler.Status.ProjectID == "" {
 		projectID, err := utils.ProjectID(scheduler.Spec.Project, metadataClient.NewDefaultMetadataClient())
 		if err != nil {
 			logging.FromContext(ctx).Desugar().Error("Failed to find project id", zap.Error(err))
-			return err
+			return nil, err
 		}
 		// Set the projectID in the status.
 		scheduler.Status.ProjectID = projectID
@@ -107,43 +119,68 @@ func (r *Reconciler) reconcileJob(ctx context.Context, scheduler *v1.CloudSchedu
 	client, err := r.createClientFn(ctx)
 	if err != nil {
 		logging.FromContext(ctx).Desugar().Error("Failed to create CloudSchedulerSource client", zap.Error(err))
-		return err
+		return nil, err
 	}
 	defer client.Close()
 
+	desired := resources.MakeJob(scheduler, topic, jobName)
+
 	// Check if the job exists.
-	_, err = client.GetJob(ctx, &schedulerpb.GetJobRequest{Name: jobName})
+	var drift []string
+	job, err := client.GetJob(ctx, &schedulerpb.GetJobRequest{Name: jobName})
 	if err != nil {
 		if st, ok := gstatus.FromError(err); !ok {
 			logging.FromContext(ctx).Desugar().Error("Failed from CloudSchedulerSource client while retrieving CloudSchedulerSource job", zap.String("jobName", jobName), zap.Error(err))
-			return err
+			return nil, err
 		} else if st.Code() == codes.NotFound {
 			// Create the job as it does not exist. For creation, we need a parent, extract it from the jobName.
-			parent := resources.ExtractParentName(jobName)
-			// Add jobName as customAttribute.
-			customAttributes := map[string]string{
-				v1.CloudSchedulerSourceJobName: jobName,
-			}
-			_, err = client.CreateJob(ctx, &schedulerpb.CreateJobRequest{
-				Parent: parent,
-				Job: &schedulerpb.Job{
-					Name: jobName,
-					Target: &schedulerpb.Job_PubsubTarget{
-						PubsubTarget: &schedulerpb.PubsubTarget{
-							TopicName:  resources.GeneratePubSubTargetTopic(scheduler, topic),
-							Data:       []byte(scheduler.Spec.Data),
-							Attributes: customAttributes,
-						},
-					},
-					Schedule: scheduler.Spec.Schedule,
-				},
+			job, err = client.CreateJob(ctx, &schedulerpb.CreateJobRequest{
+				Parent: resources.ExtractParentName(jobName),
+				Job:    desired,
 			})
 			if err != nil {
 				logging.FromContext(ctx).Desugar().Error("Failed to create CloudSchedulerSource job", zap.String("jobName", jobName), zap.Error(err))
-				return err
+				return nil, err
+			}
+			// The job was created before, so it was deleted out of band.
+			if scheduler.Status.JobName == jobName {
+				drift = append(drift, fmt.Sprintf("job %q was deleted", jobName))
 			}
 		} else {
 			logging.FromContext(ctx).Desugar().Error("Failed from CloudSchedulerSource client while retrieving CloudSchedulerSource job", zap.String("jobName", jobName), zap.Any("errorCode", st.Code()), zap.Error(err))
+			return nil, err
+		}
+	} else if fields := resources.JobDrift(desired, job); len(fields) > 0 {
+		_, err = client.UpdateJob(ctx, &schedulerpb.UpdateJobRequest{
+			Job:        desired,
+			UpdateMask: &field_mask.FieldMask{Paths: fields},
+		})
+		if err != nil {
+			logging.FromContext(ctx).Desugar().Error("Failed to update CloudSchedulerSource job", zap.String("jobName", jobName), zap.Strings("fields", fields), zap.Error(err))
+			return nil, err
+		}
+		drift = append(drift, fmt.Sprintf("job %q was modified: %s", jobName, strings.Join(fields, ", ")))
+	}
+
+	if err := reconcileJobState(ctx, client, scheduler, job); err != nil {
+		return nil, err
+	}
+	return drift, nil
+}
+
+// reconcileJobState pauses or resumes the job so that it runs only when the scheduler is not
+// paused.
+func reconcileJobState(ctx context.Context, client gscheduler.Client, scheduler *v1.CloudSchedulerSource, job *schedulerpb.Job) error {
+	paused := job.GetState() == schedulerpb.Job_PAUSED
+	switch {
+	case scheduler.Spec.Paused && !paused:
+		if _, err := client.PauseJob(ctx, &schedulerpb.PauseJobRequest{Name: job.GetName()}); err != nil {
+			logging.FromContext(ctx).Desugar().Error("Failed to pause CloudSchedulerSource job", zap.String("jobName", job.GetName()), zap.Error(err))
+			return err
+		}
+	case !scheduler.Spec.Paused && paused:
+		if _, err := client.ResumeJob(ctx, &schedulerpb.ResumeJobRequest{Name: job.GetName()}); err != nil {
+			logging.FromContext(ctx).Desugar().Error("Failed to resume CloudSchedulerSource job", zap.String("jobName", job.GetName()), zap.Error(err))
 			return err
 		}
 	}
@@ -51,6 +51,7 @@ import (
 	"github.com/google/knative-gcp/pkg/reconciler/intevents"
 	. "github.com/google/knative-gcp/pkg/reconciler/testing"
 
+	schedulerpb "google.golang.org/genproto/googleapis/cloud/scheduler/v1"
 	"google.golang.org/grpc/codes"
 	gstatus "google.golang.org/grpc/status"
 )
@@ -1086,6 +1087,220 @@ func TestAllCases(t *testing.T) {
 				),
 				newSink(),
 			},
+			OtherTestData: map[string]interface{}{
+				"scheduler": gscheduler.TestClientData{
+					Job: newJob(onceAMinuteSchedule),
+				},
+			},
+			Key: testNS + "/" + schedulerName,
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: reconcilertestingv1.NewCloudSchedulerSource(schedulerName, testNS,
+					reconcilertestingv1.WithCloudSchedulerSourceProject(testProject),
+					reconcilertestingv1.WithCloudSchedulerSourceSink(sinkGVK, sinkName),
+					reconcilertestingv1.WithCloudSchedulerSourceLocation(location),
+					reconcilertestingv1.WithCloudSchedulerSourceData(testData),
+					reconcilertestingv1.WithCloudSchedulerSourceSchedule(onceAMinuteSchedule),
+					reconcilertestingv1.WithInitCloudSchedulerSourceConditions,
+					reconcilertestingv1.WithCloudSchedulerSourceTopicReady(testTopicID, testProject),
+					reconcilertestingv1.WithCloudSchedulerSourcePullSubscriptionReady,
+					reconcilertestingv1.WithCloudSchedulerSourceSubscriptionID(reconcilertestingv1.SubscriptionID),
+					reconcilertestingv1.WithCloudSchedulerSourceJobReady(jobName),
+					reconcilertestingv1.WithCloudSchedulerSourceSinkURI(schedulerSinkURL),
+					reconcilertestingv1.WithCloudSchedulerSourceSetDefaults,
+				),
+			}},
+			WantPatches: []clientgotesting.PatchActionImpl{
+				patchFinalizers(testNS, schedulerName, true),
+			},
+			WantEvents: []string{
+				Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", schedulerName),
+				Eventf(corev1.EventTypeNormal, reconciledSuccessReason, `CloudSchedulerSource reconciled: "%s/%s"`, testNS, schedulerName),
+			},
+		}, {
+			Name: "topic and pullsubscription exist and ready, job modified out of band",
+			Objects: []runtime.Object{
+				reconcilertestingv1.NewCloudSchedulerSource(schedulerName, testNS,
+					reconcilertestingv1.WithCloudSchedulerSourceProject(testProject),
+					reconcilertestingv1.WithCloudSchedulerSourceSink(sinkGVK, sinkName),
+					reconcilertestingv1.WithCloudSchedulerSourceLocation(location),
+					reconcilertestingv1.WithCloudSchedulerSourceData(testData),
+					reconcilertestingv1.WithCloudSchedulerSourceSchedule(onceAMinuteSchedule),
+					reconcilertestingv1.WithCloudSchedulerSourceSetDefaults,
+				),
+				reconcilertestingv1.NewTopic(schedulerName, testNS,
+					reconcilertestingv1.WithTopicSpec(inteventsv1.TopicSpec{
+						Topic:             testTopicID,
+						PropagationPolicy: "CreateDelete",
+						Project:           testProject,
+						EnablePublisher:   &falseVal,
+					}),
+					reconcilertestingv1.WithTopicReady(testTopicID),
+					reconcilertestingv1.WithTopicAddress(testTopicURI),
+					reconcilertestingv1.WithTopicProjectID(testProject),
+					reconcilertestingv1.WithTopicSetDefaults,
+				),
+				reconcilertestingv1.NewPullSubscription(schedulerName, testNS,
+					reconcilertestingv1.WithPullSubscriptionReady(sinkURI),
+					reconcilertestingv1.WithPullSubscriptionSpec(inteventsv1.PullSubscriptionSpec{
+						Topic: testTopicID,
+						PubSubSpec: gcpduckv1.PubSubSpec{
+							Secret: &secret,
+							SourceSpec: duckv1.SourceSpec{
+								Sink: newSinkDestination(),
+							},
+							Project: testProject,
+						},
+						AdapterType: string(converters.CloudScheduler),
+					}),
+				),
+				newSink(),
+			},
+			OtherTestData: map[string]interface{}{
+				"scheduler": gscheduler.TestClientData{
+					Job: newJob("0 * * * *"),
+				},
+			},
+			Key: testNS + "/" + schedulerName,
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: reconcilertestingv1.NewCloudSchedulerSource(schedulerName, testNS,
+					reconcilertestingv1.WithCloudSchedulerSourceProject(testProject),
+					reconcilertestingv1.WithCloudSchedulerSourceSink(sinkGVK, sinkName),
+					reconcilertestingv1.WithCloudSchedulerSourceLocation(location),
+					reconcilertestingv1.WithCloudSchedulerSourceData(testData),
+					reconcilertestingv1.WithCloudSchedulerSourceSchedule(onceAMinuteSchedule),
+					reconcilertestingv1.WithInitCloudSchedulerSourceConditions,
+					reconcilertestingv1.WithCloudSchedulerSourceTopicReady(testTopicID, testProject),
+					reconcilertestingv1.WithCloudSchedulerSourcePullSubscriptionReady,
+					reconcilertestingv1.WithCloudSchedulerSourceSubscriptionID(reconcilertestingv1.SubscriptionID),
+					reconcilertestingv1.WithCloudSchedulerSourceJobReady(jobName),
+					reconcilertestingv1.WithCloudSchedulerSourceDriftDetected(fmt.Sprintf(`Repaired drift: job %q was modified: schedule`, jobName)),
+					reconcilertestingv1.WithCloudSchedulerSourceSinkURI(schedulerSinkURL),
+					reconcilertestingv1.WithCloudSchedulerSourceSetDefaults,
+				),
+			}},
+			WantPatches: []clientgotesting.PatchActionImpl{
+				patchFinalizers(testNS, schedulerName, true),
+			},
+			WantEvents: []string{
+				Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", schedulerName),
+				Eventf(corev1.EventTypeWarning, "DriftDetected", `Repaired drift: job %q was modified: schedule`, jobName),
+				Eventf(corev1.EventTypeNormal, reconciledSuccessReason, `CloudSchedulerSource reconciled: "%s/%s"`, testNS, schedulerName),
+			},
+		}, {
+			Name: "topic and pullsubscription exist and ready, job pause fails",
+			Objects: []runtime.Object{
+				reconcilertestingv1.NewCloudSchedulerSource(schedulerName, testNS,
+					reconcilertestingv1.WithCloudSchedulerSourceProject(testProject),
+					reconcilertestingv1.WithCloudSchedulerSourceSink(sinkGVK, sinkName),
+					reconcilertestingv1.WithCloudSchedulerSourceLocation(location),
+					reconcilertestingv1.WithCloudSchedulerSourceData(testData),
+					reconcilertestingv1.WithCloudSchedulerSourceSchedule(onceAMinuteSchedule),
+					reconcilertestingv1.WithCloudSchedulerSourcePaused(true),
+					reconcilertestingv1.WithCloudSchedulerSourceSetDefaults,
+				),
+				reconcilertestingv1.NewTopic(schedulerName, testNS,
+					reconcilertestingv1.WithTopicSpec(inteventsv1.TopicSpec{
+						Topic:             testTopicID,
+						PropagationPolicy: "CreateDelete",
+						Project:           testProject,
+						EnablePublisher:   &falseVal,
+					}),
+					reconcilertestingv1.WithTopicReady(testTopicID),
+					reconcilertestingv1.WithTopicAddress(testTopicURI),
+					reconcilertestingv1.WithTopicProjectID(testProject),
+					reconcilertestingv1.WithTopicSetDefaults,
+				),
+				reconcilertestingv1.NewPullSubscription(schedulerName, testNS,
+					reconcilertestingv1.WithPullSubscriptionReady(sinkURI),
+					reconcilertestingv1.WithPullSubscriptionSpec(inteventsv1.PullSubscriptionSpec{
+						Topic: testTopicID,
+						PubSubSpec: gcpduckv1.PubSubSpec{
+							Secret: &secret,
+							SourceSpec: duckv1.SourceSpec{
+								Sink: newSinkDestination(),
+							},
+							Project: testProject,
+						},
+						AdapterType: string(converters.CloudScheduler),
+					}),
+				),
+				newSink(),
+			},
+			OtherTestData: map[string]interface{}{
+				"scheduler": gscheduler.TestClientData{
+					Job:         newJob(onceAMinuteSchedule),
+					PauseJobErr: errors.New("pause-job-induced-error"),
+				},
+			},
+			Key: testNS + "/" + schedulerName,
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: reconcilertestingv1.NewCloudSchedulerSource(schedulerName, testNS,
+					reconcilertestingv1.WithCloudSchedulerSourceProject(testProject),
+					reconcilertestingv1.WithCloudSchedulerSourceSink(sinkGVK, sinkName),
+					reconcilertestingv1.WithCloudSchedulerSourceLocation(location),
+					reconcilertestingv1.WithCloudSchedulerSourceData(testData),
+					reconcilertestingv1.WithCloudSchedulerSourceSchedule(onceAMinuteSchedule),
+					reconcilertestingv1.WithCloudSchedulerSourcePaused(true),
+					reconcilertestingv1.WithInitCloudSchedulerSourceConditions,
+					reconcilertestingv1.WithCloudSchedulerSourceTopicReady(testTopicID, testProject),
+					reconcilertestingv1.WithCloudSchedulerSourcePullSubscriptionReady,
+					reconcilertestingv1.WithCloudSchedulerSourceSubscriptionID(reconcilertestingv1.SubscriptionID),
+					reconcilertestingv1.WithCloudSchedulerSourceJobNotReady(reconciledFailedReason, fmt.Sprintf("%s: %s", failedToReconcileJobMsg, "pause-job-induced-error")),
+					reconcilertestingv1.WithCloudSchedulerSourceSinkURI(schedulerSinkURL),
+					reconcilertestingv1.WithCloudSchedulerSourceSetDefaults,
+				),
+			}},
+			WantPatches: []clientgotesting.PatchActionImpl{
+				patchFinalizers(testNS, schedulerName, true),
+			},
+			WantEvents: []string{
+				Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", schedulerName),
+				Eventf(corev1.EventTypeWarning, reconciledFailedReason, "Reconcile Job failed with: pause-job-induced-error"),
+			},
+		}, {
+			Name: "topic and pullsubscription exist and ready, paused job resumed",
+			Objects: []runtime.Object{
+				reconcilertestingv1.NewCloudSchedulerSource(schedulerName, testNS,
+					reconcilertestingv1.WithCloudSchedulerSourceProject(testProject),
+					reconcilertestingv1.WithCloudSchedulerSourceSink(sinkGVK, sinkName),
+					reconcilertestingv1.WithCloudSchedulerSourceLocation(location),
+					reconcilertestingv1.WithCloudSchedulerSourceData(testData),
+					reconcilertestingv1.WithCloudSchedulerSourceSchedule(onceAMinuteSchedule),
+					reconcilertestingv1.WithCloudSchedulerSourceSetDefaults,
+				),
+				reconcilertestingv1.NewTopic(schedulerName, testNS,
+					reconcilertestingv1.WithTopicSpec(inteventsv1.TopicSpec{
+						Topic:             testTopicID,
+						PropagationPolicy: "CreateDelete",
+						Project:           testProject,
+						EnablePublisher:   &falseVal,
+					}),
+					reconcilertestingv1.WithTopicReady(testTopicID),
+					reconcilertestingv1.WithTopicAddress(testTopicURI),
+					reconcilertestingv1.WithTopicProjectID(testProject),
+					reconcilertestingv1.WithTopicSetDefaults,
+				),
+				reconcilertestingv1.NewPullSubscription(schedulerName, testNS,
+					reconcilertestingv1.WithPullSubscriptionReady(sinkURI),
+					reconcilertestingv1.WithPullSubscriptionSpec(inteventsv1.PullSubscriptionSpec{
+						Topic: testTopicID,
+						PubSubSpec: gcpduckv1.PubSubSpec{
+							Secret: &secret,
+							SourceSpec: duckv1.SourceSpec{
+								Sink: newSinkDestination(),
+							},
+							Project: testProject,
+						},
+						AdapterType: string(converters.CloudScheduler),
+					}),
+				),
+				newSink(),
+			},
+			OtherTestData: map[string]interface{}{
+				"scheduler": gscheduler.TestClientData{
+					Job: newPausedJob(onceAMinuteSchedule),
+				},
+			},
 			Key: testNS + "/" + schedulerName,
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 				Object: reconcilertestingv1.NewCloudSchedulerSource(schedulerName, testNS,
@@ -1296,6 +1511,7 @@ func TestAllCases(t *testing.T) {
 					ReceiveAdapterName:  receiveAdapterName,
 					ReceiveAdapterType:  string(converters.CloudScheduler),
 					ConfigWatcher:       cmw,
+					EventTypeLister:     listers.GetEventTypeLister(),
 				}),
 			Identity:        identity.NewIdentity(ctx, NoopIAMPolicyManager, NewGCPAuthTestStore(t, nil)),
 			schedulerLister: listers.GetCloudSchedulerSourceLister(),
@@ -1305,3 +1521,25 @@ func TestAllCases(t *testing.T) {
 	}))
 
 }
+
+func newJob(schedule string) *schedulerpb.Job {
+	return &schedulerpb.Job{
+		Name: jobName,
+		Target: &schedulerpb.Job_PubsubTarget{
+			PubsubTarget: &schedulerpb.PubsubTarget{
+				TopicName: "projects/" + testProject + "/topics/" + testTopicID,
+				Data:      []byte(testData),
+				Attributes: map[string]string{
+					schedulerv1.CloudSchedulerSourceJobName: jobName,
+				},
+			},
+		},
+		Schedule: schedule,
+	}
+}
+
+func newPausedJob(schedule string) *schedulerpb.Job {
+	job := newJob(schedule)
+	job.State = schedulerpb.Job_PAUSED
+	return job
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "cloud.google.com/go/storage"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/google/knative-gcp/pkg/apis/events/v1"
+	gstorage "github.com/google/knative-gcp/pkg/gclient/storage/testing"
+)
+
+func TestSelectBuckets(t *testing.T) {
+	data := gstorage.TestClientData{
+		Buckets: []*BucketAttrs{
+			{Name: "b3", Labels: map[string]string{"env": "prod"}},
+			{Name: "b1", Labels: map[string]string{"env": "prod"}},
+			{Name: "b2", Labels: map[string]string{"env": "dev"}},
+		},
+	}
+	tests := []struct {
+		name    string
+		spec    v1.CloudStorageSourceSpec
+		data    gstorage.TestClientData
+		want    []string
+		wantErr bool
+	}{{
+		name: "bucket",
+		spec: v1.CloudStorageSourceSpec{Bucket: "b1"},
+		want: []string{"b1"},
+	}, {
+		name: "buckets",
+		spec: v1.CloudStorageSourceSpec{Buckets: []string{"b2", "b1"}},
+		want: []string{"b1", "b2"},
+	}, {
+		name: "bucket selector",
+		spec: v1.CloudStorageSourceSpec{BucketSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}},
+		data: data,
+		want: []string{"b1", "b3"},
+	}, {
+		name: "bucket selector without match",
+		spec: v1.CloudStorageSourceSpec{BucketSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "test"}}},
+		data: data,
+	}, {
+		name:    "list error",
+		spec:    v1.CloudStorageSourceSpec{BucketSelector: &metav1.LabelSelector{}},
+		data:    gstorage.TestClientData{ListBucketsErr: errors.New("list failed")},
+		wantErr: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			client, err := gstorage.TestClientCreator(test.data)(ctx)
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+			r := &Reconciler{}
+			got, err := r.selectBuckets(ctx, client, &v1.CloudStorageSource{Spec: test.spec})
+			if test.wantErr != (err != nil) {
+				t.Fatalf("selectBuckets got error %v, want error=%v", err, test.wantErr)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("unexpected buckets (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestReconcileNotifications(t *testing.T) {
+	storage := &v1.CloudStorageSource{
+		Spec: v1.CloudStorageSourceSpec{Buckets: []string{"b2", "b1"}},
+		Status: v1.CloudStorageSourceStatus{
+			Notifications: []v1.BucketNotification{
+				{Bucket: "b1", NotificationID: "n1"},
+				{Bucket: "b3", NotificationID: "n3"},
+			},
+		},
+	}
+	storage.Status.ProjectID = "project"
+	r := &Reconciler{
+		createClientFn: gstorage.TestClientCreator(gstorage.TestClientData{
+			BucketData: gstorage.TestBucketData{
+				Notifications: map[string]*Notification{
+					"n1": {ID: "n1", TopicProjectID: "project", PayloadFormat: JSONPayload},
+					"n3": {ID: "n3"},
+				},
+				AddNotificationID: "new",
+				Attrs:             &BucketAttrs{},
+			},
+		}),
+	}
+	drift, err := r.reconcileNotifications(context.Background(), storage)
+	if err != nil {
+		t.Fatalf("reconcileNotifications got error: %v", err)
+	}
+	if len(drift) != 0 {
+		t.Errorf("reconcileNotifications got drift %v, want none", drift)
+	}
+	want := []v1.BucketNotification{
+		{Bucket: "b1", NotificationID: "n1"},
+		{Bucket: "b2", NotificationID: "new"},
+	}
+	if diff := cmp.Diff(want, storage.Status.Notifications); diff != "" {
+		t.Errorf("unexpected notifications (-want, +got) = %v", diff)
+	}
+}
+
+func TestReconcileNotificationsKeepsCreated(t *testing.T) {
+	storage := &v1.CloudStorageSource{
+		Spec: v1.CloudStorageSourceSpec{Buckets: []string{"b1"}},
+		Status: v1.CloudStorageSourceStatus{
+			Notifications: []v1.BucketNotification{
+				{Bucket: "b3", NotificationID: "n3"},
+			},
+		},
+	}
+	storage.Status.ProjectID = "project"
+	r := &Reconciler{
+		createClientFn: gstorage.TestClientCreator(gstorage.TestClientData{
+			BucketData: gstorage.TestBucketData{
+				Notifications: map[string]*Notification{
+					"n3": {ID: "n3"},
+				},
+				AddNotificationID: "new",
+				Attrs:             &BucketAttrs{},
+				DeleteErr:         errors.New("delete failed"),
+			},
+		}),
+	}
+	if _, err := r.reconcileNotifications(context.Background(), storage); err == nil {
+		t.Fatal("reconcileNotifications got no error")
+	}
+	want := []v1.BucketNotification{
+		{Bucket: "b1", NotificationID: "new"},
+		{Bucket: "b3", NotificationID: "n3"},
+	}
+	if diff := cmp.Diff(want, storage.Status.Notifications); diff != "" {
+		t.Errorf("unexpected notifications (-want, +got) = %v", diff)
+	}
+}
+
+func TestReconcileNotificationsDrift(t *testing.T) {
+	storage := &v1.CloudStorageSource{
+		Spec: v1.CloudStorageSourceSpec{Buckets: []string{"b1", "b2"}},
+		Status: v1.CloudStorageSourceStatus{
+			Notifications: []v1.BucketNotification{
+				{Bucket: "b1", NotificationID: "n1"},
+				{Bucket: "b2", NotificationID: "n2"},
+			},
+		},
+	}
+	storage.Status.ProjectID = "project"
+	r := &Reconciler{
+		createClientFn: gstorage.TestClientCreator(gstorage.TestClientData{
+			BucketData: gstorage.TestBucketData{
+				Notifications: map[string]*Notification{
+					"n1": {ID: "n1", TopicProjectID: "project", PayloadFormat: JSONPayload, ObjectNamePrefix: "other/"},
+				},
+				AddNotificationID: "new",
+				Attrs:             &BucketAttrs{},
+			},
+		}),
+	}
+	drift, err := r.reconcileNotifications(context.Background(), storage)
+	if err != nil {
+		t.Fatalf("reconcileNotifications got error: %v", err)
+	}
+	wantDrift := []string{
+		`notification "n1" of bucket "b1" was modified: objectNamePrefix`,
+		`notification "n2" of bucket "b2" was deleted`,
+	}
+	if diff := cmp.Diff(wantDrift, drift); diff != "" {
+		t.Errorf("unexpected drift (-want, +got) = %v", diff)
+	}
+	want := []v1.BucketNotification{
+		{Bucket: "b1", NotificationID: "new"},
+		{Bucket: "b2", NotificationID: "new"},
+	}
+	if diff := cmp.Diff(want, storage.Status.Notifications); diff != "" {
+		t.Errorf("unexpected notifications (-want, +got) = %v", diff)
+	}
+}
+
+func TestNotificationDrift(t *testing.T) {
+	desired := &Notification{
+		TopicProjectID: "project",
+		TopicID:        "topic",
+		PayloadFormat:  JSONPayload,
+		EventTypes:     []string{ObjectFinalizeEvent, ObjectDeleteEvent},
+	}
+	tests := []struct {
+		name string
+		live Notification
+		want []string
+	}{{
+		name: "unchanged",
+		live: Notification{
+			TopicProjectID: "project",
+			TopicID:        "topic",
+			PayloadFormat:  JSONPayload,
+			EventTypes:     []string{ObjectDeleteEvent, ObjectFinalizeEvent},
+		},
+	}, {
+		name: "all changed",
+		live: Notification{
+			TopicProjectID:   "project",
+			TopicID:          "other",
+			PayloadFormat:    NoPayload,
+			EventTypes:       []string{ObjectFinalizeEvent},
+			ObjectNamePrefix: "prefix/",
+		},
+		want: []string{"topic", "payloadFormat", "eventTypes", "objectNamePrefix"},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.want, notificationDrift(desired, &test.live)); diff != "" {
+				t.Errorf("unexpected (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
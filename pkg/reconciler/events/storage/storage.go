@@ -18,22 +18,31 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 
 	"go.uber.org/zap"
 
 	"google.golang.org/grpc/codes"
 	gstatus "google.golang.org/grpc/status"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/reconciler"
 
 	. "cloud.google.com/go/storage"
 
+	"github.com/google/knative-gcp/pkg/apis/duck"
 	v1 "github.com/google/knative-gcp/pkg/apis/events/v1"
 	cloudstoragesourcereconciler "github.com/google/knative-gcp/pkg/client/injection/reconciler/events/v1/cloudstoragesource"
 	listers "github.com/google/knative-gcp/pkg/client/listers/events/v1"
 	metadataClient "github.com/google/knative-gcp/pkg/gclient/metadata"
 	gstorage "github.com/google/knative-gcp/pkg/gclient/storage"
+	"github.com/google/knative-gcp/pkg/pubsub/adapter/converters"
 	"github.com/google/knative-gcp/pkg/reconciler/events/storage/resources"
 	"github.com/google/knative-gcp/pkg/reconciler/identity"
 	"github.com/google/knative-gcp/pkg/reconciler/intevents"
@@ -94,65 +103,198 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, storage *v1.CloudStorage
 	}
 
 	topic := resources.GenerateTopicName(storage)
-	_, _, err := r.PubSubBase.ReconcilePubSub(ctx, storage, topic, resourceGroup)
+	filter := converters.ObjectNameFilter{
+		Include: storage.Spec.IncludeObjectNames,
+		Exclude: storage.Spec.ExcludeObjectNames,
+	}
+	annotations := map[string]string{duck.ObjectNameFilterAnnotation: filter.String()}
+	_, _, err := r.PubSubBase.ReconcilePubSubWithAnnotations(ctx, storage, topic, resourceGroup, annotations)
 	if err != nil {
 		return reconciler.NewEvent(corev1.EventTypeWarning, reconciledPubSubFailed, "Failed to reconcile CloudStorageSource PubSub: %s", err.Error())
 	}
 
-	notification, err := r.reconcileNotification(ctx, storage)
+	var source, notification string
+	var drift []string
+	if storage.Spec.IsMultiBucket() {
+		drift, err = r.reconcileNotifications(ctx, storage)
+	} else {
+		source = schemasv1.CloudStorageEventSource(storage.Spec.Bucket)
+		notification, drift, err = r.reconcileNotification(ctx, storage)
+	}
 	if err != nil {
 		storage.Status.MarkNotificationNotReady(reconciledNotificationFailed, "Failed to reconcile CloudStorageSource notification: %s", err.Error())
 		return reconciler.NewEvent(corev1.EventTypeWarning, reconciledNotificationFailed, "Failed to reconcile CloudStorageSource notification: %s", err.Error())
 	}
 	storage.Status.MarkNotificationReady(notification)
+	r.PubSubBase.ReportDrift(ctx, storage, drift)
+
+	// The events of a source watching several buckets don't share a source.
+	if event := r.PubSubBase.ReconcileEventTypes(ctx, storage, source, r.eventTypes(storage)); event != nil {
+		return event
+	}
 
 	return reconciler.NewEvent(corev1.EventTypeNormal, reconciledSuccessReason, `CloudStorageSource reconciled: "%s/%s"`, storage.Namespace, storage.Name)
 }
 
-func (r *Reconciler) reconcileNotification(ctx context.Context, storage *v1.CloudStorageSource) (string, error) {
+func (r *Reconciler) reconcileProjectID(ctx context.Context, storage *v1.CloudStorageSource) error {
 	if storage.Status.ProjectID == "" {
 		projectID, err := utils.ProjectID(storage.Spec.Project, metadataClient.NewDefaultMetadataClient())
 		if err != nil {
 			logging.FromContext(ctx).Desugar().Error("Failed to find project id", zap.Error(err))
-			return "", err
+			return err
 		}
 		// Set the projectID in the status.
 		storage.Status.ProjectID = projectID
 	}
+	return nil
+}
+
+func (r *Reconciler) reconcileNotification(ctx context.Context, storage *v1.CloudStorageSource) (string, []string, error) {
+	if err := r.reconcileProjectID(ctx, storage); err != nil {
+		return "", nil, err
+	}
+
+	client, err := r.createClientFn(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Desugar().Error("Failed to create CloudStorageSource client", zap.Error(err))
+		return "", nil, err
+	}
+	defer client.Close()
+
+	id, drift, err := r.reconcileBucketNotification(ctx, client, storage, storage.Spec.Bucket, storage.Status.NotificationID)
+	if err != nil {
+		return "", nil, err
+	}
+	if drift != "" {
+		return id, []string{drift}, nil
+	}
+	return id, nil, nil
+}
+
+// reconcileNotifications reconciles the notifications of the buckets of a CloudStorageSource
+// watching Buckets or the buckets of BucketSelector, and deletes the notifications of the buckets
+// it no longer watches. status.Notifications is updated even if some of the buckets fail, so that
+// the notifications that were created are not leaked. It returns the drift of the notifications
+// that was repaired, if any.
+func (r *Reconciler) reconcileNotifications(ctx context.Context, storage *v1.CloudStorageSource) ([]string, error) {
+	if err := r.reconcileProjectID(ctx, storage); err != nil {
+		return nil, err
+	}
 
 	client, err := r.createClientFn(ctx)
 	if err != nil {
 		logging.FromContext(ctx).Desugar().Error("Failed to create CloudStorageSource client", zap.Error(err))
-		return "", err
+		return nil, err
 	}
 	defer client.Close()
 
+	buckets, err := r.selectBuckets(ctx, client, storage)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]string, len(storage.Status.Notifications))
+	for _, n := range storage.Status.Notifications {
+		ids[n.Bucket] = n.NotificationID
+	}
+	defer func() {
+		storage.Status.Notifications = toBucketNotifications(ids)
+	}()
+
+	var errs, drift []string
+	selected := sets.NewString(buckets...)
+	for _, bucket := range buckets {
+		id, d, err := r.reconcileBucketNotification(ctx, client, storage, bucket, ids[bucket])
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("bucket %q: %v", bucket, err))
+			continue
+		}
+		ids[bucket] = id
+		if d != "" {
+			drift = append(drift, d)
+		}
+	}
+	for bucket, id := range ids {
+		if selected.Has(bucket) {
+			continue
+		}
+		if err := r.deleteBucketNotification(ctx, client, storage, bucket, id); err != nil {
+			errs = append(errs, fmt.Sprintf("bucket %q: %v", bucket, err))
+			continue
+		}
+		delete(ids, bucket)
+	}
+	if len(errs) != 0 {
+		sort.Strings(errs)
+		return nil, errors.New(strings.Join(errs, "; "))
+	}
+	return drift, nil
+}
+
+// selectBuckets returns the sorted names of the buckets watched by a CloudStorageSource.
+func (r *Reconciler) selectBuckets(ctx context.Context, client gstorage.Client, storage *v1.CloudStorageSource) ([]string, error) {
+	if storage.Spec.BucketSelector == nil {
+		buckets := append([]string(nil), storage.Spec.BucketNames()...)
+		sort.Strings(buckets)
+		return buckets, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(storage.Spec.BucketSelector)
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := client.ListBuckets(ctx, storage.Status.ProjectID)
+	if err != nil {
+		logging.FromContext(ctx).Desugar().Error("Failed to list buckets", zap.String("projectId", storage.Status.ProjectID), zap.Error(err))
+		return nil, err
+	}
+	var buckets []string
+	for _, a := range attrs {
+		if selector.Matches(labels.Set(a.Labels)) {
+			buckets = append(buckets, a.Name)
+		}
+	}
+	sort.Strings(buckets)
+	return buckets, nil
+}
+
+func toBucketNotifications(ids map[string]string) []v1.BucketNotification {
+	if len(ids) == 0 {
+		return nil
+	}
+	notifications := make([]v1.BucketNotification, 0, len(ids))
+	for bucket, id := range ids {
+		notifications = append(notifications, v1.BucketNotification{Bucket: bucket, NotificationID: id})
+	}
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].Bucket < notifications[j].Bucket
+	})
+	return notifications
+}
+
+// reconcileBucketNotification makes sure that the notification of a bucket exists and matches its
+// desired state, creating or replacing it if needed, and returns its ID. It also returns the drift
+// of the notification that was repaired, if any.
+func (r *Reconciler) reconcileBucketNotification(ctx context.Context, client gstorage.Client, storage *v1.CloudStorageSource, bucketName, notificationID string) (string, string, error) {
 	// Load the Bucket.
-	bucket := client.Bucket(storage.Spec.Bucket)
+	bucket := client.Bucket(bucketName)
 	//Check whether Bucket exists or not
 	if _, err := bucket.Attrs(ctx); err != nil {
 		if err == ErrBucketNotExist {
-			logging.FromContext(ctx).Desugar().Error("Bucket doesn't exist", zap.String("bucketName", storage.Spec.Bucket), zap.Error(err))
-			return "", err
+			logging.FromContext(ctx).Desugar().Error("Bucket doesn't exist", zap.String("bucketName", bucketName), zap.Error(err))
+			return "", "", err
 		}
-		logging.FromContext(ctx).Desugar().Error("Failed to fetch attrs of bucket", zap.String("bucketName", storage.Spec.Bucket), zap.Error(err))
-		return "", err
+		logging.FromContext(ctx).Desugar().Error("Failed to fetch attrs of bucket", zap.String("bucketName", bucketName), zap.Error(err))
+		return "", "", err
 	}
 
 	notifications, err := bucket.Notifications(ctx)
 	if err != nil {
 		logging.FromContext(ctx).Desugar().Error("Failed to fetch existing notifications", zap.Error(err))
-		return "", err
-	}
-
-	// If the notification does exist, then return its ID.
-	if existing, ok := notifications[storage.Status.NotificationID]; ok {
-		return existing.ID, nil
+		return "", "", err
 	}
 
-	// If the notification does not exist, then create it.
-
-	nc := &Notification{
+	desired := &Notification{
 		TopicProjectID:   storage.Status.ProjectID,
 		TopicID:          storage.Status.TopicID,
 		PayloadFormat:    JSONPayload,
@@ -160,12 +302,66 @@ func (r *Reconciler) reconcileNotification(ctx context.Context, storage *v1.Clou
 		ObjectNamePrefix: storage.Spec.ObjectNamePrefix,
 	}
 
-	notification, err := bucket.AddNotification(ctx, nc)
+	var drift string
+	if existing, ok := notifications[notificationID]; ok {
+		// If the notification does exist and is unchanged, then return its ID.
+		fields := notificationDrift(desired, existing)
+		if len(fields) == 0 {
+			return existing.ID, "", nil
+		}
+		// Notifications can't be updated, so replace it.
+		logging.FromContext(ctx).Desugar().Warn("Replacing modified notification", zap.String("bucketName", bucketName), zap.String("notificationId", notificationID), zap.Strings("fields", fields))
+		if err := bucket.DeleteNotification(ctx, notificationID); err != nil {
+			logging.FromContext(ctx).Desugar().Error("Failed to delete modified CloudStorageSource notification", zap.String("notificationId", notificationID), zap.Error(err))
+			return "", "", err
+		}
+		drift = fmt.Sprintf("notification %q of bucket %q was modified: %s", notificationID, bucketName, strings.Join(fields, ", "))
+	} else if notificationID != "" {
+		// The notification was created before, so it was deleted out of band.
+		drift = fmt.Sprintf("notification %q of bucket %q was deleted", notificationID, bucketName)
+	}
+
+	// If the notification does not exist, then create it.
+	notification, err := bucket.AddNotification(ctx, desired)
 	if err != nil {
 		logging.FromContext(ctx).Desugar().Error("Failed to create CloudStorageSource notification", zap.Error(err))
-		return "", err
+		return "", "", err
 	}
-	return notification.ID, nil
+	return notification.ID, drift, nil
+}
+
+// notificationDrift returns the names of the fields of the live notification that differ from the
+// desired notification.
+func notificationDrift(desired, live *Notification) []string {
+	var fields []string
+	if live.TopicProjectID != desired.TopicProjectID || live.TopicID != desired.TopicID {
+		fields = append(fields, "topic")
+	}
+	if live.PayloadFormat != desired.PayloadFormat {
+		fields = append(fields, "payloadFormat")
+	}
+	if !sets.NewString(live.EventTypes...).Equal(sets.NewString(desired.EventTypes...)) {
+		fields = append(fields, "eventTypes")
+	}
+	if live.ObjectNamePrefix != desired.ObjectNamePrefix {
+		fields = append(fields, "objectNamePrefix")
+	}
+	return fields
+}
+
+// eventTypes returns the types of the events sent by the CloudStorageSource.
+func (r *Reconciler) eventTypes(storage *v1.CloudStorageSource) []schemasv1.EventTypeInfo {
+	kind := storage.GetGroupVersionKind().Kind
+	if len(storage.Spec.EventTypes) == 0 {
+		return schemasv1.EventTypes(kind)
+	}
+	var types []schemasv1.EventTypeInfo
+	for _, eventType := range storage.Spec.EventTypes {
+		if info, ok := schemasv1.EventTypeInfoOf(kind, eventType); ok {
+			types = append(types, info)
+		}
+	}
+	return types
 }
 
 func (r *Reconciler) toCloudStorageSourceEventTypes(eventTypes []string) []string {
@@ -176,11 +372,11 @@ func (r *Reconciler) toCloudStorageSourceEventTypes(eventTypes []string) []strin
 	return storageTypes
 }
 
-// deleteNotification looks at the status.NotificationID and if non-empty,
-// hence indicating that we have created a notification successfully
-// in the CloudStorageSource, remove it.
+// deleteNotification looks at the status.NotificationID and status.Notifications and if non-empty,
+// hence indicating that we have created notifications successfully
+// in the CloudStorageSource, remove them.
 func (r *Reconciler) deleteNotification(ctx context.Context, storage *v1.CloudStorageSource) error {
-	if storage.Status.NotificationID == "" {
+	if storage.Status.NotificationID == "" && len(storage.Status.Notifications) == 0 {
 		return nil
 	}
 
@@ -194,17 +390,34 @@ func (r *Reconciler) deleteNotification(ctx context.Context, storage *v1.CloudSt
 	}
 	defer client.Close()
 
+	if storage.Status.NotificationID != "" {
+		if err := r.deleteBucketNotification(ctx, client, storage, storage.Spec.Bucket, storage.Status.NotificationID); err != nil {
+			return err
+		}
+	}
+	for len(storage.Status.Notifications) != 0 {
+		n := storage.Status.Notifications[0]
+		if err := r.deleteBucketNotification(ctx, client, storage, n.Bucket, n.NotificationID); err != nil {
+			return err
+		}
+		storage.Status.Notifications = storage.Status.Notifications[1:]
+	}
+	return nil
+}
+
+// deleteBucketNotification deletes the notification of a bucket, if both still exist.
+func (r *Reconciler) deleteBucketNotification(ctx context.Context, client gstorage.Client, storage *v1.CloudStorageSource, bucketName, notificationID string) error {
 	// Load the Bucket.
-	bucket := client.Bucket(storage.Spec.Bucket)
+	bucket := client.Bucket(bucketName)
 
 	// Check whether bucket exists or not
 	if _, err := bucket.Attrs(ctx); err != nil {
 		// If the bucket was already deleted, then we should  proceed.
 		if err == ErrBucketNotExist {
-			logging.FromContext(ctx).Desugar().Info("Bucket does not exist.", zap.String("bucketName", storage.Spec.Bucket), zap.Error(err))
+			logging.FromContext(ctx).Desugar().Info("Bucket does not exist.", zap.String("bucketName", bucketName), zap.Error(err))
 			return nil
 		}
-		logging.FromContext(ctx).Desugar().Error("Failed to fetch attrs of bucket", zap.String("bucketName", storage.Spec.Bucket), zap.Error(err))
+		logging.FromContext(ctx).Desugar().Error("Failed to fetch attrs of bucket", zap.String("bucketName", bucketName), zap.Error(err))
 		storage.Status.MarkNotificationUnknown(deleteNotificationFailed, "Failed to fetch attrs of bucket: %s", err.Error())
 		return err
 	}
@@ -219,19 +432,19 @@ func (r *Reconciler) deleteNotification(ctx context.Context, storage *v1.CloudSt
 	// This is bit wonky because, we could always just try to delete, but figuring out
 	// if an error returned is NotFound seems to not really work, so, we'll try
 	// checking first the list and only then deleting.
-	if existing, ok := notifications[storage.Status.NotificationID]; ok {
+	if existing, ok := notifications[notificationID]; ok {
 		logging.FromContext(ctx).Desugar().Debug("Found existing notification", zap.Any("notification", existing))
-		err = bucket.DeleteNotification(ctx, storage.Status.NotificationID)
+		err = bucket.DeleteNotification(ctx, notificationID)
 		if err == nil {
-			logging.FromContext(ctx).Desugar().Debug("Deleted Notification", zap.String("notificationId", storage.Status.NotificationID))
+			logging.FromContext(ctx).Desugar().Debug("Deleted Notification", zap.String("notificationId", notificationID))
 			return nil
 		}
 		if st, ok := gstatus.FromError(err); !ok {
-			logging.FromContext(ctx).Desugar().Error("Failed from CloudStorageSource client while deleting CloudStorageSource notification", zap.String("notificationId", storage.Status.NotificationID), zap.Error(err))
+			logging.FromContext(ctx).Desugar().Error("Failed from CloudStorageSource client while deleting CloudStorageSource notification", zap.String("notificationId", notificationID), zap.Error(err))
 			storage.Status.MarkNotificationUnknown(deleteNotificationFailed, "Failed from CloudStorageSource client while deleting CloudStorageSource notification: %s", err.Error())
 			return err
 		} else if st.Code() != codes.NotFound {
-			logging.FromContext(ctx).Desugar().Error("Failed to delete CloudStorageSource notification", zap.String("notificationId", storage.Status.NotificationID), zap.Error(err))
+			logging.FromContext(ctx).Desugar().Error("Failed to delete CloudStorageSource notification", zap.String("notificationId", notificationID), zap.Error(err))
 			storage.Status.MarkNotificationUnknown(deleteNotificationFailed, "Failed to delete CloudStorageSource notification: %s", err.Error())
 			return err
 		}
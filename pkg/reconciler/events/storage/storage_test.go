@@ -1315,6 +1315,7 @@ func TestAllCases(t *testing.T) {
 					ReceiveAdapterName:  receiveAdapterName,
 					ReceiveAdapterType:  string(converters.CloudStorage),
 					ConfigWatcher:       cmw,
+					EventTypeLister:     listers.GetEventTypeLister(),
 				}),
 			Identity:       identity.NewIdentity(ctx, NoopIAMPolicyManager, NewGCPAuthTestStore(t, nil)),
 			storageLister:  listers.GetCloudStorageSourceLister(),
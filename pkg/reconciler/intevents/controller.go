@@ -19,7 +19,12 @@ package intevents
 import (
 	"context"
 
+	"github.com/kelseyhightower/envconfig"
+	"go.uber.org/zap"
+	eventingclient "knative.dev/eventing/pkg/client/injection/client"
+	eventinglisters "knative.dev/eventing/pkg/client/listers/eventing/v1beta1"
 	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/logging"
 
 	pubsubClient "github.com/google/knative-gcp/pkg/client/injection/client"
 	"github.com/google/knative-gcp/pkg/reconciler"
@@ -30,13 +35,21 @@ type PubSubBaseArgs struct {
 	ReceiveAdapterName  string
 	ReceiveAdapterType  string
 	ConfigWatcher       configmap.Watcher
+	EventTypeLister     eventinglisters.EventTypeLister
 }
 
 func NewPubSubBase(ctx context.Context, args *PubSubBaseArgs) *PubSubBase {
+	var env driftEnvConfig
+	if err := envconfig.Process("", &env); err != nil {
+		logging.FromContext(ctx).Fatal("Failed to process env var", zap.Error(err))
+	}
 	return &PubSubBase{
 		Base:               reconciler.NewBase(ctx, args.ControllerAgentName, args.ConfigWatcher),
 		pubsubClient:       pubsubClient.Get(ctx),
+		eventingClient:     eventingclient.Get(ctx),
+		eventTypeLister:    args.EventTypeLister,
 		receiveAdapterName: args.ReceiveAdapterName,
 		receiveAdapterType: args.ReceiveAdapterType,
+		driftRetention:     env.DriftRetention,
 	}
 }
@@ -19,6 +19,7 @@ package intevents
 import (
 	"testing"
 
+	_ "knative.dev/eventing/pkg/client/injection/client/fake"
 	"knative.dev/pkg/configmap"
 	. "knative.dev/pkg/reconciler/testing"
 )
@@ -0,0 +1,64 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package intevents
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/logging"
+
+	duck "github.com/google/knative-gcp/pkg/duck/v1"
+)
+
+const (
+	// DriftDetectedReason is the reason of the DriftDetected condition and
+	// events of the sources whose GCP resources had been changed out of band.
+	DriftDetectedReason = "DriftDetected"
+)
+
+type driftEnvConfig struct {
+	// DriftRetention is how long the DriftDetected condition of a source is
+	// kept after the last repair of its GCP resources.
+	DriftRetention time.Duration `envconfig:"DRIFT_DETECTED_RETENTION" default:"1h"`
+}
+
+// ReportDrift reports the drift of the GCP resources of pubsubable from their
+// desired state, found and repaired while reconciling it. Each drift is a
+// human readable description of a change. If there is any, the DriftDetected
+// condition is set and a warning event is recorded, otherwise the condition is
+// cleared once the last repair is older than the drift retention, so that it
+// is not missed by the users checking the status.
+func (psb *PubSubBase) ReportDrift(ctx context.Context, pubsubable duck.PubSubable, drift []string) {
+	status := pubsubable.PubSubStatus()
+	cs := pubsubable.ConditionSet()
+	if len(drift) == 0 {
+		status.ClearDriftDetected(cs, psb.driftRetention)
+		return
+	}
+
+	message := strings.Join(drift, "; ")
+	logging.FromContext(ctx).Desugar().Warn("Repaired drift of GCP resources", zap.Strings("drift", drift))
+	status.MarkDriftDetected(cs, DriftDetectedReason, "Repaired drift: %s", message)
+	if obj, ok := pubsubable.(runtime.Object); ok {
+		psb.Recorder.Eventf(obj, corev1.EventTypeWarning, DriftDetectedReason, "Repaired drift: %s", message)
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package intevents
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/logging"
+	pkgreconciler "knative.dev/pkg/reconciler"
+
+	duck "github.com/google/knative-gcp/pkg/duck/v1"
+	"github.com/google/knative-gcp/pkg/reconciler/intevents/resources"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
+)
+
+const (
+	eventTypesListFailedReason  = "EventTypesListFailed"
+	eventTypeCreateFailedReason = "EventTypeCreateFailed"
+	eventTypeDeleteFailedReason = "EventTypeDeleteFailed"
+	brokerKind                  = "Broker"
+	eventingGroup               = "eventing.knative.dev"
+)
+
+// ReconcileEventTypes registers the given types of the events sent by pubsubable
+// as EventTypes of its sink, if its sink is a Broker of its namespace. source is
+// the CloudEvent source of the events, or "" if it varies. The EventTypes of the
+// types that pubsubable no longer sends, or of a Broker that is no longer its
+// sink, are deleted. The EventTypes are owned by pubsubable, so that they are
+// garbage collected along with it.
+func (psb *PubSubBase) ReconcileEventTypes(ctx context.Context, pubsubable duck.PubSubable, source string, types []schemasv1.EventTypeInfo) pkgreconciler.Event {
+	namespace := pubsubable.GetObjectMeta().GetNamespace()
+	name := pubsubable.GetObjectMeta().GetName()
+	ls := resources.GetLabels(psb.receiveAdapterName, name)
+
+	var desired []*eventingv1beta1.EventType
+	if broker := sinkBroker(pubsubable); broker != "" {
+		var sourceURL *apis.URL
+		if source != "" {
+			// The sources are URI-references, they don't fail to parse.
+			sourceURL, _ = apis.ParseURL(source)
+		}
+		for _, info := range types {
+			desired = append(desired, resources.MakeEventType(&resources.EventTypeArgs{
+				Namespace: namespace,
+				Name:      name,
+				Owner:     pubsubable,
+				Broker:    broker,
+				Source:    sourceURL,
+				Info:      info,
+				Labels:    ls,
+			}))
+		}
+	}
+
+	existing, err := psb.eventTypeLister.EventTypes(namespace).List(labels.SelectorFromSet(ls))
+	if err != nil {
+		logging.FromContext(ctx).Desugar().Error("Failed to list EventTypes", zap.Error(err))
+		return pkgreconciler.NewEvent(corev1.EventTypeWarning, eventTypesListFailedReason, "Listing EventTypes failed with: %s", err.Error())
+	}
+	eventTypes := psb.eventingClient.EventingV1beta1().EventTypes(namespace)
+	current := make(map[string]bool, len(existing))
+	for _, et := range existing {
+		if !metav1.IsControlledBy(et, pubsubable.GetObjectMeta()) {
+			continue
+		}
+		if want := findEventType(desired, et.Name); want != nil && equality.Semantic.DeepEqual(want.Spec, et.Spec) {
+			current[et.Name] = true
+			continue
+		}
+		// The spec of an EventType is immutable, so the EventTypes that changed
+		// are deleted and created again.
+		logging.FromContext(ctx).Desugar().Debug("Deleting EventType", zap.String("eventType", et.Name))
+		if err := eventTypes.Delete(ctx, et.Name, metav1.DeleteOptions{}); err != nil && !apierrs.IsNotFound(err) {
+			logging.FromContext(ctx).Desugar().Error("Failed to delete EventType", zap.String("eventType", et.Name), zap.Error(err))
+			return pkgreconciler.NewEvent(corev1.EventTypeWarning, eventTypeDeleteFailedReason, "Deleting EventType %q failed with: %s", et.Name, err.Error())
+		}
+	}
+
+	for _, et := range desired {
+		if current[et.Name] {
+			continue
+		}
+		logging.FromContext(ctx).Desugar().Debug("Creating EventType", zap.Any("eventType", et))
+		if _, err := eventTypes.Create(ctx, et, metav1.CreateOptions{}); err != nil {
+			logging.FromContext(ctx).Desugar().Error("Failed to create EventType", zap.Any("eventType", et), zap.Error(err))
+			return pkgreconciler.NewEvent(corev1.EventTypeWarning, eventTypeCreateFailedReason, "Creating EventType %q failed with: %s", et.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// sinkBroker returns the name of the sink of pubsubable if it is a Broker of
+// its namespace, or "" otherwise.
+func sinkBroker(pubsubable duck.PubSubable) string {
+	ref := pubsubable.PubSubSpec().Sink.Ref
+	if ref == nil || ref.Kind != brokerKind {
+		return ""
+	}
+	if gv, err := schema.ParseGroupVersion(ref.APIVersion); err != nil || gv.Group != eventingGroup {
+		return ""
+	}
+	if ref.Namespace != "" && ref.Namespace != pubsubable.GetObjectMeta().GetNamespace() {
+		return ""
+	}
+	return ref.Name
+}
+
+func findEventType(eventTypes []*eventingv1beta1.EventType, name string) *eventingv1beta1.EventType {
+	for _, et := range eventTypes {
+		if et.Name == name {
+			return et
+		}
+	}
+	return nil
+}
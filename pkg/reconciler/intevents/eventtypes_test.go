@@ -0,0 +1,145 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package intevents
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	fakeeventingclient "knative.dev/eventing/pkg/client/clientset/versioned/fake"
+	eventinglisters "knative.dev/eventing/pkg/client/listers/eventing/v1beta1"
+	"knative.dev/pkg/apis"
+	logtesting "knative.dev/pkg/logging/testing"
+
+	v1 "github.com/google/knative-gcp/pkg/apis/events/v1"
+	"github.com/google/knative-gcp/pkg/reconciler"
+	"github.com/google/knative-gcp/pkg/reconciler/intevents/resources"
+	reconcilertestingv1 "github.com/google/knative-gcp/pkg/reconciler/testing/v1"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
+)
+
+const eventTypesSource = "//pubsub.googleapis.com/projects/project/topics/topic"
+
+var (
+	brokerGVK = metav1.GroupVersionKind{
+		Group:   "eventing.knative.dev",
+		Version: "v1beta1",
+		Kind:    "Broker",
+	}
+	serviceGVK = metav1.GroupVersionKind{
+		Group:   "serving.knative.dev",
+		Version: "v1",
+		Kind:    "Service",
+	}
+)
+
+func TestReconcileEventTypes(t *testing.T) {
+	brokerSource := reconcilertestingv1.NewCloudPubSubSource(name, testNS,
+		reconcilertestingv1.WithCloudPubSubSourceSink(brokerGVK, "default"))
+	serviceSource := reconcilertestingv1.NewCloudPubSubSource(name, testNS,
+		reconcilertestingv1.WithCloudPubSubSourceSink(serviceGVK, "sink"))
+	otherBrokerSource := reconcilertestingv1.NewCloudPubSubSource(name, testNS,
+		reconcilertestingv1.WithCloudPubSubSourceSink(brokerGVK, "other"))
+
+	testCases := []struct {
+		name        string
+		source      *v1.CloudPubSubSource
+		objects     []runtime.Object
+		wantCreates []runtime.Object
+		wantDeletes []string
+	}{{
+		name:        "sink is a broker",
+		source:      brokerSource,
+		wantCreates: []runtime.Object{newEventType(brokerSource, "default")},
+	}, {
+		name:   "sink is not a broker",
+		source: serviceSource,
+	}, {
+		name:    "up to date",
+		source:  brokerSource,
+		objects: []runtime.Object{newEventType(brokerSource, "default")},
+	}, {
+		name:        "sink changed to another broker",
+		source:      otherBrokerSource,
+		objects:     []runtime.Object{newEventType(brokerSource, "default")},
+		wantCreates: []runtime.Object{newEventType(otherBrokerSource, "other")},
+		wantDeletes: []string{resources.GenerateEventTypeName(name, schemasv1.CloudPubSubMessagePublishedEventType)},
+	}, {
+		name:        "sink is no longer a broker",
+		source:      serviceSource,
+		objects:     []runtime.Object{newEventType(brokerSource, "default")},
+		wantDeletes: []string{resources.GenerateEventTypeName(name, schemasv1.CloudPubSubMessagePublishedEventType)},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := fakeeventingclient.NewSimpleClientset(tc.objects...)
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, obj := range tc.objects {
+				indexer.Add(obj)
+			}
+			psBase := &PubSubBase{
+				Base:               &reconciler.Base{},
+				eventingClient:     cs,
+				eventTypeLister:    eventinglisters.NewEventTypeLister(indexer),
+				receiveAdapterName: receiveAdapterName,
+			}
+			psBase.Logger = logtesting.TestLogger(t)
+
+			if event := psBase.ReconcileEventTypes(context.Background(), tc.source, eventTypesSource, schemasv1.EventTypes("CloudPubSubSource")); event != nil {
+				t.Fatalf("ReconcileEventTypes failed: %v", event)
+			}
+
+			var gotCreates []runtime.Object
+			var gotDeletes []string
+			for _, action := range cs.Actions() {
+				switch a := action.(type) {
+				case clientgotesting.CreateAction:
+					gotCreates = append(gotCreates, a.GetObject())
+				case clientgotesting.DeleteAction:
+					gotDeletes = append(gotDeletes, a.GetName())
+				}
+			}
+			if diff := cmp.Diff(tc.wantCreates, gotCreates); diff != "" {
+				t.Errorf("Unexpected creates (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(tc.wantDeletes, gotDeletes); diff != "" {
+				t.Errorf("Unexpected deletes (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func newEventType(source *v1.CloudPubSubSource, broker string) *eventingv1beta1.EventType {
+	info, _ := schemasv1.EventTypeInfoOf("CloudPubSubSource", schemasv1.CloudPubSubMessagePublishedEventType)
+	url, _ := apis.ParseURL(eventTypesSource)
+	return resources.MakeEventType(&resources.EventTypeArgs{
+		Namespace: testNS,
+		Name:      name,
+		Owner:     source,
+		Broker:    broker,
+		Source:    url,
+		Info:      info,
+		Labels:    resources.GetLabels(receiveAdapterName, name),
+	})
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"time"
 
+	"cloud.google.com/go/pubsub"
 	"go.uber.org/zap"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -66,6 +67,10 @@ const (
 	// If the topic of the subscription has been deleted, the value of its topic becomes "_deleted-topic_".
 	// See https://cloud.google.com/pubsub/docs/reference/rpc/google.pubsub.v1#subscription
 	deletedTopic = "_deleted-topic_"
+
+	// The Pub/Sub defaults of the retry policy backoffs.
+	defaultMinimumBackoff = 10 * time.Second
+	defaultMaximumBackoff = 600 * time.Second
 )
 
 // Base implements the core controller logic for pullsubscription.
@@ -219,6 +224,14 @@ func (r *Base) reconcileSubscription(ctx context.Context, ps *v1.PullSubscriptio
 		subConfig.RetentionDuration = retentionDuration
 	}
 
+	retryPolicy, err := pubsubRetryPolicy(ps.Spec.RetryPolicy)
+	if err != nil {
+		logging.FromContext(ctx).Desugar().Error("Invalid retryPolicy", zap.Error(err))
+		return "", err
+	}
+	subConfig.RetryPolicy = retryPolicy
+	subConfig.DeadLetterPolicy = pubsubDeadLetterPolicy(ps.Status.ProjectID, ps.Spec.DeadLetterPolicy)
+
 	// Check if the topic of the subscription is "_deleted-topic_"
 	if subExists {
 		config, err := sub.Config(ctx)
@@ -239,6 +252,13 @@ func (r *Base) reconcileSubscription(ctx context.Context, ps *v1.PullSubscriptio
 				logging.FromContext(ctx).Desugar().Error("Failed to create subscription", zap.Error(err))
 				return "", err
 			}
+		} else if update, ok := policiesUpdate(config, subConfig); ok {
+			// The retry and dead letter policies are the only mutable fields of the spec
+			// that are part of the subscription config.
+			if _, err := sub.Update(ctx, update); err != nil {
+				logging.FromContext(ctx).Desugar().Error("Failed to update subscription", zap.Error(err))
+				return "", err
+			}
 		}
 	} else {
 		sub, err = client.CreateSubscription(ctx, subID, subConfig)
@@ -247,10 +267,97 @@ func (r *Base) reconcileSubscription(ctx context.Context, ps *v1.PullSubscriptio
 			return "", err
 		}
 	}
-	// TODO update the subscription's config if needed.
 	return subID, nil
 }
 
+// pubsubRetryPolicy translates the retry policy of the PullSubscription to a
+// Pub/Sub retry policy, filling in the Pub/Sub defaults so that it can be
+// compared with the one of an existing subscription.
+func pubsubRetryPolicy(rp *v1.RetryPolicy) (*pubsub.RetryPolicy, error) {
+	if rp == nil {
+		return nil, nil
+	}
+	minimumBackoff := defaultMinimumBackoff
+	if rp.MinimumBackoff != nil {
+		d, err := time.ParseDuration(*rp.MinimumBackoff)
+		if err != nil {
+			return nil, fmt.Errorf("invalid minimumBackoff: %w", err)
+		}
+		minimumBackoff = d
+	}
+	maximumBackoff := defaultMaximumBackoff
+	if rp.MaximumBackoff != nil {
+		d, err := time.ParseDuration(*rp.MaximumBackoff)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maximumBackoff: %w", err)
+		}
+		maximumBackoff = d
+	}
+	return &pubsub.RetryPolicy{
+		MinimumBackoff: minimumBackoff,
+		MaximumBackoff: maximumBackoff,
+	}, nil
+}
+
+// pubsubDeadLetterPolicy translates the dead letter policy of the
+// PullSubscription to a Pub/Sub dead letter policy.
+func pubsubDeadLetterPolicy(projectID string, dlp *v1.DeadLetterPolicy) *pubsub.DeadLetterPolicy {
+	if dlp == nil {
+		return nil
+	}
+	return &pubsub.DeadLetterPolicy{
+		DeadLetterTopic:     fmt.Sprintf("projects/%s/topics/%s", projectID, dlp.DeadLetterTopic),
+		MaxDeliveryAttempts: int(dlp.MaxDeliveryAttempts),
+	}
+}
+
+// policiesUpdate returns the config to update the existing subscription with
+// when its retry or dead letter policies differ from the wanted ones. Empty
+// policies remove the existing ones.
+func policiesUpdate(existing, wanted gpubsub.SubscriptionConfig) (gpubsub.SubscriptionConfig, bool) {
+	update := existing
+	changed := false
+	if !retryPolicyEqual(existing.RetryPolicy, wanted.RetryPolicy) {
+		update.RetryPolicy = wanted.RetryPolicy
+		if update.RetryPolicy == nil {
+			update.RetryPolicy = &pubsub.RetryPolicy{}
+		}
+		changed = true
+	} else {
+		update.RetryPolicy = nil
+	}
+	if !deadLetterPolicyEqual(existing.DeadLetterPolicy, wanted.DeadLetterPolicy) {
+		update.DeadLetterPolicy = wanted.DeadLetterPolicy
+		if update.DeadLetterPolicy == nil {
+			update.DeadLetterPolicy = &pubsub.DeadLetterPolicy{}
+		}
+		changed = true
+	} else {
+		update.DeadLetterPolicy = nil
+	}
+	return update, changed
+}
+
+func retryPolicyEqual(a, b *pubsub.RetryPolicy) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return durationOf(a.MinimumBackoff) == durationOf(b.MinimumBackoff) &&
+		durationOf(a.MaximumBackoff) == durationOf(b.MaximumBackoff)
+}
+
+func deadLetterPolicyEqual(a, b *pubsub.DeadLetterPolicy) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func durationOf(d interface{}) time.Duration {
+	v, _ := d.(time.Duration)
+	return v
+}
+
 // deleteSubscription looks at the status.SubscriptionID and if non-empty,
 // hence indicating that we have created a subscription successfully
 // in the PullSubscription, remove it.
@@ -26,6 +26,7 @@ import (
 	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/logging"
 
+	"github.com/google/knative-gcp/pkg/apis/duck"
 	"github.com/google/knative-gcp/pkg/apis/intevents"
 	intereventsv1 "github.com/google/knative-gcp/pkg/apis/intevents/v1"
 	"github.com/google/knative-gcp/pkg/pubsub/adapter/converters"
@@ -159,6 +160,27 @@ func makeReceiveAdapterPodSpec(ctx context.Context, args *ReceiveAdapterArgs) *c
 		}},
 	}
 
+	if sink, ok := args.PullSubscription.Annotations[duck.UnconvertibleSinkAnnotation]; ok {
+		receiveAdapterContainer.Env = append(receiveAdapterContainer.Env, corev1.EnvVar{
+			Name:  "UNCONVERTIBLE_SINK_URI",
+			Value: sink,
+		})
+	}
+
+	if validation, ok := args.PullSubscription.Annotations[duck.DataValidationAnnotation]; ok {
+		receiveAdapterContainer.Env = append(receiveAdapterContainer.Env, corev1.EnvVar{
+			Name:  "DATA_VALIDATION",
+			Value: validation,
+		})
+	}
+
+	if filter, ok := args.PullSubscription.Annotations[duck.ObjectNameFilterAnnotation]; ok {
+		receiveAdapterContainer.Env = append(receiveAdapterContainer.Env, corev1.EnvVar{
+			Name:  "OBJECT_NAME_FILTER",
+			Value: filter,
+		})
+	}
+
 	// If there is no secret to embed, return what we have.
 	if args.PullSubscription.Spec.Secret == nil {
 		return &corev1.PodSpec{
@@ -514,3 +514,102 @@ func TestMakeReceiveAdapterWithServiceAccount(t *testing.T) {
 		t.Errorf("unexpected deploy (-want, +got) = %v", diff)
 	}
 }
+
+func TestMakeReceiveAdapterWithUnconvertibleSink(t *testing.T) {
+	ps := &intereventsv1.PullSubscription{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testname",
+			Namespace: "testnamespace",
+			Annotations: map[string]string{
+				duck.UnconvertibleSinkAnnotation: "http://unconvertible",
+			},
+		},
+		Spec: intereventsv1.PullSubscriptionSpec{
+			Topic: "topic",
+		},
+	}
+
+	got := MakeReceiveAdapter(context.Background(), &ReceiveAdapterArgs{
+		Image:            "test-image",
+		PullSubscription: ps,
+		SubscriptionID:   "sub-id",
+		SinkURI:          apis.HTTP("sink-uri"),
+	})
+
+	want := corev1.EnvVar{Name: "UNCONVERTIBLE_SINK_URI", Value: "http://unconvertible"}
+	for _, env := range got.Spec.Template.Spec.Containers[0].Env {
+		if env.Name == want.Name {
+			if diff := cmp.Diff(want, env); diff != "" {
+				t.Errorf("unexpected env (-want, +got) = %v", diff)
+			}
+			return
+		}
+	}
+	t.Errorf("missing env %s", want.Name)
+}
+
+func TestMakeReceiveAdapterWithDataValidation(t *testing.T) {
+	ps := &intereventsv1.PullSubscription{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testname",
+			Namespace: "testnamespace",
+			Annotations: map[string]string{
+				duck.DataValidationAnnotation: "reject",
+			},
+		},
+		Spec: intereventsv1.PullSubscriptionSpec{
+			Topic: "topic",
+		},
+	}
+
+	got := MakeReceiveAdapter(context.Background(), &ReceiveAdapterArgs{
+		Image:            "test-image",
+		PullSubscription: ps,
+		SubscriptionID:   "sub-id",
+		SinkURI:          apis.HTTP("sink-uri"),
+	})
+
+	want := corev1.EnvVar{Name: "DATA_VALIDATION", Value: "reject"}
+	for _, env := range got.Spec.Template.Spec.Containers[0].Env {
+		if env.Name == want.Name {
+			if diff := cmp.Diff(want, env); diff != "" {
+				t.Errorf("unexpected env (-want, +got) = %v", diff)
+			}
+			return
+		}
+	}
+	t.Errorf("missing env %s", want.Name)
+}
+
+func TestMakeReceiveAdapterWithObjectNameFilter(t *testing.T) {
+	ps := &intereventsv1.PullSubscription{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testname",
+			Namespace: "testnamespace",
+			Annotations: map[string]string{
+				duck.ObjectNameFilterAnnotation: `{"include":["**.jpg"]}`,
+			},
+		},
+		Spec: intereventsv1.PullSubscriptionSpec{
+			Topic: "topic",
+		},
+	}
+
+	got := MakeReceiveAdapter(context.Background(), &ReceiveAdapterArgs{
+		Image:            "test-image",
+		PullSubscription: ps,
+		SubscriptionID:   "sub-id",
+		SinkURI:          apis.HTTP("sink-uri"),
+	})
+
+	want := corev1.EnvVar{Name: "OBJECT_NAME_FILTER", Value: `{"include":["**.jpg"]}`}
+	for _, env := range got.Spec.Template.Spec.Containers[0].Env {
+		if env.Name == want.Name {
+			if diff := cmp.Diff(want, env); diff != "" {
+				t.Errorf("unexpected env (-want, +got) = %v", diff)
+			}
+			return
+		}
+	}
+	t.Errorf("missing env %s", want.Name)
+}
@@ -19,7 +19,9 @@ package intevents
 import (
 	"context"
 	"fmt"
+	"time"
 
+	gcpduck "github.com/google/knative-gcp/pkg/apis/duck"
 	duckv1 "github.com/google/knative-gcp/pkg/apis/duck/v1"
 	inteventsv1 "github.com/google/knative-gcp/pkg/apis/intevents/v1"
 	clientset "github.com/google/knative-gcp/pkg/client/clientset/versioned"
@@ -32,6 +34,8 @@ import (
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	eventingclientset "knative.dev/eventing/pkg/client/clientset/versioned"
+	eventinglisters "knative.dev/eventing/pkg/client/listers/eventing/v1beta1"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/logging"
 	pkgreconciler "knative.dev/pkg/reconciler"
@@ -46,17 +50,32 @@ const (
 
 var falseVal = false
 
+// adapterAnnotations are the annotations of a pubsubable configuring its receive
+// adapter. They are kept in sync with its PullSubscription.
+var adapterAnnotations = []string{
+	gcpduck.UnconvertibleSinkAnnotation,
+	gcpduck.DataValidationAnnotation,
+}
+
 type PubSubBase struct {
 	*reconciler.Base
 
 	// For dealing with Topics and Pullsubscriptions
 	pubsubClient clientset.Interface
 
+	// For dealing with EventTypes
+	eventingClient  eventingclientset.Interface
+	eventTypeLister eventinglisters.EventTypeLister
+
 	// What do we tag receive adapter as.
 	receiveAdapterName string
 
 	// What type of receive adapter to use.
 	receiveAdapterType string
+
+	// driftRetention is how long the DriftDetected condition is kept after the
+	// last repair.
+	driftRetention time.Duration
 }
 
 // ReconcilePubSub reconciles Topic / PullSubscription given a PubSubSpec.
@@ -65,12 +84,21 @@ type PubSubBase struct {
 // Also sets the following fields in the pubsubable.Status upon success
 // TopicID, ProjectID, and SinkURI
 func (psb *PubSubBase) ReconcilePubSub(ctx context.Context, pubsubable duck.PubSubable, topic, resourceGroup string) (*inteventsv1.Topic, *inteventsv1.PullSubscription, error) {
+	return psb.ReconcilePubSubWithAnnotations(ctx, pubsubable, topic, resourceGroup, nil)
+}
+
+// ReconcilePubSubWithAnnotations is ReconcilePubSub, additionally keeping the
+// given annotations of the PullSubscription in sync with the pubsubable, as
+// opposed to the annotations of the pubsubable which, apart from its adapter
+// annotations, are only set when the PullSubscription is created. The
+// annotations with an empty value are removed.
+func (psb *PubSubBase) ReconcilePubSubWithAnnotations(ctx context.Context, pubsubable duck.PubSubable, topic, resourceGroup string, annotations map[string]string) (*inteventsv1.Topic, *inteventsv1.PullSubscription, error) {
 	t, err := psb.reconcileTopic(ctx, pubsubable, topic)
 	if err != nil {
 		return t, nil, err
 	}
 
-	ps, err := psb.ReconcilePullSubscription(ctx, pubsubable, topic, resourceGroup)
+	ps, err := psb.reconcilePullSubscription(ctx, pubsubable, topic, resourceGroup, annotations)
 	if err != nil {
 		return t, ps, err
 	}
@@ -130,6 +158,10 @@ func (psb *PubSubBase) reconcileTopic(ctx context.Context, pubsubable duck.PubSu
 }
 
 func (psb *PubSubBase) ReconcilePullSubscription(ctx context.Context, pubsubable duck.PubSubable, topic, resourceGroup string) (*inteventsv1.PullSubscription, pkgreconciler.Event) {
+	return psb.reconcilePullSubscription(ctx, pubsubable, topic, resourceGroup, nil)
+}
+
+func (psb *PubSubBase) reconcilePullSubscription(ctx context.Context, pubsubable duck.PubSubable, topic, resourceGroup string, managed map[string]string) (*inteventsv1.PullSubscription, pkgreconciler.Event) {
 	if pubsubable == nil {
 		logging.FromContext(ctx).Desugar().Error("Nil pubsubable passed in")
 		return nil, pkgreconciler.NewEvent(corev1.EventTypeWarning, nilPubsubableReason, "nil pubsubable passed in")
@@ -139,6 +171,7 @@ func (psb *PubSubBase) ReconcilePullSubscription(ctx context.Context, pubsubable
 	annotations := pubsubable.GetObjectMeta().GetAnnotations()
 	spec := pubsubable.PubSubSpec()
 	status := pubsubable.PubSubStatus()
+	managed = withAdapterAnnotations(annotations, managed)
 
 	cs := pubsubable.ConditionSet()
 
@@ -150,7 +183,7 @@ func (psb *PubSubBase) ReconcilePullSubscription(ctx context.Context, pubsubable
 		Topic:       topic,
 		AdapterType: psb.receiveAdapterType,
 		Labels:      resources.GetLabels(psb.receiveAdapterName, name),
-		Annotations: resources.GetAnnotations(annotations, resourceGroup),
+		Annotations: withAnnotations(resources.GetAnnotations(annotations, resourceGroup), managed),
 	}
 
 	newPS := resources.MakePullSubscription(args)
@@ -169,10 +202,11 @@ func (psb *PubSubBase) ReconcilePullSubscription(ctx context.Context, pubsubable
 			return nil, pkgreconciler.NewEvent(corev1.EventTypeWarning, pullSubscriptionCreateFailedReason, "Creating PullSubscription failed with: %s", err.Error())
 		}
 		// Check whether the specs differ and update the PS if so.
-	} else if !equality.Semantic.DeepDerivative(newPS.Spec, ps.Spec) {
+	} else if !equality.Semantic.DeepDerivative(newPS.Spec, ps.Spec) || !hasAnnotations(ps.Annotations, managed) {
 		// Don't modify the informers copy.
 		desired := ps.DeepCopy()
 		desired.Spec = newPS.Spec
+		desired.Annotations = withAnnotations(desired.Annotations, managed)
 		logging.FromContext(ctx).Desugar().Debug("Updating PullSubscription", zap.Any("ps", desired))
 		ps, err = pullSubscriptions.Update(ctx, desired, v1.UpdateOptions{})
 		if err != nil {
@@ -191,6 +225,50 @@ func (psb *PubSubBase) ReconcilePullSubscription(ctx context.Context, pubsubable
 	return ps, nil
 }
 
+// withAnnotations returns a copy of annotations with the given managed
+// annotations set, or removed if their value is empty.
+func withAnnotations(annotations, managed map[string]string) map[string]string {
+	if len(managed) == 0 {
+		return annotations
+	}
+	res := make(map[string]string, len(annotations)+len(managed))
+	for k, v := range annotations {
+		res[k] = v
+	}
+	for k, v := range managed {
+		if v == "" {
+			delete(res, k)
+		} else {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+// withAdapterAnnotations returns the managed annotations along with the adapter
+// annotations of the pubsubable, empty if they are not set so that they are
+// removed from the PullSubscription.
+func withAdapterAnnotations(annotations, managed map[string]string) map[string]string {
+	res := make(map[string]string, len(adapterAnnotations)+len(managed))
+	for _, k := range adapterAnnotations {
+		res[k] = annotations[k]
+	}
+	for k, v := range managed {
+		res[k] = v
+	}
+	return res
+}
+
+// hasAnnotations returns true if the given managed annotations are in sync.
+func hasAnnotations(annotations, managed map[string]string) bool {
+	for k, v := range managed {
+		if got, ok := annotations[k]; got != v || (v == "" && ok) {
+			return false
+		}
+	}
+	return true
+}
+
 func propagatePullSubscriptionStatus(ps *inteventsv1.PullSubscription, status *duckv1.PubSubStatus, cs *apis.ConditionSet) error {
 	pc := ps.Status.GetTopLevelCondition()
 	if pc == nil {
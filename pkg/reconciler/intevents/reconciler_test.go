@@ -1109,3 +1109,55 @@ func TestDeletes(t *testing.T) {
 		}
 	}
 }
+
+func TestManagedAnnotations(t *testing.T) {
+	annotations := map[string]string{"a": "1", "b": "2"}
+	managed := map[string]string{"b": "3", "c": "4", "a": ""}
+
+	if hasAnnotations(annotations, managed) {
+		t.Error("hasAnnotations got true for annotations out of sync")
+	}
+	got := withAnnotations(annotations, managed)
+	if diff := cmp.Diff(map[string]string{"b": "3", "c": "4"}, got); diff != "" {
+		t.Errorf("unexpected annotations (-want, +got) = %v", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"a": "1", "b": "2"}, annotations); diff != "" {
+		t.Errorf("withAnnotations modified the original annotations (-want, +got) = %v", diff)
+	}
+	if !hasAnnotations(got, managed) {
+		t.Error("hasAnnotations got false for annotations in sync")
+	}
+	if !hasAnnotations(annotations, nil) {
+		t.Error("hasAnnotations got false without managed annotations")
+	}
+}
+
+func TestAdapterAnnotations(t *testing.T) {
+	annotations := map[string]string{
+		duck.DataValidationAnnotation: "reject",
+		duck.ClusterNameAnnotation:    testingmetadata.FakeClusterName,
+	}
+	managed := map[string]string{duck.ObjectNameFilterAnnotation: `["*.csv"]`}
+
+	got := withAdapterAnnotations(annotations, managed)
+	want := map[string]string{
+		duck.DataValidationAnnotation:    "reject",
+		duck.UnconvertibleSinkAnnotation: "",
+		duck.ObjectNameFilterAnnotation:  `["*.csv"]`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected managed annotations (-want, +got) = %v", diff)
+	}
+
+	// A PullSubscription created before the annotations changed is out of sync.
+	ps := map[string]string{duck.UnconvertibleSinkAnnotation: "http://unconvertible"}
+	if hasAnnotations(ps, got) {
+		t.Error("hasAnnotations got true for adapter annotations out of sync")
+	}
+	if diff := cmp.Diff(map[string]string{
+		duck.DataValidationAnnotation:   "reject",
+		duck.ObjectNameFilterAnnotation: `["*.csv"]`,
+	}, withAnnotations(ps, got)); diff != "" {
+		t.Errorf("unexpected PullSubscription annotations (-want, +got) = %v", diff)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redisstreamsource
+
+import (
+	"context"
+
+	"github.com/kelseyhightower/envconfig"
+	"go.uber.org/zap"
+	"k8s.io/client-go/tools/cache"
+	secretinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/secret"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/resolver"
+
+	"github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+	redisstreamsourceinformer "github.com/google/knative-gcp/pkg/client/injection/informers/intevents/v1alpha1/redisstreamsource"
+	statefulsetinformer "github.com/google/knative-gcp/pkg/client/injection/kube/informers/apps/v1/statefulset"
+	redisstreamsourcereconciler "github.com/google/knative-gcp/pkg/client/injection/reconciler/intevents/v1alpha1/redisstreamsource"
+	"github.com/google/knative-gcp/pkg/reconciler"
+)
+
+const (
+	// reconcilerName is the name of the reconciler
+	reconcilerName = "RedisStreamSources"
+
+	// controllerAgentName is the string used by this controller to identify
+	// itself when creating events.
+	controllerAgentName = "cloud-run-events-redisstreamsource-controller"
+)
+
+type envConfig struct {
+	// ReceiveAdapter is the receive adapters image. Required.
+	ReceiveAdapter string `envconfig:"REDISSTREAM_RA_IMAGE" required:"true"`
+}
+
+type Constructor injection.ControllerConstructor
+
+// NewConstructor creates a constructor to make a RedisStreamSource controller.
+func NewConstructor() Constructor {
+	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+		return newController(ctx, cmw)
+	}
+}
+
+func newController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+	sourceInformer := redisstreamsourceinformer.Get(ctx)
+	statefulSetInformer := statefulsetinformer.Get(ctx)
+	secretInformer := secretinformer.Get(ctx)
+
+	logger := logging.FromContext(ctx).Named(controllerAgentName).Desugar()
+
+	var env envConfig
+	if err := envconfig.Process("", &env); err != nil {
+		logger.Fatal("Failed to process env var", zap.Error(err))
+	}
+
+	r := &Reconciler{
+		Base:                reconciler.NewBase(ctx, controllerAgentName, cmw),
+		statefulSetLister:   statefulSetInformer.Lister(),
+		secretLister:        secretInformer.Lister(),
+		receiveAdapterImage: env.ReceiveAdapter,
+	}
+	r.createGroupManagerFn = r.newGroupManager
+
+	impl := redisstreamsourcereconciler.NewImpl(ctx, r)
+
+	r.Logger.Info("Setting up event handlers")
+	sourceInformer.Informer().AddEventHandlerWithResyncPeriod(controller.HandleAll(impl.Enqueue), reconciler.DefaultResyncPeriod)
+
+	statefulSetInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterControllerGK(v1alpha1.Kind("RedisStreamSource")),
+		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+	})
+
+	r.sinkResolver = resolver.NewURIResolver(ctx, impl.EnqueueKey)
+
+	cmw.Watch(logging.ConfigMapName(), r.UpdateFromLoggingConfigMap)
+	cmw.Watch(metrics.ConfigMapName(), r.UpdateFromMetricsConfigMap)
+
+	return impl
+}
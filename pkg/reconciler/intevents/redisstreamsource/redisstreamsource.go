@@ -0,0 +1,265 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redisstreamsource
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/metrics"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/resolver"
+
+	"github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+	redisstreamsourcereconciler "github.com/google/knative-gcp/pkg/client/injection/reconciler/intevents/v1alpha1/redisstreamsource"
+	"github.com/google/knative-gcp/pkg/reconciler"
+	"github.com/google/knative-gcp/pkg/reconciler/intevents/redisstreamsource/resources"
+	reconcilerutilsredis "github.com/google/knative-gcp/pkg/reconciler/utils/redis"
+	"github.com/google/knative-gcp/pkg/redis"
+)
+
+const (
+	component = "redisstreamsource"
+
+	deleteGroupFailed           = "GroupDeleteFailed"
+	reconciledGroupFailedReason = "GroupReconcileFailed"
+	reconciledStatefulSetFailed = "StatefulSetReconcileFailed"
+	reconciledSuccessReason     = "RedisStreamSourceReconciled"
+	reconciledSinkFailedReason  = "InvalidSink"
+	statefulSetCreated          = "StatefulSetCreated"
+	statefulSetUpdated          = "StatefulSetUpdated"
+	finalizedSuccessReason      = "RedisStreamSourceFinalized"
+)
+
+// GroupManager creates and destroys the consumer groups of a Redis stream.
+// It is implemented by redis.Client.
+type GroupManager interface {
+	CreateGroup(ctx context.Context, stream, group, id string) error
+	DestroyGroup(ctx context.Context, stream, group string) error
+	Close() error
+}
+
+// CreateGroupManagerFn creates a GroupManager connected to the Redis instance
+// of the source.
+type CreateGroupManagerFn func(ctx context.Context, source *v1alpha1.RedisStreamSource) (GroupManager, error)
+
+// Reconciler implements controller.Reconciler for RedisStreamSource resources.
+type Reconciler struct {
+	*reconciler.Base
+
+	statefulSetLister appsv1listers.StatefulSetLister
+	secretLister      corev1listers.SecretLister
+	sinkResolver      *resolver.URIResolver
+
+	receiveAdapterImage string
+	loggingConfig       *logging.Config
+	metricsConfig       *metrics.ExporterOptions
+
+	// createGroupManagerFn is the function used to connect to Redis to
+	// manage consumer groups. This is needed so that we can inject a fake
+	// for UTs purposes.
+	createGroupManagerFn CreateGroupManagerFn
+}
+
+// Check that our Reconciler implements Interface and Finalizer.
+var _ redisstreamsourcereconciler.Interface = (*Reconciler)(nil)
+var _ redisstreamsourcereconciler.Finalizer = (*Reconciler)(nil)
+
+func (r *Reconciler) ReconcileKind(ctx context.Context, source *v1alpha1.RedisStreamSource) pkgreconciler.Event {
+	ctx = logging.WithLogger(ctx, r.Logger.With(zap.Any("redisstreamsource", source)))
+
+	source.Status.InitializeConditions()
+	source.Status.ObservedGeneration = source.Generation
+
+	dest := *source.Spec.Sink.DeepCopy()
+	// To call URIFromDestinationV1(), dest.Ref must have a Namespace. If there is
+	// no Namespace defined in dest.Ref, we will use the Namespace of the source.
+	if dest.Ref != nil && dest.Ref.Namespace == "" {
+		dest.Ref.Namespace = source.Namespace
+	}
+	sinkURI, err := r.sinkResolver.URIFromDestinationV1(ctx, dest, source)
+	if err != nil {
+		source.Status.MarkNoSink(reconciledSinkFailedReason, "%s", err)
+		return pkgreconciler.NewEvent(corev1.EventTypeWarning, reconciledSinkFailedReason, "InvalidSink: %s", err.Error())
+	}
+	source.Status.MarkSink(sinkURI.String())
+
+	if err := r.reconcileGroup(ctx, source); err != nil {
+		return pkgreconciler.NewEvent(corev1.EventTypeWarning, reconciledGroupFailedReason, "Failed to reconcile consumer group: %s", err.Error())
+	}
+
+	ss, err := r.reconcileReceiveAdapter(ctx, source)
+	if err != nil {
+		return pkgreconciler.NewEvent(corev1.EventTypeWarning, reconciledStatefulSetFailed, "Failed to reconcile receive adapter: %s", err.Error())
+	}
+	source.Status.PropagateStatefulSetAvailability(ss)
+
+	return pkgreconciler.NewEvent(corev1.EventTypeNormal, reconciledSuccessReason, `RedisStreamSource reconciled: "%s/%s"`, source.Namespace, source.Name)
+}
+
+func (r *Reconciler) FinalizeKind(ctx context.Context, source *v1alpha1.RedisStreamSource) pkgreconciler.Event {
+	if err := r.deleteGeneratedGroup(ctx, source); err != nil {
+		return pkgreconciler.NewEvent(corev1.EventTypeWarning, deleteGroupFailed, "Failed to delete consumer group: %s", err.Error())
+	}
+	return pkgreconciler.NewEvent(corev1.EventTypeNormal, finalizedSuccessReason, `RedisStreamSource finalized: "%s/%s"`, source.Namespace, source.Name)
+}
+
+// reconcileGroup sets the consumer group used by the source in its status.
+// If the spec doesn't set a group, a group is created for the source, starting
+// at the end of the stream.
+func (r *Reconciler) reconcileGroup(ctx context.Context, source *v1alpha1.RedisStreamSource) error {
+	if source.Spec.Group != "" {
+		// The group is managed by the user. Delete the group we may have
+		// created before the user set it.
+		if err := r.deleteGeneratedGroup(ctx, source); err != nil {
+			return err
+		}
+		source.Status.Group = source.Spec.Group
+		return nil
+	}
+
+	group := resources.GenerateGroupName(source)
+	gm, err := r.createGroupManagerFn(ctx, source)
+	if err != nil {
+		logging.FromContext(ctx).Desugar().Error("Failed to connect to Redis", zap.Error(err))
+		return err
+	}
+	defer gm.Close()
+	if err := gm.CreateGroup(ctx, source.Spec.Stream, group, redis.LastMessage); err != nil {
+		logging.FromContext(ctx).Desugar().Error("Failed to create consumer group", zap.String("group", group), zap.Error(err))
+		return err
+	}
+	source.Status.Group = group
+	return nil
+}
+
+// deleteGeneratedGroup deletes the consumer group created for the source, if
+// any.
+func (r *Reconciler) deleteGeneratedGroup(ctx context.Context, source *v1alpha1.RedisStreamSource) error {
+	group := resources.GenerateGroupName(source)
+	if source.Status.Group != group {
+		return nil
+	}
+	gm, err := r.createGroupManagerFn(ctx, source)
+	if err != nil {
+		logging.FromContext(ctx).Desugar().Error("Failed to connect to Redis", zap.Error(err))
+		return err
+	}
+	defer gm.Close()
+	if err := gm.DestroyGroup(ctx, source.Spec.Stream, group); err != nil {
+		logging.FromContext(ctx).Desugar().Error("Failed to delete consumer group", zap.String("group", group), zap.Error(err))
+		return err
+	}
+	source.Status.Group = ""
+	return nil
+}
+
+func (r *Reconciler) reconcileReceiveAdapter(ctx context.Context, source *v1alpha1.RedisStreamSource) (*appsv1.StatefulSet, error) {
+	loggingConfig, err := logging.LoggingConfigToJson(r.loggingConfig)
+	if err != nil {
+		logging.FromContext(ctx).Desugar().Error("Error serializing existing logging config", zap.Error(err))
+	}
+	if r.metricsConfig != nil {
+		r.metricsConfig.Component = component
+	}
+	metricsConfig, err := metrics.MetricsOptionsToJson(r.metricsConfig)
+	if err != nil {
+		logging.FromContext(ctx).Desugar().Error("Error serializing metrics config", zap.Error(err))
+	}
+
+	desired := resources.MakeReceiveAdapter(ctx, &resources.ReceiveAdapterArgs{
+		Image:         r.receiveAdapterImage,
+		Source:        source,
+		Labels:        resources.GetLabels(controllerAgentName, source.Name),
+		Group:         source.Status.Group,
+		SinkURI:       source.Status.SinkURI,
+		LoggingConfig: loggingConfig,
+		MetricsConfig: metricsConfig,
+	})
+
+	current, err := r.statefulSetLister.StatefulSets(desired.Namespace).Get(desired.Name)
+	if apierrs.IsNotFound(err) {
+		current, err = r.KubeClientSet.AppsV1().StatefulSets(desired.Namespace).Create(ctx, desired, metav1.CreateOptions{})
+		if err != nil {
+			logging.FromContext(ctx).Desugar().Error("Error creating receive adapter", zap.Error(err))
+			return nil, err
+		}
+		r.Recorder.Eventf(source, corev1.EventTypeNormal, statefulSetCreated, "Created statefulset %s/%s", desired.Namespace, desired.Name)
+		return current, nil
+	}
+	if err != nil {
+		logging.FromContext(ctx).Desugar().Error("Error getting receive adapter", zap.Error(err))
+		return nil, err
+	}
+	if !metav1.IsControlledBy(current, source) {
+		return nil, fmt.Errorf("statefulset %s/%s is not owned by RedisStreamSource %q", current.Namespace, current.Name, source.Name)
+	}
+	if equality.Semantic.DeepDerivative(desired.Spec, current.Spec) {
+		return current, nil
+	}
+	// Don't modify the informers copy.
+	existing := current.DeepCopy()
+	existing.Spec = desired.Spec
+	current, err = r.KubeClientSet.AppsV1().StatefulSets(existing.Namespace).Update(ctx, existing, metav1.UpdateOptions{})
+	if err != nil {
+		logging.FromContext(ctx).Desugar().Error("Error updating receive adapter", zap.Error(err))
+		return nil, err
+	}
+	r.Recorder.Eventf(source, corev1.EventTypeNormal, statefulSetUpdated, "Updated statefulset %s/%s", existing.Namespace, existing.Name)
+	return current, nil
+}
+
+// newGroupManager connects to the Redis instance of the source, reading the
+// password and TLS certificates from the secrets referenced by the source.
+func (r *Reconciler) newGroupManager(ctx context.Context, source *v1alpha1.RedisStreamSource) (GroupManager, error) {
+	return reconcilerutilsredis.NewClient(r.secretLister, source.Namespace, &source.Spec.RedisConnection)
+}
+
+func (r *Reconciler) UpdateFromLoggingConfigMap(cfg *corev1.ConfigMap) {
+	if cfg != nil {
+		delete(cfg.Data, "_example")
+	}
+
+	logcfg, err := logging.NewConfigFromConfigMap(cfg)
+	if err != nil {
+		r.Logger.Warnw("Failed to create logging config from configmap", zap.String("cfg.Name", cfg.Name))
+		return
+	}
+	r.loggingConfig = logcfg
+	r.Logger.Debugw("Update from logging ConfigMap", zap.Any("loggingCfg", cfg))
+}
+
+func (r *Reconciler) UpdateFromMetricsConfigMap(cfg *corev1.ConfigMap) {
+	if cfg != nil {
+		delete(cfg.Data, "_example")
+	}
+
+	r.metricsConfig = &metrics.ExporterOptions{
+		Domain:    metrics.Domain(),
+		ConfigMap: cfg.Data,
+	}
+	r.Logger.Debugw("Update from metrics ConfigMap", zap.Any("metricsCfg", cfg))
+}
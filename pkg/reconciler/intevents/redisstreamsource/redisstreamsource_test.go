@@ -0,0 +1,467 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redisstreamsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	. "knative.dev/pkg/reconciler/testing"
+	"knative.dev/pkg/resolver"
+
+	"github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+	"github.com/google/knative-gcp/pkg/client/injection/reconciler/intevents/v1alpha1/redisstreamsource"
+	"github.com/google/knative-gcp/pkg/reconciler"
+	"github.com/google/knative-gcp/pkg/reconciler/intevents/redisstreamsource/resources"
+	. "github.com/google/knative-gcp/pkg/reconciler/testing"
+)
+
+const (
+	sourceName = "source"
+	sourceUID  = "test-source-uid"
+	sinkName   = "sink"
+
+	testNS           = "testnamespace"
+	testImage        = "redisstream-ra-image"
+	testAddress      = "redis:6379"
+	testStream       = "mystream"
+	testGroup        = "mygroup"
+	deadLetterStream = "dead-letters"
+	generation       = 1
+
+	finalizerName = "redisstreamsources.internal.events.cloud.google.com"
+)
+
+var (
+	sinkDNS = sinkName + ".mynamespace.svc.cluster.local"
+	sinkURI = apis.HTTP(sinkDNS)
+
+	generatedGroup = fmt.Sprintf("cre-src_%s_%s_%s", testNS, sourceName, sourceUID)
+
+	sinkGVK = metav1.GroupVersionKind{
+		Group:   "testing.cloud.google.com",
+		Version: "v1",
+		Kind:    "Sink",
+	}
+)
+
+// fakeGroupManager records the consumer groups created and destroyed by the
+// reconciler.
+type fakeGroupManager struct {
+	createErr  error
+	destroyErr error
+	created    []string
+	destroyed  []string
+}
+
+func (m *fakeGroupManager) CreateGroup(_ context.Context, stream, group, _ string) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	m.created = append(m.created, stream+"/"+group)
+	return nil
+}
+
+func (m *fakeGroupManager) DestroyGroup(_ context.Context, stream, group string) error {
+	if m.destroyErr != nil {
+		return m.destroyErr
+	}
+	m.destroyed = append(m.destroyed, stream+"/"+group)
+	return nil
+}
+
+func (m *fakeGroupManager) Close() error {
+	return nil
+}
+
+func patchFinalizers(namespace, name string, add bool) clientgotesting.PatchActionImpl {
+	action := clientgotesting.PatchActionImpl{}
+	action.Name = name
+	action.Namespace = namespace
+	var fname string
+	if add {
+		fname = fmt.Sprintf("%q", finalizerName)
+	}
+	patch := `{"metadata":{"finalizers":[` + fname + `],"resourceVersion":""}}`
+	action.Patch = []byte(patch)
+	return action
+}
+
+func newSink() *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "testing.cloud.google.com/v1",
+			"kind":       "Sink",
+			"metadata": map[string]interface{}{
+				"namespace": testNS,
+				"name":      sinkName,
+			},
+			"status": map[string]interface{}{
+				"address": map[string]interface{}{
+					"hostname": sinkDNS,
+				},
+			},
+		},
+	}
+}
+
+// newSource creates a source with the spec fields used by all the test
+// cases, followed by opts.
+func newSource(opts ...RedisStreamSourceOption) *v1alpha1.RedisStreamSource {
+	return NewRedisStreamSource(sourceName, testNS, append([]RedisStreamSourceOption{
+		WithRedisStreamSourceUID(sourceUID),
+		WithRedisStreamSourceObjectMetaGeneration(generation),
+		WithRedisStreamSourceAddress(testAddress),
+		WithRedisStreamSourceStream(testStream),
+		WithRedisStreamSourceMaxDeliveries(v1alpha1.DefaultRedisMaxDeliveries),
+		WithRedisStreamSourceSink(sinkGVK, sinkName),
+	}, opts...)...)
+}
+
+func newReceiveAdapter(source *v1alpha1.RedisStreamSource, group string) *appsv1.StatefulSet {
+	return resources.MakeReceiveAdapter(context.Background(), &resources.ReceiveAdapterArgs{
+		Image:   testImage,
+		Source:  source,
+		Labels:  resources.GetLabels(controllerAgentName, sourceName),
+		Group:   group,
+		SinkURI: sinkURI,
+	})
+}
+
+func newReadyReceiveAdapter(source *v1alpha1.RedisStreamSource, group string) *appsv1.StatefulSet {
+	ss := newReceiveAdapter(source, group)
+	ss.Status.ReadyReplicas = *ss.Spec.Replicas
+	return ss
+}
+
+// groupManager returns the fake GroupManager of the test row.
+func groupManager(r *TableRow) *fakeGroupManager {
+	gm, _ := r.OtherTestData["groupManager"].(*fakeGroupManager)
+	return gm
+}
+
+func groupsCreated(want ...string) func(*testing.T, *TableRow) {
+	return func(t *testing.T, r *TableRow) {
+		if got := groupManager(r).created; fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("Created groups %v, want %v", got, want)
+		}
+	}
+}
+
+func groupsDestroyed(want ...string) func(*testing.T, *TableRow) {
+	return func(t *testing.T, r *TableRow) {
+		if got := groupManager(r).destroyed; fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("Destroyed groups %v, want %v", got, want)
+		}
+	}
+}
+
+func TestAllCases(t *testing.T) {
+	groupErr := errors.New("connection refused")
+
+	table := TableTest{{
+		Name: "bad workqueue key",
+		// Make sure Reconcile handles bad keys.
+		Key: "too/many/parts",
+	}, {
+		Name: "key not found",
+		// Make sure Reconcile handles good keys that don't exist.
+		Key: "foo/not-found",
+	}, {
+		Name: "cannot get sink",
+		Objects: []runtime.Object{
+			newSource(),
+		},
+		Key: testNS + "/" + sourceName,
+		OtherTestData: map[string]interface{}{
+			"groupManager": &fakeGroupManager{},
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
+			Eventf(corev1.EventTypeWarning, reconciledSinkFailedReason,
+				`InvalidSink: sinks.testing.cloud.google.com "sink" not found`),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName, true),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: newSource(
+				WithInitRedisStreamSourceConditions,
+				WithRedisStreamSourceStatusObservedGeneration(generation),
+				WithRedisStreamSourceNoSink(reconciledSinkFailedReason, `sinks.testing.cloud.google.com "sink" not found`),
+			),
+		}},
+		PostConditions: []func(*testing.T, *TableRow){
+			groupsCreated(),
+		},
+	}, {
+		Name: "group created, receive adapter created",
+		Objects: []runtime.Object{
+			newSource(WithRedisStreamSourceDeadLetterStream(deadLetterStream)),
+			newSink(),
+		},
+		Key: testNS + "/" + sourceName,
+		OtherTestData: map[string]interface{}{
+			"groupManager": &fakeGroupManager{},
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
+			Eventf(corev1.EventTypeNormal, statefulSetCreated, "Created statefulset %s/cre-%s-redisstream", testNS, sourceName),
+			Eventf(corev1.EventTypeNormal, reconciledSuccessReason, `RedisStreamSource reconciled: "%s/%s"`, testNS, sourceName),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName, true),
+		},
+		WantCreates: []runtime.Object{
+			newReceiveAdapter(newSource(WithRedisStreamSourceDeadLetterStream(deadLetterStream)), generatedGroup),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: newSource(
+				WithRedisStreamSourceDeadLetterStream(deadLetterStream),
+				WithInitRedisStreamSourceConditions,
+				WithRedisStreamSourceStatusObservedGeneration(generation),
+				WithRedisStreamSourceSinkURI(sinkURI),
+				WithRedisStreamSourceStatusGroup(generatedGroup),
+				WithRedisStreamSourceStatefulSetUnavailable("cre-"+sourceName+"-redisstream"),
+			),
+		}},
+		PostConditions: []func(*testing.T, *TableRow){
+			groupsCreated(testStream + "/" + generatedGroup),
+		},
+	}, {
+		Name: "group set in spec, receive adapter ready",
+		Objects: []runtime.Object{
+			newSource(
+				WithRedisStreamSourceGroup(testGroup),
+				WithRedisStreamSourceFinalizers(finalizerName),
+			),
+			newSink(),
+			newReadyReceiveAdapter(newSource(WithRedisStreamSourceGroup(testGroup)), testGroup),
+		},
+		Key: testNS + "/" + sourceName,
+		OtherTestData: map[string]interface{}{
+			"groupManager": &fakeGroupManager{},
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, reconciledSuccessReason, `RedisStreamSource reconciled: "%s/%s"`, testNS, sourceName),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: newSource(
+				WithRedisStreamSourceGroup(testGroup),
+				WithRedisStreamSourceFinalizers(finalizerName),
+				WithInitRedisStreamSourceConditions,
+				WithRedisStreamSourceStatusObservedGeneration(generation),
+				WithRedisStreamSourceSinkURI(sinkURI),
+				WithRedisStreamSourceStatusGroup(testGroup),
+				WithRedisStreamSourceStatefulSetReady,
+			),
+		}},
+		PostConditions: []func(*testing.T, *TableRow){
+			groupsCreated(),
+			groupsDestroyed(),
+		},
+	}, {
+		Name: "group set in spec after the generated one, generated group destroyed",
+		Objects: []runtime.Object{
+			newSource(
+				WithRedisStreamSourceGroup(testGroup),
+				WithRedisStreamSourceFinalizers(finalizerName),
+				WithRedisStreamSourceStatusGroup(generatedGroup),
+			),
+			newSink(),
+			newReadyReceiveAdapter(newSource(), generatedGroup),
+		},
+		Key: testNS + "/" + sourceName,
+		OtherTestData: map[string]interface{}{
+			"groupManager": &fakeGroupManager{},
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, statefulSetUpdated, "Updated statefulset %s/cre-%s-redisstream", testNS, sourceName),
+			Eventf(corev1.EventTypeNormal, reconciledSuccessReason, `RedisStreamSource reconciled: "%s/%s"`, testNS, sourceName),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: func() runtime.Object {
+				ss := newReadyReceiveAdapter(newSource(), generatedGroup)
+				ss.Spec = newReceiveAdapter(newSource(WithRedisStreamSourceGroup(testGroup)), testGroup).Spec
+				return ss
+			}(),
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: newSource(
+				WithRedisStreamSourceGroup(testGroup),
+				WithRedisStreamSourceFinalizers(finalizerName),
+				WithInitRedisStreamSourceConditions,
+				WithRedisStreamSourceStatusObservedGeneration(generation),
+				WithRedisStreamSourceSinkURI(sinkURI),
+				WithRedisStreamSourceStatusGroup(testGroup),
+				WithRedisStreamSourceStatefulSetReady,
+			),
+		}},
+		PostConditions: []func(*testing.T, *TableRow){
+			groupsCreated(),
+			groupsDestroyed(testStream + "/" + generatedGroup),
+		},
+	}, {
+		Name: "group creation fails",
+		Objects: []runtime.Object{
+			newSource(WithRedisStreamSourceFinalizers(finalizerName)),
+			newSink(),
+		},
+		Key: testNS + "/" + sourceName,
+		OtherTestData: map[string]interface{}{
+			"groupManager": &fakeGroupManager{createErr: groupErr},
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, reconciledGroupFailedReason, "Failed to reconcile consumer group: %s", groupErr),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: newSource(
+				WithRedisStreamSourceFinalizers(finalizerName),
+				WithInitRedisStreamSourceConditions,
+				WithRedisStreamSourceStatusObservedGeneration(generation),
+				WithRedisStreamSourceSinkURI(sinkURI),
+			),
+		}},
+	}, {
+		Name: "receive adapter not owned by the source",
+		Objects: []runtime.Object{
+			newSource(
+				WithRedisStreamSourceGroup(testGroup),
+				WithRedisStreamSourceFinalizers(finalizerName),
+			),
+			newSink(),
+			func() runtime.Object {
+				ss := newReadyReceiveAdapter(newSource(WithRedisStreamSourceGroup(testGroup)), testGroup)
+				ss.OwnerReferences = nil
+				return ss
+			}(),
+		},
+		Key: testNS + "/" + sourceName,
+		OtherTestData: map[string]interface{}{
+			"groupManager": &fakeGroupManager{},
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, reconciledStatefulSetFailed,
+				`Failed to reconcile receive adapter: statefulset %s/cre-%s-redisstream is not owned by RedisStreamSource %q`, testNS, sourceName, sourceName),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: newSource(
+				WithRedisStreamSourceGroup(testGroup),
+				WithRedisStreamSourceFinalizers(finalizerName),
+				WithInitRedisStreamSourceConditions,
+				WithRedisStreamSourceStatusObservedGeneration(generation),
+				WithRedisStreamSourceSinkURI(sinkURI),
+				WithRedisStreamSourceStatusGroup(testGroup),
+			),
+		}},
+	}, {
+		Name: "source deleted, generated group destroyed",
+		Objects: []runtime.Object{
+			newSource(
+				WithRedisStreamSourceFinalizers(finalizerName),
+				WithRedisStreamSourceStatusGroup(generatedGroup),
+				WithRedisStreamSourceDeletionTimestamp,
+			),
+		},
+		Key: testNS + "/" + sourceName,
+		OtherTestData: map[string]interface{}{
+			"groupManager": &fakeGroupManager{},
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
+			Eventf(corev1.EventTypeNormal, finalizedSuccessReason, `RedisStreamSource finalized: "%s/%s"`, testNS, sourceName),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName, false),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: newSource(
+				WithRedisStreamSourceDeletionTimestamp,
+			),
+		}},
+		PostConditions: []func(*testing.T, *TableRow){
+			groupsDestroyed(testStream + "/" + generatedGroup),
+		},
+	}, {
+		Name: "source deleted, group set in spec isn't destroyed",
+		Objects: []runtime.Object{
+			newSource(
+				WithRedisStreamSourceGroup(testGroup),
+				WithRedisStreamSourceFinalizers(finalizerName),
+				WithRedisStreamSourceStatusGroup(testGroup),
+				WithRedisStreamSourceDeletionTimestamp,
+			),
+		},
+		Key: testNS + "/" + sourceName,
+		OtherTestData: map[string]interface{}{
+			"groupManager": &fakeGroupManager{},
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
+			Eventf(corev1.EventTypeNormal, finalizedSuccessReason, `RedisStreamSource finalized: "%s/%s"`, testNS, sourceName),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName, false),
+		},
+		PostConditions: []func(*testing.T, *TableRow){
+			groupsDestroyed(),
+		},
+	}, {
+		Name: "source deleted, group deletion fails",
+		Objects: []runtime.Object{
+			newSource(
+				WithRedisStreamSourceFinalizers(finalizerName),
+				WithRedisStreamSourceStatusGroup(generatedGroup),
+				WithRedisStreamSourceDeletionTimestamp,
+			),
+		},
+		Key: testNS + "/" + sourceName,
+		OtherTestData: map[string]interface{}{
+			"groupManager": &fakeGroupManager{destroyErr: groupErr},
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, deleteGroupFailed, "Failed to delete consumer group: %s", groupErr),
+		},
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher, testData map[string]interface{}) controller.Reconciler {
+		gm, _ := testData["groupManager"].(*fakeGroupManager)
+		r := &Reconciler{
+			Base:                reconciler.NewBase(ctx, controllerAgentName, cmw),
+			statefulSetLister:   listers.GetStatefulSetLister(),
+			secretLister:        listers.GetSecretLister(),
+			sinkResolver:        resolver.NewURIResolver(ctx, func(types.NamespacedName) {}),
+			receiveAdapterImage: testImage,
+			createGroupManagerFn: func(context.Context, *v1alpha1.RedisStreamSource) (GroupManager, error) {
+				return gm, nil
+			},
+		}
+		return redisstreamsource.NewReconciler(ctx, r.Logger, r.RunClientSet, listers.GetRedisStreamSourceLister(), r.Recorder, r)
+	}))
+}
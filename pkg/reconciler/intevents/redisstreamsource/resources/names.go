@@ -0,0 +1,43 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	"knative.dev/pkg/kmeta"
+
+	"github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+)
+
+// GenerateReceiveAdapterName generates the name of the receive adapter
+// StatefulSet of the source.
+func GenerateReceiveAdapterName(source *v1alpha1.RedisStreamSource) string {
+	if strings.HasPrefix(source.Name, "cre-") {
+		return kmeta.ChildName(source.Name, "-redisstream")
+	}
+	return kmeta.ChildName(fmt.Sprintf("cre-%s", source.Name), "-redisstream")
+}
+
+// GenerateGroupName generates the name of the consumer group automatically
+// created for a source that doesn't specify one. It is derived from the UID
+// so that it is stable and doesn't collide with the group of a source that
+// is recreated with the same name.
+func GenerateGroupName(source *v1alpha1.RedisStreamSource) string {
+	return fmt.Sprintf("cre-src_%s_%s_%s", source.Namespace, source.Name, string(source.UID))
+}
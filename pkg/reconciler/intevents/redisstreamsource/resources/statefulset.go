@@ -0,0 +1,190 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"strconv"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/logging"
+
+	"github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+	"github.com/google/knative-gcp/pkg/utils"
+)
+
+// DefaultPasswordKey is the key of the password in the secret referenced by
+// the dial options when the reference doesn't set a field path.
+const DefaultPasswordKey = "password"
+
+// ReceiveAdapterArgs are the arguments needed to create a RedisStreamSource
+// receive adapter. Every field is required.
+type ReceiveAdapterArgs struct {
+	Image         string
+	Source        *v1alpha1.RedisStreamSource
+	Labels        map[string]string
+	Group         string
+	SinkURI       *apis.URL
+	MetricsConfig string
+	LoggingConfig string
+}
+
+// PasswordSecretKeySelector returns the selector of the secret holding the
+// password to connect to Redis, or nil if the connection doesn't set one. The
+// password reference names a secret in the namespace of the object holding
+// the connection, and its field path, if set, is the key of the password in
+// the secret.
+func PasswordSecretKeySelector(conn *v1alpha1.RedisConnection) *corev1.SecretKeySelector {
+	opts := conn.Options
+	if opts == nil || opts.Password.Name == "" {
+		return nil
+	}
+	key := opts.Password.FieldPath
+	if key == "" {
+		key = DefaultPasswordKey
+	}
+	return &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: opts.Password.Name},
+		Key:                  key,
+	}
+}
+
+func makeReceiveAdapterPodSpec(ctx context.Context, args *ReceiveAdapterArgs) *corev1.PodSpec {
+	// Convert CloudEvent Overrides to pod embeddable properties.
+	ceExtensions := ""
+	if args.Source.Spec.CloudEventOverrides != nil && args.Source.Spec.CloudEventOverrides.Extensions != nil {
+		var err error
+		ceExtensions, err = utils.MapToBase64(args.Source.Spec.CloudEventOverrides.Extensions)
+		if err != nil {
+			logging.FromContext(ctx).Warnw("failed to make cloudevents overrides extensions",
+				zap.Error(err),
+				zap.Any("extensions", args.Source.Spec.CloudEventOverrides.Extensions))
+		}
+	}
+
+	container := corev1.Container{
+		Name:  "receive-adapter",
+		Image: args.Image,
+		Env: []corev1.EnvVar{{
+			Name:  "SINK_URI",
+			Value: args.SinkURI.String(),
+		}, {
+			Name:  "REDIS_ADDRESS",
+			Value: args.Source.Spec.Address,
+		}, {
+			Name:  "REDIS_STREAM",
+			Value: args.Source.Spec.Stream,
+		}, {
+			Name:  "REDIS_GROUP",
+			Value: args.Group,
+		}, {
+			Name:  "REDIS_MAX_DELIVERIES",
+			Value: strconv.Itoa(int(args.Source.Spec.MaxDeliveries)),
+		}, {
+			Name:  "REDIS_DEAD_LETTER_STREAM",
+			Value: args.Source.Spec.DeadLetterStream,
+		}, {
+			Name: "POD_NAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+			},
+		}, {
+			Name:  "K_CE_EXTENSIONS",
+			Value: ceExtensions,
+		}, {
+			Name:  "K_METRICS_CONFIG",
+			Value: args.MetricsConfig,
+		}, {
+			Name:  "K_LOGGING_CONFIG",
+			Value: args.LoggingConfig,
+		}},
+	}
+
+	if password := PasswordSecretKeySelector(&args.Source.Spec.RedisConnection); password != nil {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:      "REDIS_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: password},
+		})
+	}
+
+	if opts := args.Source.Spec.Options; opts != nil && opts.UseTLS {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  "REDIS_TLS_ENABLED",
+			Value: "true",
+		})
+		if opts.SkipVerify {
+			container.Env = append(container.Env, corev1.EnvVar{
+				Name:  "REDIS_TLS_SKIP_VERIFY",
+				Value: "true",
+			})
+		}
+		container.Env = appendSecretEnv(container.Env, "REDIS_TLS_CERT", opts.Cert)
+		container.Env = appendSecretEnv(container.Env, "REDIS_TLS_KEY", opts.Key)
+		container.Env = appendSecretEnv(container.Env, "REDIS_TLS_CA_CERTIFICATE", opts.CACert)
+	}
+
+	return &corev1.PodSpec{
+		Containers: []corev1.Container{container},
+	}
+}
+
+func appendSecretEnv(env []corev1.EnvVar, name string, value v1alpha1.RedisSecretValueFromSource) []corev1.EnvVar {
+	if value.SecretKeyRef == nil {
+		return env
+	}
+	return append(env, corev1.EnvVar{
+		Name:      name,
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: value.SecretKeyRef},
+	})
+}
+
+// MakeReceiveAdapter generates (but does not insert into K8s) the receive
+// adapter StatefulSet for RedisStreamSources. A StatefulSet is used so that
+// the pods, whose names are the consumer names in the group, keep their
+// names and hence their pending entries across restarts.
+func MakeReceiveAdapter(ctx context.Context, args *ReceiveAdapterArgs) *appsv1.StatefulSet {
+	podSpec := makeReceiveAdapterPodSpec(ctx, args)
+	replicas := int32(1)
+	name := GenerateReceiveAdapterName(args.Source)
+
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       args.Source.Namespace,
+			Name:            name,
+			Labels:          args.Labels,
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(args.Source)},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			ServiceName: name,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: args.Labels,
+			},
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: args.Labels,
+				},
+				Spec: *podSpec,
+			},
+		},
+	}
+}
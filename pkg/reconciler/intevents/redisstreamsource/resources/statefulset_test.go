@@ -0,0 +1,195 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+)
+
+func TestMakeMinimumReceiveAdapter(t *testing.T) {
+	source := &v1alpha1.RedisStreamSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testname",
+			Namespace: "testnamespace",
+		},
+		Spec: v1alpha1.RedisStreamSourceSpec{
+			RedisConnection: v1alpha1.RedisConnection{
+				Address: "redis:6379",
+			},
+			Stream:        "mystream",
+			MaxDeliveries: 10,
+		},
+	}
+
+	got := MakeReceiveAdapter(context.Background(), &ReceiveAdapterArgs{
+		Image:  "test-image",
+		Source: source,
+		Labels: map[string]string{
+			"test-key1": "test-value1",
+		},
+		Group:         "mygroup",
+		SinkURI:       apis.HTTP("sink-uri"),
+		LoggingConfig: "LoggingConfig-ABC123",
+		MetricsConfig: "MetricsConfig-ABC123",
+	})
+
+	one := int32(1)
+	yes := true
+	labels := map[string]string{
+		"test-key1": "test-value1",
+	}
+	want := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "testnamespace",
+			Name:      "cre-testname-redisstream",
+			Labels:    labels,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         "internal.events.cloud.google.com/v1alpha1",
+				Kind:               "RedisStreamSource",
+				Name:               "testname",
+				Controller:         &yes,
+				BlockOwnerDeletion: &yes,
+			}},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			ServiceName: "cre-testname-redisstream",
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Replicas: &one,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "receive-adapter",
+						Image: "test-image",
+						Env: []corev1.EnvVar{{
+							Name:  "SINK_URI",
+							Value: "http://sink-uri",
+						}, {
+							Name:  "REDIS_ADDRESS",
+							Value: "redis:6379",
+						}, {
+							Name:  "REDIS_STREAM",
+							Value: "mystream",
+						}, {
+							Name:  "REDIS_GROUP",
+							Value: "mygroup",
+						}, {
+							Name:  "REDIS_MAX_DELIVERIES",
+							Value: "10",
+						}, {
+							Name: "REDIS_DEAD_LETTER_STREAM",
+						}, {
+							Name: "POD_NAME",
+							ValueFrom: &corev1.EnvVarSource{
+								FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+							},
+						}, {
+							Name: "K_CE_EXTENSIONS",
+						}, {
+							Name:  "K_METRICS_CONFIG",
+							Value: "MetricsConfig-ABC123",
+						}, {
+							Name:  "K_LOGGING_CONFIG",
+							Value: "LoggingConfig-ABC123",
+						}},
+					}},
+				},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected statefulset (-want, +got) = %v", diff)
+	}
+}
+
+func TestMakeReceiveAdapterWithOptions(t *testing.T) {
+	caCert := &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "tls"},
+		Key:                  "ca.crt",
+	}
+	source := &v1alpha1.RedisStreamSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testname",
+			Namespace: "testnamespace",
+		},
+		Spec: v1alpha1.RedisStreamSourceSpec{
+			SourceSpec: duckv1.SourceSpec{
+				CloudEventOverrides: &duckv1.CloudEventOverrides{
+					Extensions: map[string]string{"foo": "bar"},
+				},
+			},
+			RedisConnection: v1alpha1.RedisConnection{
+				Address: "redis:6379",
+				Options: &v1alpha1.RedisConnectionOptions{
+					Password: corev1.ObjectReference{Name: "redis-secret"},
+					UseTLS:   true,
+					CACert:   v1alpha1.RedisSecretValueFromSource{SecretKeyRef: caCert},
+				},
+			},
+			Stream: "mystream",
+		},
+	}
+
+	got := MakeReceiveAdapter(context.Background(), &ReceiveAdapterArgs{
+		Image:   "test-image",
+		Source:  source,
+		Group:   "mygroup",
+		SinkURI: apis.HTTP("sink-uri"),
+	})
+
+	env := make(map[string]corev1.EnvVar)
+	for _, e := range got.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e
+	}
+	if got, want := env["K_CE_EXTENSIONS"].Value, "eyJmb28iOiJiYXIifQ=="; got != want {
+		t.Errorf("K_CE_EXTENSIONS = %q, want %q", got, want)
+	}
+	wantPassword := &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "redis-secret"},
+		Key:                  DefaultPasswordKey,
+	}}
+	if diff := cmp.Diff(wantPassword, env["REDIS_PASSWORD"].ValueFrom); diff != "" {
+		t.Errorf("unexpected REDIS_PASSWORD (-want, +got) = %v", diff)
+	}
+	if got := env["REDIS_TLS_ENABLED"].Value; got != "true" {
+		t.Errorf("REDIS_TLS_ENABLED = %q, want true", got)
+	}
+	if _, ok := env["REDIS_TLS_SKIP_VERIFY"]; ok {
+		t.Error("REDIS_TLS_SKIP_VERIFY is set, want unset")
+	}
+	if diff := cmp.Diff(&corev1.EnvVarSource{SecretKeyRef: caCert}, env["REDIS_TLS_CA_CERTIFICATE"].ValueFrom); diff != "" {
+		t.Errorf("unexpected REDIS_TLS_CA_CERTIFICATE (-want, +got) = %v", diff)
+	}
+	if _, ok := env["REDIS_TLS_CERT"]; ok {
+		t.Error("REDIS_TLS_CERT is set, want unset")
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/kmeta"
+
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
+)
+
+type EventTypeArgs struct {
+	Namespace string
+	// Name is the name of the source.
+	Name   string
+	Owner  kmeta.OwnerRefable
+	Broker string
+	// Source is the CloudEvent source of the events, if it is known in advance.
+	Source *apis.URL
+	Info   schemasv1.EventTypeInfo
+	Labels map[string]string
+}
+
+// MakeEventType creates the spec for, but does not create, the EventType of a
+// type of the events sent by a source to a Broker.
+func MakeEventType(args *EventTypeArgs) *eventingv1beta1.EventType {
+	et := &eventingv1beta1.EventType{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            GenerateEventTypeName(args.Name, args.Info.Type),
+			Namespace:       args.Namespace,
+			Labels:          args.Labels,
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(args.Owner)},
+		},
+		Spec: eventingv1beta1.EventTypeSpec{
+			Type:        args.Info.Type,
+			Source:      args.Source,
+			Broker:      args.Broker,
+			Description: args.Info.Description,
+		},
+	}
+	if args.Info.Schema != "" {
+		et.Spec.Schema, _ = apis.ParseURL(args.Info.Schema)
+	}
+	return et
+}
+
+// GenerateEventTypeName generates the name of the EventType of a type of the
+// events sent by the named source.
+func GenerateEventTypeName(source, eventType string) string {
+	return kmeta.ChildName(source+"-", strings.ToLower(eventType))
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/pkg/apis"
+
+	v1 "github.com/google/knative-gcp/pkg/apis/events/v1"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
+)
+
+func TestMakeEventType(t *testing.T) {
+	source := &v1.CloudStorageSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "storage-name",
+			Namespace: "storage-namespace",
+			UID:       "storage-uid",
+		},
+	}
+	info, _ := schemasv1.EventTypeInfoOf("CloudStorageSource", schemasv1.CloudStorageObjectMetadataUpdatedEventType)
+	got := MakeEventType(&EventTypeArgs{
+		Namespace: "storage-namespace",
+		Name:      "storage-name",
+		Owner:     source,
+		Broker:    "default",
+		Source:    apis.HTTP("storage.googleapis.com"),
+		Info:      info,
+		Labels:    GetLabels("receive-adapter-name", "storage-name"),
+	})
+
+	yes := true
+	want := &eventingv1beta1.EventType{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "storage-name-google.cloud.storage.object.v1.metadataupdated",
+			Namespace: "storage-namespace",
+			Labels: map[string]string{
+				"receive-adapter":                     "receive-adapter-name",
+				"events.cloud.google.com/source-name": "storage-name",
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         "events.cloud.google.com/v1",
+				Kind:               "CloudStorageSource",
+				Name:               "storage-name",
+				UID:                "storage-uid",
+				Controller:         &yes,
+				BlockOwnerDeletion: &yes,
+			}},
+		},
+		Spec: eventingv1beta1.EventTypeSpec{
+			Type:        schemasv1.CloudStorageObjectMetadataUpdatedEventType,
+			Source:      apis.HTTP("storage.googleapis.com"),
+			Schema:      &apis.URL{Scheme: "https", Host: "raw.githubusercontent.com", Path: "/googleapis/google-cloudevents/master/proto/google/events/cloud/storage/v1/data.proto"},
+			Broker:      "default",
+			Description: info.Description,
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected (-want, +got) = %v", diff)
+	}
+}
+
+func TestGenerateEventTypeName(t *testing.T) {
+	if got, want := GenerateEventTypeName("source", schemasv1.CloudPubSubMessagePublishedEventType), "source-google.cloud.pubsub.topic.v1.messagepublished"; got != want {
+		t.Errorf("GenerateEventTypeName got=%q, want=%q", got, want)
+	}
+	long := "a-very-long-source-name-that-does-not-leave-room-for-the-type"
+	if got := GenerateEventTypeName(long, schemasv1.CloudPubSubMessagePublishedEventType); len(got) > 63 {
+		t.Errorf("GenerateEventTypeName got %q, longer than 63 characters", got)
+	}
+}
@@ -20,12 +20,14 @@ import (
 	"context"
 	"fmt"
 
+	"cloud.google.com/go/pubsub"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	eventingduckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
 	"knative.dev/pkg/logging"
 	pkgreconciler "knative.dev/pkg/reconciler"
@@ -39,6 +41,7 @@ import (
 	"github.com/google/knative-gcp/pkg/reconciler"
 	"github.com/google/knative-gcp/pkg/reconciler/identity"
 	"github.com/google/knative-gcp/pkg/reconciler/messaging/channel/resources"
+	reconcilerutils "github.com/google/knative-gcp/pkg/reconciler/utils"
 )
 
 const (
@@ -50,6 +53,10 @@ const (
 	reconciledSubscribersFailedReason       = "SubscribersReconcileFailed"
 	reconciledSubscribersStatusFailedReason = "SubscribersStatusReconcileFailed"
 	workloadIdentityFailed                  = "WorkloadIdentityReconcileFailed"
+	reconciledDispatcherFailedReason        = "DispatcherReconcileFailed"
+	deleteDispatcherFailedReason            = "DispatcherDeleteFailed"
+	reconciledDeadLetterSinksFailedReason   = "DeadLetterSinksReconcileFailed"
+	deleteDeadLetterSinksFailedReason       = "DeadLetterSinksDeleteFailed"
 )
 
 // Reconciler implements controller.Reconciler for Channel resources.
@@ -60,6 +67,13 @@ type Reconciler struct {
 	// listers index properties about resources
 	channelLister listers.ChannelLister
 	topicLister   inteventslisters.TopicLister
+	podLister     corev1listers.PodLister
+
+	// cmRec reconciles the targets config of the shared dispatcher.
+	cmRec *reconcilerutils.ConfigMapReconciler
+
+	// newPubsubClient creates the Pub/Sub client managing the Pub/Sub resources of a channel.
+	newPubsubClient func(ctx context.Context, channel *v1beta1.Channel) (*pubsub.Client, error)
 }
 
 // Check that our Reconciler implements Interface.
@@ -87,14 +101,37 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, channel *v1beta1.Channel
 	channel.Status.PropagateTopicStatus(&topic.Status)
 	channel.Status.TopicID = topic.Spec.Topic
 
-	// 2. Sync all subscriptions.
+	// The events of channels with the shared dispatcher are delivered by the dispatcher rather
+	// than by PullSubscriptions.
+	if channel.IsSharedDispatcher() {
+		if !channel.Status.GetCondition(v1beta1.ChannelConditionTopicReady).IsTrue() {
+			// The channel is reconciled again once its topic becomes ready. Until then, the
+			// dispatcher has nothing to pull the events of the channel from.
+			return nil
+		}
+		if err := r.reconcileSharedDispatcher(ctx, channel); err != nil {
+			return pkgreconciler.NewEvent(corev1.EventTypeWarning, reconciledDispatcherFailedReason, "Reconcile shared dispatcher failed with: %s", err.Error())
+		}
+		if err := r.reconcileDispatcherConfig(ctx, channel); err != nil {
+			return pkgreconciler.NewEvent(corev1.EventTypeWarning, reconciledDispatcherFailedReason, "Reconcile shared dispatcher failed with: %s", err.Error())
+		}
+		return pkgreconciler.NewEvent(corev1.EventTypeNormal, reconciledSuccessReason, `Channel reconciled: "%s/%s"`, channel.Namespace, channel.Name)
+	}
+
+	// 2. Sync the dead letter topics and subscriptions of the subscribers with a dead letter sink,
+	// before the subscriptions forwarding the events to them.
+	if err := r.syncDeadLetterSinks(ctx, channel); err != nil {
+		return pkgreconciler.NewEvent(corev1.EventTypeWarning, reconciledDeadLetterSinksFailedReason, "Reconcile dead letter sinks failed with: %s", err.Error())
+	}
+
+	// 3. Sync all subscriptions.
 	//   a. create all subscriptions that are in spec and not in status.
 	//   b. delete all subscriptions that are in status but not in spec.
 	if err := r.syncSubscribers(ctx, channel); err != nil {
 		return pkgreconciler.NewEvent(corev1.EventTypeWarning, reconciledSubscribersFailedReason, "Reconcile Subscribers failed with: %s", err.Error())
 	}
 
-	// 3. Sync all subscriptions statuses.
+	// 4. Sync all subscriptions statuses.
 	if err := r.syncSubscribersStatus(ctx, channel); err != nil {
 		return pkgreconciler.NewEvent(corev1.EventTypeWarning, reconciledSubscribersStatusFailedReason, "Reconcile Subscribers Status failed with: %s", err.Error())
 	}
@@ -162,6 +199,7 @@ func (r *Reconciler) syncSubscribers(ctx context.Context, channel *v1beta1.Chann
 			Labels:             resources.GetPullSubscriptionLabels(controllerAgentName, channel.Name, genName, string(channel.UID)),
 			Annotations:        resources.GetPullSubscriptionAnnotations(channel.Name, clusterName),
 			Subscriber:         s,
+			DeadLetterTopic:    deadLetterTopicID(channel, s),
 		})
 		ps, err := r.RunClientSet.InternalV1beta1().PullSubscriptions(channel.Namespace).Create(ctx, ps, metav1.CreateOptions{})
 		if apierrs.IsAlreadyExists(err) {
@@ -197,6 +235,7 @@ func (r *Reconciler) syncSubscribers(ctx context.Context, channel *v1beta1.Chann
 			Labels:             resources.GetPullSubscriptionLabels(controllerAgentName, channel.Name, genName, string(channel.UID)),
 			Annotations:        resources.GetPullSubscriptionAnnotations(channel.Name, clusterName),
 			Subscriber:         s,
+			DeadLetterTopic:    deadLetterTopicID(channel, s),
 		})
 
 		existingPs, found := pullsubs[genName]
@@ -260,19 +299,40 @@ func (r *Reconciler) syncSubscribersStatus(ctx context.Context, channel *v1beta1
 		channel.Status.SubscribableStatus.Subscribers = make([]eventingduckv1beta1.SubscriberStatus, 0)
 	}
 
-	// Make a map of subscriber name to PullSubscription for lookup.
+	// Make maps of subscriber name to PullSubscription and dead letter PullSubscription for lookup.
 	pullsubs := make(map[string]inteventsv1beta1.PullSubscription)
+	dlsubs := make(map[string]inteventsv1beta1.PullSubscription)
 	if subs, err := r.getPullSubscriptions(ctx, channel); err != nil {
 		logging.FromContext(ctx).Desugar().Error("Failed to list PullSubscriptions", zap.Error(err))
 	} else {
 		for _, s := range subs {
-			pullsubs[resources.ExtractUIDFromPullSubscriptionName(s.Name)] = s
+			if resources.IsDeadLetterPullSubscriptionName(s.Name) {
+				dlsubs[resources.ExtractUIDFromDeadLetterPullSubscriptionName(s.Name)] = s
+			} else {
+				pullsubs[resources.ExtractUIDFromPullSubscriptionName(s.Name)] = s
+			}
+		}
+	}
+
+	// Make a set of the subscribers with a dead letter sink.
+	deadLettered := make(map[types.UID]bool)
+	if channel.Spec.SubscribableSpec != nil {
+		for _, s := range channel.Spec.SubscribableSpec.Subscribers {
+			deadLettered[s.UID] = resources.HasDeadLetterSink(s)
 		}
 	}
 
 	for i, ss := range channel.Status.SubscribableStatus.Subscribers {
 		if ps, ok := pullsubs[string(ss.UID)]; ok {
 			ready, msg := r.getPullSubscriptionStatus(&ps)
+			if ready == corev1.ConditionTrue && deadLettered[ss.UID] {
+				if dls, ok := dlsubs[string(ss.UID)]; ok {
+					ready, msg = r.getPullSubscriptionStatus(&dls)
+				} else {
+					ready = corev1.ConditionFalse
+					msg = fmt.Sprintf("Dead letter PullSubscription %s is not created", resources.GenerateDeadLetterPullSubscriptionName(ss.UID))
+				}
+			}
 			channel.Status.SubscribableStatus.Subscribers[i].Ready = ready
 			channel.Status.SubscribableStatus.Subscribers[i].Message = msg
 		} else {
@@ -380,6 +440,17 @@ func (r *Reconciler) getPullSubscriptionStatus(ps *inteventsv1beta1.PullSubscrip
 }
 
 func (r *Reconciler) FinalizeKind(ctx context.Context, channel *v1beta1.Channel) pkgreconciler.Event {
+	if channel.IsSharedDispatcher() {
+		if err := r.reconcileDispatcherConfig(ctx, channel); err != nil {
+			return pkgreconciler.NewEvent(corev1.EventTypeWarning, deleteDispatcherFailedReason, "Failed to delete shared dispatcher: %s", err.Error())
+		}
+		if err := r.deleteSharedDispatcher(ctx, channel); err != nil {
+			return pkgreconciler.NewEvent(corev1.EventTypeWarning, deleteDispatcherFailedReason, "Failed to delete shared dispatcher: %s", err.Error())
+		}
+	} else if err := r.deleteDeadLetterTopics(ctx, channel); err != nil {
+		return pkgreconciler.NewEvent(corev1.EventTypeWarning, deleteDeadLetterSinksFailedReason, "Failed to delete dead letter sinks: %s", err.Error())
+	}
+
 	// If k8s ServiceAccount exists, binds to the default GCP ServiceAccount, and it only has one ownerReference,
 	// remove the corresponding GCP ServiceAccount iam policy binding.
 	// No need to delete k8s ServiceAccount, it will be automatically handled by k8s Garbage Collection.
@@ -226,6 +226,48 @@ func TestAllCases(t *testing.T) {
 		WantPatches: []clientgotesting.PatchActionImpl{
 			patchFinalizers(testNS, channelName, true),
 		},
+	}, {
+		Name: "shared dispatcher, the status of topic is false",
+		Objects: []runtime.Object{
+			NewChannel(channelName, testNS,
+				WithChannelUID(channelUID),
+				WithChannelAnnotations(map[string]string{
+					v1beta1.DispatcherAnnotationKey: v1beta1.DispatcherShared,
+				}),
+				WithChannelSpec(v1beta1.ChannelSpec{
+					Project: testProject,
+				}),
+				WithInitChannelConditions,
+				WithChannelSetDefaults,
+			),
+			newFalseTopic(),
+		},
+		Key: testNS + "/" + channelName,
+		// Neither the Pub/Sub resources of the dispatcher nor its targets config are
+		// reconciled until the topic is ready.
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", channelName),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: NewChannel(channelName, testNS,
+				WithChannelUID(channelUID),
+				WithChannelAnnotations(map[string]string{
+					v1beta1.DispatcherAnnotationKey: v1beta1.DispatcherShared,
+				}),
+				WithChannelSpec(v1beta1.ChannelSpec{
+					Project: testProject,
+				}),
+				WithInitChannelConditions,
+				WithChannelSetDefaults,
+				WithChannelTopic(testTopicID),
+				// Updates
+				WithChannelAddress(topicURI),
+				WithChannelTopicFailed("PublisherStatus", "Publisher has no Ready type status"),
+			),
+		}},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, channelName, true),
+		},
 	},
 		{
 			Name: "new subscriber",
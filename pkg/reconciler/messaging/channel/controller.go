@@ -34,6 +34,9 @@ import (
 	"github.com/google/knative-gcp/pkg/reconciler"
 	"github.com/google/knative-gcp/pkg/reconciler/identity"
 	"github.com/google/knative-gcp/pkg/reconciler/identity/iam"
+	reconcilerutils "github.com/google/knative-gcp/pkg/reconciler/utils"
+	configmapinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/configmap"
+	podinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/pod"
 	serviceaccountinformers "knative.dev/pkg/client/injection/kube/informers/core/v1/serviceaccount"
 )
 
@@ -67,11 +70,19 @@ func newController(
 	pullSubscriptionInformer := pullsubscriptioninformer.Get(ctx)
 	serviceAccountInformer := serviceaccountinformers.Get(ctx)
 
+	base := reconciler.NewBase(ctx, controllerAgentName, cmw)
 	r := &Reconciler{
-		Base:          reconciler.NewBase(ctx, controllerAgentName, cmw),
+		Base:          base,
 		Identity:      identity.NewIdentity(ctx, ipm, gcpas),
 		channelLister: channelInformer.Lister(),
 		topicLister:   topicInformer.Lister(),
+		podLister:     podinformer.Get(ctx).Lister(),
+		cmRec: &reconcilerutils.ConfigMapReconciler{
+			KubeClient: base.KubeClientSet,
+			Lister:     configmapinformer.Get(ctx).Lister(),
+			Recorder:   base.Recorder,
+		},
+		newPubsubClient: newPubsubClient,
 	}
 	impl := channelreconciler.NewImpl(ctx, r)
 
@@ -0,0 +1,178 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channel
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/pubsub"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	eventingduckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
+	"knative.dev/pkg/logging"
+
+	"github.com/google/knative-gcp/pkg/apis/duck"
+	inteventsv1beta1 "github.com/google/knative-gcp/pkg/apis/intevents/v1beta1"
+	"github.com/google/knative-gcp/pkg/apis/messaging/v1beta1"
+	"github.com/google/knative-gcp/pkg/reconciler/messaging/channel/resources"
+	reconcilerutilspubsub "github.com/google/knative-gcp/pkg/reconciler/utils/pubsub"
+)
+
+// syncDeadLetterSinks reconciles the Pub/Sub dead letter topics of the subscribers with a dead
+// letter sink, and the PullSubscriptions delivering their events to the dead letter sinks. The
+// dead letter topics and PullSubscriptions of the other subscribers are deleted.
+func (r *Reconciler) syncDeadLetterSinks(ctx context.Context, channel *v1beta1.Channel) error {
+	want := make(map[types.UID]eventingduckv1beta1.SubscriberSpec)
+	if channel.Spec.SubscribableSpec != nil {
+		for _, s := range channel.Spec.SubscribableSpec.Subscribers {
+			if resources.HasDeadLetterSink(s) {
+				want[s.UID] = s
+			}
+		}
+	}
+	existing, err := r.getDeadLetterPullSubscriptions(ctx, channel)
+	if err != nil {
+		return err
+	}
+	if len(want) == 0 && len(existing) == 0 {
+		return nil
+	}
+
+	client, err := r.newPubsubClient(ctx, channel)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	psr := reconcilerutilspubsub.NewReconciler(client, r.Recorder)
+	pubsubLabels := map[string]string{
+		"resource":  "channels",
+		"namespace": channel.Namespace,
+		"name":      channel.Name,
+	}
+
+	for uid, s := range want {
+		if _, err := psr.ReconcileTopic(ctx, resources.GenerateDeadLetterTopicID(channel, uid), &pubsub.TopicConfig{Labels: pubsubLabels}, channel, discardStatus{}); err != nil {
+			return fmt.Errorf("failed to reconcile the dead letter topic of subscriber %q: %w", uid, err)
+		}
+		if err := r.reconcileDeadLetterPullSubscription(ctx, channel, s, existing); err != nil {
+			return err
+		}
+	}
+
+	for name := range existing {
+		uid := types.UID(resources.ExtractUIDFromDeadLetterPullSubscriptionName(name))
+		if _, ok := want[uid]; ok {
+			continue
+		}
+		if err := psr.DeleteTopic(ctx, resources.GenerateDeadLetterTopicID(channel, uid), channel, discardStatus{}); err != nil {
+			return fmt.Errorf("failed to delete the dead letter topic of subscriber %q: %w", uid, err)
+		}
+		if err := r.RunClientSet.InternalV1beta1().PullSubscriptions(channel.Namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+			logging.FromContext(ctx).Desugar().Error("unable to delete dead letter PullSubscription for Channel", zap.String("ps", name), zap.String("channel", channel.Name), zap.Error(err))
+			r.Recorder.Eventf(channel, corev1.EventTypeWarning, "DeadLetterSubscriberDeleteFailed", "Deleting dead letter Subscriber %q failed", name)
+			return err
+		}
+		r.Recorder.Eventf(channel, corev1.EventTypeNormal, "DeadLetterSubscriberDeleted", "Deleted dead letter Subscriber %q", name)
+	}
+	return nil
+}
+
+func (r *Reconciler) reconcileDeadLetterPullSubscription(ctx context.Context, channel *v1beta1.Channel, s eventingduckv1beta1.SubscriberSpec, existing map[string]inteventsv1beta1.PullSubscription) error {
+	genName := resources.GenerateDeadLetterPullSubscriptionName(s.UID)
+	ps := resources.MakeDeadLetterPullSubscription(&resources.PullSubscriptionArgs{
+		Owner:              channel,
+		Name:               genName,
+		Project:            channel.Spec.Project,
+		Topic:              resources.GenerateDeadLetterTopicID(channel, s.UID),
+		ServiceAccountName: channel.Spec.ServiceAccountName,
+		Secret:             channel.Spec.Secret,
+		Labels:             resources.GetPullSubscriptionLabels(controllerAgentName, channel.Name, genName, string(channel.UID)),
+		Annotations:        resources.GetPullSubscriptionAnnotations(channel.Name, channel.GetAnnotations()[duck.ClusterNameAnnotation]),
+		Subscriber:         s,
+	})
+
+	existingPs, found := existing[genName]
+	if !found {
+		ps, err := r.RunClientSet.InternalV1beta1().PullSubscriptions(channel.Namespace).Create(ctx, ps, metav1.CreateOptions{})
+		if err != nil {
+			r.Recorder.Eventf(channel, corev1.EventTypeWarning, "DeadLetterSubscriberCreateFailed", "Creating dead letter Subscriber %q failed", genName)
+			return err
+		}
+		r.Recorder.Eventf(channel, corev1.EventTypeNormal, "DeadLetterSubscriberCreated", "Created dead letter Subscriber %q", ps.Name)
+	} else if !equality.Semantic.DeepEqual(ps.Spec, existingPs.Spec) {
+		// Don't modify the informers copy.
+		desired := existingPs.DeepCopy()
+		desired.Spec = ps.Spec
+		ps, err := r.RunClientSet.InternalV1beta1().PullSubscriptions(channel.Namespace).Update(ctx, desired, metav1.UpdateOptions{})
+		if err != nil {
+			r.Recorder.Eventf(channel, corev1.EventTypeWarning, "DeadLetterSubscriberUpdateFailed", "Updating dead letter Subscriber %q failed", genName)
+			return err
+		}
+		r.Recorder.Eventf(channel, corev1.EventTypeNormal, "DeadLetterSubscriberUpdated", "Updated dead letter Subscriber %q", ps.Name)
+	}
+	return nil
+}
+
+// deleteDeadLetterTopics deletes the Pub/Sub dead letter topics of the subscribers of the
+// channel. Their PullSubscriptions are garbage collected with the channel.
+func (r *Reconciler) deleteDeadLetterTopics(ctx context.Context, channel *v1beta1.Channel) error {
+	existing, err := r.getDeadLetterPullSubscriptions(ctx, channel)
+	if err != nil || len(existing) == 0 {
+		return err
+	}
+	client, err := r.newPubsubClient(ctx, channel)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	psr := reconcilerutilspubsub.NewReconciler(client, r.Recorder)
+	for name := range existing {
+		uid := types.UID(resources.ExtractUIDFromDeadLetterPullSubscriptionName(name))
+		if err := psr.DeleteTopic(ctx, resources.GenerateDeadLetterTopicID(channel, uid), channel, discardStatus{}); err != nil {
+			return fmt.Errorf("failed to delete the dead letter topic of subscriber %q: %w", uid, err)
+		}
+	}
+	return nil
+}
+
+// getDeadLetterPullSubscriptions returns the dead letter PullSubscriptions of the channel by name.
+func (r *Reconciler) getDeadLetterPullSubscriptions(ctx context.Context, channel *v1beta1.Channel) (map[string]inteventsv1beta1.PullSubscription, error) {
+	subs, err := r.getPullSubscriptions(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+	pullsubs := make(map[string]inteventsv1beta1.PullSubscription)
+	for _, s := range subs {
+		if resources.IsDeadLetterPullSubscriptionName(s.Name) {
+			pullsubs[s.Name] = s
+		}
+	}
+	return pullsubs, nil
+}
+
+// deadLetterTopicID returns the Pub/Sub dead letter topic of the subscriber, if it has a dead
+// letter sink.
+func deadLetterTopicID(channel *v1beta1.Channel, s eventingduckv1beta1.SubscriberSpec) string {
+	if !resources.HasDeadLetterSink(s) {
+		return ""
+	}
+	return resources.GenerateDeadLetterTopicID(channel, s.UID)
+}
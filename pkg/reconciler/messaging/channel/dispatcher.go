@@ -0,0 +1,241 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/rickb777/date/period"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	eventingduckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/system"
+
+	"github.com/google/knative-gcp/pkg/apis/messaging/v1beta1"
+	"github.com/google/knative-gcp/pkg/broker/config/memory"
+	metadataClient "github.com/google/knative-gcp/pkg/gclient/metadata"
+	"github.com/google/knative-gcp/pkg/reconciler/messaging/channel/resources"
+	reconcilerutilspubsub "github.com/google/knative-gcp/pkg/reconciler/utils/pubsub"
+	"github.com/google/knative-gcp/pkg/reconciler/utils/volume"
+	"github.com/google/knative-gcp/pkg/utils"
+)
+
+// defaultMaximumBackoff is the maximum backoff of the retry queues of the subscribers with an
+// exponential backoff policy.
+const defaultMaximumBackoff = 600 * time.Second
+
+// reconcileSharedDispatcher reconciles the Pub/Sub subscription the shared dispatcher pulls the
+// events of the channel from, and the Pub/Sub retry queues of the channel subscribers. The
+// statuses of the subscribers are then set from their retry queues.
+func (r *Reconciler) reconcileSharedDispatcher(ctx context.Context, channel *v1beta1.Channel) error {
+	if channel.Status.SubscribableStatus.Subscribers == nil {
+		channel.Status.SubscribableStatus.Subscribers = make([]eventingduckv1beta1.SubscriberStatus, 0)
+	}
+
+	client, err := r.newPubsubClient(ctx, channel)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	psr := reconcilerutilspubsub.NewReconciler(client, r.Recorder)
+	pubsubLabels := map[string]string{
+		"resource":  "channels",
+		"namespace": channel.Namespace,
+		"name":      channel.Name,
+	}
+
+	subID := resources.GenerateDispatcherSubscriptionID(channel)
+	if _, err := psr.ReconcileSubscription(ctx, subID, pubsub.SubscriptionConfig{
+		Topic:  client.Topic(channel.Status.TopicID),
+		Labels: pubsubLabels,
+	}, channel, discardStatus{}); err != nil {
+		return fmt.Errorf("failed to reconcile the dispatcher subscription: %w", err)
+	}
+
+	var want []eventingduckv1beta1.SubscriberSpec
+	if channel.Spec.SubscribableSpec != nil {
+		want = channel.Spec.SubscribableSpec.Subscribers
+	}
+	wanted := make(map[types.UID]bool, len(want))
+	statuses := make([]eventingduckv1beta1.SubscriberStatus, 0, len(want))
+	for _, s := range want {
+		wanted[s.UID] = true
+		status := eventingduckv1beta1.SubscriberStatus{
+			UID:                s.UID,
+			ObservedGeneration: s.Generation,
+			Ready:              corev1.ConditionTrue,
+		}
+		if err := r.reconcileRetryQueue(ctx, psr, channel, s, pubsubLabels); err != nil {
+			logging.FromContext(ctx).Error("Failed to reconcile the subscriber retry queue", zap.String("uid", string(s.UID)), zap.Error(err))
+			status.Ready = corev1.ConditionFalse
+			status.Message = fmt.Sprintf("Failed to reconcile the retry queue: %v", err)
+		}
+		statuses = append(statuses, status)
+	}
+
+	// Delete the retry queues of the removed subscribers, keeping the subscribers whose retry
+	// queue fails to be deleted so that it is attempted again.
+	var deleteErr error
+	for _, ss := range channel.Status.SubscribableStatus.Subscribers {
+		if wanted[ss.UID] {
+			continue
+		}
+		if err := r.deleteRetryQueue(ctx, psr, channel, ss.UID); err != nil {
+			logging.FromContext(ctx).Error("Failed to delete the subscriber retry queue", zap.String("uid", string(ss.UID)), zap.Error(err))
+			ss.Ready = corev1.ConditionFalse
+			ss.Message = fmt.Sprintf("Failed to delete the retry queue: %v", err)
+			statuses = append(statuses, ss)
+			deleteErr = err
+		}
+	}
+	channel.Status.SubscribableStatus.Subscribers = statuses
+	return deleteErr
+}
+
+func (r *Reconciler) reconcileRetryQueue(ctx context.Context, psr *reconcilerutilspubsub.Reconciler, channel *v1beta1.Channel, s eventingduckv1beta1.SubscriberSpec, pubsubLabels map[string]string) error {
+	topic, err := psr.ReconcileTopic(ctx, resources.GenerateRetryTopicID(channel, s.UID), &pubsub.TopicConfig{Labels: pubsubLabels}, channel, discardStatus{})
+	if err != nil {
+		return err
+	}
+	_, err = psr.ReconcileSubscription(ctx, resources.GenerateRetrySubscriptionID(channel, s.UID), pubsub.SubscriptionConfig{
+		Topic:       topic,
+		Labels:      pubsubLabels,
+		RetryPolicy: pubsubRetryPolicy(s.Delivery),
+	}, channel, discardStatus{})
+	return err
+}
+
+func (r *Reconciler) deleteRetryQueue(ctx context.Context, psr *reconcilerutilspubsub.Reconciler, channel *v1beta1.Channel, uid types.UID) error {
+	if err := psr.DeleteTopic(ctx, resources.GenerateRetryTopicID(channel, uid), channel, discardStatus{}); err != nil {
+		return err
+	}
+	return psr.DeleteSubscription(ctx, resources.GenerateRetrySubscriptionID(channel, uid), channel, discardStatus{})
+}
+
+// deleteSharedDispatcher deletes the Pub/Sub subscription the shared dispatcher pulls the events
+// of the channel from, and the Pub/Sub retry queues of the channel subscribers.
+func (r *Reconciler) deleteSharedDispatcher(ctx context.Context, channel *v1beta1.Channel) error {
+	client, err := r.newPubsubClient(ctx, channel)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	psr := reconcilerutilspubsub.NewReconciler(client, r.Recorder)
+
+	if err := psr.DeleteSubscription(ctx, resources.GenerateDispatcherSubscriptionID(channel), channel, discardStatus{}); err != nil {
+		return fmt.Errorf("failed to delete the dispatcher subscription: %w", err)
+	}
+	for _, ss := range channel.Status.SubscribableStatus.Subscribers {
+		if err := r.deleteRetryQueue(ctx, psr, channel, ss.UID); err != nil {
+			return fmt.Errorf("failed to delete the retry queue of subscriber %q: %w", ss.UID, err)
+		}
+	}
+	return nil
+}
+
+// newPubsubClient creates a Pub/Sub client in the project of the channel.
+func newPubsubClient(ctx context.Context, channel *v1beta1.Channel) (*pubsub.Client, error) {
+	projectID, err := utils.ProjectID(channel.Spec.Project, metadataClient.NewDefaultMetadataClient())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find project id: %w", err)
+	}
+	client, err := pubsub.NewClient(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Pub/Sub client: %w", err)
+	}
+	return client, nil
+}
+
+// reconcileDispatcherConfig rebuilds the targets config of the shared dispatcher from all the
+// channels using it. The given channel, which is being reconciled, takes the place of its
+// possibly stale lister copy, and is left out if it is being deleted.
+func (r *Reconciler) reconcileDispatcherConfig(ctx context.Context, channel *v1beta1.Channel) error {
+	channels, err := r.channelLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list channels: %w", err)
+	}
+	targets := memory.NewEmptyTargets()
+	for _, c := range channels {
+		if c.UID == channel.UID {
+			c = channel
+		}
+		if !c.IsSharedDispatcher() || c.DeletionTimestamp != nil {
+			continue
+		}
+		resources.AddToDispatcherConfig(c, targets)
+	}
+
+	desired, err := resources.MakeDispatcherTargetsConfig(targets)
+	if err != nil {
+		return err
+	}
+	handlerFuncs := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { r.refreshDispatcherVolume(ctx) },
+		UpdateFunc: func(oldObj, newObj interface{}) { r.refreshDispatcherVolume(ctx) },
+	}
+	if _, err := r.cmRec.ReconcileConfigMap(ctx, channel, desired, resources.DispatcherTargetsConfigMapEqual, handlerFuncs); err != nil {
+		return fmt.Errorf("failed to update the dispatcher configmap: %w", err)
+	}
+	return nil
+}
+
+func (r *Reconciler) refreshDispatcherVolume(ctx context.Context) {
+	if err := volume.UpdateVolumeGeneration(ctx, r.KubeClientSet, r.podLister, system.Namespace(), resources.DispatcherLabels()); err != nil {
+		// Failing to update the annotation on the dispatcher pods only delays the propagation of
+		// the configmap to their volume.
+		logging.FromContext(ctx).Warn("Error updating annotation for dispatcher pods", zap.Error(err))
+	}
+}
+
+// pubsubRetryPolicy translates the delivery spec of a subscriber to the retry policy of its
+// retry queue, in the same manner as for triggers.
+func pubsubRetryPolicy(spec *eventingduckv1beta1.DeliverySpec) *pubsub.RetryPolicy {
+	if spec == nil || spec.BackoffDelay == nil {
+		return nil
+	}
+	p, err := period.Parse(*spec.BackoffDelay)
+	if err != nil {
+		return nil
+	}
+	minimumBackoff, _ := p.Duration()
+	maximumBackoff := minimumBackoff
+	if spec.BackoffPolicy != nil && *spec.BackoffPolicy == eventingduckv1beta1.BackoffPolicyExponential {
+		maximumBackoff = defaultMaximumBackoff
+	}
+	return &pubsub.RetryPolicy{
+		MinimumBackoff: minimumBackoff,
+		MaximumBackoff: maximumBackoff,
+	}
+}
+
+// discardStatus is the status updater of the Pub/Sub resources of the shared dispatcher, whose
+// failures are reported through the returned errors instead.
+type discardStatus struct{}
+
+func (discardStatus) MarkTopicFailed(string, string, ...interface{})         {}
+func (discardStatus) MarkTopicUnknown(string, string, ...interface{})        {}
+func (discardStatus) MarkTopicReady()                                        {}
+func (discardStatus) MarkSubscriptionFailed(string, string, ...interface{})  {}
+func (discardStatus) MarkSubscriptionUnknown(string, string, ...interface{}) {}
+func (discardStatus) MarkSubscriptionReady()                                 {}
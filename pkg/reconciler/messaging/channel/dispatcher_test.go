@@ -0,0 +1,189 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channel
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	eventingduckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
+	"knative.dev/pkg/system"
+	_ "knative.dev/pkg/system/testing"
+
+	"github.com/google/knative-gcp/pkg/apis/messaging/v1beta1"
+	"github.com/google/knative-gcp/pkg/broker/config/memory"
+	"github.com/google/knative-gcp/pkg/reconciler"
+	"github.com/google/knative-gcp/pkg/reconciler/messaging/channel/resources"
+	. "github.com/google/knative-gcp/pkg/reconciler/testing"
+	reconcilerutils "github.com/google/knative-gcp/pkg/reconciler/utils"
+)
+
+var sharedDispatcherAnnotations = map[string]string{
+	v1beta1.DispatcherAnnotationKey: v1beta1.DispatcherShared,
+}
+
+func TestReconcileSharedDispatcher(t *testing.T) {
+	ctx := context.Background()
+	psclient, newClient, close := TestPubsubClientFactory(ctx, testProject)
+	defer close()
+
+	channel := NewChannel(channelName, testNS,
+		WithChannelUID(channelUID),
+		WithChannelAnnotations(sharedDispatcherAnnotations),
+		WithChannelReady(testTopicID),
+		WithChannelSubscribers([]eventingduckv1beta1.SubscriberSpec{{
+			UID:           "sub1",
+			Generation:    2,
+			SubscriberURI: subscriberURI,
+		}, {
+			UID:      "sub2",
+			ReplyURI: replyURI,
+		}}),
+		WithChannelSubscribersStatus([]eventingduckv1beta1.SubscriberStatus{{
+			UID:   "sub1",
+			Ready: corev1.ConditionTrue,
+		}, {
+			UID:   "removed",
+			Ready: corev1.ConditionTrue,
+		}}),
+	)
+	// The topic of the channel, and the retry queue of the removed subscriber.
+	Topic(testTopicID)(ctx, t, psclient)
+	TopicAndSub(resources.GenerateRetryTopicID(channel, "removed"), resources.GenerateRetrySubscriptionID(channel, "removed"))(ctx, t, psclient)
+
+	r := &Reconciler{
+		Base: &reconciler.Base{Recorder: record.NewFakeRecorder(100)},
+		newPubsubClient: func(ctx context.Context, _ *v1beta1.Channel) (*pubsub.Client, error) {
+			return newClient(ctx, testProject)
+		},
+	}
+	if err := r.reconcileSharedDispatcher(ctx, channel); err != nil {
+		t.Fatalf("reconcileSharedDispatcher() = %v", err)
+	}
+
+	wantStatuses := []eventingduckv1beta1.SubscriberStatus{{
+		UID:                "sub1",
+		ObservedGeneration: 2,
+		Ready:              corev1.ConditionTrue,
+	}, {
+		UID:   "sub2",
+		Ready: corev1.ConditionTrue,
+	}}
+	if diff := cmp.Diff(wantStatuses, channel.Status.Subscribers); diff != "" {
+		t.Errorf("unexpected subscriber statuses (-want, +got): %s", diff)
+	}
+
+	wantTopics := map[string]bool{
+		testTopicID: true,
+		resources.GenerateRetryTopicID(channel, "sub1"):    true,
+		resources.GenerateRetryTopicID(channel, "sub2"):    true,
+		resources.GenerateRetryTopicID(channel, "removed"): false,
+	}
+	for id, want := range wantTopics {
+		if got, err := psclient.Topic(id).Exists(ctx); err != nil {
+			t.Errorf("failed to check if topic %q exists: %v", id, err)
+		} else if got != want {
+			t.Errorf("topic %q exists = %v, want %v", id, got, want)
+		}
+	}
+	wantSubscriptions := map[string]bool{
+		resources.GenerateDispatcherSubscriptionID(channel):       true,
+		resources.GenerateRetrySubscriptionID(channel, "sub1"):    true,
+		resources.GenerateRetrySubscriptionID(channel, "sub2"):    true,
+		resources.GenerateRetrySubscriptionID(channel, "removed"): false,
+	}
+	for id, want := range wantSubscriptions {
+		if got, err := psclient.Subscription(id).Exists(ctx); err != nil {
+			t.Errorf("failed to check if subscription %q exists: %v", id, err)
+		} else if got != want {
+			t.Errorf("subscription %q exists = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestReconcileDispatcherConfig(t *testing.T) {
+	ctx := context.Background()
+	subscribers := []eventingduckv1beta1.SubscriberSpec{{
+		UID:           "sub1",
+		SubscriberURI: subscriberURI,
+	}}
+	// The lister copy of the reconciled channel doesn't have its subscriber yet.
+	stale := NewChannel(channelName, testNS,
+		WithChannelUID(channelUID),
+		WithChannelAnnotations(sharedDispatcherAnnotations),
+		WithChannelReady(testTopicID),
+	)
+	reconciled := stale.DeepCopy()
+	WithChannelSubscribers(subscribers)(reconciled)
+	dedicated := NewChannel("dedicated", testNS,
+		WithChannelUID("dedicated-uid"),
+		WithChannelReady("dedicated-topic"),
+		WithChannelSubscribers(subscribers),
+	)
+	deleted := NewChannel("deleted", testNS,
+		WithChannelUID("deleted-uid"),
+		WithChannelAnnotations(sharedDispatcherAnnotations),
+		WithChannelReady("deleted-topic"),
+		WithChannelDeleted,
+	)
+	other := NewChannel("other", testNS,
+		WithChannelUID("other-uid"),
+		WithChannelAnnotations(sharedDispatcherAnnotations),
+		WithChannelReady("other-topic"),
+		WithChannelSubscribers(subscribers),
+	)
+	listers := NewListers([]runtime.Object{stale, dedicated, deleted, other})
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	recorder := record.NewFakeRecorder(100)
+	r := &Reconciler{
+		Base:          &reconciler.Base{KubeClientSet: kubeClient, Recorder: recorder},
+		channelLister: listers.GetChannelLister(),
+		podLister:     listers.GetPodLister(),
+		cmRec: &reconcilerutils.ConfigMapReconciler{
+			KubeClient: kubeClient,
+			Lister:     listers.GetConfigMapLister(),
+			Recorder:   recorder,
+		},
+	}
+	if err := r.reconcileDispatcherConfig(ctx, reconciled); err != nil {
+		t.Fatalf("reconcileDispatcherConfig() = %v", err)
+	}
+
+	targets := memory.NewEmptyTargets()
+	resources.AddToDispatcherConfig(reconciled, targets)
+	resources.AddToDispatcherConfig(other, targets)
+	want, err := resources.MakeDispatcherTargetsConfig(targets)
+	if err != nil {
+		t.Fatalf("MakeDispatcherTargetsConfig() = %v", err)
+	}
+	got, err := kubeClient.CoreV1().ConfigMaps(system.Namespace()).Get(ctx, resources.DispatcherTargetsConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get the dispatcher configmap: %v", err)
+	}
+	if !resources.DispatcherTargetsConfigMapEqual(want, got) {
+		t.Errorf("unexpected dispatcher targets config (-want, +got): %s",
+			cmp.Diff(want.Data, got.Data, cmpopts.EquateEmpty()))
+	}
+}
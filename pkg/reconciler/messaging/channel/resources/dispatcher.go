@@ -0,0 +1,156 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	eventingduckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
+	"knative.dev/pkg/system"
+
+	"github.com/google/knative-gcp/pkg/apis/messaging/v1beta1"
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+const (
+	// DispatcherTargetsConfigMapName is the name of the ConfigMap holding the targets config of
+	// the shared channel dispatcher.
+	DispatcherTargetsConfigMapName = "channel-dispatcher-targets"
+	dispatcherTargetsCMKey         = "targets"
+)
+
+// DispatcherLabels returns the labels of the shared channel dispatcher pods.
+func DispatcherLabels() map[string]string {
+	return map[string]string{
+		"app":  "cloud-run-events",
+		"role": "channel-dispatcher",
+	}
+}
+
+// AddToDispatcherConfig adds the given channel to the targets config of the shared channel
+// dispatcher. The channel takes the place of the broker, and each of its subscribers the place
+// of a target named after the subscriber's UID.
+func AddToDispatcherConfig(channel *v1beta1.Channel, targets config.Targets) {
+	targets.MutateBroker(channel.Namespace, channel.Name, func(m config.BrokerMutation) {
+		// First delete the channel entry.
+		m.Delete()
+
+		state := config.State_UNKNOWN
+		if channel.Status.IsReady() {
+			state = config.State_READY
+		}
+		m.SetID(string(channel.UID))
+		// Replies are sent to the reply address of each subscriber, never to the channel.
+		m.SetAddress("")
+		m.SetDecoupleQueue(&config.Queue{
+			Topic:        channel.Status.TopicID,
+			Subscription: GenerateDispatcherSubscriptionID(channel),
+			State:        state,
+		})
+		m.SetState(state)
+
+		if channel.Spec.SubscribableSpec == nil {
+			return
+		}
+		ready := make(map[string]bool, len(channel.Status.Subscribers))
+		for _, ss := range channel.Status.Subscribers {
+			ready[string(ss.UID)] = ss.Ready == corev1.ConditionTrue
+		}
+		for _, s := range channel.Spec.SubscribableSpec.Subscribers {
+			m.UpsertTargets(makeTarget(channel, s, ready[string(s.UID)]))
+		}
+	})
+}
+
+func makeTarget(channel *v1beta1.Channel, s eventingduckv1beta1.SubscriberSpec, ready bool) *config.Target {
+	target := &config.Target{
+		Id:        string(s.UID),
+		Name:      string(s.UID),
+		Namespace: channel.Namespace,
+		Broker:    channel.Name,
+		RetryQueue: &config.Queue{
+			Topic:        GenerateRetryTopicID(channel, s.UID),
+			Subscription: GenerateRetrySubscriptionID(channel, s.UID),
+		},
+	}
+	switch {
+	case s.SubscriberURI != nil:
+		target.Address = s.SubscriberURI.String()
+		if s.ReplyURI != nil {
+			target.ReplyAddress = s.ReplyURI.String()
+		}
+	case s.ReplyURI != nil:
+		// Without a subscriber, the events are sent to the reply directly.
+		target.Address = s.ReplyURI.String()
+	}
+	if s.Delivery != nil && s.Delivery.DeadLetterSink != nil && s.Delivery.DeadLetterSink.URI != nil {
+		target.DeliverySpec = &config.DeliverySpec{
+			DeadLetter: s.Delivery.DeadLetterSink.URI.String(),
+		}
+		if s.Delivery.Retry != nil {
+			target.DeliverySpec.Retry = *s.Delivery.Retry
+		}
+	}
+	if ready {
+		target.State = config.State_READY
+	}
+	return target
+}
+
+// MakeDispatcherTargetsConfig makes the ConfigMap holding the targets config of the shared
+// channel dispatcher.
+func MakeDispatcherTargetsConfig(targets config.Targets) (*corev1.ConfigMap, error) {
+	data, err := targets.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("error serializing targets config: %w", err)
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DispatcherTargetsConfigMapName,
+			Namespace: system.Namespace(),
+			Labels:    DispatcherLabels(),
+		},
+		BinaryData: map[string][]byte{dispatcherTargetsCMKey: data},
+		// Write out the text version for debugging purposes only
+		Data: map[string]string{"targets.txt": targets.String()},
+	}, nil
+}
+
+// DispatcherTargetsConfigMapEqual compares the targets configs held by two shared channel
+// dispatcher ConfigMaps, and returns true if and only if both are valid and equal.
+func DispatcherTargetsConfigMapEqual(cm1, cm2 *corev1.ConfigMap) bool {
+	v1, ok := cm1.BinaryData[dispatcherTargetsCMKey]
+	if !ok {
+		return false
+	}
+	v2, ok := cm2.BinaryData[dispatcherTargetsCMKey]
+	if !ok {
+		return false
+	}
+	proto1 := &config.TargetsConfig{}
+	proto2 := &config.TargetsConfig{}
+	if err := proto.Unmarshal(v1, proto1); err != nil {
+		return false
+	}
+	if err := proto.Unmarshal(v2, proto2); err != nil {
+		return false
+	}
+	return proto.Equal(proto1, proto2)
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	eventingduckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/ptr"
+	_ "knative.dev/pkg/system/testing"
+
+	"github.com/google/knative-gcp/pkg/apis/messaging/v1beta1"
+	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/broker/config/memory"
+)
+
+func TestAddToDispatcherConfig(t *testing.T) {
+	channel := &v1beta1.Channel{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "chan",
+			Namespace: "ns",
+			UID:       "chan-uid",
+		},
+		Spec: v1beta1.ChannelSpec{
+			SubscribableSpec: &eventingduckv1beta1.SubscribableSpec{
+				Subscribers: []eventingduckv1beta1.SubscriberSpec{{
+					UID:           "sub1",
+					SubscriberURI: apis.HTTP("subscriber1"),
+					ReplyURI:      apis.HTTP("reply1"),
+					Delivery: &eventingduckv1beta1.DeliverySpec{
+						DeadLetterSink: &duckv1.Destination{URI: apis.HTTP("dls")},
+						Retry:          ptr.Int32(3),
+					},
+				}, {
+					UID:      "sub2",
+					ReplyURI: apis.HTTP("reply2"),
+				}},
+			},
+		},
+	}
+	channel.Status.InitializeConditions()
+	channel.Status.SetAddress(apis.HTTP("publisher"))
+	channel.Status.MarkTopicReady()
+	channel.Status.TopicID = "topic"
+	channel.Status.Subscribers = []eventingduckv1beta1.SubscriberStatus{{
+		UID:   "sub1",
+		Ready: corev1.ConditionTrue,
+	}, {
+		UID:   "sub2",
+		Ready: corev1.ConditionFalse,
+	}}
+
+	targets := memory.NewEmptyTargets()
+	AddToDispatcherConfig(channel, targets)
+
+	want := &config.Broker{
+		Id:        "chan-uid",
+		Name:      "chan",
+		Namespace: "ns",
+		DecoupleQueue: &config.Queue{
+			Topic:        "topic",
+			Subscription: "cre-chan_ns_chan_chan-uid",
+			State:        config.State_READY,
+		},
+		State: config.State_READY,
+		Targets: map[string]*config.Target{
+			"sub1": {
+				Id:           "sub1",
+				Name:         "sub1",
+				Namespace:    "ns",
+				Broker:       "chan",
+				Address:      "http://subscriber1",
+				ReplyAddress: "http://reply1",
+				RetryQueue: &config.Queue{
+					Topic:        "cre-chansub_ns_chan_sub1",
+					Subscription: "cre-chansub_ns_chan_sub1",
+				},
+				DeliverySpec: &config.DeliverySpec{
+					DeadLetter: "http://dls",
+					Retry:      3,
+				},
+				State: config.State_READY,
+			},
+			"sub2": {
+				Id:        "sub2",
+				Name:      "sub2",
+				Namespace: "ns",
+				Broker:    "chan",
+				Address:   "http://reply2",
+				RetryQueue: &config.Queue{
+					Topic:        "cre-chansub_ns_chan_sub2",
+					Subscription: "cre-chansub_ns_chan_sub2",
+				},
+			},
+		},
+	}
+	got, ok := targets.GetBrokerByKey(config.BrokerKey("ns", "chan"))
+	if !ok {
+		t.Fatal("channel not found in the targets config")
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("unexpected channel config (-want, +got) = %v", diff)
+	}
+}
+
+func TestDispatcherTargetsConfigMapEqual(t *testing.T) {
+	channel := &v1beta1.Channel{
+		ObjectMeta: metav1.ObjectMeta{Name: "chan", Namespace: "ns", UID: "chan-uid"},
+	}
+	targets := memory.NewEmptyTargets()
+	empty, err := MakeDispatcherTargetsConfig(targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AddToDispatcherConfig(channel, targets)
+	cm1, err := MakeDispatcherTargetsConfig(targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm2, err := MakeDispatcherTargetsConfig(targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !DispatcherTargetsConfigMapEqual(cm1, cm2) {
+		t.Error("DispatcherTargetsConfigMapEqual() = false for the same config")
+	}
+	if DispatcherTargetsConfigMapEqual(empty, cm1) {
+		t.Error("DispatcherTargetsConfigMapEqual() = true for different configs")
+	}
+	if DispatcherTargetsConfigMapEqual(&corev1.ConfigMap{}, cm1) {
+		t.Error("DispatcherTargetsConfigMapEqual() = true for an invalid config")
+	}
+}
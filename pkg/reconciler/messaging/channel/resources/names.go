@@ -28,7 +28,8 @@ import (
 )
 
 const (
-	subscriptionNamePrefix = "cre-sub-"
+	subscriptionNamePrefix           = "cre-sub-"
+	deadLetterSubscriptionNamePrefix = "cre-dls-"
 )
 
 // GenerateTopicID generates the name of the Pub/Sub topic, not our Topic resource.
@@ -36,6 +37,30 @@ func GenerateTopicID(channel *v1beta1.Channel) string {
 	return naming.TruncatedPubsubResourceName("cre-chan", channel.Namespace, channel.Name, channel.UID)
 }
 
+// GenerateDispatcherSubscriptionID generates the name of the Pub/Sub subscription the shared
+// dispatcher pulls the events of the channel from.
+func GenerateDispatcherSubscriptionID(channel *v1beta1.Channel) string {
+	return naming.TruncatedPubsubResourceName("cre-chan", channel.Namespace, channel.Name, channel.UID)
+}
+
+// GenerateRetryTopicID generates the name of the Pub/Sub topic of the retry queue of a subscriber
+// of a channel with the shared dispatcher, using the subscriber's UID.
+func GenerateRetryTopicID(channel *v1beta1.Channel, UID types.UID) string {
+	return naming.TruncatedPubsubResourceName("cre-chansub", channel.Namespace, channel.Name, UID)
+}
+
+// GenerateRetrySubscriptionID generates the name of the Pub/Sub subscription of the retry queue
+// of a subscriber of a channel with the shared dispatcher, using the subscriber's UID.
+func GenerateRetrySubscriptionID(channel *v1beta1.Channel, UID types.UID) string {
+	return naming.TruncatedPubsubResourceName("cre-chansub", channel.Namespace, channel.Name, UID)
+}
+
+// GenerateDeadLetterTopicID generates the name of the Pub/Sub topic the events that could not be
+// delivered to a subscriber are forwarded to, using the subscriber's UID.
+func GenerateDeadLetterTopicID(channel *v1beta1.Channel, UID types.UID) string {
+	return naming.TruncatedPubsubResourceName("cre-chandl", channel.Namespace, channel.Name, UID)
+}
+
 func GeneratePublisherName(channel *v1beta1.Channel) string {
 	if strings.HasPrefix(channel.Name, "cre-") {
 		return kmeta.ChildName(channel.Name, "-chan")
@@ -52,3 +77,22 @@ func GeneratePullSubscriptionName(UID types.UID) string {
 func ExtractUIDFromPullSubscriptionName(name string) string {
 	return strings.TrimPrefix(name, subscriptionNamePrefix)
 }
+
+// GenerateDeadLetterPullSubscriptionName generates the name of the PullSubscription resource
+// delivering the dead letter events of a subscriber to its dead letter sink, using the
+// subscriber's UID.
+func GenerateDeadLetterPullSubscriptionName(UID types.UID) string {
+	return fmt.Sprintf("%s%s", deadLetterSubscriptionNamePrefix, string(UID))
+}
+
+// ExtractUIDFromDeadLetterPullSubscriptionName extracts the subscriber's UID from the dead letter
+// PullSubscription name.
+func ExtractUIDFromDeadLetterPullSubscriptionName(name string) string {
+	return strings.TrimPrefix(name, deadLetterSubscriptionNamePrefix)
+}
+
+// IsDeadLetterPullSubscriptionName returns whether the PullSubscription name is the one of a
+// dead letter PullSubscription.
+func IsDeadLetterPullSubscriptionName(name string) bool {
+	return strings.HasPrefix(name, deadLetterSubscriptionNamePrefix)
+}
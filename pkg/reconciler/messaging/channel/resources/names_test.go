@@ -41,6 +41,53 @@ func TestGenerateTopicID(t *testing.T) {
 	}
 }
 
+func TestGenerateDispatcherSubscriptionID(t *testing.T) {
+	want := "cre-chan_default_foo_a-uid"
+	got := GenerateDispatcherSubscriptionID(&v1beta1.Channel{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+			UID:       "a-uid",
+		},
+	})
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected (-want, +got) = %v", diff)
+	}
+}
+
+func TestGenerateRetryQueueIDs(t *testing.T) {
+	channel := &v1beta1.Channel{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+			UID:       "a-uid",
+		},
+	}
+	want := "cre-chansub_default_foo_sub-uid"
+	if diff := cmp.Diff(want, GenerateRetryTopicID(channel, "sub-uid")); diff != "" {
+		t.Errorf("unexpected topic (-want, +got) = %v", diff)
+	}
+	if diff := cmp.Diff(want, GenerateRetrySubscriptionID(channel, "sub-uid")); diff != "" {
+		t.Errorf("unexpected subscription (-want, +got) = %v", diff)
+	}
+}
+
+func TestGenerateDeadLetterTopicID(t *testing.T) {
+	want := "cre-chandl_default_foo_sub-uid"
+	got := GenerateDeadLetterTopicID(&v1beta1.Channel{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+			UID:       "a-uid",
+		},
+	}, "sub-uid")
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected (-want, +got) = %v", diff)
+	}
+}
+
 func TestGeneratePublisherName(t *testing.T) {
 	want := "cre-foo-chan"
 	got := GeneratePublisherName(&v1beta1.Channel{
@@ -84,3 +131,27 @@ func TestExtractUIDFromSubscriptionName(t *testing.T) {
 		t.Errorf("unexpected (-want, +got) = %v", diff)
 	}
 }
+
+func TestGenerateDeadLetterSubscriptionName(t *testing.T) {
+	want := "cre-dls-a-uid"
+	got := GenerateDeadLetterPullSubscriptionName("a-uid")
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected (-want, +got) = %v", diff)
+	}
+	if !IsDeadLetterPullSubscriptionName(got) {
+		t.Errorf("IsDeadLetterPullSubscriptionName(%q) = false, want true", got)
+	}
+	if IsDeadLetterPullSubscriptionName(GeneratePullSubscriptionName("a-uid")) {
+		t.Error("IsDeadLetterPullSubscriptionName(cre-sub-a-uid) = true, want false")
+	}
+}
+
+func TestExtractUIDFromDeadLetterSubscriptionName(t *testing.T) {
+	want := "a-uid"
+	got := ExtractUIDFromDeadLetterPullSubscriptionName("cre-dls-a-uid")
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected (-want, +got) = %v", diff)
+	}
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package resources
 
 import (
+	"time"
+
+	"github.com/rickb777/date/period"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	duckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
@@ -24,9 +27,14 @@ import (
 	"knative.dev/pkg/kmeta"
 
 	gcpduckv1beta1 "github.com/google/knative-gcp/pkg/apis/duck/v1beta1"
+	"github.com/google/knative-gcp/pkg/apis/intevents"
 	"github.com/google/knative-gcp/pkg/apis/intevents/v1beta1"
 )
 
+// defaultMaxDeliveryAttempts is the number of delivery attempts of the events of subscribers
+// with a dead letter sink but without a number of retries, the same as the Pub/Sub default.
+const defaultMaxDeliveryAttempts = 5
+
 // PullSubscriptionArgs are the arguments needed to create a Channel Subscriber.
 // Every field is required.
 type PullSubscriptionArgs struct {
@@ -40,6 +48,10 @@ type PullSubscriptionArgs struct {
 	Labels             map[string]string
 	Annotations        map[string]string
 	Subscriber         duckv1beta1.SubscriberSpec
+	// DeadLetterTopic is the Pub/Sub topic the events that could not be
+	// delivered to the subscriber are forwarded to. It is only set for
+	// subscribers with a dead letter sink.
+	DeadLetterTopic string
 }
 
 // MakePullSubscription generates (but does not insert into K8s) the
@@ -83,6 +95,40 @@ func MakePullSubscription(args *PullSubscriptionArgs) *v1beta1.PullSubscription
 		}
 	}
 
+	spec.RetryPolicy = makeRetryPolicy(args.Subscriber.Delivery)
+	if args.DeadLetterTopic != "" {
+		spec.DeadLetterPolicy = &v1beta1.DeadLetterPolicy{
+			DeadLetterTopic:     args.DeadLetterTopic,
+			MaxDeliveryAttempts: maxDeliveryAttempts(args.Subscriber.Delivery),
+		}
+	}
+
+	return makePullSubscription(args, spec)
+}
+
+// MakeDeadLetterPullSubscription generates (but does not insert into K8s) the
+// PullSubscription delivering the events forwarded to the dead letter topic
+// of a Channel subscriber to its dead letter sink.
+func MakeDeadLetterPullSubscription(args *PullSubscriptionArgs) *v1beta1.PullSubscription {
+	spec := v1beta1.PullSubscriptionSpec{
+		PubSubSpec: gcpduckv1beta1.PubSubSpec{
+			SourceSpec: duckv1.SourceSpec{
+				Sink: duckv1.Destination{
+					URI: args.Subscriber.Delivery.DeadLetterSink.URI,
+				},
+			},
+			IdentitySpec: gcpduckv1beta1.IdentitySpec{
+				ServiceAccountName: args.ServiceAccountName,
+			},
+			Secret:  args.Secret,
+			Project: args.Project,
+		},
+		Topic: args.Topic,
+	}
+	return makePullSubscription(args, spec)
+}
+
+func makePullSubscription(args *PullSubscriptionArgs, spec v1beta1.PullSubscriptionSpec) *v1beta1.PullSubscription {
 	return &v1beta1.PullSubscription{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace:       args.Owner.GetObjectMeta().GetNamespace(),
@@ -94,3 +140,57 @@ func MakePullSubscription(args *PullSubscriptionArgs) *v1beta1.PullSubscription
 		Spec: spec,
 	}
 }
+
+// HasDeadLetterSink returns whether the subscriber has a resolved dead letter
+// sink.
+func HasDeadLetterSink(subscriber duckv1beta1.SubscriberSpec) bool {
+	return subscriber.Delivery != nil && subscriber.Delivery.DeadLetterSink != nil &&
+		subscriber.Delivery.DeadLetterSink.URI != nil
+}
+
+// makeRetryPolicy translates the backoff of the subscriber delivery spec to
+// the retry policy of its PullSubscription, in the same manner as for
+// triggers: a linear backoff always waits for the backoff delay, while an
+// exponential one starts from it.
+func makeRetryPolicy(delivery *duckv1beta1.DeliverySpec) *v1beta1.RetryPolicy {
+	if delivery == nil || delivery.BackoffDelay == nil {
+		return nil
+	}
+	p, err := period.Parse(*delivery.BackoffDelay)
+	if err != nil {
+		return nil
+	}
+	minimumBackoff, _ := p.Duration()
+	if minimumBackoff > intevents.MaxBackoff {
+		minimumBackoff = intevents.MaxBackoff
+	}
+	maximumBackoff := minimumBackoff
+	if delivery.BackoffPolicy != nil && *delivery.BackoffPolicy == duckv1beta1.BackoffPolicyExponential {
+		maximumBackoff = intevents.MaxBackoff
+	}
+	return &v1beta1.RetryPolicy{
+		MinimumBackoff: durationString(minimumBackoff),
+		MaximumBackoff: durationString(maximumBackoff),
+	}
+}
+
+// maxDeliveryAttempts translates the number of retries of the subscriber
+// delivery spec to a number of delivery attempts within the Pub/Sub bounds.
+func maxDeliveryAttempts(delivery *duckv1beta1.DeliverySpec) int32 {
+	if delivery == nil || delivery.Retry == nil {
+		return defaultMaxDeliveryAttempts
+	}
+	attempts := *delivery.Retry + 1
+	if attempts < intevents.MinDeliveryAttempts {
+		return intevents.MinDeliveryAttempts
+	}
+	if attempts > intevents.MaxDeliveryAttempts {
+		return intevents.MaxDeliveryAttempts
+	}
+	return attempts
+}
+
+func durationString(d time.Duration) *string {
+	s := d.String()
+	return &s
+}
@@ -28,6 +28,7 @@ import (
 	duckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/ptr"
 )
 
 func TestMakePullSubscription(t *testing.T) {
@@ -286,3 +287,177 @@ func TestMakePullSubscription_JustReply(t *testing.T) {
 		t.Errorf("unexpected (-want, +got) = %v", diff)
 	}
 }
+
+func TestMakePullSubscription_Delivery(t *testing.T) {
+	channel := &v1beta1.Channel{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "channel-name",
+			Namespace: "channel-namespace",
+			UID:       "channel-uid",
+		},
+		Status: v1beta1.ChannelStatus{
+			ProjectID: "project-123",
+			TopicID:   "topic-abc",
+		},
+	}
+	linear := duckv1beta1.BackoffPolicyLinear
+	exponential := duckv1beta1.BackoffPolicyExponential
+	dls := &duckv1.Destination{
+		URI: &apis.URL{Scheme: "http", Host: "dls", Path: "/"},
+	}
+
+	tests := []struct {
+		name                 string
+		delivery             *duckv1beta1.DeliverySpec
+		deadLetterTopic      string
+		wantRetryPolicy      *inteventsv1beta1.RetryPolicy
+		wantDeadLetterPolicy *inteventsv1beta1.DeadLetterPolicy
+	}{{
+		name: "no delivery",
+	}, {
+		name: "linear backoff",
+		delivery: &duckv1beta1.DeliverySpec{
+			BackoffDelay:  ptr.String("PT5S"),
+			BackoffPolicy: &linear,
+		},
+		wantRetryPolicy: &inteventsv1beta1.RetryPolicy{
+			MinimumBackoff: ptr.String("5s"),
+			MaximumBackoff: ptr.String("5s"),
+		},
+	}, {
+		name: "exponential backoff",
+		delivery: &duckv1beta1.DeliverySpec{
+			BackoffDelay:  ptr.String("PT5S"),
+			BackoffPolicy: &exponential,
+		},
+		wantRetryPolicy: &inteventsv1beta1.RetryPolicy{
+			MinimumBackoff: ptr.String("5s"),
+			MaximumBackoff: ptr.String("10m0s"),
+		},
+	}, {
+		name: "backoff too long",
+		delivery: &duckv1beta1.DeliverySpec{
+			BackoffDelay: ptr.String("PT1H"),
+		},
+		wantRetryPolicy: &inteventsv1beta1.RetryPolicy{
+			MinimumBackoff: ptr.String("10m0s"),
+			MaximumBackoff: ptr.String("10m0s"),
+		},
+	}, {
+		name: "dead letter sink without retry",
+		delivery: &duckv1beta1.DeliverySpec{
+			DeadLetterSink: dls,
+		},
+		deadLetterTopic: "dead-letter-topic",
+		wantDeadLetterPolicy: &inteventsv1beta1.DeadLetterPolicy{
+			DeadLetterTopic:     "dead-letter-topic",
+			MaxDeliveryAttempts: 5,
+		},
+	}, {
+		name: "dead letter sink with retry",
+		delivery: &duckv1beta1.DeliverySpec{
+			DeadLetterSink: dls,
+			Retry:          ptr.Int32(9),
+		},
+		deadLetterTopic: "dead-letter-topic",
+		wantDeadLetterPolicy: &inteventsv1beta1.DeadLetterPolicy{
+			DeadLetterTopic:     "dead-letter-topic",
+			MaxDeliveryAttempts: 10,
+		},
+	}, {
+		name: "dead letter sink with too many retries",
+		delivery: &duckv1beta1.DeliverySpec{
+			DeadLetterSink: dls,
+			Retry:          ptr.Int32(1000),
+		},
+		deadLetterTopic: "dead-letter-topic",
+		wantDeadLetterPolicy: &inteventsv1beta1.DeadLetterPolicy{
+			DeadLetterTopic:     "dead-letter-topic",
+			MaxDeliveryAttempts: 100,
+		},
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := MakePullSubscription(&PullSubscriptionArgs{
+				Owner:   channel,
+				Name:    GeneratePullSubscriptionName("subscriber-uid"),
+				Project: channel.Status.ProjectID,
+				Topic:   channel.Status.TopicID,
+				Subscriber: duckv1beta1.SubscriberSpec{
+					SubscriberURI: &apis.URL{Scheme: "http", Host: "subscriber", Path: "/"},
+					Delivery:      tc.delivery,
+				},
+				DeadLetterTopic: tc.deadLetterTopic,
+			})
+			if diff := cmp.Diff(tc.wantRetryPolicy, got.Spec.RetryPolicy); diff != "" {
+				t.Errorf("unexpected retry policy (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(tc.wantDeadLetterPolicy, got.Spec.DeadLetterPolicy); diff != "" {
+				t.Errorf("unexpected dead letter policy (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestMakeDeadLetterPullSubscription(t *testing.T) {
+	channel := &v1beta1.Channel{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "channel-name",
+			Namespace: "channel-namespace",
+			UID:       "channel-uid",
+		},
+		Status: v1beta1.ChannelStatus{
+			ProjectID: "project-123",
+		},
+	}
+
+	got := MakeDeadLetterPullSubscription(&PullSubscriptionArgs{
+		Owner:              channel,
+		Name:               GenerateDeadLetterPullSubscriptionName("subscriber-uid"),
+		Project:            channel.Status.ProjectID,
+		Topic:              "dead-letter-topic",
+		ServiceAccountName: "ksa",
+		Subscriber: duckv1beta1.SubscriberSpec{
+			SubscriberURI: &apis.URL{Scheme: "http", Host: "subscriber", Path: "/"},
+			Delivery: &duckv1beta1.DeliverySpec{
+				DeadLetterSink: &duckv1.Destination{
+					URI: &apis.URL{Scheme: "http", Host: "dls", Path: "/"},
+				},
+			},
+		},
+	})
+
+	yes := true
+	want := &inteventsv1beta1.PullSubscription{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "channel-namespace",
+			Name:      "cre-dls-subscriber-uid",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         "messaging.cloud.google.com/v1beta1",
+				Kind:               "Channel",
+				Name:               "channel-name",
+				UID:                "channel-uid",
+				Controller:         &yes,
+				BlockOwnerDeletion: &yes,
+			}},
+		},
+		Spec: inteventsv1beta1.PullSubscriptionSpec{
+			PubSubSpec: duckinteventsv1beta1.PubSubSpec{
+				IdentitySpec: duckinteventsv1beta1.IdentitySpec{
+					ServiceAccountName: "ksa",
+				},
+				Project: "project-123",
+				SourceSpec: duckv1.SourceSpec{
+					Sink: duckv1.Destination{
+						URI: &apis.URL{Scheme: "http", Host: "dls", Path: "/"},
+					},
+				},
+			},
+			Topic: "dead-letter-topic",
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected (-want, +got) = %v", diff)
+	}
+}
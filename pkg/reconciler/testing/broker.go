@@ -157,6 +157,25 @@ func WithBrokerClass(bc string) BrokerOption {
 	}
 }
 
+// WithBrokerAnnotation sets an annotation of the Broker.
+func WithBrokerAnnotation(key, value string) BrokerOption {
+	return func(b *brokerv1beta1.Broker) {
+		annotations := b.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string, 1)
+		}
+		annotations[key] = value
+		b.SetAnnotations(annotations)
+	}
+}
+
+// WithBrokerReplayed marks the subscriptions of the Broker as seeked to the given time.
+func WithBrokerReplayed(t time.Time) BrokerOption {
+	return func(b *brokerv1beta1.Broker) {
+		b.Status.MarkReplayed(t)
+	}
+}
+
 func WithBrokerSetDefaults(b *brokerv1beta1.Broker) {
 	b.SetDefaults(context.Background())
 }
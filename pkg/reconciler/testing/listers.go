@@ -32,6 +32,8 @@ import (
 	rbacv1listers "k8s.io/client-go/listers/rbac/v1"
 	"k8s.io/client-go/tools/cache"
 
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	eventinglisters "knative.dev/eventing/pkg/client/listers/eventing/v1beta1"
 	"knative.dev/pkg/reconciler/testing"
 	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 	servingv1alpha1 "knative.dev/serving/pkg/apis/serving/v1alpha1"
@@ -63,11 +65,19 @@ var sinkAddToScheme = func(scheme *runtime.Scheme) error {
 	return nil
 }
 
+// eventTypeAddToScheme only adds the EventTypes of the eventing clientset, whose Brokers and
+// Triggers conflict with the ones of the events clientset.
+var eventTypeAddToScheme = func(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(eventingv1beta1.SchemeGroupVersion, &eventingv1beta1.EventType{}, &eventingv1beta1.EventTypeList{})
+	return nil
+}
+
 var clientSetSchemes = []func(*runtime.Scheme) error{
 	fakekubeclientset.AddToScheme,
 	fakeeventsclientset.AddToScheme,
 	fakeservingclientset.AddToScheme,
 	sinkAddToScheme,
+	eventTypeAddToScheme,
 }
 
 type Listers struct {
@@ -103,6 +113,10 @@ func (l *Listers) GetEventsObjects() []runtime.Object {
 	return l.sorter.ObjectsForSchemeFunc(fakeeventsclientset.AddToScheme)
 }
 
+func (l *Listers) GetEventingObjects() []runtime.Object {
+	return l.sorter.ObjectsForSchemeFunc(eventTypeAddToScheme)
+}
+
 func (l *Listers) GetSinkObjects() []runtime.Object {
 	return l.sorter.ObjectsForSchemeFunc(sinkAddToScheme)
 }
@@ -122,6 +136,10 @@ func (l *Listers) GetPullSubscriptionLister() inteventslisters.PullSubscriptionL
 	return inteventslisters.NewPullSubscriptionLister(l.indexerFor(&inteventsv1.PullSubscription{}))
 }
 
+func (l *Listers) GetEventTypeLister() eventinglisters.EventTypeLister {
+	return eventinglisters.NewEventTypeLister(l.indexerFor(&eventingv1beta1.EventType{}))
+}
+
 func (l *Listers) GetTopicLister() inteventslisters.TopicLister {
 	return inteventslisters.NewTopicLister(l.indexerFor(&inteventsv1.Topic{}))
 }
@@ -162,6 +180,14 @@ func (l *Listers) GetDeploymentLister() appsv1listers.DeploymentLister {
 	return appsv1listers.NewDeploymentLister(l.indexerFor(&appsv1.Deployment{}))
 }
 
+func (l *Listers) GetStatefulSetLister() appsv1listers.StatefulSetLister {
+	return appsv1listers.NewStatefulSetLister(l.indexerFor(&appsv1.StatefulSet{}))
+}
+
+func (l *Listers) GetSecretLister() corev1listers.SecretLister {
+	return corev1listers.NewSecretLister(l.indexerFor(&corev1.Secret{}))
+}
+
 func (l *Listers) GetK8sServiceLister() corev1listers.ServiceLister {
 	return corev1listers.NewServiceLister(l.indexerFor(&corev1.Service{}))
 }
@@ -214,6 +240,10 @@ func (l *Listers) GetBrokerCellLister() intlisters.BrokerCellLister {
 	return intlisters.NewBrokerCellLister(l.indexerFor(&intv1alpha1.BrokerCell{}))
 }
 
+func (l *Listers) GetRedisStreamSourceLister() intlisters.RedisStreamSourceLister {
+	return intlisters.NewRedisStreamSourceLister(l.indexerFor(&intv1alpha1.RedisStreamSource{}))
+}
+
 func (l *Listers) GetHPALister() hpav2beta2listers.HorizontalPodAutoscalerLister {
 	return hpav2beta2listers.NewHorizontalPodAutoscalerLister(l.indexerFor(&hpav2beta2.HorizontalPodAutoscaler{}))
 }
@@ -213,3 +213,30 @@ func TestPubsubClient(ctx context.Context, projectID string) (*pubsub.Client, fu
 	}
 	return c, close
 }
+
+// TestPubsubClientFactory is like TestPubsubClient, but also returns a function
+// creating other clients of the same test server, which the code under test can
+// close without closing the returned client.
+func TestPubsubClientFactory(ctx context.Context, projectID string) (*pubsub.Client, func(context.Context, string) (*pubsub.Client, error), func()) {
+	srv := pstest.NewServer()
+	var conns []*grpc.ClientConn
+	newClient := func(ctx context.Context, projectID string) (*pubsub.Client, error) {
+		conn, err := grpc.Dial(srv.Addr, grpc.WithInsecure())
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial test pubsub connection: %w", err)
+		}
+		conns = append(conns, conn)
+		return pubsub.NewClient(ctx, projectID, option.WithGRPCConn(conn))
+	}
+	close := func() {
+		srv.Close()
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}
+	c, err := newClient(ctx, projectID)
+	if err != nil {
+		panic(fmt.Errorf("failed to create test pubsub client: %v", err))
+	}
+	return c, newClient, close
+}
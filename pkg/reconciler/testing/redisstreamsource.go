@@ -0,0 +1,166 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+)
+
+// RedisStreamSourceOption enables further configuration of a RedisStreamSource.
+type RedisStreamSourceOption func(*v1alpha1.RedisStreamSource)
+
+// NewRedisStreamSource creates a RedisStreamSource with RedisStreamSourceOptions.
+func NewRedisStreamSource(name, namespace string, so ...RedisStreamSourceOption) *v1alpha1.RedisStreamSource {
+	s := &v1alpha1.RedisStreamSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	for _, opt := range so {
+		opt(s)
+	}
+	return s
+}
+
+func WithRedisStreamSourceUID(uid types.UID) RedisStreamSourceOption {
+	return func(s *v1alpha1.RedisStreamSource) {
+		s.UID = uid
+	}
+}
+
+func WithRedisStreamSourceObjectMetaGeneration(generation int64) RedisStreamSourceOption {
+	return func(s *v1alpha1.RedisStreamSource) {
+		s.ObjectMeta.Generation = generation
+	}
+}
+
+func WithRedisStreamSourceStatusObservedGeneration(generation int64) RedisStreamSourceOption {
+	return func(s *v1alpha1.RedisStreamSource) {
+		s.Status.ObservedGeneration = generation
+	}
+}
+
+func WithRedisStreamSourceAddress(address string) RedisStreamSourceOption {
+	return func(s *v1alpha1.RedisStreamSource) {
+		s.Spec.Address = address
+	}
+}
+
+func WithRedisStreamSourceStream(stream string) RedisStreamSourceOption {
+	return func(s *v1alpha1.RedisStreamSource) {
+		s.Spec.Stream = stream
+	}
+}
+
+// WithRedisStreamSourceGroup sets the consumer group of the spec.
+func WithRedisStreamSourceGroup(group string) RedisStreamSourceOption {
+	return func(s *v1alpha1.RedisStreamSource) {
+		s.Spec.Group = group
+	}
+}
+
+func WithRedisStreamSourceMaxDeliveries(maxDeliveries int32) RedisStreamSourceOption {
+	return func(s *v1alpha1.RedisStreamSource) {
+		s.Spec.MaxDeliveries = maxDeliveries
+	}
+}
+
+func WithRedisStreamSourceDeadLetterStream(stream string) RedisStreamSourceOption {
+	return func(s *v1alpha1.RedisStreamSource) {
+		s.Spec.DeadLetterStream = stream
+	}
+}
+
+func WithRedisStreamSourceSink(gvk metav1.GroupVersionKind, name string) RedisStreamSourceOption {
+	return func(s *v1alpha1.RedisStreamSource) {
+		s.Spec.Sink = duckv1.Destination{
+			Ref: &duckv1.KReference{
+				APIVersion: ApiVersion(gvk),
+				Kind:       gvk.Kind,
+				Name:       name,
+			},
+		}
+	}
+}
+
+// WithInitRedisStreamSourceConditions initializes the RedisStreamSource's conditions.
+func WithInitRedisStreamSourceConditions(s *v1alpha1.RedisStreamSource) {
+	s.Status.InitializeConditions()
+}
+
+func WithRedisStreamSourceSinkURI(url *apis.URL) RedisStreamSourceOption {
+	return func(s *v1alpha1.RedisStreamSource) {
+		s.Status.MarkSink(url.String())
+	}
+}
+
+// WithRedisStreamSourceNoSink marks the condition that the sink can't be
+// resolved.
+func WithRedisStreamSourceNoSink(reason, message string) RedisStreamSourceOption {
+	return func(s *v1alpha1.RedisStreamSource) {
+		s.Status.MarkNoSink(reason, "%s", message)
+	}
+}
+
+// WithRedisStreamSourceStatusGroup sets the consumer group of the status.
+func WithRedisStreamSourceStatusGroup(group string) RedisStreamSourceOption {
+	return func(s *v1alpha1.RedisStreamSource) {
+		s.Status.Group = group
+	}
+}
+
+// WithRedisStreamSourceStatefulSetReady marks the condition that the receive
+// adapter is deployed.
+func WithRedisStreamSourceStatefulSetReady(s *v1alpha1.RedisStreamSource) {
+	one := int32(1)
+	s.Status.PropagateStatefulSetAvailability(&appsv1.StatefulSet{
+		Spec:   appsv1.StatefulSetSpec{Replicas: &one},
+		Status: appsv1.StatefulSetStatus{ReadyReplicas: one},
+	})
+}
+
+// WithRedisStreamSourceStatefulSetUnavailable marks the condition that the
+// receive adapter isn't available.
+func WithRedisStreamSourceStatefulSetUnavailable(name string) RedisStreamSourceOption {
+	return func(s *v1alpha1.RedisStreamSource) {
+		one := int32(1)
+		s.Status.PropagateStatefulSetAvailability(&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &one},
+		})
+	}
+}
+
+func WithRedisStreamSourceFinalizers(finalizers ...string) RedisStreamSourceOption {
+	return func(s *v1alpha1.RedisStreamSource) {
+		s.Finalizers = finalizers
+	}
+}
+
+func WithRedisStreamSourceDeletionTimestamp(s *v1alpha1.RedisStreamSource) {
+	t := metav1.NewTime(time.Unix(1e9, 0))
+	s.ObjectMeta.SetDeletionTimestamp(&t)
+}
@@ -18,11 +18,13 @@ package testing
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	brokerv1beta1 "github.com/google/knative-gcp/pkg/apis/broker/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	eventingduckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
 	"knative.dev/eventing/pkg/apis/eventing"
 	"knative.dev/eventing/pkg/apis/eventing/v1beta1"
 	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
@@ -94,6 +96,18 @@ func WithInitTriggerConditions(t *brokerv1beta1.Trigger) {
 	t.Status.InitializeConditions()
 }
 
+// WithTriggerDeliverySpec sets the Trigger's delivery spec annotation, overriding
+// the Broker's.
+func WithTriggerDeliverySpec(deliverySpec *eventingduckv1beta1.DeliverySpec) TriggerOption {
+	return func(t *brokerv1beta1.Trigger) {
+		value, _ := json.Marshal(deliverySpec)
+		if t.Annotations == nil {
+			t.Annotations = make(map[string]string)
+		}
+		t.Annotations[brokerv1beta1.DeliveryAnnotationKey] = string(value)
+	}
+}
+
 func WithTriggerGeneration(gen int64) TriggerOption {
 	return func(t *brokerv1beta1.Trigger) {
 		t.Generation = gen
@@ -183,6 +197,24 @@ func WithTriggerSubscriberResolvedUnknown(reason, message string) TriggerOption
 	}
 }
 
+// WithTriggerDeadLetterSinkResolvedSucceeded marks the dead letter sink as
+// resolved with the given URI. An empty URI means no addressable sink.
+func WithTriggerDeadLetterSinkResolvedSucceeded(uri string) TriggerOption {
+	return func(t *brokerv1beta1.Trigger) {
+		u, _ := apis.ParseURL(uri)
+		if uri == "" {
+			u = nil
+		}
+		t.Status.MarkDeadLetterSinkResolvedSucceeded(u)
+	}
+}
+
+func WithTriggerDeadLetterSinkResolvedFailed(reason, message string) TriggerOption {
+	return func(t *brokerv1beta1.Trigger) {
+		t.Status.MarkDeadLetterSinkResolvedFailed(reason, message)
+	}
+}
+
 func WithTriggerSubscriptionReady(t *brokerv1beta1.Trigger) {
 	t.Status.MarkSubscriptionReady()
 }
@@ -208,6 +240,25 @@ func WithTriggerFinalizers(finalizers ...string) TriggerOption {
 	}
 }
 
+// WithTriggerAnnotation sets an annotation of the Trigger.
+func WithTriggerAnnotation(key, value string) TriggerOption {
+	return func(t *brokerv1beta1.Trigger) {
+		annotations := t.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string, 1)
+		}
+		annotations[key] = value
+		t.SetAnnotations(annotations)
+	}
+}
+
+// WithTriggerReplayed marks the subscription of the Trigger as seeked to the given time.
+func WithTriggerReplayed(replayed time.Time) TriggerOption {
+	return func(t *brokerv1beta1.Trigger) {
+		t.Status.MarkReplayed(replayed)
+	}
+}
+
 func WithTriggerSetDefaults(t *brokerv1beta1.Trigger) {
 	t.SetDefaults(context.Background())
 }
@@ -151,6 +151,14 @@ func WithCloudAuditLogsSourceSinkReady(s *v1.CloudAuditLogsSource) {
 	s.Status.MarkSinkReady()
 }
 
+// WithCloudAuditLogsSourceDriftDetected marks the condition that the drift of
+// the CloudAuditLogsSource sink was detected and repaired.
+func WithCloudAuditLogsSourceDriftDetected(message string) CloudAuditLogsSourceOption {
+	return func(s *v1.CloudAuditLogsSource) {
+		s.Status.MarkDriftDetected(s.ConditionSet(), "DriftDetected", message)
+	}
+}
+
 // WithCloudAuditLogsSourceSinkDeleted is a wrapper to indicate that the
 // sink is deleted. Inside the function, we still mark the status of sink to be ready,
 // as the status of sink is unchanged if the deletion is successful.
@@ -224,6 +232,26 @@ func WithCloudAuditLogsSourceMethodName(methodName string) CloudAuditLogsSourceO
 	}
 }
 
+func WithCloudAuditLogsSourceSeverity(severity string) CloudAuditLogsSourceOption {
+	return func(s *v1.CloudAuditLogsSource) {
+		s.Spec.Severity = severity
+	}
+}
+
+func WithCloudAuditLogsSourceParent(parent string, includeChildren bool) CloudAuditLogsSourceOption {
+	return func(s *v1.CloudAuditLogsSource) {
+		s.Spec.Parent = parent
+		s.Spec.IncludeChildren = includeChildren
+	}
+}
+
+// WithCloudAuditLogsSourceFilter sets Status.Filter to filter.
+func WithCloudAuditLogsSourceFilter(filter string) CloudAuditLogsSourceOption {
+	return func(s *v1.CloudAuditLogsSource) {
+		s.Status.Filter = filter
+	}
+}
+
 func WithCloudAuditLogsSourceFinalizers(finalizers ...string) CloudAuditLogsSourceOption {
 	return func(s *v1.CloudAuditLogsSource) {
 		s.Finalizers = finalizers
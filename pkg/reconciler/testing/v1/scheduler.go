@@ -91,6 +91,12 @@ func WithCloudSchedulerSourceData(data string) CloudSchedulerSourceOption {
 	}
 }
 
+func WithCloudSchedulerSourcePaused(paused bool) CloudSchedulerSourceOption {
+	return func(s *v1.CloudSchedulerSource) {
+		s.Spec.Paused = paused
+	}
+}
+
 func WithCloudSchedulerSourceDeletionTimestamp(s *v1.CloudSchedulerSource) {
 	t := metav1.NewTime(time.Unix(1e9, 0))
 	s.ObjectMeta.SetDeletionTimestamp(&t)
@@ -195,6 +201,14 @@ func WithCloudSchedulerSourceJobReady(jobName string) CloudSchedulerSourceOption
 	}
 }
 
+// WithCloudSchedulerSourceDriftDetected marks the condition that the drift of
+// the CloudSchedulerSource job was detected and repaired.
+func WithCloudSchedulerSourceDriftDetected(message string) CloudSchedulerSourceOption {
+	return func(s *v1.CloudSchedulerSource) {
+		s.Status.MarkDriftDetected(s.ConditionSet(), "DriftDetected", message)
+	}
+}
+
 // WithCloudSchedulerSourceJobDeleted is a wrapper to indicate that the
 // job is deleted. Inside the function, we still mark the status of job to be ready,
 // as the status of job is unchanged if the deletion is successful.
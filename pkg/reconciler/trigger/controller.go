@@ -31,6 +31,7 @@ import (
 	"knative.dev/eventing/pkg/duck"
 	"knative.dev/pkg/client/injection/ducks/duck/v1/addressable"
 	"knative.dev/pkg/client/injection/ducks/duck/v1/conditions"
+	secretinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/secret"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	pkgcontroller "knative.dev/pkg/controller"
@@ -42,9 +43,11 @@ import (
 	"github.com/google/knative-gcp/pkg/apis/configs/dataresidency"
 	brokerinformer "github.com/google/knative-gcp/pkg/client/injection/informers/broker/v1beta1/broker"
 	triggerinformer "github.com/google/knative-gcp/pkg/client/injection/informers/broker/v1beta1/trigger"
+	brokercellinformer "github.com/google/knative-gcp/pkg/client/injection/informers/intevents/v1alpha1/brokercell"
 	triggerreconciler "github.com/google/knative-gcp/pkg/client/injection/reconciler/broker/v1beta1/trigger"
 	metadataClient "github.com/google/knative-gcp/pkg/gclient/metadata"
 	"github.com/google/knative-gcp/pkg/reconciler"
+	reconcilerutilsredis "github.com/google/knative-gcp/pkg/reconciler/utils/redis"
 	"github.com/google/knative-gcp/pkg/utils"
 )
 
@@ -102,11 +105,13 @@ func newController(ctx context.Context, cmw configmap.Watcher, drs *dataresidenc
 		}()
 	}
 	r := &Reconciler{
-		Base:               reconciler.NewBase(ctx, controllerAgentName, cmw),
-		brokerLister:       brokerinformer.Get(ctx).Lister(),
-		pubsubClient:       client,
-		projectID:          projectID,
-		dataresidencyStore: drs,
+		Base:                   reconciler.NewBase(ctx, controllerAgentName, cmw),
+		brokerLister:           brokerinformer.Get(ctx).Lister(),
+		brokerCellLister:       brokercellinformer.Get(ctx).Lister(),
+		newRedisQueueDeleterFn: reconcilerutilsredis.NewQueueDeleterFn(secretinformer.Get(ctx).Lister()),
+		pubsubClient:           client,
+		projectID:              projectID,
+		dataresidencyStore:     drs,
 	}
 
 	impl := triggerreconciler.NewImpl(ctx, r, withAgentAndFinalizer)
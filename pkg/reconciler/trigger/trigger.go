@@ -33,16 +33,19 @@ import (
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/pkg/resolver"
+	"knative.dev/pkg/system"
 
 	"cloud.google.com/go/pubsub"
 	brokerv1beta1 "github.com/google/knative-gcp/pkg/apis/broker/v1beta1"
 	"github.com/google/knative-gcp/pkg/apis/configs/dataresidency"
 	triggerreconciler "github.com/google/knative-gcp/pkg/client/injection/reconciler/broker/v1beta1/trigger"
 	brokerlisters "github.com/google/knative-gcp/pkg/client/listers/broker/v1beta1"
+	inteventslisters "github.com/google/knative-gcp/pkg/client/listers/intevents/v1alpha1"
 	metadataClient "github.com/google/knative-gcp/pkg/gclient/metadata"
 	"github.com/google/knative-gcp/pkg/reconciler"
 	"github.com/google/knative-gcp/pkg/reconciler/broker/resources"
 	reconcilerutilspubsub "github.com/google/knative-gcp/pkg/reconciler/utils/pubsub"
+	reconcilerutilsredis "github.com/google/knative-gcp/pkg/reconciler/utils/redis"
 	"github.com/google/knative-gcp/pkg/utils"
 	"knative.dev/eventing/pkg/apis/eventing/v1beta1"
 )
@@ -69,7 +72,8 @@ var (
 type Reconciler struct {
 	*reconciler.Base
 
-	brokerLister brokerlisters.BrokerLister
+	brokerLister     brokerlisters.BrokerLister
+	brokerCellLister inteventslisters.BrokerCellLister
 
 	// Dynamic tracker to track KResources. It tracks the dependency between Triggers and Sources.
 	kresourceTracker duck.ListableTracker
@@ -83,6 +87,10 @@ type Reconciler struct {
 	// pubsubClient is used as the Pubsub client when present.
 	pubsubClient *pubsub.Client
 
+	// newRedisQueueDeleterFn connects to the Redis instance of the BrokerCell
+	// when its queues are Redis streams.
+	newRedisQueueDeleterFn reconcilerutilsredis.QueueDeleterFn
+
 	dataresidencyStore *dataresidency.Store
 }
 
@@ -127,7 +135,12 @@ func (r *Reconciler) reconcile(ctx context.Context, t *brokerv1beta1.Trigger, b
 	if b.Spec.Delivery == nil {
 		b.SetDefaults(ctx)
 	}
-	if err := r.reconcileRetryTopicAndSubscription(ctx, t, b.Spec.Delivery); err != nil {
+	// The Trigger's delivery spec, if set, overrides the Broker's.
+	deliverySpec := t.DeliverySpec(b)
+	if err := r.resolveDeadLetterSink(ctx, t, b, deliverySpec); err != nil {
+		return err
+	}
+	if err := r.reconcileRetryTopicAndSubscription(ctx, t, deliverySpec); err != nil {
 		return err
 	}
 
@@ -153,6 +166,9 @@ func (r *Reconciler) FinalizeKind(ctx context.Context, t *brokerv1beta1.Trigger)
 	if err := r.deleteRetryTopicAndSubscription(ctx, t); err != nil {
 		return err
 	}
+	if err := r.deleteRetryRedisQueue(ctx, t); err != nil {
+		return fmt.Errorf("failed to delete Redis stream: %v", err)
+	}
 	return pkgreconciler.NewEvent(corev1.EventTypeNormal, triggerFinalized, "Trigger finalized: \"%s/%s\"", t.Namespace, t.Name)
 }
 
@@ -176,6 +192,32 @@ func (r *Reconciler) resolveSubscriber(ctx context.Context, t *brokerv1beta1.Tri
 	return nil
 }
 
+// resolveDeadLetterSink resolves the URI of an addressable dead letter sink so
+// that the broker can deliver to it. Pub/Sub dead letter sinks are handled by the
+// retry subscription's dead letter policy and need no resolution.
+func (r *Reconciler) resolveDeadLetterSink(ctx context.Context, t *brokerv1beta1.Trigger, b *brokerv1beta1.Broker, deliverySpec *eventingduckv1beta1.DeliverySpec) error {
+	sink := deliverySpec.DeadLetterSink
+	if sink == nil || brokerv1beta1.IsPubsubDeadLetterSink(sink) {
+		t.Status.MarkDeadLetterSinkResolvedSucceeded(nil)
+		return nil
+	}
+
+	// Copy the sink so that defaulting the Ref namespace doesn't modify the
+	// Broker from the lister cache.
+	sink = sink.DeepCopy()
+	if sink.Ref != nil && sink.Ref.Namespace == "" {
+		sink.Ref.Namespace = b.GetNamespace()
+	}
+	deadLetterURI, err := r.uriResolver.URIFromDestinationV1(ctx, *sink, b)
+	if err != nil {
+		logging.FromContext(ctx).Error("Unable to get the dead letter sink's URI", zap.Error(err))
+		t.Status.MarkDeadLetterSinkResolvedFailed("Unable to get the dead letter sink's URI", "%v", err)
+		return err
+	}
+	t.Status.MarkDeadLetterSinkResolvedSucceeded(deadLetterURI)
+	return nil
+}
+
 // hasGCPBrokerFinalizer checks if the Trigger object has a finalizer matching the one added by this controller.
 func hasGCPBrokerFinalizer(t *brokerv1beta1.Trigger) bool {
 	for _, f := range t.Finalizers {
@@ -251,13 +293,29 @@ func (r *Reconciler) reconcileRetryTopicAndSubscription(ctx context.Context, tri
 		Labels:           labels,
 		RetryPolicy:      retryPolicy,
 		DeadLetterPolicy: deadLetterPolicy,
+		// Events of ordered triggers are delivered from the retry queue, see the deliver
+		// processor of the fanout.
+		EnableMessageOrdering: trig.IsOrdered(),
+		// Acknowledged events are retained so that they can be replayed.
+		RetainAckedMessages: trig.ReplayRetention() != 0,
+		RetentionDuration:   trig.ReplayRetention(),
 		//TODO(grantr): configure these settings?
 		// AckDeadline
-		// RetentionDuration
 	}
-	if _, err := pubsubReconciler.ReconcileSubscription(ctx, subID, subConfig, trig, &trig.Status); err != nil {
+	sub, err := pubsubReconciler.ReconcileSubscription(ctx, subID, subConfig, trig, &trig.Status)
+	if err != nil {
 		return err
 	}
+
+	// Seek the subscription when the replay time is set or changed.
+	if replayTime, ok := trig.ReplayTime(); ok {
+		if replayed, ok := trig.Status.ReplayedTime(); !ok || !replayed.Equal(replayTime) {
+			if err := pubsubReconciler.SeekSubscription(ctx, sub, replayTime, trig); err != nil {
+				return fmt.Errorf("failed to replay the events since %v: %w", replayTime, err)
+			}
+			trig.Status.MarkReplayed(replayTime)
+		}
+	}
 	// TODO(grantr): this isn't actually persisted due to webhook issues.
 	//TODO uncomment when eventing webhook allows this
 	//trig.Status.SubscriptionID = sub.ID()
@@ -265,8 +323,8 @@ func (r *Reconciler) reconcileRetryTopicAndSubscription(ctx context.Context, tri
 	return nil
 }
 
-// getPubsubRetryPolicy gets the eventing retry policy from the Broker delivery
-// spec and translates it to a pubsub retry policy.
+// getPubsubRetryPolicy gets the eventing retry policy from the Trigger or
+// Broker delivery spec and translates it to a pubsub retry policy.
 func getPubsubRetryPolicy(spec *eventingduckv1beta1.DeliverySpec) *pubsub.RetryPolicy {
 	// The Broker delivery spec is translated to a pubsub retry policy in the
 	// manner defined in the following post:
@@ -287,9 +345,11 @@ func getPubsubRetryPolicy(spec *eventingduckv1beta1.DeliverySpec) *pubsub.RetryP
 }
 
 // getPubsubDeadLetterPolicy gets the eventing dead letter policy from the
-// Broker delivery spec and translates it to a pubsub dead letter policy.
+// Trigger or Broker delivery spec and translates it to a pubsub dead letter policy.
 func getPubsubDeadLetterPolicy(projectID string, spec *eventingduckv1beta1.DeliverySpec) *pubsub.DeadLetterPolicy {
-	if spec.DeadLetterSink == nil {
+	// Addressable dead letter sinks are delivered to by the broker, which
+	// needs the retry subscription to keep redelivering until it gives up.
+	if !brokerv1beta1.IsPubsubDeadLetterSink(spec.DeadLetterSink) {
 		return nil
 	}
 	// Translate to the pubsub dead letter policy format.
@@ -336,6 +396,26 @@ func (r *Reconciler) deleteRetryTopicAndSubscription(ctx context.Context, trig *
 	return err
 }
 
+// deleteRetryRedisQueue deletes the stream and consumer group standing for the retry topic and
+// subscription of the trigger, when the queues of the BrokerCell are Redis streams.
+func (r *Reconciler) deleteRetryRedisQueue(ctx context.Context, trig *brokerv1beta1.Trigger) error {
+	bc, err := r.brokerCellLister.BrokerCells(system.Namespace()).Get(resources.DefaultBrokerCellName)
+	if apierrs.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	d, err := r.newRedisQueueDeleterFn(ctx, bc)
+	if d == nil || err != nil {
+		return err
+	}
+	defer d.Close()
+	return reconcilerutilsredis.DeleteQueues(ctx, d, map[string]string{
+		resources.GenerateRetryTopicName(trig): resources.GenerateRetrySubscriptionName(trig),
+	})
+}
+
 func (r *Reconciler) checkDependencyAnnotation(ctx context.Context, t *brokerv1beta1.Trigger) error {
 	if dependencyAnnotation, ok := t.GetAnnotations()[v1beta1.DependencyAnnotation]; ok {
 		dependencyObjRef, err := v1beta1.GetObjRefFromDependencyAnnotation(dependencyAnnotation)
@@ -47,6 +47,7 @@ import (
 	triggerreconciler "github.com/google/knative-gcp/pkg/client/injection/reconciler/broker/v1beta1/trigger"
 	"github.com/google/knative-gcp/pkg/reconciler"
 	. "github.com/google/knative-gcp/pkg/reconciler/testing"
+	reconcilerutilsredis "github.com/google/knative-gcp/pkg/reconciler/utils/redis"
 )
 
 const (
@@ -71,6 +72,8 @@ var (
 
 	testKey = fmt.Sprintf("%s/%s", testNS, triggerName)
 
+	replayTime = time.Date(2020, 9, 1, 10, 0, 0, 0, time.UTC)
+
 	triggerFinalizerUpdatedEvent = Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "test-trigger" finalizers`)
 	triggerReconciledEvent       = Eventf(corev1.EventTypeNormal, "TriggerReconciled", `Trigger reconciled: "testnamespace/test-trigger"`)
 	triggerFinalizedEvent        = Eventf(corev1.EventTypeNormal, "TriggerFinalized", `Trigger finalized: "testnamespace/test-trigger"`)
@@ -79,12 +82,30 @@ var (
 	deadLetterTopicCreatedEvent  = Eventf(corev1.EventTypeNormal, "TopicCreated", `Created PubSub topic "test-dead-letter-topic-id"`)
 	subscriptionCreatedEvent     = Eventf(corev1.EventTypeNormal, "SubscriptionCreated", `Created PubSub subscription "cre-tgr_testnamespace_test-trigger_abc123"`)
 	subscriptionDeletedEvent     = Eventf(corev1.EventTypeNormal, "SubscriptionDeleted", `Deleted PubSub subscription "cre-tgr_testnamespace_test-trigger_abc123"`)
+	subscriptionSeekedEvent      = Eventf(corev1.EventTypeNormal, "SubscriptionSeeked", `Seeked PubSub subscription "cre-tgr_testnamespace_test-trigger_abc123" to 2020-09-01T10:00:00Z`)
 	subscriberAPIVersion         = fmt.Sprintf("%s/%s", subscriberGroup, subscriberVersion)
 	subscriberGVK                = metav1.GroupVersionKind{
 		Group:   subscriberGroup,
 		Version: subscriberVersion,
 		Kind:    subscriberKind,
 	}
+	addressableDeliverySpec = &eventingduckv1beta1.DeliverySpec{
+		BackoffDelay:  &backoffDelay,
+		BackoffPolicy: &backoffPolicy,
+		Retry:         &retry,
+		DeadLetterSink: &duckv1.Destination{
+			Ref: &duckv1.KReference{
+				APIVersion: subscriberAPIVersion,
+				Kind:       subscriberKind,
+				Name:       subscriberName,
+			},
+		},
+	}
+	triggerBackoffDelay = "PT10S"
+	triggerDeliverySpec = &eventingduckv1beta1.DeliverySpec{
+		BackoffDelay:  &triggerBackoffDelay,
+		BackoffPolicy: &backoffPolicy,
+	}
 	brokerDeliverySpec = &eventingduckv1beta1.DeliverySpec{
 		BackoffDelay:  &backoffDelay,
 		BackoffPolicy: &backoffPolicy,
@@ -265,6 +286,7 @@ func TestAllCasesTrigger(t *testing.T) {
 					WithTriggerDependencyReady,
 					WithTriggerSubscriberResolvedSucceeded,
 					WithTriggerStatusSubscriberURI(subscriberURI),
+					WithTriggerDeadLetterSinkResolvedSucceeded(""),
 					WithTriggerSetDefaults,
 				),
 			}},
@@ -348,6 +370,7 @@ func TestAllCasesTrigger(t *testing.T) {
 					WithTriggerDependencyReady,
 					WithTriggerSubscriberResolvedSucceeded,
 					WithTriggerStatusSubscriberURI(subscriberURI),
+					WithTriggerDeadLetterSinkResolvedSucceeded(""),
 					WithTriggerSetDefaults,
 				),
 			}},
@@ -385,6 +408,249 @@ func TestAllCasesTrigger(t *testing.T) {
 				}),
 			},
 		},
+		{
+			Name: "Replay time set, trigger subscription is seeked",
+			Key:  testKey,
+			Objects: []runtime.Object{
+				NewBroker(brokerName, testNS,
+					WithBrokerClass(brokerv1beta1.BrokerClass),
+					WithInitBrokerConditions,
+					WithBrokerReady("url"),
+					WithBrokerDeliverySpec(brokerDeliverySpec),
+					WithBrokerSetDefaults,
+				),
+				makeSubscriberAddressableAsUnstructured(),
+				NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(testUID),
+					WithTriggerAnnotation(brokerv1beta1.ReplayTimeAnnotationKey, replayTime.Format(time.RFC3339)),
+					WithTriggerSubscriberRef(subscriberGVK, subscriberName, testNS),
+					WithTriggerSetDefaults),
+			},
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(testUID),
+					WithTriggerAnnotation(brokerv1beta1.ReplayTimeAnnotationKey, replayTime.Format(time.RFC3339)),
+					WithTriggerSubscriberRef(subscriberGVK, subscriberName, testNS),
+					WithTriggerBrokerReady,
+					WithTriggerSubscriptionReady,
+					WithTriggerTopicReady,
+					WithTriggerDependencyReady,
+					WithTriggerSubscriberResolvedSucceeded,
+					WithTriggerStatusSubscriberURI(subscriberURI),
+					WithTriggerDeadLetterSinkResolvedSucceeded(""),
+					WithTriggerReplayed(replayTime),
+					WithTriggerSetDefaults,
+				),
+			}},
+			WantEvents: []string{
+				triggerFinalizerUpdatedEvent,
+				topicCreatedEvent,
+				subscriptionCreatedEvent,
+				subscriptionSeekedEvent,
+				triggerReconciledEvent,
+			},
+			WantPatches: []clientgotesting.PatchActionImpl{
+				patchFinalizers(testNS, triggerName, finalizerName),
+			},
+			OtherTestData: map[string]interface{}{
+				"pre": []PubsubAction{
+					Topic("test-dead-letter-topic-id"),
+				},
+			},
+			PostConditions: []func(*testing.T, *TableRow){
+				OnlySubscriptions("cre-tgr_testnamespace_test-trigger_abc123"),
+			},
+		},
+		{
+			Name: "Replay time already replayed, trigger subscription isn't seeked again",
+			Key:  testKey,
+			Objects: []runtime.Object{
+				NewBroker(brokerName, testNS,
+					WithBrokerClass(brokerv1beta1.BrokerClass),
+					WithInitBrokerConditions,
+					WithBrokerReady("url"),
+					WithBrokerDeliverySpec(brokerDeliverySpec),
+					WithBrokerSetDefaults,
+				),
+				makeSubscriberAddressableAsUnstructured(),
+				NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(testUID),
+					WithTriggerFinalizers(finalizerName),
+					WithTriggerAnnotation(brokerv1beta1.ReplayTimeAnnotationKey, replayTime.Format(time.RFC3339)),
+					WithTriggerReplayed(replayTime),
+					WithTriggerSubscriberRef(subscriberGVK, subscriberName, testNS),
+					WithTriggerSetDefaults),
+			},
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(testUID),
+					WithTriggerFinalizers(finalizerName),
+					WithTriggerAnnotation(brokerv1beta1.ReplayTimeAnnotationKey, replayTime.Format(time.RFC3339)),
+					WithTriggerReplayed(replayTime),
+					WithTriggerSubscriberRef(subscriberGVK, subscriberName, testNS),
+					WithTriggerBrokerReady,
+					WithTriggerSubscriptionReady,
+					WithTriggerTopicReady,
+					WithTriggerDependencyReady,
+					WithTriggerSubscriberResolvedSucceeded,
+					WithTriggerStatusSubscriberURI(subscriberURI),
+					WithTriggerDeadLetterSinkResolvedSucceeded(""),
+					WithTriggerSetDefaults,
+				),
+			}},
+			WantEvents: []string{
+				triggerReconciledEvent,
+			},
+			OtherTestData: map[string]interface{}{
+				"pre": []PubsubAction{
+					Topic("test-dead-letter-topic-id"),
+					TopicAndSub("cre-tgr_testnamespace_test-trigger_abc123", "cre-tgr_testnamespace_test-trigger_abc123"),
+				},
+			},
+		},
+		{
+			Name: "Trigger delivery spec overrides the broker's",
+			Key:  testKey,
+			Objects: []runtime.Object{
+				NewBroker(brokerName, testNS,
+					WithBrokerClass(brokerv1beta1.BrokerClass),
+					WithInitBrokerConditions,
+					WithBrokerReady("url"),
+					WithBrokerDeliverySpec(brokerDeliverySpec),
+					WithBrokerSetDefaults,
+				),
+				makeSubscriberAddressableAsUnstructured(),
+				NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(testUID),
+					WithTriggerSubscriberRef(subscriberGVK, subscriberName, testNS),
+					WithTriggerDeliverySpec(triggerDeliverySpec),
+					WithTriggerSetDefaults),
+			},
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(testUID),
+					WithTriggerSubscriberRef(subscriberGVK, subscriberName, testNS),
+					WithTriggerDeliverySpec(triggerDeliverySpec),
+					WithTriggerBrokerReady,
+					WithTriggerSubscriptionReady,
+					WithTriggerTopicReady,
+					WithTriggerDependencyReady,
+					WithTriggerSubscriberResolvedSucceeded,
+					WithTriggerStatusSubscriberURI(subscriberURI),
+					WithTriggerDeadLetterSinkResolvedSucceeded(""),
+					WithTriggerSetDefaults,
+				),
+			}},
+			WantEvents: []string{
+				triggerFinalizerUpdatedEvent,
+				topicCreatedEvent,
+				subscriptionCreatedEvent,
+				triggerReconciledEvent,
+			},
+			WantPatches: []clientgotesting.PatchActionImpl{
+				patchFinalizers(testNS, triggerName, finalizerName),
+			},
+			OtherTestData: map[string]interface{}{},
+			PostConditions: []func(*testing.T, *TableRow){
+				OnlyTopics("cre-tgr_testnamespace_test-trigger_abc123"),
+				OnlySubscriptions("cre-tgr_testnamespace_test-trigger_abc123"),
+				SubscriptionHasRetryPolicy("cre-tgr_testnamespace_test-trigger_abc123",
+					&pubsub.RetryPolicy{
+						MaximumBackoff: 10 * time.Second,
+						MinimumBackoff: 10 * time.Second,
+					}),
+				SubscriptionHasDeadLetterPolicy("cre-tgr_testnamespace_test-trigger_abc123", nil),
+			},
+		},
+		{
+			Name: "Addressable dead letter sink is resolved",
+			Key:  testKey,
+			Objects: []runtime.Object{
+				NewBroker(brokerName, testNS,
+					WithBrokerClass(brokerv1beta1.BrokerClass),
+					WithInitBrokerConditions,
+					WithBrokerReady("url"),
+					WithBrokerDeliverySpec(addressableDeliverySpec),
+					WithBrokerSetDefaults,
+				),
+				makeSubscriberAddressableAsUnstructured(),
+				NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(testUID),
+					WithTriggerSubscriberRef(subscriberGVK, subscriberName, testNS),
+					WithTriggerSetDefaults),
+			},
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(testUID),
+					WithTriggerSubscriberRef(subscriberGVK, subscriberName, testNS),
+					WithTriggerBrokerReady,
+					WithTriggerSubscriptionReady,
+					WithTriggerTopicReady,
+					WithTriggerDependencyReady,
+					WithTriggerSubscriberResolvedSucceeded,
+					WithTriggerStatusSubscriberURI(subscriberURI),
+					WithTriggerDeadLetterSinkResolvedSucceeded(subscriberURI),
+					WithTriggerSetDefaults,
+				),
+			}},
+			WantEvents: []string{
+				triggerFinalizerUpdatedEvent,
+				topicCreatedEvent,
+				subscriptionCreatedEvent,
+				triggerReconciledEvent,
+			},
+			WantPatches: []clientgotesting.PatchActionImpl{
+				patchFinalizers(testNS, triggerName, finalizerName),
+			},
+			OtherTestData: map[string]interface{}{},
+			PostConditions: []func(*testing.T, *TableRow){
+				OnlyTopics("cre-tgr_testnamespace_test-trigger_abc123"),
+				OnlySubscriptions("cre-tgr_testnamespace_test-trigger_abc123"),
+				SubscriptionHasRetryPolicy("cre-tgr_testnamespace_test-trigger_abc123",
+					&pubsub.RetryPolicy{
+						MaximumBackoff: 5 * time.Second,
+						MinimumBackoff: 5 * time.Second,
+					}),
+				SubscriptionHasDeadLetterPolicy("cre-tgr_testnamespace_test-trigger_abc123", nil),
+			},
+		},
+		{
+			Name: "Addressable dead letter sink doesn't exist",
+			Key:  testKey,
+			Objects: []runtime.Object{
+				NewBroker(brokerName, testNS,
+					WithBrokerClass(brokerv1beta1.BrokerClass),
+					WithInitBrokerConditions,
+					WithBrokerReady("url"),
+					WithBrokerDeliverySpec(addressableDeliverySpec),
+					WithBrokerSetDefaults,
+				),
+				NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(testUID),
+					WithTriggerSubscriberURI(subscriberURI),
+					WithTriggerSetDefaults),
+			},
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(testUID),
+					WithTriggerSubscriberURI(subscriberURI),
+					WithInitTriggerConditions,
+					WithTriggerBrokerReady,
+					WithTriggerSubscriberResolvedSucceeded,
+					WithTriggerStatusSubscriberURI(subscriberURI),
+					WithTriggerDeadLetterSinkResolvedFailed("Unable to get the dead letter sink's URI", `services.serving.knative.dev "subscriber-name" not found`),
+					WithTriggerSetDefaults,
+				),
+			}},
+			WantEvents: []string{
+				triggerFinalizerUpdatedEvent,
+				Eventf(corev1.EventTypeWarning, "InternalError", `services.serving.knative.dev "subscriber-name" not found`),
+			},
+			WantPatches: []clientgotesting.PatchActionImpl{
+				patchFinalizers(testNS, triggerName, finalizerName),
+			},
+			WantErr: true,
+		},
 		{
 			Name: "Check topic config and labels",
 			Key:  testKey,
@@ -412,6 +678,7 @@ func TestAllCasesTrigger(t *testing.T) {
 					WithTriggerDependencyReady,
 					WithTriggerSubscriberResolvedSucceeded,
 					WithTriggerStatusSubscriberURI(subscriberURI),
+					WithTriggerDeadLetterSinkResolvedSucceeded(""),
 					WithTriggerSetDefaults,
 				),
 			}},
@@ -480,14 +747,16 @@ func TestAllCasesTrigger(t *testing.T) {
 		ctx = conditions.WithDuck(ctx)
 
 		r := &Reconciler{
-			Base:               reconciler.NewBase(ctx, controllerAgentName, cmw),
-			brokerLister:       listers.GetBrokerLister(),
-			kresourceTracker:   duck.NewListableTracker(ctx, conditions.Get, func(types.NamespacedName) {}, 0),
-			addressableTracker: duck.NewListableTracker(ctx, addressable.Get, func(types.NamespacedName) {}, 0),
-			uriResolver:        resolver.NewURIResolver(ctx, func(types.NamespacedName) {}),
-			projectID:          testProject,
-			pubsubClient:       psclient,
-			dataresidencyStore: drStore,
+			Base:                   reconciler.NewBase(ctx, controllerAgentName, cmw),
+			brokerLister:           listers.GetBrokerLister(),
+			brokerCellLister:       listers.GetBrokerCellLister(),
+			newRedisQueueDeleterFn: reconcilerutilsredis.NewQueueDeleterFn(listers.GetSecretLister()),
+			kresourceTracker:       duck.NewListableTracker(ctx, conditions.Get, func(types.NamespacedName) {}, 0),
+			addressableTracker:     duck.NewListableTracker(ctx, addressable.Get, func(types.NamespacedName) {}, 0),
+			uriResolver:            resolver.NewURIResolver(ctx, func(types.NamespacedName) {}),
+			projectID:              testProject,
+			pubsubClient:           psclient,
+			dataresidencyStore:     drStore,
 		}
 
 		return triggerreconciler.NewReconciler(ctx, r.Logger, r.RunClientSet, listers.GetTriggerLister(), r.Recorder, r, withAgentAndFinalizer(nil))
@@ -19,6 +19,7 @@ package pubsub
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"cloud.google.com/go/pubsub"
 	"github.com/google/knative-gcp/pkg/logging"
@@ -33,6 +34,8 @@ const (
 	deletedTopic = "_deleted-topic_"
 	subCreated   = "SubscriptionCreated"
 	subDeleted   = "SubscriptionDeleted"
+	subUpdated   = "SubscriptionUpdated"
+	subSeeked    = "SubscriptionSeeked"
 )
 
 func (r *Reconciler) ReconcileSubscription(ctx context.Context, id string, subConfig pubsub.SubscriptionConfig, obj runtime.Object, updater StatusUpdater) (*pubsub.Subscription, error) {
@@ -64,6 +67,12 @@ func (r *Reconciler) ReconcileSubscription(ctx context.Context, id string, subCo
 			}
 			return r.createSubscription(ctx, id, subConfig, obj, updater)
 		}
+		if retentionChanged(config, subConfig) {
+			if err := r.updateRetention(ctx, sub, subConfig, obj); err != nil {
+				updater.MarkSubscriptionFailed("SubscriptionUpdateFailed", "Failed to update Pub/Sub subscription retention: %v", err)
+				return nil, err
+			}
+		}
 		updater.MarkSubscriptionReady()
 		return sub, nil
 	}
@@ -71,6 +80,18 @@ func (r *Reconciler) ReconcileSubscription(ctx context.Context, id string, subCo
 	return r.createSubscription(ctx, id, subConfig, obj, updater)
 }
 
+// SeekSubscription seeks the subscription to the given time: the retained messages published
+// after it are marked as unacknowledged, and delivered again.
+func (r *Reconciler) SeekSubscription(ctx context.Context, sub *pubsub.Subscription, t time.Time, obj runtime.Object) error {
+	if err := sub.SeekToTime(ctx, t); err != nil {
+		logging.FromContext(ctx).Error("Failed to seek Pub/Sub subscription", zap.String("name", sub.ID()), zap.Time("time", t), zap.Error(err))
+		return err
+	}
+	logging.FromContext(ctx).Info("Seeked PubSub subscription", zap.String("name", sub.ID()), zap.Time("time", t))
+	r.recorder.Eventf(obj, corev1.EventTypeNormal, subSeeked, "Seeked PubSub subscription %q to %s", sub.ID(), t.Format(time.RFC3339Nano))
+	return nil
+}
+
 func (r *Reconciler) DeleteSubscription(ctx context.Context, id string, obj runtime.Object, updater StatusUpdater) error {
 	logger := logging.FromContext(ctx)
 	logger.Debug("Deleting decoupling sub")
@@ -116,3 +137,26 @@ func (r *Reconciler) createSubscription(ctx context.Context, id string, subConfi
 	updater.MarkSubscriptionReady()
 	return sub, nil
 }
+
+// retentionChanged returns true if the retention of acknowledged messages of the existing
+// subscription differs from the desired one. The retention duration is left as is when the
+// desired one is unset.
+func retentionChanged(got pubsub.SubscriptionConfig, want pubsub.SubscriptionConfig) bool {
+	return got.RetainAckedMessages != want.RetainAckedMessages ||
+		(want.RetentionDuration != 0 && got.RetentionDuration != want.RetentionDuration)
+}
+
+func (r *Reconciler) updateRetention(ctx context.Context, sub *pubsub.Subscription, subConfig pubsub.SubscriptionConfig, obj runtime.Object) error {
+	logger := logging.FromContext(ctx)
+	update := pubsub.SubscriptionConfigToUpdate{
+		RetainAckedMessages: subConfig.RetainAckedMessages,
+		RetentionDuration:   subConfig.RetentionDuration,
+	}
+	if _, err := sub.Update(ctx, update); err != nil {
+		logger.Error("Failed to update Pub/Sub subscription retention", zap.Error(err))
+		return err
+	}
+	logger.Info("Updated PubSub subscription retention", zap.String("name", sub.ID()))
+	r.recorder.Eventf(obj, corev1.EventTypeNormal, subUpdated, "Updated PubSub subscription %q", sub.ID())
+	return nil
+}
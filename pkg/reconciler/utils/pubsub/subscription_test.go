@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/pubsub"
 	corev1 "k8s.io/api/core/v1"
@@ -78,6 +79,51 @@ func TestReconcileSub(t *testing.T) {
 
 }
 
+func TestReconcileSubRetention(t *testing.T) {
+	tr, cleanup := newTestRunner(t, testCase{
+		pre:        []reconcilertesting.PubsubAction{reconcilertesting.TopicAndSub(topic, sub)},
+		wantEvents: []string{`Normal SubscriptionUpdated Updated PubSub subscription "test-sub"`},
+	})
+	defer cleanup()
+	r := NewReconciler(tr.client, tr.recorder)
+	su := &utilspubsubtesting.StatusUpdater{}
+	subConfig := pubsub.SubscriptionConfig{
+		Topic:               tr.client.Topic(topic),
+		RetainAckedMessages: true,
+		RetentionDuration:   time.Hour,
+	}
+	res, err := r.ReconcileSubscription(context.Background(), sub, subConfig, obj, su)
+	if err != nil {
+		t.Fatalf("Failed to reconcile sub: %v", err)
+	}
+	if got := <-tr.recorder.Events; got != `Normal SubscriptionUpdated Updated PubSub subscription "test-sub"` {
+		t.Errorf("Unexpected event recorded: %v", got)
+	}
+	gotConfig, err := res.Config(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to get config: %v", err)
+	}
+	if !gotConfig.RetainAckedMessages || gotConfig.RetentionDuration != time.Hour {
+		t.Errorf("Unexpected retention, got: %v %v, want: true %v", gotConfig.RetainAckedMessages, gotConfig.RetentionDuration, time.Hour)
+	}
+}
+
+func TestSeekSub(t *testing.T) {
+	tr, cleanup := newTestRunner(t, testCase{
+		pre:        []reconcilertesting.PubsubAction{reconcilertesting.TopicAndSub(topic, sub)},
+		wantEvents: []string{`Normal SubscriptionSeeked Seeked PubSub subscription "test-sub" to 2020-09-01T10:00:00Z`},
+	})
+	defer cleanup()
+	r := NewReconciler(tr.client, tr.recorder)
+	seekTime := time.Date(2020, 9, 1, 10, 0, 0, 0, time.UTC)
+	if err := r.SeekSubscription(context.Background(), tr.client.Subscription(sub), seekTime, obj); err != nil {
+		t.Fatalf("Failed to seek sub: %v", err)
+	}
+	if got := <-tr.recorder.Events; got != `Normal SubscriptionSeeked Seeked PubSub subscription "test-sub" to 2020-09-01T10:00:00Z` {
+		t.Errorf("Unexpected event recorded: %v", got)
+	}
+}
+
 func TestDeleteSub(t *testing.T) {
 	tests := []testCase{
 		{
@@ -0,0 +1,116 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package redis contains helpers for the reconcilers using Redis streams.
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/multierr"
+	corev1 "k8s.io/api/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+	"github.com/google/knative-gcp/pkg/reconciler/brokercell/resources"
+	redisstreamresources "github.com/google/knative-gcp/pkg/reconciler/intevents/redisstreamsource/resources"
+	"github.com/google/knative-gcp/pkg/redis"
+)
+
+// NewClient connects to the Redis instance of the connection, reading the
+// password and TLS certificates from the secrets of the namespace.
+func NewClient(secretLister corev1listers.SecretLister, namespace string, conn *intv1alpha1.RedisConnection) (*redis.Client, error) {
+	opts := redis.Options{Address: conn.Address}
+	if sel := redisstreamresources.PasswordSecretKeySelector(conn); sel != nil {
+		password, err := secretValue(secretLister, namespace, sel)
+		if err != nil {
+			return nil, err
+		}
+		opts.Password = password
+	}
+	if o := conn.Options; o != nil && o.UseTLS {
+		var values [3]string
+		for i, v := range []intv1alpha1.RedisSecretValueFromSource{o.Cert, o.Key, o.CACert} {
+			if v.SecretKeyRef == nil {
+				continue
+			}
+			value, err := secretValue(secretLister, namespace, v.SecretKeyRef)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		tlsConfig, err := redis.TLSConfig(o.SkipVerify, values[0], values[1], values[2])
+		if err != nil {
+			return nil, err
+		}
+		opts.TLSConfig = tlsConfig
+	}
+	return redis.NewClient(opts), nil
+}
+
+func secretValue(secretLister corev1listers.SecretLister, namespace string, sel *corev1.SecretKeySelector) (string, error) {
+	secret, err := secretLister.Secrets(namespace).Get(sel.Name)
+	if err != nil {
+		return "", err
+	}
+	value, ok := secret.Data[sel.Key]
+	if !ok {
+		return "", fmt.Errorf("key %q not found in secret %s/%s", sel.Key, namespace, sel.Name)
+	}
+	return string(value), nil
+}
+
+// QueueDeleter deletes the streams and consumer groups used as queues. It is
+// implemented by redis.Client.
+type QueueDeleter interface {
+	DestroyGroup(ctx context.Context, stream, group string) error
+	DeleteStream(ctx context.Context, stream string) error
+	Close() error
+}
+
+// QueueDeleterFn creates the QueueDeleter of the queues of the BrokerCell,
+// or returns nil if its queues aren't Redis streams.
+type QueueDeleterFn func(ctx context.Context, bc *intv1alpha1.BrokerCell) (QueueDeleter, error)
+
+// NewQueueDeleterFn returns a QueueDeleterFn connecting to the Redis instance
+// of the BrokerCell with the secrets listed by secretLister.
+func NewQueueDeleterFn(secretLister corev1listers.SecretLister) QueueDeleterFn {
+	return func(ctx context.Context, bc *intv1alpha1.BrokerCell) (QueueDeleter, error) {
+		conn, err := resources.RedisQueueConnection(ctx, bc)
+		if conn == nil || err != nil {
+			return nil, err
+		}
+		return NewClient(secretLister, bc.Namespace, conn)
+	}
+}
+
+// DeleteQueues destroys the consumer group of each queue, then deletes its
+// stream. The queues map the names of the streams to the names of their
+// groups, which are the names of the Pub/Sub topics and subscriptions the
+// queues stand for.
+func DeleteQueues(ctx context.Context, d QueueDeleter, queues map[string]string) error {
+	var err error
+	for stream, group := range queues {
+		if e := d.DestroyGroup(ctx, stream, group); e != nil {
+			err = multierr.Append(err, e)
+			continue
+		}
+		err = multierr.Append(err, d.DeleteStream(ctx, stream))
+	}
+	return err
+}
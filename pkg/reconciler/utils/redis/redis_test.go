@@ -0,0 +1,122 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+	redistesting "github.com/google/knative-gcp/pkg/redis/testing"
+)
+
+func newSecretLister(t *testing.T, secrets ...*corev1.Secret) corev1listers.SecretLister {
+	t.Helper()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, s := range secrets {
+		if err := indexer.Add(s); err != nil {
+			t.Fatalf("Failed to add secret: %v", err)
+		}
+	}
+	return corev1listers.NewSecretLister(indexer)
+}
+
+func TestNewClient(t *testing.T) {
+	srv, err := redistesting.NewServer("secret")
+	if err != nil {
+		t.Fatalf("Failed to start Redis server: %v", err)
+	}
+	defer srv.Close()
+
+	conn := &intv1alpha1.RedisConnection{
+		Address: srv.Addr(),
+		Options: &intv1alpha1.RedisConnectionOptions{
+			Password: corev1.ObjectReference{Name: "redis"},
+		},
+	}
+	secretLister := newSecretLister(t, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "redis"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	})
+
+	c, err := NewClient(secretLister, "ns", conn)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer c.Close()
+	if err := c.Ping(context.Background()); err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+
+	// The secret is looked up in the given namespace.
+	if _, err := NewClient(secretLister, "other", conn); err == nil {
+		t.Error("NewClient succeeded without the secret, want error")
+	}
+}
+
+// fakeQueueDeleter records the groups and streams it deletes.
+type fakeQueueDeleter struct {
+	destroyErr error
+	destroyed  []string
+	deleted    []string
+}
+
+func (d *fakeQueueDeleter) DestroyGroup(_ context.Context, stream, group string) error {
+	if d.destroyErr != nil {
+		return d.destroyErr
+	}
+	d.destroyed = append(d.destroyed, stream+"/"+group)
+	return nil
+}
+
+func (d *fakeQueueDeleter) DeleteStream(_ context.Context, stream string) error {
+	d.deleted = append(d.deleted, stream)
+	return nil
+}
+
+func (d *fakeQueueDeleter) Close() error {
+	return nil
+}
+
+func TestDeleteQueues(t *testing.T) {
+	d := &fakeQueueDeleter{}
+	if err := DeleteQueues(context.Background(), d, map[string]string{"topic": "sub"}); err != nil {
+		t.Fatalf("DeleteQueues failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"topic/sub"}, d.destroyed); diff != "" {
+		t.Errorf("unexpected destroyed groups (-want, +got) = %v", diff)
+	}
+	if diff := cmp.Diff([]string{"topic"}, d.deleted); diff != "" {
+		t.Errorf("unexpected deleted streams (-want, +got) = %v", diff)
+	}
+
+	// The stream is kept when its group can't be destroyed.
+	d = &fakeQueueDeleter{destroyErr: errors.New("connection refused")}
+	if err := DeleteQueues(context.Background(), d, map[string]string{"topic": "sub"}); err == nil {
+		t.Error("DeleteQueues succeeded, want error")
+	}
+	if len(d.deleted) != 0 {
+		t.Errorf("unexpected deleted streams %v", d.deleted)
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDialTimeout = 10 * time.Second
+	defaultMaxIdle     = 4
+)
+
+// ErrClosed is returned when using a closed Client.
+var ErrClosed = errors.New("redis: client is closed")
+
+// Options are the options to connect to a Redis instance.
+type Options struct {
+	// Address is the TCP address of the Redis instance, in the host:port form.
+	Address string
+
+	// Password, if set, is used to authenticate the connections.
+	Password string
+
+	// TLSConfig, if set, enables TLS using the given configuration.
+	TLSConfig *tls.Config
+
+	// DialTimeout is the timeout to establish a connection. Defaults to 10s.
+	DialTimeout time.Duration
+
+	// MaxIdle is the maximum number of idle connections kept open. Defaults
+	// to 4.
+	MaxIdle int
+}
+
+// Client is a Redis client. It is safe for concurrent use and keeps a pool
+// of connections, so that a blocking command doesn't hold up the others.
+type Client struct {
+	opts Options
+
+	mu     sync.Mutex
+	idle   []*conn
+	closed bool
+}
+
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+// NewClient creates a Client for the given options. Connections are
+// established lazily.
+func NewClient(opts Options) *Client {
+	if opts.DialTimeout == 0 {
+		opts.DialTimeout = defaultDialTimeout
+	}
+	if opts.MaxIdle == 0 {
+		opts.MaxIdle = defaultMaxIdle
+	}
+	return &Client{opts: opts}
+}
+
+// Close closes the idle connections. Connections in use are closed when they
+// are released.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	for _, cn := range c.idle {
+		cn.Close()
+	}
+	c.idle = nil
+	return nil
+}
+
+// Ping checks the connectivity to the Redis instance.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+	return err
+}
+
+// Do runs a command and returns its reply as decoded by readReply. An error
+// reply is returned as an Error.
+func (c *Client) Do(ctx context.Context, args ...string) (interface{}, error) {
+	return c.do(ctx, 0, args...)
+}
+
+// do runs a command that may block on the server for up to block.
+func (c *Client) do(ctx context.Context, block time.Duration, args ...string) (interface{}, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok && block > 0 {
+		// Give the server some slack to reply after the block timeout.
+		deadline = time.Now().Add(block + c.opts.DialTimeout)
+	}
+	if err := cn.SetDeadline(deadline); err != nil {
+		cn.Close()
+		return nil, err
+	}
+
+	// Unblock the connection if the context is cancelled while waiting
+	// for the reply.
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			cn.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+	reply, err := cn.roundTrip(args)
+	close(done)
+	<-stopped
+
+	var rerr Error
+	switch {
+	case errors.As(err, &rerr):
+		// The connection is still usable after an error reply.
+		c.put(cn)
+	case err != nil:
+		cn.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	default:
+		c.put(cn)
+	}
+	return reply, err
+}
+
+func (cn *conn) roundTrip(args []string) (interface{}, error) {
+	if err := writeCommand(cn.w, args); err != nil {
+		return nil, err
+	}
+	return readReply(cn.r)
+}
+
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, ErrClosed
+	}
+	if n := len(c.idle); n > 0 {
+		cn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return cn, nil
+	}
+	c.mu.Unlock()
+	return c.dial(ctx)
+}
+
+func (c *Client) put(cn *conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || len(c.idle) >= c.opts.MaxIdle {
+		cn.Close()
+		return
+	}
+	c.idle = append(c.idle, cn)
+}
+
+func (c *Client) dial(ctx context.Context) (*conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.DialTimeout)
+	defer cancel()
+
+	var d net.Dialer
+	nc, err := d.DialContext(ctx, "tcp", c.opts.Address)
+	if err != nil {
+		return nil, err
+	}
+	if c.opts.TLSConfig != nil {
+		tc := tls.Client(nc, c.opts.TLSConfig)
+		if deadline, ok := ctx.Deadline(); ok {
+			tc.SetDeadline(deadline)
+		}
+		if err := tc.Handshake(); err != nil {
+			nc.Close()
+			return nil, err
+		}
+		nc = tc
+	}
+	cn := &conn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+	if c.opts.Password != "" {
+		if deadline, ok := ctx.Deadline(); ok {
+			cn.SetDeadline(deadline)
+		}
+		if _, err := cn.roundTrip([]string{"AUTH", c.opts.Password}); err != nil {
+			cn.Close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
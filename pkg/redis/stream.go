@@ -0,0 +1,275 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// NewMessages is the ID to read messages never delivered to any consumer
+	// of the group.
+	NewMessages = ">"
+
+	// PendingMessages is the ID to read the messages delivered to the
+	// consumer but not acknowledged yet.
+	PendingMessages = "0"
+
+	// LastMessage is the ID to start a group at the end of the stream.
+	LastMessage = "$"
+)
+
+// Message is a stream entry.
+type Message struct {
+	// ID is the ID of the entry in the stream.
+	ID string
+
+	// Fields are the field-value pairs of the entry, in order. Fields is nil
+	// for a pending entry that was deleted from the stream.
+	Fields []string
+}
+
+// Add appends an entry with the given field-value pairs to the stream,
+// creating the stream if it doesn't exist. It returns the ID of the entry.
+// If maxLen is positive, the oldest entries are trimmed to keep about maxLen
+// entries in the stream. The trimming is approximate (MAXLEN ~), so that
+// Redis only removes whole nodes of the stream, which is much cheaper.
+func (c *Client) Add(ctx context.Context, stream string, maxLen int64, fields ...string) (string, error) {
+	args := []string{"XADD", stream}
+	if maxLen > 0 {
+		args = append(args, "MAXLEN", "~", strconv.FormatInt(maxLen, 10))
+	}
+	args = append(append(args, "*"), fields...)
+	reply, err := c.Do(ctx, args...)
+	if err != nil {
+		return "", err
+	}
+	id, ok := reply.(string)
+	if !ok {
+		return "", fmt.Errorf("redis: unexpected XADD reply %v", reply)
+	}
+	return id, nil
+}
+
+// CreateGroup creates a consumer group starting at the given ID, creating
+// the stream if it doesn't exist. It succeeds if the group already exists.
+func (c *Client) CreateGroup(ctx context.Context, stream, group, id string) error {
+	_, err := c.Do(ctx, "XGROUP", "CREATE", stream, group, id, "MKSTREAM")
+	if isBusyGroup(err) {
+		return nil
+	}
+	return err
+}
+
+// DestroyGroup destroys a consumer group. It succeeds if the group doesn't
+// exist.
+func (c *Client) DestroyGroup(ctx context.Context, stream, group string) error {
+	_, err := c.Do(ctx, "XGROUP", "DESTROY", stream, group)
+	if err != nil && strings.Contains(err.Error(), "no such key") {
+		// The stream is gone, and the group with it.
+		return nil
+	}
+	return err
+}
+
+// DeleteStream deletes the stream with its entries and consumer groups. It
+// succeeds if the stream doesn't exist.
+func (c *Client) DeleteStream(ctx context.Context, stream string) error {
+	_, err := c.Do(ctx, "DEL", stream)
+	return err
+}
+
+// ReadGroup reads up to count messages from the stream for the consumer of the
+// group, starting after the given ID. Use NewMessages to read new messages,
+// and PendingMessages to read the unacknowledged messages of the consumer.
+// If block is positive, it waits up to block for new messages and returns
+// no messages if none arrived.
+func (c *Client) ReadGroup(ctx context.Context, stream, group, consumer, id string, count int, block time.Duration) ([]Message, error) {
+	args := []string{"XREADGROUP", "GROUP", group, consumer, "COUNT", strconv.Itoa(count)}
+	if block > 0 {
+		args = append(args, "BLOCK", strconv.FormatInt(block.Milliseconds(), 10))
+	}
+	args = append(args, "STREAMS", stream, id)
+	reply, err := c.do(ctx, block, args...)
+	if err != nil || reply == nil {
+		return nil, err
+	}
+	return parseReadReply(reply)
+}
+
+// Ack acknowledges messages of the group. It returns the number of messages
+// that were pending and are now acknowledged.
+func (c *Client) Ack(ctx context.Context, stream, group string, ids ...string) (int64, error) {
+	args := append([]string{"XACK", stream, group}, ids...)
+	reply, err := c.Do(ctx, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("redis: unexpected XACK reply %v", reply)
+	}
+	return n, nil
+}
+
+// PendingMessage is a message delivered to a consumer of a group but not
+// acknowledged yet.
+type PendingMessage struct {
+	// ID is the ID of the entry in the stream.
+	ID string
+
+	// Consumer is the consumer the message was last delivered to.
+	Consumer string
+
+	// Idle is the time elapsed since the message was last delivered.
+	Idle time.Duration
+
+	// Deliveries is the number of times the message was delivered.
+	Deliveries int64
+}
+
+// Pending returns up to count of the oldest pending messages of the consumer
+// of the group, or of all its consumers if consumer is empty.
+func (c *Client) Pending(ctx context.Context, stream, group, consumer string, count int) ([]PendingMessage, error) {
+	args := []string{"XPENDING", stream, group, "-", "+", strconv.Itoa(count)}
+	if consumer != "" {
+		args = append(args, consumer)
+	}
+	reply, err := c.Do(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	entries, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("redis: unexpected XPENDING reply %v", reply)
+	}
+	pending := make([]PendingMessage, 0, len(entries))
+	for _, e := range entries {
+		entry, ok := e.([]interface{})
+		if !ok || len(entry) != 4 {
+			return nil, fmt.Errorf("redis: unexpected pending entry %v", e)
+		}
+		id, ok1 := entry[0].(string)
+		consumer, ok2 := entry[1].(string)
+		idle, ok3 := entry[2].(int64)
+		deliveries, ok4 := entry[3].(int64)
+		if !ok1 || !ok2 || !ok3 || !ok4 {
+			return nil, fmt.Errorf("redis: unexpected pending entry %v", e)
+		}
+		pending = append(pending, PendingMessage{
+			ID:         id,
+			Consumer:   consumer,
+			Idle:       time.Duration(idle) * time.Millisecond,
+			Deliveries: deliveries,
+		})
+	}
+	return pending, nil
+}
+
+// Claim transfers the pending messages that have been idle for at least
+// minIdle to the consumer of the group, and returns them. Claiming a message
+// delivers it again, incrementing its delivery count. Messages that are not
+// pending or were delivered more recently are not returned.
+func (c *Client) Claim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, ids ...string) ([]Message, error) {
+	args := append([]string{"XCLAIM", stream, group, consumer, strconv.FormatInt(minIdle.Milliseconds(), 10)}, ids...)
+	reply, err := c.Do(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	entries, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("redis: unexpected XCLAIM reply %v", reply)
+	}
+	messages := make([]Message, 0, len(entries))
+	for _, e := range entries {
+		if e == nil {
+			// The entry was deleted from the stream while pending.
+			continue
+		}
+		m, err := parseMessage(e)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+func isBusyGroup(err error) bool {
+	rerr, ok := err.(Error)
+	return ok && strings.HasPrefix(string(rerr), "BUSYGROUP")
+}
+
+// parseReadReply parses the [[stream, [[id, [field, value, ...]], ...]]]
+// reply of XREADGROUP for a single stream.
+func parseReadReply(reply interface{}) ([]Message, error) {
+	streams, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("redis: unexpected XREADGROUP reply %v", reply)
+	}
+	var messages []Message
+	for _, s := range streams {
+		stream, ok := s.([]interface{})
+		if !ok || len(stream) != 2 {
+			return nil, fmt.Errorf("redis: unexpected XREADGROUP stream reply %v", s)
+		}
+		entries, ok := stream[1].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("redis: unexpected XREADGROUP entries reply %v", stream[1])
+		}
+		for _, e := range entries {
+			m, err := parseMessage(e)
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
+
+func parseMessage(e interface{}) (Message, error) {
+	entry, ok := e.([]interface{})
+	if !ok || len(entry) != 2 {
+		return Message{}, fmt.Errorf("redis: unexpected stream entry %v", e)
+	}
+	id, ok := entry[0].(string)
+	if !ok {
+		return Message{}, fmt.Errorf("redis: unexpected stream entry ID %v", entry[0])
+	}
+	m := Message{ID: id}
+	if entry[1] == nil {
+		return m, nil
+	}
+	fields, ok := entry[1].([]interface{})
+	if !ok {
+		return Message{}, fmt.Errorf("redis: unexpected stream entry fields %v", entry[1])
+	}
+	m.Fields = make([]string, 0, len(fields))
+	for _, f := range fields {
+		s, ok := f.(string)
+		if !ok {
+			return Message{}, fmt.Errorf("redis: unexpected stream entry field %v", f)
+		}
+		m.Fields = append(m.Fields, s)
+	}
+	return m, nil
+}
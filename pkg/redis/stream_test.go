@@ -0,0 +1,289 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	redistesting "github.com/google/knative-gcp/pkg/redis/testing"
+)
+
+func newTestClient(t *testing.T, password string) (*Client, *redistesting.Server) {
+	t.Helper()
+	srv, err := redistesting.NewServer(password)
+	if err != nil {
+		t.Fatalf("Failed to start Redis server: %v", err)
+	}
+	c := NewClient(Options{Address: srv.Addr(), Password: password})
+	t.Cleanup(func() {
+		c.Close()
+		srv.Close()
+	})
+	return c, srv
+}
+
+func TestPing(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t, "secret")
+	if err := c.Ping(ctx); err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+
+	bad := NewClient(Options{Address: c.opts.Address, Password: "wrong"})
+	defer bad.Close()
+	if err := bad.Ping(ctx); err == nil {
+		t.Error("Ping with wrong password succeeded, want error")
+	}
+}
+
+func TestGroupLifecycle(t *testing.T) {
+	ctx := context.Background()
+	c, srv := newTestClient(t, "")
+
+	if err := c.CreateGroup(ctx, "stream", "group", LastMessage); err != nil {
+		t.Fatalf("CreateGroup failed: %v", err)
+	}
+	if err := c.CreateGroup(ctx, "stream", "group", LastMessage); err != nil {
+		t.Errorf("CreateGroup on existing group failed: %v", err)
+	}
+	if !srv.HasGroup("stream", "group") {
+		t.Error("group was not created")
+	}
+	if err := c.DestroyGroup(ctx, "stream", "group"); err != nil {
+		t.Errorf("DestroyGroup failed: %v", err)
+	}
+	if srv.HasGroup("stream", "group") {
+		t.Error("group was not destroyed")
+	}
+	if err := c.DestroyGroup(ctx, "missing", "group"); err != nil {
+		t.Errorf("DestroyGroup on missing stream failed: %v", err)
+	}
+}
+
+func TestReadGroupAndAck(t *testing.T) {
+	ctx := context.Background()
+	c, srv := newTestClient(t, "")
+
+	if err := c.CreateGroup(ctx, "stream", "group", LastMessage); err != nil {
+		t.Fatalf("CreateGroup failed: %v", err)
+	}
+	id1 := srv.Add("stream", "a", "1")
+	id2 := srv.Add("stream", "b", "2")
+
+	got, err := c.ReadGroup(ctx, "stream", "group", "consumer", NewMessages, 10, 0)
+	if err != nil {
+		t.Fatalf("ReadGroup failed: %v", err)
+	}
+	want := []Message{{ID: id1, Fields: []string{"a", "1"}}, {ID: id2, Fields: []string{"b", "2"}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected messages (-want, +got) = %v", diff)
+	}
+
+	// Unacknowledged messages are redelivered when reading pending messages.
+	got, err = c.ReadGroup(ctx, "stream", "group", "consumer", PendingMessages, 10, 0)
+	if err != nil {
+		t.Fatalf("ReadGroup pending failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected pending messages (-want, +got) = %v", diff)
+	}
+
+	n, err := c.Ack(ctx, "stream", "group", id1)
+	if err != nil {
+		t.Fatalf("Ack failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Ack = %d, want 1", n)
+	}
+	if diff := cmp.Diff([]string{id2}, srv.Pending("stream", "group")); diff != "" {
+		t.Errorf("unexpected pending IDs (-want, +got) = %v", diff)
+	}
+}
+
+func TestPendingAndClaim(t *testing.T) {
+	ctx := context.Background()
+	c, srv := newTestClient(t, "")
+
+	if err := c.CreateGroup(ctx, "stream", "group", LastMessage); err != nil {
+		t.Fatalf("CreateGroup failed: %v", err)
+	}
+	id := srv.Add("stream", "a", "1")
+	if _, err := c.ReadGroup(ctx, "stream", "group", "consumer", NewMessages, 10, 0); err != nil {
+		t.Fatalf("ReadGroup failed: %v", err)
+	}
+
+	pending, err := c.Pending(ctx, "stream", "group", "consumer", 10)
+	if err != nil {
+		t.Fatalf("Pending failed: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != id || pending[0].Consumer != "consumer" || pending[0].Deliveries != 1 {
+		t.Fatalf("unexpected pending messages %+v", pending)
+	}
+
+	// An entry that isn't idle long enough isn't claimed.
+	got, err := c.Claim(ctx, "stream", "group", "consumer", time.Hour, id)
+	if err != nil {
+		t.Fatalf("Claim failed: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("unexpected claimed messages %v", got)
+	}
+
+	got, err = c.Claim(ctx, "stream", "group", "consumer", 0, id)
+	if err != nil {
+		t.Fatalf("Claim failed: %v", err)
+	}
+	if diff := cmp.Diff([]Message{{ID: id, Fields: []string{"a", "1"}}}, got); diff != "" {
+		t.Errorf("unexpected claimed messages (-want, +got) = %v", diff)
+	}
+
+	// Claiming counts as a delivery.
+	pending, err = c.Pending(ctx, "stream", "group", "consumer", 10)
+	if err != nil {
+		t.Fatalf("Pending failed: %v", err)
+	}
+	if len(pending) != 1 || pending[0].Deliveries != 2 {
+		t.Errorf("unexpected pending messages %+v", pending)
+	}
+
+	// The messages pending for other consumers are listed without a consumer.
+	if _, err := c.Claim(ctx, "stream", "group", "other", 0, id); err != nil {
+		t.Fatalf("Claim failed: %v", err)
+	}
+	pending, err = c.Pending(ctx, "stream", "group", "consumer", 10)
+	if err != nil {
+		t.Fatalf("Pending failed: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("unexpected pending messages %+v", pending)
+	}
+	pending, err = c.Pending(ctx, "stream", "group", "", 10)
+	if err != nil {
+		t.Fatalf("Pending failed: %v", err)
+	}
+	if len(pending) != 1 || pending[0].Consumer != "other" {
+		t.Errorf("unexpected pending messages %+v", pending)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t, "")
+
+	if err := c.CreateGroup(ctx, "stream", "group", LastMessage); err != nil {
+		t.Fatalf("CreateGroup failed: %v", err)
+	}
+	id, err := c.Add(ctx, "stream", 0, "a", "1", "b", "2")
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	got, err := c.ReadGroup(ctx, "stream", "group", "consumer", NewMessages, 10, 0)
+	if err != nil {
+		t.Fatalf("ReadGroup failed: %v", err)
+	}
+	want := []Message{{ID: id, Fields: []string{"a", "1", "b", "2"}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected messages (-want, +got) = %v", diff)
+	}
+}
+
+func TestAddTrims(t *testing.T) {
+	ctx := context.Background()
+	c, srv := newTestClient(t, "")
+
+	for _, v := range []string{"1", "2", "3"} {
+		if _, err := c.Add(ctx, "stream", 2, "a", v); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	if diff := cmp.Diff([][]string{{"a", "2"}, {"a", "3"}}, srv.Entries("stream")); diff != "" {
+		t.Errorf("unexpected entries (-want, +got) = %v", diff)
+	}
+}
+
+func TestDeleteStream(t *testing.T) {
+	ctx := context.Background()
+	c, srv := newTestClient(t, "")
+
+	if err := c.CreateGroup(ctx, "stream", "group", LastMessage); err != nil {
+		t.Fatalf("CreateGroup failed: %v", err)
+	}
+	srv.Add("stream", "a", "1")
+
+	if err := c.DeleteStream(ctx, "stream"); err != nil {
+		t.Fatalf("DeleteStream failed: %v", err)
+	}
+	if got := srv.Entries("stream"); got != nil {
+		t.Errorf("unexpected entries %v after the stream is deleted", got)
+	}
+	// Deleting a stream that doesn't exist succeeds.
+	if err := c.DeleteStream(ctx, "stream"); err != nil {
+		t.Errorf("DeleteStream of a missing stream failed: %v", err)
+	}
+}
+
+func TestReadGroupBlock(t *testing.T) {
+	ctx := context.Background()
+	c, srv := newTestClient(t, "")
+
+	if err := c.CreateGroup(ctx, "stream", "group", LastMessage); err != nil {
+		t.Fatalf("CreateGroup failed: %v", err)
+	}
+
+	got, err := c.ReadGroup(ctx, "stream", "group", "consumer", NewMessages, 10, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("ReadGroup failed: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("ReadGroup returned %v, want no messages", got)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		srv.Add("stream", "a", "1")
+	}()
+	got, err = c.ReadGroup(ctx, "stream", "group", "consumer", NewMessages, 10, 5*time.Second)
+	if err != nil {
+		t.Fatalf("ReadGroup failed: %v", err)
+	}
+	if len(got) != 1 {
+		t.Errorf("ReadGroup returned %v, want 1 message", got)
+	}
+
+	// A cancelled context unblocks the read.
+	cctx, cancel := context.WithCancel(ctx)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := c.ReadGroup(cctx, "stream", "group", "consumer", NewMessages, 10, time.Minute); err != context.Canceled {
+		t.Errorf("ReadGroup error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestReadGroupNoGroup(t *testing.T) {
+	c, _ := newTestClient(t, "")
+	_, err := c.ReadGroup(context.Background(), "stream", "missing", "consumer", NewMessages, 10, 0)
+	if _, ok := err.(Error); !ok {
+		t.Errorf("ReadGroup error = %v, want an Error reply", err)
+	}
+}
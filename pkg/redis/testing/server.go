@@ -0,0 +1,735 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testing provides an in-process stand-in for a Redis server that
+// supports the stream commands used by the redis package.
+package testing
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is a minimal in-memory Redis server speaking RESP. It supports
+// PING, AUTH, DEL, XADD, XGROUP CREATE/DESTROY, XREADGROUP, XACK, XPENDING
+// and XCLAIM.
+type Server struct {
+	ln       net.Listener
+	password string
+
+	mu      sync.Mutex
+	streams map[string]*stream
+	// changed is closed and replaced whenever an entry is added.
+	changed chan struct{}
+	lastMs  int64
+	lastSeq int64
+	conns   map[net.Conn]struct{}
+
+	// done is closed when the server is closed, to unblock pending reads.
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+type streamID struct {
+	ms, seq int64
+}
+
+func (id streamID) String() string {
+	return fmt.Sprintf("%d-%d", id.ms, id.seq)
+}
+
+func (id streamID) less(o streamID) bool {
+	return id.ms < o.ms || (id.ms == o.ms && id.seq < o.seq)
+}
+
+func parseID(s string) (streamID, error) {
+	parts := strings.SplitN(s, "-", 2)
+	ms, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return streamID{}, fmt.Errorf("Invalid stream ID specified as stream command argument")
+	}
+	var seq int64
+	if len(parts) == 2 {
+		if seq, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+			return streamID{}, fmt.Errorf("Invalid stream ID specified as stream command argument")
+		}
+	}
+	return streamID{ms: ms, seq: seq}, nil
+}
+
+type entry struct {
+	id     streamID
+	fields []string
+}
+
+type stream struct {
+	entries []entry
+	groups  map[string]*group
+}
+
+type group struct {
+	lastDelivered streamID
+	// pending maps the IDs of delivered but unacknowledged entries to their
+	// last delivery.
+	pending map[streamID]*delivery
+}
+
+type delivery struct {
+	consumer string
+	time     time.Time
+	count    int64
+}
+
+// deliver records a delivery of the entry to the consumer.
+func (g *group) deliver(id streamID, consumer string) {
+	d, ok := g.pending[id]
+	if !ok {
+		d = &delivery{}
+		g.pending[id] = d
+	}
+	d.consumer = consumer
+	d.time = time.Now()
+	d.count++
+}
+
+// NewServer starts a Server listening on a random local port. If password is
+// not empty, clients must authenticate with it.
+func NewServer(password string) (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		ln:       ln,
+		password: password,
+		streams:  make(map[string]*stream),
+		changed:  make(chan struct{}),
+		conns:    make(map[net.Conn]struct{}),
+		done:     make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the address the server listens on.
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	err := s.ln.Close()
+	s.mu.Lock()
+	close(s.done)
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+// Add adds an entry to the stream, creating it if needed, and returns the
+// entry ID.
+func (s *Server) Add(streamName string, fields ...string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.add(streamName, fields).String()
+}
+
+// Pending returns the IDs of the pending entries of the group, sorted.
+func (s *Server) Pending(streamName, groupName string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g := s.group(streamName, groupName)
+	if g == nil {
+		return nil
+	}
+	ids := make([]streamID, 0, len(g.pending))
+	for id := range g.pending {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].less(ids[j]) })
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, id.String())
+	}
+	return out
+}
+
+// Entries returns the fields of the entries of the stream, in order.
+func (s *Server) Entries(streamName string) [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.streams[streamName]
+	if !ok {
+		return nil
+	}
+	out := make([][]string, 0, len(st.entries))
+	for _, e := range st.entries {
+		out = append(out, e.fields)
+	}
+	return out
+}
+
+// HasGroup returns whether the group exists on the stream.
+func (s *Server) HasGroup(streamName, groupName string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.group(streamName, groupName) != nil
+}
+
+func (s *Server) group(streamName, groupName string) *group {
+	st, ok := s.streams[streamName]
+	if !ok {
+		return nil
+	}
+	return st.groups[groupName]
+}
+
+func (s *Server) add(streamName string, fields []string) streamID {
+	st, ok := s.streams[streamName]
+	if !ok {
+		st = &stream{groups: make(map[string]*group)}
+		s.streams[streamName] = st
+	}
+	ms := time.Now().UnixNano() / int64(time.Millisecond)
+	if ms <= s.lastMs {
+		ms = s.lastMs
+		s.lastSeq++
+	} else {
+		s.lastSeq = 0
+	}
+	s.lastMs = ms
+	id := streamID{ms: ms, seq: s.lastSeq}
+	st.entries = append(st.entries, entry{id: id, fields: fields})
+	close(s.changed)
+	s.changed = make(chan struct{})
+	return id
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[c] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(c)
+		}()
+	}
+}
+
+type reply interface{}
+
+// errorReply is written as a RESP error.
+type errorReply string
+
+// nilReply is written as a RESP null array.
+type nilReply struct{}
+
+// simpleReply is written as a RESP simple string.
+type simpleReply string
+
+func (s *Server) handle(c net.Conn) {
+	defer func() {
+		c.Close()
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+	}()
+	r := bufio.NewReader(c)
+	w := bufio.NewWriter(c)
+	authed := s.password == ""
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		var rep reply
+		switch cmd := strings.ToUpper(args[0]); {
+		case cmd == "AUTH":
+			if len(args) == 2 && args[1] == s.password {
+				authed = true
+				rep = simpleReply("OK")
+			} else {
+				rep = errorReply("WRONGPASS invalid username-password pair")
+			}
+		case !authed:
+			rep = errorReply("NOAUTH Authentication required.")
+		default:
+			rep = s.exec(cmd, args[1:])
+		}
+		if err := writeReply(w, rep); err != nil {
+			return
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) exec(cmd string, args []string) reply {
+	switch cmd {
+	case "PING":
+		return simpleReply("PONG")
+	case "DEL":
+		return s.del(args)
+	case "XADD":
+		return s.xadd(args)
+	case "XGROUP":
+		return s.xgroup(args)
+	case "XREADGROUP":
+		return s.xreadgroup(args)
+	case "XACK":
+		return s.xack(args)
+	case "XPENDING":
+		return s.xpending(args)
+	case "XCLAIM":
+		return s.xclaim(args)
+	default:
+		return errorReply(fmt.Sprintf("ERR unknown command '%s'", cmd))
+	}
+}
+
+func (s *Server) del(args []string) reply {
+	if len(args) < 1 {
+		return errorReply("ERR wrong number of arguments for 'del' command")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int64
+	for _, key := range args {
+		if _, ok := s.streams[key]; ok {
+			delete(s.streams, key)
+			n++
+		}
+	}
+	return n
+}
+
+// xadd supports XADD key [MAXLEN [~|=] count] * field value [field value ...].
+// The stream is trimmed exactly, which approximate trimming allows.
+func (s *Server) xadd(args []string) reply {
+	if len(args) < 1 {
+		return errorReply("ERR wrong number of arguments for 'xadd' command")
+	}
+	name, args := args[0], args[1:]
+	maxLen := -1
+	if len(args) > 0 && strings.ToUpper(args[0]) == "MAXLEN" {
+		args = args[1:]
+		if len(args) > 0 && (args[0] == "~" || args[0] == "=") {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			return errorReply("ERR syntax error")
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 0 {
+			return errorReply("ERR The MAXLEN argument must be >= 0.")
+		}
+		maxLen, args = n, args[1:]
+	}
+	if len(args) < 3 || len(args)%2 != 1 || args[0] != "*" {
+		return errorReply("ERR wrong number of arguments for 'xadd' command")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.add(name, args[1:])
+	if st := s.streams[name]; maxLen >= 0 && len(st.entries) > maxLen {
+		st.entries = append([]entry(nil), st.entries[len(st.entries)-maxLen:]...)
+	}
+	return id.String()
+}
+
+func (s *Server) xgroup(args []string) reply {
+	if len(args) < 3 {
+		return errorReply("ERR wrong number of arguments for 'xgroup' command")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.streams[args[1]]
+	switch strings.ToUpper(args[0]) {
+	case "CREATE":
+		if len(args) < 4 {
+			return errorReply("ERR wrong number of arguments for 'xgroup' command")
+		}
+		if !ok {
+			if len(args) < 5 || strings.ToUpper(args[4]) != "MKSTREAM" {
+				return errorReply("ERR The XGROUP subcommand requires the key to exist.")
+			}
+			st = &stream{groups: make(map[string]*group)}
+			s.streams[args[1]] = st
+		}
+		if _, exists := st.groups[args[2]]; exists {
+			return errorReply("BUSYGROUP Consumer Group name already exists")
+		}
+		var start streamID
+		if args[3] == "$" {
+			if n := len(st.entries); n > 0 {
+				start = st.entries[n-1].id
+			}
+		} else {
+			var err error
+			if start, err = parseID(args[3]); err != nil {
+				return errorReply("ERR " + err.Error())
+			}
+		}
+		st.groups[args[2]] = &group{lastDelivered: start, pending: make(map[streamID]*delivery)}
+		return simpleReply("OK")
+	case "DESTROY":
+		if !ok {
+			return errorReply("ERR no such key")
+		}
+		if _, exists := st.groups[args[2]]; !exists {
+			return int64(0)
+		}
+		delete(st.groups, args[2])
+		return int64(1)
+	default:
+		return errorReply("ERR unknown XGROUP subcommand")
+	}
+}
+
+func (s *Server) xreadgroup(args []string) reply {
+	if len(args) < 6 || strings.ToUpper(args[0]) != "GROUP" {
+		return errorReply("ERR syntax error")
+	}
+	groupName, consumer := args[1], args[2]
+	count := -1
+	var block time.Duration
+	blocking := false
+	i := 3
+	for ; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "COUNT":
+			i++
+			n, err := strconv.Atoi(args[i])
+			if err != nil {
+				return errorReply("ERR value is not an integer or out of range")
+			}
+			count = n
+		case "BLOCK":
+			i++
+			ms, err := strconv.Atoi(args[i])
+			if err != nil {
+				return errorReply("ERR timeout is not an integer or out of range")
+			}
+			block = time.Duration(ms) * time.Millisecond
+			blocking = true
+		case "STREAMS":
+			if len(args)-i != 3 {
+				return errorReply("ERR only a single stream is supported")
+			}
+			return s.read(args[i+1], groupName, consumer, args[i+2], count, blocking, block)
+		default:
+			return errorReply("ERR syntax error")
+		}
+	}
+	return errorReply("ERR syntax error")
+}
+
+func (s *Server) read(streamName, groupName, consumer, id string, count int, blocking bool, block time.Duration) reply {
+	var timeout <-chan time.Time
+	if blocking && block > 0 {
+		timer := time.NewTimer(block)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	for {
+		s.mu.Lock()
+		g := s.group(streamName, groupName)
+		if g == nil {
+			s.mu.Unlock()
+			return errorReply(fmt.Sprintf("NOGROUP No such key '%s' or consumer group '%s'", streamName, groupName))
+		}
+		st := s.streams[streamName]
+		if id != ">" {
+			after, err := parseID(id)
+			if err != nil {
+				s.mu.Unlock()
+				return errorReply("ERR " + err.Error())
+			}
+			rep := readPending(st, g, consumer, after, count, streamName)
+			s.mu.Unlock()
+			return rep
+		}
+		var delivered []interface{}
+		for _, e := range st.entries {
+			if count >= 0 && len(delivered) == count {
+				break
+			}
+			if !g.lastDelivered.less(e.id) {
+				continue
+			}
+			g.lastDelivered = e.id
+			g.deliver(e.id, consumer)
+			delivered = append(delivered, entryReply(e.id, e.fields))
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		if len(delivered) > 0 {
+			return []interface{}{[]interface{}{streamName, delivered}}
+		}
+		if !blocking {
+			return nilReply{}
+		}
+		select {
+		case <-changed:
+		case <-timeout:
+			return nilReply{}
+		case <-s.done:
+			return nilReply{}
+		}
+	}
+}
+
+func readPending(st *stream, g *group, consumer string, after streamID, count int, streamName string) reply {
+	var ids []streamID
+	for id, d := range g.pending {
+		if d.consumer == consumer && after.less(id) {
+			ids = append(ids, id)
+		}
+	}
+	sortIDs(ids)
+	if count >= 0 && len(ids) > count {
+		ids = ids[:count]
+	}
+	entries := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		// Reading the history of the consumer delivers its entries again.
+		g.deliver(id, consumer)
+		entries = append(entries, entryReply(id, st.fields(id)))
+	}
+	return []interface{}{[]interface{}{streamName, entries}}
+}
+
+// fields returns the fields of the entry, or nil if it was deleted.
+func (st *stream) fields(id streamID) []string {
+	for _, e := range st.entries {
+		if e.id == id {
+			return e.fields
+		}
+	}
+	return nil
+}
+
+func sortIDs(ids []streamID) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i].less(ids[j]) })
+}
+
+func entryReply(id streamID, fields []string) interface{} {
+	if fields == nil {
+		return []interface{}{id.String(), nil}
+	}
+	f := make([]interface{}, 0, len(fields))
+	for _, v := range fields {
+		f = append(f, v)
+	}
+	return []interface{}{id.String(), f}
+}
+
+func (s *Server) xack(args []string) reply {
+	if len(args) < 3 {
+		return errorReply("ERR wrong number of arguments for 'xack' command")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g := s.group(args[0], args[1])
+	if g == nil {
+		return int64(0)
+	}
+	var n int64
+	for _, raw := range args[2:] {
+		id, err := parseID(raw)
+		if err != nil {
+			return errorReply("ERR " + err.Error())
+		}
+		if _, ok := g.pending[id]; ok {
+			delete(g.pending, id)
+			n++
+		}
+	}
+	return n
+}
+
+// xpending supports the extended form XPENDING key group start end count
+// [consumer], with - and + as start and end.
+func (s *Server) xpending(args []string) reply {
+	if len(args) != 5 && len(args) != 6 {
+		return errorReply("ERR only the extended form of XPENDING is supported")
+	}
+	if args[2] != "-" || args[3] != "+" {
+		return errorReply("ERR only the - + range of XPENDING is supported")
+	}
+	count, err := strconv.Atoi(args[4])
+	if err != nil {
+		return errorReply("ERR value is not an integer or out of range")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g := s.group(args[0], args[1])
+	if g == nil {
+		return errorReply(fmt.Sprintf("NOGROUP No such key '%s' or consumer group '%s'", args[0], args[1]))
+	}
+	var ids []streamID
+	for id, d := range g.pending {
+		if len(args) == 5 || d.consumer == args[5] {
+			ids = append(ids, id)
+		}
+	}
+	sortIDs(ids)
+	if len(ids) > count {
+		ids = ids[:count]
+	}
+	entries := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		d := g.pending[id]
+		entries = append(entries, []interface{}{
+			id.String(),
+			d.consumer,
+			time.Since(d.time).Milliseconds(),
+			d.count,
+		})
+	}
+	return entries
+}
+
+// xclaim supports XCLAIM key group consumer min-idle-time id [id ...],
+// without options.
+func (s *Server) xclaim(args []string) reply {
+	if len(args) < 5 {
+		return errorReply("ERR wrong number of arguments for 'xclaim' command")
+	}
+	ms, err := strconv.Atoi(args[3])
+	if err != nil {
+		return errorReply("ERR Invalid min-idle-time argument for XCLAIM")
+	}
+	minIdle := time.Duration(ms) * time.Millisecond
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g := s.group(args[0], args[1])
+	if g == nil {
+		return errorReply(fmt.Sprintf("NOGROUP No such key '%s' or consumer group '%s'", args[0], args[1]))
+	}
+	st := s.streams[args[0]]
+	entries := []interface{}{}
+	for _, raw := range args[4:] {
+		id, err := parseID(raw)
+		if err != nil {
+			return errorReply("ERR " + err.Error())
+		}
+		d, ok := g.pending[id]
+		if !ok || time.Since(d.time) < minIdle {
+			continue
+		}
+		g.deliver(id, args[2])
+		if fields := st.fields(id); fields != nil {
+			entries = append(entries, entryReply(id, fields))
+		} else {
+			// As Redis before 7.0, reply nil for deleted entries.
+			entries = append(entries, nil)
+		}
+	}
+	return entries
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '*' {
+		return nil, fmt.Errorf("unexpected command %q", line)
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("invalid command length %q", line)
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, fmt.Errorf("unexpected argument %q", line)
+		}
+		l, err := strconv.Atoi(line[1:])
+		if err != nil || l < 0 {
+			return nil, fmt.Errorf("invalid argument length %q", line)
+		}
+		buf := make([]byte, l+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:l])
+	}
+	return args, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(line, "\r\n"), nil
+}
+
+func writeReply(w *bufio.Writer, rep reply) error {
+	var err error
+	switch v := rep.(type) {
+	case nil:
+		_, err = w.WriteString("$-1\r\n")
+	case nilReply:
+		_, err = w.WriteString("*-1\r\n")
+	case simpleReply:
+		_, err = fmt.Fprintf(w, "+%s\r\n", v)
+	case errorReply:
+		_, err = fmt.Fprintf(w, "-%s\r\n", v)
+	case int64:
+		_, err = fmt.Fprintf(w, ":%d\r\n", v)
+	case string:
+		_, err = fmt.Fprintf(w, "$%d\r\n%s\r\n", len(v), v)
+	case []interface{}:
+		if _, err = fmt.Fprintf(w, "*%d\r\n", len(v)); err != nil {
+			return err
+		}
+		for _, e := range v {
+			if err = writeReply(w, e); err != nil {
+				return err
+			}
+		}
+	default:
+		err = fmt.Errorf("unsupported reply %T", rep)
+	}
+	return err
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package adapter implements the receive adapter of the RedisStreamSource,
+// which reads entries of a Redis stream as a member of a consumer group and
+// delivers them as CloudEvents to a sink.
+package adapter
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"time"
+
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"go.uber.org/zap"
+
+	"github.com/google/knative-gcp/pkg/logging"
+	"github.com/google/knative-gcp/pkg/redis"
+)
+
+const (
+	// EventType is the type of the CloudEvents sent for stream entries.
+	EventType = "dev.knative.sources.redisstream"
+
+	defaultBatchSize     = 10
+	defaultBlockTimeout  = 10 * time.Second
+	defaultRetryDelay    = time.Second
+	defaultMaxDeliveries = 10
+)
+
+// AdapterArgs has a bundle of arguments needed to create an Adapter.
+type AdapterArgs struct {
+	// Stream is the name of the Redis stream.
+	Stream string
+
+	// Group is the name of the consumer group to read the stream with.
+	Group string
+
+	// Consumer is the name of this consumer in the group. Pending entries are
+	// owned by a consumer, so it must be stable across restarts.
+	Consumer string
+
+	// Source is the source attribute of the events.
+	Source string
+
+	// SinkURI is the URI where to sink events to.
+	SinkURI string
+
+	// Extensions is the converted ExtensionsBased64 value.
+	Extensions map[string]string
+
+	// BatchSize is the maximum number of entries read at once. Defaults to 10.
+	BatchSize int
+
+	// BlockTimeout is how long a read waits for new entries. Defaults to 10s.
+	BlockTimeout time.Duration
+
+	// RetryDelay is how long to wait before redelivering entries that
+	// failed to be delivered. Defaults to 1s.
+	RetryDelay time.Duration
+
+	// MaxDeliveries is the maximum number of times an entry is delivered.
+	// Once reached, the entry is added to the DeadLetterStream, if any, and
+	// acknowledged. Defaults to 10.
+	MaxDeliveries int64
+
+	// DeadLetterStream is the stream the entries that could not be delivered
+	// are added to. They are dropped if it is empty.
+	DeadLetterStream string
+}
+
+// Adapter implements the RedisStreamSource receive adapter.
+type Adapter struct {
+	// client is the Redis client used to read and acknowledge entries.
+	client *redis.Client
+
+	// outbound is the client used to send events to.
+	outbound *nethttp.Client
+
+	// args holds a set of arguments used to configure the Adapter.
+	args *AdapterArgs
+
+	logger *zap.Logger
+}
+
+// NewAdapter creates a new adapter.
+func NewAdapter(ctx context.Context, client *redis.Client, outbound *nethttp.Client, args *AdapterArgs) *Adapter {
+	if args.BatchSize == 0 {
+		args.BatchSize = defaultBatchSize
+	}
+	if args.BlockTimeout == 0 {
+		args.BlockTimeout = defaultBlockTimeout
+	}
+	if args.RetryDelay == 0 {
+		args.RetryDelay = defaultRetryDelay
+	}
+	if args.MaxDeliveries <= 0 {
+		args.MaxDeliveries = defaultMaxDeliveries
+	}
+	return &Adapter{
+		client:   client,
+		outbound: outbound,
+		args:     args,
+		logger:   logging.FromContext(ctx),
+	}
+}
+
+// Start reads and delivers entries until the context is done. Entries that
+// fail to be delivered are left pending and redelivered once they have been
+// pending for the retry delay, while new entries keep being read. The entries
+// left pending by a previous run of this consumer are redelivered first.
+func (a *Adapter) Start(ctx context.Context) error {
+	// Whether entries may be pending, as after a previous run.
+	pending := true
+	retryAt := time.Now()
+	for {
+		if pending && !time.Now().Before(retryAt) {
+			pending = a.redeliver(ctx)
+			retryAt = time.Now().Add(a.args.RetryDelay)
+		}
+		// Don't wait for new entries past the next redelivery.
+		block := a.args.BlockTimeout
+		if pending && block > a.args.RetryDelay {
+			block = a.args.RetryDelay
+		}
+		msgs, err := a.client.ReadGroup(ctx, a.args.Stream, a.args.Group, a.args.Consumer, redis.NewMessages, a.args.BatchSize, block)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			a.logger.Error("Failed to read from stream", zap.String("stream", a.args.Stream), zap.String("group", a.args.Group), zap.Error(err))
+			if !a.wait(ctx) {
+				return nil
+			}
+			continue
+		}
+		for _, msg := range msgs {
+			if !a.receive(ctx, msg) {
+				pending = true
+			}
+		}
+	}
+}
+
+// redeliver claims the pending entries of the consumer that have been pending
+// for the retry delay, and delivers them again. The entries that were already
+// delivered the maximum number of times are given up on instead. It returns
+// false if no entries are pending.
+func (a *Adapter) redeliver(ctx context.Context) bool {
+	pending, err := a.client.Pending(ctx, a.args.Stream, a.args.Group, a.args.Consumer, a.args.BatchSize)
+	if err != nil {
+		a.logger.Error("Failed to list pending entries", zap.String("stream", a.args.Stream), zap.String("group", a.args.Group), zap.Error(err))
+		return true
+	}
+	deliveries := make(map[string]int64, len(pending))
+	ids := make([]string, 0, len(pending))
+	for _, p := range pending {
+		if p.Idle >= a.args.RetryDelay {
+			deliveries[p.ID] = p.Deliveries
+			ids = append(ids, p.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return len(pending) > 0
+	}
+	msgs, err := a.client.Claim(ctx, a.args.Stream, a.args.Group, a.args.Consumer, a.args.RetryDelay, ids...)
+	if err != nil {
+		a.logger.Error("Failed to claim pending entries", zap.String("stream", a.args.Stream), zap.String("group", a.args.Group), zap.Error(err))
+		return true
+	}
+	for _, msg := range msgs {
+		n := deliveries[msg.ID]
+		delete(deliveries, msg.ID)
+		if n >= a.args.MaxDeliveries {
+			a.giveUp(ctx, msg, n)
+			continue
+		}
+		a.receive(ctx, msg)
+	}
+	// The entries that were not claimed were deleted from the stream while
+	// pending, there is nothing left to deliver.
+	for id := range deliveries {
+		a.ack(ctx, a.logger.With(zap.String("id", id)), redis.Message{ID: id})
+	}
+	return true
+}
+
+// giveUp adds the entry to the dead letter stream, if any, and acknowledges
+// it.
+func (a *Adapter) giveUp(ctx context.Context, msg redis.Message, deliveries int64) {
+	logger := a.logger.With(zap.String("id", msg.ID), zap.Int64("deliveries", deliveries))
+	if a.args.DeadLetterStream != "" && msg.Fields != nil {
+		if _, err := a.client.Add(ctx, a.args.DeadLetterStream, 0, msg.Fields...); err != nil {
+			logger.Error("Failed to add stream entry to the dead letter stream", zap.String("deadLetterStream", a.args.DeadLetterStream), zap.Error(err))
+			return
+		}
+		logger.Warn("Stream entry could not be delivered, added it to the dead letter stream", zap.String("deadLetterStream", a.args.DeadLetterStream))
+	} else {
+		logger.Warn("Stream entry could not be delivered, dropping it")
+	}
+	a.ack(ctx, logger, msg)
+}
+
+// wait waits for the retry delay. It returns false if the context is done.
+func (a *Adapter) wait(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(a.args.RetryDelay):
+		return true
+	}
+}
+
+// receive delivers the entry to the sink and acknowledges it on success. It
+// returns false if the entry should be redelivered.
+func (a *Adapter) receive(ctx context.Context, msg redis.Message) bool {
+	logger := a.logger.With(zap.String("id", msg.ID))
+	if msg.Fields == nil {
+		// The entry was deleted from the stream while pending, there is
+		// nothing left to deliver.
+		return a.ack(ctx, logger, msg)
+	}
+
+	event, err := a.toEvent(msg)
+	if err != nil {
+		// Conversion errors are not retryable.
+		logger.Error("Failed to convert stream entry to an event", zap.Error(err))
+		return a.ack(ctx, logger, msg)
+	}
+
+	if err := a.send(ctx, event); err != nil {
+		logger.Error("Failed to send event to sink", zap.String("address", a.args.SinkURI), zap.Error(err))
+		return false
+	}
+	return a.ack(ctx, logger, msg)
+}
+
+func (a *Adapter) ack(ctx context.Context, logger *zap.Logger, msg redis.Message) bool {
+	if _, err := a.client.Ack(ctx, a.args.Stream, a.args.Group, msg.ID); err != nil {
+		logger.Error("Failed to acknowledge stream entry", zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// toEvent converts a stream entry to a CloudEvent whose data is the JSON
+// array of the entry field-value pairs.
+func (a *Adapter) toEvent(msg redis.Message) (*cev2.Event, error) {
+	event := cev2.NewEvent(cev2.VersionV1)
+	event.SetID(msg.ID)
+	event.SetType(EventType)
+	event.SetSource(a.args.Source)
+	event.SetSubject(a.args.Stream)
+	if err := event.SetData(cev2.ApplicationJSON, msg.Fields); err != nil {
+		return nil, err
+	}
+	for k, v := range a.args.Extensions {
+		event.SetExtension(k, v)
+	}
+	return &event, event.Validate()
+}
+
+func (a *Adapter) send(ctx context.Context, event *cev2.Event) error {
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodPost, a.args.SinkURI, nil)
+	if err != nil {
+		return err
+	}
+	if err := cehttp.WriteRequest(ctx, (*binding.EventMessage)(event), req); err != nil {
+		return err
+	}
+	resp, err := a.outbound.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			a.logger.Warn("Failed to close response body", zap.Error(err))
+		}
+	}()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("event delivery failed with status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,314 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	logtest "knative.dev/pkg/logging/testing"
+
+	"github.com/google/knative-gcp/pkg/redis"
+	redistesting "github.com/google/knative-gcp/pkg/redis/testing"
+)
+
+const (
+	testStream   = "test-stream"
+	testGroup    = "test-group"
+	testConsumer = "test-consumer"
+	testSource   = "redis://test-address/test-stream"
+)
+
+// sink records the events it receives, fails the first failures requests and
+// rejects the event with the poison ID.
+type sink struct {
+	mu       sync.Mutex
+	failures int
+	poison   string
+	rejected int
+	events   []cev2.Event
+	received chan struct{}
+}
+
+func (s *sink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	event, err := binding.ToEvent(r.Context(), cehttp.NewMessageFromHttpRequest(r))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if event.ID() == s.poison {
+		s.rejected++
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.events = append(s.events, *event)
+	w.WriteHeader(http.StatusAccepted)
+	s.received <- struct{}{}
+}
+
+func setup(t *testing.T, failures int) (*redistesting.Server, *sink, *redis.Client, func(...func(*AdapterArgs)) *Adapter) {
+	t.Helper()
+	srv, err := redistesting.NewServer("")
+	if err != nil {
+		t.Fatalf("Failed to start Redis server: %v", err)
+	}
+	client := redis.NewClient(redis.Options{Address: srv.Addr()})
+	s := &sink{failures: failures, received: make(chan struct{}, 10)}
+	hs := httptest.NewServer(s)
+	t.Cleanup(func() {
+		hs.Close()
+		client.Close()
+		srv.Close()
+	})
+	if err := client.CreateGroup(context.Background(), testStream, testGroup, redis.LastMessage); err != nil {
+		t.Fatalf("Failed to create group: %v", err)
+	}
+	newAdapter := func(opts ...func(*AdapterArgs)) *Adapter {
+		args := &AdapterArgs{
+			Stream:       testStream,
+			Group:        testGroup,
+			Consumer:     testConsumer,
+			Source:       testSource,
+			SinkURI:      hs.URL,
+			Extensions:   map[string]string{"foo": "bar"},
+			BlockTimeout: 100 * time.Millisecond,
+			RetryDelay:   10 * time.Millisecond,
+		}
+		for _, opt := range opts {
+			opt(args)
+		}
+		return NewAdapter(logtest.TestContextWithLogger(t), client, hs.Client(), args)
+	}
+	return srv, s, client, newAdapter
+}
+
+func run(t *testing.T, a *Adapter) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- a.Start(ctx)
+	}()
+	return func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Start returned error: %v", err)
+		}
+	}
+}
+
+func waitEvents(t *testing.T, s *sink, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-s.received:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for event %d", i)
+		}
+	}
+}
+
+func waitNoPending(t *testing.T, srv *redistesting.Server) {
+	t.Helper()
+	waitPending(t, srv)
+}
+
+// waitPending waits for the entries pending in the test group to be ids.
+func waitPending(t *testing.T, srv *redistesting.Server, ids ...string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cmp.Equal(ids, srv.Pending(testStream, testGroup), cmpopts.EquateEmpty()) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Pending entries %v, want %v", srv.Pending(testStream, testGroup), ids)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// waitRejected waits for the sink to reject the poison entry n times.
+func waitRejected(t *testing.T, s *sink, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s.mu.Lock()
+		rejected := s.rejected
+		s.mu.Unlock()
+		if rejected >= n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Poison entry rejected %d times, want %d", rejected, n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAdapterDeliversAndAcks(t *testing.T) {
+	srv, s, _, newAdapter := setup(t, 0)
+	stop := run(t, newAdapter())
+	defer stop()
+
+	id := srv.Add(testStream, "temperature", "21", "unit", "celsius")
+	waitEvents(t, s, 1)
+	waitNoPending(t, srv)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event := s.events[0]
+	if event.ID() != id {
+		t.Errorf("event ID = %q, want %q", event.ID(), id)
+	}
+	if event.Type() != EventType {
+		t.Errorf("event type = %q, want %q", event.Type(), EventType)
+	}
+	if event.Source() != testSource {
+		t.Errorf("event source = %q, want %q", event.Source(), testSource)
+	}
+	if event.Subject() != testStream {
+		t.Errorf("event subject = %q, want %q", event.Subject(), testStream)
+	}
+	if got := event.Extensions()["foo"]; got != "bar" {
+		t.Errorf("event extension foo = %v, want bar", got)
+	}
+	var fields []string
+	if err := json.Unmarshal(event.Data(), &fields); err != nil {
+		t.Fatalf("Failed to decode event data: %v", err)
+	}
+	if diff := cmp.Diff([]string{"temperature", "21", "unit", "celsius"}, fields); diff != "" {
+		t.Errorf("unexpected event data (-want, +got) = %v", diff)
+	}
+}
+
+func TestAdapterRetriesFailedDelivery(t *testing.T) {
+	srv, s, _, newAdapter := setup(t, 2)
+	stop := run(t, newAdapter())
+	defer stop()
+
+	id := srv.Add(testStream, "a", "1")
+	waitEvents(t, s, 1)
+	waitNoPending(t, srv)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.events) != 1 || s.events[0].ID() != id {
+		t.Errorf("unexpected events %v, want a single event with ID %q", s.events, id)
+	}
+}
+
+func TestAdapterRedeliversPendingOnStart(t *testing.T) {
+	srv, s, client, newAdapter := setup(t, 0)
+
+	// Simulate a previous run that read an entry without acknowledging it.
+	id := srv.Add(testStream, "a", "1")
+	if _, err := client.ReadGroup(context.Background(), testStream, testGroup, testConsumer, redis.NewMessages, 10, 0); err != nil {
+		t.Fatalf("ReadGroup failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{id}, srv.Pending(testStream, testGroup)); diff != "" {
+		t.Fatalf("unexpected pending entries (-want, +got) = %v", diff)
+	}
+
+	stop := run(t, newAdapter())
+	defer stop()
+	waitEvents(t, s, 1)
+	waitNoPending(t, srv)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.events[0].ID() != id {
+		t.Errorf("event ID = %q, want %q", s.events[0].ID(), id)
+	}
+}
+
+func TestAdapterDeliversNewEntriesWhileRetrying(t *testing.T) {
+	srv, s, _, newAdapter := setup(t, 0)
+	stop := run(t, newAdapter(func(args *AdapterArgs) {
+		// Redeliver the poison entry slowly, for the new entry to be
+		// delivered in between.
+		args.RetryDelay = time.Second
+		args.MaxDeliveries = 100
+	}))
+	defer stop()
+
+	s.mu.Lock()
+	s.poison = srv.Add(testStream, "a", "1")
+	s.mu.Unlock()
+	id := srv.Add(testStream, "b", "2")
+	waitEvents(t, s, 1)
+	waitPending(t, srv, s.poison)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.events) != 1 || s.events[0].ID() != id {
+		t.Errorf("unexpected events %v, want a single event with ID %q", s.events, id)
+	}
+}
+
+func TestAdapterGivesUpAfterMaxDeliveries(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		deadLetterStream string
+		wantDeadLetters  [][]string
+	}{{
+		name: "dropped",
+	}, {
+		name:             "dead lettered",
+		deadLetterStream: "dead-letters",
+		wantDeadLetters:  [][]string{{"a", "1"}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, s, _, newAdapter := setup(t, 0)
+			stop := run(t, newAdapter(func(args *AdapterArgs) {
+				args.MaxDeliveries = 3
+				args.DeadLetterStream = tc.deadLetterStream
+			}))
+			defer stop()
+
+			s.mu.Lock()
+			s.poison = srv.Add(testStream, "a", "1")
+			s.mu.Unlock()
+			waitRejected(t, s, 3)
+			waitNoPending(t, srv)
+
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.rejected != 3 {
+				t.Errorf("poison entry delivered %d times, want 3", s.rejected)
+			}
+			if tc.deadLetterStream != "" {
+				if diff := cmp.Diff(tc.wantDeadLetters, srv.Entries(tc.deadLetterStream)); diff != "" {
+					t.Errorf("unexpected dead letter entries (-want, +got) = %v", diff)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/empty"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
+	auditpb "google.golang.org/genproto/googleapis/cloud/audit"
+	logpb "google.golang.org/genproto/googleapis/logging/v2"
+)
+
+var (
+	// LogEntryUnmarshaler decodes the JSON log entries of the Cloud Audit Logs events. The
+	// messages of unknown types in Any fields are decoded as UnknownMsg.
+	LogEntryUnmarshaler = jsonpb.Unmarshaler{
+		AllowUnknownFields: true,
+		AnyResolver:        resolver(resolveAnyUnknowns),
+	}
+
+	parentResourceRegexp = regexp.MustCompile(`^(:?projects|organizations|billingAccounts|folders)/[^/]+`)
+)
+
+// Resolver function type that can be used to resolve Any fields in a jsonpb.Unmarshaler.
+type resolver func(turl string) (proto.Message, error)
+
+func (r resolver) Resolve(turl string) (proto.Message, error) {
+	return r(turl)
+}
+
+type UnknownMsg empty.Empty
+
+func (m *UnknownMsg) ProtoMessage() {
+	(*empty.Empty)(m).ProtoMessage()
+}
+
+func (m *UnknownMsg) Reset() {
+	(*empty.Empty)(m).Reset()
+}
+
+func (m *UnknownMsg) String() string {
+	return "Unknown message"
+}
+
+// Resolves type URLs such as
+// type.googleapis.com/google.profile.Person to a proto message
+// type. Resolves unknown message types to empty.Empty.
+func resolveAnyUnknowns(typeURL string) (proto.Message, error) {
+	// Only the part of typeUrl after the last slash is relevant.
+	mname := typeURL
+	if slash := strings.LastIndex(mname, "/"); slash >= 0 {
+		mname = mname[slash+1:]
+	}
+	mt := proto.MessageType(mname)
+	if mt == nil {
+		return (*UnknownMsg)(&empty.Empty{}), nil
+	}
+	return reflect.New(mt.Elem()).Interface().(proto.Message), nil
+}
+
+// Log name ref: https://cloud.google.com/logging/docs/audit#viewing_audit_logs
+func logActivity(logName string) string {
+	parts := strings.Split(logName, "%2F")
+	if len(parts) < 2 {
+		return ""
+	}
+	// Could be "activity" or "data_access"
+	return parts[1]
+}
+
+// AuditLogEntry decodes the log entry of a Cloud Audit Logs event.
+func AuditLogEntry(event cev2.Event) (*logpb.LogEntry, error) {
+	if err := checkType(event, schemasv1.CloudAuditLogsLogWrittenEventType); err != nil {
+		return nil, err
+	}
+	return unmarshalLogEntry(event.Data())
+}
+
+// AuditLog decodes the log entry of a Cloud Audit Logs event, and returns its AuditLog payload.
+func AuditLog(event cev2.Event) (*auditpb.AuditLog, error) {
+	entry, err := AuditLogEntry(event)
+	if err != nil {
+		return nil, err
+	}
+	return auditLogOf(entry)
+}
+
+// NewAuditLogEvent returns the Cloud Audit Logs event of the log entry, encoded in JSON.
+func NewAuditLogEvent(data []byte) (*cev2.Event, error) {
+	entry, err := unmarshalLogEntry(data)
+	if err != nil {
+		return nil, err
+	}
+
+	parentResource := parentResourceRegexp.FindString(entry.LogName)
+	if parentResource == "" {
+		return nil, fmt.Errorf("invalid LogName: %q", entry.LogName)
+	}
+	logActivity := logActivity(entry.LogName)
+
+	// Make a new event and convert the message payload.
+	event := cev2.NewEvent(cev2.VersionV1)
+	event.SetID(schemasv1.CloudAuditLogsEventID(entry.InsertId, entry.LogName, ptypes.TimestampString(entry.Timestamp)))
+	if timestamp, err := ptypes.Timestamp(entry.Timestamp); err != nil {
+		return nil, fmt.Errorf("invalid LogEntry timestamp: %w", err)
+	} else {
+		event.SetTime(timestamp)
+	}
+	event.SetType(schemasv1.CloudAuditLogsLogWrittenEventType)
+	event.SetSource(schemasv1.CloudAuditLogsEventSource(parentResource, logActivity))
+	event.SetDataSchema(schemasv1.CloudAuditLogsEventDataSchema)
+	event.SetData(cev2.ApplicationJSON, data)
+
+	auditLog, err := auditLogOf(entry)
+	if err != nil {
+		return nil, err
+	}
+	event.SetSubject(schemasv1.CloudAuditLogsEventSubject(auditLog.ServiceName, auditLog.ResourceName))
+	event.SetExtension(schemasv1.ServiceNameExtension, auditLog.ServiceName)
+	event.SetExtension(schemasv1.MethodNameExtension, auditLog.MethodName)
+	event.SetExtension(schemasv1.ResourceNameExtension, auditLog.ResourceName)
+	return &event, nil
+}
+
+// NewAuditLogEntryEvent returns the Cloud Audit Logs event of the log entry.
+func NewAuditLogEntryEvent(entry *logpb.LogEntry) (*cev2.Event, error) {
+	var buf bytes.Buffer
+	if err := new(jsonpb.Marshaler).Marshal(&buf, entry); err != nil {
+		return nil, fmt.Errorf("failed to encode LogEntry: %w", err)
+	}
+	return NewAuditLogEvent(buf.Bytes())
+}
+
+func unmarshalLogEntry(data []byte) (*logpb.LogEntry, error) {
+	entry := &logpb.LogEntry{}
+	if err := LogEntryUnmarshaler.Unmarshal(bytes.NewReader(data), entry); err != nil {
+		return nil, fmt.Errorf("failed to decode LogEntry: %w", err)
+	}
+	return entry, nil
+}
+
+func auditLogOf(entry *logpb.LogEntry) (*auditpb.AuditLog, error) {
+	switch payload := entry.Payload.(type) {
+	case *logpb.LogEntry_ProtoPayload:
+		var unpacked ptypes.DynamicAny
+		if err := ptypes.UnmarshalAny(payload.ProtoPayload, &unpacked); err != nil {
+			return nil, fmt.Errorf("unrecognized proto payload: %w", err)
+		}
+		switch proto := unpacked.Message.(type) {
+		case *auditpb.AuditLog:
+			return proto, nil
+		default:
+			return nil, fmt.Errorf("unhandled proto payload type: %T", proto)
+		}
+	default:
+		return nil, errors.New("non-AuditLog log entry")
+	}
+}
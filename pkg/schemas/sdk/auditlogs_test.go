@@ -0,0 +1,109 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
+	auditpb "google.golang.org/genproto/googleapis/cloud/audit"
+	logpb "google.golang.org/genproto/googleapis/logging/v2"
+)
+
+func TestAuditLog(t *testing.T) {
+	auditLog := &auditpb.AuditLog{
+		ServiceName:  "pubsub.googleapis.com",
+		MethodName:   "google.pubsub.v1.Publisher.CreateTopic",
+		ResourceName: "projects/test-project/topics/test-topic",
+	}
+	payload, err := ptypes.MarshalAny(auditLog)
+	if err != nil {
+		t.Fatalf("Failed to marshal proto payload: %v", err)
+	}
+	timestamp, _ := ptypes.TimestampProto(time.Date(2020, time.September, 15, 12, 0, 0, 0, time.UTC))
+	entry := &logpb.LogEntry{
+		InsertId:  "insert-id",
+		LogName:   "projects/test-project/logs/cloudaudit.googleapis.com%2Factivity",
+		Timestamp: timestamp,
+		Payload: &logpb.LogEntry_ProtoPayload{
+			ProtoPayload: payload,
+		},
+	}
+
+	event, err := NewAuditLogEntryEvent(entry)
+	if err != nil {
+		t.Fatalf("NewAuditLogEntryEvent got error: %v", err)
+	}
+	if want := schemasv1.CloudAuditLogsEventSource("projects/test-project", "activity"); event.Source() != want {
+		t.Errorf("Source %q != %q", event.Source(), want)
+	}
+	if want := schemasv1.CloudAuditLogsEventSubject(auditLog.ServiceName, auditLog.ResourceName); event.Subject() != want {
+		t.Errorf("Subject %q != %q", event.Subject(), want)
+	}
+
+	gotEntry, err := AuditLogEntry(*event)
+	if err != nil {
+		t.Fatalf("AuditLogEntry got error: %v", err)
+	}
+	if gotEntry.InsertId != entry.InsertId || gotEntry.LogName != entry.LogName {
+		t.Errorf("AuditLogEntry got=%v, want=%v", gotEntry, entry)
+	}
+	got, err := AuditLog(*event)
+	if err != nil {
+		t.Fatalf("AuditLog got error: %v", err)
+	}
+	if got.MethodName != auditLog.MethodName {
+		t.Errorf("MethodName %q != %q", got.MethodName, auditLog.MethodName)
+	}
+
+	// Non-AuditLog payloads are rejected.
+	other, _ := ptypes.MarshalAny(&structpb.Struct{})
+	entry.Payload = &logpb.LogEntry_ProtoPayload{ProtoPayload: other}
+	if _, err := NewAuditLogEntryEvent(entry); err == nil {
+		t.Errorf("NewAuditLogEntryEvent got no error for a non-AuditLog payload")
+	}
+}
+
+func TestAuditLogUnknownServiceData(t *testing.T) {
+	data := []byte(`{
+		"insertId": "insert-id",
+		"logName": "projects/test-project/logs/cloudaudit.googleapis.com%2Factivity",
+		"timestamp": "2020-09-15T12:00:00Z",
+		"protoPayload": {
+			"@type": "type.googleapis.com/google.cloud.audit.AuditLog",
+			"serviceName": "example.googleapis.com",
+			"serviceData": {
+				"@type": "type.googleapis.com/google.example.v1.Unknown",
+				"field": "value"
+			}
+		}
+	}`)
+	event, err := NewAuditLogEvent(data)
+	if err != nil {
+		t.Fatalf("NewAuditLogEvent got error: %v", err)
+	}
+	got, err := AuditLog(*event)
+	if err != nil {
+		t.Fatalf("AuditLog got error: %v", err)
+	}
+	if got.ServiceName != "example.googleapis.com" {
+		t.Errorf("ServiceName %q != %q", got.ServiceName, "example.googleapis.com")
+	}
+}
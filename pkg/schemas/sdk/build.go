@@ -0,0 +1,40 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
+)
+
+// Build decodes the build of a Cloud Build event.
+func Build(event cev2.Event) (*schemasv1.BuildData, error) {
+	data := &schemasv1.BuildData{}
+	if err := decode(event, data, schemasv1.CloudBuildSourceEventType); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// NewBuildEvent returns a Cloud Build event about the build, with its status as subject.
+func NewBuildEvent(build *schemasv1.BuildData) (*cev2.Event, error) {
+	return newEvent(schemasv1.CloudBuildSourceEventType,
+		schemasv1.CloudBuildSourceEventSource(build.ProjectID, build.ID),
+		build.Status,
+		schemasv1.CloudBuildEventDataSchema,
+		build)
+}
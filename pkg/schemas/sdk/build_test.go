@@ -0,0 +1,61 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
+)
+
+func TestBuild(t *testing.T) {
+	createTime := time.Date(2020, time.September, 15, 12, 0, 0, 0, time.UTC)
+	build := &schemasv1.BuildData{
+		ID:         "build-id",
+		ProjectID:  "project",
+		Status:     "SUCCESS",
+		CreateTime: &createTime,
+		Steps: []map[string]interface{}{{
+			"name": "gcr.io/cloud-builders/docker",
+		}},
+	}
+	event, err := NewBuildEvent(build)
+	if err != nil {
+		t.Fatalf("NewBuildEvent got error: %v", err)
+	}
+	if want := schemasv1.CloudBuildSourceEventSource("project", "build-id"); event.Source() != want {
+		t.Errorf("Source %q != %q", event.Source(), want)
+	}
+	if event.Subject() != "SUCCESS" {
+		t.Errorf("Subject %q != %q", event.Subject(), "SUCCESS")
+	}
+
+	got, err := Build(*event)
+	if err != nil {
+		t.Fatalf("Build got error: %v", err)
+	}
+	if diff := cmp.Diff(build, got); diff != "" {
+		t.Errorf("unexpected build (-want, +got) = %v", diff)
+	}
+
+	event.SetData("application/json", []byte("not json"))
+	if _, err := Build(*event); err == nil {
+		t.Errorf("Build got no error for invalid data")
+	}
+}
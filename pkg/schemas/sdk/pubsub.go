@@ -0,0 +1,77 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"time"
+
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
+)
+
+// pushMessage is schemasv1.PushMessage with the data of the message decoded as bytes.
+type pushMessage struct {
+	Subscription string `json:"subscription"`
+	Message      *struct {
+		ID          string            `json:"messageId,omitempty"`
+		Data        []byte            `json:"data,omitempty"`
+		Attributes  map[string]string `json:"attributes,omitempty"`
+		PublishTime time.Time         `json:"publishTime,omitempty"`
+	} `json:"message,omitempty"`
+}
+
+// PubSubMessage decodes the message of a Cloud Pub/Sub event. The data of the message is
+// returned as a byte slice.
+func PubSubMessage(event cev2.Event) (*schemasv1.PushMessage, error) {
+	data := &pushMessage{}
+	if err := decode(event, data, schemasv1.CloudPubSubMessagePublishedEventType); err != nil {
+		return nil, err
+	}
+	msg := &schemasv1.PushMessage{Subscription: data.Subscription}
+	if data.Message != nil {
+		msg.Message = &schemasv1.PubSubMessage{
+			ID:          data.Message.ID,
+			Data:        data.Message.Data,
+			Attributes:  data.Message.Attributes,
+			PublishTime: data.Message.PublishTime,
+		}
+	}
+	return msg, nil
+}
+
+// NewPubSubEvent returns a Cloud Pub/Sub event of the message published to the topic and
+// received by the subscription. The event has the ID and publish time of the message.
+func NewPubSubEvent(project, topic, subscription string, msg *schemasv1.PubSubMessage) (*cev2.Event, error) {
+	event, err := newEvent(schemasv1.CloudPubSubMessagePublishedEventType,
+		schemasv1.CloudPubSubEventSource(project, topic),
+		"",
+		schemasv1.CloudPubSubEventDataSchema,
+		&schemasv1.PushMessage{
+			Subscription: subscription,
+			Message:      msg,
+		})
+	if err != nil {
+		return nil, err
+	}
+	if msg.ID != "" {
+		event.SetID(msg.ID)
+	}
+	if !msg.PublishTime.IsZero() {
+		event.SetTime(msg.PublishTime)
+	}
+	return event, nil
+}
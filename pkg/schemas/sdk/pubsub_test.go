@@ -0,0 +1,59 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
+)
+
+func TestPubSubMessage(t *testing.T) {
+	msg := &schemasv1.PubSubMessage{
+		ID:          "id",
+		Data:        []byte("data"),
+		Attributes:  map[string]string{"key": "value"},
+		PublishTime: time.Date(2020, time.September, 15, 12, 0, 0, 0, time.UTC),
+	}
+	event, err := NewPubSubEvent("project", "topic", "subscription", msg)
+	if err != nil {
+		t.Fatalf("NewPubSubEvent got error: %v", err)
+	}
+	if event.ID() != "id" {
+		t.Errorf("ID %q != %q", event.ID(), "id")
+	}
+	if !event.Time().Equal(msg.PublishTime) {
+		t.Errorf("Time '%v' != '%v'", event.Time(), msg.PublishTime)
+	}
+	if want := schemasv1.CloudPubSubEventSource("project", "topic"); event.Source() != want {
+		t.Errorf("Source %q != %q", event.Source(), want)
+	}
+
+	got, err := PubSubMessage(*event)
+	if err != nil {
+		t.Fatalf("PubSubMessage got error: %v", err)
+	}
+	want := &schemasv1.PushMessage{
+		Subscription: "subscription",
+		Message:      msg,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected message (-want, +got) = %v", diff)
+	}
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
+)
+
+// SchedulerJob decodes the data of a Cloud Scheduler event.
+func SchedulerJob(event cev2.Event) (*schemasv1.SchedulerJobData, error) {
+	data := &schemasv1.SchedulerJobData{}
+	if err := decode(event, data, schemasv1.CloudSchedulerJobExecutedEventType); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// NewSchedulerJobEvent returns a Cloud Scheduler event of the execution of the job, with the
+// given payload.
+func NewSchedulerJobEvent(jobName string, customData []byte) (*cev2.Event, error) {
+	return newEvent(schemasv1.CloudSchedulerJobExecutedEventType,
+		schemasv1.CloudSchedulerEventSource(jobName),
+		"",
+		schemasv1.CloudSchedulerEventDataSchema,
+		&schemasv1.SchedulerJobData{CustomData: customData})
+}
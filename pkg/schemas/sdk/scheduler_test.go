@@ -0,0 +1,41 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"testing"
+
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
+)
+
+func TestSchedulerJob(t *testing.T) {
+	event, err := NewSchedulerJobEvent("projects/project/locations/us-central1/jobs/job", []byte("payload"))
+	if err != nil {
+		t.Fatalf("NewSchedulerJobEvent got error: %v", err)
+	}
+	if want := schemasv1.CloudSchedulerEventSource("projects/project/locations/us-central1/jobs/job"); event.Source() != want {
+		t.Errorf("Source %q != %q", event.Source(), want)
+	}
+
+	got, err := SchedulerJob(*event)
+	if err != nil {
+		t.Fatalf("SchedulerJob got error: %v", err)
+	}
+	if string(got.CustomData) != "payload" {
+		t.Errorf("CustomData %q != %q", got.CustomData, "payload")
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/uuid"
+)
+
+// decode decodes the data of an event, after checking that its type is one of the given types.
+func decode(event cev2.Event, data interface{}, types ...string) error {
+	if err := checkType(event, types...); err != nil {
+		return err
+	}
+	if err := event.DataAs(data); err != nil {
+		return fmt.Errorf("failed to decode the data of the %s event: %w", event.Type(), err)
+	}
+	return nil
+}
+
+func checkType(event cev2.Event, types ...string) error {
+	for _, t := range types {
+		if event.Type() == t {
+			return nil
+		}
+	}
+	return fmt.Errorf("unexpected event type %q, want %s", event.Type(), strings.Join(types, " or "))
+}
+
+// newEvent returns an event with a random ID, the current time and JSON data, like the events
+// sent by the sources.
+func newEvent(eventType, source, subject, dataSchema string, data interface{}) (*cev2.Event, error) {
+	event := cev2.NewEvent(cev2.VersionV1)
+	event.SetID(uuid.New().String())
+	event.SetTime(time.Now())
+	event.SetType(eventType)
+	event.SetSource(source)
+	if subject != "" {
+		event.SetSubject(subject)
+	}
+	event.SetDataSchema(dataSchema)
+	if err := event.SetData(cev2.ApplicationJSON, data); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
+)
+
+var storageEventTypes = []string{
+	schemasv1.CloudStorageObjectFinalizedEventType,
+	schemasv1.CloudStorageObjectArchivedEventType,
+	schemasv1.CloudStorageObjectDeletedEventType,
+	schemasv1.CloudStorageObjectMetadataUpdatedEventType,
+}
+
+// StorageObject decodes the object of a Cloud Storage event.
+func StorageObject(event cev2.Event) (*schemasv1.StorageObjectData, error) {
+	data := &schemasv1.StorageObjectData{}
+	if err := decode(event, data, storageEventTypes...); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// NewStorageObjectEvent returns a Cloud Storage event of the given type about the object.
+func NewStorageObjectEvent(eventType string, object *schemasv1.StorageObjectData) (*cev2.Event, error) {
+	return newEvent(eventType,
+		schemasv1.CloudStorageEventSource(object.Bucket),
+		schemasv1.CloudStorageEventSubject(object.Name),
+		schemasv1.CloudStorageEventDataSchema,
+		object)
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
+)
+
+func TestStorageObject(t *testing.T) {
+	object := &schemasv1.StorageObjectData{
+		Kind:     "storage#object",
+		ID:       "my-bucket/myfile.jpg/1",
+		Name:     "myfile.jpg",
+		Bucket:   "my-bucket",
+		Size:     "1024",
+		Metadata: map[string]string{"key": "value"},
+	}
+	event, err := NewStorageObjectEvent(schemasv1.CloudStorageObjectDeletedEventType, object)
+	if err != nil {
+		t.Fatalf("NewStorageObjectEvent got error: %v", err)
+	}
+	if err := event.Validate(); err != nil {
+		t.Errorf("NewStorageObjectEvent got invalid event: %v", err)
+	}
+	if want := schemasv1.CloudStorageEventSource("my-bucket"); event.Source() != want {
+		t.Errorf("Source %q != %q", event.Source(), want)
+	}
+	if want := schemasv1.CloudStorageEventSubject("myfile.jpg"); event.Subject() != want {
+		t.Errorf("Subject %q != %q", event.Subject(), want)
+	}
+
+	got, err := StorageObject(*event)
+	if err != nil {
+		t.Fatalf("StorageObject got error: %v", err)
+	}
+	if diff := cmp.Diff(object, got); diff != "" {
+		t.Errorf("unexpected object (-want, +got) = %v", diff)
+	}
+
+	event.SetType(schemasv1.CloudBuildSourceEventType)
+	if _, err := StorageObject(*event); err == nil {
+		t.Errorf("StorageObject got no error for a %s event", event.Type())
+	}
+}
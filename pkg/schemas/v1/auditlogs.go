@@ -19,6 +19,7 @@ package v1
 import (
 	"crypto/md5"
 	"fmt"
+	"time"
 )
 
 const (
@@ -50,3 +51,33 @@ func CloudAuditLogsEventID(id, logName, timestamp string) string {
 func CloudAuditLogsEventSubject(serviceName, resourceName string) string {
 	return fmt.Sprintf("%s/%s", serviceName, resourceName)
 }
+
+// AuditLogEntryData is the data of the Cloud Audit Logs events, the JSON representation of the
+// log entry. See https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry.
+type AuditLogEntryData struct {
+	LogName          string                 `json:"logName"`
+	Resource         *MonitoredResource     `json:"resource,omitempty"`
+	ProtoPayload     map[string]interface{} `json:"protoPayload"`
+	Timestamp        time.Time              `json:"timestamp"`
+	ReceiveTimestamp *time.Time             `json:"receiveTimestamp,omitempty"`
+	Severity         string                 `json:"severity,omitempty"`
+	InsertID         string                 `json:"insertId"`
+	Labels           map[string]string      `json:"labels,omitempty"`
+	Operation        *LogEntryOperation     `json:"operation,omitempty"`
+	Trace            string                 `json:"trace,omitempty"`
+	SpanID           string                 `json:"spanId,omitempty"`
+}
+
+// MonitoredResource is the resource that produced a log entry.
+type MonitoredResource struct {
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// LogEntryOperation is the operation a log entry is associated with.
+type LogEntryOperation struct {
+	ID       string `json:"id,omitempty"`
+	Producer string `json:"producer,omitempty"`
+	First    bool   `json:"first,omitempty"`
+	Last     bool   `json:"last,omitempty"`
+}
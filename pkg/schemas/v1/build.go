@@ -18,11 +18,13 @@ package v1
 
 import (
 	"fmt"
+	"time"
 )
 
 const (
 	// CloudBuildSource CloudEvent type
 	CloudBuildSourceEventType = "google.cloud.cloudbuild.build.v1.statusChanged"
+	CloudBuildEventDataSchema = "https://raw.githubusercontent.com/googleapis/google-cloudevents/master/proto/google/events/cloud/cloudbuild/v1/data.proto"
 	// CloudBuildSourceBuildId is the Pub/Sub message attribute key with the CloudBuildSource's buildId.
 	CloudBuildSourceBuildId = "buildId"
 	// CloudBuildSourceBuildStatus is the Pub/Sub message attribute key with the CloudBuildSource's build status.
@@ -33,3 +35,29 @@ const (
 func CloudBuildSourceEventSource(googleCloudProject, buildId string) string {
 	return fmt.Sprintf("//cloudbuild.googleapis.com/projects/%s/builds/%s", googleCloudProject, buildId)
 }
+
+// BuildData is the data of the Cloud Build events, the JSON representation of the build. See
+// https://cloud.google.com/cloud-build/docs/api/reference/rest/v1/projects.builds. The nested
+// resources are kept untyped.
+type BuildData struct {
+	ID               string                   `json:"id"`
+	ProjectID        string                   `json:"projectId"`
+	Status           string                   `json:"status"`
+	StatusDetail     string                   `json:"statusDetail,omitempty"`
+	Source           map[string]interface{}   `json:"source,omitempty"`
+	Steps            []map[string]interface{} `json:"steps,omitempty"`
+	Results          map[string]interface{}   `json:"results,omitempty"`
+	CreateTime       *time.Time               `json:"createTime,omitempty"`
+	StartTime        *time.Time               `json:"startTime,omitempty"`
+	FinishTime       *time.Time               `json:"finishTime,omitempty"`
+	Timeout          string                   `json:"timeout,omitempty"`
+	Images           []string                 `json:"images,omitempty"`
+	LogsBucket       string                   `json:"logsBucket,omitempty"`
+	SourceProvenance map[string]interface{}   `json:"sourceProvenance,omitempty"`
+	BuildTriggerID   string                   `json:"buildTriggerId,omitempty"`
+	Options          map[string]interface{}   `json:"options,omitempty"`
+	LogURL           string                   `json:"logUrl,omitempty"`
+	Substitutions    map[string]string        `json:"substitutions,omitempty"`
+	Tags             []string                 `json:"tags,omitempty"`
+	Timing           map[string]interface{}   `json:"timing,omitempty"`
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// JSONSchemaDraft is the version of JSON Schema of the event data schemas.
+	JSONSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+	// DataSchemaErrorExtension is the extension set to the validation error of the events whose
+	// data doesn't match the schema of their type.
+	DataSchemaErrorExtension = "dataschemaerror"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+
+	// dataTypes maps the types of the events to the Go types of their data.
+	dataTypes = map[string]reflect.Type{
+		CloudAuditLogsLogWrittenEventType:          reflect.TypeOf(AuditLogEntryData{}),
+		CloudBuildSourceEventType:                  reflect.TypeOf(BuildData{}),
+		CloudPubSubMessagePublishedEventType:       reflect.TypeOf(PushMessage{}),
+		CloudSchedulerJobExecutedEventType:         reflect.TypeOf(SchedulerJobData{}),
+		CloudStorageObjectFinalizedEventType:       reflect.TypeOf(StorageObjectData{}),
+		CloudStorageObjectArchivedEventType:        reflect.TypeOf(StorageObjectData{}),
+		CloudStorageObjectDeletedEventType:         reflect.TypeOf(StorageObjectData{}),
+		CloudStorageObjectMetadataUpdatedEventType: reflect.TypeOf(StorageObjectData{}),
+	}
+)
+
+// JSONSchema is the subset of JSON Schema used to describe the data of the events.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+}
+
+// EventDataSchema returns the JSON Schema of the data of the events of the given type, or nil if
+// the type is unknown. The schema is generated from the Go type of the data: the fields whose JSON
+// name is not tagged with omitempty are required.
+func EventDataSchema(eventType string) *JSONSchema {
+	t, ok := dataTypes[eventType]
+	if !ok {
+		return nil
+	}
+	s := schemaOf(t)
+	s.Schema = JSONSchemaDraft
+	s.Title = eventType
+	return s
+}
+
+// ValidateEventData validates the JSON data of an event of the given type against the schema of
+// its data. The data of the events of unknown types is not validated.
+func ValidateEventData(eventType string, data []byte) error {
+	s := EventDataSchema(eventType)
+	if s == nil {
+		return nil
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return fmt.Errorf("invalid JSON data: %w", err)
+	}
+	return s.Validate(v)
+}
+
+// Validate validates a value decoded from JSON with numbers decoded as json.Number.
+func (s *JSONSchema) Validate(v interface{}) error {
+	return s.validate("data", v)
+}
+
+func (s *JSONSchema) validate(path string, v interface{}) error {
+	switch s.Type {
+	case "":
+		return nil
+	case "object":
+		o, ok := v.(map[string]interface{})
+		if !ok {
+			return typeError(path, s.Type, v)
+		}
+		for _, name := range s.Required {
+			if p, ok := o[name]; !ok || p == nil {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+		keys := make([]string, 0, len(o))
+		for k := range o {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := o[k]
+			if p == nil {
+				continue
+			}
+			ps, ok := s.Properties[k]
+			if !ok {
+				ps = s.AdditionalProperties
+			}
+			if ps == nil {
+				continue
+			}
+			if err := ps.validate(path+"."+k, p); err != nil {
+				return err
+			}
+		}
+	case "array":
+		a, ok := v.([]interface{})
+		if !ok {
+			return typeError(path, s.Type, v)
+		}
+		if s.Items == nil {
+			return nil
+		}
+		for i, item := range a {
+			if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+				return err
+			}
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return typeError(path, s.Type, v)
+		}
+		switch s.Format {
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+				return fmt.Errorf("%s: invalid date-time %q", path, str)
+			}
+		case "byte":
+			if _, err := base64.StdEncoding.DecodeString(str); err != nil {
+				return fmt.Errorf("%s: invalid base64 string", path)
+			}
+		}
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return typeError(path, s.Type, v)
+		}
+		if _, err := n.Int64(); err != nil {
+			return fmt.Errorf("%s: invalid integer %s", path, n)
+		}
+	case "number":
+		if _, ok := v.(json.Number); !ok {
+			return typeError(path, s.Type, v)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return typeError(path, s.Type, v)
+		}
+	}
+	return nil
+}
+
+func typeError(path, want string, v interface{}) error {
+	got := "null"
+	switch v.(type) {
+	case map[string]interface{}:
+		got = "object"
+	case []interface{}:
+		got = "array"
+	case string:
+		got = "string"
+	case json.Number:
+		got = "number"
+	case bool:
+		got = "boolean"
+	}
+	return fmt.Errorf("%s: expected %s, got %s", path, want, got)
+}
+
+func schemaOf(t reflect.Type) *JSONSchema {
+	if t == timeType {
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaOf(t.Elem())
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes byte slices as base64 strings.
+			return &JSONSchema{Type: "string", Format: "byte"}
+		}
+		return &JSONSchema{Type: "array", Items: schemaOf(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: schemaOf(t.Elem())}
+	case reflect.Struct:
+		s := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			name := f.Name
+			opts := ""
+			if tag, ok := f.Tag.Lookup("json"); ok {
+				if tag == "-" {
+					continue
+				}
+				split := strings.SplitN(tag, ",", 2)
+				if split[0] != "" {
+					name = split[0]
+				}
+				if len(split) > 1 {
+					opts = split[1]
+				}
+			}
+			s.Properties[name] = schemaOf(f.Type)
+			if !strings.Contains(opts, "omitempty") {
+				s.Required = append(s.Required, name)
+			}
+		}
+		return s
+	default:
+		// Interfaces can hold any value.
+		return &JSONSchema{}
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEventDataSchema(t *testing.T) {
+	for _, types := range catalog {
+		for _, info := range types {
+			if EventDataSchema(info.Type) == nil {
+				t.Errorf("EventDataSchema(%q) got=nil, want a schema", info.Type)
+			}
+		}
+	}
+	if got := EventDataSchema("unknown"); got != nil {
+		t.Errorf("EventDataSchema got=%v, want=nil", got)
+	}
+
+	got := EventDataSchema(CloudPubSubMessagePublishedEventType)
+	want := &JSONSchema{
+		Schema: JSONSchemaDraft,
+		Title:  CloudPubSubMessagePublishedEventType,
+		Type:   "object",
+		Properties: map[string]*JSONSchema{
+			"subscription": {Type: "string"},
+			"message": {
+				Type: "object",
+				Properties: map[string]*JSONSchema{
+					"messageId":   {Type: "string"},
+					"data":        {},
+					"attributes":  {Type: "object", AdditionalProperties: &JSONSchema{Type: "string"}},
+					"publishTime": {Type: "string", Format: "date-time"},
+				},
+			},
+		},
+		Required: []string{"subscription"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected schema (-want, +got) = %v", diff)
+	}
+}
+
+func TestValidateEventData(t *testing.T) {
+	now := time.Now()
+	object, _ := json.Marshal(&StorageObjectData{
+		Kind:     "storage#object",
+		ID:       "bucket/object/1",
+		Name:     "object",
+		Bucket:   "bucket",
+		Updated:  &now,
+		Metadata: map[string]string{"key": "value"},
+	})
+	pushMessage, _ := json.Marshal(&PushMessage{
+		Subscription: "sub",
+		Message: &PubSubMessage{
+			ID:          "id",
+			Data:        []byte("data"),
+			PublishTime: now,
+		},
+	})
+
+	testCases := map[string]struct {
+		eventType string
+		data      string
+		wantErr   bool
+	}{
+		"valid object": {
+			eventType: CloudStorageObjectFinalizedEventType,
+			data:      string(object),
+		},
+		"valid push message": {
+			eventType: CloudPubSubMessagePublishedEventType,
+			data:      string(pushMessage),
+		},
+		"unknown type": {
+			eventType: "unknown",
+			data:      "not json",
+		},
+		"invalid json": {
+			eventType: CloudStorageObjectFinalizedEventType,
+			data:      "not json",
+			wantErr:   true,
+		},
+		"not an object": {
+			eventType: CloudStorageObjectFinalizedEventType,
+			data:      `"object"`,
+			wantErr:   true,
+		},
+		"missing required property": {
+			eventType: CloudStorageObjectDeletedEventType,
+			data:      `{"kind": "storage#object", "id": "id", "name": "object"}`,
+			wantErr:   true,
+		},
+		"null required property": {
+			eventType: CloudBuildSourceEventType,
+			data:      `{"id": "id", "projectId": null, "status": "SUCCESS"}`,
+			wantErr:   true,
+		},
+		"wrong property type": {
+			eventType: CloudBuildSourceEventType,
+			data:      `{"id": "id", "projectId": "project", "status": "SUCCESS", "images": "image"}`,
+			wantErr:   true,
+		},
+		"wrong item type": {
+			eventType: CloudBuildSourceEventType,
+			data:      `{"id": "id", "projectId": "project", "status": "SUCCESS", "tags": ["tag", 1]}`,
+			wantErr:   true,
+		},
+		"invalid date-time": {
+			eventType: CloudAuditLogsLogWrittenEventType,
+			data:      `{"logName": "log", "insertId": "id", "protoPayload": {}, "timestamp": "yesterday"}`,
+			wantErr:   true,
+		},
+		"invalid integer": {
+			eventType: CloudStorageObjectFinalizedEventType,
+			data:      `{"kind": "storage#object", "id": "id", "name": "object", "bucket": "bucket", "componentCount": 1.5}`,
+			wantErr:   true,
+		},
+		"invalid bytes": {
+			eventType: CloudSchedulerJobExecutedEventType,
+			data:      `{"custom_data": "not base64!"}`,
+			wantErr:   true,
+		},
+		"unknown properties": {
+			eventType: CloudAuditLogsLogWrittenEventType,
+			data:      `{"logName": "log", "insertId": "id", "protoPayload": {"@type": "type"}, "timestamp": "2020-09-15T12:00:00.123Z", "new": true}`,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			err := ValidateEventData(tc.eventType, []byte(tc.data))
+			if tc.wantErr != (err != nil) {
+				t.Errorf("ValidateEventData() = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// EventTypeInfo describes a type of the events sent by the sources.
+type EventTypeInfo struct {
+	// Type is the CloudEvent type of the events.
+	Type string `json:"type"`
+	// Schema is the URL of the schema of the data of the events, if any.
+	Schema string `json:"schema,omitempty"`
+	// Description is a human readable description of the events.
+	Description string `json:"description"`
+}
+
+// catalog lists the types of the events sent by each kind of source.
+var catalog = map[string][]EventTypeInfo{
+	"CloudAuditLogsSource": {{
+		Type:        CloudAuditLogsLogWrittenEventType,
+		Schema:      CloudAuditLogsEventDataSchema,
+		Description: "An audit log entry matching the service, method and resource of the source was written.",
+	}},
+	"CloudBuildSource": {{
+		Type:        CloudBuildSourceEventType,
+		Schema:      CloudBuildEventDataSchema,
+		Description: "The status of a build changed.",
+	}},
+	"CloudPubSubSource": {{
+		Type:        CloudPubSubMessagePublishedEventType,
+		Schema:      CloudPubSubEventDataSchema,
+		Description: "A message was published to the topic of the source.",
+	}},
+	"CloudSchedulerSource": {{
+		Type:        CloudSchedulerJobExecutedEventType,
+		Schema:      CloudSchedulerEventDataSchema,
+		Description: "The job of the source was executed.",
+	}},
+	"CloudStorageSource": {{
+		Type:        CloudStorageObjectFinalizedEventType,
+		Schema:      CloudStorageEventDataSchema,
+		Description: "An object was created, or an existing object was overwritten, in the bucket of the source.",
+	}, {
+		Type:        CloudStorageObjectArchivedEventType,
+		Schema:      CloudStorageEventDataSchema,
+		Description: "A live version of an object of the bucket of the source became a noncurrent version.",
+	}, {
+		Type:        CloudStorageObjectDeletedEventType,
+		Schema:      CloudStorageEventDataSchema,
+		Description: "An object of the bucket of the source was permanently deleted.",
+	}, {
+		Type:        CloudStorageObjectMetadataUpdatedEventType,
+		Schema:      CloudStorageEventDataSchema,
+		Description: "The metadata of an existing object of the bucket of the source changed.",
+	}},
+}
+
+// EventTypes returns the types of the events that the given kind of source can send, or nil if
+// the kind is unknown.
+func EventTypes(kind string) []EventTypeInfo {
+	types, ok := catalog[kind]
+	if !ok {
+		return nil
+	}
+	return append([]EventTypeInfo(nil), types...)
+}
+
+// EventTypeInfoOf returns the description of the given event type sent by the given kind of
+// source.
+func EventTypeInfoOf(kind, eventType string) (EventTypeInfo, bool) {
+	for _, info := range catalog[kind] {
+		if info.Type == eventType {
+			return info, true
+		}
+	}
+	return EventTypeInfo{}, false
+}
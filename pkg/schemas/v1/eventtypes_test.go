@@ -0,0 +1,54 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+)
+
+func TestEventTypes(t *testing.T) {
+	for kind, types := range catalog {
+		got := EventTypes(kind)
+		if len(got) != len(types) {
+			t.Errorf("EventTypes(%q) got %d types, want %d", kind, len(got), len(types))
+		}
+		for _, info := range got {
+			if info.Type == "" || info.Schema == "" || info.Description == "" {
+				t.Errorf("EventTypes(%q) got incomplete type %+v", kind, info)
+			}
+		}
+	}
+	if got := EventTypes("Unknown"); got != nil {
+		t.Errorf("EventTypes got=%v, want=nil", got)
+	}
+
+	got := EventTypes("CloudPubSubSource")
+	got[0].Type = "modified"
+	if info, _ := EventTypeInfoOf("CloudPubSubSource", CloudPubSubMessagePublishedEventType); info.Type != CloudPubSubMessagePublishedEventType {
+		t.Errorf("EventTypes returned the catalog instead of a copy")
+	}
+}
+
+func TestEventTypeInfoOf(t *testing.T) {
+	info, ok := EventTypeInfoOf("CloudStorageSource", CloudStorageObjectDeletedEventType)
+	if !ok || info.Schema != CloudStorageEventDataSchema {
+		t.Errorf("EventTypeInfoOf got=%+v, %v, want the deleted object type", info, ok)
+	}
+	if _, ok := EventTypeInfoOf("CloudStorageSource", CloudPubSubMessagePublishedEventType); ok {
+		t.Errorf("EventTypeInfoOf found a type that the kind doesn't send")
+	}
+}
@@ -16,7 +16,10 @@ limitations under the License.
 
 package v1
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 const (
 	CloudStorageObjectFinalizedEventType       = "google.cloud.storage.object.v1.finalized"
@@ -33,3 +36,36 @@ func CloudStorageEventSource(bucket string) string {
 func CloudStorageEventSubject(object string) string {
 	return fmt.Sprintf("objects/%s", object)
 }
+
+// StorageObjectData is the data of the Cloud Storage events, the JSON representation of the
+// object the event is about. See https://cloud.google.com/storage/docs/json_api/v1/objects.
+// The 64-bit integers are represented as strings.
+type StorageObjectData struct {
+	Kind                    string            `json:"kind"`
+	ID                      string            `json:"id"`
+	SelfLink                string            `json:"selfLink,omitempty"`
+	Name                    string            `json:"name"`
+	Bucket                  string            `json:"bucket"`
+	Generation              string            `json:"generation,omitempty"`
+	Metageneration          string            `json:"metageneration,omitempty"`
+	ContentType             string            `json:"contentType,omitempty"`
+	TimeCreated             *time.Time        `json:"timeCreated,omitempty"`
+	Updated                 *time.Time        `json:"updated,omitempty"`
+	TimeDeleted             *time.Time        `json:"timeDeleted,omitempty"`
+	StorageClass            string            `json:"storageClass,omitempty"`
+	TimeStorageClassUpdated *time.Time        `json:"timeStorageClassUpdated,omitempty"`
+	Size                    string            `json:"size,omitempty"`
+	MD5Hash                 string            `json:"md5Hash,omitempty"`
+	MediaLink               string            `json:"mediaLink,omitempty"`
+	ContentEncoding         string            `json:"contentEncoding,omitempty"`
+	ContentDisposition      string            `json:"contentDisposition,omitempty"`
+	ContentLanguage         string            `json:"contentLanguage,omitempty"`
+	CacheControl            string            `json:"cacheControl,omitempty"`
+	Metadata                map[string]string `json:"metadata,omitempty"`
+	CRC32C                  string            `json:"crc32c,omitempty"`
+	ComponentCount          int32             `json:"componentCount,omitempty"`
+	Etag                    string            `json:"etag,omitempty"`
+	EventBasedHold          bool              `json:"eventBasedHold,omitempty"`
+	TemporaryHold           bool              `json:"temporaryHold,omitempty"`
+	KMSKeyName              string            `json:"kmsKeyName,omitempty"`
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import "time"
+
+const (
+	// UnconvertibleMessageEventType is the type of the events wrapping the messages that could not
+	// be converted to events, see UnconvertibleMessage.
+	UnconvertibleMessageEventType = "dev.knative.gcp.message.v1.unconvertible"
+)
+
+// UnconvertibleMessage is the data of the events wrapping the raw messages that could not be
+// converted to events, so that their data is preserved.
+type UnconvertibleMessage struct {
+	// ID is the ID of the message in its queue.
+	ID string `json:"messageId"`
+
+	// Data is the raw payload of the message.
+	Data []byte `json:"data,omitempty"`
+
+	// Attributes are the attributes of the message, if any.
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// PublishTime is the time at which the message was published, if known.
+	PublishTime *time.Time `json:"publishTime,omitempty"`
+
+	// Error is the error that occurred when converting the message.
+	Error string `json:"error"`
+}
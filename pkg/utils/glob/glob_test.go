@@ -0,0 +1,111 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package glob
+
+import (
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		pattern string
+		match   []string
+		noMatch []string
+	}{{
+		pattern: "*.csv",
+		match:   []string{"a.csv", ".csv"},
+		noMatch: []string{"a.csv.gz", "dir/a.csv", "a.CSV"},
+	}, {
+		pattern: "**.csv",
+		match:   []string{"a.csv", "dir/sub/a.csv"},
+		noMatch: []string{"a.csv.gz"},
+	}, {
+		pattern: "logs/**/*.json",
+		match:   []string{"logs/2020/09/a.json", "logs//a.json"},
+		noMatch: []string{"logs/a.json", "other/2020/a.json"},
+	}, {
+		pattern: "report-????.pdf",
+		match:   []string{"report-2020.pdf"},
+		noMatch: []string{"report-20.pdf", "report-20/0.pdf"},
+	}, {
+		pattern: "a+b(1).[txt]",
+		match:   []string{"a+b(1).[txt]"},
+		noMatch: []string{"aab(1).t"},
+	}}
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			re, err := Compile(test.pattern)
+			if err != nil {
+				t.Fatalf("Compile() got error: %v", err)
+			}
+			for _, name := range test.match {
+				if !re.MatchString(name) {
+					t.Errorf("%q doesn't match %q", name, test.pattern)
+				}
+			}
+			for _, name := range test.noMatch {
+				if re.MatchString(name) {
+					t.Errorf("%q matches %q", name, test.pattern)
+				}
+			}
+		})
+	}
+}
+
+func TestCompileEmpty(t *testing.T) {
+	if _, err := Compile(""); err == nil {
+		t.Error("Compile() of empty pattern got nil error")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	f, err := NewFilter([]string{"**.csv", "**.json"}, []string{"tmp/**"})
+	if err != nil {
+		t.Fatalf("NewFilter() got error: %v", err)
+	}
+	for name, want := range map[string]bool{
+		"a.csv":         true,
+		"data/a.json":   true,
+		"a.txt":         false,
+		"tmp/a.csv":     false,
+		"tmp/sub/a.csv": false,
+	} {
+		if got := f.Match(name); got != want {
+			t.Errorf("Match(%q) got=%v, want=%v", name, got, want)
+		}
+	}
+
+	exclude, err := NewFilter(nil, []string{"*.tmp"})
+	if err != nil {
+		t.Fatalf("NewFilter() got error: %v", err)
+	}
+	if !exclude.Match("a.csv") || exclude.Match("a.tmp") {
+		t.Error("exclude only filter doesn't match all the names but the excluded ones")
+	}
+
+	none, err := NewFilter(nil, nil)
+	if err != nil || none != nil {
+		t.Fatalf("NewFilter() without patterns got=(%v, %v), want=(nil, nil)", none, err)
+	}
+	if !none.Match("a.csv") {
+		t.Error("nil filter doesn't match")
+	}
+
+	if _, err := NewFilter([]string{""}, nil); err == nil {
+		t.Error("NewFilter() with empty pattern got nil error")
+	}
+}